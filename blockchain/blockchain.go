@@ -9,8 +9,6 @@ import (
 	"sync/atomic"
 
 	"github.com/0xPolygon/minimal/blockchain/storage"
-	"github.com/0xPolygon/minimal/blockchain/storage/leveldb"
-	"github.com/0xPolygon/minimal/blockchain/storage/memory"
 	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/state"
 	"github.com/0xPolygon/minimal/types"
@@ -18,8 +16,44 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+
+	// register the storage backends
+	_ "github.com/0xPolygon/minimal/blockchain/storage/leveldb"
+	_ "github.com/0xPolygon/minimal/blockchain/storage/memory"
+	_ "github.com/0xPolygon/minimal/blockchain/storage/pebble"
 )
 
+// DefaultStorageBackend is the storage backend used for a data directory
+// when none is configured
+const DefaultStorageBackend = "leveldb"
+
+// StorageConfig selects the storage backend of the chain and the
+// options passed to its factory
+type StorageConfig struct {
+	Backend string
+	Config  map[string]interface{}
+
+	// Metrics is the registry of the storage metrics, they are disabled if nil
+	Metrics prometheus.Registerer
+
+	// ForkRetention is the number of blocks below the head for which the non
+	// canonical blocks are kept, they are never removed if it is zero
+	ForkRetention uint64
+
+	// Mode is the storage mode, archive if empty. It cannot be changed
+	// once the storage is created
+	Mode StorageMode
+
+	// HistoryRetention is the number of blocks below the head for which the
+	// full and light modes keep the history, DefaultHistoryRetention if zero
+	HistoryRetention uint64
+
+	// Cache is the number of entries cached per storage namespace, it
+	// overrides storage.DefaultCacheSizes and zero disables the cache
+	Cache map[string]int
+}
+
 var (
 	errDuplicateUncle  = errors.New("duplicate uncle")
 	errUncleIsAncestor = errors.New("uncle is ancestor")
@@ -37,6 +71,8 @@ type Blockchain struct {
 	config  *chain.Chain
 	genesis types.Hash
 
+	// the decoded headers and difficulties, the storage does not cache
+	// their namespaces so that the hits skip the decoding. Nil if disabled
 	headersCache    *lru.Cache
 	difficultyCache *lru.Cache
 
@@ -47,6 +83,21 @@ type Blockchain struct {
 	// event subscriptions
 	stream *eventStream
 
+	// indexes derived from the canonical chain
+	indexers        []*ChainIndexer
+	indexersLock    sync.Mutex
+	indexersRunning bool
+
+	// index of the log blooms
+	bloomIndex *ChainIndexer
+
+	// depth below the head at which the non canonical blocks are pruned
+	forkRetention uint64
+
+	// storage mode and depth below the head at which the history is pruned
+	mode             StorageMode
+	historyRetention uint64
+
 	// Average gas price (rolling average)
 	averageGasPrice      *big.Int
 	averageGasPriceCount *big.Int
@@ -82,8 +133,9 @@ func (b *Blockchain) GetAvgGasPrice() *big.Int {
 	return b.averageGasPrice
 }
 
-// NewBlockchain creates a new blockchain object
-func NewBlockchain(logger hclog.Logger, dataDir string, config *chain.Chain, consensus Verifier, executor Executor) (*Blockchain, error) {
+// NewBlockchain creates a new blockchain object. The chain is stored in dataDir
+// with the configured storage backend, or in memory if dataDir is empty
+func NewBlockchain(logger hclog.Logger, dataDir string, storageConfig *StorageConfig, config *chain.Chain, consensus Verifier, executor Executor) (*Blockchain, error) {
 	b := &Blockchain{
 		logger:    logger.Named("blockchain"),
 		config:    config,
@@ -92,21 +144,58 @@ func NewBlockchain(logger hclog.Logger, dataDir string, config *chain.Chain, con
 		stream:    &eventStream{},
 	}
 
-	var storage storage.Storage
-	var err error
-	if dataDir == "" {
-		if storage, err = memory.NewMemoryStorage(nil); err != nil {
-			return nil, err
+	backend, backendConfig := "memory", map[string]interface{}{}
+	if dataDir != "" {
+		backend = DefaultStorageBackend
+		if storageConfig != nil {
+			if storageConfig.Backend != "" {
+				backend = storageConfig.Backend
+			}
+			for k, v := range storageConfig.Config {
+				backendConfig[k] = v
+			}
 		}
-	} else {
-		if storage, err = leveldb.NewLevelDBStorage(filepath.Join(dataDir, "blockchain"), logger); err != nil {
-			return nil, err
+		backendConfig["path"] = filepath.Join(dataDir, "blockchain")
+	}
+	if storageConfig != nil {
+		if storageConfig.Metrics != nil {
+			backendConfig["metrics"] = storageConfig.Metrics
+		}
+		b.forkRetention = storageConfig.ForkRetention
+		b.mode = storageConfig.Mode
+		b.historyRetention = storageConfig.HistoryRetention
+	}
+	cache := map[string]int{}
+	for name, size := range storage.DefaultCacheSizes {
+		cache[name] = size
+	}
+	if storageConfig != nil {
+		for name, size := range storageConfig.Cache {
+			cache[name] = size
 		}
 	}
-	b.db = storage
+	// the headers and the difficulties are cached decoded
+	var err error
+	if b.headersCache, err = newDecodedCache(cache, "headers"); err != nil {
+		return nil, err
+	}
+	if b.difficultyCache, err = newDecodedCache(cache, "difficulties"); err != nil {
+		return nil, err
+	}
+	backendConfig["cache"] = cache
+
+	db, err := storage.Open(backend, backendConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	b.db = db
+
+	if err := b.setupMode(); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	b.headersCache, _ = lru.New(100)
-	b.difficultyCache, _ = lru.New(100)
+	b.bloomIndex = b.AddIndexer(bloomIndexName, &bloomIndexer{db: b.db, size: BloomSectionSize}, BloomSectionSize, bloomConfirmations)
 
 	// push the first event to the stream
 	b.stream.push(&Event{})
@@ -127,7 +216,7 @@ func NewBlockchain(logger hclog.Logger, dataDir string, config *chain.Chain, con
 
 func (b *Blockchain) ComputeGenesis() error {
 	// try to write the genesis block
-	head, ok := b.db.ReadHeadHash()
+	_, ok := b.db.ReadHeadHash()
 	if ok {
 		// initialized storage
 		b.genesis, ok = b.db.ReadCanonicalHash(0)
@@ -138,17 +227,19 @@ func (b *Blockchain) ComputeGenesis() error {
 		if b.genesis != b.config.Genesis.Hash() {
 			return fmt.Errorf("genesis file does not match current genesis")
 		}
-		header, ok := b.GetHeaderByHash(head)
-		if !ok {
-			return fmt.Errorf("failed to get header with hash %s", head.String())
-		}
-		diff, ok := b.GetTD(head)
-		if !ok {
-			return fmt.Errorf("failed to read difficulty")
+		// validate the head and rewind to a consistent block if required
+		header, diff, err := b.loadHead()
+		if err != nil {
+			return err
 		}
 
 		b.logger.Info("Current header", "hash", header.Hash.String(), "number", header.Number)
 		b.setCurrentHeader(header, diff)
+
+		// complete or roll back the block being written when the process stopped
+		if err := b.recoverPendingBlock(); err != nil {
+			return err
+		}
 	} else {
 		// empty storage, write the genesis
 		if err := b.writeGenesis(b.config.Genesis); err != nil {
@@ -156,6 +247,9 @@ func (b *Blockchain) ComputeGenesis() error {
 		}
 	}
 	b.logger.Info("genesis", "hash", b.config.Genesis.Hash())
+
+	// the chain is initialized, start the indexes
+	b.startIndexers()
 	return nil
 }
 
@@ -266,16 +360,6 @@ func (b *Blockchain) writeCanonicalHeader(evnt *Event, h *types.Header) error {
 }
 
 func (b *Blockchain) advanceHead(h *types.Header) (*big.Int, error) {
-	if err := b.db.WriteHeadHash(h.Hash); err != nil {
-		return nil, err
-	}
-	if err := b.db.WriteHeadNumber(h.Number); err != nil {
-		return nil, err
-	}
-	if err := b.db.WriteCanonicalHash(h.Number, h.Hash); err != nil {
-		return nil, err
-	}
-
 	currentDiff := big.NewInt(0)
 	if h.ParentHash != types.StringToHash("") {
 		td, ok := b.readDiff(h.ParentHash)
@@ -284,9 +368,14 @@ func (b *Blockchain) advanceHead(h *types.Header) (*big.Int, error) {
 		}
 		currentDiff = td
 	}
-
 	diff := big.NewInt(1).Add(currentDiff, new(big.Int).SetUint64(h.Difficulty))
-	if err := b.db.WriteDiff(h.Hash, diff); err != nil {
+
+	batch := storage.NewBatchWriter(b.db)
+	batch.PutHeadHash(h.Hash)
+	batch.PutHeadNumber(h.Number)
+	batch.PutCanonicalHash(h.Number, h.Hash)
+	batch.PutDiff(h.Hash, diff)
+	if err := batch.Commit(); err != nil {
 		return nil, err
 	}
 
@@ -309,17 +398,35 @@ func (b *Blockchain) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
 	return b.readHeader(hash)
 }
 
+// newDecodedCache creates the cache of a namespace of the sizes and removes it
+// from the sizes of the storage cache
+func newDecodedCache(sizes map[string]int, name string) (*lru.Cache, error) {
+	size := sizes[name]
+	sizes[name] = 0
+	if size < 0 {
+		return nil, fmt.Errorf("negative cache size %d for namespace '%s'", size, name)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	return lru.New(size)
+}
+
+// readHeader returns a copy of the header, the cached one is never modified
 func (b *Blockchain) readHeader(hash types.Hash) (*types.Header, bool) {
-	h, ok := b.headersCache.Get(hash)
-	if ok {
-		return h.(*types.Header), true
+	if b.headersCache != nil {
+		if h, ok := b.headersCache.Get(hash); ok {
+			return h.(*types.Header).Copy(), true
+		}
 	}
 	hh, err := b.db.ReadHeader(hash)
 	if err != nil {
 		return nil, false
 	}
 	hh.ComputeHash()
-	b.headersCache.Add(hash, hh)
+	if b.headersCache != nil {
+		b.headersCache.Add(hash, hh.Copy())
+	}
 	return hh, true
 }
 
@@ -333,18 +440,31 @@ func (b *Blockchain) readBody(hash types.Hash) (*types.Body, bool) {
 }
 
 func (b *Blockchain) readDiff(hash types.Hash) (*big.Int, bool) {
-	d, ok := b.difficultyCache.Get(hash)
-	if ok {
-		return d.(*big.Int), true
+	if b.difficultyCache != nil {
+		if d, ok := b.difficultyCache.Get(hash); ok {
+			return new(big.Int).Set(d.(*big.Int)), true
+		}
 	}
 	dd, ok := b.db.ReadDiff(hash)
 	if !ok {
 		return nil, false
 	}
-	b.difficultyCache.Add(hash, dd)
+	if b.difficultyCache != nil {
+		b.difficultyCache.Add(hash, new(big.Int).Set(dd))
+	}
 	return dd, true
 }
 
+// uncacheBlock removes the header and the difficulty of a deleted block from the caches
+func (b *Blockchain) uncacheBlock(hash types.Hash) {
+	if b.headersCache != nil {
+		b.headersCache.Remove(hash)
+	}
+	if b.difficultyCache != nil {
+		b.difficultyCache.Remove(hash)
+	}
+}
+
 // GetHeaderByNumber returns the header by his number
 func (b *Blockchain) GetHeaderByNumber(n uint64) (*types.Header, bool) {
 	hash, ok := b.db.ReadCanonicalHash(n)
@@ -405,7 +525,13 @@ func (b *Blockchain) WriteBlocks(blocks []*types.Block) error {
 		return fmt.Errorf("parent not found")
 	}
 
+	var forks *chain.Forks
+	if b.config.Params != nil {
+		forks = b.config.Params.Forks
+	}
+
 	// validate chain
+	head := parent
 	for i := 0; i < size; i++ {
 		block := blocks[i]
 		if block.Number()-1 != parent.Number {
@@ -414,10 +540,9 @@ func (b *Blockchain) WriteBlocks(blocks []*types.Block) error {
 		if block.ParentHash() != parent.Hash {
 			return fmt.Errorf("parent hash not correct")
 		}
-		if err := b.consensus.VerifyHeader(parent, block.Header); err != nil {
-			return fmt.Errorf("failed to verify the header: %v", err)
+		if err := chain.VerifyBaseFee(forks, parent, block.Header); err != nil {
+			return fmt.Errorf("block %d: %v", block.Number(), err)
 		}
-
 		// verify body data
 		if hash := buildroot.CalculateUncleRoot(block.Uncles); hash != block.Header.Sha3Uncles {
 			return fmt.Errorf("uncle root hash mismatch: have %s, want %s", hash, block.Header.Sha3Uncles)
@@ -430,18 +555,37 @@ func (b *Blockchain) WriteBlocks(blocks []*types.Block) error {
 	}
 
 	// Write chain
+	parent = head
 	for indx, block := range blocks {
 		header := block.Header
 
-		if err := b.writeBody(block); err != nil {
-			return err
+		// the consensus verifies the header once the parent is written since
+		// it might need the state of the parent
+		if err := b.consensus.VerifyHeader(parent, header); err != nil {
+			return fmt.Errorf("failed to verify the header: %v", err)
 		}
+		if err := b.verifyUncles(block); err != nil {
+			return fmt.Errorf("failed to verify the uncles: %v", err)
+		}
+		parent = header
+
 		// Process and validate the block
 		res, err := b.processBlock(blocks[indx])
 		if err != nil {
 			return err
 		}
 
+		// write the body and the bloom of the logs before the header so that
+		// they are available for the bloom index once the block is canonical.
+		// The block is marked as pending until the receipts are written
+		batch := storage.NewBatchWriter(b.db)
+		b.writeBody(batch, block)
+		batch.PutBloom(block.Hash(), types.CreateBloom(res.Receipts))
+		batch.PutPendingBlock(block.Hash())
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+
 		// Write the header to the chain
 		evnt := &Event{}
 		if err := b.writeHeaderImpl(evnt, header); err != nil {
@@ -452,7 +596,10 @@ func (b *Blockchain) WriteBlocks(blocks []*types.Block) error {
 		// write the receipts, do it only after the header has been written.
 		// Otherwise, a client might ask for a header once the receipt is valid
 		// but before it is written into the storage
-		if err := b.db.WriteReceipts(block.Hash(), res.Receipts); err != nil {
+		batch = storage.NewBatchWriter(b.db)
+		batch.PutReceipts(block.Hash(), res.Receipts)
+		batch.DeletePendingBlock()
+		if err := batch.Commit(); err != nil {
 			return err
 		}
 
@@ -464,21 +611,14 @@ func (b *Blockchain) WriteBlocks(blocks []*types.Block) error {
 	return nil
 }
 
-func (b *Blockchain) writeBody(block *types.Block) error {
-	body := block.Body()
-
+func (b *Blockchain) writeBody(batch *storage.BatchWriter, block *types.Block) {
 	// write the full body (txns + receipts)
-	if err := b.db.WriteBody(block.Header.Hash, body); err != nil {
-		return err
-	}
+	batch.PutBody(block.Header.Hash, block.Body())
 
 	// write txn lookups (txhash -> block)
 	for _, txn := range block.Transactions {
-		if err := b.db.WriteTxLookup(txn.Hash, block.Hash()); err != nil {
-			return err
-		}
+		batch.PutTxLookup(txn.Hash, block.Hash())
 	}
-	return nil
 }
 
 func (b *Blockchain) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
@@ -520,26 +660,63 @@ func (b *Blockchain) processBlock(block *types.Block) (*state.BlockResult, error
 
 var emptyFrom = types.Address{}
 
+// GetHashHelper returns a function that resolves the hash of an ancestor of the header
+// by number. The ancestors that are part of the canonical chain are read from the
+// canonical index, only the (usually empty) segment of the header's branch that is
+// not canonical is walked, and it is done once per helper.
 func (b *Blockchain) GetHashHelper(header *types.Header) func(i uint64) (res types.Hash) {
-	return func(i uint64) (res types.Hash) {
-		num, hash := header.Number-1, header.ParentHash
+	var (
+		once sync.Once
+
+		// hashes of the ancestors that are not in the canonical chain
+		fork = map[uint64]types.Hash{}
+
+		// lowest number walked in the fork and whether the walk
+		// ended in a canonical ancestor
+		forkBase     uint64
+		forkResolved bool
+	)
 
+	resolveFork := func() {
+		forkBase = header.Number
+		num, hash := header.Number-1, header.ParentHash
 		for {
-			if num == i {
-				res = hash
+			if canonical, ok := b.db.ReadCanonicalHash(num); ok && canonical == hash {
+				forkResolved = true
 				return
 			}
-			h, ok := b.GetHeaderByHash(hash)
-			if !ok {
+			fork[num] = hash
+			forkBase = num
+			if num == 0 {
 				return
 			}
-			hash = h.ParentHash
-			if num == 0 {
+			// read from the db directly to avoid filling the header cache
+			h, err := b.db.ReadHeader(hash)
+			if err != nil {
 				return
 			}
-			num--
+			num, hash = num-1, h.ParentHash
 		}
 	}
+
+	return func(i uint64) (res types.Hash) {
+		if i >= header.Number {
+			return
+		}
+		once.Do(resolveFork)
+
+		if i >= forkBase {
+			res = fork[i]
+			return
+		}
+		if !forkResolved {
+			// the branch of the header could not be walked up to
+			// the canonical chain
+			return
+		}
+		res, _ = b.db.ReadCanonicalHash(i)
+		return
+	}
 }
 
 func (b *Blockchain) GetHashByNumber(i uint64) types.Hash {
@@ -571,11 +748,11 @@ func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
 	// Write the data
 	if header.ParentHash == head.Hash {
 		// Fast path to save the new canonical header
-		return b.writeCanonicalHeader(evnt, header)
-	}
-
-	if err := b.db.WriteHeader(header); err != nil {
-		return err
+		if err := b.writeCanonicalHeader(evnt, header); err != nil {
+			return err
+		}
+		b.prune()
+		return nil
 	}
 
 	headerDiff, ok := b.readDiff(head.Hash)
@@ -587,13 +764,18 @@ func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
 	if !ok {
 		return fmt.Errorf("parent of %s (%d) not found", header.Hash.String(), header.Number)
 	}
-	if err := b.db.WriteDiff(header.Hash, big.NewInt(1).Add(parentDiff, new(big.Int).SetUint64(header.Difficulty))); err != nil {
+	incomingDiff := big.NewInt(1).Add(parentDiff, new(big.Int).SetUint64(header.Difficulty))
+
+	// the header is indexed as non canonical even if it causes a reorg,
+	// the index entries of the canonical blocks are skipped when pruning
+	batch := storage.NewBatchWriter(b.db)
+	batch.PutHeader(header)
+	batch.PutDiff(header.Hash, incomingDiff)
+	batch.PutNonCanonical(header.Number, header.Hash)
+	if err := batch.Commit(); err != nil {
 		return err
 	}
 
-	b.headersCache.Add(header.Hash, header)
-
-	incomingDiff := big.NewInt(1).Add(parentDiff, new(big.Int).SetUint64(header.Difficulty))
 	if incomingDiff.Cmp(headerDiff) > 0 {
 		// new block has higher difficulty than us, reorg the chain
 		if err := b.handleReorg(evnt, head, header); err != nil {
@@ -609,6 +791,7 @@ func (b *Blockchain) writeHeaderImpl(evnt *Event, header *types.Header) error {
 		}
 	}
 
+	b.prune()
 	return nil
 }
 
@@ -641,6 +824,9 @@ func (b *Blockchain) handleReorg(evnt *Event, oldHeader *types.Header, newHeader
 	oldChain := []*types.Header{}
 	newChain := []*types.Header{}
 
+	// headers of the new chain that become canonical
+	canonicalChain := []*types.Header{}
+
 	var ok bool
 
 	for oldHeader.Number > newHeader.Number {
@@ -657,6 +843,7 @@ func (b *Blockchain) handleReorg(evnt *Event, oldHeader *types.Header, newHeader
 			return fmt.Errorf("header '%s' not found", newHeader.ParentHash.String())
 		}
 		newChain = append(newChain, newHeader)
+		canonicalChain = append(canonicalChain, newHeader)
 	}
 
 	for oldHeader.Hash != newHeader.Hash {
@@ -670,6 +857,7 @@ func (b *Blockchain) handleReorg(evnt *Event, oldHeader *types.Header, newHeader
 		}
 
 		oldChain = append(oldChain, oldHeader)
+		canonicalChain = append(canonicalChain, newHeader)
 	}
 
 	for _, b := range oldChain[:len(oldChain)-1] {
@@ -686,8 +874,22 @@ func (b *Blockchain) handleReorg(evnt *Event, oldHeader *types.Header, newHeader
 		return fmt.Errorf("failed to write the old header as fork: %v", err)
 	}
 
+	// the blocks of the old chain are not canonical anymore
+	batch := storage.NewBatchWriter(b.db)
+	batch.PutNonCanonical(oldChainHead.Number, oldChainHead.Hash)
+	for _, h := range oldChain[:len(oldChain)-1] {
+		batch.PutNonCanonical(h.Number, h.Hash)
+	}
+	// a shorter new chain leaves no canonical blocks above its head
+	for n := newChainHead.Number + 1; n <= oldChainHead.Number; n++ {
+		batch.DeleteCanonicalHash(n)
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
 	// Update canonical chain numbers
-	for _, h := range newChain {
+	for _, h := range canonicalChain {
 		if err := b.db.WriteCanonicalHash(h.Number, h.Hash); err != nil {
 			return err
 		}
@@ -740,6 +942,17 @@ func (b *Blockchain) GetBlockByNumber(n uint64, full bool) (*types.Block, bool)
 	return b.GetBlockByHash(hash, full)
 }
 
+// Snapshot writes a consistent copy of the chain storage to dst
+func (b *Blockchain) Snapshot(dst string) error {
+	return b.db.Snapshot(dst)
+}
+
+// Compact compacts the range of keys [start, end) of the chain storage
+func (b *Blockchain) Compact(start, end []byte) error {
+	return b.db.Compact(start, end)
+}
+
 func (b *Blockchain) Close() error {
+	b.closeIndexers()
 	return b.db.Close()
 }
@@ -1,6 +1,7 @@
 package blockchain
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -10,7 +11,9 @@ import (
 
 	"github.com/0xPolygon/minimal/blockchain/storage"
 	"github.com/0xPolygon/minimal/blockchain/storage/memory"
+	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/types"
+	"github.com/0xPolygon/minimal/types/buildroot"
 )
 
 func TestGenesis(t *testing.T) {
@@ -535,11 +538,11 @@ func TestForkUnkwonParents(t *testing.T) {
 }
 
 func TestBlockchainWriteBody(t *testing.T) {
-	storage, err := memory.NewMemoryStorage(nil)
+	db, err := memory.NewMemoryStorage(nil)
 	assert.NoError(t, err)
 
 	b := &Blockchain{
-		db: storage,
+		db: db,
 	}
 
 	block := &types.Block{
@@ -553,7 +556,9 @@ func TestBlockchainWriteBody(t *testing.T) {
 	}
 	block.Header.ComputeHash()
 
-	if err := b.writeBody(block); err != nil {
+	batch := storage.NewBatchWriter(b.db)
+	b.writeBody(batch, block)
+	if err := batch.Commit(); err != nil {
 		t.Fatal(err)
 	}
 
@@ -561,3 +566,380 @@ func TestBlockchainWriteBody(t *testing.T) {
 	fmt.Println(body)
 	fmt.Println(ok)
 }
+
+func TestGetHashHelper(t *testing.T) {
+	b := NewTestBlockchain(t, nil)
+
+	h0 := NewTestHeaderChain(10)
+	h1 := NewTestHeaderFromChainWithSeed(h0[:5], 3, 1)
+
+	_, err := b.advanceHead(h0[0])
+	assert.NoError(t, err)
+	assert.NoError(t, b.WriteHeaders(h0[1:]))
+
+	// h1 is a fork with lower difficulty
+	assert.NoError(t, b.WriteHeaders(h1[5:]))
+	assert.Equal(t, h0[9].Hash, b.Header().Hash)
+
+	// canonical head
+	getHash := b.GetHashHelper(h0[9])
+	for i := 0; i < 9; i++ {
+		assert.Equal(t, h0[i].Hash, getHash(uint64(i)))
+	}
+	assert.Equal(t, types.Hash{}, getHash(9))
+	assert.Equal(t, types.Hash{}, getHash(100))
+
+	// the fork resolves its own branch and the common ancestors
+	getHash = b.GetHashHelper(h1[7])
+	for i := 0; i < 7; i++ {
+		assert.Equal(t, h1[i].Hash, getHash(uint64(i)))
+	}
+	assert.Equal(t, types.Hash{}, getHash(7))
+}
+
+func TestReorgCanonicalIndex(t *testing.T) {
+	b := NewTestBlockchain(t, nil)
+
+	h0 := NewTestHeaderChain(10)
+	h1 := NewTestHeaderFromChainWithSeed(h0[:5], 10, 1)
+
+	_, err := b.advanceHead(h0[0])
+	assert.NoError(t, err)
+	assert.NoError(t, b.WriteHeaders(h0[1:]))
+
+	// h1 is longer and triggers a reorg
+	assert.NoError(t, b.WriteHeaders(h1[5:]))
+	assert.Equal(t, h1[14].Hash, b.Header().Hash)
+
+	for _, h := range h1[1:] {
+		found, ok := b.GetHeaderByNumber(h.Number)
+		assert.True(t, ok)
+		assert.Equal(t, h.Hash, found.Hash)
+	}
+}
+
+func TestReorgShorterChain(t *testing.T) {
+	b := NewTestBlockchain(t, nil)
+
+	h0 := NewTestHeaderChain(10)
+	h1 := NewTestHeaderFromChainWithSeed(h0[:5], 3, 1)
+
+	// h1 is shorter but heavier
+	for i := 5; i < len(h1); i++ {
+		h1[i].Difficulty = 100
+		h1[i].ParentHash = h1[i-1].Hash
+		h1[i].ComputeHash()
+	}
+
+	_, err := b.advanceHead(h0[0])
+	assert.NoError(t, err)
+	assert.NoError(t, b.WriteHeaders(h0[1:]))
+
+	assert.NoError(t, b.WriteHeaders(h1[5:]))
+	assert.Equal(t, h1[7].Hash, b.Header().Hash)
+
+	// there are no canonical blocks above the new head
+	for n := uint64(8); n < 10; n++ {
+		_, ok := b.GetHeaderByNumber(n)
+		assert.False(t, ok)
+	}
+
+	// the old branch resolves its own hashes
+	getHash := b.GetHashHelper(h0[9])
+	for i := 0; i < 9; i++ {
+		assert.Equal(t, h0[i].Hash, getHash(uint64(i)))
+	}
+}
+
+func TestLoadHeadRepair(t *testing.T) {
+	chain := NewTestHeaderChain(10)
+
+	newChain := func(t *testing.T) *Blockchain {
+		b := NewTestBlockchain(t, chain)
+		assert.Equal(t, chain[9].Hash, b.Header().Hash)
+		return b
+	}
+
+	t.Run("Consistent", func(t *testing.T) {
+		b := newChain(t)
+
+		header, diff, err := b.loadHead()
+		assert.NoError(t, err)
+		assert.Equal(t, chain[9].Hash, header.Hash)
+		assert.Equal(t, b.CurrentTD(), diff)
+	})
+
+	t.Run("UnknownHeadHash", func(t *testing.T) {
+		b := newChain(t)
+		assert.NoError(t, b.db.WriteHeadHash(types.StringToHash("1")))
+
+		header, _, err := b.loadHead()
+		assert.NoError(t, err)
+		assert.Equal(t, chain[9].Hash, header.Hash)
+
+		// the head has been rewritten
+		head, _ := b.db.ReadHeadHash()
+		assert.Equal(t, chain[9].Hash, head)
+	})
+
+	t.Run("PartialWrite", func(t *testing.T) {
+		b := newChain(t)
+
+		// the head and the canonical index were written for a block
+		// whose header and difficulty are not in storage
+		unknown := types.StringToHash("1")
+		assert.NoError(t, b.db.WriteCanonicalHash(10, unknown))
+		assert.NoError(t, b.db.WriteHeadHash(unknown))
+		assert.NoError(t, b.db.WriteHeadNumber(10))
+
+		header, _, err := b.loadHead()
+		assert.NoError(t, err)
+		assert.Equal(t, chain[9].Hash, header.Hash)
+
+		num, _ := b.db.ReadHeadNumber()
+		assert.Equal(t, uint64(9), num)
+	})
+
+	t.Run("BrokenCanonicalIndex", func(t *testing.T) {
+		b := newChain(t)
+
+		// the canonical entry of an ancestor is wrong
+		assert.NoError(t, b.db.WriteCanonicalHash(8, types.StringToHash("1")))
+
+		header, _, err := b.loadHead()
+		assert.NoError(t, err)
+		assert.Equal(t, chain[7].Hash, header.Hash)
+	})
+}
+
+func TestRecoverPendingBlock(t *testing.T) {
+	newBlock := func(b *Blockchain, txs []*types.Transaction) *types.Block {
+		header := &types.Header{
+			ParentHash:   b.Header().Hash,
+			Number:       1,
+			Difficulty:   1,
+			Sha3Uncles:   types.EmptyUncleHash,
+			TxRoot:       buildroot.CalculateTransactionsRoot(txs),
+			ReceiptsRoot: buildroot.CalculateReceiptsRoot(nil),
+		}
+		header.ComputeHash()
+		return &types.Block{Header: header, Transactions: txs}
+	}
+
+	// writePending writes the entries of the block up to the header
+	writePending := func(t *testing.T, b *Blockchain, block *types.Block) {
+		batch := storage.NewBatchWriter(b.db)
+		b.writeBody(batch, block)
+		batch.PutBloom(block.Hash(), types.Bloom{})
+		batch.PutPendingBlock(block.Hash())
+		assert.NoError(t, batch.Commit())
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		b := TestBlockchain(t, nil)
+
+		txn := &types.Transaction{Value: big.NewInt(1), V: 1}
+		txn.ComputeHash()
+
+		block := newBlock(b, []*types.Transaction{txn})
+		writePending(t, b, block)
+
+		// the header was not written, the rest of the block is removed
+		assert.NoError(t, b.recoverPendingBlock())
+
+		_, err := b.db.ReadBody(block.Hash())
+		assert.Error(t, err)
+		_, ok := b.db.ReadTxLookup(txn.Hash)
+		assert.False(t, ok)
+		_, ok = b.db.ReadBloom(block.Hash())
+		assert.False(t, ok)
+		_, ok = b.db.ReadPendingBlock()
+		assert.False(t, ok)
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		b := TestBlockchain(t, nil)
+
+		block := newBlock(b, nil)
+		writePending(t, b, block)
+		assert.NoError(t, b.writeHeaderImpl(&Event{}, block.Header))
+
+		_, err := b.db.ReadReceipts(block.Hash())
+		assert.Error(t, err)
+
+		// the header was written, the block is processed again for the receipts
+		assert.NoError(t, b.recoverPendingBlock())
+
+		_, err = b.db.ReadReceipts(block.Hash())
+		assert.NoError(t, err)
+		_, ok := b.db.ReadPendingBlock()
+		assert.False(t, ok)
+		assert.Equal(t, block.Hash(), b.Header().Hash)
+	})
+
+	t.Run("NoPendingBlock", func(t *testing.T) {
+		b := TestBlockchain(t, nil)
+		assert.NoError(t, b.recoverPendingBlock())
+	})
+}
+
+func TestWriteBlocks_MockVerifier(t *testing.T) {
+	verifier := &MockVerifier{}
+	b := TestBlockchainWithVerifier(t, nil, verifier)
+
+	h0 := NewTestHeaderChainWithSeed(b.Header(), 5, 0)
+	h1 := NewTestHeaderChainWithSeed(b.Header(), 5, 1)
+
+	// the invalid header is not written
+	errInvalid := errors.New("invalid")
+	verifier.SetResult(h0[2].Hash, errInvalid)
+
+	err := b.WriteBlocks(HeadersToBlocks(h0[1:]))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), errInvalid.Error())
+	assert.Equal(t, h0[1].Hash, b.Header().Hash)
+
+	// the chain is forced to the other branch
+	verifier.ForceFork(1, h1[1].Hash)
+
+	err = b.WriteBlocks(HeadersToBlocks(h0[1:]))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), ErrMockForkRejected.Error())
+
+	assert.NoError(t, b.WriteBlocks(HeadersToBlocks(h1[1:])))
+	assert.Equal(t, h1[4].Hash, b.Header().Hash)
+
+	// the verification without an outcome
+	verifier.SetVerifyFn(func(parent, header *types.Header) error {
+		return errInvalid
+	})
+	h2 := NewTestHeaderChainWithSeed(b.Header(), 2, 1)
+	assert.Error(t, b.WriteBlocks(HeadersToBlocks(h2[1:])))
+
+	assert.Len(t, verifier.Verified(), 8)
+}
+
+func TestWriteBlocks_BaseFee(t *testing.T) {
+	b := TestBlockchain(t, nil)
+	forks := &chain.Forks{London: chain.NewFork(1)}
+	b.config.Params = &chain.Params{Forks: forks}
+
+	newBlock := func(parent *types.Header, baseFee *big.Int) *types.Block {
+		header := &types.Header{
+			ParentHash:   parent.Hash,
+			Number:       parent.Number + 1,
+			GasLimit:     1000000,
+			TxRoot:       types.EmptyRootHash,
+			Sha3Uncles:   types.EmptyUncleHash,
+			ReceiptsRoot: types.EmptyRootHash,
+			BaseFee:      baseFee,
+		}
+		header.ComputeHash()
+		return &types.Block{Header: header}
+	}
+
+	// the blocks after the fork need the base fee of the parent
+	genesis := b.Header()
+	assert.Error(t, b.WriteBlocks([]*types.Block{newBlock(genesis, nil)}))
+	assert.Error(t, b.WriteBlocks([]*types.Block{newBlock(genesis, big.NewInt(1))}))
+
+	block := newBlock(genesis, big.NewInt(chain.InitialBaseFee))
+	block2 := newBlock(block.Header, chain.CalcBaseFee(forks, block.Header))
+	assert.NoError(t, b.WriteBlocks([]*types.Block{block, block2}))
+	assert.Equal(t, block2.Hash(), b.Header().Hash)
+}
+
+func TestBlockchain_DecodedCache(t *testing.T) {
+	b := TestBlockchain(t, nil)
+	assert.NotNil(t, b.headersCache)
+	assert.NotNil(t, b.difficultyCache)
+
+	headers := NewTestHeaderChainWithSeed(b.Header(), 3, 0)
+	assert.NoError(t, b.WriteHeaders(headers[1:]))
+
+	// the hits return copies of the cached values
+	h, ok := b.GetHeaderByHash(headers[2].Hash)
+	assert.True(t, ok)
+	h.ExtraData = []byte{0x1}
+	h2, ok := b.GetHeaderByHash(headers[2].Hash)
+	assert.True(t, ok)
+	assert.Empty(t, h2.ExtraData)
+	assert.Equal(t, headers[2].Hash, h2.Hash)
+
+	td, ok := b.GetTD(headers[2].Hash)
+	assert.True(t, ok)
+	td.SetUint64(100)
+	td2, _ := b.GetTD(headers[2].Hash)
+	assert.NotEqual(t, uint64(100), td2.Uint64())
+
+	// the deleted blocks are removed from the caches
+	b.uncacheBlock(headers[2].Hash)
+	assert.False(t, b.headersCache.Contains(headers[2].Hash))
+	assert.False(t, b.difficultyCache.Contains(headers[2].Hash))
+}
+
+func TestWriteBlocks_Uncles(t *testing.T) {
+	verifier := &MockVerifier{}
+	b := TestBlockchainWithVerifier(t, nil, verifier)
+
+	newHeader := func(parent *types.Header, extra byte) *types.Header {
+		header := &types.Header{
+			ParentHash:   parent.Hash,
+			Number:       parent.Number + 1,
+			TxRoot:       types.EmptyRootHash,
+			Sha3Uncles:   types.EmptyUncleHash,
+			ReceiptsRoot: types.EmptyRootHash,
+			ExtraData:    []byte{extra},
+		}
+		header.ComputeHash()
+		return header
+	}
+	newBlock := func(parent *types.Header, uncles ...*types.Header) *types.Block {
+		header := newHeader(parent, 0)
+		header.Sha3Uncles = buildroot.CalculateUncleRoot(uncles)
+		header.ComputeHash()
+		return &types.Block{Header: header, Uncles: uncles}
+	}
+
+	// a chain of 10 blocks and a sibling of each block
+	chain := []*types.Header{b.Header()}
+	siblings := []*types.Header{nil}
+	for i := 1; i < 10; i++ {
+		block := newBlock(chain[i-1])
+		assert.NoError(t, b.WriteBlocks([]*types.Block{block}))
+		chain = append(chain, block.Header)
+		siblings = append(siblings, newHeader(chain[i-1], 1))
+	}
+	head := chain[9]
+
+	cases := []struct {
+		uncles []*types.Header
+		err    error
+	}{
+		{siblings[7:10], errTooManyUncles},
+		{[]*types.Header{siblings[9], siblings[9]}, errDuplicateUncle},
+		{[]*types.Header{chain[8]}, errUncleIsAncestor},
+		// a sibling of the block and an uncle 8 blocks deep
+		{[]*types.Header{newHeader(head, 1)}, errDanglingUncle},
+		{[]*types.Header{siblings[2]}, errDanglingUncle},
+	}
+	for _, c := range cases {
+		err := b.WriteBlocks([]*types.Block{newBlock(head, c.uncles...)})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), c.err.Error())
+	}
+
+	// the uncles with an invalid seal are rejected
+	verifier.SetResult(siblings[8].Hash, errors.New("invalid seal"))
+	assert.Error(t, b.WriteBlocks([]*types.Block{newBlock(head, siblings[8])}))
+	verifier.SetResult(siblings[8].Hash, nil)
+
+	// up to 7 blocks deep
+	block := newBlock(head, siblings[3], siblings[9])
+	assert.NoError(t, b.WriteBlocks([]*types.Block{block}))
+
+	// the uncles cannot be included twice
+	err := b.WriteBlocks([]*types.Block{newBlock(block.Header, siblings[9])})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), errDuplicateUncle.Error())
+}
@@ -0,0 +1,136 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/blockchain/bloombits"
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/types"
+)
+
+const (
+	// BloomSectionSize is the number of blocks grouped in a bloom bits section
+	BloomSectionSize = 4096
+
+	// bloomConfirmations is the number of blocks a section has to be behind
+	// the head before it is indexed, to avoid rebuilding it on small reorgs
+	bloomConfirmations = 256
+
+	bloomIndexName = "bloombits"
+)
+
+// fullBloom is used for blocks without a stored bloom (i.e. written without
+// being processed) so that they are never skipped by a filter
+var fullBloom types.Bloom
+
+func init() {
+	for i := range fullBloom {
+		fullBloom[i] = 0xff
+	}
+}
+
+// bloomIndexer is a chain indexer backend that rotates the per-block log blooms
+// of a section into bloom bits, so that a log filter can discard a whole
+// section with a few reads
+type bloomIndexer struct {
+	db   storage.Storage
+	size uint64
+
+	gen     *bloombits.Generator
+	section uint64
+}
+
+// Reset implements the ChainIndexerBackend interface
+func (i *bloomIndexer) Reset(section uint64, prevHead types.Hash) error {
+	gen, err := bloombits.NewGenerator(i.size)
+	if err != nil {
+		return err
+	}
+	i.gen, i.section = gen, section
+	return nil
+}
+
+// Process implements the ChainIndexerBackend interface
+func (i *bloomIndexer) Process(header *types.Header) error {
+	bloom, ok := i.db.ReadBloom(header.Hash)
+	if !ok {
+		bloom = fullBloom
+	}
+	return i.gen.AddBloom(header.Number-i.section*i.size, bloom)
+}
+
+// Commit implements the ChainIndexerBackend interface
+func (i *bloomIndexer) Commit() error {
+	for bit := uint(0); bit < bloombits.BloomBitLength; bit++ {
+		bits, err := i.gen.Bitset(bit)
+		if err != nil {
+			return err
+		}
+		if err := i.db.WriteBloomBits(bit, i.section, bits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FilterBlooms returns the numbers of the canonical blocks in the range [from, to]
+// whose log bloom might match the filter. The filter is a list of groups
+// (i.e. addresses and topics per position) and a block matches if it can
+// include at least one item of every group. Empty groups match any block.
+func (b *Blockchain) FilterBlooms(from, to uint64, filter [][][]byte) ([]uint64, error) {
+	sectionSize := b.bloomIndex.SectionSize()
+	matcher := bloombits.NewMatcher(sectionSize, filter)
+
+	res := []uint64{}
+	if matcher.Empty() {
+		for num := from; num <= to; num++ {
+			res = append(res, num)
+			if num == to {
+				// avoid overflow
+				break
+			}
+		}
+		return res, nil
+	}
+
+	sections := b.bloomIndex.Sections()
+
+	// use the bloom bits for the indexed sections
+	num := from
+	for num <= to && num/sectionSize < sections {
+		section := num / sectionSize
+
+		matches, err := matcher.Match(func(bit uint) ([]byte, error) {
+			bits, ok := b.db.ReadBloomBits(bit, section)
+			if !ok {
+				return nil, fmt.Errorf("bloom bits %d for section %d not found", bit, section)
+			}
+			return bits, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if n := section*sectionSize + match; n >= from && n <= to {
+				res = append(res, n)
+			}
+		}
+		num = (section + 1) * sectionSize
+	}
+
+	// check the blooms one by one for the blocks not indexed yet
+	for ; num <= to; num++ {
+		hash, ok := b.db.ReadCanonicalHash(num)
+		if !ok {
+			break
+		}
+		bloom, ok := b.db.ReadBloom(hash)
+		if !ok || matcher.MatchBloom(bloom) {
+			res = append(res, num)
+		}
+		if num == to {
+			break
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,55 @@
+package blockchain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomIndex(t *testing.T) {
+	addr1 := types.StringToAddress("1")
+	addr2 := types.StringToAddress("2")
+
+	headers := NewTestHeaderChain(30)
+
+	b := NewTestBlockchain(t, nil)
+	b.bloomIndex = b.AddIndexer("test", &bloomIndexer{db: b.db, size: 8}, 8, 2)
+
+	// addr1 logs in blocks 3, 12 and 25, the rest of the blocks are empty
+	for _, h := range headers {
+		var bloom types.Bloom
+		if h.Number == 3 || h.Number == 12 || h.Number == 25 {
+			bloom = types.CreateBloom([]*types.Receipt{{Logs: []*types.Log{{Address: addr1}}}})
+		}
+		assert.NoError(t, b.db.WriteBloom(h.Hash, bloom))
+		assert.NoError(t, b.db.WriteHeader(h))
+	}
+
+	_, err := b.advanceHead(headers[0])
+	assert.NoError(t, err)
+	assert.NoError(t, b.WriteHeaders(headers[1:]))
+
+	// head is 29, with two confirmations only the first three sections are indexed
+	assert.Eventually(t, func() bool {
+		return b.bloomIndex.Sections() == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	matches, err := b.FilterBlooms(0, 29, [][][]byte{{addr1.Bytes()}})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 12, 25}, matches)
+
+	matches, err = b.FilterBlooms(4, 24, [][][]byte{{addr1.Bytes()}})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{12}, matches)
+
+	matches, err = b.FilterBlooms(0, 29, [][][]byte{{addr2.Bytes()}})
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// an empty filter matches all the blocks
+	matches, err = b.FilterBlooms(5, 7, [][][]byte{})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{5, 6, 7}, matches)
+}
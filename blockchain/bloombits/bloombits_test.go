@@ -0,0 +1,111 @@
+package bloombits
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	addr1 = types.StringToAddress("1")
+	addr2 = types.StringToAddress("2")
+	addr3 = types.StringToAddress("3")
+
+	topic1 = types.StringToHash("1")
+	topic2 = types.StringToHash("2")
+)
+
+func bloomWithLogs(logs ...*types.Log) types.Bloom {
+	return types.CreateBloom([]*types.Receipt{{Logs: logs}})
+}
+
+func TestGenerator_SectionSize(t *testing.T) {
+	_, err := NewGenerator(7)
+	assert.Error(t, err)
+
+	gen, err := NewGenerator(8)
+	assert.NoError(t, err)
+
+	// blooms have to be added in order
+	assert.Error(t, gen.AddBloom(1, types.Bloom{}))
+
+	_, err = gen.Bitset(0)
+	assert.Error(t, err)
+
+	for i := uint64(0); i < 8; i++ {
+		assert.NoError(t, gen.AddBloom(i, types.Bloom{}))
+	}
+	assert.Error(t, gen.AddBloom(8, types.Bloom{}))
+
+	_, err = gen.Bitset(BloomBitLength)
+	assert.Error(t, err)
+}
+
+func TestMatcher(t *testing.T) {
+	blooms := []types.Bloom{
+		bloomWithLogs(&types.Log{Address: addr1, Topics: []types.Hash{topic1}}),
+		bloomWithLogs(&types.Log{Address: addr2, Topics: []types.Hash{topic2}}),
+		{},
+		bloomWithLogs(&types.Log{Address: addr1, Topics: []types.Hash{topic2}}),
+		{},
+		{},
+		{},
+		bloomWithLogs(&types.Log{Address: addr2}),
+	}
+
+	gen, err := NewGenerator(uint64(len(blooms)))
+	assert.NoError(t, err)
+
+	for indx, bloom := range blooms {
+		assert.NoError(t, gen.AddBloom(uint64(indx), bloom))
+	}
+
+	cases := []struct {
+		filter  [][][]byte
+		matches []uint64
+	}{
+		{
+			// single address
+			[][][]byte{{addr1.Bytes()}},
+			[]uint64{0, 3},
+		},
+		{
+			// any of the addresses
+			[][][]byte{{addr1.Bytes(), addr2.Bytes()}},
+			[]uint64{0, 1, 3, 7},
+		},
+		{
+			// address and topic
+			[][][]byte{{addr1.Bytes()}, {topic2.Bytes()}},
+			[]uint64{3},
+		},
+		{
+			// wildcard address and topic
+			[][][]byte{{}, {topic2.Bytes()}},
+			[]uint64{1, 3},
+		},
+		{
+			// no match
+			[][][]byte{{addr3.Bytes()}},
+			[]uint64{},
+		},
+	}
+
+	for _, c := range cases {
+		m := NewMatcher(uint64(len(blooms)), c.filter)
+
+		matches, err := m.Match(gen.Bitset)
+		assert.NoError(t, err)
+		assert.Equal(t, c.matches, matches)
+
+		// the single bloom check has to be consistent with the section
+		bloomMatches := []uint64{}
+		for indx, bloom := range blooms {
+			if m.MatchBloom(bloom) {
+				bloomMatches = append(bloomMatches, uint64(indx))
+			}
+		}
+		assert.Equal(t, c.matches, bloomMatches)
+	}
+}
@@ -0,0 +1,73 @@
+package bloombits
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+// BloomBitLength is the number of bits in a log bloom
+const BloomBitLength = 8 * types.BloomByteLength
+
+var (
+	errSectionOutOfBounds  = fmt.Errorf("section out of bounds")
+	errBloomBitOutOfBounds = fmt.Errorf("bloom bit out of bounds")
+)
+
+// Generator rotates a set of block blooms into bit vectors. Each vector
+// holds one bloom bit for all the blocks of a section so they can be
+// matched against a filter without having to read every single bloom
+type Generator struct {
+	blooms [BloomBitLength][]byte
+	size   uint64
+	next   uint64
+}
+
+// NewGenerator creates a generator for a section with the given number of blocks.
+// The size has to be a multiple of 8 so that every block maps to a full byte
+func NewGenerator(size uint64) (*Generator, error) {
+	if size%8 != 0 {
+		return nil, fmt.Errorf("section size not multiple of 8")
+	}
+	b := &Generator{size: size}
+	for i := 0; i < BloomBitLength; i++ {
+		b.blooms[i] = make([]byte, size/8)
+	}
+	return b, nil
+}
+
+// AddBloom adds the bloom of the block at position index inside the section.
+// Blooms have to be added in order
+func (b *Generator) AddBloom(index uint64, bloom types.Bloom) error {
+	if b.next >= b.size {
+		return errSectionOutOfBounds
+	}
+	if b.next != index {
+		return fmt.Errorf("bloom filter with unexpected index %d, expected %d", index, b.next)
+	}
+
+	byteIndx := b.next / 8
+	bitMask := byte(1) << byte(7-b.next%8)
+
+	for i := 0; i < BloomBitLength; i++ {
+		bloomByteIndx := types.BloomByteLength - 1 - i/8
+		bloomBitMask := byte(1) << byte(i%8)
+
+		if (bloom[bloomByteIndx] & bloomBitMask) != 0 {
+			b.blooms[i][byteIndx] |= bitMask
+		}
+	}
+	b.next++
+	return nil
+}
+
+// Bitset returns the bit vector for the given bloom bit once the section is complete
+func (b *Generator) Bitset(idx uint) ([]byte, error) {
+	if b.next != b.size {
+		return nil, fmt.Errorf("bloom not fully generated yet")
+	}
+	if idx >= BloomBitLength {
+		return nil, errBloomBitOutOfBounds
+	}
+	return b.blooms[idx], nil
+}
@@ -0,0 +1,154 @@
+package bloombits
+
+import (
+	"github.com/0xPolygon/minimal/helper/keccak"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// bloomIndexes are the three bloom bits that an item sets in a log bloom
+type bloomIndexes [3]uint
+
+func calcBloomIndexes(data []byte) bloomIndexes {
+	h := keccak.Keccak256(nil, data)
+
+	var idxs bloomIndexes
+	for i := 0; i < len(idxs); i++ {
+		idxs[i] = (uint(h[2*i+1]) + (uint(h[2*i]) << 8)) & (BloomBitLength - 1)
+	}
+	return idxs
+}
+
+// Matcher checks blooms and bloom sections against a log filter.
+// The filter is a list of groups where each group is a list of items
+// (addresses or topics). A bloom matches if, for every group, at least one
+// of its items is present. Empty groups are wildcards and always match
+type Matcher struct {
+	sectionSize uint64
+	filters     [][]bloomIndexes
+}
+
+// NewMatcher creates a matcher for sections of the given size
+func NewMatcher(sectionSize uint64, filters [][][]byte) *Matcher {
+	m := &Matcher{
+		sectionSize: sectionSize,
+		filters:     [][]bloomIndexes{},
+	}
+	for _, group := range filters {
+		if len(group) == 0 {
+			// wildcard
+			continue
+		}
+		idxs := []bloomIndexes{}
+		for _, item := range group {
+			idxs = append(idxs, calcBloomIndexes(item))
+		}
+		m.filters = append(m.filters, idxs)
+	}
+	return m
+}
+
+// Empty returns true if the matcher has no conditions and matches everything
+func (m *Matcher) Empty() bool {
+	return len(m.filters) == 0
+}
+
+// MatchBloom checks whether a single block bloom might contain logs for the filter
+func (m *Matcher) MatchBloom(bloom types.Bloom) bool {
+	for _, group := range m.filters {
+		match := false
+		for _, idxs := range group {
+			if bloomHasIndexes(bloom, idxs) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func bloomHasIndexes(bloom types.Bloom, idxs bloomIndexes) bool {
+	for _, bit := range idxs {
+		if bloom[types.BloomByteLength-1-bit/8]&(byte(1)<<byte(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns the indexes (relative to the start of the section) of the blocks
+// whose blooms might match the filter. fetch retrieves the bit vector
+// of a bloom bit for the section
+func (m *Matcher) Match(fetch func(bit uint) ([]byte, error)) ([]uint64, error) {
+	size := m.sectionSize / 8
+
+	// cache of the bit vectors for this section
+	vectors := map[uint][]byte{}
+	getVector := func(bit uint) ([]byte, error) {
+		if v, ok := vectors[bit]; ok {
+			return v, nil
+		}
+		v, err := fetch(bit)
+		if err != nil {
+			return nil, err
+		}
+		vectors[bit] = v
+		return v, nil
+	}
+
+	res := make([]byte, size)
+	for i := range res {
+		res[i] = 0xff
+	}
+	for _, group := range m.filters {
+		groupRes := make([]byte, size)
+		for _, idxs := range group {
+			itemRes := make([]byte, size)
+			for i := range itemRes {
+				itemRes[i] = 0xff
+			}
+			for _, bit := range idxs {
+				v, err := getVector(bit)
+				if err != nil {
+					return nil, err
+				}
+				andBytes(itemRes, v)
+			}
+			orBytes(groupRes, itemRes)
+		}
+		andBytes(res, groupRes)
+	}
+
+	matches := []uint64{}
+	for i, b := range res {
+		if b == 0 {
+			continue
+		}
+		for j := 0; j < 8; j++ {
+			if b&(byte(1)<<byte(7-j)) != 0 {
+				matches = append(matches, uint64(i*8+j))
+			}
+		}
+	}
+	return matches, nil
+}
+
+func andBytes(dst, src []byte) {
+	for i := range dst {
+		if i < len(src) {
+			dst[i] &= src[i]
+		} else {
+			dst[i] = 0
+		}
+	}
+}
+
+func orBytes(dst, src []byte) {
+	for i := range dst {
+		if i < len(src) {
+			dst[i] |= src[i]
+		}
+	}
+}
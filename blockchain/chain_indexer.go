@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// ChainIndexerBackend builds a derived index from sections of the canonical chain
+type ChainIndexerBackend interface {
+	// Reset starts a new section. prevHead is the hash of the last block
+	// of the previous section (zero for the first one)
+	Reset(section uint64, prevHead types.Hash) error
+
+	// Process adds a canonical header of the section. Headers are processed in order
+	Process(header *types.Header) error
+
+	// Commit writes the processed section to storage
+	Commit() error
+}
+
+// ChainIndexer follows the blockchain events and feeds the canonical chain to a
+// backend in sections of a fixed size. A section is processed once it has enough
+// confirmations and its head hash is stored as a checkpoint, so the progress
+// survives restarts and the sections affected by a reorg are processed again.
+type ChainIndexer struct {
+	logger  hclog.Logger
+	b       *Blockchain
+	name    string
+	backend ChainIndexerBackend
+
+	sectionSize   uint64
+	confirmations uint64
+
+	// number of sections processed
+	sections uint64
+	lock     sync.RWMutex
+
+	subscription Subscription
+	doneCh       chan struct{}
+}
+
+// AddIndexer registers a chain indexer. The indexer starts processing the chain
+// once the genesis is computed, or right away if the chain is already initialized
+func (b *Blockchain) AddIndexer(name string, backend ChainIndexerBackend, sectionSize, confirmations uint64) *ChainIndexer {
+	c := &ChainIndexer{
+		logger:        b.logger.Named("indexer-" + name),
+		b:             b,
+		name:          name,
+		backend:       backend,
+		sectionSize:   sectionSize,
+		confirmations: confirmations,
+	}
+
+	b.indexersLock.Lock()
+	defer b.indexersLock.Unlock()
+
+	b.indexers = append(b.indexers, c)
+	if b.indexersRunning {
+		c.start()
+	}
+	return c
+}
+
+func (b *Blockchain) startIndexers() {
+	b.indexersLock.Lock()
+	defer b.indexersLock.Unlock()
+
+	if b.indexersRunning {
+		return
+	}
+	b.indexersRunning = true
+	for _, c := range b.indexers {
+		c.start()
+	}
+}
+
+func (b *Blockchain) closeIndexers() {
+	b.indexersLock.Lock()
+	defer b.indexersLock.Unlock()
+
+	if !b.indexersRunning {
+		return
+	}
+	b.indexersRunning = false
+	for _, c := range b.indexers {
+		c.close()
+	}
+}
+
+// Sections returns the number of sections processed
+func (c *ChainIndexer) Sections() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.sections
+}
+
+// SectionSize returns the number of blocks in a section
+func (c *ChainIndexer) SectionSize() uint64 {
+	return c.sectionSize
+}
+
+func (c *ChainIndexer) start() {
+	c.load()
+
+	c.subscription = c.b.SubscribeEvents()
+	c.doneCh = make(chan struct{})
+
+	go c.run()
+}
+
+func (c *ChainIndexer) close() {
+	c.subscription.Close()
+	<-c.doneCh
+}
+
+func (c *ChainIndexer) run() {
+	defer close(c.doneCh)
+
+	// catch up with the current head
+	c.update(nil)
+
+	for {
+		evnt := c.subscription.GetEvent()
+		if evnt == nil {
+			return
+		}
+		c.update(evnt)
+	}
+}
+
+// load recovers the processed sections from storage. A section is only
+// valid if its head is still part of the canonical chain
+func (c *ChainIndexer) load() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sections = 0
+	for {
+		head, ok := c.b.db.ReadIndexSectionHead(c.name, c.sections)
+		if !ok {
+			break
+		}
+		hash, ok := c.b.db.ReadCanonicalHash((c.sections+1)*c.sectionSize - 1)
+		if !ok || hash != head {
+			break
+		}
+		c.sections++
+	}
+}
+
+// update rewinds the sections affected by a reorg and processes
+// any new section that has enough confirmations
+func (c *ChainIndexer) update(evnt *Event) {
+	if evnt != nil && evnt.Type == EventFork {
+		// the canonical chain does not change
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if evnt != nil && evnt.Type == EventReorg {
+		for _, h := range evnt.OldChain {
+			if section := h.Number / c.sectionSize; section < c.sections {
+				c.sections = section
+			}
+		}
+	}
+
+	head := c.b.Header().Number
+	for (c.sections+1)*c.sectionSize+c.confirmations <= head+1 {
+		if err := c.processSection(c.sections); err != nil {
+			c.logger.Error("failed to process section", "section", c.sections, "err", err)
+			return
+		}
+		c.sections++
+	}
+}
+
+func (c *ChainIndexer) processSection(section uint64) error {
+	var prevHead types.Hash
+	if section != 0 {
+		var ok bool
+		if prevHead, ok = c.b.db.ReadIndexSectionHead(c.name, section-1); !ok {
+			return fmt.Errorf("head of section %d not found", section-1)
+		}
+	}
+	if err := c.backend.Reset(section, prevHead); err != nil {
+		return err
+	}
+
+	var head types.Hash
+	for num := section * c.sectionSize; num < (section+1)*c.sectionSize; num++ {
+		hash, ok := c.b.db.ReadCanonicalHash(num)
+		if !ok {
+			return fmt.Errorf("canonical hash for %d not found", num)
+		}
+		header, err := c.b.db.ReadHeader(hash)
+		if err != nil {
+			return fmt.Errorf("header %s not found: %v", hash, err)
+		}
+		// the hash is not part of the stored header
+		header.Hash = hash
+
+		if err := c.backend.Process(header); err != nil {
+			return err
+		}
+		head = hash
+	}
+	if err := c.backend.Commit(); err != nil {
+		return err
+	}
+
+	// the head is written last, it marks the section as complete
+	return c.b.db.WriteIndexSectionHead(c.name, section, head)
+}
@@ -0,0 +1,144 @@
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockIndexerBackend struct {
+	lock sync.Mutex
+
+	section  uint64
+	prevHead types.Hash
+	headers  []*types.Header
+
+	// committed sections
+	sections map[uint64][]types.Hash
+}
+
+func newMockIndexerBackend() *mockIndexerBackend {
+	return &mockIndexerBackend{sections: map[uint64][]types.Hash{}}
+}
+
+func (m *mockIndexerBackend) Reset(section uint64, prevHead types.Hash) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.section, m.prevHead, m.headers = section, prevHead, nil
+	return nil
+}
+
+func (m *mockIndexerBackend) Process(header *types.Header) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(m.headers) != 0 && m.headers[len(m.headers)-1].Hash != header.ParentHash {
+		return fmt.Errorf("headers out of order")
+	}
+	m.headers = append(m.headers, header)
+	return nil
+}
+
+func (m *mockIndexerBackend) Commit() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	hashes := []types.Hash{}
+	for _, h := range m.headers {
+		hashes = append(hashes, h.Hash)
+	}
+	m.sections[m.section] = hashes
+	return nil
+}
+
+func (m *mockIndexerBackend) get(section uint64) []types.Hash {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.sections[section]
+}
+
+func waitSections(t *testing.T, c *ChainIndexer, sections uint64) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		return c.Sections() == sections
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func newIndexerTestChain(t *testing.T, headers []*types.Header) *Blockchain {
+	b := NewTestBlockchain(t, nil)
+
+	// the indexer reads the full headers from storage
+	assert.NoError(t, b.db.WriteHeader(headers[0]))
+
+	_, err := b.advanceHead(headers[0])
+	assert.NoError(t, err)
+	return b
+}
+
+func TestChainIndexer(t *testing.T) {
+	h0 := NewTestHeaderChain(20)
+
+	b := newIndexerTestChain(t, h0)
+
+	backend := newMockIndexerBackend()
+	c := b.AddIndexer("test", backend, 4, 1)
+
+	assert.NoError(t, b.WriteHeaders(h0[1:]))
+
+	// head is 19, the last section [16, 19] does not have enough confirmations
+	waitSections(t, c, 4)
+
+	for i := uint64(0); i < 4; i++ {
+		hashes := []types.Hash{}
+		for _, h := range h0[i*4 : i*4+4] {
+			hashes = append(hashes, h.Hash)
+		}
+		assert.Equal(t, hashes, backend.get(i))
+	}
+
+	// the previous head points to the last block of the previous section
+	assert.Equal(t, h0[11].Hash, backend.prevHead)
+
+	// a reorg from block 10 on rewinds and processes the affected sections again
+	h1 := NewTestHeaderFromChainWithSeed(h0[:10], 12, 1)
+	assert.NoError(t, b.WriteHeaders(h1[10:]))
+	assert.Equal(t, h1[21].Hash, b.Header().Hash)
+
+	assert.Eventually(t, func() bool {
+		hashes := backend.get(2)
+		return len(hashes) == 4 && hashes[3] == h1[11].Hash
+	}, 2*time.Second, 10*time.Millisecond)
+
+	waitSections(t, c, 5)
+
+	// the progress is recovered from the checkpoints on restart
+	b.closeIndexers()
+	b.startIndexers()
+
+	assert.Equal(t, uint64(5), c.Sections())
+}
+
+func TestChainIndexer_LoadCheckpoints(t *testing.T) {
+	h0 := NewTestHeaderChain(10)
+
+	b := newIndexerTestChain(t, h0)
+	c := b.AddIndexer("test", newMockIndexerBackend(), 4, 0)
+
+	assert.NoError(t, b.WriteHeaders(h0[1:]))
+	waitSections(t, c, 2)
+
+	b.closeIndexers()
+
+	// the head of the second section is not canonical anymore
+	assert.NoError(t, b.db.WriteCanonicalHash(7, types.StringToHash("1")))
+
+	c.load()
+	assert.Equal(t, uint64(1), c.Sections())
+}
@@ -0,0 +1,134 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+)
+
+// StorageMode selects the historical data of the canonical chain kept in storage.
+// The modes only prune the blocks data, the state trie is not pruned in any mode
+// and it is most of the storage, so the full and light modes save little space
+type StorageMode string
+
+const (
+	// StorageModeArchive keeps all the data
+	StorageModeArchive StorageMode = "archive"
+
+	// StorageModeFull removes the receipts of the blocks below the retention depth,
+	// the state is kept
+	StorageModeFull StorageMode = "full"
+
+	// StorageModeLight removes the receipts, the bodies and the transaction
+	// lookups of the blocks below the retention depth, only the headers and the
+	// state are kept
+	StorageModeLight StorageMode = "light"
+)
+
+// DefaultHistoryRetention is the number of blocks for which the history is kept
+// in the full and light modes, about two days with blocks every two seconds
+const DefaultHistoryRetention = 90000
+
+// historyPruneLimit is the maximum number of blocks pruned after a header is
+// written, so that switching on a long chain does not stall the writes
+const historyPruneLimit = 1024
+
+// ParseStorageMode parses a storage mode, the empty string is the archive mode
+func ParseStorageMode(s string) (StorageMode, error) {
+	switch mode := StorageMode(s); mode {
+	case "":
+		return StorageModeArchive, nil
+	case StorageModeArchive, StorageModeFull, StorageModeLight:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown storage mode '%s'", s)
+	}
+}
+
+// setupMode validates the configured mode against the one recorded in storage.
+// The state trie is never pruned, so it is kept in all the modes for now
+func (b *Blockchain) setupMode() error {
+	mode, err := ParseStorageMode(string(b.mode))
+	if err != nil {
+		return err
+	}
+	b.mode = mode
+	if b.historyRetention == 0 {
+		b.historyRetention = DefaultHistoryRetention
+	}
+
+	current, ok := b.db.ReadMode()
+	if !ok {
+		if _, ok := b.db.ReadHeadHash(); !ok {
+			// new storage
+			return b.db.WriteMode(string(b.mode))
+		}
+		// the storages created before the modes were recorded keep all the data
+		current = string(StorageModeArchive)
+		if err := b.db.WriteMode(current); err != nil {
+			return err
+		}
+	}
+	if current != string(b.mode) {
+		// the pruned data cannot be recovered, and leaving the archive mode
+		// would make the data dir unusable as an archive, use a new data dir
+		return fmt.Errorf("storage was created in mode %s, it cannot be opened in mode %s", current, b.mode)
+	}
+	return nil
+}
+
+// StateHistory returns the number of blocks below the head whose state is
+// served, zero if the state of all the blocks is. The state is kept in all the
+// modes but only the archive mode serves it below the retention depth
+func (b *Blockchain) StateHistory() uint64 {
+	if b.mode == StorageModeArchive {
+		return 0
+	}
+	return b.historyRetention
+}
+
+// pruneHistory removes the data of the canonical blocks below the retention depth
+// that the storage mode does not keep. The progress is stored as the history tail
+func (b *Blockchain) pruneHistory() {
+	if b.mode == StorageModeArchive {
+		return
+	}
+	head := b.Header().Number
+	if head < b.historyRetention {
+		return
+	}
+
+	tail, _ := b.db.ReadHistoryTail()
+	limit := head - b.historyRetention
+	if tail >= limit {
+		return
+	}
+	if limit-tail > historyPruneLimit {
+		limit = tail + historyPruneLimit
+	}
+
+	batch := storage.NewBatchWriter(b.db)
+	for num := tail; num < limit; num++ {
+		hash, ok := b.db.ReadCanonicalHash(num)
+		if !ok {
+			continue
+		}
+		batch.DeleteReceipts(hash)
+
+		if b.mode == StorageModeLight {
+			if body, err := b.db.ReadBody(hash); err == nil {
+				for _, txn := range body.Transactions {
+					if blockHash, ok := b.db.ReadTxLookup(txn.Hash); ok && blockHash == hash {
+						batch.DeleteTxLookup(txn.Hash)
+					}
+				}
+			}
+			batch.DeleteBody(hash)
+		}
+	}
+	batch.PutHistoryTail(limit)
+
+	if err := batch.Commit(); err != nil {
+		b.logger.Error("failed to prune the history", "err", err)
+	}
+}
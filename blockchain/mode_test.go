@@ -0,0 +1,123 @@
+package blockchain
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/blockchain/storage/memory"
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageMode(t *testing.T) {
+	path, err := ioutil.TempDir("/tmp", "minimal_mode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	open := func(mode StorageMode) (*Blockchain, error) {
+		config := &chain.Chain{Genesis: &chain.Genesis{}}
+		return NewBlockchain(hclog.NewNullLogger(), path, &StorageConfig{Mode: mode}, config, &MockVerifier{}, &mockExecutor{})
+	}
+
+	b, err := open(StorageModeFull)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(DefaultHistoryRetention), b.StateHistory())
+	assert.NoError(t, b.Close())
+
+	_, err = open(StorageModeArchive)
+	assert.Error(t, err)
+
+	b, err = open(StorageModeFull)
+	assert.NoError(t, err)
+	assert.NoError(t, b.Close())
+
+	_, err = open("unknown")
+	assert.Error(t, err)
+}
+
+func TestStorageModeUnrecorded(t *testing.T) {
+	db, _ := memory.NewMemoryStorage(hclog.NewNullLogger())
+	assert.NoError(t, db.WriteHeadHash(types.StringToHash("1")))
+
+	// an existing storage without a mode was created as an archive
+	b := &Blockchain{db: db, mode: StorageModeLight}
+	assert.Error(t, b.setupMode())
+
+	b = &Blockchain{db: db}
+	assert.NoError(t, b.setupMode())
+	assert.Equal(t, uint64(0), b.StateHistory())
+
+	mode, ok := db.ReadMode()
+	assert.True(t, ok)
+	assert.Equal(t, string(StorageModeArchive), mode)
+}
+
+func TestPruneHistory(t *testing.T) {
+	headers := NewTestHeaderChain(20)
+
+	txn := func(h *types.Header) *types.Transaction {
+		txn := &types.Transaction{Value: big.NewInt(1), Input: h.Hash.Bytes(), V: 1}
+		txn.ComputeHash()
+		return txn
+	}
+
+	newChain := func(t *testing.T, mode StorageMode) *Blockchain {
+		b := NewTestBlockchain(t, headers)
+		b.mode, b.historyRetention = mode, 5
+
+		for _, h := range headers {
+			block := &types.Block{Header: h, Transactions: []*types.Transaction{txn(h)}}
+
+			batch := storage.NewBatchWriter(b.db)
+			b.writeBody(batch, block)
+			batch.PutReceipts(h.Hash, []*types.Receipt{{GasUsed: 1}})
+			assert.NoError(t, batch.Commit())
+		}
+		return b
+	}
+
+	// the blocks below 14 are pruned with the head at 19
+	check := func(t *testing.T, b *Blockchain, bodies bool) {
+		for _, h := range headers {
+			pruned := h.Number < 14
+
+			_, err := b.db.ReadReceipts(h.Hash)
+			assert.Equal(t, pruned, err != nil)
+
+			_, err = b.db.ReadBody(h.Hash)
+			assert.Equal(t, pruned && !bodies, err != nil)
+
+			_, ok := b.db.ReadTxLookup(txn(h).Hash)
+			assert.Equal(t, !pruned || bodies, ok)
+		}
+		tail, _ := b.db.ReadHistoryTail()
+		assert.Equal(t, uint64(14), tail)
+	}
+
+	t.Run("Full", func(t *testing.T) {
+		b := newChain(t, StorageModeFull)
+		b.pruneHistory()
+		check(t, b, true)
+	})
+
+	t.Run("Light", func(t *testing.T) {
+		b := newChain(t, StorageModeLight)
+		b.pruneHistory()
+		check(t, b, false)
+	})
+
+	t.Run("Archive", func(t *testing.T) {
+		b := newChain(t, StorageModeArchive)
+		b.pruneHistory()
+
+		_, err := b.db.ReadReceipts(headers[0].Hash)
+		assert.NoError(t, err)
+	})
+}
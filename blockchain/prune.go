@@ -0,0 +1,88 @@
+package blockchain
+
+import (
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// prune removes the data that is not retained anymore after a header is written
+func (b *Blockchain) prune() {
+	b.pruneForks()
+	b.pruneHistory()
+}
+
+// pruneForks removes the non canonical blocks that are more than forkRetention
+// blocks below the head. It runs after every header is written, the blocks to
+// remove are read in order from the index so it only visits the old ones
+func (b *Blockchain) pruneForks() {
+	head := b.Header().Number
+	if b.forkRetention == 0 || head < b.forkRetention {
+		return
+	}
+
+	blocks, err := storage.ReadNonCanonicalBlocks(b.db, head-b.forkRetention)
+	if err != nil {
+		b.logger.Error("failed to read the non canonical blocks", "err", err)
+		return
+	}
+	if len(blocks) == 0 {
+		return
+	}
+
+	batch := storage.NewBatchWriter(b.db)
+	pruned := map[types.Hash]struct{}{}
+	for _, block := range blocks {
+		batch.DeleteNonCanonical(block.Number, block.Hash)
+
+		// the block became canonical after a reorg
+		if hash, ok := b.db.ReadCanonicalHash(block.Number); ok && hash == block.Hash {
+			continue
+		}
+		b.deleteBlock(batch, block.Hash)
+		pruned[block.Hash] = struct{}{}
+	}
+
+	forks, err := b.db.ReadForks()
+	if err == nil {
+		newForks := []types.Hash{}
+		for _, fork := range forks {
+			if _, ok := pruned[fork]; !ok {
+				newForks = append(newForks, fork)
+			}
+		}
+		if len(newForks) != len(forks) {
+			batch.PutForks(newForks)
+		}
+	} else if err != storage.ErrNotFound {
+		b.logger.Error("failed to read the forks", "err", err)
+		return
+	}
+
+	if err := batch.Commit(); err != nil {
+		b.logger.Error("failed to prune the non canonical blocks", "err", err)
+		return
+	}
+	for hash := range pruned {
+		b.uncacheBlock(hash)
+	}
+	if len(pruned) != 0 {
+		b.logger.Debug("pruned non canonical blocks", "num", len(pruned))
+	}
+}
+
+// deleteBlock adds to the batch the removal of all the entries of a block
+func (b *Blockchain) deleteBlock(batch *storage.BatchWriter, hash types.Hash) {
+	if body, err := b.db.ReadBody(hash); err == nil {
+		for _, txn := range body.Transactions {
+			// the transaction is usually included in a canonical block too
+			if blockHash, ok := b.db.ReadTxLookup(txn.Hash); ok && blockHash == hash {
+				batch.DeleteTxLookup(txn.Hash)
+			}
+		}
+	}
+	batch.DeleteHeader(hash)
+	batch.DeleteDiff(hash)
+	batch.DeleteBody(hash)
+	batch.DeleteReceipts(hash)
+	batch.DeleteBloom(hash)
+}
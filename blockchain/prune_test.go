@@ -0,0 +1,91 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneForks(t *testing.T) {
+	h0 := NewTestHeaderChain(20)
+
+	exists := func(b *Blockchain, h *types.Header) bool {
+		_, err := b.db.ReadHeader(h.Hash)
+		return err == nil
+	}
+
+	t.Run("Fork", func(t *testing.T) {
+		b := NewTestBlockchain(t, h0[:10])
+		b.forkRetention = 5
+
+		// fork from block 5 with a lower difficulty than the head
+		fork := NewTestHeaderFromChainWithSeed(h0[:6], 3, 1)
+		assert.NoError(t, b.WriteHeaders(fork[6:]))
+		assert.Equal(t, h0[9].Hash, b.Header().Hash)
+
+		forks, err := b.GetForks()
+		assert.NoError(t, err)
+		assert.Contains(t, forks, fork[8].Hash)
+
+		// head at 11, the fork blocks are still within the retention
+		assert.NoError(t, b.WriteHeaders(h0[10:12]))
+		for _, h := range fork[6:] {
+			assert.True(t, exists(b, h))
+		}
+
+		// head at 19, the fork blocks are more than 5 blocks below
+		assert.NoError(t, b.WriteHeaders(h0[12:]))
+		for _, h := range fork[6:] {
+			assert.False(t, exists(b, h))
+		}
+
+		forks, err = b.GetForks()
+		assert.NoError(t, err)
+		assert.NotContains(t, forks, fork[8].Hash)
+
+		blocks, err := storage.ReadNonCanonicalBlocks(b.db, 100)
+		assert.NoError(t, err)
+		assert.Empty(t, blocks)
+	})
+
+	t.Run("Reorg", func(t *testing.T) {
+		b := NewTestBlockchain(t, h0[:10])
+		b.forkRetention = 5
+
+		// the new chain from block 5 becomes canonical at block 10
+		h1 := NewTestHeaderFromChainWithSeed(h0[:5], 10, 2)
+		assert.NoError(t, b.WriteHeaders(h1[5:]))
+		assert.Equal(t, h1[14].Hash, b.Header().Hash)
+
+		// the blocks of the new chain were indexed as forks but they are canonical now
+		for _, h := range h1[5:] {
+			assert.True(t, exists(b, h))
+		}
+		for _, h := range h0[5:9] {
+			assert.False(t, exists(b, h))
+		}
+		assert.True(t, exists(b, h0[9]))
+
+		// the entries from block 9 on are still indexed
+		blocks, err := storage.ReadNonCanonicalBlocks(b.db, 100)
+		assert.NoError(t, err)
+		assert.Contains(t, blocks, storage.NonCanonicalBlock{Number: 9, Hash: h0[9].Hash})
+		for _, block := range blocks {
+			assert.GreaterOrEqual(t, block.Number, uint64(9))
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		b := NewTestBlockchain(t, h0[:10])
+
+		fork := NewTestHeaderFromChainWithSeed(h0[:6], 3, 1)
+		assert.NoError(t, b.WriteHeaders(fork[6:]))
+		assert.NoError(t, b.WriteHeaders(h0[10:]))
+
+		for _, h := range fork[6:] {
+			assert.True(t, exists(b, h))
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// loadHead validates that the head entries in storage (head hash, head number,
+// canonical hash and difficulty) are consistent with each other. If they are not,
+// the chain is rewound to the highest canonical block with all its entries
+// and the head is rewritten to point to it.
+func (b *Blockchain) loadHead() (*types.Header, *big.Int, error) {
+	if header, diff, ok := b.checkHead(); ok {
+		return header, diff, nil
+	}
+
+	// find the number from which to start the search
+	num, ok := b.db.ReadHeadNumber()
+	if !ok {
+		if hash, ok := b.db.ReadHeadHash(); ok {
+			if header, err := b.db.ReadHeader(hash); err == nil {
+				num, ok = header.Number, true
+			}
+		}
+	}
+	if !ok {
+		// walk the canonical index up to the last entry
+		num = 0
+		for {
+			if _, ok := b.db.ReadCanonicalHash(num + 1); !ok {
+				break
+			}
+			num++
+		}
+	}
+
+	for {
+		if header, diff, ok := b.checkCanonical(num); ok {
+			b.logger.Warn("inconsistent chain head, rewinding", "number", header.Number, "hash", header.Hash)
+
+			if err := b.db.WriteHeadHash(header.Hash); err != nil {
+				return nil, nil, err
+			}
+			if err := b.db.WriteHeadNumber(header.Number); err != nil {
+				return nil, nil, err
+			}
+			return header, diff, nil
+		}
+		if num == 0 {
+			return nil, nil, fmt.Errorf("failed to find a consistent block in the chain")
+		}
+		num--
+	}
+}
+
+// checkHead checks that the current head entries point to the same canonical block
+func (b *Blockchain) checkHead() (*types.Header, *big.Int, bool) {
+	hash, ok := b.db.ReadHeadHash()
+	if !ok {
+		return nil, nil, false
+	}
+	num, ok := b.db.ReadHeadNumber()
+	if !ok {
+		return nil, nil, false
+	}
+	header, diff, ok := b.checkCanonical(num)
+	if !ok || header.Hash != hash {
+		return nil, nil, false
+	}
+	return header, diff, true
+}
+
+// checkCanonical checks that the canonical block at the given number has a header
+// with that number, a difficulty and a parent that is canonical too
+func (b *Blockchain) checkCanonical(num uint64) (*types.Header, *big.Int, bool) {
+	hash, ok := b.db.ReadCanonicalHash(num)
+	if !ok {
+		return nil, nil, false
+	}
+	header, ok := b.readHeader(hash)
+	if !ok || header.Number != num {
+		return nil, nil, false
+	}
+	diff, ok := b.readDiff(hash)
+	if !ok {
+		return nil, nil, false
+	}
+	if num != 0 {
+		parent, ok := b.db.ReadCanonicalHash(num - 1)
+		if !ok || parent != header.ParentHash {
+			return nil, nil, false
+		}
+		if _, ok := b.readDiff(parent); !ok {
+			return nil, nil, false
+		}
+	}
+	return header, diff, true
+}
+
+// recoverPendingBlock handles a block whose write was interrupted. The body, the
+// bloom and the pending mark are written in a single batch before the header,
+// and the mark is removed with the receipts. If the header is not in storage the
+// block is rolled back, otherwise it is processed again to write its receipts
+func (b *Blockchain) recoverPendingBlock() error {
+	hash, ok := b.db.ReadPendingBlock()
+	if !ok {
+		return nil
+	}
+	body, ok := b.readBody(hash)
+	if !ok {
+		return fmt.Errorf("body of the pending block %s not found", hash)
+	}
+
+	batch := storage.NewBatchWriter(b.db)
+
+	header, ok := b.readHeader(hash)
+	if !ok {
+		b.logger.Warn("rolling back partially written block", "hash", hash)
+
+		batch.DeleteBody(hash)
+		batch.DeleteBloom(hash)
+		for _, txn := range body.Transactions {
+			// the transaction might be included in another block too
+			if blockHash, ok := b.db.ReadTxLookup(txn.Hash); ok && blockHash == hash {
+				batch.DeleteTxLookup(txn.Hash)
+			}
+		}
+	} else {
+		b.logger.Warn("completing partially written block", "number", header.Number, "hash", hash)
+
+		block := &types.Block{
+			Header:       header,
+			Transactions: body.Transactions,
+			Uncles:       body.Uncles,
+		}
+		res, err := b.processBlock(block)
+		if err != nil {
+			return fmt.Errorf("failed to process the pending block %s: %v", hash, err)
+		}
+		batch.PutReceipts(hash, res.Receipts)
+	}
+
+	batch.DeletePendingBlock()
+	return batch.Commit()
+}
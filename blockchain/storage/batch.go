@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"math/big"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/umbracle/fastrlp"
+)
+
+// Batch is a set of writes that are applied atomically on Commit
+type Batch interface {
+	Put(k []byte, v []byte)
+	Delete(k []byte)
+	Commit() error
+}
+
+// BatchWriter encodes the blockchain objects into a batch, so that
+// several of them can be written to storage at once
+type BatchWriter struct {
+	batch Batch
+}
+
+// NewBatchWriter creates a batch writer on a new batch of the storage
+func NewBatchWriter(s Storage) *BatchWriter {
+	return &BatchWriter{batch: s.NewBatch()}
+}
+
+// PutHeader adds the header to the batch
+func (b *BatchWriter) PutHeader(h *types.Header) {
+	b.put(HEADER, h.Hash.Bytes(), marshalRLP(h))
+}
+
+// PutCanonicalHeader adds the header, its difficulty and the entries
+// that make it the head of the canonical chain
+func (b *BatchWriter) PutCanonicalHeader(h *types.Header, diff *big.Int) {
+	b.PutHeader(h)
+	b.PutHeadHash(h.Hash)
+	b.PutHeadNumber(h.Number)
+	b.PutCanonicalHash(h.Number, h.Hash)
+	b.PutDiff(h.Hash, diff)
+}
+
+// PutHeadHash adds the hash of the head
+func (b *BatchWriter) PutHeadHash(h types.Hash) {
+	b.put(HEAD, HASH, h.Bytes())
+}
+
+// PutHeadNumber adds the number of the head
+func (b *BatchWriter) PutHeadNumber(n uint64) {
+	b.put(HEAD, NUMBER, encodeUint(n))
+}
+
+// PutCanonicalHash adds the hash for a number in the canonical chain
+func (b *BatchWriter) PutCanonicalHash(n uint64, hash types.Hash) {
+	b.put(CANONICAL, encodeUint(n), hash.Bytes())
+}
+
+// DeleteCanonicalHash removes the hash of a number in the canonical chain
+func (b *BatchWriter) DeleteCanonicalHash(n uint64) {
+	b.delete(CANONICAL, encodeUint(n))
+}
+
+// PutDiff adds the total difficulty of a block
+func (b *BatchWriter) PutDiff(hash types.Hash, diff *big.Int) {
+	b.put(DIFFICULTY, hash.Bytes(), diff.Bytes())
+}
+
+// PutBody adds the body of a block
+func (b *BatchWriter) PutBody(hash types.Hash, body *types.Body) {
+	b.put(BODY, hash.Bytes(), marshalRLP(body))
+}
+
+// PutReceipts adds the receipts of a block
+func (b *BatchWriter) PutReceipts(hash types.Hash, receipts []*types.Receipt) {
+	rr := types.Receipts(receipts)
+	b.put(RECEIPTS, hash.Bytes(), marshalRLP(&rr))
+}
+
+// PutTxLookup adds the block in which a transaction is included
+func (b *BatchWriter) PutTxLookup(hash types.Hash, blockHash types.Hash) {
+	ar := &fastrlp.Arena{}
+	b.put(TX_LOOKUP_PREFIX, hash.Bytes(), ar.NewBytes(blockHash.Bytes()).MarshalTo(nil))
+}
+
+// PutBloom adds the log bloom of a block
+func (b *BatchWriter) PutBloom(hash types.Hash, bloom types.Bloom) {
+	b.put(BLOOM, hash.Bytes(), bloom[:])
+}
+
+// PutForks adds the current forks
+func (b *BatchWriter) PutForks(forks []types.Hash) {
+	ff := Forks(forks)
+	b.put(FORK, EMPTY, marshalRLP(&ff))
+}
+
+// PutNonCanonical adds a block to the index of non canonical blocks
+func (b *BatchWriter) PutNonCanonical(n uint64, hash types.Hash) {
+	b.put(NON_CANONICAL, encodeNonCanonicalKey(n, hash), []byte{})
+}
+
+// DeleteNonCanonical removes a block from the index of non canonical blocks
+func (b *BatchWriter) DeleteNonCanonical(n uint64, hash types.Hash) {
+	b.delete(NON_CANONICAL, encodeNonCanonicalKey(n, hash))
+}
+
+// DeleteHeader removes the header of a block
+func (b *BatchWriter) DeleteHeader(hash types.Hash) {
+	b.delete(HEADER, hash.Bytes())
+}
+
+// DeleteDiff removes the total difficulty of a block
+func (b *BatchWriter) DeleteDiff(hash types.Hash) {
+	b.delete(DIFFICULTY, hash.Bytes())
+}
+
+// DeleteReceipts removes the receipts of a block
+func (b *BatchWriter) DeleteReceipts(hash types.Hash) {
+	b.delete(RECEIPTS, hash.Bytes())
+}
+
+// DeleteBody removes the body of a block
+func (b *BatchWriter) DeleteBody(hash types.Hash) {
+	b.delete(BODY, hash.Bytes())
+}
+
+// DeleteTxLookup removes the block in which a transaction is included
+func (b *BatchWriter) DeleteTxLookup(hash types.Hash) {
+	b.delete(TX_LOOKUP_PREFIX, hash.Bytes())
+}
+
+// DeleteBloom removes the log bloom of a block
+func (b *BatchWriter) DeleteBloom(hash types.Hash) {
+	b.delete(BLOOM, hash.Bytes())
+}
+
+// PutPendingBlock marks the block as being written. It is added with the
+// first entries of the block and removed with the last ones, so that a
+// block whose write was interrupted can be found on restart
+func (b *BatchWriter) PutPendingBlock(hash types.Hash) {
+	b.put(PENDING, EMPTY, hash.Bytes())
+}
+
+// DeletePendingBlock removes the mark of the block being written
+func (b *BatchWriter) DeletePendingBlock() {
+	b.delete(PENDING, EMPTY)
+}
+
+// PutHistoryTail adds the number of the first block whose history is kept
+func (b *BatchWriter) PutHistoryTail(n uint64) {
+	b.put(HISTORY, TAIL, encodeUint(n))
+}
+
+// Commit writes the batch to storage
+func (b *BatchWriter) Commit() error {
+	return b.batch.Commit()
+}
+
+func (b *BatchWriter) put(p []byte, k []byte, v []byte) {
+	b.batch.Put(key(p, k), v)
+}
+
+func (b *BatchWriter) delete(p []byte, k []byte) {
+	b.batch.Delete(key(p, k))
+}
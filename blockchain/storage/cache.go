@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// DefaultCacheSizes are the number of entries cached per namespace
+// for the namespaces that are read on every block. The blockchain
+// caches the headers and the difficulties decoded instead
+var DefaultCacheSizes = map[string]int{
+	"headers":      2048,
+	"difficulties": 2048,
+	"canonical":    2048,
+	"bodies":       256,
+	"receipts":     256,
+	"tx_lookups":   1024,
+}
+
+// setupCache adds a read-through cache of the values of the kv database of the
+// storage, with an lru of the given number of entries for each namespace. The
+// cache is above the encryption, so the cached values are already decrypted
+func setupCache(s Storage, sizes map[string]int) (Storage, error) {
+	kv, ok := s.(*KeyValueStorage)
+	if !ok {
+		return s, nil
+	}
+
+	caches := map[string]*lru.Cache{}
+	for name, size := range sizes {
+		if _, ok := LookupNamespace(name); !ok {
+			return nil, fmt.Errorf("cache for unknown namespace '%s'", name)
+		}
+		if size < 0 {
+			return nil, fmt.Errorf("negative cache size %d for namespace '%s'", size, name)
+		}
+		if size == 0 {
+			continue
+		}
+		cache, err := lru.New(size)
+		if err != nil {
+			return nil, err
+		}
+		caches[name] = cache
+	}
+	if len(caches) == 0 {
+		return s, nil
+	}
+	return NewKeyValueStorage(kv.logger, &cacheKV{db: kv.db, caches: caches}), nil
+}
+
+// cacheKV caches the values read from a kv database. The writes and the
+// committed batches update the cache, the iterators read the database directly
+type cacheKV struct {
+	db     KV
+	caches map[string]*lru.Cache
+
+	// gen increases on every write, a value read from the database is
+	// not cached if there were writes while it was being read
+	gen  uint64
+	lock sync.Mutex
+}
+
+func (c *cacheKV) cache(k []byte) *lru.Cache {
+	return c.caches[namespaceName(k)]
+}
+
+func (c *cacheKV) Set(k []byte, v []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.db.Set(k, v); err != nil {
+		// the value in the database is unknown
+		if cache := c.cache(k); cache != nil {
+			cache.Remove(string(k))
+		}
+		return err
+	}
+	c.gen++
+	if cache := c.cache(k); cache != nil {
+		cache.Add(string(k), copyBytes(v))
+	}
+	return nil
+}
+
+func (c *cacheKV) Get(k []byte) ([]byte, bool, error) {
+	cache := c.cache(k)
+	if cache == nil {
+		return c.db.Get(k)
+	}
+	if v, ok := cache.Get(string(k)); ok {
+		return copyBytes(v.([]byte)), true, nil
+	}
+
+	c.lock.Lock()
+	gen := c.gen
+	c.lock.Unlock()
+
+	v, ok, err := c.db.Get(k)
+	if err != nil || !ok {
+		return v, ok, err
+	}
+
+	c.lock.Lock()
+	if gen == c.gen {
+		cache.Add(string(k), copyBytes(v))
+	}
+	c.lock.Unlock()
+
+	return v, true, nil
+}
+
+func (c *cacheKV) NewBatch() Batch {
+	return &cacheBatch{kv: c, batch: c.db.NewBatch()}
+}
+
+func (c *cacheKV) NewIterator(prefix []byte) Iterator {
+	return c.db.NewIterator(prefix)
+}
+
+func (c *cacheKV) Snapshot(dst string) error {
+	return c.db.Snapshot(dst)
+}
+
+func (c *cacheKV) Compact(start, end []byte) error {
+	return c.db.Compact(start, end)
+}
+
+func (c *cacheKV) Close() error {
+	return c.db.Close()
+}
+
+type cacheOp struct {
+	k []byte
+	v []byte
+
+	// delete is set if the op removes the key
+	delete bool
+}
+
+// cacheBatch applies its writes and deletes to the cache once it is committed
+type cacheBatch struct {
+	kv    *cacheKV
+	batch Batch
+	ops   []cacheOp
+}
+
+func (b *cacheBatch) Put(k []byte, v []byte) {
+	if b.kv.cache(k) != nil {
+		b.ops = append(b.ops, cacheOp{k: copyBytes(k), v: copyBytes(v)})
+	}
+	b.batch.Put(k, v)
+}
+
+func (b *cacheBatch) Delete(k []byte) {
+	if b.kv.cache(k) != nil {
+		b.ops = append(b.ops, cacheOp{k: copyBytes(k), delete: true})
+	}
+	b.batch.Delete(k)
+}
+
+func (b *cacheBatch) Commit() error {
+	b.kv.lock.Lock()
+	defer b.kv.lock.Unlock()
+
+	err := b.batch.Commit()
+	if err == nil {
+		b.kv.gen++
+	}
+	for _, op := range b.ops {
+		cache := b.kv.cache(op.k)
+		if err != nil || op.delete {
+			cache.Remove(string(op.k))
+		} else {
+			cache.Add(string(op.k), op.v)
+		}
+	}
+	b.ops = nil
+	return err
+}
+
+func copyBytes(b []byte) []byte {
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheStorage(t *testing.T, sizes map[string]int) (Storage, mapKV) {
+	db := mapKV{}
+	s, err := setupCache(NewKeyValueStorage(hclog.NewNullLogger(), db), sizes)
+	assert.NoError(t, err)
+	return s, db
+}
+
+func TestCacheStorage(t *testing.T) {
+	TestStorage(t, func(t *testing.T) (Storage, func()) {
+		s, _ := newCacheStorage(t, DefaultCacheSizes)
+		return s, func() {}
+	})
+}
+
+func TestCache(t *testing.T) {
+	s, db := newCacheStorage(t, map[string]int{"difficulties": 1, "canonical": 0})
+
+	assert.NoError(t, s.WriteDiff(hash1, big.NewInt(10)))
+	assert.NoError(t, s.WriteCanonicalHash(1, hash1))
+
+	// remove the entries from the database, the cached ones are still read
+	for k := range db {
+		delete(db, k)
+	}
+	diff, ok := s.ReadDiff(hash1)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(10), diff)
+
+	_, ok = s.ReadCanonicalHash(1)
+	assert.False(t, ok)
+
+	// the lru only keeps the last entry
+	assert.NoError(t, s.WriteDiff(hash2, big.NewInt(20)))
+	_, ok = s.ReadDiff(hash1)
+	assert.False(t, ok)
+
+	// the values read from the database are cached
+	db[string(key(DIFFICULTY, hash1.Bytes()))] = big.NewInt(30).Bytes()
+	diff, ok = s.ReadDiff(hash1)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(30), diff)
+
+	delete(db, string(key(DIFFICULTY, hash1.Bytes())))
+	_, ok = s.ReadDiff(hash1)
+	assert.True(t, ok)
+}
+
+func TestCache_Batch(t *testing.T) {
+	s, _ := newCacheStorage(t, map[string]int{"difficulties": 10})
+
+	assert.NoError(t, s.WriteDiff(hash1, big.NewInt(10)))
+	assert.NoError(t, s.WriteDiff(hash2, big.NewInt(20)))
+
+	batch := NewBatchWriter(s)
+	batch.PutDiff(hash1, big.NewInt(11))
+	batch.DeleteDiff(hash2)
+
+	// the cache is not updated until the batch is committed
+	diff, _ := s.ReadDiff(hash1)
+	assert.Equal(t, big.NewInt(10), diff)
+
+	assert.NoError(t, batch.Commit())
+
+	diff, _ = s.ReadDiff(hash1)
+	assert.Equal(t, big.NewInt(11), diff)
+	_, ok := s.ReadDiff(hash2)
+	assert.False(t, ok)
+}
+
+func TestCache_Copy(t *testing.T) {
+	s, _ := newCacheStorage(t, map[string]int{"canonical": 10})
+
+	k := key(CANONICAL, encodeUint(1))
+	v := hash1.Bytes()
+
+	kv := s.(*KeyValueStorage).db
+	assert.NoError(t, kv.Set(k, v))
+
+	// the cached value does not change with the written or the read slices
+	v[0] = 0xff
+	res, _, _ := kv.Get(k)
+	assert.Equal(t, hash1.Bytes(), res)
+
+	res[0] = 0xff
+	hash, _ := s.ReadCanonicalHash(1)
+	assert.Equal(t, hash1, hash)
+}
+
+func TestCache_Config(t *testing.T) {
+	s := newTestStorage()
+
+	_, err := setupCache(s, map[string]int{"unknown": 10})
+	assert.Error(t, err)
+
+	_, err = setupCache(s, map[string]int{"headers": -1})
+	assert.Error(t, err)
+
+	// without caches the storage is not wrapped
+	res, err := setupCache(s, map[string]int{"headers": 0})
+	assert.NoError(t, err)
+	assert.Equal(t, s, res)
+
+	Register("test-cache", func(c map[string]interface{}, logger hclog.Logger) (Storage, error) {
+		return newTestStorage(), nil
+	})
+
+	res, err = Open("test-cache", map[string]interface{}{"cache": map[string]int{"headers": 10}}, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.IsType(t, &cacheKV{}, res.(*KeyValueStorage).db)
+
+	_, err = Open("test-cache", map[string]interface{}{"cache": "a"}, hclog.NewNullLogger())
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/umbracle/fastrlp"
+)
+
+// QUARANTINE is the prefix where the checker moves the corrupted entries
+var QUARANTINE = []byte("q")
+
+// RepairMode is the action taken by the checker with the corrupted entries
+type RepairMode int
+
+const (
+	// RepairNone only reports the corrupted entries
+	RepairNone RepairMode = iota
+
+	// RepairDrop deletes the corrupted entries
+	RepairDrop
+
+	// RepairQuarantine moves the corrupted entries under the quarantine prefix
+	RepairQuarantine
+)
+
+// Corruption is an entry that failed the integrity checks
+type Corruption struct {
+	Key    []byte
+	Reason string
+
+	value []byte
+}
+
+// CheckReport is the result of an integrity check
+type CheckReport struct {
+	// Entries is the number of entries checked per category
+	Entries map[string]uint64
+
+	Corrupted []*Corruption
+}
+
+type entryCheck struct {
+	name   string
+	prefix []byte
+	check  func(s *KeyValueStorage, k, v []byte) error
+}
+
+var entryChecks = []entryCheck{
+	{"headers", HEADER, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != types.HashLength {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		return unmarshalRLP(v, &types.Header{})
+	}},
+	{"bodies", BODY, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != types.HashLength {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		return unmarshalRLP(v, &types.Body{})
+	}},
+	{"receipts", RECEIPTS, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != types.HashLength {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		return unmarshalRLP(v, &types.Receipts{})
+	}},
+	{"difficulties", DIFFICULTY, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != types.HashLength {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		return nil
+	}},
+	{"tx lookups", TX_LOOKUP_PREFIX, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != types.HashLength {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		val, err := (&fastrlp.Parser{}).Parse(v)
+		if err != nil {
+			return err
+		}
+		_, err = val.GetBytes(nil, types.HashLength)
+		return err
+	}},
+	// the canonical entries are checked last since they reference the headers
+	{"canonical", CANONICAL, func(s *KeyValueStorage, k, v []byte) error {
+		if len(k) != 8 {
+			return fmt.Errorf("bad key length %d", len(k))
+		}
+		if len(v) != types.HashLength {
+			return fmt.Errorf("bad hash length %d", len(v))
+		}
+		header, err := s.ReadHeader(types.BytesToHash(v))
+		if err != nil {
+			return fmt.Errorf("header %s: %v", types.BytesToHash(v), err)
+		}
+		if num := decodeUint(k); header.Number != num {
+			return fmt.Errorf("header has number %d instead of %d", header.Number, num)
+		}
+		return nil
+	}},
+}
+
+// Check scans the headers, bodies, receipts, difficulties, transaction lookups
+// and canonical entries, verifies that they can be decoded and that the canonical
+// entries point to headers with the same number. The corrupted entries are
+// handled according to the repair mode. The head entries are not modified,
+// an inconsistent head is rewound when the blockchain starts.
+func (s *KeyValueStorage) Check(mode RepairMode) (*CheckReport, error) {
+	report := &CheckReport{
+		Entries:   map[string]uint64{},
+		Corrupted: []*Corruption{},
+	}
+
+	for _, c := range entryChecks {
+		corrupted, err := s.checkPrefix(c, report)
+		if err != nil {
+			return nil, err
+		}
+		if mode == RepairNone || len(corrupted) == 0 {
+			continue
+		}
+
+		// repair after every prefix, the checks of the next ones might depend on it
+		batch := s.NewBatch()
+		for _, entry := range corrupted {
+			if mode == RepairQuarantine {
+				batch.Put(key(QUARANTINE, entry.Key), entry.value)
+			}
+			batch.Delete(entry.Key)
+		}
+		if err := batch.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// run runs the check on an entry, the rlp parser might panic with malformed data
+func (c *entryCheck) run(s *KeyValueStorage, k, v []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to decode: %v", r)
+		}
+	}()
+	return c.check(s, k, v)
+}
+
+func (s *KeyValueStorage) checkPrefix(c entryCheck, report *CheckReport) ([]*Corruption, error) {
+	iter := s.Iterator(c.prefix)
+	defer iter.Release()
+
+	corrupted := []*Corruption{}
+	for iter.Next() {
+		k, v := iter.Key(), iter.Value()
+		report.Entries[c.name]++
+
+		if err := c.run(s, bytes.TrimPrefix(k, c.prefix), v); err != nil {
+			entry := &Corruption{
+				Key:    append([]byte{}, k...),
+				Reason: fmt.Sprintf("%s: %v", c.name, err),
+				value:  append([]byte{}, v...),
+			}
+			report.Corrupted = append(report.Corrupted, entry)
+			corrupted = append(corrupted, entry)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return corrupted, nil
+}
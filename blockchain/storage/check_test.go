@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	newCorruptedStorage := func() (*KeyValueStorage, *types.Header) {
+		s := newTestStorage().(*KeyValueStorage)
+
+		h0 := &types.Header{Number: 0, ExtraData: []byte{}}
+		h0.ComputeHash()
+		h1 := &types.Header{Number: 1, ParentHash: h0.Hash, ExtraData: []byte{}}
+		h1.ComputeHash()
+
+		assert.NoError(t, s.WriteCanonicalHeader(h0, big.NewInt(1)))
+		assert.NoError(t, s.WriteCanonicalHeader(h1, big.NewInt(2)))
+		assert.NoError(t, s.WriteBody(h1.Hash, &types.Body{}))
+		assert.NoError(t, s.WriteReceipts(h1.Hash, []*types.Receipt{}))
+
+		// garbage for the header and the body of h1
+		assert.NoError(t, s.set(HEADER, h1.Hash.Bytes(), []byte{0x1, 0x2}))
+		assert.NoError(t, s.set(BODY, h1.Hash.Bytes(), []byte{0xff}))
+		return s, h1
+	}
+
+	t.Run("Report", func(t *testing.T) {
+		s, h1 := newCorruptedStorage()
+
+		report, err := s.Check(RepairNone)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint64(2), report.Entries["headers"])
+		assert.Equal(t, uint64(1), report.Entries["bodies"])
+		assert.Equal(t, uint64(1), report.Entries["receipts"])
+		assert.Equal(t, uint64(2), report.Entries["canonical"])
+
+		// the header, the body and the canonical entry pointing to the header
+		assert.Len(t, report.Corrupted, 3)
+
+		// nothing is modified
+		_, ok := s.get(HEADER, h1.Hash.Bytes())
+		assert.True(t, ok)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		s, h1 := newCorruptedStorage()
+
+		_, err := s.Check(RepairDrop)
+		assert.NoError(t, err)
+
+		_, ok := s.get(HEADER, h1.Hash.Bytes())
+		assert.False(t, ok)
+		_, ok = s.get(BODY, h1.Hash.Bytes())
+		assert.False(t, ok)
+		_, ok = s.ReadCanonicalHash(1)
+		assert.False(t, ok)
+		_, ok = s.ReadCanonicalHash(0)
+		assert.True(t, ok)
+
+		report, err := s.Check(RepairNone)
+		assert.NoError(t, err)
+		assert.Empty(t, report.Corrupted)
+	})
+
+	t.Run("Quarantine", func(t *testing.T) {
+		s, h1 := newCorruptedStorage()
+
+		_, err := s.Check(RepairQuarantine)
+		assert.NoError(t, err)
+
+		_, ok := s.get(HEADER, h1.Hash.Bytes())
+		assert.False(t, ok)
+
+		data, ok := s.get(QUARANTINE, key(HEADER, h1.Hash.Bytes()))
+		assert.True(t, ok)
+		assert.Equal(t, []byte{0x1, 0x2}, data)
+	})
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// ENCRYPTION is the entry that marks an encrypted storage. It is not encrypted
+// and holds a known value sealed with the key, to validate the key on open
+var ENCRYPTION = []byte("e")
+
+var encryptionCheck = []byte("polygon-sdk")
+
+// setupEncryption wraps the kv database of the storage with encryption if a
+// key is given. It fails if the key does not match the existing data, or if
+// the storage is encrypted and the key is missing
+func setupEncryption(s Storage, encryptionKey []byte) (Storage, error) {
+	kv, ok := s.(*KeyValueStorage)
+	if !ok {
+		if encryptionKey != nil {
+			return nil, fmt.Errorf("encryption is only supported on key value storages")
+		}
+		return s, nil
+	}
+
+	markerKey := key(ENCRYPTION, EMPTY)
+
+	marker, encrypted, err := kv.db.Get(markerKey)
+	if err != nil {
+		return nil, err
+	}
+	if encryptionKey == nil {
+		if encrypted {
+			return nil, fmt.Errorf("storage is encrypted but no encryption key is set")
+		}
+		return s, nil
+	}
+
+	db, err := newEncryptedKV(kv.db, encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		check, err := db.open(markerKey, marker)
+		if err != nil || !bytes.Equal(check, encryptionCheck) {
+			return nil, fmt.Errorf("wrong storage encryption key")
+		}
+	} else {
+		// a storage with data has to be empty to enable the encryption, otherwise
+		// the plain entries could not be read anymore
+		if _, ok, _ := kv.db.Get(key(VERSION, EMPTY)); ok {
+			return nil, fmt.Errorf("cannot enable encryption on an existing storage")
+		}
+		if _, ok, _ := kv.db.Get(key(HEAD, HASH)); ok {
+			return nil, fmt.Errorf("cannot enable encryption on an existing storage")
+		}
+		marker, err := db.seal(markerKey, encryptionCheck)
+		if err != nil {
+			return nil, err
+		}
+		if err := kv.db.Set(markerKey, marker); err != nil {
+			return nil, err
+		}
+	}
+	return NewKeyValueStorage(kv.logger, db), nil
+}
+
+// encryptedKV encrypts the values of a kv database with AES-GCM. The keys are
+// not encrypted since they are needed for the lookups and the iteration
+type encryptedKV struct {
+	db   KV
+	aead cipher.AEAD
+}
+
+func newEncryptedKV(db KV, key []byte) (*encryptedKV, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes (AES-256) but it is %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedKV{db: db, aead: aead}, nil
+}
+
+// seal encrypts the value with a random nonce as a prefix. The key is used as
+// additional data so that a value cannot be moved to another key
+func (e *encryptedKV) seal(k, v []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize(), e.aead.NonceSize()+len(v)+e.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return e.aead.Seal(nonce, nonce, v, k), nil
+}
+
+func (e *encryptedKV) open(k, data []byte) ([]byte, error) {
+	size := e.aead.NonceSize()
+	if len(data) < size {
+		return nil, fmt.Errorf("encrypted value too short")
+	}
+	return e.aead.Open(nil, data[:size], data[size:], k)
+}
+
+func (e *encryptedKV) Set(k []byte, v []byte) error {
+	data, err := e.seal(k, v)
+	if err != nil {
+		return err
+	}
+	return e.db.Set(k, data)
+}
+
+func (e *encryptedKV) Get(k []byte) ([]byte, bool, error) {
+	data, ok, err := e.db.Get(k)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	v, err := e.open(k, data)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decrypt %x: %v", k, err)
+	}
+	return v, true, nil
+}
+
+func (e *encryptedKV) NewBatch() Batch {
+	return &encryptedBatch{kv: e, batch: e.db.NewBatch()}
+}
+
+func (e *encryptedKV) NewIterator(prefix []byte) Iterator {
+	return &encryptedIterator{kv: e, iter: e.db.NewIterator(prefix)}
+}
+
+func (e *encryptedKV) Snapshot(dst string) error {
+	// the copy is encrypted with the same key
+	return e.db.Snapshot(dst)
+}
+
+func (e *encryptedKV) Compact(start, end []byte) error {
+	// only the values are encrypted, the order of the keys is the same
+	return e.db.Compact(start, end)
+}
+
+func (e *encryptedKV) Close() error {
+	return e.db.Close()
+}
+
+type encryptedBatch struct {
+	kv    *encryptedKV
+	batch Batch
+	err   error
+}
+
+func (b *encryptedBatch) Put(k []byte, v []byte) {
+	data, err := b.kv.seal(k, v)
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.batch.Put(k, data)
+}
+
+func (b *encryptedBatch) Delete(k []byte) {
+	b.batch.Delete(k)
+}
+
+func (b *encryptedBatch) Commit() error {
+	if b.err != nil {
+		return b.err
+	}
+	return b.batch.Commit()
+}
+
+type encryptedIterator struct {
+	kv    *encryptedKV
+	iter  Iterator
+	value []byte
+	err   error
+}
+
+func (i *encryptedIterator) Next() bool {
+	if i.err != nil || !i.iter.Next() {
+		return false
+	}
+	if bytes.Equal(i.iter.Key(), key(ENCRYPTION, EMPTY)) {
+		// the marker is not a value of the storage
+		return i.Next()
+	}
+	i.value, i.err = i.kv.open(i.iter.Key(), i.iter.Value())
+	return i.err == nil
+}
+
+func (i *encryptedIterator) Key() []byte {
+	return i.iter.Key()
+}
+
+func (i *encryptedIterator) Value() []byte {
+	return i.value
+}
+
+func (i *encryptedIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.iter.Error()
+}
+
+func (i *encryptedIterator) Release() {
+	i.iter.Release()
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryption(t *testing.T) {
+	key1 := bytes.Repeat([]byte{0x1}, 32)
+	key2 := bytes.Repeat([]byte{0x2}, 32)
+
+	h := &types.Header{Number: 1, ExtraData: []byte{0x1, 0x2, 0x3}}
+	h.ComputeHash()
+
+	db := mapKV{}
+	plain := NewKeyValueStorage(hclog.NewNullLogger(), db)
+
+	s, err := setupEncryption(plain, key1)
+	assert.NoError(t, err)
+	assert.NoError(t, s.WriteCanonicalHeader(h, big.NewInt(1)))
+
+	batch := NewBatchWriter(s)
+	batch.PutBody(h.Hash, &types.Body{})
+	assert.NoError(t, batch.Commit())
+
+	found, err := s.ReadHeader(h.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, h.ExtraData, found.ExtraData)
+
+	_, err = s.ReadBody(h.Hash)
+	assert.NoError(t, err)
+
+	// the values are not stored in plain
+	raw := db[string(key(HEADER, h.Hash.Bytes()))]
+	assert.NotEqual(t, marshalRLP(h), raw)
+	_, err = plain.ReadHeader(h.Hash)
+	assert.Error(t, err)
+
+	// the iteration decrypts the values and skips the marker
+	iter := s.(*KeyValueStorage).db.NewIterator(HEADER)
+	assert.True(t, iter.Next())
+	assert.Equal(t, marshalRLP(h), iter.Value())
+	assert.False(t, iter.Next())
+	assert.NoError(t, iter.Error())
+
+	// the key is validated when the storage is opened again
+	_, err = setupEncryption(plain, key2)
+	assert.Error(t, err)
+	_, err = setupEncryption(plain, nil)
+	assert.Error(t, err)
+
+	s, err = setupEncryption(plain, key1)
+	assert.NoError(t, err)
+	num, ok := s.ReadHeadNumber()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), num)
+
+	// a value cannot be moved to another key
+	db[string(key(DIFFICULTY, hash1.Bytes()))] = db[string(key(DIFFICULTY, h.Hash.Bytes()))]
+	_, ok = s.ReadDiff(hash1)
+	assert.False(t, ok)
+}
+
+func TestEncryption_ExistingStorage(t *testing.T) {
+	s := newTestStorage()
+
+	h := &types.Header{ExtraData: []byte{}}
+	h.ComputeHash()
+	assert.NoError(t, s.WriteCanonicalHeader(h, big.NewInt(1)))
+
+	_, err := setupEncryption(s, bytes.Repeat([]byte{0x1}, 32))
+	assert.Error(t, err)
+
+	// the key has to be 32 bytes
+	_, err = setupEncryption(newTestStorage(), []byte{0x1})
+	assert.Error(t, err)
+}
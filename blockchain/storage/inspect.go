@@ -0,0 +1,47 @@
+package storage
+
+import "sort"
+
+// PrefixStats are the number of entries and their size for a namespace
+type PrefixStats struct {
+	Name  string
+	Keys  uint64
+	Bytes uint64
+}
+
+// Inspect walks over all the entries of the storage and returns the number of
+// keys and the total size of the keys and values per namespace, sorted by size.
+// The entries with an unknown prefix are reported as 'other'
+func Inspect(s Storage) ([]*PrefixStats, error) {
+	iter := s.Iterator(nil)
+	defer iter.Release()
+
+	stats := map[string]*PrefixStats{}
+	for iter.Next() {
+		k := iter.Key()
+
+		name := namespaceName(k)
+		stat, ok := stats[name]
+		if !ok {
+			stat = &PrefixStats{Name: name}
+			stats[name] = stat
+		}
+		stat.Keys++
+		stat.Bytes += uint64(len(k) + len(iter.Value()))
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	res := []*PrefixStats{}
+	for _, stat := range stats {
+		res = append(res, stat)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Bytes != res[j].Bytes {
+			return res[i].Bytes > res[j].Bytes
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspect(t *testing.T) {
+	s := newTestStorage()
+
+	for i := uint64(0); i < 3; i++ {
+		h := &types.Header{Number: i, ExtraData: []byte{}}
+		h.ComputeHash()
+		assert.NoError(t, s.WriteCanonicalHeader(h, big.NewInt(1)))
+	}
+	assert.NoError(t, s.WriteForks([]types.Hash{hash1}))
+	assert.NoError(t, s.(*KeyValueStorage).set([]byte("z"), []byte{0x1}, []byte{0x2}))
+
+	stats, err := Inspect(s)
+	assert.NoError(t, err)
+
+	byName := map[string]*PrefixStats{}
+	for _, stat := range stats {
+		byName[stat.Name] = stat
+	}
+	assert.Equal(t, uint64(3), byName["headers"].Keys)
+	assert.Equal(t, uint64(3), byName["canonical"].Keys)
+	assert.Equal(t, uint64(3), byName["difficulties"].Keys)
+	assert.Equal(t, uint64(2), byName["head"].Keys)
+	assert.Equal(t, uint64(1), byName["forks"].Keys)
+
+	// canonical entries are 1 + 8 bytes of key and 32 of value
+	assert.Equal(t, uint64(3*41), byName["canonical"].Bytes)
+
+	assert.Equal(t, uint64(1), byName["other"].Keys)
+	assert.Equal(t, uint64(3), byName["other"].Bytes)
+
+	// sorted by size
+	for i := 1; i < len(stats); i++ {
+		assert.True(t, stats[i-1].Bytes >= stats[i].Bytes)
+	}
+}
@@ -39,6 +39,24 @@ var (
 
 	// TRANSACTION is the prefix for transactions
 	TX_LOOKUP_PREFIX = []byte("l")
+
+	// BLOOM is the prefix for the log blooms of the blocks
+	BLOOM = []byte("g")
+
+	// BLOOM_BITS is the prefix for the rotated bloom bits of a section
+	BLOOM_BITS = []byte("B")
+
+	// INDEX_SECTION is the prefix for the head hashes of the sections of the chain indexes
+	INDEX_SECTION = []byte("S")
+
+	// VERSION is the entry to store the schema version
+	VERSION = []byte("v")
+
+	// PENDING is the entry to store the hash of the block being written
+	PENDING = []byte("p")
+
+	// HISTORY is the prefix for the storage mode and the pruning progress
+	HISTORY = []byte("y")
 )
 
 // sub-prefix
@@ -47,6 +65,8 @@ var (
 	HASH   = []byte("hash")
 	NUMBER = []byte("number")
 	EMPTY  = []byte("empty")
+	MODE   = []byte("mode")
+	TAIL   = []byte("tail")
 )
 
 // KV is a key value storage interface
@@ -54,6 +74,21 @@ type KV interface {
 	Close() error
 	Set(p []byte, v []byte) error
 	Get(p []byte) ([]byte, bool, error)
+	NewBatch() Batch
+	NewIterator(prefix []byte) Iterator
+	Snapshot(dst string) error
+	Compact(start, end []byte) error
+}
+
+// Iterator iterates in order over the entries of a kv storage with a given
+// prefix. The key and the value are only valid until the next call to Next,
+// writes made after the iterator is created are not visible
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
 }
 
 // KeyValueStorage is a generic storage for kv databases
@@ -67,21 +102,26 @@ func NewKeyValueStorage(logger hclog.Logger, db KV) Storage {
 	return &KeyValueStorage{logger: logger, db: db}
 }
 
-func (s *KeyValueStorage) encodeUint(n uint64) []byte {
+func encodeUint(n uint64) []byte {
 	b := make([]byte, 8)
 	binary.BigEndian.PutUint64(b[:], n)
 	return b[:]
 }
 
-func (s *KeyValueStorage) decodeUint(b []byte) uint64 {
+func decodeUint(b []byte) uint64 {
 	return binary.BigEndian.Uint64(b[:])
 }
 
+// NewBatch creates a batch of writes on the kv database
+func (s *KeyValueStorage) NewBatch() Batch {
+	return s.db.NewBatch()
+}
+
 // -- canonical hash --
 
 // ReadCanonicalHash gets the hash from the number of the canonical chain
 func (s *KeyValueStorage) ReadCanonicalHash(n uint64) (types.Hash, bool) {
-	data, ok := s.get(CANONICAL, s.encodeUint(n))
+	data, ok := s.get(CANONICAL, encodeUint(n))
 	if !ok {
 		return types.Hash{}, false
 	}
@@ -90,7 +130,7 @@ func (s *KeyValueStorage) ReadCanonicalHash(n uint64) (types.Hash, bool) {
 
 // WriteCanonicalHash writes a hash for a number block in the canonical chain
 func (s *KeyValueStorage) WriteCanonicalHash(n uint64, hash types.Hash) error {
-	return s.set(CANONICAL, s.encodeUint(n), hash.Bytes())
+	return s.set(CANONICAL, encodeUint(n), hash.Bytes())
 }
 
 // -- head --
@@ -113,7 +153,7 @@ func (s *KeyValueStorage) ReadHeadNumber() (uint64, bool) {
 	if len(data) != 8 {
 		return 0, false
 	}
-	return s.decodeUint(data), true
+	return decodeUint(data), true
 }
 
 // WriteHeadHash writes the hash of the head
@@ -123,7 +163,7 @@ func (s *KeyValueStorage) WriteHeadHash(h types.Hash) error {
 
 // WriteHeadNumber writes the number of the head
 func (s *KeyValueStorage) WriteHeadNumber(n uint64) error {
-	return s.set(HEAD, NUMBER, s.encodeUint(n))
+	return s.set(HEAD, NUMBER, encodeUint(n))
 }
 
 // -- fork --
@@ -173,22 +213,9 @@ func (s *KeyValueStorage) ReadHeader(hash types.Hash) (*types.Header, error) {
 
 // WriteCanonicalHeader implements the storage interface
 func (s *KeyValueStorage) WriteCanonicalHeader(h *types.Header, diff *big.Int) error {
-	if err := s.WriteHeader(h); err != nil {
-		return err
-	}
-	if err := s.WriteHeadHash(h.Hash); err != nil {
-		return err
-	}
-	if err := s.WriteHeadNumber(h.Number); err != nil {
-		return err
-	}
-	if err := s.WriteCanonicalHash(h.Number, h.Hash); err != nil {
-		return err
-	}
-	if err := s.WriteDiff(h.Hash, diff); err != nil {
-		return err
-	}
-	return nil
+	batch := NewBatchWriter(s)
+	batch.PutCanonicalHeader(h, diff)
+	return batch.Commit()
 }
 
 // -- body --
@@ -262,41 +289,144 @@ func (s *KeyValueStorage) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
 	return types.BytesToHash(blockHash), true
 }
 
+// -- bloom --
+
+// WriteBloom writes the log bloom of a block
+func (s *KeyValueStorage) WriteBloom(hash types.Hash, bloom types.Bloom) error {
+	return s.set(BLOOM, hash.Bytes(), bloom[:])
+}
+
+// ReadBloom reads the log bloom of a block
+func (s *KeyValueStorage) ReadBloom(hash types.Hash) (types.Bloom, bool) {
+	var bloom types.Bloom
+	data, ok := s.get(BLOOM, hash.Bytes())
+	if !ok || len(data) != types.BloomByteLength {
+		return bloom, false
+	}
+	copy(bloom[:], data)
+	return bloom, true
+}
+
+// WriteBloomBits writes the bit vector of a bloom bit for a section
+func (s *KeyValueStorage) WriteBloomBits(bit uint, section uint64, bits []byte) error {
+	return s.set(BLOOM_BITS, encodeBloomBitsKey(bit, section), bits)
+}
+
+// ReadBloomBits reads the bit vector of a bloom bit for a section
+func (s *KeyValueStorage) ReadBloomBits(bit uint, section uint64) ([]byte, bool) {
+	return s.get(BLOOM_BITS, encodeBloomBitsKey(bit, section))
+}
+
+func encodeBloomBitsKey(bit uint, section uint64) []byte {
+	k := make([]byte, 10)
+	binary.BigEndian.PutUint16(k[0:2], uint16(bit))
+	binary.BigEndian.PutUint64(k[2:], section)
+	return k
+}
+
+// -- chain indexes --
+
+// WriteIndexSectionHead writes the hash of the last block of a section of a chain index
+func (s *KeyValueStorage) WriteIndexSectionHead(index string, section uint64, hash types.Hash) error {
+	return s.set(INDEX_SECTION, encodeIndexSectionKey(index, section), hash.Bytes())
+}
+
+// ReadIndexSectionHead reads the hash of the last block of a section of a chain index
+func (s *KeyValueStorage) ReadIndexSectionHead(index string, section uint64) (types.Hash, bool) {
+	data, ok := s.get(INDEX_SECTION, encodeIndexSectionKey(index, section))
+	if !ok {
+		return types.Hash{}, false
+	}
+	return types.BytesToHash(data), true
+}
+
+func encodeIndexSectionKey(index string, section uint64) []byte {
+	k := append([]byte(index), '-')
+	return append(k, encodeUint(section)...)
+}
+
+// -- schema version --
+
+// ReadSchemaVersion reads the version of the layout of the storage
+func (s *KeyValueStorage) ReadSchemaVersion() (uint64, bool) {
+	data, ok := s.get(VERSION, EMPTY)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+	return decodeUint(data), true
+}
+
+// WriteSchemaVersion writes the version of the layout of the storage
+func (s *KeyValueStorage) WriteSchemaVersion(version uint64) error {
+	return s.set(VERSION, EMPTY, encodeUint(version))
+}
+
+// ReadPendingBlock reads the hash of the block whose write did not complete
+func (s *KeyValueStorage) ReadPendingBlock() (types.Hash, bool) {
+	data, ok := s.get(PENDING, EMPTY)
+	if !ok {
+		return types.Hash{}, false
+	}
+	return types.BytesToHash(data), true
+}
+
+// ReadMode reads the storage mode the storage was created with
+func (s *KeyValueStorage) ReadMode() (string, bool) {
+	data, ok := s.get(HISTORY, MODE)
+	if !ok {
+		return "", false
+	}
+	return string(data), true
+}
+
+// WriteMode writes the storage mode
+func (s *KeyValueStorage) WriteMode(mode string) error {
+	return s.set(HISTORY, MODE, []byte(mode))
+}
+
+// ReadHistoryTail reads the number of the first canonical block
+// whose history has not been pruned
+func (s *KeyValueStorage) ReadHistoryTail() (uint64, bool) {
+	data, ok := s.get(HISTORY, TAIL)
+	if !ok || len(data) != 8 {
+		return 0, false
+	}
+	return decodeUint(data), true
+}
+
 // -- write ops --
 
 func (s *KeyValueStorage) writeRLP(p, k []byte, raw types.RLPMarshaler) error {
-	var data []byte
+	return s.set(p, k, marshalRLP(raw))
+}
+
+func marshalRLP(raw types.RLPMarshaler) []byte {
 	if obj, ok := raw.(types.RLPStoreMarshaler); ok {
-		data = obj.MarshalStoreRLPTo(nil)
-	} else {
-		data = raw.MarshalRLPTo(nil)
+		return obj.MarshalStoreRLPTo(nil)
 	}
-	return s.set(p, k, data)
+	return raw.MarshalRLPTo(nil)
 }
 
 var ErrNotFound = fmt.Errorf("not found")
 
 func (s *KeyValueStorage) readRLP(p, k []byte, raw types.RLPUnmarshaler) error {
-	p = append(p, k...)
-	data, ok, err := s.db.Get(p)
+	data, ok, err := s.db.Get(key(p, k))
 	if err != nil {
 		return err
 	}
 	if !ok {
 		return ErrNotFound
 	}
+	return unmarshalRLP(data, raw)
+}
+
+func unmarshalRLP(data []byte, raw types.RLPUnmarshaler) error {
 	if obj, ok := raw.(types.RLPStoreUnmarshaler); ok {
 		// decode in the store format
-		if err := obj.UnmarshalStoreRLP(data); err != nil {
-			return err
-		}
-	} else {
-		// normal rlp decoding
-		if err := raw.UnmarshalRLP(data); err != nil {
-			return err
-		}
+		return obj.UnmarshalStoreRLP(data)
 	}
-	return nil
+	// normal rlp decoding
+	return raw.UnmarshalRLP(data)
 }
 
 func (s *KeyValueStorage) read2(p, k []byte, parser *fastrlp.Parser) *fastrlp.Value {
@@ -319,19 +449,40 @@ func (s *KeyValueStorage) write2(p, k []byte, v *fastrlp.Value) error {
 }
 
 func (s *KeyValueStorage) set(p []byte, k []byte, v []byte) error {
-	p = append(p, k...)
-	return s.db.Set(p, v)
+	return s.db.Set(key(p, k), v)
 }
 
 func (s *KeyValueStorage) get(p []byte, k []byte) ([]byte, bool) {
-	p = append(p, k...)
-	data, ok, err := s.db.Get(p)
+	data, ok, err := s.db.Get(key(p, k))
 	if err != nil {
 		return nil, false
 	}
 	return data, ok
 }
 
+// key joins the prefix and the key in a new slice, appending to the
+// prefix directly could overwrite the spare capacity of the shared prefixes
+func key(p []byte, k []byte) []byte {
+	res := make([]byte, 0, len(p)+len(k))
+	res = append(res, p...)
+	return append(res, k...)
+}
+
+// Iterator iterates over the entries of the kv database with the prefix
+func (s *KeyValueStorage) Iterator(prefix []byte) Iterator {
+	return s.db.NewIterator(prefix)
+}
+
+// Snapshot writes a copy of the kv database to dst
+func (s *KeyValueStorage) Snapshot(dst string) error {
+	return s.db.Snapshot(dst)
+}
+
+// Compact compacts the range of keys of the kv database
+func (s *KeyValueStorage) Compact(start, end []byte) error {
+	return s.db.Compact(start, end)
+}
+
 // Close closes the connection with the db
 func (s *KeyValueStorage) Close() error {
 	return s.db.Close()
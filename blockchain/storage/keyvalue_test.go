@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// mapKV is a minimal kv storage to test the storage package without a backend
+type mapKV map[string][]byte
+
+func newTestStorage() Storage {
+	return NewKeyValueStorage(hclog.NewNullLogger(), mapKV{})
+}
+
+func (m mapKV) Set(k []byte, v []byte) error {
+	m[string(k)] = v
+	return nil
+}
+
+func (m mapKV) Get(k []byte) ([]byte, bool, error) {
+	v, ok := m[string(k)]
+	return v, ok, nil
+}
+
+func (m mapKV) NewBatch() Batch {
+	return &mapBatch{db: m, ops: map[string][]byte{}}
+}
+
+func (m mapKV) NewIterator(prefix []byte) Iterator {
+	keys := []string{}
+	for k := range m {
+		if strings.HasPrefix(k, string(prefix)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return &mapIterator{db: m, keys: keys, index: -1}
+}
+
+type mapIterator struct {
+	db    mapKV
+	keys  []string
+	index int
+}
+
+func (i *mapIterator) Next() bool {
+	i.index++
+	return i.index < len(i.keys)
+}
+
+func (i *mapIterator) Key() []byte {
+	return []byte(i.keys[i.index])
+}
+
+func (i *mapIterator) Value() []byte {
+	return i.db[i.keys[i.index]]
+}
+
+func (i *mapIterator) Error() error {
+	return nil
+}
+
+func (i *mapIterator) Release() {}
+
+func (m mapKV) Snapshot(dst string) error {
+	return fmt.Errorf("not supported")
+}
+
+func (m mapKV) Compact(start, end []byte) error {
+	return nil
+}
+
+func (m mapKV) Close() error {
+	return nil
+}
+
+type mapBatch struct {
+	db  mapKV
+	ops map[string][]byte
+}
+
+func (b *mapBatch) Put(k []byte, v []byte) {
+	b.ops[string(k)] = v
+}
+
+func (b *mapBatch) Delete(k []byte) {
+	b.ops[string(k)] = nil
+}
+
+func (b *mapBatch) Commit() error {
+	for k, v := range b.ops {
+		if v == nil {
+			delete(b.db, k)
+		} else {
+			b.db[k] = v
+		}
+	}
+	return nil
+}
@@ -2,12 +2,66 @@ package leveldb
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/0xPolygon/minimal/blockchain/storage"
 	"github.com/hashicorp/go-hclog"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+func init() {
+	storage.Register("leveldb", Factory)
+}
+
+// Options are the tuning parameters of leveldb. The sizes are in MiB
+// and the zero values use the leveldb defaults
+type Options struct {
+	// CacheSize is the capacity of the block cache
+	CacheSize int
+
+	// Handles is the number of open files kept in the cache
+	Handles int
+
+	// WriteBuffer is the size of the memtable before it is flushed to disk
+	WriteBuffer int
+
+	// CompactionTableSize is the size of the tables generated by the compaction
+	CompactionTableSize int
+
+	// CompactionTotalSize is the total size of the tables in the first level,
+	// the rest of the levels grow by a factor of ten
+	CompactionTotalSize int
+
+	// CompactionL0Trigger is the number of tables in level 0 that triggers a compaction
+	CompactionL0Trigger int
+
+	// BloomFilterBits is the number of bits per key of the table bloom filters,
+	// the filters are disabled if zero
+	BloomFilterBits int
+
+	// CompactionInterval is the period of the background compactions of the
+	// whole database, they are disabled if zero
+	CompactionInterval time.Duration
+}
+
+func (o *Options) leveldbOptions() *opt.Options {
+	options := &opt.Options{
+		BlockCacheCapacity:     o.CacheSize * opt.MiB,
+		OpenFilesCacheCapacity: o.Handles,
+		WriteBuffer:            o.WriteBuffer * opt.MiB,
+		CompactionTableSize:    o.CompactionTableSize * opt.MiB,
+		CompactionTotalSize:    o.CompactionTotalSize * opt.MiB,
+		CompactionL0Trigger:    o.CompactionL0Trigger,
+	}
+	if o.BloomFilterBits != 0 {
+		options.Filter = filter.NewBloomFilter(o.BloomFilterBits)
+	}
+	return options
+}
+
 // Factory creates a leveldb storage
 func Factory(config map[string]interface{}, logger hclog.Logger) (storage.Storage, error) {
 	path, ok := config["path"]
@@ -18,23 +72,111 @@ func Factory(config map[string]interface{}, logger hclog.Logger) (storage.Storag
 	if !ok {
 		return nil, fmt.Errorf("path is not a string")
 	}
-	return NewLevelDBStorage(pathStr, logger)
+
+	options := &Options{}
+	fields := map[string]*int{
+		"cache_size":            &options.CacheSize,
+		"handles":               &options.Handles,
+		"write_buffer":          &options.WriteBuffer,
+		"compaction_table_size": &options.CompactionTableSize,
+		"compaction_total_size": &options.CompactionTotalSize,
+		"compaction_l0_trigger": &options.CompactionL0Trigger,
+		"bloom_filter_bits":     &options.BloomFilterBits,
+	}
+	for name, field := range fields {
+		raw, ok := config[name]
+		if !ok {
+			continue
+		}
+		val, err := toInt(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		*field = val
+	}
+	if raw, ok := config["compaction_interval"]; ok {
+		interval, err := toDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("compaction_interval: %v", err)
+		}
+		options.CompactionInterval = interval
+	}
+	return NewLevelDBStorage(pathStr, options, logger)
+}
+
+// toInt converts the numeric values decoded by the config formats (json decodes
+// numbers as floats while hcl and the flags use ints)
+func toInt(raw interface{}) (int, error) {
+	var val int
+	switch obj := raw.(type) {
+	case int:
+		val = obj
+	case int64:
+		val = int(obj)
+	case uint64:
+		val = int(obj)
+	case float64:
+		if obj != float64(int(obj)) {
+			return 0, fmt.Errorf("%v is not an integer", obj)
+		}
+		val = int(obj)
+	default:
+		return 0, fmt.Errorf("expected a number but found %T", raw)
+	}
+	if val < 0 {
+		return 0, fmt.Errorf("%d is negative", val)
+	}
+	return val, nil
+}
+
+// toDuration converts a duration string (i.e. 12h) or a number of seconds
+func toDuration(raw interface{}) (time.Duration, error) {
+	if str, ok := raw.(string); ok {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return 0, err
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("%s is negative", str)
+		}
+		return d, nil
+	}
+	secs, err := toInt(raw)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(secs) * time.Second, nil
 }
 
 // NewLevelDBStorage creates the new storage reference with leveldb
-func NewLevelDBStorage(path string, logger hclog.Logger) (storage.Storage, error) {
-	db, err := leveldb.OpenFile(path, nil)
+func NewLevelDBStorage(path string, options *Options, logger hclog.Logger) (storage.Storage, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	db, err := leveldb.OpenFile(path, options.leveldbOptions())
 	if err != nil {
 		return nil, err
 	}
 
-	kv := &levelDBKV{db}
-	return storage.NewKeyValueStorage(logger.Named("leveldb"), kv), nil
+	logger = logger.Named("leveldb")
+
+	kv := &levelDBKV{db: db, logger: logger}
+	if options.CompactionInterval != 0 {
+		kv.closeCh = make(chan struct{})
+		kv.doneCh = make(chan struct{})
+		go kv.runCompactions(options.CompactionInterval)
+	}
+	return storage.NewKeyValueStorage(logger, kv), nil
 }
 
 // levelDBKV is the leveldb implementation of the kv storage
 type levelDBKV struct {
-	db *leveldb.DB
+	db     *leveldb.DB
+	logger hclog.Logger
+
+	// channels of the compaction scheduler, nil if it is not running
+	closeCh chan struct{}
+	doneCh  chan struct{}
 }
 
 func (l *levelDBKV) Set(p []byte, v []byte) error {
@@ -52,6 +194,107 @@ func (l *levelDBKV) Get(p []byte) ([]byte, bool, error) {
 	return data, true, nil
 }
 
+func (l *levelDBKV) NewIterator(prefix []byte) storage.Iterator {
+	return l.db.NewIterator(util.BytesPrefix(prefix), nil)
+}
+
+// snapshotBatchSize is the number of bytes copied in each write of a snapshot
+const snapshotBatchSize = 4 * opt.MiB
+
+func (l *levelDBKV) Snapshot(dst string) error {
+	snap, err := l.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	db, err := leveldb.OpenFile(dst, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	if err := copySnapshot(snap, db); err != nil {
+		db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+func copySnapshot(snap *leveldb.Snapshot, db *leveldb.DB) error {
+	iter := snap.NewIterator(nil, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Put(iter.Key(), iter.Value())
+		if len(batch.Dump()) >= snapshotBatchSize {
+			if err := db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return db.Write(batch, nil)
+}
+
+func (l *levelDBKV) Compact(start, end []byte) error {
+	return l.db.CompactRange(util.Range{Start: start, Limit: end})
+}
+
+// runCompactions compacts the whole database every interval until the storage
+// is closed. Leveldb only compacts the ranges that receive writes, this reclaims
+// the space of the deleted entries in the ranges that are not written anymore
+func (l *levelDBKV) runCompactions(interval time.Duration) {
+	defer close(l.doneCh)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			start := time.Now()
+			if err := l.Compact(nil, nil); err != nil {
+				l.logger.Error("failed to compact", "err", err)
+				continue
+			}
+			l.logger.Debug("compaction completed", "elapsed", time.Since(start))
+
+		case <-l.closeCh:
+			return
+		}
+	}
+}
+
 func (l *levelDBKV) Close() error {
+	if l.closeCh != nil {
+		// wait for a running compaction to finish
+		close(l.closeCh)
+		<-l.doneCh
+	}
 	return l.db.Close()
 }
+
+func (l *levelDBKV) NewBatch() storage.Batch {
+	return &levelDBBatch{db: l.db, batch: new(leveldb.Batch)}
+}
+
+// levelDBBatch is a batch of writes on leveldb
+type levelDBBatch struct {
+	db    *leveldb.DB
+	batch *leveldb.Batch
+}
+
+func (b *levelDBBatch) Put(k []byte, v []byte) {
+	b.batch.Put(k, v)
+}
+
+func (b *levelDBBatch) Delete(k []byte) {
+	b.batch.Delete(k)
+}
+
+func (b *levelDBBatch) Commit() error {
+	return b.db.Write(b.batch, nil)
+}
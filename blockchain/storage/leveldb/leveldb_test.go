@@ -3,7 +3,9 @@ package leveldb
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/0xPolygon/minimal/blockchain/storage"
 	"github.com/hashicorp/go-hclog"
@@ -14,7 +16,7 @@ func newStorage(t *testing.T) (storage.Storage, func()) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	s, err := NewLevelDBStorage(path, hclog.NewNullLogger())
+	s, err := NewLevelDBStorage(path, nil, hclog.NewNullLogger())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -29,3 +31,81 @@ func newStorage(t *testing.T) (storage.Storage, func()) {
 func TestStorage(t *testing.T) {
 	storage.TestStorage(t, newStorage)
 }
+
+func TestFactoryOptions(t *testing.T) {
+	path, err := ioutil.TempDir("/tmp", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	// numbers decoded from json are floats
+	s, err := Factory(map[string]interface{}{
+		"path":              path,
+		"cache_size":        float64(16),
+		"handles":           100,
+		"bloom_filter_bits": uint64(10),
+	}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	for _, interval := range []interface{}{"12h", float64(3600)} {
+		s, err := Factory(map[string]interface{}{"path": path, "compaction_interval": interval}, hclog.NewNullLogger())
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+	}
+	if _, err := Factory(map[string]interface{}{"path": path, "compaction_interval": "-1h"}, hclog.NewNullLogger()); err == nil {
+		t.Fatal("expected error for a negative interval")
+	}
+
+	cases := []interface{}{
+		"16",
+		1.5,
+		-1,
+	}
+	for _, c := range cases {
+		if _, err := Factory(map[string]interface{}{"path": path, "cache_size": c}, hclog.NewNullLogger()); err == nil {
+			t.Fatalf("expected error for %v", c)
+		}
+	}
+}
+
+func TestScheduledCompaction(t *testing.T) {
+	path, err := ioutil.TempDir("/tmp", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	s, err := NewLevelDBStorage(path, &Options{CompactionInterval: 10 * time.Millisecond}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 100; i++ {
+		if err := s.WriteHeadNumber(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// close stops the scheduler before closing the database
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	path, err := ioutil.TempDir("/tmp", "minimal_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	storage.TestSnapshot(t, newStorage, filepath.Join(path, "snapshot"), func(path string) (storage.Storage, error) {
+		return NewLevelDBStorage(path, nil, hclog.NewNullLogger())
+	})
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lockFileName is the name of the lock file in the data dir of the storage.
+// It is not the LOCK file of the backends, which does not say who holds it
+const lockFileName = "minimal.lock"
+
+// errLocked is returned by lockFile if the file is locked by another process
+var errLocked = errors.New("file is locked")
+
+// dirLock is an exclusive lock of a data dir held by the process while the
+// storage is open. The lock file includes the PID of the process
+type dirLock struct {
+	file *os.File
+}
+
+// lockDir acquires the lock of the data dir, creating the dir if it does not exist
+func lockDir(dir string) (*dirLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, lockFileName)
+
+	file, err := lockFile(path)
+	if err == errLocked {
+		pid := "unknown"
+		if data, err := ioutil.ReadFile(path); err == nil && len(data) != 0 {
+			pid = strings.TrimSpace(string(data))
+		}
+		return nil, fmt.Errorf("datadir %s in use by PID %s", dir, pid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock the datadir %s: %v", dir, err)
+	}
+
+	if err := file.Truncate(0); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &dirLock{file: file}, nil
+}
+
+// release releases the lock. The file is kept, since removing it while another
+// process waits on it would let a third one lock a new file at the same time
+func (l *dirLock) release() error {
+	if l == nil {
+		return nil
+	}
+	l.file.Truncate(0)
+	return l.file.Close()
+}
+
+// setupLock makes the storage release the lock when it is closed
+func setupLock(s Storage, lock *dirLock) Storage {
+	kv, ok := s.(*KeyValueStorage)
+	if !ok || lock == nil {
+		return s
+	}
+	return NewKeyValueStorage(kv.logger, &lockedKV{KV: kv.db, lock: lock})
+}
+
+// lockedKV is a kv database that holds the lock of its data dir
+type lockedKV struct {
+	KV
+	lock *dirLock
+}
+
+func (l *lockedKV) Close() error {
+	err := l.KV.Close()
+	if err := l.lock.release(); err != nil {
+		return err
+	}
+	return err
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockDir(t *testing.T) {
+	Register("test-lock", func(c map[string]interface{}, logger hclog.Logger) (Storage, error) {
+		return newTestStorage(), nil
+	})
+
+	dir, err := ioutil.TempDir("/tmp", "minimal_lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := map[string]interface{}{"path": filepath.Join(dir, "blockchain")}
+
+	s, err := Open("test-lock", config, hclog.NewNullLogger())
+	assert.NoError(t, err)
+
+	_, err = Open("test-lock", config, hclog.NewNullLogger())
+	assert.EqualError(t, err, fmt.Sprintf("datadir %s in use by PID %d", config["path"], os.Getpid()))
+
+	// the lock is released on close
+	assert.NoError(t, s.Close())
+
+	s, err = Open("test-lock", config, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+}
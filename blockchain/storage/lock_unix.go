@@ -0,0 +1,25 @@
+// +build !windows
+
+package storage
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile opens the file and locks it. The lock is released by the
+// kernel when the file is closed, even if the process crashes
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return file, nil
+}
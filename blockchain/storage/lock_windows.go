@@ -0,0 +1,34 @@
+// +build windows
+
+package storage
+
+import (
+	"os"
+	"syscall"
+)
+
+// errorSharingViolation is the error of opening a file already opened without sharing
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens the file without sharing the writes, so it cannot be opened for
+// writing until it is closed. The reads are shared to report the PID of the owner
+func lockFile(path string) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	handle, err := syscall.CreateFile(name,
+		syscall.GENERIC_READ|syscall.GENERIC_WRITE,
+		syscall.FILE_SHARE_READ,
+		nil,
+		syscall.OPEN_ALWAYS,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		if err == errorSharingViolation {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return os.NewFile(uintptr(handle), path), nil
+}
@@ -1,35 +1,267 @@
 package memory
 
 import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/0xPolygon/minimal/blockchain/storage"
-	"github.com/0xPolygon/minimal/helper/hex"
 	"github.com/hashicorp/go-hclog"
 )
 
+func init() {
+	storage.Register("memory", Factory)
+}
+
+// Factory creates a memory storage, the config is not used
+func Factory(config map[string]interface{}, logger hclog.Logger) (storage.Storage, error) {
+	s, err := NewMemoryStorage(logger)
+	if err != nil {
+		return nil, err
+	}
+	return s.Storage, nil
+}
+
+// Storage is an in memory blockchain storage whose entries can be saved and
+// restored, so that the tests can run divergent scenarios on the same chain
+type Storage struct {
+	storage.Storage
+
+	kv     *memoryKV
+	logger hclog.Logger
+}
+
 // NewMemoryStorage creates the new storage reference with inmemory
-func NewMemoryStorage(logger hclog.Logger) (storage.Storage, error) {
-	db := &memoryKV{map[string][]byte{}}
-	return storage.NewKeyValueStorage(logger, db), nil
+func NewMemoryStorage(logger hclog.Logger) (*Storage, error) {
+	return newStorage(logger, namespaces{}), nil
+}
+
+func newStorage(logger hclog.Logger, db namespaces) *Storage {
+	kv := &memoryKV{db: db}
+	return &Storage{
+		Storage: storage.NewKeyValueStorage(logger, kv),
+		kv:      kv,
+		logger:  logger,
+	}
+}
+
+// Checkpoint is a copy of the entries of a memory storage. Snapshot is not
+// used as the name since it writes the storage to disk in the Storage interface
+type Checkpoint struct {
+	db namespaces
+}
+
+// Checkpoint returns a copy of the current entries of the storage
+func (s *Storage) Checkpoint() *Checkpoint {
+	s.kv.lock.RLock()
+	defer s.kv.lock.RUnlock()
+
+	return &Checkpoint{db: s.kv.db.copy()}
+}
+
+// Restore replaces the entries of the storage with the ones of the checkpoint.
+// The checkpoint is not modified and it can be restored again
+func (s *Storage) Restore(c *Checkpoint) {
+	db := c.db.copy()
+
+	s.kv.lock.Lock()
+	defer s.kv.lock.Unlock()
+
+	s.kv.db = db
+}
+
+// Fork returns a new storage with a copy of the entries, the writes
+// on any of the storages are not visible in the other one
+func (s *Storage) Fork() *Storage {
+	return newStorage(s.logger, s.Checkpoint().db)
+}
+
+// namespaces splits the entries in a map per namespace (the first byte of
+// the key), so that iterating over a namespace does not visit the rest
+type namespaces map[string]map[string][]byte
+
+func namespaceKey(k []byte) string {
+	if len(k) == 0 {
+		return ""
+	}
+	return string(k[:1])
+}
+
+func (n namespaces) get(k []byte) ([]byte, bool) {
+	v, ok := n[namespaceKey(k)][string(k)]
+	return v, ok
+}
+
+func (n namespaces) set(k []byte, v []byte) {
+	ns, ok := n[namespaceKey(k)]
+	if !ok {
+		ns = map[string][]byte{}
+		n[namespaceKey(k)] = ns
+	}
+	ns[string(k)] = v
+}
+
+func (n namespaces) delete(k []byte) {
+	delete(n[namespaceKey(k)], string(k))
+}
+
+func (n namespaces) copy() namespaces {
+	res := make(namespaces, len(n))
+	for name, ns := range n {
+		entries := make(map[string][]byte, len(ns))
+		for k, v := range ns {
+			entries[k] = append([]byte{}, v...)
+		}
+		res[name] = entries
+	}
+	return res
 }
 
 // memoryKV is an in memory implementation of the kv storage
 type memoryKV struct {
-	db map[string][]byte
+	db   namespaces
+	lock sync.RWMutex
 }
 
 func (m *memoryKV) Set(p []byte, v []byte) error {
-	m.db[hex.EncodeToHex(p)] = v
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	// the value is copied, the caller may reuse the slice
+	m.db.set(p, append([]byte{}, v...))
 	return nil
 }
 
 func (m *memoryKV) Get(p []byte) ([]byte, bool, error) {
-	v, ok := m.db[hex.EncodeToHex(p)]
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	v, ok := m.db.get(p)
 	if !ok {
 		return nil, false, nil
 	}
-	return v, true, nil
+	return append([]byte{}, v...), true, nil
+}
+
+func (m *memoryKV) NewIterator(prefix []byte) storage.Iterator {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	// copy the matching entries so that the iteration
+	// is not affected by the writes that happen after this
+	iter := &memoryIterator{index: -1}
+	add := func(ns map[string][]byte) {
+		for k, v := range ns {
+			if strings.HasPrefix(k, string(prefix)) {
+				iter.keys = append(iter.keys, []byte(k))
+				iter.values = append(iter.values, v)
+			}
+		}
+	}
+	if len(prefix) == 0 {
+		for _, ns := range m.db {
+			add(ns)
+		}
+	} else {
+		add(m.db[namespaceKey(prefix)])
+	}
+	sort.Sort(iter)
+	return iter
+}
+
+// memoryIterator iterates over a sorted copy of the memory entries
+type memoryIterator struct {
+	keys   [][]byte
+	values [][]byte
+	index  int
+}
+
+func (i *memoryIterator) Len() int {
+	return len(i.keys)
+}
+
+func (i *memoryIterator) Less(a, b int) bool {
+	return bytes.Compare(i.keys[a], i.keys[b]) < 0
+}
+
+func (i *memoryIterator) Swap(a, b int) {
+	i.keys[a], i.keys[b] = i.keys[b], i.keys[a]
+	i.values[a], i.values[b] = i.values[b], i.values[a]
+}
+
+func (i *memoryIterator) Next() bool {
+	if i.index < len(i.keys) {
+		i.index++
+	}
+	return i.index < len(i.keys)
+}
+
+func (i *memoryIterator) Key() []byte {
+	return i.keys[i.index]
+}
+
+func (i *memoryIterator) Value() []byte {
+	return i.values[i.index]
+}
+
+func (i *memoryIterator) Error() error {
+	return nil
+}
+
+func (i *memoryIterator) Release() {
+	i.keys, i.values = nil, nil
+}
+
+func (m *memoryKV) Snapshot(dst string) error {
+	return fmt.Errorf("snapshots are not supported by the memory storage")
+}
+
+func (m *memoryKV) Compact(start, end []byte) error {
+	// there is nothing to reclaim, deleted entries are removed from the map
+	return nil
 }
 
 func (m *memoryKV) Close() error {
 	return nil
 }
+
+func (m *memoryKV) NewBatch() storage.Batch {
+	return &memoryBatch{db: m}
+}
+
+type memoryOp struct {
+	key   []byte
+	value []byte
+	del   bool
+}
+
+// memoryBatch is a batch of writes on the in memory storage
+type memoryBatch struct {
+	db  *memoryKV
+	ops []memoryOp
+}
+
+func (b *memoryBatch) Put(k []byte, v []byte) {
+	b.ops = append(b.ops, memoryOp{key: append([]byte{}, k...), value: append([]byte{}, v...)})
+}
+
+func (b *memoryBatch) Delete(k []byte) {
+	b.ops = append(b.ops, memoryOp{key: append([]byte{}, k...), del: true})
+}
+
+func (b *memoryBatch) Commit() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	for _, op := range b.ops {
+		if op.del {
+			b.db.db.delete(op.key)
+		} else {
+			b.db.db.set(op.key, op.value)
+		}
+	}
+	b.ops = nil
+	return nil
+}
@@ -4,6 +4,10 @@ import (
 	"testing"
 
 	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestStorage(t *testing.T) {
@@ -13,3 +17,95 @@ func TestStorage(t *testing.T) {
 	}
 	storage.TestStorage(t, f)
 }
+
+func TestCheckpoint(t *testing.T) {
+	s, _ := NewMemoryStorage(nil)
+
+	hash1, hash2 := types.StringToHash("1"), types.StringToHash("2")
+	assert.NoError(t, s.WriteCanonicalHash(1, hash1))
+
+	c := s.Checkpoint()
+
+	assert.NoError(t, s.WriteCanonicalHash(1, hash2))
+	assert.NoError(t, s.WriteCanonicalHash(2, hash2))
+
+	// the checkpoint can be restored more than once
+	for i := 0; i < 2; i++ {
+		s.Restore(c)
+
+		hash, ok := s.ReadCanonicalHash(1)
+		assert.True(t, ok)
+		assert.Equal(t, hash1, hash)
+
+		_, ok = s.ReadCanonicalHash(2)
+		assert.False(t, ok)
+
+		assert.NoError(t, s.WriteCanonicalHash(2, hash2))
+	}
+}
+
+func TestFork(t *testing.T) {
+	s, _ := NewMemoryStorage(nil)
+
+	hash1, hash2 := types.StringToHash("1"), types.StringToHash("2")
+	assert.NoError(t, s.WriteCanonicalHash(1, hash1))
+
+	fork := s.Fork()
+	assert.NoError(t, fork.WriteCanonicalHash(1, hash2))
+	assert.NoError(t, s.WriteCanonicalHash(2, hash1))
+
+	hash, _ := s.ReadCanonicalHash(1)
+	assert.Equal(t, hash1, hash)
+
+	hash, _ = fork.ReadCanonicalHash(1)
+	assert.Equal(t, hash2, hash)
+
+	_, ok := fork.ReadCanonicalHash(2)
+	assert.False(t, ok)
+}
+
+func TestValuesCopied(t *testing.T) {
+	s, _ := NewMemoryStorage(nil)
+
+	blob := []byte{1, 2, 3}
+	assert.NoError(t, s.WriteSnapshot(types.Hash{}, blob))
+
+	// neither the written nor the read slices share memory with the storage
+	blob[0] = 0xff
+	v, _ := s.ReadSnapshot(types.Hash{})
+	assert.Equal(t, []byte{1, 2, 3}, v)
+
+	v[0] = 0xff
+	v, _ = s.ReadSnapshot(types.Hash{})
+	assert.Equal(t, []byte{1, 2, 3}, v)
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	s, err := storage.Open("memory", map[string]interface{}{"metrics": registry}, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer s.Close()
+
+	assert.NoError(t, s.WriteCanonicalHash(1, types.StringToHash("1")))
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	// the write of the canonical hash is recorded by the operations counter
+	writes := float64(0)
+	for _, f := range families {
+		if f.GetName() != "minimal_storage_operations_total" {
+			continue
+		}
+		for _, m := range f.Metric {
+			labels := map[string]string{}
+			for _, l := range m.Label {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["op"] == "write" && labels["namespace"] == "canonical" {
+				writes = m.Counter.GetValue()
+			}
+		}
+	}
+	assert.Equal(t, float64(1), writes)
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics are the counters and the latencies of the operations of a kv
+// database, labelled by operation and by the namespace of the key
+type metrics struct {
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+}
+
+var metricLabels = []string{"op", "namespace"}
+
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	operations := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "minimal",
+		Subsystem: "storage",
+		Name:      "operations_total",
+		Help:      "Number of operations on the blockchain storage",
+	}, metricLabels)
+	errors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "minimal",
+		Subsystem: "storage",
+		Name:      "errors_total",
+		Help:      "Number of failed operations on the blockchain storage",
+	}, metricLabels)
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "minimal",
+		Subsystem: "storage",
+		Name:      "operation_duration_seconds",
+		Help:      "Latency of the operations on the blockchain storage",
+		Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+	}, metricLabels)
+
+	m := &metrics{}
+	c, err := register(registerer, operations)
+	if err != nil {
+		return nil, err
+	}
+	m.operations = c.(*prometheus.CounterVec)
+
+	if c, err = register(registerer, errors); err != nil {
+		return nil, err
+	}
+	m.errors = c.(*prometheus.CounterVec)
+
+	if c, err = register(registerer, latency); err != nil {
+		return nil, err
+	}
+	m.latency = c.(*prometheus.HistogramVec)
+	return m, nil
+}
+
+// register registers the collector or returns the one already registered with
+// the same name, so that a storage opened again in the same process reuses it
+func register(registerer prometheus.Registerer, c prometheus.Collector) (prometheus.Collector, error) {
+	if err := registerer.Register(c); err != nil {
+		existing, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			return nil, err
+		}
+		return existing.ExistingCollector, nil
+	}
+	return c, nil
+}
+
+func (m *metrics) observe(op, namespace string, start time.Time, err error) {
+	m.operations.WithLabelValues(op, namespace).Inc()
+	m.latency.WithLabelValues(op, namespace).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(op, namespace).Inc()
+	}
+}
+
+// setupMetrics instruments the kv database of the storage with the metrics
+// of the registerer. The metrics measure the backend, below the encryption.
+// Only the storages built on a kv database can be instrumented, any other
+// storage is returned as is and a warning is logged
+func setupMetrics(s Storage, registerer prometheus.Registerer, logger hclog.Logger) (Storage, error) {
+	if registerer == nil {
+		return s, nil
+	}
+	kv, ok := s.(*KeyValueStorage)
+	if !ok {
+		logger.Warn("storage backend is not a kv database, no metrics are recorded", "type", fmt.Sprintf("%T", s))
+		return s, nil
+	}
+	m, err := newMetrics(registerer)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyValueStorage(kv.logger, &metricsKV{db: kv.db, metrics: m}), nil
+}
+
+// metricsKV records the metrics of the operations of a kv database
+type metricsKV struct {
+	db      KV
+	metrics *metrics
+}
+
+func (m *metricsKV) Set(k []byte, v []byte) error {
+	start := time.Now()
+	err := m.db.Set(k, v)
+	m.metrics.observe("write", namespaceName(k), start, err)
+	return err
+}
+
+func (m *metricsKV) Get(k []byte) ([]byte, bool, error) {
+	start := time.Now()
+	v, ok, err := m.db.Get(k)
+	m.metrics.observe("read", namespaceName(k), start, err)
+	return v, ok, err
+}
+
+func (m *metricsKV) NewBatch() Batch {
+	return &metricsBatch{kv: m, batch: m.db.NewBatch()}
+}
+
+func (m *metricsKV) NewIterator(prefix []byte) Iterator {
+	m.metrics.operations.WithLabelValues("iterate", namespaceName(prefix)).Inc()
+	return m.db.NewIterator(prefix)
+}
+
+func (m *metricsKV) Snapshot(dst string) error {
+	return m.db.Snapshot(dst)
+}
+
+func (m *metricsKV) Compact(start, end []byte) error {
+	return m.db.Compact(start, end)
+}
+
+func (m *metricsKV) Close() error {
+	return m.db.Close()
+}
+
+type metricsOp struct {
+	op        string
+	namespace string
+}
+
+// metricsBatch counts the writes and the deletes of a batch when it is committed.
+// The latency of the commit is recorded for the whole batch
+type metricsBatch struct {
+	kv    *metricsKV
+	batch Batch
+	ops   []metricsOp
+}
+
+func (b *metricsBatch) Put(k []byte, v []byte) {
+	b.ops = append(b.ops, metricsOp{"write", namespaceName(k)})
+	b.batch.Put(k, v)
+}
+
+func (b *metricsBatch) Delete(k []byte) {
+	b.ops = append(b.ops, metricsOp{"delete", namespaceName(k)})
+	b.batch.Delete(k)
+}
+
+func (b *metricsBatch) Commit() error {
+	start := time.Now()
+	err := b.batch.Commit()
+	b.kv.metrics.observe("commit", "batch", start, err)
+
+	for _, op := range b.ops {
+		b.kv.metrics.operations.WithLabelValues(op.op, op.namespace).Inc()
+		if err != nil {
+			b.kv.metrics.errors.WithLabelValues(op.op, op.namespace).Inc()
+		}
+	}
+	b.ops = nil
+	return err
+}
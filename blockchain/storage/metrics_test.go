@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	Register("test-metrics", func(c map[string]interface{}, logger hclog.Logger) (Storage, error) {
+		return newTestStorage(), nil
+	})
+
+	registry := prometheus.NewRegistry()
+	s, err := Open("test-metrics", map[string]interface{}{"metrics": registry}, hclog.NewNullLogger())
+	assert.NoError(t, err)
+
+	m := s.(*KeyValueStorage).db.(*metricsKV).metrics
+	count := func(op, namespace string) float64 {
+		return testutil.ToFloat64(m.operations.WithLabelValues(op, namespace))
+	}
+
+	// opening a new storage writes the schema version
+	assert.Equal(t, float64(1), count("write", "version"))
+
+	assert.NoError(t, s.WriteDiff(hash1, big.NewInt(10)))
+	_, ok := s.ReadDiff(hash1)
+	assert.True(t, ok)
+	_, ok = s.ReadDiff(hash2)
+	assert.False(t, ok)
+
+	assert.Equal(t, float64(1), count("write", "difficulties"))
+	assert.Equal(t, float64(2), count("read", "difficulties"))
+
+	batch := s.NewBatch()
+	batch.Put(key(CANONICAL, encodeUint(1)), hash1.Bytes())
+	batch.Delete(key(CANONICAL, encodeUint(2)))
+	assert.NoError(t, batch.Commit())
+
+	assert.Equal(t, float64(1), count("write", "canonical"))
+	assert.Equal(t, float64(1), count("delete", "canonical"))
+	assert.Equal(t, float64(1), count("commit", "batch"))
+	assert.Equal(t, 0, testutil.CollectAndCount(m.errors))
+
+	// a second storage in the same registry shares the collectors
+	s2, err := Open("test-metrics", map[string]interface{}{"metrics": registry}, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.Equal(t, m.operations, s2.(*KeyValueStorage).db.(*metricsKV).metrics.operations)
+
+	_, err = Open("test-metrics", map[string]interface{}{"metrics": "a"}, hclog.NewNullLogger())
+	assert.Error(t, err)
+}
+
+func TestMetrics_NotKV(t *testing.T) {
+	// a storage that is not built on a kv database is returned as is
+	s := &struct{ Storage }{newTestStorage()}
+	res, err := setupMetrics(s, prometheus.NewRegistry(), hclog.NewNullLogger())
+	assert.NoError(t, err)
+	assert.Equal(t, s, res)
+}
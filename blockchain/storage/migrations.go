@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Migration upgrades the layout of the storage to the next schema version.
+// A migration might run again if the node stops before the new version is
+// written, so it has to be safe to apply it twice
+type Migration struct {
+	Name    string
+	Migrate func(s Storage) error
+}
+
+// migrations is the ordered list of the schema upgrades. The migration at
+// index i upgrades the storage from version i+1 to version i+2
+var migrations = []Migration{}
+
+// SchemaVersion is the version of the storage layout of this client. The storages
+// created before the schema was versioned have the layout of the version 1
+var SchemaVersion = uint64(len(migrations)) + 1
+
+// Migrate writes the schema version of a new storage or runs the migrations
+// required to upgrade an existing storage to the current version
+func Migrate(s Storage, logger hclog.Logger) error {
+	return migrate(s, migrations, logger)
+}
+
+func migrate(s Storage, migrations []Migration, logger hclog.Logger) error {
+	target := uint64(len(migrations)) + 1
+
+	version, ok := s.ReadSchemaVersion()
+	if !ok {
+		if _, ok := s.ReadHeadHash(); !ok {
+			// empty storage
+			return s.WriteSchemaVersion(target)
+		}
+		version = 1
+	}
+	if version > target {
+		return fmt.Errorf("storage schema version %d is newer than the supported version %d", version, target)
+	}
+
+	for ; version < target; version++ {
+		m := migrations[version-1]
+
+		logger.Info("running storage migration", "name", m.Name, "from", version, "to", version+1)
+		if err := m.Migrate(s); err != nil {
+			return fmt.Errorf("migration '%s' failed: %v", m.Name, err)
+		}
+		if err := s.WriteSchemaVersion(version + 1); err != nil {
+			return err
+		}
+	}
+
+	// stamp the version on storages created before the versioning
+	if !ok {
+		return s.WriteSchemaVersion(target)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	logger := hclog.NewNullLogger()
+
+	applied := []string{}
+	migrations := []Migration{
+		{Name: "a", Migrate: func(s Storage) error { applied = append(applied, "a"); return nil }},
+		{Name: "b", Migrate: func(s Storage) error { applied = append(applied, "b"); return nil }},
+	}
+
+	writeHead := func(s Storage) {
+		h := &types.Header{ExtraData: []byte{}}
+		h.ComputeHash()
+		assert.NoError(t, s.WriteCanonicalHeader(h, big.NewInt(1)))
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		applied = applied[:0]
+		s := newTestStorage()
+
+		assert.NoError(t, migrate(s, migrations, logger))
+		assert.Empty(t, applied)
+
+		version, ok := s.ReadSchemaVersion()
+		assert.True(t, ok)
+		assert.Equal(t, uint64(3), version)
+	})
+
+	t.Run("Unversioned", func(t *testing.T) {
+		applied = applied[:0]
+		s := newTestStorage()
+		writeHead(s)
+
+		assert.NoError(t, migrate(s, migrations, logger))
+		assert.Equal(t, []string{"a", "b"}, applied)
+
+		version, _ := s.ReadSchemaVersion()
+		assert.Equal(t, uint64(3), version)
+
+		// nothing to do once it is upgraded
+		assert.NoError(t, migrate(s, migrations, logger))
+		assert.Len(t, applied, 2)
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		applied = applied[:0]
+		s := newTestStorage()
+		writeHead(s)
+		assert.NoError(t, s.WriteSchemaVersion(2))
+
+		assert.NoError(t, migrate(s, migrations, logger))
+		assert.Equal(t, []string{"b"}, applied)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		s := newTestStorage()
+		writeHead(s)
+
+		failing := []Migration{
+			migrations[0],
+			{Name: "c", Migrate: func(s Storage) error { return fmt.Errorf("failed") }},
+		}
+		assert.Error(t, migrate(s, failing, logger))
+
+		// the successful migrations are not applied again
+		version, _ := s.ReadSchemaVersion()
+		assert.Equal(t, uint64(2), version)
+	})
+
+	t.Run("Newer", func(t *testing.T) {
+		s := newTestStorage()
+		assert.NoError(t, s.WriteSchemaVersion(10))
+		assert.Error(t, migrate(s, migrations, logger))
+	})
+}
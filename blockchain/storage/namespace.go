@@ -0,0 +1,80 @@
+package storage
+
+// Namespace is a category of entries of the storage. The keys of a namespace
+// start with its prefix, so its entries are contiguous in the ordered backends
+// and they can be iterated and compacted without visiting the other ones
+type Namespace struct {
+	Name   string
+	Prefix []byte
+}
+
+// Range returns the range of keys [start, end) of the namespace
+func (n *Namespace) Range() ([]byte, []byte) {
+	start := append([]byte{}, n.Prefix...)
+	end := append([]byte{}, n.Prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] != 0xff {
+			end[i]++
+			return start, end[:i+1]
+		}
+	}
+	return start, nil
+}
+
+// Namespaces are the namespaces of the blockchain storage
+var Namespaces = []*Namespace{
+	{"headers", HEADER},
+	{"bodies", BODY},
+	{"receipts", RECEIPTS},
+	{"difficulties", DIFFICULTY},
+	{"canonical", CANONICAL},
+	{"tx_lookups", TX_LOOKUP_PREFIX},
+	{"blooms", BLOOM},
+	{"bloom_bits", BLOOM_BITS},
+	{"index_sections", INDEX_SECTION},
+	{"snapshots", SNAPSHOTS},
+	{"non_canonical", NON_CANONICAL},
+	{"forks", FORK},
+	{"head", HEAD},
+	{"pending", PENDING},
+	{"history", HISTORY},
+	{"version", VERSION},
+	{"quarantine", QUARANTINE},
+	{"encryption", ENCRYPTION},
+}
+
+// namespacesByPrefix indexes the namespaces by prefix, which is a single byte
+var namespacesByPrefix = map[string]*Namespace{}
+
+func init() {
+	for _, n := range Namespaces {
+		if len(n.Prefix) != 1 {
+			panic("storage: prefix of namespace " + n.Name + " is not a single byte")
+		}
+		if _, ok := namespacesByPrefix[string(n.Prefix)]; ok {
+			panic("storage: duplicated prefix for namespace " + n.Name)
+		}
+		namespacesByPrefix[string(n.Prefix)] = n
+	}
+}
+
+// LookupNamespace returns the namespace with the given name
+func LookupNamespace(name string) (*Namespace, bool) {
+	for _, n := range Namespaces {
+		if n.Name == name {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// namespaceName returns the name of the namespace of the key,
+// or 'other' if the key does not belong to any of them
+func namespaceName(k []byte) string {
+	if len(k) != 0 {
+		if n, ok := namespacesByPrefix[string(k[:1])]; ok {
+			return n.Name
+		}
+	}
+	return "other"
+}
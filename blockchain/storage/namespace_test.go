@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaces(t *testing.T) {
+	ns, ok := LookupNamespace("headers")
+	assert.True(t, ok)
+	assert.Equal(t, HEADER, ns.Prefix)
+
+	_, ok = LookupNamespace("unknown")
+	assert.False(t, ok)
+
+	start, end := ns.Range()
+	assert.Equal(t, []byte("h"), start)
+	assert.Equal(t, []byte("i"), end)
+
+	// the range of a prefix that ends in 0xff carries over
+	start, end = (&Namespace{Prefix: []byte{0x1, 0xff}}).Range()
+	assert.Equal(t, []byte{0x1, 0xff}, start)
+	assert.Equal(t, []byte{0x2}, end)
+
+	_, end = (&Namespace{Prefix: []byte{0xff}}).Range()
+	assert.Nil(t, end)
+
+	assert.Equal(t, "receipts", namespaceName(key(RECEIPTS, hash1.Bytes())))
+	assert.Equal(t, "other", namespaceName([]byte("z")))
+	assert.Equal(t, "other", namespaceName(nil))
+}
+
+func TestNamespaceIterator(t *testing.T) {
+	s := newTestStorage()
+
+	assert.NoError(t, s.WriteTxLookup(hash1, hash2))
+	assert.NoError(t, s.WriteTxLookup(hash2, hash1))
+	assert.NoError(t, s.WriteSchemaVersion(1))
+
+	ns, _ := LookupNamespace("tx_lookups")
+	iter := s.Iterator(ns.Prefix)
+	defer iter.Release()
+
+	num := 0
+	for iter.Next() {
+		assert.Equal(t, "tx_lookups", namespaceName(iter.Key()))
+		num++
+	}
+	assert.Equal(t, 2, num)
+}
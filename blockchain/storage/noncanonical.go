@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"encoding/binary"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+// NON_CANONICAL is the prefix for the index of the blocks that are not part of
+// the canonical chain, ordered by number so that the old ones can be pruned
+var NON_CANONICAL = []byte("n")
+
+// NonCanonicalBlock is an entry of the index of non canonical blocks
+type NonCanonicalBlock struct {
+	Number uint64
+	Hash   types.Hash
+}
+
+func encodeNonCanonicalKey(n uint64, hash types.Hash) []byte {
+	return append(encodeUint(n), hash.Bytes()...)
+}
+
+// ReadNonCanonicalBlocks returns the indexed non canonical blocks
+// with a number lower than limit, in order
+func ReadNonCanonicalBlocks(s Storage, limit uint64) ([]NonCanonicalBlock, error) {
+	iter := s.Iterator(NON_CANONICAL)
+	defer iter.Release()
+
+	res := []NonCanonicalBlock{}
+	for iter.Next() {
+		k := iter.Key()[len(NON_CANONICAL):]
+		if len(k) != 8+types.HashLength {
+			continue
+		}
+		n := binary.BigEndian.Uint64(k[:8])
+		if n >= limit {
+			break
+		}
+		res = append(res, NonCanonicalBlock{Number: n, Hash: types.BytesToHash(k[8:])})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
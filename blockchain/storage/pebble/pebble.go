@@ -0,0 +1,169 @@
+package pebble
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/cockroachdb/pebble"
+	"github.com/hashicorp/go-hclog"
+)
+
+func init() {
+	storage.Register("pebble", Factory)
+}
+
+// Factory creates a pebble storage
+func Factory(config map[string]interface{}, logger hclog.Logger) (storage.Storage, error) {
+	path, ok := config["path"]
+	if !ok {
+		return nil, fmt.Errorf("path not found")
+	}
+	pathStr, ok := path.(string)
+	if !ok {
+		return nil, fmt.Errorf("path is not a string")
+	}
+	return NewPebbleStorage(pathStr, logger)
+}
+
+// NewPebbleStorage creates the new storage reference with pebble
+func NewPebbleStorage(path string, logger hclog.Logger) (storage.Storage, error) {
+	db, err := pebble.Open(path, &pebble.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	kv := &pebbleKV{db}
+	return storage.NewKeyValueStorage(logger.Named("pebble"), kv), nil
+}
+
+// pebbleKV is the pebble implementation of the kv storage
+type pebbleKV struct {
+	db *pebble.DB
+}
+
+func (p *pebbleKV) Set(k []byte, v []byte) error {
+	return p.db.Set(k, v, pebble.NoSync)
+}
+
+func (p *pebbleKV) Get(k []byte) ([]byte, bool, error) {
+	data, closer, err := p.db.Get(k)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	defer closer.Close()
+
+	// the returned slice is only valid until the closer is called
+	v := make([]byte, len(data))
+	copy(v, data)
+	return v, true, nil
+}
+
+func (p *pebbleKV) NewIterator(prefix []byte) storage.Iterator {
+	iter := p.db.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: prefixEnd(prefix),
+	})
+	return &pebbleIterator{iter: iter}
+}
+
+// prefixEnd returns the first key after all the keys with the prefix,
+// or nil if there is none
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] != 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// pebbleIterator adapts the pebble iterator, which has to be positioned
+// with First, to the Next based iteration of the kv storage
+type pebbleIterator struct {
+	iter    *pebble.Iterator
+	started bool
+}
+
+func (i *pebbleIterator) Next() bool {
+	if !i.started {
+		i.started = true
+		return i.iter.First()
+	}
+	return i.iter.Next()
+}
+
+func (i *pebbleIterator) Key() []byte {
+	return i.iter.Key()
+}
+
+func (i *pebbleIterator) Value() []byte {
+	return i.iter.Value()
+}
+
+func (i *pebbleIterator) Error() error {
+	return i.iter.Error()
+}
+
+func (i *pebbleIterator) Release() {
+	i.iter.Close()
+}
+
+func (p *pebbleKV) Snapshot(dst string) error {
+	// the checkpoint hard links the immutable tables, so it is cheap even
+	// for large databases. The log is flushed first since the writes do not sync
+	return p.db.Checkpoint(dst, pebble.WithFlushedWAL())
+}
+
+func (p *pebbleKV) Compact(start, end []byte) error {
+	if start == nil {
+		start = []byte{}
+	}
+	if end == nil {
+		// pebble needs an upper bound, the bound is inclusive but it has
+		// to be after the start so use the successor of the last key
+		iter := p.db.NewIter(&pebble.IterOptions{LowerBound: start})
+		if !iter.Last() {
+			err := iter.Error()
+			iter.Close()
+			return err
+		}
+		end = append(append([]byte{}, iter.Key()...), 0)
+		if err := iter.Close(); err != nil {
+			return err
+		}
+	}
+	return p.db.Compact(start, end)
+}
+
+func (p *pebbleKV) Close() error {
+	return p.db.Close()
+}
+
+func (p *pebbleKV) NewBatch() storage.Batch {
+	return &pebbleBatch{p.db.NewBatch()}
+}
+
+// pebbleBatch is a batch of writes on pebble
+type pebbleBatch struct {
+	batch *pebble.Batch
+}
+
+func (b *pebbleBatch) Put(k []byte, v []byte) {
+	// the batch copies the key and the value, it does not return errors
+	_ = b.batch.Set(k, v, nil)
+}
+
+func (b *pebbleBatch) Delete(k []byte) {
+	_ = b.batch.Delete(k, nil)
+}
+
+func (b *pebbleBatch) Commit() error {
+	defer b.batch.Close()
+
+	return b.batch.Commit(pebble.NoSync)
+}
@@ -0,0 +1,44 @@
+package pebble
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/hashicorp/go-hclog"
+)
+
+func newStorage(t *testing.T) (storage.Storage, func()) {
+	path, err := ioutil.TempDir("/tmp", "minimal_storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewPebbleStorage(path, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	close := func() {
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s, close
+}
+
+func TestStorage(t *testing.T) {
+	storage.TestStorage(t, newStorage)
+}
+
+func TestSnapshot(t *testing.T) {
+	path, err := ioutil.TempDir("/tmp", "minimal_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	storage.TestSnapshot(t, newStorage, filepath.Join(path, "snapshot"), func(path string) (storage.Storage, error) {
+		return NewPebbleStorage(path, hclog.NewNullLogger())
+	})
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	factories     = map[string]Factory{}
+	factoriesLock sync.RWMutex
+)
+
+// Register makes a storage backend available by name. It is meant to be
+// called from the init function of the backend package and it panics
+// if the name is already registered
+func Register(name string, factory Factory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+
+	if factory == nil {
+		panic("storage: nil factory for backend " + name)
+	}
+	if _, ok := factories[name]; ok {
+		panic("storage: backend " + name + " registered twice")
+	}
+	factories[name] = factory
+}
+
+// Backends returns the names of the registered storage backends
+func Backends() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+
+	names := []string{}
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open creates a storage with the backend registered under the given name
+// and migrates it to the current schema version. The values are encrypted
+// if the config includes an encryption_key, the operations are recorded
+// in the metrics prometheus.Registerer if there is one and the values are
+// cached with the cache sizes per namespace name if there are any.
+// The data dir in the path, if any, is locked until the storage is closed
+func Open(name string, config map[string]interface{}, logger hclog.Logger) (Storage, error) {
+	factoriesLock.RLock()
+	factory, ok := factories[name]
+	factoriesLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("storage backend '%s' not found", name)
+	}
+
+	// the data dir is locked before the backend opens it, so that a second
+	// process fails with the PID of the first one instead of a backend error
+	var lock *dirLock
+	if path, ok := config["path"].(string); ok && path != "" {
+		var err error
+		if lock, err = lockDir(path); err != nil {
+			return nil, err
+		}
+	}
+	s, err := factory(config, logger)
+	if err != nil {
+		lock.release()
+		return nil, err
+	}
+	s = setupLock(s, lock)
+
+	if raw, ok := config["metrics"]; ok {
+		registerer, ok := raw.(prometheus.Registerer)
+		if !ok {
+			s.Close()
+			return nil, fmt.Errorf("metrics is not a prometheus registerer")
+		}
+		instrumented, err := setupMetrics(s, registerer, logger)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s = instrumented
+	}
+
+	var encryptionKey []byte
+	if raw, ok := config["encryption_key"]; ok {
+		if encryptionKey, ok = raw.([]byte); !ok {
+			s.Close()
+			return nil, fmt.Errorf("encryption key is not a byte slice")
+		}
+	}
+	encrypted, err := setupEncryption(s, encryptionKey)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	s = encrypted
+
+	if raw, ok := config["cache"]; ok {
+		sizes, ok := raw.(map[string]int)
+		if !ok {
+			s.Close()
+			return nil, fmt.Errorf("cache is not a map of namespaces to sizes")
+		}
+		cached, err := setupCache(s, sizes)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s = cached
+	}
+
+	if err := Migrate(s, logger); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	var config map[string]interface{}
+
+	Register("test-registry", func(c map[string]interface{}, logger hclog.Logger) (Storage, error) {
+		config = c
+		return newTestStorage(), nil
+	})
+	assert.Contains(t, Backends(), "test-registry")
+
+	path, err := ioutil.TempDir("/tmp", "minimal_storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	s, err := Open("test-registry", map[string]interface{}{"path": path}, hclog.NewNullLogger())
+	assert.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, path, config["path"])
+
+	// new storages are stamped with the schema version
+	version, ok := s.ReadSchemaVersion()
+	assert.True(t, ok)
+	assert.Equal(t, SchemaVersion, version)
+
+	_, err = Open("unknown", nil, hclog.NewNullLogger())
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		Register("test-registry", func(c map[string]interface{}, logger hclog.Logger) (Storage, error) {
+			return nil, nil
+		})
+	})
+}
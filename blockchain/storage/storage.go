@@ -40,6 +40,41 @@ type Storage interface {
 	WriteTxLookup(hash types.Hash, blockHash types.Hash) error
 	ReadTxLookup(hash types.Hash) (types.Hash, bool)
 
+	WriteBloom(hash types.Hash, bloom types.Bloom) error
+	ReadBloom(hash types.Hash) (types.Bloom, bool)
+
+	WriteBloomBits(bit uint, section uint64, bits []byte) error
+	ReadBloomBits(bit uint, section uint64) ([]byte, bool)
+
+	WriteIndexSectionHead(index string, section uint64, hash types.Hash) error
+	ReadIndexSectionHead(index string, section uint64) (types.Hash, bool)
+
+	ReadSchemaVersion() (uint64, bool)
+	WriteSchemaVersion(version uint64) error
+
+	ReadMode() (string, bool)
+	WriteMode(mode string) error
+	ReadHistoryTail() (uint64, bool)
+
+	// ReadPendingBlock returns the block marked as being written, the marker
+	// is set and cleared in the batches of the BatchWriter
+	ReadPendingBlock() (types.Hash, bool)
+
+	NewBatch() Batch
+
+	// Iterator returns an ordered iterator over the raw entries whose key starts
+	// with the prefix. The iterator reads a snapshot of the storage taken when it
+	// is created and it has to be released after use
+	Iterator(prefix []byte) Iterator
+
+	// Snapshot writes a consistent copy of the storage to
+	// the dst directory while it is still in use
+	Snapshot(dst string) error
+
+	// Compact compacts the underlying data of the range of keys [start, end),
+	// a nil start or end means the beginning or the end of the keys
+	Compact(start, end []byte) error
+
 	Close() error
 }
 
@@ -49,6 +49,30 @@ func TestStorage(t *testing.T, m MockStorage) {
 	t.Run("", func(t *testing.T) {
 		testReceipts(t, m)
 	})
+	t.Run("", func(t *testing.T) {
+		testBloom(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testIndexSectionHead(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testBatch(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testSchemaVersion(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testCheck(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testIterator(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testCompact(t, m)
+	})
+	t.Run("", func(t *testing.T) {
+		testPendingBlock(t, m)
+	})
 }
 
 func testCanonicalChain(t *testing.T, m MockStorage) {
@@ -415,3 +439,277 @@ func testWriteCanonicalHeader(t *testing.T, m MockStorage) {
 		t.Fatal("canonical hash not correct")
 	}
 }
+
+func testBloom(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	bloom := types.Bloom{0x1, 0x2}
+	assert.NoError(t, s.WriteBloom(hash1, bloom))
+
+	found, ok := s.ReadBloom(hash1)
+	assert.True(t, ok)
+	assert.Equal(t, bloom, found)
+
+	_, ok = s.ReadBloom(hash2)
+	assert.False(t, ok)
+
+	// bloom bits are indexed by bit and section
+	assert.NoError(t, s.WriteBloomBits(1, 0, []byte{0x1}))
+	assert.NoError(t, s.WriteBloomBits(1, 1, []byte{0x2}))
+	assert.NoError(t, s.WriteBloomBits(2, 0, []byte{0x3}))
+
+	bits, ok := s.ReadBloomBits(1, 1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x2}, bits)
+
+	bits, ok = s.ReadBloomBits(2, 0)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{0x3}, bits)
+
+	_, ok = s.ReadBloomBits(2, 1)
+	assert.False(t, ok)
+
+}
+
+func testIndexSectionHead(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	assert.NoError(t, s.WriteIndexSectionHead("a", 0, hash1))
+	assert.NoError(t, s.WriteIndexSectionHead("b", 0, hash2))
+
+	head, ok := s.ReadIndexSectionHead("a", 0)
+	assert.True(t, ok)
+	assert.Equal(t, hash1, head)
+
+	head, ok = s.ReadIndexSectionHead("b", 0)
+	assert.True(t, ok)
+	assert.Equal(t, hash2, head)
+
+	_, ok = s.ReadIndexSectionHead("a", 1)
+	assert.False(t, ok)
+}
+
+func testSchemaVersion(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	_, ok := s.ReadSchemaVersion()
+	assert.False(t, ok)
+
+	assert.NoError(t, s.WriteSchemaVersion(2))
+
+	version, ok := s.ReadSchemaVersion()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), version)
+}
+
+func testIterator(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	for _, n := range []uint64{3, 1, 2} {
+		assert.NoError(t, s.WriteCanonicalHash(n, types.StringToHash(fmt.Sprint(n))))
+	}
+	assert.NoError(t, s.WriteDiff(hash1, big.NewInt(1)))
+
+	iter := s.Iterator(CANONICAL)
+	defer iter.Release()
+
+	// writes after the iterator is created are not visible
+	assert.NoError(t, s.WriteCanonicalHash(4, hash1))
+
+	nums := []uint64{}
+	for iter.Next() {
+		assert.Equal(t, CANONICAL, iter.Key()[:1])
+		nums = append(nums, decodeUint(iter.Key()[1:]))
+		assert.Equal(t, types.StringToHash(fmt.Sprint(nums[len(nums)-1])).Bytes(), iter.Value())
+	}
+	assert.NoError(t, iter.Error())
+	assert.Equal(t, []uint64{1, 2, 3}, nums)
+
+	// empty prefix
+	iter2 := s.Iterator(BLOOM)
+	defer iter2.Release()
+	assert.False(t, iter2.Next())
+}
+
+func testPendingBlock(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	_, ok := s.ReadPendingBlock()
+	assert.False(t, ok)
+
+	batch := NewBatchWriter(s)
+	batch.PutPendingBlock(hash1)
+	assert.NoError(t, batch.Commit())
+
+	hash, ok := s.ReadPendingBlock()
+	assert.True(t, ok)
+	assert.Equal(t, hash1, hash)
+
+	batch = NewBatchWriter(s)
+	batch.DeletePendingBlock()
+	assert.NoError(t, batch.Commit())
+
+	_, ok = s.ReadPendingBlock()
+	assert.False(t, ok)
+}
+
+func testCompact(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	for i := uint64(0); i < 100; i++ {
+		assert.NoError(t, s.WriteCanonicalHash(i, types.StringToHash(fmt.Sprint(i))))
+	}
+	batch := s.NewBatch()
+	for i := uint64(0); i < 50; i++ {
+		batch.Delete(key(CANONICAL, encodeUint(i)))
+	}
+	assert.NoError(t, batch.Commit())
+
+	assert.NoError(t, s.Compact(key(CANONICAL, encodeUint(10)), key(CANONICAL, encodeUint(60))))
+	assert.NoError(t, s.Compact(nil, nil))
+
+	for i := uint64(0); i < 100; i++ {
+		hash, ok := s.ReadCanonicalHash(i)
+		if i < 50 {
+			assert.False(t, ok)
+		} else {
+			assert.True(t, ok)
+			assert.Equal(t, types.StringToHash(fmt.Sprint(i)), hash)
+		}
+	}
+}
+
+func testCheck(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	kv, ok := s.(*KeyValueStorage)
+	if !ok {
+		t.Skip("not a key value storage")
+	}
+
+	for i := uint64(0); i < 3; i++ {
+		h := &types.Header{Number: i, ExtraData: []byte{}}
+		h.ComputeHash()
+		assert.NoError(t, s.WriteCanonicalHeader(h, big.NewInt(1)))
+	}
+	// canonical entry without a header
+	assert.NoError(t, s.WriteCanonicalHash(3, hash1))
+
+	report, err := kv.Check(RepairDrop)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), report.Entries["headers"])
+	assert.Equal(t, uint64(4), report.Entries["canonical"])
+	assert.Len(t, report.Corrupted, 1)
+
+	_, ok = s.ReadCanonicalHash(3)
+	assert.False(t, ok)
+	_, ok = s.ReadCanonicalHash(2)
+	assert.True(t, ok)
+}
+
+func testBatch(t *testing.T, m MockStorage) {
+	s, close := m(t)
+	defer close()
+
+	h := &types.Header{
+		Number:    10,
+		ExtraData: []byte{0x1},
+	}
+	h.ComputeHash()
+
+	body := &types.Body{
+		Transactions: []*types.Transaction{
+			{Nonce: 1, GasPrice: big.NewInt(1), Value: big.NewInt(1), V: 1},
+		},
+	}
+	body.Transactions[0].ComputeHash()
+
+	batch := NewBatchWriter(s)
+	batch.PutCanonicalHeader(h, big.NewInt(100))
+	batch.PutBody(h.Hash, body)
+	batch.PutTxLookup(body.Transactions[0].Hash, h.Hash)
+	batch.PutReceipts(h.Hash, []*types.Receipt{{CumulativeGasUsed: 10}})
+	batch.PutBloom(h.Hash, types.Bloom{0x1})
+
+	// nothing is written until the batch is committed
+	_, err := s.ReadHeader(h.Hash)
+	assert.Equal(t, ErrNotFound, err)
+
+	assert.NoError(t, batch.Commit())
+
+	hh, err := s.ReadHeader(h.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Hash, hh.ComputeHash().Hash)
+
+	num, ok := s.ReadHeadNumber()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), num)
+
+	diff, ok := s.ReadDiff(h.Hash)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(100), diff.Uint64())
+
+	bb, err := s.ReadBody(h.Hash)
+	assert.NoError(t, err)
+	assert.Len(t, bb.Transactions, 1)
+
+	blockHash, ok := s.ReadTxLookup(body.Transactions[0].Hash)
+	assert.True(t, ok)
+	assert.Equal(t, h.Hash, blockHash)
+
+	receipts, err := s.ReadReceipts(h.Hash)
+	assert.NoError(t, err)
+	assert.Len(t, receipts, 1)
+
+	_, ok = s.ReadBloom(h.Hash)
+	assert.True(t, ok)
+
+	// deletes are applied in order with the puts
+	raw := NewBatchWriter(s)
+	raw.batch.Delete(key(BLOOM, h.Hash.Bytes()))
+	assert.NoError(t, raw.Commit())
+
+	_, ok = s.ReadBloom(h.Hash)
+	assert.False(t, ok)
+}
+
+// TestSnapshot tests that a snapshot of a storage only includes the writes made
+// before it. The snapshot is written to path and opened with open
+func TestSnapshot(t *testing.T, m MockStorage, path string, open func(path string) (Storage, error)) {
+	t.Helper()
+
+	s, close := m(t)
+	defer close()
+
+	h0 := &types.Header{Number: 1, ExtraData: []byte{}}
+	h0.ComputeHash()
+	h1 := &types.Header{Number: 2, ExtraData: []byte{}}
+	h1.ComputeHash()
+
+	assert.NoError(t, s.WriteCanonicalHeader(h0, big.NewInt(1)))
+	assert.NoError(t, s.Snapshot(path))
+	assert.NoError(t, s.WriteCanonicalHeader(h1, big.NewInt(2)))
+
+	// the destination must be empty
+	assert.Error(t, s.Snapshot(path))
+
+	snap, err := open(path)
+	assert.NoError(t, err)
+	defer snap.Close()
+
+	_, err = snap.ReadHeader(h0.Hash)
+	assert.NoError(t, err)
+	_, err = snap.ReadHeader(h1.Hash)
+	assert.Equal(t, ErrNotFound, err)
+
+	num, ok := snap.ReadHeadNumber()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), num)
+}
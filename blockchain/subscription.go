@@ -41,6 +41,7 @@ type subscription struct {
 	updateCh chan struct{}
 	closeCh  chan struct{}
 	elem     *eventElem
+	stream   *eventStream
 }
 
 func (s *subscription) GetEventCh() chan *Event {
@@ -56,8 +57,8 @@ func (s *subscription) GetEventCh() chan *Event {
 
 func (s *subscription) GetEvent() *Event {
 	for {
-		if s.elem.next != nil {
-			s.elem = s.elem.next
+		if next := s.stream.next(s.elem); next != nil {
+			s.elem = next
 			evnt := s.elem.event
 			return evnt
 		}
@@ -149,15 +150,25 @@ func (e *eventStream) subscribe() *subscription {
 		elem:     head,
 		updateCh: updateCh,
 		closeCh:  make(chan struct{}),
+		stream:   e,
 	}
 	return s
 }
 
+func (e *eventStream) next(elem *eventElem) *eventElem {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return elem.next
+}
+
 func (e *eventStream) Head() (*eventElem, chan struct{}) {
 	e.lock.Lock()
 	head := e.head
 
-	ch := make(chan struct{})
+	// buffered so that an update pushed while the subscriber
+	// is not waiting is not lost
+	ch := make(chan struct{}, 1)
 	if e.updateCh == nil {
 		e.updateCh = []chan struct{}{}
 	}
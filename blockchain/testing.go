@@ -1,8 +1,11 @@
 package blockchain
 
 import (
+	"errors"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/state"
@@ -158,7 +161,7 @@ func NewTestBlockchain(t *testing.T, headers []*types.Header) *Blockchain {
 	}
 
 	st := itrie.NewState(itrie.NewMemoryStorage())
-	b, err := NewBlockchain(hclog.NewNullLogger(), "", config, &MockVerifier{}, state.NewExecutor(config.Params, st))
+	b, err := NewBlockchain(hclog.NewNullLogger(), "", nil, config, &MockVerifier{}, state.NewExecutor(config.Params, st))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -191,13 +194,111 @@ func createGenesis(header *types.Header) *chain.Genesis {
 	return genesis
 }
 
+// ErrMockForkRejected is returned by the MockVerifier for the headers
+// that are not in the forced fork
+var ErrMockForkRejected = errors.New("header is not in the forced fork")
+
+// MockVerifier is a consensus for the tests, the headers are valid unless the
+// test sets an outcome for them. The zero value accepts all the headers
 type MockVerifier struct {
+	lock sync.Mutex
+
+	// results are the outcomes of the headers by hash
+	results map[types.Hash]error
+
+	// forks are the hashes of the headers the chain is forced to at a number
+	forks map[uint64]types.Hash
+
+	// verifyFn is called for the headers without an outcome, if set
+	verifyFn func(parent, header *types.Header) error
+
+	// delay is added to every verification
+	delay time.Duration
+
+	verified []*types.Header
+}
+
+// SetResult sets the outcome of the verification of the header with the hash
+func (m *MockVerifier) SetResult(hash types.Hash, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.results == nil {
+		m.results = map[types.Hash]error{}
+	}
+	m.results[hash] = err
+}
+
+// SetVerifyFn sets the verification of the headers without an outcome
+func (m *MockVerifier) SetVerifyFn(fn func(parent, header *types.Header) error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.verifyFn = fn
+}
+
+// SetDelay sets the time that every verification takes
+func (m *MockVerifier) SetDelay(d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.delay = d
+}
+
+// ForceFork rejects the headers at the number that do not have the hash,
+// as a consensus that finalized another branch of the chain would
+func (m *MockVerifier) ForceFork(number uint64, hash types.Hash) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.forks == nil {
+		m.forks = map[uint64]types.Hash{}
+	}
+	m.forks[number] = hash
+}
+
+// Verified returns the headers verified so far
+func (m *MockVerifier) Verified() []*types.Header {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]*types.Header{}, m.verified...)
 }
 
 func (m *MockVerifier) VerifyHeader(parent, header *types.Header) error {
+	m.lock.Lock()
+	m.verified = append(m.verified, header)
+	delay := m.delay
+	err, ok := m.results[header.Hash]
+	if hash, forced := m.forks[header.Number]; forced && hash != header.Hash {
+		err, ok = ErrMockForkRejected, true
+	}
+	verifyFn := m.verifyFn
+	m.lock.Unlock()
+
+	// the lock is not held during the delay so that concurrent
+	// verifications are delayed in parallel
+	if delay != 0 {
+		time.Sleep(delay)
+	}
+	if ok {
+		return err
+	}
+	if verifyFn != nil {
+		return verifyFn(parent, header)
+	}
 	return nil
 }
 
+// VerifyUncle implements the UncleVerifier interface, the uncles are
+// valid unless the test sets an outcome for them
+func (m *MockVerifier) VerifyUncle(parent, uncle *types.Header) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.results[uncle.Hash]
+}
+
 type mockExecutor struct {
 }
 
@@ -206,13 +307,19 @@ func (m *mockExecutor) ProcessBlock(parentRoot types.Hash, block *types.Block) (
 }
 
 func TestBlockchain(t *testing.T, genesis *chain.Genesis) *Blockchain {
+	return TestBlockchainWithVerifier(t, genesis, &MockVerifier{})
+}
+
+// TestBlockchainWithVerifier creates a test blockchain that verifies
+// the headers with the mock consensus
+func TestBlockchainWithVerifier(t *testing.T, genesis *chain.Genesis, verifier *MockVerifier) *Blockchain {
 	if genesis == nil {
 		genesis = &chain.Genesis{}
 	}
 	config := &chain.Chain{
 		Genesis: genesis,
 	}
-	b, err := NewBlockchain(hclog.NewNullLogger(), "", config, &MockVerifier{}, &mockExecutor{})
+	b, err := NewBlockchain(hclog.NewNullLogger(), "", nil, config, verifier, &mockExecutor{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,91 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+const (
+	// maxUncles is the maximum number of uncles of a block
+	maxUncles = 2
+
+	// maxUncleDepth is how far below the block an uncle can be, the
+	// uncle rewards are only positive up to this depth
+	maxUncleDepth = 7
+)
+
+var (
+	errTooManyUncles     = errors.New("too many uncles")
+	errUnclesNotAllowed  = errors.New("the consensus does not allow uncles")
+	errInvalidUncleDepth = errors.New("invalid uncle depth")
+)
+
+// UncleVerifier is implemented by the consensus engines whose blocks include
+// uncles, the blocks of the other engines cannot include any
+type UncleVerifier interface {
+	// VerifyUncle verifies the uncle header and its seal with its parent
+	VerifyUncle(parent, uncle *types.Header) error
+}
+
+// verifyUncles checks that the uncles of the block are at most two, that they
+// are siblings of one of the last ancestors and that they were not included
+// before. The ancestors of the block must be written
+func (b *Blockchain) verifyUncles(block *types.Block) error {
+	if len(block.Uncles) == 0 {
+		return nil
+	}
+	if len(block.Uncles) > maxUncles {
+		return errTooManyUncles
+	}
+	verifier, ok := b.consensus.(UncleVerifier)
+	if !ok {
+		return errUnclesNotAllowed
+	}
+
+	// the ancestors an uncle can descend from and the uncles they included
+	ancestors := map[types.Hash]*types.Header{}
+	included := map[types.Hash]struct{}{
+		block.Hash(): {},
+	}
+	hash := block.ParentHash()
+	for i := 0; i <= maxUncleDepth; i++ {
+		ancestor, ok := b.readHeader(hash)
+		if !ok {
+			break
+		}
+		ancestors[ancestor.Hash] = ancestor
+		if body, ok := b.readBody(ancestor.Hash); ok {
+			for _, uncle := range body.Uncles {
+				included[uncle.Hash] = struct{}{}
+			}
+		}
+		if ancestor.Number == 0 {
+			break
+		}
+		hash = ancestor.ParentHash
+	}
+
+	for _, uncle := range block.Uncles {
+		if _, ok := included[uncle.Hash]; ok {
+			return errDuplicateUncle
+		}
+		included[uncle.Hash] = struct{}{}
+
+		if _, ok := ancestors[uncle.Hash]; ok {
+			return errUncleIsAncestor
+		}
+		parent, ok := ancestors[uncle.ParentHash]
+		if !ok || uncle.ParentHash == block.ParentHash() {
+			return errDanglingUncle
+		}
+		if uncle.Number != parent.Number+1 || uncle.Number >= block.Number() || block.Number()-uncle.Number > maxUncleDepth {
+			return errInvalidUncleDepth
+		}
+		if err := verifier.VerifyUncle(parent, uncle); err != nil {
+			return fmt.Errorf("invalid uncle %s: %v", uncle.Hash, err)
+		}
+	}
+	return nil
+}
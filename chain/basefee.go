@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+const (
+	// InitialBaseFee is the base fee of the first block with a base fee
+	InitialBaseFee = 1000000000
+
+	// BaseFeeChangeDenominator bounds the change of the base fee
+	// between a block and its parent to 1/8
+	BaseFeeChangeDenominator = 8
+
+	// ElasticityMultiplier is the ratio between the gas limit of
+	// a block and the gas target used for the base fee
+	ElasticityMultiplier = 2
+)
+
+// CalcBaseFee returns the base fee of the child of the parent, nil before the
+// London fork. It raises when the parent used more gas than the target, half
+// of its gas limit, and lowers when it used less
+func CalcBaseFee(forks *Forks, parent *types.Header) *big.Int {
+	if forks == nil || !forks.IsLondon(parent.Number+1) {
+		return nil
+	}
+	// the parent is the last block before the fork
+	if parent.BaseFee == nil {
+		return big.NewInt(InitialBaseFee)
+	}
+
+	gasTarget := parent.GasLimit / ElasticityMultiplier
+	if gasTarget == 0 || parent.GasUsed == gasTarget {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+
+	var gasDelta uint64
+	if parent.GasUsed > gasTarget {
+		gasDelta = parent.GasUsed - gasTarget
+	} else {
+		gasDelta = gasTarget - parent.GasUsed
+	}
+	delta := new(big.Int).Mul(parent.BaseFee, new(big.Int).SetUint64(gasDelta))
+	delta.Div(delta, new(big.Int).SetUint64(gasTarget))
+	delta.Div(delta, big.NewInt(BaseFeeChangeDenominator))
+
+	if parent.GasUsed > gasTarget {
+		// the base fee raises at least by one
+		if delta.Sign() == 0 {
+			delta.SetUint64(1)
+		}
+		return delta.Add(parent.BaseFee, delta)
+	}
+	return delta.Sub(parent.BaseFee, delta)
+}
+
+// VerifyBaseFee checks that the header has the base fee computed from its parent
+func VerifyBaseFee(forks *Forks, parent, header *types.Header) error {
+	expected := CalcBaseFee(forks, parent)
+	if expected == nil {
+		if header.BaseFee != nil {
+			return fmt.Errorf("base fee before the london fork: %s", header.BaseFee)
+		}
+		return nil
+	}
+	if header.BaseFee == nil {
+		return fmt.Errorf("header is missing the base fee")
+	}
+	if header.BaseFee.Cmp(expected) != 0 {
+		return fmt.Errorf("invalid base fee: have %s, want %s", header.BaseFee, expected)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcBaseFee(t *testing.T) {
+	forks := &Forks{London: NewFork(5)}
+
+	cases := []struct {
+		number  uint64
+		baseFee *big.Int
+		gasUsed uint64
+		result  *big.Int
+	}{
+		// before the fork
+		{3, nil, 0, nil},
+		// first block of the fork
+		{4, nil, 0, big.NewInt(InitialBaseFee)},
+		// the parent used the gas target
+		{5, big.NewInt(InitialBaseFee), 5000000, big.NewInt(InitialBaseFee)},
+		// the parent was full and empty
+		{5, big.NewInt(InitialBaseFee), 10000000, big.NewInt(1125000000)},
+		{5, big.NewInt(InitialBaseFee), 0, big.NewInt(875000000)},
+		{5, big.NewInt(InitialBaseFee), 4000000, big.NewInt(975000000)},
+		// the base fee raises at least by one
+		{5, big.NewInt(7), 5000001, big.NewInt(8)},
+	}
+	for _, c := range cases {
+		parent := &types.Header{
+			Number:   c.number,
+			GasLimit: 10000000,
+			GasUsed:  c.gasUsed,
+			BaseFee:  c.baseFee,
+		}
+		assert.Equal(t, c.result, CalcBaseFee(forks, parent), c)
+	}
+}
+
+func TestVerifyBaseFee(t *testing.T) {
+	forks := &Forks{London: NewFork(5)}
+	parent := &types.Header{Number: 4, GasLimit: 10000000}
+
+	assert.NoError(t, VerifyBaseFee(forks, parent, &types.Header{BaseFee: big.NewInt(InitialBaseFee)}))
+	assert.Error(t, VerifyBaseFee(forks, parent, &types.Header{}))
+	assert.Error(t, VerifyBaseFee(forks, parent, &types.Header{BaseFee: big.NewInt(1)}))
+
+	// the headers before the fork do not have a base fee
+	parent.Number = 3
+	assert.NoError(t, VerifyBaseFee(forks, parent, &types.Header{}))
+	assert.Error(t, VerifyBaseFee(forks, parent, &types.Header{BaseFee: big.NewInt(InitialBaseFee)}))
+}
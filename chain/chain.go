@@ -364,5 +364,13 @@ func importChain(content []byte) (*Chain, error) {
 	if engines := chain.Params.Engine; len(engines) != 1 {
 		return nil, fmt.Errorf("Expected one consensus engine but found %d", len(engines))
 	}
+	if err := chain.Params.validateEngineForks(); err != nil {
+		return nil, err
+	}
+	if rewards := chain.Params.Rewards; rewards != nil {
+		if err := rewards.validate(); err != nil {
+			return nil, err
+		}
+	}
 	return chain, nil
 }
@@ -1,7 +1,11 @@
 package chain
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/big"
+
+	"github.com/0xPolygon/minimal/types"
 )
 
 // Params are all the set of params for the chain
@@ -9,16 +13,120 @@ type Params struct {
 	Forks   *Forks                 `json:"forks"`
 	ChainID int                    `json:"chainID"`
 	Engine  map[string]interface{} `json:"engine"`
+
+	// EngineForks are the consensus engines that replace
+	// the Engine at a block, sorted by block
+	EngineForks []*EngineFork `json:"engineForks,omitempty"`
+
+	// Rewards are credited at the end of each block, if any
+	Rewards *BlockRewards `json:"rewards,omitempty"`
 }
 
 func (p *Params) GetEngine() string {
+	return engineName(p.Engine)
+}
+
+// EngineFork switches the consensus engine at a block
+type EngineFork struct {
+	Block  uint64                 `json:"block"`
+	Engine map[string]interface{} `json:"engine"`
+}
+
+func (e *EngineFork) GetEngine() string {
+	return engineName(e.Engine)
+}
+
+func engineName(engine map[string]interface{}) string {
 	// We now there is already one
-	for k := range p.Engine {
+	for k := range engine {
 		return k
 	}
 	return ""
 }
 
+// validateEngineForks checks that the engines are activated after the genesis
+// and in order, and that an engine is not used twice
+func (p *Params) validateEngineForks() error {
+	names := map[string]struct{}{
+		p.GetEngine(): {},
+	}
+	last := uint64(0)
+	for _, fork := range p.EngineForks {
+		if len(fork.Engine) != 1 {
+			return fmt.Errorf("Expected one consensus engine at block %d but found %d", fork.Block, len(fork.Engine))
+		}
+		if fork.Block <= last {
+			return fmt.Errorf("Consensus engine forks are not sorted at block %d", fork.Block)
+		}
+		last = fork.Block
+
+		name := fork.GetEngine()
+		if _, ok := names[name]; ok {
+			return fmt.Errorf("Consensus engine %s is used more than once", name)
+		}
+		names[name] = struct{}{}
+	}
+	return nil
+}
+
+// BlockRewards mints a reward at each block for the proposer of the block or
+// a treasury. The fees of the txns go to the proposer instead of the coinbase
+// and a share of them can go to the treasury
+type BlockRewards struct {
+	// Reward is the amount minted at each block
+	Reward *big.Int
+
+	// Treasury receives the reward instead of the proposer if it is set
+	Treasury *types.Address
+
+	// TreasuryFees is the percentage of the fees of the block for the treasury
+	TreasuryFees uint64
+}
+
+type blockRewardsEncoder struct {
+	Reward       *string        `json:"reward,omitempty"`
+	Treasury     *types.Address `json:"treasury,omitempty"`
+	TreasuryFees uint64         `json:"treasuryFees,omitempty"`
+}
+
+// MarshalJSON implements the json interface
+func (b *BlockRewards) MarshalJSON() ([]byte, error) {
+	obj := &blockRewardsEncoder{
+		Treasury:     b.Treasury,
+		TreasuryFees: b.TreasuryFees,
+	}
+	if b.Reward != nil {
+		obj.Reward = types.EncodeBigInt(b.Reward)
+	}
+	return json.Marshal(obj)
+}
+
+// UnmarshalJSON implements the json interface
+func (b *BlockRewards) UnmarshalJSON(data []byte) error {
+	var dec blockRewardsEncoder
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	reward, err := types.ParseUint256orHex(dec.Reward)
+	if err != nil {
+		return fmt.Errorf("reward: %v", err)
+	}
+	b.Reward = reward
+	b.Treasury = dec.Treasury
+	b.TreasuryFees = dec.TreasuryFees
+	return nil
+}
+
+func (b *BlockRewards) validate() error {
+	if b.TreasuryFees > 100 {
+		return fmt.Errorf("The treasury fees are a percentage but found %d", b.TreasuryFees)
+	}
+	if b.TreasuryFees != 0 && b.Treasury == nil {
+		return fmt.Errorf("The treasury fees require a treasury")
+	}
+	return nil
+}
+
 // Forks specifies when each fork is activated
 type Forks struct {
 	Homestead      *Fork `json:"homestead,omitempty"`
@@ -29,6 +137,11 @@ type Forks struct {
 	EIP150         *Fork `json:"EIP150,omitempty"`
 	EIP158         *Fork `json:"EIP158,omitempty"`
 	EIP155         *Fork `json:"EIP155,omitempty"`
+
+	// Berlin and London enable the access list and the dynamic fee
+	// transactions, the blocks after London have a base fee
+	Berlin *Fork `json:"berlin,omitempty"`
+	London *Fork `json:"london,omitempty"`
 }
 
 func (f *Forks) active(ff *Fork, block uint64) bool {
@@ -66,6 +179,14 @@ func (f *Forks) IsEIP155(block uint64) bool {
 	return f.active(f.EIP155, block)
 }
 
+func (f *Forks) IsBerlin(block uint64) bool {
+	return f.active(f.Berlin, block)
+}
+
+func (f *Forks) IsLondon(block uint64) bool {
+	return f.active(f.London, block)
+}
+
 func (f *Forks) At(block uint64) ForksInTime {
 	return ForksInTime{
 		Homestead:      f.active(f.Homestead, block),
@@ -76,6 +197,8 @@ func (f *Forks) At(block uint64) ForksInTime {
 		EIP150:         f.active(f.EIP150, block),
 		EIP158:         f.active(f.EIP158, block),
 		EIP155:         f.active(f.EIP155, block),
+		Berlin:         f.active(f.Berlin, block),
+		London:         f.active(f.London, block),
 	}
 }
 
@@ -95,7 +218,7 @@ func (f Fork) Int() *big.Int {
 }
 
 type ForksInTime struct {
-	Homestead, Byzantium, Constantinople, Petersburg, Istanbul, EIP150, EIP158, EIP155 bool
+	Homestead, Byzantium, Constantinople, Petersburg, Istanbul, EIP150, EIP158, EIP155, Berlin, London bool
 }
 
 var AllForksEnabled = &Forks{
@@ -66,3 +66,74 @@ func TestParamsForksInTime(t *testing.T) {
 	expect("constantinople", ff.Constantinople, false)
 	expect("eip150", ff.EIP150, false)
 }
+
+func TestParamsEngineForks(t *testing.T) {
+	engine := func(name string) map[string]interface{} {
+		return map[string]interface{}{name: map[string]interface{}{}}
+	}
+	cases := []struct {
+		forks []*EngineFork
+		valid bool
+	}{
+		{
+			forks: []*EngineFork{{Block: 10, Engine: engine("ibft")}},
+			valid: true,
+		},
+		{
+			// the genesis engine cannot be replaced at the genesis
+			forks: []*EngineFork{{Block: 0, Engine: engine("ibft")}},
+		},
+		{
+			forks: []*EngineFork{
+				{Block: 10, Engine: engine("ibft")},
+				{Block: 10, Engine: engine("clique")},
+			},
+		},
+		{
+			forks: []*EngineFork{{Block: 10, Engine: engine("dev")}},
+		},
+		{
+			forks: []*EngineFork{{Block: 10, Engine: map[string]interface{}{}}},
+		},
+	}
+
+	for _, c := range cases {
+		p := &Params{
+			Engine:      engine("dev"),
+			EngineForks: c.forks,
+		}
+		if err := p.validateEngineForks(); (err == nil) != c.valid {
+			t.Fatalf("bad: %v", err)
+		}
+	}
+}
+
+func TestParamsRewards(t *testing.T) {
+	var p *Params
+	data := `{
+		"rewards": {
+			"reward": "0xde0b6b3a7640000",
+			"treasury": "0x0000000000000000000000000000000000000001",
+			"treasuryFees": 10
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Rewards.Reward.String() != "1000000000000000000" {
+		t.Fatalf("bad reward %s", p.Rewards.Reward)
+	}
+	if err := p.Rewards.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fees cannot go to the treasury without one
+	p.Rewards.Treasury = nil
+	if err := p.Rewards.validate(); err == nil {
+		t.Fatal("expected an error without a treasury")
+	}
+	p.Rewards.TreasuryFees = 101
+	if err := p.Rewards.validate(); err == nil {
+		t.Fatal("expected an error with more than the fees")
+	}
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+
+	"github.com/0xPolygon/minimal/minimal/proto"
+)
+
+// BackupCommand is the command to make a copy of the databases of a running server
+type BackupCommand struct {
+	Meta
+}
+
+// Help implements the cli.Command interface
+func (c *BackupCommand) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.Command interface
+func (c *BackupCommand) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.Command interface
+func (c *BackupCommand) Run(args []string) int {
+	flags := c.FlagSet("backup")
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		c.UI.Error("backup path argument expected")
+		return 1
+	}
+
+	conn, err := c.Conn()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	// the path is resolved in the server
+	clt := proto.NewSystemClient(conn)
+	if _, err := clt.Backup(context.Background(), &proto.BackupRequest{Path: args[0]}); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info("Backup written to " + args[0])
+	return 0
+}
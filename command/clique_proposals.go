@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	cliqueOp "github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// CliqueProposals is the command to list the votes proposed by the node
+type CliqueProposals struct {
+	Meta
+}
+
+// Help implements the cli.CliqueProposals interface
+func (p *CliqueProposals) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.CliqueProposals interface
+func (p *CliqueProposals) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.CliqueProposals interface
+func (p *CliqueProposals) Run(args []string) int {
+	flags := p.FlagSet("clique proposals")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := cliqueOp.NewCliqueOperatorClient(conn)
+	resp, err := clt.Proposals(context.Background(), &empty.Empty{})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(resp.Proposals) == 0 {
+		p.UI.Output("No proposals")
+		return 0
+	}
+
+	for _, c := range resp.Proposals {
+		p.UI.Output(fmt.Sprintf("%s %v", c.Address, c.Auth))
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+
+	cliqueOp "github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// CliquePropose is the command to propose a vote to add or remove a signer
+type CliquePropose struct {
+	Meta
+}
+
+// Help implements the cli.CliquePropose interface
+func (p *CliquePropose) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.CliquePropose interface
+func (p *CliquePropose) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.CliquePropose interface
+func (p *CliquePropose) Run(args []string) int {
+	flags := p.FlagSet("clique propose")
+
+	var add, del, discard bool
+	flags.BoolVar(&add, "add", false, "add")
+	flags.BoolVar(&del, "del", false, "del")
+	flags.BoolVar(&discard, "discard", false, "discard")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	num := 0
+	for _, b := range []bool{add, del, discard} {
+		if b {
+			num++
+		}
+	}
+	if num != 1 {
+		p.UI.Error("only one of add, del and discard needs to be set")
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		p.UI.Error("address expected")
+		return 1
+	}
+
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(args[0])); err != nil {
+		p.UI.Error("failed to decode addr")
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := cliqueOp.NewCliqueOperatorClient(conn)
+	req := &cliqueOp.CliqueProposal{
+		Address: addr.String(),
+		Auth:    add,
+	}
+	if discard {
+		_, err = clt.Discard(context.Background(), req)
+	} else {
+		_, err = clt.Propose(context.Background(), req)
+	}
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+	return 0
+}
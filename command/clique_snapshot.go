@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	cliqueOp "github.com/0xPolygon/minimal/consensus/clique/proto"
+)
+
+// CliqueSnapshot is the command to query the clique snapshot
+type CliqueSnapshot struct {
+	Meta
+}
+
+// Help implements the cli.CliqueSnapshot interface
+func (p *CliqueSnapshot) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.CliqueSnapshot interface
+func (p *CliqueSnapshot) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.CliqueSnapshot interface
+func (p *CliqueSnapshot) Run(args []string) int {
+	flags := p.FlagSet("clique snapshot")
+
+	// query a specific snapshot
+	var number int64
+	flags.Int64Var(&number, "number", -1, "")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	req := &cliqueOp.CliqueSnapshotReq{}
+	if number >= 0 {
+		req.Number = uint64(number)
+	} else {
+		req.Latest = true
+	}
+
+	clt := cliqueOp.NewCliqueOperatorClient(conn)
+	resp, err := clt.GetSnapshot(context.Background(), req)
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	p.UI.Output(printCliqueSnapshot(resp))
+	return 0
+}
+
+func printCliqueSnapshot(s *cliqueOp.CliqueSnapshot) (output string) {
+	output = formatKV([]string{
+		fmt.Sprintf("Block|%d", s.Number),
+		fmt.Sprintf("Hash|%s", s.Hash),
+	})
+
+	votes := make([]string, len(s.Votes)+1)
+	votes[0] = "Signer|Block|Address|Authorize"
+	for i, d := range s.Votes {
+		votes[i+1] = fmt.Sprintf("%s|%d|%s|%v", d.Signer, d.Block, d.Address, d.Auth)
+	}
+
+	output += "\nVotes\n"
+	output += formatList(votes)
+
+	signers := make([]string, len(s.Signers)+1)
+	signers[0] = "Address"
+	for i, d := range s.Signers {
+		signers[i+1] = d
+	}
+
+	output += "\nSigners\n"
+	output += formatList(signers)
+
+	recents := make([]string, len(s.Recents)+1)
+	recents[0] = "Block|Signer"
+	for i, d := range s.Recents {
+		recents[i+1] = fmt.Sprintf("%d|%s", d.Number, d.Signer)
+	}
+
+	output += "\nRecent signers\n"
+	output += formatList(recents)
+
+	return output
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	cliqueOp "github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// CliqueStatus is the command to query the signer key of the node
+type CliqueStatus struct {
+	Meta
+}
+
+// Help implements the cli.CliqueStatus interface
+func (p *CliqueStatus) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.CliqueStatus interface
+func (p *CliqueStatus) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.CliqueStatus interface
+func (p *CliqueStatus) Run(args []string) int {
+	flags := p.FlagSet("clique status")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := cliqueOp.NewCliqueOperatorClient(conn)
+	resp, err := clt.Status(context.Background(), &empty.Empty{})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	p.UI.Output(formatKV([]string{
+		fmt.Sprintf("Key|%s", resp.Key),
+		fmt.Sprintf("Signer|%v", resp.Signer),
+	}))
+	return 0
+}
@@ -1,14 +1,20 @@
 package command
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"os"
 
 	"github.com/0xPolygon/minimal/command/server"
 	"github.com/mitchellh/cli"
 	"github.com/ryanuber/columnize"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 // Commands returns the cli commands
@@ -74,17 +80,83 @@ func Commands() map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"ibft unvote": func() (cli.Command, error) {
+			return &IbftUnvote{
+				Meta: meta,
+			}, nil
+		},
 		"ibft status": func() (cli.Command, error) {
 			return &IbftStatus{
 				Meta: meta,
 			}, nil
 		},
+		"ibft round-change": func() (cli.Command, error) {
+			return &IbftRoundChange{
+				Meta: meta,
+			}, nil
+		},
+		"ibft evidence": func() (cli.Command, error) {
+			return &IbftEvidence{
+				Meta: meta,
+			}, nil
+		},
+		"ibft liveness": func() (cli.Command, error) {
+			return &IbftLiveness{
+				Meta: meta,
+			}, nil
+		},
+		"ibft validators": func() (cli.Command, error) {
+			return &IbftValidators{
+				Meta: meta,
+			}, nil
+		},
+		// ---- clique commands ----
+		"clique snapshot": func() (cli.Command, error) {
+			return &CliqueSnapshot{
+				Meta: meta,
+			}, nil
+		},
+		"clique proposals": func() (cli.Command, error) {
+			return &CliqueProposals{
+				Meta: meta,
+			}, nil
+		},
+		"clique propose": func() (cli.Command, error) {
+			return &CliquePropose{
+				Meta: meta,
+			}, nil
+		},
+		"clique status": func() (cli.Command, error) {
+			return &CliqueStatus{
+				Meta: meta,
+			}, nil
+		},
 		// ---- txpool ----
+		"txpool account": func() (cli.Command, error) {
+			return &TxPoolAccount{
+				Meta: meta,
+			}, nil
+		},
 		"txpool add": func() (cli.Command, error) {
 			return &TxPoolAdd{
 				Meta: meta,
 			}, nil
 		},
+		"txpool content": func() (cli.Command, error) {
+			return &TxPoolContent{
+				Meta: meta,
+			}, nil
+		},
+		"txpool drop": func() (cli.Command, error) {
+			return &TxPoolDrop{
+				Meta: meta,
+			}, nil
+		},
+		"txpool flush": func() (cli.Command, error) {
+			return &TxPoolFlush{
+				Meta: meta,
+			}, nil
+		},
 		"txpool status": func() (cli.Command, error) {
 			return &TxPoolStatus{
 				Meta: meta,
@@ -96,6 +168,31 @@ func Commands() map[string]cli.CommandFactory {
 				Meta: meta,
 			}, nil
 		},
+		"storage check": func() (cli.Command, error) {
+			return &StorageCheck{
+				UI: ui,
+			}, nil
+		},
+		"storage inspect": func() (cli.Command, error) {
+			return &StorageInspect{
+				UI: ui,
+			}, nil
+		},
+		"compact": func() (cli.Command, error) {
+			return &CompactCommand{
+				Meta: meta,
+			}, nil
+		},
+		"backup": func() (cli.Command, error) {
+			return &BackupCommand{
+				Meta: meta,
+			}, nil
+		},
+		"reload": func() (cli.Command, error) {
+			return &ReloadCommand{
+				Meta: meta,
+			}, nil
+		},
 		"monitor": func() (cli.Command, error) {
 			return &MonitorCommand{
 				Meta: meta,
@@ -113,24 +210,102 @@ func Commands() map[string]cli.CommandFactory {
 type Meta struct {
 	UI   cli.Ui
 	addr string
+
+	// token and the tls files to authenticate with the server
+	token   string
+	tlsCA   string
+	tlsCert string
+	tlsKey  string
 }
 
 // FlagSet adds some default commands to handle grpc connections with the server
 func (m *Meta) FlagSet(n string) *flag.FlagSet {
 	f := flag.NewFlagSet(n, flag.ContinueOnError)
 	f.StringVar(&m.addr, "address", "127.0.0.1:9632", "Address of the http api")
+	f.StringVar(&m.token, "token", os.Getenv("MINIMAL_GRPC_TOKEN"), "Bearer token of the grpc api")
+	f.StringVar(&m.tlsCA, "tls-ca", "", "CA of the tls certificate of the grpc api")
+	f.StringVar(&m.tlsCert, "tls-cert", "", "Client certificate for the grpc api")
+	f.StringVar(&m.tlsKey, "tls-key", "", "Key of the client certificate")
 	return f
 }
 
 // Conn returns a grpc connection
 func (m *Meta) Conn() (*grpc.ClientConn, error) {
-	conn, err := grpc.Dial(m.addr, grpc.WithInsecure())
+	opts := []grpc.DialOption{}
+	if m.tlsCA != "" {
+		tlsConfig, err := m.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
+	if m.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(&tokenCredentials{
+			token: m.token,
+			tls:   m.tlsCA != "" || !isLoopback(m.addr),
+		}))
+	}
+
+	conn, err := grpc.Dial(m.addr, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to server: %v", err)
 	}
 	return conn, nil
 }
 
+func (m *Meta) tlsConfig() (*tls.Config, error) {
+	data, err := ioutil.ReadFile(m.tlsCA)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in %s", m.tlsCA)
+	}
+	tlsConfig := &tls.Config{
+		RootCAs: pool,
+	}
+	if m.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(m.tlsCert, m.tlsKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// tokenCredentials sends the bearer token in the calls
+type tokenCredentials struct {
+	token string
+	tls   bool
+}
+
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity allows the token without tls only to reach a
+// server on the loopback address
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return t.tls
+}
+
+// isLoopback checks if the address of the server is on the local host
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func formatList(in []string) string {
 	columnConf := columnize.DefaultConfig()
 	columnConf.Empty = "<none>"
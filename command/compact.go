@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/minimal/proto"
+)
+
+// CompactCommand is the command to compact the blockchain storage of a running server
+type CompactCommand struct {
+	Meta
+}
+
+// Help implements the cli.Command interface
+func (c *CompactCommand) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.Command interface
+func (c *CompactCommand) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.Command interface
+func (c *CompactCommand) Run(args []string) int {
+	flags := c.FlagSet("compact")
+
+	// the bounds are raw keys in hex, the whole storage is compacted by default
+	var start, end, namespace string
+	flags.StringVar(&start, "start", "", "")
+	flags.StringVar(&end, "end", "", "")
+	flags.StringVar(&namespace, "namespace", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	req := &proto.CompactRequest{}
+	var err error
+	if namespace != "" {
+		if start != "" || end != "" {
+			c.UI.Error("namespace cannot be used with start or end")
+			return 1
+		}
+		ns, ok := storage.LookupNamespace(namespace)
+		if !ok {
+			c.UI.Error(fmt.Sprintf("namespace '%s' not found", namespace))
+			return 1
+		}
+		req.Start, req.End = ns.Range()
+	}
+	if start != "" {
+		if req.Start, err = hex.DecodeHex(start); err != nil {
+			c.UI.Error(fmt.Sprintf("failed to decode start: %v", err))
+			return 1
+		}
+	}
+	if end != "" {
+		if req.End, err = hex.DecodeHex(end); err != nil {
+			c.UI.Error(fmt.Sprintf("failed to decode end: %v", err))
+			return 1
+		}
+	}
+
+	conn, err := c.Conn()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := proto.NewSystemClient(conn)
+	if _, err := clt.Compact(context.Background(), req); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info("Compaction completed")
+	return 0
+}
@@ -1,10 +1,13 @@
 package command
 
 import (
+	"github.com/0xPolygon/minimal/command/server"
 	"github.com/mitchellh/cli"
 )
 
-// DevCommand is the command to show the version of the agent
+// DevCommand is the command to start a single node that seals the
+// transactions as they arrive, it takes the flags of the server
+// (i.e. --dev-interval to also seal a block every interval seconds)
 type DevCommand struct {
 	UI cli.Ui
 }
@@ -21,6 +24,8 @@ func (c *DevCommand) Synopsis() string {
 
 // Run implements the cli.Command interface
 func (c *DevCommand) Run(args []string) int {
-	// TODO
-	return 0
+	cmd := &server.Command{
+		UI: c.UI,
+	}
+	return cmd.Run(append([]string{"--dev"}, args...))
 }
@@ -5,11 +5,13 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus/clique"
 	"github.com/0xPolygon/minimal/consensus/ibft"
 	"github.com/0xPolygon/minimal/crypto"
 	helperFlags "github.com/0xPolygon/minimal/helper/flags"
@@ -53,6 +55,19 @@ func (c *GenesisCommand) Run(args []string) int {
 	// ibft flags
 	var ibftValidators helperFlags.ArrayFlags
 	var ibftValidatorsPrefixPath string
+	var ibftType string
+	var ibftEpochSize uint64
+	var ibftBlockTime, ibftRoundTimeout uint64
+	var ibftRoundTimeoutMultiplier, ibftMaxRoundTimeout uint64
+	var ibftMaxIdleTime uint64
+	var ibftProposerPolicy string
+	var ibftValidatorContract string
+	var ibftMinStake string
+	var ibftMaxValidators uint64
+
+	// clique flags
+	var cliqueSigners helperFlags.ArrayFlags
+	var cliquePeriod, cliqueEpoch uint64
 
 	flags.StringVar(&dataDir, "data-dir", "", "")
 	flags.StringVar(&name, "name", "example", "")
@@ -61,6 +76,20 @@ func (c *GenesisCommand) Run(args []string) int {
 	flags.StringVar(&consensus, "consensus", "pow", "")
 	flags.Var(&ibftValidators, "ibft-validator", "list of ibft validators")
 	flags.StringVar(&ibftValidatorsPrefixPath, "ibft-validators-prefix-path", "", "")
+	flags.StringVar(&ibftType, "ibft-type", string(ibft.PoA), "")
+	flags.Uint64Var(&ibftEpochSize, "ibft-epoch", 0, "")
+	flags.Uint64Var(&ibftBlockTime, "ibft-block-time", 0, "minimum seconds between blocks")
+	flags.Uint64Var(&ibftRoundTimeout, "ibft-round-timeout", 0, "timeout in seconds of the first round")
+	flags.Uint64Var(&ibftRoundTimeoutMultiplier, "ibft-round-timeout-multiplier", 0, "base of the exponential growth of the round timeouts")
+	flags.Uint64Var(&ibftMaxRoundTimeout, "ibft-max-round-timeout", 0, "maximum timeout in seconds of the rounds")
+	flags.Uint64Var(&ibftMaxIdleTime, "ibft-max-idle-time", 0, "maximum seconds without blocks if there are no transactions")
+	flags.StringVar(&ibftValidatorContract, "ibft-validator-contract", "", "")
+	flags.StringVar(&ibftMinStake, "ibft-min-stake", "", "minimum stake in wei of the PoS validators")
+	flags.Uint64Var(&ibftMaxValidators, "ibft-max-validators", 0, "maximum number of PoS validators")
+	flags.StringVar(&ibftProposerPolicy, "ibft-proposer-policy", "", "selection of the proposers: roundrobin, sticky or random")
+	flags.Var(&cliqueSigners, "clique-signer", "list of clique signers")
+	flags.Uint64Var(&cliquePeriod, "clique-period", 0, "")
+	flags.Uint64Var(&cliqueEpoch, "clique-epoch", 0, "")
 
 	if err := flags.Parse(args); err != nil {
 		c.UI.Error(fmt.Sprintf("failed to parse args: %v", err))
@@ -80,6 +109,8 @@ func (c *GenesisCommand) Run(args []string) int {
 
 	var bootnodes chain.Bootnodes
 	var extraData []byte
+	engineConfig := map[string]interface{}{}
+	alloc := map[types.Address]*chain.GenesisAccount{}
 
 	if consensus == "ibft" {
 		// we either use validatorsFlags or ibftValidatorsPrefixPath to set the validators
@@ -109,6 +140,88 @@ func (c *GenesisCommand) Run(args []string) int {
 		}
 		extraData = make([]byte, ibft.IstanbulExtraVanity)
 		extraData = ibftExtra.MarshalRLPTo(extraData)
+
+		switch ibft.MechanismType(ibftType) {
+		case ibft.PoA:
+		case ibft.PoS:
+			// the initial validators are the first stakers of the staking
+			// contract, their minimum stake is minted in the contract
+			minStake := big.NewInt(0)
+			if ibftMinStake != "" {
+				if minStake, err = types.ParseUint256orHex(&ibftMinStake); err != nil {
+					c.UI.Error(fmt.Sprintf("failed to parse the ibft min stake: %v", err))
+					return 1
+				}
+			}
+			if ibftMaxValidators != 0 && uint64(len(validators)) > ibftMaxValidators {
+				c.UI.Error(fmt.Sprintf("%d ibft validators but the maximum is %d", len(validators), ibftMaxValidators))
+				return 1
+			}
+			engineConfig["type"] = ibftType
+			if minStake.Sign() != 0 {
+				engineConfig["minStake"] = minStake.String()
+			}
+			if ibftMaxValidators != 0 {
+				engineConfig["maxValidators"] = ibftMaxValidators
+			}
+			alloc[ibft.StakingContractAddr] = &chain.GenesisAccount{
+				Code:    ibft.StakingContractCode(minStake, ibftMaxValidators),
+				Storage: ibft.StakingContractStorage(validators, minStake),
+				Balance: new(big.Int).Mul(minStake, big.NewInt(int64(len(validators)))),
+			}
+		case ibft.Contract:
+			if ibftValidatorContract == "" {
+				c.UI.Error("ibft validator contract required")
+				return 1
+			}
+			engineConfig["type"] = ibftType
+		default:
+			c.UI.Error(fmt.Sprintf("ibft type must be %s, %s or %s", ibft.PoA, ibft.PoS, ibft.Contract))
+			return 1
+		}
+		if ibftValidatorContract != "" {
+			engineConfig["validatorContract"] = ibftValidatorContract
+		}
+		if ibftEpochSize != 0 {
+			engineConfig["epochSize"] = ibftEpochSize
+		}
+		if ibftBlockTime != 0 {
+			engineConfig["blockTime"] = ibftBlockTime
+		}
+		if ibftRoundTimeout != 0 {
+			engineConfig["roundTimeout"] = ibftRoundTimeout
+		}
+		if ibftRoundTimeoutMultiplier != 0 {
+			engineConfig["roundTimeoutMultiplier"] = ibftRoundTimeoutMultiplier
+		}
+		if ibftMaxRoundTimeout != 0 {
+			engineConfig["maxRoundTimeout"] = ibftMaxRoundTimeout
+		}
+		if ibftMaxIdleTime != 0 {
+			engineConfig["maxIdleTime"] = ibftMaxIdleTime
+		}
+		if ibftProposerPolicy != "" {
+			engineConfig["proposerPolicy"] = ibftProposerPolicy
+		}
+	}
+
+	if consensus == "clique" {
+		if len(cliqueSigners) == 0 {
+			c.UI.Error("cannot load signers for clique")
+			return 1
+		}
+		signers := []types.Address{}
+		for _, signer := range cliqueSigners {
+			signers = append(signers, types.StringToAddress(signer))
+		}
+		extraData = clique.BuildExtra(nil, signers)
+
+		if cliquePeriod != 0 {
+			engineConfig["period"] = cliquePeriod
+		}
+		if cliqueEpoch != 0 {
+			engineConfig["epoch"] = cliqueEpoch
+		}
 	}
 
 	cc := &chain.Chain{
@@ -116,14 +229,14 @@ func (c *GenesisCommand) Run(args []string) int {
 		Genesis: &chain.Genesis{
 			GasLimit:   5000,
 			Difficulty: 1,
-			Alloc:      map[types.Address]*chain.GenesisAccount{},
+			Alloc:      alloc,
 			ExtraData:  extraData,
 		},
 		Params: &chain.Params{
 			ChainID: int(chainID),
 			Forks:   chain.AllForksEnabled,
 			Engine: map[string]interface{}{
-				consensus: map[string]interface{}{},
+				consensus: engineConfig,
 			},
 		},
 		Bootnodes: bootnodes,
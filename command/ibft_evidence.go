@@ -0,0 +1,60 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
+)
+
+// IbftEvidence is the command to query the double signs of the validators
+type IbftEvidence struct {
+	Meta
+}
+
+// Help implements the cli.IbftEvidence interface
+func (p *IbftEvidence) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.IbftEvidence interface
+func (p *IbftEvidence) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.IbftEvidence interface
+func (p *IbftEvidence) Run(args []string) int {
+	flags := p.FlagSet("ibft evidence")
+
+	var validator string
+	flags.StringVar(&validator, "validator", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := ibftOp.NewIbftOperatorClient(conn)
+	resp, err := clt.GetEvidence(context.Background(), &ibftOp.EvidenceReq{Validator: validator})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(resp.Evidence) == 0 {
+		p.UI.Output("No evidence")
+		return 0
+	}
+
+	for _, e := range resp.Evidence {
+		p.UI.Output(fmt.Sprintf("%s %s sequence=%d round=%d %s", e.Validator, e.Type, e.Sequence, e.Round, strings.Join(e.Digests, ",")))
+	}
+	return 0
+}
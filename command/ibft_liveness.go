@@ -0,0 +1,64 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
+)
+
+// IbftLiveness is the command to query the participation of the validators in the recent blocks
+type IbftLiveness struct {
+	Meta
+}
+
+// Help implements the cli.IbftLiveness interface
+func (p *IbftLiveness) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.IbftLiveness interface
+func (p *IbftLiveness) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.IbftLiveness interface
+func (p *IbftLiveness) Run(args []string) int {
+	flags := p.FlagSet("ibft liveness")
+
+	var validator string
+	flags.StringVar(&validator, "validator", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := ibftOp.NewIbftOperatorClient(conn)
+	resp, err := clt.GetLiveness(context.Background(), &ibftOp.LivenessReq{Validator: validator})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(resp.Validators) == 0 {
+		p.UI.Output("No blocks")
+		return 0
+	}
+
+	p.UI.Output(fmt.Sprintf("window=%d threshold=%d%%", resp.Window, resp.Threshold))
+	for _, v := range resp.Validators {
+		line := fmt.Sprintf("%s sealed=%d/%d participation=%.2f last=%d", v.Validator, v.Sealed, v.Blocks, v.Participation, v.LastSealed)
+		if v.Alert {
+			line += " ALERT"
+		}
+		p.UI.Output(line)
+	}
+	return 0
+}
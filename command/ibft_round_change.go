@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
+)
+
+// IbftRoundChange is the command to force a round change of the consensus
+type IbftRoundChange struct {
+	Meta
+}
+
+// Help implements the cli.IbftRoundChange interface
+func (p *IbftRoundChange) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.IbftRoundChange interface
+func (p *IbftRoundChange) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.IbftRoundChange interface
+func (p *IbftRoundChange) Run(args []string) int {
+	flags := p.FlagSet("ibft round-change")
+
+	var resync bool
+	flags.BoolVar(&resync, "resync", false, "reset the consensus and sync again")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := ibftOp.NewIbftOperatorClient(conn)
+	resp, err := clt.RoundChange(context.Background(), &ibftOp.RoundChangeReq{Resync: resync})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	p.UI.Output(formatKV([]string{
+		fmt.Sprintf("State|%s", resp.State),
+		fmt.Sprintf("Sequence|%d", resp.Sequence),
+		fmt.Sprintf("Round|%d", resp.Round),
+	}))
+	return 0
+}
@@ -76,9 +76,9 @@ func printSnapshot(s *proto.Snapshot) (output string) {
 	output += formatList(votes)
 
 	validators := make([]string, len(s.Validators)+1)
-	validators[0] = "Address"
+	validators[0] = "Address|Weight"
 	for i, d := range s.Validators {
-		validators[i+1] = d.Address
+		validators[i+1] = fmt.Sprintf("%s|%d", d.Address, d.Weight)
 	}
 
 	output += "\nValidators\n"
@@ -2,6 +2,10 @@ package command
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
 	"github.com/golang/protobuf/ptypes/empty"
@@ -30,6 +34,9 @@ func (p *IbftStatus) Run(args []string) int {
 	flags.BoolVar(&add, "add", false, "add")
 	flags.BoolVar(&del, "del", false, "del")
 
+	var watch bool
+	flags.BoolVar(&watch, "watch", false, "watch the consensus state")
+
 	if err := flags.Parse(args); err != nil {
 		p.UI.Error(err.Error())
 		return 1
@@ -41,12 +48,72 @@ func (p *IbftStatus) Run(args []string) int {
 	}
 
 	clt := ibftOp.NewIbftOperatorClient(conn)
+	if watch {
+		return p.watch(clt)
+	}
+
 	resp, err := clt.Status(context.Background(), &empty.Empty{})
 	if err != nil {
 		p.UI.Error(err.Error())
 		return 1
 	}
 
-	p.UI.Output(resp.Key)
+	kv := []string{
+		fmt.Sprintf("Key|%s", resp.Key),
+		fmt.Sprintf("Source|%s", resp.Source),
+	}
+	if resp.Contract != "" {
+		kv = append(kv, fmt.Sprintf("Contract|%s", resp.Contract))
+	}
+	p.UI.Output(formatKV(kv))
+	return 0
+}
+
+func (p *IbftStatus) watch(clt ibftOp.IbftOperatorClient) int {
+	ctx, cancelFn := context.WithCancel(context.Background())
+
+	stream, err := clt.StreamStatus(ctx, &empty.Empty{})
+	if err != nil {
+		p.UI.Error(err.Error())
+		cancelFn()
+		return 1
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		for {
+			status, err := stream.Recv()
+			if err != nil {
+				p.UI.Error(fmt.Sprintf("failed to read status: %v", err))
+				break
+			}
+			kv := []string{
+				fmt.Sprintf("State|%s", status.State),
+				fmt.Sprintf("Sequence|%d", status.Sequence),
+				fmt.Sprintf("Round|%d", status.Round),
+				fmt.Sprintf("Proposer|%s", status.Proposer),
+				fmt.Sprintf("Prepared|%d", status.Prepared),
+				fmt.Sprintf("Committed|%d", status.Committed),
+				fmt.Sprintf("Round changes|%d", status.RoundChanges),
+			}
+			if status.LockedBlock != "" {
+				kv = append(kv, fmt.Sprintf("Locked block|%s", status.LockedBlock))
+			}
+			p.UI.Output("-- status --")
+			p.UI.Output(formatKV(kv))
+		}
+		doneCh <- struct{}{}
+	}()
+
+	// wait for the user to quit with ctrl-c
+	signalCh := make(chan os.Signal, 4)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	select {
+	case <-signalCh:
+	case <-doneCh:
+	}
+	cancelFn()
+
 	return 0
 }
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+
+	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// IbftUnvote is the command to withdraw a pending proposal
+type IbftUnvote struct {
+	Meta
+}
+
+// Help implements the cli.IbftUnvote interface
+func (p *IbftUnvote) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.IbftUnvote interface
+func (p *IbftUnvote) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.IbftUnvote interface
+func (p *IbftUnvote) Run(args []string) int {
+	flags := p.FlagSet("ibft unvote")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		p.UI.Error("address expected")
+		return 1
+	}
+
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(args[0])); err != nil {
+		p.UI.Error("failed to decode addr")
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := ibftOp.NewIbftOperatorClient(conn)
+	if _, err := clt.Unvote(context.Background(), &ibftOp.UnvoteReq{Address: addr.String()}); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+	return 0
+}
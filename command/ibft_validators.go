@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ibftOp "github.com/0xPolygon/minimal/consensus/ibft/proto"
+)
+
+// IbftValidators is the command to query the validator set effective at a block
+type IbftValidators struct {
+	Meta
+}
+
+// Help implements the cli.IbftValidators interface
+func (p *IbftValidators) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.IbftValidators interface
+func (p *IbftValidators) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.IbftValidators interface
+func (p *IbftValidators) Run(args []string) int {
+	flags := p.FlagSet("ibft validators")
+
+	var number uint64
+	flags.Uint64Var(&number, "number", 0, "")
+
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := ibftOp.NewIbftOperatorClient(conn)
+	resp, err := clt.GetValidators(context.Background(), &ibftOp.ValidatorsReq{Number: number})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	p.UI.Output(printValidators(resp))
+	return 0
+}
+
+func printValidators(v *ibftOp.ValidatorsResp) (output string) {
+	kv := []string{
+		fmt.Sprintf("Block|%d", v.Number),
+		fmt.Sprintf("Hash|%s", v.Hash),
+		fmt.Sprintf("Proposer|%s", v.Proposer),
+	}
+	if v.Vote != nil {
+		kv = append(kv, fmt.Sprintf("Vote|%s (authorize=%v)", v.Vote.Proposed, v.Vote.Auth))
+	}
+	if len(v.Added) != 0 {
+		kv = append(kv, fmt.Sprintf("Added|%s", strings.Join(v.Added, ", ")))
+	}
+	if len(v.Removed) != 0 {
+		kv = append(kv, fmt.Sprintf("Removed|%s", strings.Join(v.Removed, ", ")))
+	}
+	output = formatKV(kv)
+
+	validators := make([]string, len(v.Validators)+1)
+	validators[0] = "Address|Weight"
+	for i, d := range v.Validators {
+		validators[i+1] = fmt.Sprintf("%s|%d", d.Address, d.Weight)
+	}
+	output += "\nValidators\n"
+	output += formatList(validators)
+
+	tallies := make([]string, len(v.Tallies)+1)
+	tallies[0] = "Address|Authorize|Votes|Required"
+	for i, d := range v.Tallies {
+		tallies[i+1] = fmt.Sprintf("%s|%v|%d|%d", d.Address, d.Auth, d.Votes, d.Required)
+	}
+	output += "\nTallies\n"
+	output += formatList(tallies)
+
+	return output
+}
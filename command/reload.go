@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/minimal/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// ReloadCommand is the command to reload the config of a running server
+type ReloadCommand struct {
+	Meta
+}
+
+// Help implements the cli.Command interface
+func (c *ReloadCommand) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.Command interface
+func (c *ReloadCommand) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.Command interface
+func (c *ReloadCommand) Run(args []string) int {
+	flags := c.FlagSet("reload")
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := c.Conn()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := proto.NewSystemClient(conn)
+	resp, err := clt.Reload(context.Background(), &empty.Empty{})
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(resp.Changes) == 0 {
+		c.UI.Info("No changes")
+		return 0
+	}
+	changes := make([]string, len(resp.Changes)+1)
+	changes[0] = "Option|Old|New"
+	for i, change := range resp.Changes {
+		changes[i+1] = fmt.Sprintf("%s|%s|%s", change.Name, change.OldValue, change.NewValue)
+	}
+	c.UI.Output(formatList(changes))
+	return 0
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	helperFlags "github.com/0xPolygon/minimal/helper/flags"
 	"github.com/0xPolygon/minimal/minimal"
 	"github.com/hashicorp/go-hclog"
 	"github.com/mitchellh/cli"
@@ -57,16 +60,33 @@ func (c *Command) Run(args []string) int {
 		// make a non-blocking join request
 		server.Join(conf.Join, 0)
 	}
-	return c.handleSignals(server.Close)
+
+	// the reloads read the config file and the flags again
+	server.SetConfigLoader(func() (*minimal.Config, error) {
+		conf, err := readConfig(args)
+		if err != nil {
+			return nil, err
+		}
+		return conf.BuildConfig()
+	})
+	return c.handleSignals(server.Close, func() {
+		if _, err := server.ReloadConfig("sighup"); err != nil {
+			c.UI.Error(fmt.Sprintf("Failed to reload the config: %v", err))
+		}
+	})
 }
 
-func (c *Command) handleSignals(closeFn func()) int {
+func (c *Command) handleSignals(closeFn func(), reloadFn func()) int {
 	signalCh := make(chan os.Signal, 4)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	var sig os.Signal
-	select {
-	case sig = <-signalCh:
+	for {
+		sig = <-signalCh
+		if sig != syscall.SIGHUP || reloadFn == nil {
+			break
+		}
+		reloadFn()
 	}
 
 	c.UI.Output(fmt.Sprintf("Caught signal: %v", sig))
@@ -96,19 +116,79 @@ func readConfig(args []string) (*Config, error) {
 	cliConfig := &Config{
 		Telemetry: &Telemetry{},
 		Network:   &Network{},
+		LevelDB:   &LevelDB{},
 	}
 
 	flags := flag.NewFlagSet("server", flag.ContinueOnError)
 	flags.Usage = func() {}
 
 	var configFile string
+	var storageCache helperFlags.ArrayFlags
+	var prioritySenders helperFlags.ArrayFlags
+	var grpcAuth GRPCAuth
+	var jsonrpcTLS TLS
+	var ws WS
+	var wsOrigins helperFlags.ArrayFlags
+	var corsOrigins helperFlags.ArrayFlags
+	var vhosts helperFlags.ArrayFlags
+	var methodRateLimits helperFlags.ArrayFlags
+	var allowMethods helperFlags.ArrayFlags
+	var denyMethods helperFlags.ArrayFlags
 	flags.StringVar(&cliConfig.LogLevel, "log-level", "", "")
 	flags.BoolVar(&cliConfig.Seal, "seal", false, "")
+	flags.Uint64Var(&cliConfig.BlockGasTarget, "block-gas-target", 0, "")
+	flags.Uint64Var(&cliConfig.PriceBump, "price-bump", 0, "")
+	flags.Uint64Var(&cliConfig.MaxPendingTxns, "max-pending-txns", 0, "")
+	flags.Uint64Var(&cliConfig.MaxQueuedTxns, "max-queued-txns", 0, "")
+	flags.Uint64Var(&cliConfig.MaxTxPoolSize, "max-txpool-size", 0, "size of the txpool in MiB")
+	flags.Uint64Var(&cliConfig.AccountPending, "account-pending-txns", 0, "")
+	flags.Uint64Var(&cliConfig.AccountQueued, "account-queued-txns", 0, "")
+	flags.Uint64Var(&cliConfig.TxnLifetime, "txn-lifetime", 0, "lifetime of the queued txns in seconds")
+	flags.Var(&prioritySenders, "priority-sender", "sender whose txns are included first in the blocks")
+	flags.BoolVar(&cliConfig.TxPoolSimulation, "txpool-simulation", false, "reject the txns that revert against the latest state")
 	flags.StringVar(&configFile, "config", "", "")
 	flags.StringVar(&cliConfig.Chain, "chain", "", "")
 	flags.StringVar(&cliConfig.DataDir, "data-dir", "", "")
+	flags.StringVar(&cliConfig.Storage, "storage", "", "")
+	flags.StringVar(&cliConfig.StorageKey, "storage-key", "", "")
+	flags.Uint64Var(&cliConfig.ForkRetention, "fork-retention", 0, "")
+	flags.StringVar(&cliConfig.StorageMode, "storage-mode", "", "archive, full or light, the full and light modes prune the receipts and the bodies of the old blocks but the state is kept in all the modes")
+	flags.Uint64Var(&cliConfig.HistoryRetention, "history-retention", 0, "number of blocks whose receipts and bodies are kept in the full and light modes")
+	flags.Var(&storageCache, "storage-cache", "cache size of a storage namespace (i.e. headers=4096)")
+	flags.IntVar(&cliConfig.LevelDB.CacheSize, "leveldb-cache", 0, "")
+	flags.IntVar(&cliConfig.LevelDB.Handles, "leveldb-handles", 0, "")
+	flags.StringVar(&cliConfig.LevelDB.CompactionInterval, "leveldb-compaction-interval", "", "")
 	flags.StringVar(&cliConfig.GRPCAddr, "grpc", "", "")
+	flags.StringVar(&grpcAuth.TLSCert, "grpc-tls-cert", "", "")
+	flags.StringVar(&grpcAuth.TLSKey, "grpc-tls-key", "", "")
+	flags.BoolVar(&grpcAuth.TLSSelfSigned, "grpc-tls-self-signed", false, "serve the grpc api over tls with a self signed certificate of the data dir")
+	flags.StringVar(&grpcAuth.ClientCA, "grpc-client-ca", "", "")
 	flags.StringVar(&cliConfig.JSONRPCAddr, "jsonrpc", "", "")
+	flags.Uint64Var(&cliConfig.BatchLimit, "jsonrpc-batch-limit", 0, "maximum number of requests of a jsonrpc batch")
+	flags.Uint64Var(&cliConfig.MaxRequestSize, "jsonrpc-max-request-size", 0, "maximum size in bytes of a jsonrpc request")
+	flags.Uint64Var(&cliConfig.MaxResponseSize, "jsonrpc-max-response-size", 0, "maximum size in bytes of a jsonrpc response")
+	flags.IntVar(&cliConfig.MaxClientFilters, "jsonrpc-max-client-filters", 0, "maximum number of filters and subscriptions of each client ip")
+	flags.Uint64Var(&cliConfig.LogsBlockRange, "jsonrpc-logs-block-range", 0, "maximum number of blocks of a query of logs without a cursor")
+	flags.Uint64Var(&cliConfig.LogsLimit, "jsonrpc-logs-limit", 0, "maximum number of logs of a query or of a page of logs")
+	flags.IntVar(&cliConfig.CacheSize, "jsonrpc-cache-size", 0, "number of results of the old blocks, receipts and txns that are cached")
+	flags.StringVar(&cliConfig.AccessLog, "jsonrpc-access-log", "", "file of the json access log of the jsonrpc requests")
+	flags.StringVar(&jsonrpcTLS.Cert, "jsonrpc-tls-cert", "", "tls certificate of the jsonrpc http and websocket servers")
+	flags.StringVar(&jsonrpcTLS.Key, "jsonrpc-tls-key", "", "key of the tls certificate of the jsonrpc servers")
+	flags.BoolVar(&jsonrpcTLS.SelfSigned, "jsonrpc-tls-self-signed", false, "serve the jsonrpc over tls with a self signed certificate of the data dir")
+	flags.BoolVar(&cliConfig.JSONRPCAdmin, "jsonrpc-admin", false, "enable the admin jsonrpc namespace over the ipc server, the http and websocket servers only serve it with --jsonrpc-allow")
+	flags.Var(&corsOrigins, "jsonrpc-cors", "origin of the browsers allowed to send jsonrpc requests, '*' allows any")
+	flags.Var(&vhosts, "jsonrpc-vhosts", "host name accepted by the jsonrpc http server, any name if it is not set")
+	flags.Uint64Var(&cliConfig.RateLimit, "jsonrpc-rate-limit", 0, "maximum number of jsonrpc requests per second of each client ip")
+	flags.Var(&methodRateLimits, "jsonrpc-method-rate-limit", "maximum number of requests per second of each client ip to a method or namespace (i.e. eth_getLogs=10)")
+	flags.Var(&allowMethods, "jsonrpc-allow", "method or namespace served in the http and websocket servers, all of them by default")
+	flags.Var(&denyMethods, "jsonrpc-deny", "method or namespace not served in the http and websocket servers")
+	flags.StringVar(&ws.Addr, "ws", "", "address of the websocket jsonrpc server")
+	flags.StringVar(&cliConfig.IPCPath, "ipc", "", "path of the ipc socket, it is in the data dir by default")
+	flags.BoolVar(&cliConfig.NoIPC, "no-ipc", false, "disable the ipc server")
+	flags.IntVar(&ws.MaxConns, "ws-max-conns", 0, "maximum number of websocket connections")
+	flags.IntVar(&ws.MaxSubscriptions, "ws-max-subscriptions", 0, "maximum number of subscriptions of a websocket connection")
+	flags.Var(&wsOrigins, "ws-origin", "origin allowed to open websocket connections")
+	flags.IntVar(&cliConfig.Telemetry.PrometheusPort, "prometheus", 0, "port of the prometheus metrics endpoint, 8080 by default and disabled if negative")
 	flags.StringVar(&cliConfig.Join, "join", "", "")
 	flags.StringVar(&cliConfig.Network.Addr, "libp2p", "", "")
 	flags.BoolVar(&cliConfig.Network.NoDiscover, "no-discover", false, "")
@@ -119,6 +199,63 @@ func readConfig(args []string) (*Config, error) {
 	if err := flags.Parse(args); err != nil {
 		return nil, err
 	}
+	if len(prioritySenders) != 0 {
+		cliConfig.PrioritySenders = prioritySenders
+	}
+	if len(storageCache) != 0 {
+		cliConfig.StorageCache = map[string]int{}
+		for _, raw := range storageCache {
+			parts := strings.Split(raw, "=")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("storage cache '%s' is not in the namespace=size format", raw)
+			}
+			size, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the storage cache size '%s': %v", raw, err)
+			}
+			cliConfig.StorageCache[parts[0]] = size
+		}
+	}
+
+	if len(corsOrigins) != 0 {
+		cliConfig.CORSOrigins = corsOrigins
+	}
+	if len(vhosts) != 0 {
+		cliConfig.VHosts = vhosts
+	}
+	if len(methodRateLimits) != 0 {
+		cliConfig.MethodRateLimits = map[string]uint64{}
+		for _, raw := range methodRateLimits {
+			parts := strings.Split(raw, "=")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("method rate limit '%s' is not in the method=rate format", raw)
+			}
+			rate, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the method rate limit '%s': %v", raw, err)
+			}
+			cliConfig.MethodRateLimits[parts[0]] = rate
+		}
+	}
+	if len(allowMethods) != 0 {
+		cliConfig.AllowMethods = allowMethods
+	}
+	if len(denyMethods) != 0 {
+		cliConfig.DenyMethods = denyMethods
+	}
+
+	if grpcAuth.TLSCert != "" || grpcAuth.TLSKey != "" || grpcAuth.TLSSelfSigned || grpcAuth.ClientCA != "" {
+		cliConfig.GRPCAuth = &grpcAuth
+	}
+	if jsonrpcTLS.Cert != "" || jsonrpcTLS.Key != "" || jsonrpcTLS.SelfSigned {
+		cliConfig.JSONRPCTLS = &jsonrpcTLS
+	}
+	if len(wsOrigins) != 0 {
+		ws.Origins = wsOrigins
+	}
+	if ws.Addr != "" || ws.MaxConns != 0 || ws.MaxSubscriptions != 0 || len(ws.Origins) != 0 {
+		cliConfig.WS = &ws
+	}
 
 	if configFile != "" {
 		conf2, err := readConfigFile(configFile)
@@ -5,35 +5,154 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/0xPolygon/minimal/blockchain"
 	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/minimal"
+	"github.com/0xPolygon/minimal/minimal/keystore"
+	"github.com/0xPolygon/minimal/txpool"
+	"github.com/0xPolygon/minimal/types"
 	"github.com/hashicorp/hcl"
 	"github.com/imdario/mergo"
+	"github.com/libp2p/go-libp2p-core/peer"
 )
 
 type Config struct {
-	Chain       string                 `json:"chain"`
-	DataDir     string                 `json:"data_dir"`
-	GRPCAddr    string                 `json:"rpc_addr"`
-	JSONRPCAddr string                 `json:"jsonrpc_addr"`
-	Network     *Network               `json:"network"`
-	Telemetry   *Telemetry             `json:"telemetry"`
-	Seal        bool                   `json:"seal"`
-	LogLevel    string                 `json:"log_level"`
-	Consensus   map[string]interface{} `json:"consensus"`
-	Dev         bool
-	DevInterval uint64
-	Join        string
+	Chain            string                 `json:"chain"`
+	DataDir          string                 `json:"data_dir"`
+	Storage          string                 `json:"storage"`
+	LevelDB          *LevelDB               `json:"leveldb"`
+	StorageKey       string                 `json:"storage_key"`
+	ForkRetention    uint64                 `json:"fork_retention"`
+	StorageMode      string                 `json:"storage_mode"`
+	HistoryRetention uint64                 `json:"history_retention"`
+	StorageCache     map[string]int         `json:"storage_cache"`
+	GRPCAddr         string                 `json:"rpc_addr"`
+	GRPCAuth         *GRPCAuth              `json:"rpc_auth"`
+	JSONRPCAddr      string                 `json:"jsonrpc_addr"`
+	BatchLimit       uint64                 `json:"jsonrpc_batch_limit"`
+	MaxRequestSize   uint64                 `json:"jsonrpc_max_request_size"`
+	MaxResponseSize  uint64                 `json:"jsonrpc_max_response_size"`
+	MaxClientFilters int                    `json:"jsonrpc_max_client_filters"`
+	LogsBlockRange   uint64                 `json:"jsonrpc_logs_block_range"`
+	LogsLimit        uint64                 `json:"jsonrpc_logs_limit"`
+	CacheSize        int                    `json:"jsonrpc_cache_size"`
+	JSONRPCTLS       *TLS                   `json:"jsonrpc_tls"`
+	AccessLog        string                 `json:"jsonrpc_access_log"`
+	JSONRPCAdmin     bool                   `json:"jsonrpc_admin"`
+	CORSOrigins      []string               `json:"jsonrpc_cors"`
+	VHosts           []string               `json:"jsonrpc_vhosts"`
+	RateLimit        uint64                 `json:"jsonrpc_rate_limit"`
+	MethodRateLimits map[string]uint64      `json:"jsonrpc_method_rate_limits"`
+	AllowMethods     []string               `json:"jsonrpc_allow"`
+	DenyMethods      []string               `json:"jsonrpc_deny"`
+	WS               *WS                    `json:"ws"`
+	IPCPath          string                 `json:"ipc_path"`
+	NoIPC            bool                   `json:"no_ipc"`
+	Network          *Network               `json:"network"`
+	Telemetry        *Telemetry             `json:"telemetry"`
+	Seal             bool                   `json:"seal"`
+	LogLevel         string                 `json:"log_level"`
+	Consensus        map[string]interface{} `json:"consensus"`
+	BlockGasTarget   uint64                 `json:"block_gas_target"`
+	PriceBump        uint64                 `json:"price_bump"`
+	MaxPendingTxns   uint64                 `json:"max_pending_txns"`
+	MaxQueuedTxns    uint64                 `json:"max_queued_txns"`
+	MaxTxPoolSize    uint64                 `json:"max_txpool_size"`
+	AccountPending   uint64                 `json:"account_pending_txns"`
+	AccountQueued    uint64                 `json:"account_queued_txns"`
+	TxnLifetime      uint64                 `json:"txn_lifetime"`
+	PrioritySenders  []string               `json:"priority_senders"`
+	PriorityTypes    []uint64               `json:"priority_txn_types"`
+	TxPoolSimulation bool                   `json:"txpool_simulation"`
+	Dev              bool
+	DevInterval      uint64
+	Join             string
 }
 
 type Network struct {
 	NoDiscover bool   `json:"no_discover"`
 	Addr       string `json:"addr"`
 	MaxPeers   uint64 `json:"max_peers"`
+
+	// AllowedPeers are the ids of the only peers that can connect if it is set
+	AllowedPeers []string `json:"allowed_peers"`
+}
+
+// LevelDB are the tuning options of the leveldb storage backend. The sizes
+// are in MiB, zero values use the leveldb defaults
+type LevelDB struct {
+	CacheSize           int `json:"cache_size"`
+	Handles             int `json:"handles"`
+	WriteBuffer         int `json:"write_buffer"`
+	CompactionTableSize int `json:"compaction_table_size"`
+	CompactionTotalSize int `json:"compaction_total_size"`
+	CompactionL0Trigger int `json:"compaction_l0_trigger"`
+	BloomFilterBits     int `json:"bloom_filter_bits"`
+
+	// CompactionInterval is the period of the background
+	// compactions (i.e. 24h), they are disabled if empty
+	CompactionInterval string `json:"compaction_interval"`
+}
+
+func (l *LevelDB) storageConfig() map[string]interface{} {
+	config := map[string]interface{}{}
+	if l == nil {
+		return config
+	}
+	fields := map[string]int{
+		"cache_size":            l.CacheSize,
+		"handles":               l.Handles,
+		"write_buffer":          l.WriteBuffer,
+		"compaction_table_size": l.CompactionTableSize,
+		"compaction_total_size": l.CompactionTotalSize,
+		"compaction_l0_trigger": l.CompactionL0Trigger,
+		"bloom_filter_bits":     l.BloomFilterBits,
+	}
+	for k, v := range fields {
+		if v != 0 {
+			config[k] = v
+		}
+	}
+	if l.CompactionInterval != "" {
+		config["compaction_interval"] = l.CompactionInterval
+	}
+	return config
+}
+
+// GRPCAuth secures the grpc endpoint with tls, the client certificates and the
+// bearer tokens. Clients and Tokens are the methods allowed to each client
+// certificate by its common name and to each token
+type GRPCAuth struct {
+	TLSCert       string              `json:"tls_cert"`
+	TLSKey        string              `json:"tls_key"`
+	TLSSelfSigned bool                `json:"tls_self_signed"`
+	ClientCA      string              `json:"client_ca"`
+	Clients       map[string][]string `json:"clients"`
+	Tokens        map[string][]string `json:"tokens"`
 }
 
+// TLS is the certificate of the jsonrpc servers, the self signed
+// certificate is generated in the data dir if there are no files
+type TLS struct {
+	Cert       string `json:"cert"`
+	Key        string `json:"key"`
+	SelfSigned bool   `json:"self_signed"`
+}
+
+// WS is the config of the websocket jsonrpc server, it is disabled if the addr is empty
+type WS struct {
+	Addr     string   `json:"addr"`
+	MaxConns int      `json:"max_conns"`
+	Origins  []string `json:"origins"`
+
+	MaxSubscriptions int `json:"max_subscriptions"`
+}
+
+// Telemetry is the config of the metrics endpoint, it is disabled if the port is negative
 type Telemetry struct {
 	PrometheusPort int `json:"prometheus_port"`
 }
@@ -49,6 +168,7 @@ func defaultConfig() *Config {
 			NoDiscover: false,
 			MaxPeers:   20,
 		},
+		LevelDB:   &LevelDB{},
 		Seal:      false,
 		LogLevel:  "INFO",
 		Consensus: map[string]interface{}{},
@@ -66,17 +186,133 @@ func (c *Config) BuildConfig() (*minimal.Config, error) {
 	conf.Chain = cc
 	conf.Seal = c.Seal
 	conf.DataDir = c.DataDir
+	conf.Consensus = c.Consensus
+	conf.BlockGasTarget = c.BlockGasTarget
+	conf.PriceBump = c.PriceBump
+	conf.TxPoolLimits = txpool.Limits{
+		MaxPending: c.MaxPendingTxns,
+		MaxQueued:  c.MaxQueuedTxns,
+		MaxSize:    c.MaxTxPoolSize * 1024 * 1024,
+
+		AccountPending: c.AccountPending,
+		AccountQueued:  c.AccountQueued,
+
+		Lifetime: time.Duration(c.TxnLifetime) * time.Second,
+	}
+	for _, raw := range c.PrioritySenders {
+		var addr types.Address
+		if err := addr.UnmarshalText([]byte(raw)); err != nil {
+			return nil, fmt.Errorf("failed to decode the priority sender '%s': %v", raw, err)
+		}
+		conf.TxPoolPriority.Senders = append(conf.TxPoolPriority.Senders, addr)
+	}
+	for _, typ := range c.PriorityTypes {
+		conf.TxPoolPriority.Types = append(conf.TxPoolPriority.Types, types.TxType(typ))
+	}
+	conf.TxPoolSimulation = c.TxPoolSimulation
+	conf.Storage = &blockchain.StorageConfig{
+		Backend:       c.Storage,
+		Config:        c.LevelDB.storageConfig(),
+		ForkRetention: c.ForkRetention,
+	}
+	if conf.Storage.Mode, err = blockchain.ParseStorageMode(c.StorageMode); err != nil {
+		return nil, err
+	}
+	conf.Storage.HistoryRetention = c.HistoryRetention
+	conf.Storage.Cache = c.StorageCache
+	if c.StorageKey != "" {
+		key, err := keystore.ReadEncryptionKey(c.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+		conf.Storage.Config["encryption_key"] = key
+	}
 
 	if c.GRPCAddr != "" {
 		if conf.GRPCAddr, err = resolveAddr(c.GRPCAddr); err != nil {
 			return nil, err
 		}
 	}
+	if c.GRPCAuth != nil {
+		for token := range c.GRPCAuth.Tokens {
+			if token == "" {
+				return nil, fmt.Errorf("the grpc tokens cannot be empty")
+			}
+		}
+		conf.GRPCAuth = &minimal.GRPCAuthConfig{
+			CertFile:     c.GRPCAuth.TLSCert,
+			KeyFile:      c.GRPCAuth.TLSKey,
+			SelfSigned:   c.GRPCAuth.TLSSelfSigned,
+			ClientCAFile: c.GRPCAuth.ClientCA,
+			Clients:      c.GRPCAuth.Clients,
+			Tokens:       c.GRPCAuth.Tokens,
+		}
+	}
 	if c.JSONRPCAddr != "" {
 		if conf.JSONRPCAddr, err = resolveAddr(c.JSONRPCAddr); err != nil {
 			return nil, err
 		}
 	}
+	if c.BatchLimit != 0 {
+		conf.JSONRPCBatchLimit = c.BatchLimit
+	}
+	if c.MaxRequestSize != 0 {
+		conf.JSONRPCMaxRequestSize = c.MaxRequestSize
+	}
+	if c.MaxResponseSize != 0 {
+		conf.JSONRPCMaxResponseSize = c.MaxResponseSize
+	}
+	if c.MaxClientFilters != 0 {
+		conf.JSONRPCMaxClientFilters = c.MaxClientFilters
+	}
+	if c.LogsBlockRange != 0 {
+		conf.JSONRPCLogsBlockRange = c.LogsBlockRange
+	}
+	if c.LogsLimit != 0 {
+		conf.JSONRPCLogsLimit = c.LogsLimit
+	}
+	if c.CacheSize != 0 {
+		conf.JSONRPCCacheSize = c.CacheSize
+	}
+	conf.JSONRPCAccessLog = c.AccessLog
+	if c.JSONRPCTLS != nil {
+		conf.JSONRPCTLS = &minimal.TLSConfig{
+			CertFile:   c.JSONRPCTLS.Cert,
+			KeyFile:    c.JSONRPCTLS.Key,
+			SelfSigned: c.JSONRPCTLS.SelfSigned,
+		}
+	}
+	conf.JSONRPCAdmin = c.JSONRPCAdmin
+	conf.JSONRPCCORSOrigins = c.CORSOrigins
+	conf.JSONRPCVHosts = c.VHosts
+	conf.JSONRPCRateLimit = c.RateLimit
+	conf.JSONRPCMethodRateLimits = c.MethodRateLimits
+	conf.JSONRPCAllow = c.AllowMethods
+	conf.JSONRPCDeny = c.DenyMethods
+	if !c.NoIPC {
+		// the socket is in the data dir by default
+		conf.IPCPath = c.IPCPath
+		if conf.IPCPath == "" {
+			conf.IPCPath = filepath.Join(c.DataDir, "minimal.ipc")
+		}
+	}
+	if c.WS != nil {
+		if c.WS.Addr != "" {
+			if conf.JSONRPCWSAddr, err = resolveAddr(c.WS.Addr); err != nil {
+				return nil, err
+			}
+		}
+		if c.WS.MaxConns != 0 {
+			conf.JSONRPCWSMaxConns = c.WS.MaxConns
+		}
+		if c.WS.MaxSubscriptions != 0 {
+			conf.JSONRPCWSMaxSubscriptions = c.WS.MaxSubscriptions
+		}
+		conf.JSONRPCWSOrigins = c.WS.Origins
+	}
+	if c.Telemetry != nil && c.Telemetry.PrometheusPort > 0 {
+		conf.PrometheusAddr = &net.TCPAddr{IP: net.ParseIP("0.0.0.0"), Port: c.Telemetry.PrometheusPort}
+	}
 	// network
 	{
 		if conf.Network.Addr, err = resolveAddr(c.Network.Addr); err != nil {
@@ -84,6 +320,13 @@ func (c *Config) BuildConfig() (*minimal.Config, error) {
 		}
 		conf.Network.NoDiscover = c.Network.NoDiscover
 		conf.Network.MaxPeers = c.Network.MaxPeers
+		for _, raw := range c.Network.AllowedPeers {
+			id, err := peer.Decode(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the allowed peer '%s': %v", raw, err)
+			}
+			conf.Network.AllowedPeers = append(conf.Network.AllowedPeers, id)
+		}
 		conf.Chain = cc
 	}
 
@@ -122,6 +365,35 @@ func (c *Config) merge(c1 *Config) error {
 	if c1.DataDir != "" {
 		c.DataDir = c1.DataDir
 	}
+	if c1.Storage != "" {
+		c.Storage = c1.Storage
+	}
+	if c1.StorageKey != "" {
+		c.StorageKey = c1.StorageKey
+	}
+	if c1.ForkRetention != 0 {
+		c.ForkRetention = c1.ForkRetention
+	}
+	if c1.StorageMode != "" {
+		c.StorageMode = c1.StorageMode
+	}
+	if c1.HistoryRetention != 0 {
+		c.HistoryRetention = c1.HistoryRetention
+	}
+	for name, size := range c1.StorageCache {
+		if c.StorageCache == nil {
+			c.StorageCache = map[string]int{}
+		}
+		c.StorageCache[name] = size
+	}
+	if c1.LevelDB != nil {
+		if c.LevelDB == nil {
+			c.LevelDB = &LevelDB{}
+		}
+		if err := mergo.Merge(c.LevelDB, c1.LevelDB, mergo.WithOverride); err != nil {
+			return err
+		}
+	}
 	if c1.Chain != "" {
 		c.Chain = c1.Chain
 	}
@@ -139,15 +411,126 @@ func (c *Config) merge(c1 *Config) error {
 	if c1.Seal {
 		c.Seal = true
 	}
+	if c1.BlockGasTarget != 0 {
+		c.BlockGasTarget = c1.BlockGasTarget
+	}
+	if c1.PriceBump != 0 {
+		c.PriceBump = c1.PriceBump
+	}
+	if c1.MaxPendingTxns != 0 {
+		c.MaxPendingTxns = c1.MaxPendingTxns
+	}
+	if c1.MaxQueuedTxns != 0 {
+		c.MaxQueuedTxns = c1.MaxQueuedTxns
+	}
+	if c1.MaxTxPoolSize != 0 {
+		c.MaxTxPoolSize = c1.MaxTxPoolSize
+	}
+	if c1.AccountPending != 0 {
+		c.AccountPending = c1.AccountPending
+	}
+	if c1.AccountQueued != 0 {
+		c.AccountQueued = c1.AccountQueued
+	}
+	if c1.TxnLifetime != 0 {
+		c.TxnLifetime = c1.TxnLifetime
+	}
+	if len(c1.PrioritySenders) != 0 {
+		c.PrioritySenders = c1.PrioritySenders
+	}
+	if len(c1.PriorityTypes) != 0 {
+		c.PriorityTypes = c1.PriorityTypes
+	}
+	if c1.TxPoolSimulation {
+		c.TxPoolSimulation = true
+	}
 	if c1.LogLevel != "" {
 		c.LogLevel = c1.LogLevel
 	}
 	if c1.GRPCAddr != "" {
 		c.GRPCAddr = c1.GRPCAddr
 	}
+	if c1.GRPCAuth != nil {
+		if c.GRPCAuth == nil {
+			c.GRPCAuth = &GRPCAuth{}
+		}
+		if err := mergo.Merge(c.GRPCAuth, c1.GRPCAuth, mergo.WithOverride); err != nil {
+			return err
+		}
+	}
 	if c1.JSONRPCAddr != "" {
 		c.JSONRPCAddr = c1.JSONRPCAddr
 	}
+	if c1.BatchLimit != 0 {
+		c.BatchLimit = c1.BatchLimit
+	}
+	if c1.MaxRequestSize != 0 {
+		c.MaxRequestSize = c1.MaxRequestSize
+	}
+	if c1.MaxResponseSize != 0 {
+		c.MaxResponseSize = c1.MaxResponseSize
+	}
+	if c1.MaxClientFilters != 0 {
+		c.MaxClientFilters = c1.MaxClientFilters
+	}
+	if c1.LogsBlockRange != 0 {
+		c.LogsBlockRange = c1.LogsBlockRange
+	}
+	if c1.LogsLimit != 0 {
+		c.LogsLimit = c1.LogsLimit
+	}
+	if c1.CacheSize != 0 {
+		c.CacheSize = c1.CacheSize
+	}
+	if c1.AccessLog != "" {
+		c.AccessLog = c1.AccessLog
+	}
+	if c1.JSONRPCTLS != nil {
+		if c.JSONRPCTLS == nil {
+			c.JSONRPCTLS = &TLS{}
+		}
+		if err := mergo.Merge(c.JSONRPCTLS, c1.JSONRPCTLS, mergo.WithOverride); err != nil {
+			return err
+		}
+	}
+	if c1.JSONRPCAdmin {
+		c.JSONRPCAdmin = true
+	}
+	if len(c1.CORSOrigins) != 0 {
+		c.CORSOrigins = c1.CORSOrigins
+	}
+	if len(c1.VHosts) != 0 {
+		c.VHosts = c1.VHosts
+	}
+	if c1.RateLimit != 0 {
+		c.RateLimit = c1.RateLimit
+	}
+	for rule, rate := range c1.MethodRateLimits {
+		if c.MethodRateLimits == nil {
+			c.MethodRateLimits = map[string]uint64{}
+		}
+		c.MethodRateLimits[rule] = rate
+	}
+	if len(c1.AllowMethods) != 0 {
+		c.AllowMethods = c1.AllowMethods
+	}
+	if len(c1.DenyMethods) != 0 {
+		c.DenyMethods = c1.DenyMethods
+	}
+	if c1.IPCPath != "" {
+		c.IPCPath = c1.IPCPath
+	}
+	if c1.NoIPC {
+		c.NoIPC = true
+	}
+	if c1.WS != nil {
+		if c.WS == nil {
+			c.WS = &WS{}
+		}
+		if err := mergo.Merge(c.WS, c1.WS, mergo.WithOverride); err != nil {
+			return err
+		}
+	}
 	if c1.Join != "" {
 		c.Join = c1.Join
 	}
@@ -162,6 +545,9 @@ func (c *Config) merge(c1 *Config) error {
 		if c1.Network.NoDiscover {
 			c.Network.NoDiscover = true
 		}
+		if len(c1.Network.AllowedPeers) != 0 {
+			c.Network.AllowedPeers = c1.Network.AllowedPeers
+		}
 	}
 	if err := mergo.Merge(&c.Consensus, c1.Consensus, mergo.WithOverride); err != nil {
 		return err
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/minimal/keystore"
+	"github.com/hashicorp/go-hclog"
+)
+
+// storageFlags are the flags of the maintenance commands that open the
+// blockchain storage of a data directory while the server is stopped
+type storageFlags struct {
+	dataDir    string
+	backend    string
+	storageKey string
+}
+
+func (s *storageFlags) addFlags(flags *flag.FlagSet) {
+	flags.StringVar(&s.dataDir, "data-dir", "./test-chain", "")
+	flags.StringVar(&s.backend, "storage", blockchain.DefaultStorageBackend, "")
+	flags.StringVar(&s.storageKey, "storage-key", "", "")
+}
+
+func (s *storageFlags) open() (storage.Storage, error) {
+	config := map[string]interface{}{
+		"path": filepath.Join(s.dataDir, "blockchain"),
+	}
+	if s.storageKey != "" {
+		key, err := keystore.ReadEncryptionKey(s.storageKey)
+		if err != nil {
+			return nil, err
+		}
+		config["encryption_key"] = key
+	}
+
+	logger := hclog.New(&hclog.LoggerOptions{Name: "storage"})
+	db, err := storage.Open(s.backend, config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %v", err)
+	}
+	return db, nil
+}
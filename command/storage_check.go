@@ -0,0 +1,115 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/mitchellh/cli"
+)
+
+// StorageCheck is the command to check the integrity of the blockchain storage.
+// It opens the data directory, so the server has to be stopped
+type StorageCheck struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *StorageCheck) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.Command interface
+func (c *StorageCheck) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.Command interface
+func (c *StorageCheck) Run(args []string) int {
+	flags := flag.NewFlagSet("storage check", flag.ContinueOnError)
+	flags.Usage = func() {}
+
+	var storageFlags storageFlags
+	var repair string
+	storageFlags.addFlags(flags)
+	flags.StringVar(&repair, "repair", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	var mode storage.RepairMode
+	switch repair {
+	case "":
+		mode = storage.RepairNone
+	case "drop":
+		mode = storage.RepairDrop
+	case "quarantine":
+		mode = storage.RepairQuarantine
+	default:
+		c.UI.Error(fmt.Sprintf("unknown repair mode '%s', expected drop or quarantine", repair))
+		return 1
+	}
+
+	s, err := storageFlags.open()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	defer s.Close()
+
+	kv, ok := s.(*storage.KeyValueStorage)
+	if !ok {
+		c.UI.Error(fmt.Sprintf("storage backend '%s' does not support checks", storageFlags.backend))
+		return 1
+	}
+	report, err := kv.Check(mode)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to check storage: %v", err))
+		return 1
+	}
+
+	c.UI.Output(printCheckReport(report))
+
+	if len(report.Corrupted) != 0 {
+		switch mode {
+		case storage.RepairNone:
+			c.UI.Output("\nRun with -repair drop or -repair quarantine to remove the corrupted entries")
+		case storage.RepairDrop:
+			c.UI.Output("\nThe corrupted entries were deleted")
+		case storage.RepairQuarantine:
+			c.UI.Output("\nThe corrupted entries were moved to the quarantine prefix")
+		}
+	}
+	return 0
+}
+
+func printCheckReport(report *storage.CheckReport) (output string) {
+	names := []string{}
+	for name := range report.Entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := []string{}
+	for _, name := range names {
+		entries = append(entries, fmt.Sprintf("%s|%d", name, report.Entries[name]))
+	}
+
+	output = "Entries\n"
+	output += formatKV(entries)
+
+	corrupted := make([]string, len(report.Corrupted)+1)
+	corrupted[0] = "Key|Reason"
+	for i, entry := range report.Corrupted {
+		corrupted[i+1] = fmt.Sprintf("%s|%s", hex.EncodeToHex(entry.Key), entry.Reason)
+	}
+
+	output += "\n\nCorrupted\n"
+	output += formatList(corrupted)
+
+	return output
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/blockchain/storage"
+	"github.com/mitchellh/cli"
+)
+
+// StorageInspect is the command to show the number of entries and the space
+// used by each namespace of the blockchain storage
+type StorageInspect struct {
+	UI cli.Ui
+}
+
+// Help implements the cli.Command interface
+func (c *StorageInspect) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.Command interface
+func (c *StorageInspect) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.Command interface
+func (c *StorageInspect) Run(args []string) int {
+	flags := flag.NewFlagSet("storage inspect", flag.ContinueOnError)
+	flags.Usage = func() {}
+
+	var storageFlags storageFlags
+	storageFlags.addFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	s, err := storageFlags.open()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	defer s.Close()
+
+	stats, err := storage.Inspect(s)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to inspect storage: %v", err))
+		return 1
+	}
+
+	var keys, size uint64
+	rows := make([]string, len(stats)+1)
+	rows[0] = "Namespace|Keys|Size"
+	for i, stat := range stats {
+		rows[i+1] = fmt.Sprintf("%s|%d|%s", stat.Name, stat.Keys, formatBytes(stat.Bytes))
+		keys += stat.Keys
+		size += stat.Bytes
+	}
+	c.UI.Output(formatList(rows))
+	c.UI.Output(fmt.Sprintf("\nTotal: %d keys, %s", keys, formatBytes(size)))
+	return 0
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	txpoolOp "github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// TxPoolAccount is the command to show the transactions of an account in the pool
+type TxPoolAccount struct {
+	Meta
+}
+
+// Help implements the cli.TxPoolAccount interface
+func (p *TxPoolAccount) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.TxPoolAccount interface
+func (p *TxPoolAccount) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.TxPoolAccount interface
+func (p *TxPoolAccount) Run(args []string) int {
+	flags := p.FlagSet("txpool account")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		p.UI.Error("address expected")
+		return 1
+	}
+
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(args[0])); err != nil {
+		p.UI.Error("failed to decode addr")
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := txpoolOp.NewTxnPoolOperatorClient(conn)
+	resp, err := clt.GetAccount(context.Background(), &txpoolOp.AccountReq{Address: addr.String()})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	p.UI.Output(formatKV([]string{
+		fmt.Sprintf("Address|%s", resp.Address),
+		fmt.Sprintf("Local|%v", resp.Local),
+		fmt.Sprintf("Pending|%d", len(resp.Pending)),
+		fmt.Sprintf("Queued|%d", len(resp.Queued)),
+	}))
+
+	if len(resp.Pending) == 0 && len(resp.Queued) == 0 {
+		return 0
+	}
+	rows := []string{"Hash|Nonce|Gas Price|Gas|Status"}
+	for _, txn := range resp.Pending {
+		rows = append(rows, fmt.Sprintf("%s|%d|%s|%d|pending", txn.Hash, txn.Nonce, txn.GasPrice, txn.Gas))
+	}
+	for _, txn := range resp.Queued {
+		rows = append(rows, fmt.Sprintf("%s|%d|%s|%d|queued", txn.Hash, txn.Nonce, txn.GasPrice, txn.Gas))
+	}
+	p.UI.Output("\n" + formatList(rows))
+	return 0
+}
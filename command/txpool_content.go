@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	txpoolOp "github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// TxPoolContent is the command to list the accounts of the pool
+type TxPoolContent struct {
+	Meta
+}
+
+// Help implements the cli.TxPoolContent interface
+func (p *TxPoolContent) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.TxPoolContent interface
+func (p *TxPoolContent) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.TxPoolContent interface
+func (p *TxPoolContent) Run(args []string) int {
+	flags := p.FlagSet("txpool content")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := txpoolOp.NewTxnPoolOperatorClient(conn)
+	resp, err := clt.GetContent(context.Background(), &empty.Empty{})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(resp.Accounts) == 0 {
+		p.UI.Output("No transactions")
+		return 0
+	}
+
+	rows := []string{"Address|Pending|Queued|Local"}
+	for _, acct := range resp.Accounts {
+		rows = append(rows, fmt.Sprintf("%s|%d|%d|%v", acct.Address, len(acct.Pending), len(acct.Queued), acct.Local))
+	}
+	p.UI.Output(formatList(rows))
+	return 0
+}
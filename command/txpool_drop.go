@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+
+	txpoolOp "github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// TxPoolDrop is the command to remove a transaction from the pool
+type TxPoolDrop struct {
+	Meta
+}
+
+// Help implements the cli.TxPoolDrop interface
+func (p *TxPoolDrop) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.TxPoolDrop interface
+func (p *TxPoolDrop) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.TxPoolDrop interface
+func (p *TxPoolDrop) Run(args []string) int {
+	flags := p.FlagSet("txpool drop")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		p.UI.Error("hash expected")
+		return 1
+	}
+
+	var hash types.Hash
+	if err := hash.UnmarshalText([]byte(args[0])); err != nil {
+		p.UI.Error("failed to decode hash")
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := txpoolOp.NewTxnPoolOperatorClient(conn)
+	if _, err := clt.DropTxn(context.Background(), &txpoolOp.DropTxnReq{Hash: hash.String()}); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	txpoolOp "github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// TxPoolFlush is the command to remove the queued transactions of an account
+type TxPoolFlush struct {
+	Meta
+}
+
+// Help implements the cli.TxPoolFlush interface
+func (p *TxPoolFlush) Help() string {
+	return ""
+}
+
+// Synopsis implements the cli.TxPoolFlush interface
+func (p *TxPoolFlush) Synopsis() string {
+	return ""
+}
+
+// Run implements the cli.TxPoolFlush interface
+func (p *TxPoolFlush) Run(args []string) int {
+	flags := p.FlagSet("txpool flush")
+	if err := flags.Parse(args); err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	args = flags.Args()
+	if len(args) != 1 {
+		p.UI.Error("address expected")
+		return 1
+	}
+
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(args[0])); err != nil {
+		p.UI.Error("failed to decode addr")
+		return 1
+	}
+
+	conn, err := p.Conn()
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+
+	clt := txpoolOp.NewTxnPoolOperatorClient(conn)
+	resp, err := clt.FlushAccount(context.Background(), &txpoolOp.AccountReq{Address: addr.String()})
+	if err != nil {
+		p.UI.Error(err.Error())
+		return 1
+	}
+	p.UI.Output(fmt.Sprintf("Dropped %d queued txns", resp.Dropped))
+	return 0
+}
@@ -47,6 +47,10 @@ func (p *TxPoolStatus) Run(args []string) int {
 		return 1
 	}
 
-	p.UI.Output(fmt.Sprintf("%d", resp.Length))
+	p.UI.Output(formatKV([]string{
+		fmt.Sprintf("Pending|%d", resp.Length),
+		fmt.Sprintf("Queued|%d", resp.Queued),
+		fmt.Sprintf("Size|%s", formatBytes(resp.Size)),
+	}))
 	return 0
 }
@@ -0,0 +1,102 @@
+package consensus
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/txpool"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// Builder builds the blocks of a sealing engine. The engine prepares the
+// header on top of the parent, the txns of the pool are applied and the
+// engine seals the block
+type Builder struct {
+	engine   Consensus
+	executor *state.Executor
+	txpool   *txpool.TxPool
+
+	// gasTarget is the gas limit the blocks move toward and gasLimit is the
+	// gas limit without a target, the parent one is kept if both are zero
+	gasTarget uint64
+	gasLimit  uint64
+}
+
+// NewBuilder creates the builder of the engine, gasLimit is the gas limit of the
+// blocks if the config has no block gas target or zero to keep the parent one
+func NewBuilder(engine Consensus, config *Config, executor *state.Executor, txpool *txpool.TxPool, gasLimit uint64) *Builder {
+	return &Builder{
+		engine:    engine,
+		executor:  executor,
+		txpool:    txpool,
+		gasTarget: config.BlockGasTarget,
+		gasLimit:  gasLimit,
+	}
+}
+
+// SetGasTarget changes the gas target of the next blocks
+func (b *Builder) SetGasTarget(gasTarget uint64) {
+	atomic.StoreUint64(&b.gasTarget, gasTarget)
+}
+
+func (b *Builder) calcGasLimit(parent *types.Header) uint64 {
+	gasTarget := atomic.LoadUint64(&b.gasTarget)
+	if gasTarget == 0 && b.gasLimit != 0 {
+		return b.gasLimit
+	}
+	return CalcGasLimit(parent.GasLimit, gasTarget)
+}
+
+// Build builds a block on top of the parent with the txns of the pool and
+// seals it. It returns nil if the engine aborts the seal. The txns stay in
+// the pool until the engine resets it with the written block
+func (b *Builder) Build(ctx context.Context, parent *types.Header) (*types.Block, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		GasLimit:   b.calcGasLimit(parent),
+		Timestamp:  uint64(time.Now().Unix()),
+		BaseFee:    chain.CalcBaseFee(b.executor.Config().Forks, parent),
+	}
+	if err := b.engine.Prepare(header); err != nil {
+		return nil, err
+	}
+
+	transition, err := b.executor.BeginTxn(parent.StateRoot, header)
+	if err != nil {
+		return nil, err
+	}
+	// the txns with the highest gas price go first
+	txns := []*types.Transaction{}
+	pending := b.txpool.Pending()
+	for {
+		txn := pending.Peek()
+		if txn == nil {
+			break
+		}
+		if txn.Gas > header.GasLimit-transition.TotalGas() {
+			// the next txns of the account cannot be applied without this one
+			pending.Pop()
+			continue
+		}
+		if err := transition.Write(txn); err != nil {
+			pending.Pop()
+			continue
+		}
+		txns = append(txns, txn)
+		pending.Shift()
+	}
+	if err := transition.Finalize(nil); err != nil {
+		return nil, err
+	}
+
+	_, root := transition.Commit()
+	header.StateRoot = root
+	header.GasUsed = transition.TotalGas()
+
+	// the engine seals the block once all the fields are completed
+	return b.engine.Seal(BuildBlock(header, txns, transition.Receipts()), ctx)
+}
@@ -0,0 +1,258 @@
+package consensus
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/state"
+	itrie "github.com/0xPolygon/minimal/state/immutable-trie"
+	"github.com/0xPolygon/minimal/txpool"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSealer struct {
+	mockEngine
+	abort bool
+}
+
+func (m *mockSealer) Prepare(header *types.Header) error {
+	header.Miner = types.StringToAddress("1")
+	return nil
+}
+
+func (m *mockSealer) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	if m.abort {
+		return nil, nil
+	}
+	block.Header.ExtraData = []byte{0x1}
+	return block, nil
+}
+
+func TestBuilder_Build(t *testing.T) {
+	executor := state.NewExecutor(&chain.Params{Forks: chain.AllForksEnabled}, itrie.NewState(itrie.NewMemoryStorage()))
+	executor.GetHash = func(*types.Header) state.GetHashByNumber {
+		return func(uint64) types.Hash {
+			return types.Hash{}
+		}
+	}
+	pool, err := txpool.NewTxPool(hclog.NewNullLogger(), false, nil, nil, nil)
+	assert.NoError(t, err)
+
+	parent := &types.Header{
+		Number:    10,
+		GasLimit:  1024000,
+		StateRoot: executor.WriteGenesis(nil),
+	}
+	parent.ComputeHash()
+
+	engine := &mockSealer{}
+	b := NewBuilder(engine, &Config{}, executor, pool, 0)
+
+	block, err := b.Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, parent.Hash, block.ParentHash())
+	assert.Equal(t, uint64(11), block.Number())
+	assert.Equal(t, parent.GasLimit, block.Header.GasLimit)
+
+	// the engine prepares and seals the block
+	assert.Equal(t, types.StringToAddress("1"), block.Header.Miner)
+	assert.Equal(t, []byte{0x1}, block.Header.ExtraData)
+
+	// the gas limit without a gas target
+	b = NewBuilder(engine, &Config{}, executor, pool, 5000)
+	block, err = b.Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5000), block.Header.GasLimit)
+
+	b = NewBuilder(engine, &Config{BlockGasTarget: 2000000}, executor, pool, 5000)
+	block, err = b.Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, CalcGasLimit(parent.GasLimit, 2000000), block.Header.GasLimit)
+
+	engine.abort = true
+	block, err = b.Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+}
+
+func TestBuilder_Rewards(t *testing.T) {
+	proposer := types.StringToAddress("2")
+	treasury := types.StringToAddress("3")
+	sender, receiver := types.StringToAddress("4"), types.StringToAddress("5")
+
+	params := &chain.Params{
+		Forks: chain.AllForksEnabled,
+		Rewards: &chain.BlockRewards{
+			Reward: big.NewInt(1000),
+		},
+	}
+	executor := state.NewExecutor(params, itrie.NewState(itrie.NewMemoryStorage()))
+	executor.GetHash = func(*types.Header) state.GetHashByNumber {
+		return func(uint64) types.Hash {
+			return types.Hash{}
+		}
+	}
+	executor.GetProposer = func(*types.Header) (types.Address, error) {
+		return proposer, nil
+	}
+	pool, err := txpool.NewTxPool(hclog.NewNullLogger(), false, nil, nil, nil)
+	assert.NoError(t, err)
+
+	parent := &types.Header{
+		Number:   10,
+		GasLimit: 1024000,
+		StateRoot: executor.WriteGenesis(map[types.Address]*chain.GenesisAccount{
+			sender: {Balance: big.NewInt(1000000)},
+		}),
+	}
+	parent.ComputeHash()
+
+	balance := func(root types.Hash, addr types.Address) uint64 {
+		transition, err := executor.BeginTxn(root, parent)
+		assert.NoError(t, err)
+		return transition.GetBalance(addr).Uint64()
+	}
+
+	// the proposer gets the reward instead of the coinbase
+	block, err := NewBuilder(&mockSealer{}, &Config{}, executor, pool, 0).Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), balance(block.Header.StateRoot, proposer))
+	assert.Equal(t, uint64(0), balance(block.Header.StateRoot, block.Header.Miner))
+
+	// the treasury gets the reward and a share of the fees
+	params.Rewards.Treasury = &treasury
+	params.Rewards.TreasuryFees = 25
+
+	transition, err := executor.BeginTxn(parent.StateRoot, parent)
+	assert.NoError(t, err)
+	assert.NoError(t, transition.Write(&types.Transaction{
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		To:       &receiver,
+		Value:    big.NewInt(1),
+		From:     sender,
+	}))
+	assert.NoError(t, transition.Finalize(nil))
+	_, root := transition.Commit()
+
+	assert.Equal(t, uint64(1000+21000/4), balance(root, treasury))
+	assert.Equal(t, uint64(21000-21000/4), balance(root, proposer))
+}
+
+func TestBuilder_RewardsRange(t *testing.T) {
+	miner := types.StringToAddress("1")
+
+	executor := state.NewExecutor(&chain.Params{Forks: chain.AllForksEnabled}, itrie.NewState(itrie.NewMemoryStorage()))
+	executor.GetHash = func(*types.Header) state.GetHashByNumber {
+		return func(uint64) types.Hash {
+			return types.Hash{}
+		}
+	}
+	root := executor.WriteGenesis(map[types.Address]*chain.GenesisAccount{})
+
+	// the rewards of the engine are only credited in its range of blocks
+	executor.EnableRewards(10, 12)
+
+	reward := func(number uint64) *big.Int {
+		transition, err := executor.BeginTxn(root, &types.Header{Number: number, Miner: miner, GasLimit: 1024000})
+		assert.NoError(t, err)
+		assert.NoError(t, transition.Finalize(nil))
+		return transition.GetBalance(miner)
+	}
+	assert.Equal(t, big.NewInt(0), reward(9))
+	assert.Equal(t, state.ConstantinopleBlockReward, reward(10))
+	assert.Equal(t, state.ConstantinopleBlockReward, reward(11))
+	assert.Equal(t, big.NewInt(0), reward(12))
+}
+
+func TestSetProposer(t *testing.T) {
+	miner := types.StringToAddress("1")
+	first, second := types.StringToAddress("2"), types.StringToAddress("3")
+
+	executor := state.NewExecutor(&chain.Params{Forks: chain.AllForksEnabled}, itrie.NewState(itrie.NewMemoryStorage()))
+
+	// two engines with the blocks up to 10 and from 10
+	SetProposer(executor, &Config{EndBlock: 10}, func(*types.Header) (types.Address, error) {
+		return first, nil
+	})
+	SetProposer(executor, &Config{ForkBlock: 10}, func(*types.Header) (types.Address, error) {
+		return second, nil
+	})
+
+	proposer := func(number uint64) types.Address {
+		addr, err := executor.GetProposer(&types.Header{Number: number, Miner: miner})
+		assert.NoError(t, err)
+		return addr
+	}
+	assert.Equal(t, first, proposer(9))
+	assert.Equal(t, second, proposer(10))
+
+	// the miner is the proposer outside of the range of the engines
+	executor.GetProposer = nil
+	SetProposer(executor, &Config{ForkBlock: 10}, func(*types.Header) (types.Address, error) {
+		return second, nil
+	})
+	assert.Equal(t, miner, proposer(9))
+}
+
+func TestBuilder_BaseFee(t *testing.T) {
+	sender, receiver := types.StringToAddress("4"), types.StringToAddress("5")
+
+	forks := *chain.AllForksEnabled
+	forks.London = chain.NewFork(11)
+
+	executor := state.NewExecutor(&chain.Params{Forks: &forks}, itrie.NewState(itrie.NewMemoryStorage()))
+	executor.GetHash = func(*types.Header) state.GetHashByNumber {
+		return func(uint64) types.Hash {
+			return types.Hash{}
+		}
+	}
+	pool, err := txpool.NewTxPool(hclog.NewNullLogger(), false, nil, nil, nil)
+	assert.NoError(t, err)
+
+	parent := &types.Header{
+		Number:   10,
+		GasLimit: 1024000,
+		StateRoot: executor.WriteGenesis(map[types.Address]*chain.GenesisAccount{
+			sender: {Balance: big.NewInt(1000000000000000)},
+		}),
+	}
+	parent.ComputeHash()
+
+	// the first block of the fork has the initial base fee
+	block, err := NewBuilder(&mockSealer{}, &Config{}, executor, pool, 0).Build(context.Background(), parent)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(chain.InitialBaseFee), block.Header.BaseFee)
+	assert.NoError(t, chain.VerifyBaseFee(&forks, parent, block.Header))
+
+	// the sender pays the base fee and the tip, the coinbase only gets the tip
+	header := block.Header
+	transition, err := executor.BeginTxn(parent.StateRoot, header)
+	assert.NoError(t, err)
+	assert.NoError(t, transition.Write(&types.Transaction{
+		Type:      types.DynamicFeeTx,
+		GasPrice:  big.NewInt(chain.InitialBaseFee + 5),
+		GasTipCap: big.NewInt(2),
+		Gas:       21000,
+		To:        &receiver,
+		Value:     big.NewInt(0),
+		From:      sender,
+	}))
+	assert.Equal(t, uint64(1000000000000000-21000*(chain.InitialBaseFee+2)), transition.GetBalance(sender).Uint64())
+	assert.Equal(t, uint64(21000*2), transition.GetBalance(header.Miner).Uint64())
+
+	// the fee cap has to cover the base fee
+	_, _, err = transition.Apply(&types.Transaction{
+		Nonce:    1,
+		GasPrice: big.NewInt(chain.InitialBaseFee - 1),
+		Gas:      21000,
+		To:       &receiver,
+		Value:    big.NewInt(0),
+		From:     sender,
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,555 @@
+package clique
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/protocol"
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/txpool"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"google.golang.org/grpc"
+)
+
+const (
+	// KeyName is the name of the file of the signer key in the consensus path
+	KeyName = "validator.key"
+
+	// snapshotFileName is the file in which the head snapshot is saved on close
+	snapshotFileName = "clique_snapshot"
+
+	defaultEpoch  = 30000
+	defaultPeriod = 15
+
+	// defaultMaxClockDrift is the allowed clock drift in seconds, the headers
+	// cannot be in the future by default
+	defaultMaxClockDrift = 0
+
+	// number of snapshots kept in memory
+	inmemorySnapshots = 128
+
+	// wiggleTime is the delay per signer allowed to the out of turn signers
+	// so that they do not all seal at the same time
+	wiggleTime = 500 * time.Millisecond
+)
+
+var (
+	errUnknownAncestor              = errors.New("unknown ancestor")
+	errInvalidCheckpointBeneficiary = errors.New("beneficiary in checkpoint block non-zero")
+	errInvalidVote                  = errors.New("vote nonce not 0x00..0 or 0xff..f")
+	errInvalidCheckpointVote        = errors.New("vote nonce in checkpoint block non-zero")
+	errMissingVanity                = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingSignature             = errors.New("extra-data 65 byte signature suffix missing")
+	errExtraSigners                 = errors.New("non-checkpoint block contains extra signer list")
+	errInvalidCheckpointSigners     = errors.New("invalid signer list on checkpoint block")
+	errMismatchingCheckpointSigners = errors.New("mismatching signer list on checkpoint block")
+	errInvalidMixDigest             = errors.New("non-zero mix digest")
+	errInvalidUncleHash             = errors.New("non empty uncle hash")
+	errInvalidDifficulty            = errors.New("invalid difficulty")
+	errWrongDifficulty              = errors.New("wrong difficulty")
+	errInvalidTimestamp             = errors.New("invalid timestamp")
+	errInvalidVotingChain           = errors.New("invalid voting chain")
+	errUnauthorizedSigner           = errors.New("unauthorized signer")
+	errRecentlySigned               = errors.New("recently signed")
+)
+
+type blockchainInterface interface {
+	Header() *types.Header
+	GetHeaderByHash(hash types.Hash) (*types.Header, bool)
+	GetHeaderByNumber(n uint64) (*types.Header, bool)
+	WriteBlocks(blocks []*types.Block) error
+}
+
+// Clique is the proof of authority consensus of EIP-225. The authorized signers
+// take turns to seal the blocks and they vote to add or remove signers with the
+// beneficiary and the nonce of the blocks they seal
+type Clique struct {
+	sealing bool
+
+	logger hclog.Logger
+	config *consensus.Config
+
+	// period is the minimum number of seconds between blocks and epoch
+	// is the number of blocks between checkpoints
+	period uint64
+	epoch  uint64
+
+	// maxClockDrift is how far in the future the timestamp of a header can be
+	maxClockDrift time.Duration
+
+	blockchain blockchainInterface
+	executor   *state.Executor
+	txpool     *txpool.TxPool
+
+	signerKey  *ecdsa.PrivateKey
+	signerAddr types.Address
+
+	// snapshots by block hash
+	snapshots *lru.Cache
+
+	syncer   *protocol.Syncer
+	operator *operator
+	builder  *consensus.Builder
+
+	closeCh chan struct{}
+}
+
+func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv grpc.ServiceRegistrar, logger hclog.Logger) (consensus.Consensus, error) {
+	c := &Clique{
+		sealing:    sealing,
+		logger:     logger.Named("clique"),
+		config:     config,
+		blockchain: blockchain,
+		executor:   executor,
+		txpool:     txpool,
+		closeCh:    make(chan struct{}),
+	}
+
+	var err error
+	if c.epoch, err = consensus.GetUint(config.Config, "epoch", defaultEpoch, false); err != nil {
+		return nil, err
+	}
+	if c.period, err = consensus.GetUint(config.Config, "period", defaultPeriod, false); err != nil {
+		return nil, err
+	}
+	maxClockDrift, err := consensus.GetUint(config.Config, "maxClockDrift", defaultMaxClockDrift, true)
+	if err != nil {
+		return nil, err
+	}
+	c.maxClockDrift = time.Duration(maxClockDrift) * time.Second
+
+	if c.snapshots, err = lru.New(inmemorySnapshots); err != nil {
+		return nil, err
+	}
+
+	if c.signerKey, err = crypto.ReadPrivKey(filepath.Join(config.Path, KeyName)); err != nil {
+		return nil, err
+	}
+	c.signerAddr = crypto.PubKeyToAddress(&c.signerKey.PublicKey)
+	c.logger.Info("signer key", "addr", c.signerAddr.String())
+
+	c.syncer = protocol.NewSyncer(logger, network, blockchain)
+	c.builder = consensus.NewBuilder(c, config, executor, txpool, 100000000)
+
+	// the block rewards go to the signer, the miner field holds the votes
+	consensus.SetProposer(executor, config, c.blockProposer)
+
+	// register the grpc operator
+	c.operator = newOperator(c)
+	proto.RegisterCliqueOperatorServer(srv, c.operator)
+
+	return c, nil
+}
+
+// blockProposer returns the signer of the block, the blocks built by the
+// node are not sealed yet when the rewards are credited
+func (c *Clique) blockProposer(header *types.Header) (types.Address, error) {
+	if len(header.ExtraData) >= ExtraSeal && bytes.Equal(header.ExtraData[len(header.ExtraData)-ExtraSeal:], make([]byte, ExtraSeal)) {
+		return c.signerAddr, nil
+	}
+	return ecrecover(header)
+}
+
+// Start implements the consensus.Consensus interface
+func (c *Clique) Start() error {
+	if c.config.Path != "" {
+		snap, err := loadSnapshot(filepath.Join(c.config.Path, snapshotFileName))
+		if err != nil {
+			return err
+		}
+		if snap != nil {
+			c.snapshots.Add(snap.Hash, snap)
+		}
+	}
+
+	c.syncer.Start()
+	go c.run()
+
+	return nil
+}
+
+// Close implements the consensus.Consensus interface
+func (c *Clique) Close() error {
+	close(c.closeCh)
+
+	if c.config.Path == "" {
+		return nil
+	}
+	snap, err := c.getSnapshot(c.blockchain.Header())
+	if err != nil {
+		return err
+	}
+	return saveSnapshot(filepath.Join(c.config.Path, snapshotFileName), snap)
+}
+
+func (c *Clique) isClosed() bool {
+	select {
+	case <-c.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait waits for the duration, it returns false if the consensus is closed
+func (c *Clique) wait(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-c.closeCh:
+		return false
+	}
+}
+
+func (c *Clique) run() {
+	c.logger.Info("started")
+
+	for !c.isClosed() {
+		// catch up with the best peer first
+		if p := c.syncer.BestPeer(); p != nil {
+			if err := c.syncer.BulkSyncWithPeer(p); err != nil {
+				c.logger.Error("failed to bulk sync", "err", err)
+				c.wait(time.Second)
+				continue
+			}
+			if !c.sealing {
+				c.syncer.WatchSyncWithPeer(p, func(b *types.Block) bool {
+					return !c.isClosed()
+				})
+			}
+			continue
+		}
+
+		if !c.sealing {
+			c.wait(time.Second)
+			continue
+		}
+		if err := c.sealBlock(); err != nil {
+			c.logger.Error("failed to seal block", "err", err)
+			c.wait(time.Second)
+		}
+	}
+}
+
+// sealBlock seals a block on top of the head if the signer is authorized and
+// it did not sign recently. It waits for the timestamp of the block and
+// drops it if another block is written in the meantime
+func (c *Clique) sealBlock() error {
+	parent := c.blockchain.Header()
+
+	snap, err := c.getSnapshot(parent)
+	if err != nil {
+		return err
+	}
+	number := parent.Number + 1
+
+	if !snap.IsSigner(c.signerAddr) || snap.RecentlySigned(number, c.signerAddr) {
+		// wait for the next block
+		c.waitHead(parent, time.Duration(c.period)*time.Second)
+		return nil
+	}
+
+	block, err := c.builder.Build(context.Background(), parent)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		// the head changed or the consensus is closed
+		return nil
+	}
+
+	if err := c.blockchain.WriteBlocks([]*types.Block{block}); err != nil {
+		return err
+	}
+	c.logger.Info("sealed block", "number", number, "hash", block.Hash(), "txns", len(block.Transactions))
+
+	c.syncer.Broadcast(block)
+
+	// remove the included transactions from the pool
+	c.txpool.ResetWithHeader(block.Header)
+	return nil
+}
+
+// waitHead waits for the duration, it returns false if the head is not the parent anymore
+func (c *Clique) waitHead(parent *types.Header, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			return c.blockchain.Header().Hash == parent.Hash
+		case <-ticker.C:
+			if c.blockchain.Header().Hash != parent.Hash {
+				return false
+			}
+		case <-c.closeCh:
+			return false
+		}
+	}
+}
+
+func (c *Clique) parentSnapshot(header *types.Header) (*types.Header, *Snapshot, error) {
+	parent, ok := c.blockchain.GetHeaderByHash(header.ParentHash)
+	if !ok {
+		return nil, nil, fmt.Errorf("parent %s not found", header.ParentHash)
+	}
+	snap, err := c.getSnapshot(parent)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parent, snap, nil
+}
+
+// Prepare implements the consensus.Consensus interface
+func (c *Clique) Prepare(header *types.Header) error {
+	parent, snap, err := c.parentSnapshot(header)
+	if err != nil {
+		return err
+	}
+	number := header.Number
+
+	header.Miner = types.ZeroAddress
+	header.Nonce = nonceDropVote
+	header.MixHash = types.ZeroHash
+	header.Difficulty = diffNoTurn
+	if snap.InTurn(number, c.signerAddr) {
+		header.Difficulty = diffInTurn
+	}
+
+	if number%c.epoch == 0 {
+		// checkpoints include the signers and cannot vote
+		header.ExtraData = BuildExtra(nil, snap.Signers)
+	} else {
+		header.ExtraData = BuildExtra(nil, nil)
+
+		if addr, authorize, ok := c.operator.nextProposal(snap); ok {
+			header.Miner = addr
+			if authorize {
+				header.Nonce = nonceAuthVote
+			}
+		}
+	}
+
+	if timestamp := parent.Timestamp + c.period; header.Timestamp < timestamp {
+		header.Timestamp = timestamp
+	}
+	return nil
+}
+
+// Seal implements the consensus.Consensus interface, it signs the block and
+// waits for its timestamp. The seal is aborted if another block is written
+func (c *Clique) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	parent, snap, err := c.parentSnapshot(block.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	// the seal is written after all the fields are completed
+	if block.Header, err = writeSeal(c.signerKey, block.Header); err != nil {
+		return nil, err
+	}
+
+	delay := time.Until(time.Unix(int64(block.Header.Timestamp), 0))
+	if !snap.InTurn(block.Number(), c.signerAddr) {
+		// give the in turn signer the chance to seal first
+		wiggle := time.Duration(len(snap.Signers)/2+1) * wiggleTime
+		delay += time.Duration(rand.Int63n(int64(wiggle)))
+	}
+	if !c.waitHead(parent, delay) || ctx.Err() != nil {
+		return nil, nil
+	}
+	return block, nil
+}
+
+// VerifyHeader implements the consensus.Consensus interface
+func (c *Clique) VerifyHeader(parent, header *types.Header) error {
+	snap, err := c.verifyHeader(parent, header)
+	if err != nil {
+		return err
+	}
+
+	// the snapshot of the header is the parent one for the next header
+	next, err := snap.apply([]*types.Header{header}, c.epoch)
+	if err != nil {
+		return err
+	}
+	c.snapshots.Add(next.Hash, next)
+	return nil
+}
+
+// verifyHeader verifies the header and returns the snapshot of the parent
+func (c *Clique) verifyHeader(parent, header *types.Header) (*Snapshot, error) {
+	number := header.Number
+
+	if err := consensus.VerifyTimestamp(header, c.maxClockDrift); err != nil {
+		return nil, err
+	}
+	if err := chain.VerifyBaseFee(c.config.Forks(), parent, header); err != nil {
+		return nil, err
+	}
+
+	checkpoint := number%c.epoch == 0
+	if checkpoint && header.Miner != types.ZeroAddress {
+		return nil, errInvalidCheckpointBeneficiary
+	}
+	if header.Nonce != nonceAuthVote && header.Nonce != nonceDropVote {
+		return nil, errInvalidVote
+	}
+	if checkpoint && header.Nonce != nonceDropVote {
+		return nil, errInvalidCheckpointVote
+	}
+
+	if len(header.ExtraData) < ExtraVanity {
+		return nil, errMissingVanity
+	}
+	if len(header.ExtraData) < ExtraVanity+ExtraSeal {
+		return nil, errMissingSignature
+	}
+	signersBytes := len(header.ExtraData) - ExtraVanity - ExtraSeal
+	if !checkpoint && signersBytes != 0 {
+		return nil, errExtraSigners
+	}
+	if checkpoint && signersBytes%types.AddressLength != 0 {
+		return nil, errInvalidCheckpointSigners
+	}
+
+	if header.MixHash != types.ZeroHash {
+		return nil, errInvalidMixDigest
+	}
+	if header.Sha3Uncles != types.EmptyUncleHash {
+		return nil, errInvalidUncleHash
+	}
+	if header.Difficulty != diffInTurn && header.Difficulty != diffNoTurn {
+		return nil, errInvalidDifficulty
+	}
+	if parent.Timestamp+c.period > header.Timestamp {
+		return nil, errInvalidTimestamp
+	}
+
+	snap, err := c.getSnapshot(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint {
+		signers, err := extraSigners(header)
+		if err != nil {
+			return nil, err
+		}
+		if len(signers) != len(snap.Signers) {
+			return nil, errMismatchingCheckpointSigners
+		}
+		for i, signer := range signers {
+			if signer != snap.Signers[i] {
+				return nil, errMismatchingCheckpointSigners
+			}
+		}
+	}
+
+	// verify the seal
+	signer, err := ecrecover(header)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.IsSigner(signer) {
+		return nil, errUnauthorizedSigner
+	}
+	if snap.RecentlySigned(number, signer) {
+		return nil, errRecentlySigned
+	}
+	inturn := snap.InTurn(number, signer)
+	if inturn && header.Difficulty != diffInTurn || !inturn && header.Difficulty != diffNoTurn {
+		return nil, errWrongDifficulty
+	}
+	return snap, nil
+}
+
+// checkpointSnapshot builds the snapshot after a checkpoint, the signers
+// of the checkpoint and of the blocks before it up to the recents limit
+// cannot sign again yet
+func (c *Clique) checkpointSnapshot(h *types.Header) (*Snapshot, error) {
+	// the signers of the checkpoints were verified when they were written
+	signers, err := extraSigners(h)
+	if err != nil {
+		return nil, err
+	}
+	snap := newSnapshot(h.Number, h.Hash, signers)
+
+	// the genesis is not signed
+	limit := snap.recentsLimit()
+	for hh, i := h, uint64(0); hh.Number > 0 && i < limit; i++ {
+		signer, err := ecrecover(hh)
+		if err != nil {
+			return nil, err
+		}
+		snap.Recents[hh.Number] = signer
+
+		if i+1 < limit {
+			parent, ok := c.blockchain.GetHeaderByHash(hh.ParentHash)
+			if !ok {
+				return nil, errUnknownAncestor
+			}
+			hh = parent
+		}
+	}
+	return snap, nil
+}
+
+// getSnapshot returns the snapshot after the header. The snapshots that are not
+// in memory are built from the closest one, or from a checkpoint, and the headers
+func (c *Clique) getSnapshot(header *types.Header) (*Snapshot, error) {
+	headers := []*types.Header{}
+
+	var snap *Snapshot
+	for h := header; snap == nil; {
+		if s, ok := c.snapshots.Get(h.Hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		if h.Number%c.epoch == 0 {
+			s, err := c.checkpointSnapshot(h)
+			if err != nil {
+				return nil, err
+			}
+			snap = s
+			break
+		}
+
+		headers = append(headers, h)
+
+		parent, ok := c.blockchain.GetHeaderByHash(h.ParentHash)
+		if !ok {
+			return nil, errUnknownAncestor
+		}
+		h = parent
+	}
+
+	// apply the headers from the oldest
+	for i, j := 0, len(headers)-1; i < j; i, j = i+1, j-1 {
+		headers[i], headers[j] = headers[j], headers[i]
+	}
+	snap, err := snap.apply(headers, c.epoch)
+	if err != nil {
+		return nil, err
+	}
+	c.snapshots.Add(snap.Hash, snap)
+	return snap, nil
+}
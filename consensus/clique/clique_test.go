@@ -0,0 +1,338 @@
+package clique
+
+import (
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/types"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBlockchain struct {
+	headers []*types.Header
+}
+
+func (m *mockBlockchain) Header() *types.Header {
+	return m.headers[len(m.headers)-1]
+}
+
+func (m *mockBlockchain) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
+	for _, h := range m.headers {
+		if h.Hash == hash {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+func (m *mockBlockchain) GetHeaderByNumber(n uint64) (*types.Header, bool) {
+	if n >= uint64(len(m.headers)) {
+		return nil, false
+	}
+	return m.headers[n], true
+}
+
+func (m *mockBlockchain) WriteBlocks(blocks []*types.Block) error {
+	for _, b := range blocks {
+		m.headers = append(m.headers, b.Header)
+	}
+	return nil
+}
+
+func newTestClique(t *testing.T, pool *testerAccountPool, signers []string, epoch uint64) (*Clique, *mockBlockchain) {
+	genesis := &types.Header{
+		ExtraData:  BuildExtra(nil, pool.addresses(signers)),
+		Sha3Uncles: types.EmptyUncleHash,
+	}
+	genesis.ComputeHash()
+
+	snapshots, err := lru.New(inmemorySnapshots)
+	assert.NoError(t, err)
+
+	chain := &mockBlockchain{headers: []*types.Header{genesis}}
+	c := &Clique{
+		config:     &consensus.Config{},
+		period:     1,
+		epoch:      epoch,
+		blockchain: chain,
+		snapshots:  snapshots,
+	}
+	return c, chain
+}
+
+// nextHeader returns a valid header after the parent signed by the signer
+func nextHeader(c *Clique, pool *testerAccountPool, parent *types.Header, signer string) *types.Header {
+	snap, err := c.getSnapshot(parent)
+	if err != nil {
+		panic(err)
+	}
+
+	header := &types.Header{
+		ParentHash: parent.Hash,
+		Number:     parent.Number + 1,
+		Timestamp:  parent.Timestamp + c.period,
+		Sha3Uncles: types.EmptyUncleHash,
+		Difficulty: diffNoTurn,
+		ExtraData:  BuildExtra(nil, nil),
+	}
+	if header.Number%c.epoch == 0 {
+		header.ExtraData = BuildExtra(nil, snap.Signers)
+	}
+	if snap.InTurn(header.Number, pool.address(signer)) {
+		header.Difficulty = diffInTurn
+	}
+	return header
+}
+
+func TestVerifyHeader(t *testing.T) {
+	cases := []struct {
+		name   string
+		signer string
+		hook   func(h *types.Header)
+		err    error
+	}{
+		{
+			name:   "valid",
+			signer: "B",
+		},
+		{
+			name:   "future block",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.Timestamp = uint64(time.Now().Add(time.Hour).Unix())
+			},
+			err: consensus.ErrFutureTimestamp,
+		},
+		{
+			name:   "invalid vote",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.Nonce = types.Nonce{0x1}
+			},
+			err: errInvalidVote,
+		},
+		{
+			name:   "extra signers",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.ExtraData = BuildExtra(nil, []types.Address{{0x1}})
+			},
+			err: errExtraSigners,
+		},
+		{
+			name:   "missing signature",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.ExtraData = h.ExtraData[:ExtraVanity]
+			},
+			err: errMissingSignature,
+		},
+		{
+			name:   "non zero mix digest",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.MixHash = types.Hash{0x1}
+			},
+			err: errInvalidMixDigest,
+		},
+		{
+			name:   "invalid difficulty",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.Difficulty = 3
+			},
+			err: errInvalidDifficulty,
+		},
+		{
+			name:   "wrong difficulty",
+			signer: "B",
+			hook: func(h *types.Header) {
+				// swap the in turn and out of turn difficulties
+				h.Difficulty = diffInTurn + diffNoTurn - h.Difficulty
+			},
+			err: errWrongDifficulty,
+		},
+		{
+			name:   "timestamp before the period",
+			signer: "B",
+			hook: func(h *types.Header) {
+				h.Timestamp = 0
+			},
+			err: errInvalidTimestamp,
+		},
+		{
+			name:   "unauthorized signer",
+			signer: "C",
+			err:    errUnauthorizedSigner,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool := newTesterAccountPool()
+			pool.key("A")
+			pool.key("B")
+
+			clique, chain := newTestClique(t, pool, []string{"A", "B"}, defaultEpoch)
+
+			parent := chain.Header()
+			header := nextHeader(clique, pool, parent, c.signer)
+			if c.hook != nil {
+				c.hook(header)
+			}
+			if len(header.ExtraData) >= ExtraVanity+ExtraSeal {
+				header = pool.sign(c.signer, header)
+			}
+
+			err := clique.VerifyHeader(parent, header)
+			if c.err != nil {
+				assert.Equal(t, c.err, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestVerifyHeader_Chain(t *testing.T) {
+	pool := newTesterAccountPool()
+	signers := []string{"A", "B", "C"}
+	for _, name := range signers {
+		pool.key(name)
+	}
+
+	clique, chain := newTestClique(t, pool, signers, 4)
+
+	snap, err := clique.getSnapshot(chain.Header())
+	assert.NoError(t, err)
+
+	// the signers seal the blocks in turn and the checkpoints
+	// include the list of signers
+	for i := 0; i < 10; i++ {
+		parent := chain.Header()
+
+		var signer string
+		for _, name := range signers {
+			if snap.InTurn(parent.Number+1, pool.address(name)) {
+				signer = name
+			}
+		}
+
+		header := pool.sign(signer, nextHeader(clique, pool, parent, signer))
+		assert.NoError(t, clique.VerifyHeader(parent, header))
+		assert.NoError(t, chain.WriteBlocks([]*types.Block{{Header: header}}))
+	}
+
+	warm := map[uint64]*Snapshot{}
+	for _, h := range chain.headers {
+		warm[h.Number], err = clique.getSnapshot(h)
+		assert.NoError(t, err)
+	}
+
+	// a new instance rebuilds the snapshot from the latest checkpoint
+	snapshots, err := lru.New(inmemorySnapshots)
+	assert.NoError(t, err)
+	clique.snapshots = snapshots
+
+	snap, err = clique.getSnapshot(chain.Header())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), snap.Number)
+	assert.Len(t, snap.Signers, 3)
+	assert.Len(t, snap.Recents, 2)
+
+	// the recent signers of the checkpoints are the same as with the cache
+	for _, h := range chain.headers {
+		snapshots, err := lru.New(inmemorySnapshots)
+		assert.NoError(t, err)
+		clique.snapshots = snapshots
+
+		snap, err := clique.getSnapshot(h)
+		assert.NoError(t, err)
+		assert.Equal(t, warm[h.Number].Recents, snap.Recents, "block %d", h.Number)
+	}
+}
+
+func TestVerifyHeader_CheckpointSigners(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.key("A")
+
+	clique, chain := newTestClique(t, pool, []string{"A"}, 1)
+
+	parent := chain.Header()
+	header := nextHeader(clique, pool, parent, "A")
+	header.ExtraData = BuildExtra(nil, pool.addresses([]string{"A", "B"}))
+
+	err := clique.VerifyHeader(parent, pool.sign("A", header))
+	assert.Equal(t, errMismatchingCheckpointSigners, err)
+}
+
+func TestWriteSeal(t *testing.T) {
+	pool := newTesterAccountPool()
+
+	header := pool.sign("A", &types.Header{
+		Number:    1,
+		ExtraData: BuildExtra([]byte{0x1, 0x2}, nil),
+	})
+
+	signer, err := ecrecover(header)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.address("A"), signer)
+
+	// the vanity is kept and the seal is not part of the signed hash
+	assert.Equal(t, []byte{0x1, 0x2}, header.ExtraData[:2])
+	unsigned := header.Copy()
+	copy(unsigned.ExtraData[len(unsigned.ExtraData)-ExtraSeal:], make([]byte, ExtraSeal))
+	assert.Equal(t, sealHash(unsigned), sealHash(header))
+
+	_, err = writeSeal(pool.key("A"), &types.Header{ExtraData: []byte{}})
+	assert.Error(t, err)
+}
+
+func TestBlockProposer(t *testing.T) {
+	pool := newTesterAccountPool()
+
+	c := &Clique{signerAddr: pool.address("B")}
+
+	// the rewards of a sealed block go to its signer
+	header := pool.sign("A", &types.Header{
+		Number:    1,
+		ExtraData: BuildExtra(nil, nil),
+	})
+	proposer, err := c.blockProposer(header)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.address("A"), proposer)
+
+	// the block built by the node is not sealed yet
+	proposer, err = c.blockProposer(&types.Header{Number: 1, ExtraData: BuildExtra(nil, nil)})
+	assert.NoError(t, err)
+	assert.Equal(t, pool.address("B"), proposer)
+}
+
+func TestOperator_NextProposal(t *testing.T) {
+	pool := newTesterAccountPool()
+
+	clique := &Clique{signerAddr: pool.address("A")}
+	o := newOperator(clique)
+
+	snap := newSnapshot(0, types.Hash{}, pool.addresses([]string{"A", "B"}))
+
+	// a proposal for an existing signer to be added is settled
+	o.proposals[pool.address("B")] = true
+	_, _, ok := o.nextProposal(snap)
+	assert.False(t, ok)
+	assert.Len(t, o.proposals, 0)
+
+	o.proposals[pool.address("C")] = true
+	addr, auth, ok := o.nextProposal(snap)
+	assert.True(t, ok)
+	assert.True(t, auth)
+	assert.Equal(t, pool.address("C"), addr)
+
+	// the signer does not vote twice for the same address
+	snap.Votes = append(snap.Votes, &Vote{Signer: pool.address("A"), Address: pool.address("C"), Authorize: true})
+	_, _, ok = o.nextProposal(snap)
+	assert.False(t, ok)
+	assert.Len(t, o.proposals, 1)
+}
@@ -0,0 +1,156 @@
+package clique
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/golang/protobuf/ptypes/empty"
+)
+
+// operator is the grpc service to inspect the signers and to propose votes
+type operator struct {
+	clique *Clique
+
+	// proposals are the votes the node includes in the blocks it seals
+	proposalsLock sync.Mutex
+	proposals     map[types.Address]bool
+
+	proto.UnimplementedCliqueOperatorServer
+}
+
+func newOperator(c *Clique) *operator {
+	return &operator{
+		clique:    c,
+		proposals: map[types.Address]bool{},
+	}
+}
+
+// nextProposal returns a vote to include in the next block. The proposals that
+// are already settled are removed and the ones the signer voted for are skipped
+func (o *operator) nextProposal(snap *Snapshot) (types.Address, bool, bool) {
+	o.proposalsLock.Lock()
+	defer o.proposalsLock.Unlock()
+
+	for _, addr := range o.sortedProposals() {
+		authorize := o.proposals[addr]
+		if !snap.validVote(addr, authorize) {
+			delete(o.proposals, addr)
+			continue
+		}
+		voted := false
+		for _, v := range snap.Votes {
+			if v.Signer == o.clique.signerAddr && v.Address == addr {
+				voted = true
+				break
+			}
+		}
+		if !voted {
+			return addr, authorize, true
+		}
+	}
+	return types.Address{}, false, false
+}
+
+func (o *operator) sortedProposals() []types.Address {
+	addrs := []types.Address{}
+	for addr := range o.proposals {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
+
+// Status implements the CliqueOperator service
+func (o *operator) Status(ctx context.Context, req *empty.Empty) (*proto.CliqueStatusResp, error) {
+	snap, err := o.clique.getSnapshot(o.clique.blockchain.Header())
+	if err != nil {
+		return nil, err
+	}
+	resp := &proto.CliqueStatusResp{
+		Key:    o.clique.signerAddr.String(),
+		Signer: snap.IsSigner(o.clique.signerAddr),
+	}
+	return resp, nil
+}
+
+// GetSnapshot implements the CliqueOperator service
+func (o *operator) GetSnapshot(ctx context.Context, req *proto.CliqueSnapshotReq) (*proto.CliqueSnapshot, error) {
+	header := o.clique.blockchain.Header()
+	if !req.Latest {
+		var ok bool
+		if header, ok = o.clique.blockchain.GetHeaderByNumber(req.Number); !ok {
+			return nil, fmt.Errorf("header %d not found", req.Number)
+		}
+	}
+	snap, err := o.clique.getSnapshot(header)
+	if err != nil {
+		return nil, err
+	}
+	return snap.ToProto(), nil
+}
+
+// Propose implements the CliqueOperator service
+func (o *operator) Propose(ctx context.Context, req *proto.CliqueProposal) (*empty.Empty, error) {
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(req.Address)); err != nil {
+		return nil, err
+	}
+
+	snap, err := o.clique.getSnapshot(o.clique.blockchain.Header())
+	if err != nil {
+		return nil, err
+	}
+	if !snap.validVote(addr, req.Auth) {
+		if req.Auth {
+			return nil, fmt.Errorf("is already a signer")
+		}
+		return nil, fmt.Errorf("is not a signer")
+	}
+
+	o.proposalsLock.Lock()
+	o.proposals[addr] = req.Auth
+	o.proposalsLock.Unlock()
+
+	return &empty.Empty{}, nil
+}
+
+// Discard implements the CliqueOperator service
+func (o *operator) Discard(ctx context.Context, req *proto.CliqueProposal) (*empty.Empty, error) {
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(req.Address)); err != nil {
+		return nil, err
+	}
+
+	o.proposalsLock.Lock()
+	defer o.proposalsLock.Unlock()
+
+	if _, ok := o.proposals[addr]; !ok {
+		return nil, fmt.Errorf("no proposal for %s", addr)
+	}
+	delete(o.proposals, addr)
+	return &empty.Empty{}, nil
+}
+
+// Proposals implements the CliqueOperator service
+func (o *operator) Proposals(ctx context.Context, req *empty.Empty) (*proto.CliqueProposalsResp, error) {
+	o.proposalsLock.Lock()
+	defer o.proposalsLock.Unlock()
+
+	resp := &proto.CliqueProposalsResp{
+		Proposals: []*proto.CliqueProposal{},
+	}
+	for _, addr := range o.sortedProposals() {
+		resp.Proposals = append(resp.Proposals, &proto.CliqueProposal{
+			Address: addr.String(),
+			Auth:    o.proposals[addr],
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,671 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.12.0
+// source: consensus/clique/proto/operator.proto
+
+package proto
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type CliqueStatusResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// key is the address of the signer key of the node
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// signer is set if the key is an authorized signer at the head
+	Signer bool `protobuf:"varint,2,opt,name=signer,proto3" json:"signer,omitempty"`
+}
+
+func (x *CliqueStatusResp) Reset() {
+	*x = CliqueStatusResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueStatusResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueStatusResp) ProtoMessage() {}
+
+func (x *CliqueStatusResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueStatusResp.ProtoReflect.Descriptor instead.
+func (*CliqueStatusResp) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CliqueStatusResp) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CliqueStatusResp) GetSigner() bool {
+	if x != nil {
+		return x.Signer
+	}
+	return false
+}
+
+type CliqueSnapshotReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Latest bool   `protobuf:"varint,1,opt,name=latest,proto3" json:"latest,omitempty"`
+	Number uint64 `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (x *CliqueSnapshotReq) Reset() {
+	*x = CliqueSnapshotReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueSnapshotReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueSnapshotReq) ProtoMessage() {}
+
+func (x *CliqueSnapshotReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueSnapshotReq.ProtoReflect.Descriptor instead.
+func (*CliqueSnapshotReq) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CliqueSnapshotReq) GetLatest() bool {
+	if x != nil {
+		return x.Latest
+	}
+	return false
+}
+
+func (x *CliqueSnapshotReq) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type CliqueSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number  uint64                   `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Hash    string                   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Signers []string                 `protobuf:"bytes,3,rep,name=signers,proto3" json:"signers,omitempty"`
+	Votes   []*CliqueSnapshot_Vote   `protobuf:"bytes,4,rep,name=votes,proto3" json:"votes,omitempty"`
+	Recents []*CliqueSnapshot_Recent `protobuf:"bytes,5,rep,name=recents,proto3" json:"recents,omitempty"`
+}
+
+func (x *CliqueSnapshot) Reset() {
+	*x = CliqueSnapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueSnapshot) ProtoMessage() {}
+
+func (x *CliqueSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueSnapshot.ProtoReflect.Descriptor instead.
+func (*CliqueSnapshot) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CliqueSnapshot) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *CliqueSnapshot) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *CliqueSnapshot) GetSigners() []string {
+	if x != nil {
+		return x.Signers
+	}
+	return nil
+}
+
+func (x *CliqueSnapshot) GetVotes() []*CliqueSnapshot_Vote {
+	if x != nil {
+		return x.Votes
+	}
+	return nil
+}
+
+func (x *CliqueSnapshot) GetRecents() []*CliqueSnapshot_Recent {
+	if x != nil {
+		return x.Recents
+	}
+	return nil
+}
+
+type CliqueProposalsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Proposals []*CliqueProposal `protobuf:"bytes,1,rep,name=proposals,proto3" json:"proposals,omitempty"`
+}
+
+func (x *CliqueProposalsResp) Reset() {
+	*x = CliqueProposalsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueProposalsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueProposalsResp) ProtoMessage() {}
+
+func (x *CliqueProposalsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueProposalsResp.ProtoReflect.Descriptor instead.
+func (*CliqueProposalsResp) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CliqueProposalsResp) GetProposals() []*CliqueProposal {
+	if x != nil {
+		return x.Proposals
+	}
+	return nil
+}
+
+type CliqueProposal struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Auth    bool   `protobuf:"varint,2,opt,name=auth,proto3" json:"auth,omitempty"`
+}
+
+func (x *CliqueProposal) Reset() {
+	*x = CliqueProposal{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueProposal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueProposal) ProtoMessage() {}
+
+func (x *CliqueProposal) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueProposal.ProtoReflect.Descriptor instead.
+func (*CliqueProposal) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CliqueProposal) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CliqueProposal) GetAuth() bool {
+	if x != nil {
+		return x.Auth
+	}
+	return false
+}
+
+type CliqueSnapshot_Vote struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Signer  string `protobuf:"bytes,1,opt,name=signer,proto3" json:"signer,omitempty"`
+	Block   uint64 `protobuf:"varint,2,opt,name=block,proto3" json:"block,omitempty"`
+	Address string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Auth    bool   `protobuf:"varint,4,opt,name=auth,proto3" json:"auth,omitempty"`
+}
+
+func (x *CliqueSnapshot_Vote) Reset() {
+	*x = CliqueSnapshot_Vote{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueSnapshot_Vote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueSnapshot_Vote) ProtoMessage() {}
+
+func (x *CliqueSnapshot_Vote) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueSnapshot_Vote.ProtoReflect.Descriptor instead.
+func (*CliqueSnapshot_Vote) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *CliqueSnapshot_Vote) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+func (x *CliqueSnapshot_Vote) GetBlock() uint64 {
+	if x != nil {
+		return x.Block
+	}
+	return 0
+}
+
+func (x *CliqueSnapshot_Vote) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CliqueSnapshot_Vote) GetAuth() bool {
+	if x != nil {
+		return x.Auth
+	}
+	return false
+}
+
+type CliqueSnapshot_Recent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number uint64 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Signer string `protobuf:"bytes,2,opt,name=signer,proto3" json:"signer,omitempty"`
+}
+
+func (x *CliqueSnapshot_Recent) Reset() {
+	*x = CliqueSnapshot_Recent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_clique_proto_operator_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CliqueSnapshot_Recent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CliqueSnapshot_Recent) ProtoMessage() {}
+
+func (x *CliqueSnapshot_Recent) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_clique_proto_operator_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CliqueSnapshot_Recent.ProtoReflect.Descriptor instead.
+func (*CliqueSnapshot_Recent) Descriptor() ([]byte, []int) {
+	return file_consensus_clique_proto_operator_proto_rawDescGZIP(), []int{2, 1}
+}
+
+func (x *CliqueSnapshot_Recent) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *CliqueSnapshot_Recent) GetSigner() string {
+	if x != nil {
+		return x.Signer
+	}
+	return ""
+}
+
+var File_consensus_clique_proto_operator_proto protoreflect.FileDescriptor
+
+var file_consensus_clique_proto_operator_proto_rawDesc = []byte{
+	0x0a, 0x25, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x2f, 0x63, 0x6c, 0x69, 0x71,
+	0x75, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70,
+	0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3c, 0x0a, 0x10, 0x43, 0x6c, 0x69, 0x71,
+	0x75, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x22, 0x43, 0x0a, 0x11, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x6c,
+	0x61, 0x74, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x61, 0x74,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0xd8, 0x02, 0x0a, 0x0e,
+	0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x69,
+	0x67, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x69, 0x67,
+	0x6e, 0x65, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x52, 0x05, 0x76, 0x6f,
+	0x74, 0x65, 0x73, 0x12, 0x33, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x52, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x52,
+	0x07, 0x72, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x73, 0x1a, 0x62, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18,
+	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x1a, 0x38, 0x0a, 0x06,
+	0x52, 0x65, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x22, 0x47, 0x0a, 0x13, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x30, 0x0a,
+	0x09, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x61, 0x6c, 0x52, 0x09, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x22,
+	0x3e, 0x0a, 0x0e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61,
+	0x6c, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61,
+	0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x32,
+	0xae, 0x02, 0x0a, 0x0e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74,
+	0x6f, 0x72, 0x12, 0x38, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x12, 0x15, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c,
+	0x69, 0x71, 0x75, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x35, 0x0a, 0x07,
+	0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69,
+	0x71, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x35, 0x0a, 0x07, 0x44, 0x69, 0x73, 0x63, 0x61, 0x72, 0x64, 0x12, 0x12,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73,
+	0x61, 0x6c, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3c, 0x0a, 0x09, 0x50, 0x72,
+	0x6f, 0x70, 0x6f, 0x73, 0x61, 0x6c, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
+	0x17, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x61, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x36, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x6c, 0x69, 0x71, 0x75, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x42, 0x19, 0x5a, 0x17, 0x2f, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x2f, 0x63,
+	0x6c, 0x69, 0x71, 0x75, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_consensus_clique_proto_operator_proto_rawDescOnce sync.Once
+	file_consensus_clique_proto_operator_proto_rawDescData = file_consensus_clique_proto_operator_proto_rawDesc
+)
+
+func file_consensus_clique_proto_operator_proto_rawDescGZIP() []byte {
+	file_consensus_clique_proto_operator_proto_rawDescOnce.Do(func() {
+		file_consensus_clique_proto_operator_proto_rawDescData = protoimpl.X.CompressGZIP(file_consensus_clique_proto_operator_proto_rawDescData)
+	})
+	return file_consensus_clique_proto_operator_proto_rawDescData
+}
+
+var file_consensus_clique_proto_operator_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_consensus_clique_proto_operator_proto_goTypes = []interface{}{
+	(*CliqueStatusResp)(nil),      // 0: v1.CliqueStatusResp
+	(*CliqueSnapshotReq)(nil),     // 1: v1.CliqueSnapshotReq
+	(*CliqueSnapshot)(nil),        // 2: v1.CliqueSnapshot
+	(*CliqueProposalsResp)(nil),   // 3: v1.CliqueProposalsResp
+	(*CliqueProposal)(nil),        // 4: v1.CliqueProposal
+	(*CliqueSnapshot_Vote)(nil),   // 5: v1.CliqueSnapshot.Vote
+	(*CliqueSnapshot_Recent)(nil), // 6: v1.CliqueSnapshot.Recent
+	(*empty.Empty)(nil),           // 7: google.protobuf.Empty
+}
+var file_consensus_clique_proto_operator_proto_depIdxs = []int32{
+	5, // 0: v1.CliqueSnapshot.votes:type_name -> v1.CliqueSnapshot.Vote
+	6, // 1: v1.CliqueSnapshot.recents:type_name -> v1.CliqueSnapshot.Recent
+	4, // 2: v1.CliqueProposalsResp.proposals:type_name -> v1.CliqueProposal
+	1, // 3: v1.CliqueOperator.GetSnapshot:input_type -> v1.CliqueSnapshotReq
+	4, // 4: v1.CliqueOperator.Propose:input_type -> v1.CliqueProposal
+	4, // 5: v1.CliqueOperator.Discard:input_type -> v1.CliqueProposal
+	7, // 6: v1.CliqueOperator.Proposals:input_type -> google.protobuf.Empty
+	7, // 7: v1.CliqueOperator.Status:input_type -> google.protobuf.Empty
+	2, // 8: v1.CliqueOperator.GetSnapshot:output_type -> v1.CliqueSnapshot
+	7, // 9: v1.CliqueOperator.Propose:output_type -> google.protobuf.Empty
+	7, // 10: v1.CliqueOperator.Discard:output_type -> google.protobuf.Empty
+	3, // 11: v1.CliqueOperator.Proposals:output_type -> v1.CliqueProposalsResp
+	0, // 12: v1.CliqueOperator.Status:output_type -> v1.CliqueStatusResp
+	8, // [8:13] is the sub-list for method output_type
+	3, // [3:8] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_consensus_clique_proto_operator_proto_init() }
+func file_consensus_clique_proto_operator_proto_init() {
+	if File_consensus_clique_proto_operator_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_consensus_clique_proto_operator_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueStatusResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueSnapshotReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueSnapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueProposalsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueProposal); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueSnapshot_Vote); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_clique_proto_operator_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CliqueSnapshot_Recent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_consensus_clique_proto_operator_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_consensus_clique_proto_operator_proto_goTypes,
+		DependencyIndexes: file_consensus_clique_proto_operator_proto_depIdxs,
+		MessageInfos:      file_consensus_clique_proto_operator_proto_msgTypes,
+	}.Build()
+	File_consensus_clique_proto_operator_proto = out.File
+	file_consensus_clique_proto_operator_proto_rawDesc = nil
+	file_consensus_clique_proto_operator_proto_goTypes = nil
+	file_consensus_clique_proto_operator_proto_depIdxs = nil
+}
@@ -0,0 +1,246 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CliqueOperatorClient is the client API for CliqueOperator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CliqueOperatorClient interface {
+	GetSnapshot(ctx context.Context, in *CliqueSnapshotReq, opts ...grpc.CallOption) (*CliqueSnapshot, error)
+	Propose(ctx context.Context, in *CliqueProposal, opts ...grpc.CallOption) (*empty.Empty, error)
+	Discard(ctx context.Context, in *CliqueProposal, opts ...grpc.CallOption) (*empty.Empty, error)
+	Proposals(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CliqueProposalsResp, error)
+	Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CliqueStatusResp, error)
+}
+
+type cliqueOperatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCliqueOperatorClient(cc grpc.ClientConnInterface) CliqueOperatorClient {
+	return &cliqueOperatorClient{cc}
+}
+
+func (c *cliqueOperatorClient) GetSnapshot(ctx context.Context, in *CliqueSnapshotReq, opts ...grpc.CallOption) (*CliqueSnapshot, error) {
+	out := new(CliqueSnapshot)
+	err := c.cc.Invoke(ctx, "/v1.CliqueOperator/GetSnapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliqueOperatorClient) Propose(ctx context.Context, in *CliqueProposal, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/v1.CliqueOperator/Propose", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliqueOperatorClient) Discard(ctx context.Context, in *CliqueProposal, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/v1.CliqueOperator/Discard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliqueOperatorClient) Proposals(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CliqueProposalsResp, error) {
+	out := new(CliqueProposalsResp)
+	err := c.cc.Invoke(ctx, "/v1.CliqueOperator/Proposals", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cliqueOperatorClient) Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CliqueStatusResp, error) {
+	out := new(CliqueStatusResp)
+	err := c.cc.Invoke(ctx, "/v1.CliqueOperator/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CliqueOperatorServer is the server API for CliqueOperator service.
+// All implementations must embed UnimplementedCliqueOperatorServer
+// for forward compatibility
+type CliqueOperatorServer interface {
+	GetSnapshot(context.Context, *CliqueSnapshotReq) (*CliqueSnapshot, error)
+	Propose(context.Context, *CliqueProposal) (*empty.Empty, error)
+	Discard(context.Context, *CliqueProposal) (*empty.Empty, error)
+	Proposals(context.Context, *empty.Empty) (*CliqueProposalsResp, error)
+	Status(context.Context, *empty.Empty) (*CliqueStatusResp, error)
+	mustEmbedUnimplementedCliqueOperatorServer()
+}
+
+// UnimplementedCliqueOperatorServer must be embedded to have forward compatible implementations.
+type UnimplementedCliqueOperatorServer struct {
+}
+
+func (UnimplementedCliqueOperatorServer) GetSnapshot(context.Context, *CliqueSnapshotReq) (*CliqueSnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedCliqueOperatorServer) Propose(context.Context, *CliqueProposal) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Propose not implemented")
+}
+func (UnimplementedCliqueOperatorServer) Discard(context.Context, *CliqueProposal) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Discard not implemented")
+}
+func (UnimplementedCliqueOperatorServer) Proposals(context.Context, *empty.Empty) (*CliqueProposalsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Proposals not implemented")
+}
+func (UnimplementedCliqueOperatorServer) Status(context.Context, *empty.Empty) (*CliqueStatusResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedCliqueOperatorServer) mustEmbedUnimplementedCliqueOperatorServer() {}
+
+// UnsafeCliqueOperatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CliqueOperatorServer will
+// result in compilation errors.
+type UnsafeCliqueOperatorServer interface {
+	mustEmbedUnimplementedCliqueOperatorServer()
+}
+
+func RegisterCliqueOperatorServer(s grpc.ServiceRegistrar, srv CliqueOperatorServer) {
+	s.RegisterService(&CliqueOperator_ServiceDesc, srv)
+}
+
+func _CliqueOperator_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CliqueSnapshotReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliqueOperatorServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.CliqueOperator/GetSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliqueOperatorServer).GetSnapshot(ctx, req.(*CliqueSnapshotReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliqueOperator_Propose_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CliqueProposal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliqueOperatorServer).Propose(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.CliqueOperator/Propose",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliqueOperatorServer).Propose(ctx, req.(*CliqueProposal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliqueOperator_Discard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CliqueProposal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliqueOperatorServer).Discard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.CliqueOperator/Discard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliqueOperatorServer).Discard(ctx, req.(*CliqueProposal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliqueOperator_Proposals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliqueOperatorServer).Proposals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.CliqueOperator/Proposals",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliqueOperatorServer).Proposals(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CliqueOperator_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CliqueOperatorServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.CliqueOperator/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CliqueOperatorServer).Status(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CliqueOperator_ServiceDesc is the grpc.ServiceDesc for CliqueOperator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CliqueOperator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.CliqueOperator",
+	HandlerType: (*CliqueOperatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _CliqueOperator_GetSnapshot_Handler,
+		},
+		{
+			MethodName: "Propose",
+			Handler:    _CliqueOperator_Propose_Handler,
+		},
+		{
+			MethodName: "Discard",
+			Handler:    _CliqueOperator_Discard_Handler,
+		},
+		{
+			MethodName: "Proposals",
+			Handler:    _CliqueOperator_Proposals_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _CliqueOperator_Status_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "consensus/clique/proto/operator.proto",
+}
@@ -0,0 +1,125 @@
+package clique
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/helper/keccak"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/umbracle/fastrlp"
+)
+
+const (
+	// ExtraVanity is the number of bytes reserved at the start of the extra data
+	ExtraVanity = 32
+
+	// ExtraSeal is the number of bytes of the signature at the end of the extra data
+	ExtraSeal = 65
+)
+
+var (
+	// Magic nonce number to vote on adding a new signer
+	nonceAuthVote = types.Nonce{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	// Magic nonce number to vote on removing a signer
+	nonceDropVote = types.Nonce{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+)
+
+const (
+	// difficulty of a block signed by the in turn signer
+	diffInTurn = 2
+
+	// difficulty of a block signed out of turn
+	diffNoTurn = 1
+)
+
+// BuildExtra returns the extra data of a checkpoint block with the list of signers
+func BuildExtra(vanity []byte, signers []types.Address) []byte {
+	extra := make([]byte, ExtraVanity, ExtraVanity+len(signers)*types.AddressLength+ExtraSeal)
+	copy(extra, vanity)
+	for _, signer := range signers {
+		extra = append(extra, signer.Bytes()...)
+	}
+	return append(extra, make([]byte, ExtraSeal)...)
+}
+
+// extraSigners returns the signers in the extra data of a checkpoint block
+func extraSigners(h *types.Header) ([]types.Address, error) {
+	if len(h.ExtraData) < ExtraVanity+ExtraSeal {
+		return nil, fmt.Errorf("extra data too short")
+	}
+	raw := h.ExtraData[ExtraVanity : len(h.ExtraData)-ExtraSeal]
+	if len(raw)%types.AddressLength != 0 {
+		return nil, fmt.Errorf("invalid signers list in the extra data")
+	}
+	signers := make([]types.Address, len(raw)/types.AddressLength)
+	for i := range signers {
+		copy(signers[i][:], raw[i*types.AddressLength:])
+	}
+	return signers, nil
+}
+
+// sealHash returns the hash signed by the signer of the block, the hash
+// of the header without the signature at the end of the extra data
+func sealHash(h *types.Header) []byte {
+	arena := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(arena)
+
+	extra := h.ExtraData
+	if len(extra) >= ExtraSeal {
+		extra = extra[:len(extra)-ExtraSeal]
+	}
+
+	vv := arena.NewArray()
+	vv.Set(arena.NewBytes(h.ParentHash.Bytes()))
+	vv.Set(arena.NewBytes(h.Sha3Uncles.Bytes()))
+	vv.Set(arena.NewBytes(h.Miner.Bytes()))
+	vv.Set(arena.NewBytes(h.StateRoot.Bytes()))
+	vv.Set(arena.NewBytes(h.TxRoot.Bytes()))
+	vv.Set(arena.NewBytes(h.ReceiptsRoot.Bytes()))
+	vv.Set(arena.NewCopyBytes(h.LogsBloom[:]))
+	vv.Set(arena.NewUint(h.Difficulty))
+	vv.Set(arena.NewUint(h.Number))
+	vv.Set(arena.NewUint(h.GasLimit))
+	vv.Set(arena.NewUint(h.GasUsed))
+	vv.Set(arena.NewUint(h.Timestamp))
+	vv.Set(arena.NewCopyBytes(extra))
+	vv.Set(arena.NewBytes(h.MixHash.Bytes()))
+	vv.Set(arena.NewCopyBytes(h.Nonce[:]))
+	if h.BaseFee != nil {
+		vv.Set(arena.NewBigInt(h.BaseFee))
+	}
+
+	return keccak.Keccak256Rlp(nil, vv)
+}
+
+// ecrecover returns the address of the signer of the header
+func ecrecover(h *types.Header) (types.Address, error) {
+	if len(h.ExtraData) < ExtraSeal {
+		return types.Address{}, fmt.Errorf("extra data does not include the seal")
+	}
+	seal := h.ExtraData[len(h.ExtraData)-ExtraSeal:]
+
+	pub, err := crypto.RecoverPubkey(seal, sealHash(h))
+	if err != nil {
+		return types.Address{}, err
+	}
+	return crypto.PubKeyToAddress(pub), nil
+}
+
+// writeSeal signs the header and writes the signature in the extra data
+func writeSeal(prv *ecdsa.PrivateKey, h *types.Header) (*types.Header, error) {
+	if len(h.ExtraData) < ExtraVanity+ExtraSeal {
+		return nil, fmt.Errorf("extra data too short")
+	}
+	h = h.Copy()
+
+	seal, err := crypto.Sign(prv, sealHash(h))
+	if err != nil {
+		return nil, err
+	}
+	copy(h.ExtraData[len(h.ExtraData)-ExtraSeal:], seal)
+	h.ComputeHash()
+	return h, nil
+}
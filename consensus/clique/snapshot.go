@@ -0,0 +1,321 @@
+package clique
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/0xPolygon/minimal/consensus/clique/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// Vote is a vote of a signer to add or remove an address from the signers
+type Vote struct {
+	Signer    types.Address
+	Block     uint64
+	Address   types.Address
+	Authorize bool
+}
+
+// Tally is the count of the votes for an address
+type Tally struct {
+	Authorize bool
+	Votes     int
+}
+
+// Snapshot is the state of the authorization voting at a given block
+type Snapshot struct {
+	// block number and hash of the snapshot
+	Number uint64
+	Hash   types.Hash
+
+	// Signers are the authorized signers sorted by address
+	Signers []types.Address
+
+	// Recents are the signers of the recent blocks by block number,
+	// they cannot sign again until they leave the list
+	Recents map[uint64]types.Address
+
+	// Votes are the votes casted in chronological order
+	Votes []*Vote
+
+	// Tally are the current vote counts per address
+	Tally map[types.Address]Tally
+}
+
+func newSnapshot(number uint64, hash types.Hash, signers []types.Address) *Snapshot {
+	snap := &Snapshot{
+		Number:  number,
+		Hash:    hash,
+		Signers: append([]types.Address{}, signers...),
+		Recents: map[uint64]types.Address{},
+		Votes:   []*Vote{},
+		Tally:   map[types.Address]Tally{},
+	}
+	snap.sortSigners()
+	return snap
+}
+
+func (s *Snapshot) sortSigners() {
+	sort.Slice(s.Signers, func(i, j int) bool {
+		return bytes.Compare(s.Signers[i][:], s.Signers[j][:]) < 0
+	})
+}
+
+// Copy returns a deep copy of the snapshot
+func (s *Snapshot) Copy() *Snapshot {
+	ss := newSnapshot(s.Number, s.Hash, s.Signers)
+	for num, signer := range s.Recents {
+		ss.Recents[num] = signer
+	}
+	for _, vote := range s.Votes {
+		vv := *vote
+		ss.Votes = append(ss.Votes, &vv)
+	}
+	for addr, tally := range s.Tally {
+		ss.Tally[addr] = tally
+	}
+	return ss
+}
+
+// IsSigner returns whether the address is an authorized signer
+func (s *Snapshot) IsSigner(addr types.Address) bool {
+	return s.signerIndex(addr) != -1
+}
+
+func (s *Snapshot) signerIndex(addr types.Address) int {
+	for i, signer := range s.Signers {
+		if signer == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// recentsLimit is the number of consecutive blocks in which a signer can sign only once
+func (s *Snapshot) recentsLimit() uint64 {
+	return uint64(len(s.Signers)/2 + 1)
+}
+
+// RecentlySigned returns whether the signer signed one of the recent blocks and
+// cannot sign the block with the given number
+func (s *Snapshot) RecentlySigned(number uint64, signer types.Address) bool {
+	limit := s.recentsLimit()
+	for seen, recent := range s.Recents {
+		if recent == signer && (number < limit || seen > number-limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// InTurn returns whether it is the turn of the signer to sign the block
+func (s *Snapshot) InTurn(number uint64, signer types.Address) bool {
+	index := s.signerIndex(signer)
+	if index == -1 {
+		return false
+	}
+	return number%uint64(len(s.Signers)) == uint64(index)
+}
+
+// validVote returns whether the vote changes the signers
+func (s *Snapshot) validVote(addr types.Address, authorize bool) bool {
+	return s.IsSigner(addr) != authorize
+}
+
+func (s *Snapshot) cast(addr types.Address, authorize bool) bool {
+	if !s.validVote(addr, authorize) {
+		return false
+	}
+	tally, ok := s.Tally[addr]
+	if ok && tally.Authorize != authorize {
+		// votes in the other direction (i.e. authorize a removed signer)
+		return false
+	}
+	tally.Authorize = authorize
+	tally.Votes++
+	s.Tally[addr] = tally
+	return true
+}
+
+func (s *Snapshot) uncast(addr types.Address, authorize bool) {
+	tally, ok := s.Tally[addr]
+	if !ok || tally.Authorize != authorize {
+		return
+	}
+	if tally.Votes > 1 {
+		tally.Votes--
+		s.Tally[addr] = tally
+	} else {
+		delete(s.Tally, addr)
+	}
+}
+
+// removeVotes removes the matching votes and their tally
+func (s *Snapshot) removeVotes(h func(v *Vote) bool) {
+	for i := 0; i < len(s.Votes); i++ {
+		if vote := s.Votes[i]; h(vote) {
+			s.uncast(vote.Address, vote.Authorize)
+			s.Votes = append(s.Votes[:i], s.Votes[i+1:]...)
+			i--
+		}
+	}
+}
+
+// apply returns the snapshot after the headers, which follow the snapshot block
+func (s *Snapshot) apply(headers []*types.Header, epoch uint64) (*Snapshot, error) {
+	if len(headers) == 0 {
+		return s, nil
+	}
+	for i := range headers {
+		if i == 0 && headers[i].Number != s.Number+1 || i != 0 && headers[i].Number != headers[i-1].Number+1 {
+			return nil, errInvalidVotingChain
+		}
+	}
+
+	snap := s.Copy()
+	for _, h := range headers {
+		number := h.Number
+
+		if number%epoch == 0 {
+			// the votes are discarded on checkpoints
+			snap.Votes = []*Vote{}
+			snap.Tally = map[types.Address]Tally{}
+		}
+
+		// the oldest recent signer can sign again
+		if limit := snap.recentsLimit(); number >= limit {
+			delete(snap.Recents, number-limit)
+		}
+
+		signer, err := ecrecover(h)
+		if err != nil {
+			return nil, err
+		}
+		if !snap.IsSigner(signer) {
+			return nil, errUnauthorizedSigner
+		}
+		if snap.RecentlySigned(number, signer) {
+			return nil, errRecentlySigned
+		}
+		snap.Recents[number] = signer
+
+		// a new vote of the signer for the same address replaces the previous one
+		snap.removeVotes(func(v *Vote) bool {
+			return v.Signer == signer && v.Address == h.Miner
+		})
+
+		var authorize bool
+		switch h.Nonce {
+		case nonceAuthVote:
+			authorize = true
+		case nonceDropVote:
+			authorize = false
+		default:
+			return nil, errInvalidVote
+		}
+		if snap.cast(h.Miner, authorize) {
+			snap.Votes = append(snap.Votes, &Vote{
+				Signer:    signer,
+				Block:     number,
+				Address:   h.Miner,
+				Authorize: authorize,
+			})
+		}
+
+		// the vote passes with the majority of the signers
+		tally, ok := snap.Tally[h.Miner]
+		if !ok || tally.Votes <= len(snap.Signers)/2 {
+			continue
+		}
+		if tally.Authorize {
+			snap.Signers = append(snap.Signers, h.Miner)
+			snap.sortSigners()
+		} else {
+			index := snap.signerIndex(h.Miner)
+			snap.Signers = append(snap.Signers[:index], snap.Signers[index+1:]...)
+
+			// the recents window is smaller with one less signer
+			if limit := snap.recentsLimit(); number >= limit {
+				delete(snap.Recents, number-limit)
+			}
+
+			// the votes of the removed signer do not count anymore
+			snap.removeVotes(func(v *Vote) bool {
+				return v.Signer == h.Miner
+			})
+		}
+
+		// the votes for the address are settled
+		snap.removeVotes(func(v *Vote) bool {
+			return v.Address == h.Miner
+		})
+		delete(snap.Tally, h.Miner)
+	}
+
+	last := headers[len(headers)-1]
+	snap.Number, snap.Hash = last.Number, last.Hash
+	return snap, nil
+}
+
+// ToProto converts the snapshot to its operator representation
+func (s *Snapshot) ToProto() *proto.CliqueSnapshot {
+	resp := &proto.CliqueSnapshot{
+		Number:  s.Number,
+		Hash:    s.Hash.String(),
+		Signers: []string{},
+		Votes:   []*proto.CliqueSnapshot_Vote{},
+		Recents: []*proto.CliqueSnapshot_Recent{},
+	}
+	for _, signer := range s.Signers {
+		resp.Signers = append(resp.Signers, signer.String())
+	}
+	for _, vote := range s.Votes {
+		resp.Votes = append(resp.Votes, &proto.CliqueSnapshot_Vote{
+			Signer:  vote.Signer.String(),
+			Block:   vote.Block,
+			Address: vote.Address.String(),
+			Auth:    vote.Authorize,
+		})
+	}
+
+	numbers := []uint64{}
+	for num := range s.Recents {
+		numbers = append(numbers, num)
+	}
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+	for _, num := range numbers {
+		resp.Recents = append(resp.Recents, &proto.CliqueSnapshot_Recent{
+			Number: num,
+			Signer: s.Recents[num].String(),
+		})
+	}
+	return resp
+}
+
+func loadSnapshot(path string) (*Snapshot, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap *Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+func saveSnapshot(path string, snap *Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
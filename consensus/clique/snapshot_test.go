@@ -0,0 +1,255 @@
+package clique
+
+import (
+	"crypto/ecdsa"
+	"sort"
+	"testing"
+
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testerAccountPool struct {
+	accounts map[string]*ecdsa.PrivateKey
+}
+
+func newTesterAccountPool() *testerAccountPool {
+	return &testerAccountPool{accounts: map[string]*ecdsa.PrivateKey{}}
+}
+
+func (ap *testerAccountPool) key(name string) *ecdsa.PrivateKey {
+	if key, ok := ap.accounts[name]; ok {
+		return key
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		panic("BUG: Failed to generate crypto key")
+	}
+	ap.accounts[name] = key
+	return key
+}
+
+func (ap *testerAccountPool) address(name string) types.Address {
+	if name == "" {
+		return types.ZeroAddress
+	}
+	return crypto.PubKeyToAddress(&ap.key(name).PublicKey)
+}
+
+func (ap *testerAccountPool) addresses(names []string) []types.Address {
+	addrs := []types.Address{}
+	for _, name := range names {
+		addrs = append(addrs, ap.address(name))
+	}
+	return addrs
+}
+
+// sign seals the header with the key of the account
+func (ap *testerAccountPool) sign(name string, h *types.Header) *types.Header {
+	h, err := writeSeal(ap.key(name), h)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+type testerVote struct {
+	signer     string
+	voted      string
+	auth       bool
+	checkpoint []string
+}
+
+func TestSnapshot_Apply(t *testing.T) {
+	cases := []struct {
+		name    string
+		epoch   uint64
+		signers []string
+		votes   []testerVote
+		results []string
+		err     error
+	}{
+		{
+			name:    "single signer, no votes cast",
+			signers: []string{"A"},
+			votes:   []testerVote{{signer: "A"}},
+			results: []string{"A"},
+		},
+		{
+			name:    "single signer, voting to add two peers",
+			signers: []string{"A"},
+			votes: []testerVote{
+				{signer: "A", voted: "B", auth: true},
+				{signer: "B"},
+				{signer: "A", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		},
+		{
+			name:    "two signers, voting to add three peers",
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B", voted: "C", auth: true},
+				{signer: "A", voted: "D", auth: true},
+				{signer: "B", voted: "D", auth: true},
+				{signer: "C"},
+				{signer: "A", voted: "E", auth: true},
+				{signer: "B", voted: "E", auth: true},
+			},
+			results: []string{"A", "B", "C", "D"},
+		},
+		{
+			name:    "single signer, dropping itself",
+			signers: []string{"A"},
+			votes:   []testerVote{{signer: "A", voted: "A"}},
+			results: []string{},
+		},
+		{
+			name:    "two signers, actually needing mutual consent to drop either of them",
+			signers: []string{"A", "B"},
+			votes:   []testerVote{{signer: "A", voted: "B"}},
+			results: []string{"A", "B"},
+		},
+		{
+			name:    "two signers, drop the second one",
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "B"},
+				{signer: "B", voted: "B"},
+			},
+			results: []string{"A"},
+		},
+		{
+			name:    "three signers, two of them deciding to drop the third",
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A", voted: "C"},
+				{signer: "B", voted: "C"},
+			},
+			results: []string{"A", "B"},
+		},
+		{
+			name:    "the votes of a dropped signer are discarded",
+			signers: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{signer: "A", voted: "E", auth: true},
+				{signer: "B", voted: "C"},
+				{signer: "C", voted: "E", auth: true},
+				{signer: "D", voted: "C"},
+				{signer: "A", voted: "C"},
+				// C is dropped and only the vote of A for E counts
+				{signer: "B", voted: "E", auth: true},
+			},
+			results: []string{"A", "B", "D", "E"},
+		},
+		{
+			name:    "a signer changing its vote",
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				// the new vote of A replaces the previous one
+				{signer: "A", voted: "C"},
+				{signer: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		},
+		{
+			name:    "the votes are discarded on checkpoints",
+			epoch:   3,
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A", voted: "C", auth: true},
+				{signer: "B"},
+				{signer: "A", checkpoint: []string{"A", "B"}},
+				{signer: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		},
+		{
+			name:    "unauthorized signer",
+			signers: []string{"A"},
+			votes:   []testerVote{{signer: "B"}},
+			err:     errUnauthorizedSigner,
+		},
+		{
+			name:    "recently signed",
+			signers: []string{"A", "B"},
+			votes: []testerVote{
+				{signer: "A"},
+				{signer: "A"},
+			},
+			err: errRecentlySigned,
+		},
+		{
+			name:    "signing again after the recents window",
+			signers: []string{"A", "B", "C"},
+			votes: []testerVote{
+				{signer: "A"},
+				{signer: "B"},
+				{signer: "A"},
+			},
+			results: []string{"A", "B", "C"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pool := newTesterAccountPool()
+
+			epoch := c.epoch
+			if epoch == 0 {
+				epoch = defaultEpoch
+			}
+
+			genesis := &types.Header{ExtraData: BuildExtra(nil, pool.addresses(c.signers))}
+			genesis.ComputeHash()
+
+			headers := []*types.Header{}
+			for i, v := range c.votes {
+				h := &types.Header{
+					Number:    uint64(i + 1),
+					Miner:     pool.address(v.voted),
+					ExtraData: BuildExtra(nil, pool.addresses(v.checkpoint)),
+				}
+				if v.auth {
+					h.Nonce = nonceAuthVote
+				}
+				headers = append(headers, pool.sign(v.signer, h))
+			}
+
+			snap := newSnapshot(0, genesis.Hash, pool.addresses(c.signers))
+			res, err := snap.apply(headers, epoch)
+			if c.err != nil {
+				assert.Equal(t, c.err, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			expected := pool.addresses(c.results)
+			sort.Slice(expected, func(i, j int) bool {
+				return expected[i].String() < expected[j].String()
+			})
+			assert.Equal(t, expected, res.Signers)
+			assert.Equal(t, headers[len(headers)-1].Hash, res.Hash)
+
+			// the snapshot is not modified
+			assert.Equal(t, uint64(0), snap.Number)
+			assert.Len(t, snap.Signers, len(c.signers))
+		})
+	}
+}
+
+func TestSnapshot_InTurn(t *testing.T) {
+	pool := newTesterAccountPool()
+
+	snap := newSnapshot(0, types.Hash{}, pool.addresses([]string{"A", "B", "C"}))
+	for num := uint64(0); num < 6; num++ {
+		for i, signer := range snap.Signers {
+			assert.Equal(t, num%3 == uint64(i), snap.InTurn(num, signer))
+		}
+	}
+	assert.False(t, snap.InTurn(1, pool.address("D")))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/0xPolygon/minimal/txpool"
 	"github.com/0xPolygon/minimal/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 )
 
@@ -19,6 +20,14 @@ type Consensus interface {
 	// VerifyHeader verifies the header is correct
 	VerifyHeader(parent, header *types.Header) error
 
+	// Prepare sets the consensus fields of the header of a new block, the
+	// header has the parent hash, the number, the gas limit and the timestamp
+	Prepare(header *types.Header) error
+
+	// Seal seals the block once the txns are applied, it returns nil if the
+	// seal is aborted (i.e. another block is written or the context is done)
+	Seal(block *types.Block, ctx context.Context) (*types.Block, error)
+
 	// Start starts the consensus
 	Start() error
 
@@ -26,6 +35,14 @@ type Consensus interface {
 	Close() error
 }
 
+// Reloader is implemented by the engines that can apply the changes of some
+// options of the config without a restart
+type Reloader interface {
+	// Reload applies the options of the config, nothing is applied if an
+	// option that changed cannot be reloaded or it is not valid
+	Reload(config *Config) error
+}
+
 // Config is the configuration for the consensus
 type Config struct {
 	// Logger to be used by the backend
@@ -37,9 +54,51 @@ type Config struct {
 	// Specific configuration parameters for the backend
 	Config map[string]interface{}
 
-	// Path for the consensus protocol tos tore information
+	// Path for the consensus protocol tos tore information, each
+	// engine of the chain has its own path
 	Path string
+
+	// ForkBlock is the first block of the engine if it replaces
+	// another engine at a fork of the chain, EndBlock is the first
+	// block of the next engine or zero if there is none
+	ForkBlock uint64
+	EndBlock  uint64
+
+	// BlockGasTarget is the gas limit the proposed blocks move toward,
+	// the gas limit is not adjusted if it is zero
+	BlockGasTarget uint64
+
+	// Metrics is the registry of the consensus metrics, they are disabled if nil
+	Metrics prometheus.Registerer
+}
+
+// Active returns whether the block is in the range of blocks of the engine
+func (c *Config) Active(number uint64) bool {
+	return number >= c.ForkBlock && (c.EndBlock == 0 || number < c.EndBlock)
+}
+
+// Forks returns the forks of the chain, nil if there are no params
+func (c *Config) Forks() *chain.Forks {
+	if c.Params == nil {
+		return nil
+	}
+	return c.Params.Forks
+}
+
+// SetProposer sets the proposer of the block rewards of the executor for the
+// blocks of the engine, the blocks of the other engines keep their proposer
+func SetProposer(executor *state.Executor, config *Config, proposer func(header *types.Header) (types.Address, error)) {
+	prevProposer := executor.GetProposer
+	executor.GetProposer = func(header *types.Header) (types.Address, error) {
+		if !config.Active(header.Number) {
+			if prevProposer == nil {
+				return header.Miner, nil
+			}
+			return prevProposer(header)
+		}
+		return proposer(header)
+	}
 }
 
 // Factory is the factory function to create a discovery backend
-type Factory func(context.Context, bool, *Config, *txpool.TxPool, *network.Server, *blockchain.Blockchain, *state.Executor, *grpc.Server, hclog.Logger) (Consensus, error)
+type Factory func(context.Context, bool, *Config, *txpool.TxPool, *network.Server, *blockchain.Blockchain, *state.Executor, grpc.ServiceRegistrar, hclog.Logger) (Consensus, error)
@@ -15,7 +15,8 @@ import (
 	"google.golang.org/grpc"
 )
 
-// Dev consensus protocol seals any new transaction inmediatly
+// Dev consensus protocol seals any new transaction inmediatly, and seals
+// a block every interval seconds if the interval is set
 type Dev struct {
 	logger hclog.Logger
 
@@ -23,31 +24,58 @@ type Dev struct {
 	closeCh  chan struct{}
 
 	interval uint64
-	txpool   *txpool.TxPool
+
+	// coinbase is the miner of the blocks, it receives the
+	// fees and the block rewards of the chain params
+	coinbase types.Address
+
+	txpool  *txpool.TxPool
+	builder *consensus.Builder
 
 	blockchain *blockchain.Blockchain
 	executor   *state.Executor
 }
 
-func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv *grpc.Server, logger hclog.Logger) (consensus.Consensus, error) {
+func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv grpc.ServiceRegistrar, logger hclog.Logger) (consensus.Consensus, error) {
 	logger = logger.Named("dev")
 
 	d := &Dev{
 		logger:     logger,
-		notifyCh:   make(chan struct{}),
+		notifyCh:   make(chan struct{}, 1),
 		closeCh:    make(chan struct{}),
 		blockchain: blockchain,
 		executor:   executor,
 		txpool:     txpool,
 	}
+	d.builder = consensus.NewBuilder(d, config, executor, txpool, 100000000)
+
+	// the interval is a float64 if it is read from the genesis
+	switch v := config.Config["interval"].(type) {
+	case nil:
+	case uint64:
+		d.interval = v
+	case int:
+		d.interval = uint64(v)
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return nil, fmt.Errorf("interval expected a positive int")
+		}
+		d.interval = uint64(v)
+	default:
+		return nil, fmt.Errorf("interval expected int")
+	}
 
-	rawInterval, ok := config.Config["interval"]
-	if ok {
-		interval, ok := rawInterval.(uint64)
+	if raw, ok := config.Config["coinbase"]; ok {
+		coinbase, ok := raw.(string)
 		if !ok {
-			return nil, fmt.Errorf("interval expected int")
+			return nil, fmt.Errorf("coinbase expected string")
+		}
+		if err := d.coinbase.UnmarshalText([]byte(coinbase)); err != nil {
+			return nil, err
 		}
-		d.interval = interval
+	}
+	if config.Params != nil && config.Params.Rewards != nil && d.coinbase == types.ZeroAddress {
+		logger.Warn("no coinbase set, the fees and the block rewards go to the zero address")
 	}
 
 	// enable dev mode so that we can accept non-signed txns
@@ -62,25 +90,23 @@ func (d *Dev) Start() error {
 	return nil
 }
 
-func (d *Dev) nextNotify() chan struct{} {
+func (d *Dev) run() {
+	d.logger.Info("started", "interval", d.interval)
+
+	// the ticker seals the empty blocks if the interval is set
+	var tickCh <-chan time.Time
 	if d.interval != 0 {
-		ch := make(chan struct{})
-		go func() {
-			<-time.After(time.Duration(d.interval) * time.Second)
-			ch <- struct{}{}
-		}()
-		return ch
+		ticker := time.NewTicker(time.Duration(d.interval) * time.Second)
+		defer ticker.Stop()
+		tickCh = ticker.C
 	}
-	return d.notifyCh
-}
-
-func (d *Dev) run() {
-	d.logger.Info("started")
 
 	for {
-		// wait until there is a new txn
+		// wait until there is a new txn or the interval passes. The txns that
+		// arrive while the block is sealed are notified in the buffered channel
 		select {
-		case <-d.nextNotify():
+		case <-d.notifyCh:
+		case <-tickCh:
 		case <-d.closeCh:
 			return
 		}
@@ -94,43 +120,16 @@ func (d *Dev) run() {
 }
 
 func (d *Dev) do(parent *types.Header) error {
-	num := parent.Number
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     num + 1,
-		GasLimit:   100000000, // placeholder for now
-		Timestamp:  uint64(time.Now().Unix()),
-	}
-
-	transition, err := d.executor.BeginTxn(parent.StateRoot, header)
+	block, err := d.builder.Build(context.Background(), parent)
 	if err != nil {
 		return err
 	}
-
-	txns := []*types.Transaction{}
-	for {
-		txn, retFn := d.txpool.Pop()
-		if txn == nil {
-			break
-		}
-		if err := transition.Write(txn); err != nil {
-			retFn()
-			break
-		}
-		txns = append(txns, txn)
-	}
-
-	_, root := transition.Commit()
-
-	header.StateRoot = root
-	header.GasUsed = transition.TotalGas()
-
-	// header hash is computed inside buildBlock
-	block := consensus.BuildBlock(header, txns, transition.Receipts())
-
 	if err := d.blockchain.WriteBlocks([]*types.Block{block}); err != nil {
 		return err
 	}
+
+	// remove the txns of the block from the pool
+	d.txpool.ResetWithHeader(block.Header)
 	return nil
 }
 
@@ -140,13 +139,14 @@ func (d *Dev) VerifyHeader(parent *types.Header, header *types.Header) error {
 }
 
 func (d *Dev) Prepare(header *types.Header) error {
-	// TODO: Remove
+	// there are no consensus fields, the coinbase is the proposer
+	header.Miner = d.coinbase
 	return nil
 }
 
 func (d *Dev) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
-	// TODO: Remove
-	return nil, nil
+	// the blocks do not need a seal
+	return block, nil
 }
 
 func (d *Dev) Close() error {
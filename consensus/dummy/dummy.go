@@ -23,7 +23,7 @@ type Dummy struct {
 	executor   *state.Executor
 }
 
-func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv *grpc.Server, logger hclog.Logger) (consensus.Consensus, error) {
+func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv grpc.ServiceRegistrar, logger hclog.Logger) (consensus.Consensus, error) {
 	logger = logger.Named("dummy")
 
 	d := &Dummy{
@@ -51,6 +51,14 @@ func (d *Dummy) VerifyHeader(parent *types.Header, header *types.Header) error {
 	return nil
 }
 
+func (d *Dummy) Prepare(header *types.Header) error {
+	return nil
+}
+
+func (d *Dummy) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	return block, nil
+}
+
 func (d *Dummy) Close() error {
 	close(d.closeCh)
 	return nil
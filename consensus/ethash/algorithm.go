@@ -0,0 +1,212 @@
+package ethash
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/0xPolygon/minimal/helper/keccak"
+)
+
+const (
+	datasetInitBytes   = 1 << 30 // bytes in the dataset at genesis
+	datasetGrowthBytes = 1 << 23 // growth of the dataset per epoch
+	cacheInitBytes     = 1 << 24 // bytes in the cache at genesis
+	cacheGrowthBytes   = 1 << 17 // growth of the cache per epoch
+	epochLength        = 30000   // blocks per epoch
+	mixBytes           = 128     // width of the mix
+	hashBytes          = 64      // length of a hash in bytes
+	hashWords          = 16      // number of 32 bit words in a hash
+	datasetParents     = 256     // number of parents of each dataset item
+	cacheRounds        = 3       // number of rounds of the cache generation
+	loopAccesses       = 64      // number of accesses in the hashimoto loop
+)
+
+// cacheSize returns the size of the verification cache of the epoch, the
+// largest size below the linear growth with a prime number of rows
+func cacheSize(epoch uint64) uint64 {
+	size := cacheInitBytes + cacheGrowthBytes*epoch - hashBytes
+	for !new(big.Int).SetUint64(size / hashBytes).ProbablyPrime(1) {
+		size -= 2 * hashBytes
+	}
+	return size
+}
+
+// datasetSize returns the size of the mining dataset of the epoch, the
+// largest size below the linear growth with a prime number of rows
+func datasetSize(epoch uint64) uint64 {
+	size := datasetInitBytes + datasetGrowthBytes*epoch - mixBytes
+	for !new(big.Int).SetUint64(size / mixBytes).ProbablyPrime(1) {
+		size -= 2 * mixBytes
+	}
+	return size
+}
+
+// hasher writes into dst the keccak hash of the data, it reuses the same
+// keccak state between calls and it is not thread safe
+type hasher func(dst []byte, data []byte)
+
+func newKeccak512Hasher() hasher {
+	k := keccak.NewKeccak512()
+	return func(dst []byte, data []byte) {
+		k.Reset()
+		k.Write(data)
+		copy(dst, k.Read())
+	}
+}
+
+// seedHash returns the seed of the cache and the dataset of the epoch
+func seedHash(epoch uint64) []byte {
+	seed := make([]byte, 32)
+	for i := uint64(0); i < epoch; i++ {
+		seed = keccak.Keccak256(seed[:0], seed)
+	}
+	return seed
+}
+
+// generateCache returns the verification cache of the given size. The cache is
+// built from the sequential keccak512 hashes of the seed, which are then mixed
+// with a few rounds of the RandMemoHash algorithm
+func generateCache(size uint64, seed []byte) []uint32 {
+	cache := make([]byte, size)
+	rows := int(size / hashBytes)
+
+	keccak512 := newKeccak512Hasher()
+
+	keccak512(cache, seed)
+	for offset := uint64(hashBytes); offset < size; offset += hashBytes {
+		keccak512(cache[offset:], cache[offset-hashBytes:offset])
+	}
+
+	temp := make([]byte, hashBytes)
+	for i := 0; i < cacheRounds; i++ {
+		for j := 0; j < rows; j++ {
+			srcOff := ((j - 1 + rows) % rows) * hashBytes
+			dstOff := j * hashBytes
+			xorOff := int(binary.LittleEndian.Uint32(cache[dstOff:])%uint32(rows)) * hashBytes
+
+			for k := 0; k < hashBytes; k++ {
+				temp[k] = cache[srcOff+k] ^ cache[xorOff+k]
+			}
+			keccak512(cache[dstOff:], temp)
+		}
+	}
+	return bytesToWords(cache)
+}
+
+// generateDataset returns the full mining dataset of the given size
+func generateDataset(size uint64, cache []uint32) []uint32 {
+	dataset := make([]uint32, size/4)
+	keccak512 := newKeccak512Hasher()
+
+	for index := uint32(0); index < uint32(size/hashBytes); index++ {
+		copy(dataset[index*hashWords:], generateDatasetItem(cache, index, keccak512))
+	}
+	return dataset
+}
+
+// fnv is the non associative substitute of xor of ethash, it multiplies
+// the prime with the full 32 bits of the input
+func fnv(a, b uint32) uint32 {
+	return a*0x01000193 ^ b
+}
+
+// fnvHash mixes the data into the mix
+func fnvHash(mix []uint32, data []uint32) {
+	for i := 0; i < len(mix); i++ {
+		mix[i] = mix[i]*0x01000193 ^ data[i]
+	}
+}
+
+// generateDatasetItem returns an item of the dataset, the hash of 256
+// pseudorandomly selected items of the cache
+func generateDatasetItem(cache []uint32, index uint32, keccak512 hasher) []uint32 {
+	rows := uint32(len(cache) / hashWords)
+
+	mix := make([]byte, hashBytes)
+	binary.LittleEndian.PutUint32(mix, cache[(index%rows)*hashWords]^index)
+	for i := 1; i < hashWords; i++ {
+		binary.LittleEndian.PutUint32(mix[i*4:], cache[(index%rows)*hashWords+uint32(i)])
+	}
+	keccak512(mix, mix)
+
+	intMix := bytesToWords(mix)
+	for i := uint32(0); i < datasetParents; i++ {
+		parent := fnv(index^i, intMix[i%hashWords]) % rows
+		fnvHash(intMix, cache[parent*hashWords:])
+	}
+
+	for i, val := range intMix {
+		binary.LittleEndian.PutUint32(mix[i*4:], val)
+	}
+	keccak512(mix, mix)
+	return bytesToWords(mix)
+}
+
+// hashimoto returns the mix digest and the result of the proof of work of
+// the hash and the nonce, with the items of the dataset returned by lookup
+func hashimoto(hash []byte, nonce uint64, size uint64, lookup func(index uint32) []uint32) ([]byte, []byte) {
+	rows := uint32(size / mixBytes)
+
+	// the 64 bytes seed is the hash of the header hash and the nonce
+	raw := make([]byte, 40)
+	copy(raw, hash)
+	binary.LittleEndian.PutUint64(raw[32:], nonce)
+
+	seed := make([]byte, hashBytes)
+	newKeccak512Hasher()(seed, raw)
+	seedHead := binary.LittleEndian.Uint32(seed)
+
+	mix := make([]uint32, mixBytes/4)
+	for i := 0; i < len(mix); i++ {
+		mix[i] = binary.LittleEndian.Uint32(seed[i%hashWords*4:])
+	}
+
+	temp := make([]uint32, len(mix))
+	for i := 0; i < loopAccesses; i++ {
+		parent := fnv(uint32(i)^seedHead, mix[i%len(mix)]) % rows
+		for j := uint32(0); j < mixBytes/hashBytes; j++ {
+			copy(temp[j*hashWords:], lookup(2*parent+j))
+		}
+		fnvHash(mix, temp)
+	}
+
+	// compress the mix
+	for i := 0; i < len(mix); i += 4 {
+		mix[i/4] = fnv(fnv(fnv(mix[i], mix[i+1]), mix[i+2]), mix[i+3])
+	}
+	mix = mix[:len(mix)/4]
+
+	digest := make([]byte, 32)
+	for i, val := range mix {
+		binary.LittleEndian.PutUint32(digest[i*4:], val)
+	}
+	return digest, keccak.Keccak256(nil, append(seed, digest...))
+}
+
+// hashimotoLight computes the proof of work generating the items of the
+// dataset of the given size from the cache
+func hashimotoLight(size uint64, cache []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	keccak512 := newKeccak512Hasher()
+
+	lookup := func(index uint32) []uint32 {
+		return generateDatasetItem(cache, index, keccak512)
+	}
+	return hashimoto(hash, nonce, size, lookup)
+}
+
+// hashimotoFull computes the proof of work with the full dataset
+func hashimotoFull(dataset []uint32, hash []byte, nonce uint64) ([]byte, []byte) {
+	lookup := func(index uint32) []uint32 {
+		offset := index * hashWords
+		return dataset[offset : offset+hashWords]
+	}
+	return hashimoto(hash, nonce, uint64(len(dataset))*4, lookup)
+}
+
+func bytesToWords(b []byte) []uint32 {
+	words := make([]uint32, len(b)/4)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	return words
+}
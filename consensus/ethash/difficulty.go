@@ -0,0 +1,133 @@
+package ethash
+
+import (
+	"math/big"
+
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/types"
+)
+
+var (
+	// minimumDifficulty is the lower bound of the difficulty
+	minimumDifficulty = big.NewInt(131072)
+
+	// difficultyBoundDivisor is the divisor of the parent difficulty for the adjustment
+	difficultyBoundDivisor = big.NewInt(2048)
+
+	// durationLimit is the block time under which the frontier difficulty increases
+	durationLimit = big.NewInt(13)
+
+	// expDiffPeriod is the number of blocks in which the difficulty bomb doubles
+	expDiffPeriod = big.NewInt(100000)
+
+	// bomb delays of the byzantium (EIP-649) and constantinople (EIP-1234) forks
+	byzantiumBombDelay      = big.NewInt(3000000)
+	constantinopleBombDelay = big.NewInt(5000000)
+)
+
+var (
+	big1       = big.NewInt(1)
+	big2       = big.NewInt(2)
+	big9       = big.NewInt(9)
+	big10      = big.NewInt(10)
+	bigMinus99 = big.NewInt(-99)
+)
+
+// CalcDifficulty returns the difficulty of a block with the given timestamp
+// on top of the parent, with the rules of the forks active at the block
+func CalcDifficulty(forks *chain.Forks, time uint64, parent *types.Header) *big.Int {
+	next := parent.Number + 1
+	switch {
+	case forks.IsConstantinople(next):
+		return calcDifficultyByzantium(time, parent, constantinopleBombDelay)
+	case forks.IsByzantium(next):
+		return calcDifficultyByzantium(time, parent, byzantiumBombDelay)
+	case forks.IsHomestead(next):
+		return calcDifficultyHomestead(time, parent)
+	default:
+		return calcDifficultyFrontier(time, parent)
+	}
+}
+
+// calcDifficultyByzantium is the difficulty adjustment of EIP-100, which counts
+// the uncles of the parent, with the bomb delayed by the given number of blocks
+func calcDifficultyByzantium(time uint64, parent *types.Header, bombDelay *big.Int) *big.Int {
+	parentDiff := new(big.Int).SetUint64(parent.Difficulty)
+
+	// (2 if len(parent.uncles) else 1) - (timestamp - parent.timestamp) // 9
+	x := new(big.Int).SetUint64(time - parent.Timestamp)
+	x.Div(x, big9)
+	if parent.Sha3Uncles == types.EmptyUncleHash {
+		x.Sub(big1, x)
+	} else {
+		x.Sub(big2, x)
+	}
+	if x.Cmp(bigMinus99) < 0 {
+		x.Set(bigMinus99)
+	}
+
+	// parent_diff + parent_diff // 2048 * max(x, -99)
+	y := new(big.Int).Div(parentDiff, difficultyBoundDivisor)
+	x.Mul(y, x)
+	x.Add(parentDiff, x)
+	if x.Cmp(minimumDifficulty) < 0 {
+		x.Set(minimumDifficulty)
+	}
+
+	// the bomb uses a fake block number, the parent number minus the delay
+	fakeNumber := new(big.Int)
+	bombDelayFromParent := new(big.Int).Sub(bombDelay, big1)
+	if number := new(big.Int).SetUint64(parent.Number); number.Cmp(bombDelayFromParent) >= 0 {
+		fakeNumber.Sub(number, bombDelayFromParent)
+	}
+	return addBomb(x, fakeNumber)
+}
+
+// calcDifficultyHomestead is the difficulty adjustment of EIP-2
+func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
+	parentDiff := new(big.Int).SetUint64(parent.Difficulty)
+
+	// 1 - (timestamp - parent.timestamp) // 10
+	x := new(big.Int).SetUint64(time - parent.Timestamp)
+	x.Div(x, big10)
+	x.Sub(big1, x)
+	if x.Cmp(bigMinus99) < 0 {
+		x.Set(bigMinus99)
+	}
+
+	// parent_diff + parent_diff // 2048 * max(x, -99)
+	y := new(big.Int).Div(parentDiff, difficultyBoundDivisor)
+	x.Mul(y, x)
+	x.Add(parentDiff, x)
+	if x.Cmp(minimumDifficulty) < 0 {
+		x.Set(minimumDifficulty)
+	}
+	return addBomb(x, new(big.Int).SetUint64(parent.Number+1))
+}
+
+// calcDifficultyFrontier is the difficulty adjustment of the frontier release
+func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
+	parentDiff := new(big.Int).SetUint64(parent.Difficulty)
+	adjust := new(big.Int).Div(parentDiff, difficultyBoundDivisor)
+
+	diff := new(big.Int)
+	if new(big.Int).SetUint64(time-parent.Timestamp).Cmp(durationLimit) < 0 {
+		diff.Add(parentDiff, adjust)
+	} else {
+		diff.Sub(parentDiff, adjust)
+	}
+	if diff.Cmp(minimumDifficulty) < 0 {
+		diff.Set(minimumDifficulty)
+	}
+	return addBomb(diff, new(big.Int).SetUint64(parent.Number+1))
+}
+
+// addBomb adds the exponential factor 2^(number // 100000 - 2) to the difficulty
+func addBomb(diff *big.Int, number *big.Int) *big.Int {
+	periodCount := new(big.Int).Div(number, expDiffPeriod)
+	if periodCount.Cmp(big1) > 0 {
+		periodCount.Sub(periodCount, big2)
+		diff.Add(diff, new(big.Int).Exp(big2, periodCount, nil))
+	}
+	return diff
+}
@@ -0,0 +1,427 @@
+package ethash
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/helper/keccak"
+	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/protocol"
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/txpool"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/umbracle/fastrlp"
+	"google.golang.org/grpc"
+)
+
+const (
+	maxExtraDataSize     = 32
+	gasLimitBoundDivisor = 1024
+	minGasLimit          = 5000
+	maxGasLimit          = 0x7fffffffffffffff
+
+	// number of verification caches kept in memory
+	inmemoryCaches = 3
+
+	// sizes of the cache and the dataset in test mode
+	testCacheSize   = 1024
+	testDatasetSize = 32 * 1024
+)
+
+var (
+	errOlderBlockTime    = errors.New("timestamp older than parent")
+	errExtraDataTooLong  = errors.New("extra-data too long")
+	errInvalidDifficulty = errors.New("non-positive difficulty")
+	errInvalidMixDigest  = errors.New("invalid mix digest")
+	errInvalidPoW        = errors.New("invalid proof-of-work")
+)
+
+// two256 is the upper bound of the proof of work result
+var two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+
+type blockchainInterface interface {
+	Header() *types.Header
+	GetHeaderByHash(hash types.Hash) (*types.Header, bool)
+	WriteBlocks(blocks []*types.Block) error
+}
+
+// Ethash is the proof of work consensus of ethereum. It verifies the headers
+// with the verification caches and it can mine with the cpu, which is only
+// meant for development chains
+type Ethash struct {
+	sealing bool
+
+	logger hclog.Logger
+	config *consensus.Config
+	forks  *chain.Forks
+
+	// test uses the small cache and dataset of the tests instead of the ethereum ones
+	test bool
+
+	// coinbase is the address credited with the rewards of the mined blocks
+	coinbase types.Address
+
+	// maxClockDrift is how far in the future the timestamp of a header can be
+	maxClockDrift time.Duration
+
+	blockchain blockchainInterface
+	executor   *state.Executor
+	txpool     *txpool.TxPool
+	syncer     *protocol.Syncer
+	builder    *consensus.Builder
+
+	// caches by epoch
+	caches     *lru.Cache
+	cachesLock sync.Mutex
+
+	closeCh chan struct{}
+}
+
+func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv grpc.ServiceRegistrar, logger hclog.Logger) (consensus.Consensus, error) {
+	e := &Ethash{
+		sealing:    sealing,
+		logger:     logger.Named("ethash"),
+		config:     config,
+		forks:      &chain.Forks{},
+		blockchain: blockchain,
+		executor:   executor,
+		txpool:     txpool,
+		closeCh:    make(chan struct{}),
+	}
+	if config.Params != nil && config.Params.Forks != nil {
+		e.forks = config.Params.Forks
+	}
+
+	if raw, ok := config.Config["test"]; ok {
+		test, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("test expected bool")
+		}
+		e.test = test
+	}
+	if raw, ok := config.Config["coinbase"]; ok {
+		coinbase, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("coinbase expected string")
+		}
+		if err := e.coinbase.UnmarshalText([]byte(coinbase)); err != nil {
+			return nil, err
+		}
+	}
+	maxClockDrift, err := consensus.GetUint(config.Config, "maxClockDrift", uint64(consensus.DefaultMaxClockDrift/time.Second), true)
+	if err != nil {
+		return nil, err
+	}
+	e.maxClockDrift = time.Duration(maxClockDrift) * time.Second
+
+	if sealing && e.coinbase == types.ZeroAddress {
+		e.logger.Warn("no coinbase set, mining to the zero address")
+	}
+
+	if e.caches, err = lru.New(inmemoryCaches); err != nil {
+		return nil, err
+	}
+
+	// the miners are credited with the block rewards of the blocks of the engine
+	executor.EnableRewards(config.ForkBlock, config.EndBlock)
+
+	e.syncer = protocol.NewSyncer(logger, network, blockchain)
+	e.builder = consensus.NewBuilder(e, config, executor, txpool, 0)
+	return e, nil
+}
+
+// Start implements the consensus.Consensus interface
+func (e *Ethash) Start() error {
+	e.syncer.Start()
+	go e.run()
+	return nil
+}
+
+// Close implements the consensus.Consensus interface
+func (e *Ethash) Close() error {
+	close(e.closeCh)
+	return nil
+}
+
+func (e *Ethash) isClosed() bool {
+	select {
+	case <-e.closeCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait waits for the duration, it returns false if the consensus is closed
+func (e *Ethash) wait(d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-e.closeCh:
+		return false
+	}
+}
+
+func (e *Ethash) run() {
+	e.logger.Info("started")
+
+	for !e.isClosed() {
+		// catch up with the best peer first
+		if p := e.syncer.BestPeer(); p != nil {
+			if err := e.syncer.BulkSyncWithPeer(p); err != nil {
+				e.logger.Error("failed to bulk sync", "err", err)
+				e.wait(time.Second)
+				continue
+			}
+			if !e.sealing {
+				e.syncer.WatchSyncWithPeer(p, func(b *types.Block) bool {
+					return !e.isClosed()
+				})
+			}
+			continue
+		}
+
+		if !e.sealing {
+			e.wait(time.Second)
+			continue
+		}
+		if err := e.mineBlock(); err != nil {
+			e.logger.Error("failed to mine block", "err", err)
+			e.wait(time.Second)
+		}
+	}
+}
+
+// mineBlock mines a block on top of the head, it drops the block
+// if another one is written before the proof of work is found
+func (e *Ethash) mineBlock() error {
+	parent := e.blockchain.Header()
+
+	block, err := e.builder.Build(context.Background(), parent)
+	if err != nil {
+		return err
+	}
+	if block == nil {
+		return nil
+	}
+
+	if err := e.blockchain.WriteBlocks([]*types.Block{block}); err != nil {
+		return err
+	}
+	e.logger.Info("mined block", "number", block.Number(), "hash", block.Hash(), "txns", len(block.Transactions))
+
+	e.syncer.Broadcast(block)
+
+	// remove the included transactions from the pool
+	e.txpool.ResetWithHeader(block.Header)
+	return nil
+}
+
+// Prepare implements the consensus.Consensus interface
+func (e *Ethash) Prepare(header *types.Header) error {
+	parent, ok := e.blockchain.GetHeaderByHash(header.ParentHash)
+	if !ok {
+		return fmt.Errorf("parent %s not found", header.ParentHash)
+	}
+	header.Miner = e.coinbase
+	if header.Timestamp <= parent.Timestamp {
+		header.Timestamp = parent.Timestamp + 1
+	}
+	diff := CalcDifficulty(e.forks, header.Timestamp, parent)
+	if !diff.IsUint64() {
+		return fmt.Errorf("difficulty overflow")
+	}
+	header.Difficulty = diff.Uint64()
+	return nil
+}
+
+// Seal implements the consensus.Consensus interface, the seal is aborted if
+// another block is written before the proof of work is found
+func (e *Ethash) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	header, ok := e.seal(block.Header, func() bool {
+		return ctx.Err() != nil || e.isClosed() || e.blockchain.Header().Hash != block.ParentHash()
+	})
+	if !ok {
+		return nil, nil
+	}
+	block.Header = header
+	return block, nil
+}
+
+// seal searches the nonce of the proof of work of the header from a random
+// one, it returns false if abort is true before the nonce is found
+func (e *Ethash) seal(header *types.Header, abort func() bool) (*types.Header, bool) {
+	c := e.getCache(header.Number / epochLength)
+
+	hash := sealHash(header)
+	target := new(big.Int).Div(two256, new(big.Int).SetUint64(header.Difficulty))
+
+	nonce := rand.Uint64()
+	for attempts := 0; ; attempts++ {
+		if attempts%64 == 0 && abort() {
+			return nil, false
+		}
+		digest, result := c.hashimoto(hash, nonce)
+		if new(big.Int).SetBytes(result).Cmp(target) <= 0 {
+			header = header.Copy()
+			header.MixHash = types.BytesToHash(digest)
+			header.SetNonce(nonce)
+			header.ComputeHash()
+			return header, true
+		}
+		nonce++
+	}
+}
+
+// VerifyHeader implements the consensus.Consensus interface
+func (e *Ethash) VerifyHeader(parent, header *types.Header) error {
+	if err := consensus.VerifyTimestamp(header, e.maxClockDrift); err != nil {
+		return err
+	}
+	return e.verifyHeader(parent, header)
+}
+
+// VerifyUncle implements the blockchain.UncleVerifier interface, the uncles
+// are verified as the headers without the check of the clock drift
+func (e *Ethash) VerifyUncle(parent, uncle *types.Header) error {
+	return e.verifyHeader(parent, uncle)
+}
+
+func (e *Ethash) verifyHeader(parent, header *types.Header) error {
+	if len(header.ExtraData) > maxExtraDataSize {
+		return errExtraDataTooLong
+	}
+	if header.Timestamp <= parent.Timestamp {
+		return errOlderBlockTime
+	}
+	if err := chain.VerifyBaseFee(e.forks, parent, header); err != nil {
+		return err
+	}
+
+	expected := CalcDifficulty(e.forks, header.Timestamp, parent)
+	if expected.Cmp(new(big.Int).SetUint64(header.Difficulty)) != 0 {
+		return fmt.Errorf("invalid difficulty: have %d, want %s", header.Difficulty, expected)
+	}
+
+	if header.GasLimit > maxGasLimit {
+		return fmt.Errorf("invalid gas limit: have %d, max %d", header.GasLimit, uint64(maxGasLimit))
+	}
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gas used: have %d, gas limit %d", header.GasUsed, header.GasLimit)
+	}
+	diff := int64(parent.GasLimit) - int64(header.GasLimit)
+	if diff < 0 {
+		diff *= -1
+	}
+	limit := parent.GasLimit / gasLimitBoundDivisor
+	if uint64(diff) >= limit || header.GasLimit < minGasLimit {
+		return fmt.Errorf("invalid gas limit: have %d, want %d += %d", header.GasLimit, parent.GasLimit, limit)
+	}
+
+	return e.verifySeal(header)
+}
+
+// verifySeal verifies the proof of work of the header with the verification cache
+func (e *Ethash) verifySeal(header *types.Header) error {
+	if header.Difficulty == 0 {
+		return errInvalidDifficulty
+	}
+	c := e.getCache(header.Number / epochLength)
+
+	digest, result := c.hashimoto(sealHash(header), binary.BigEndian.Uint64(header.Nonce[:]))
+	if types.BytesToHash(digest) != header.MixHash {
+		return errInvalidMixDigest
+	}
+	target := new(big.Int).Div(two256, new(big.Int).SetUint64(header.Difficulty))
+	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+		return errInvalidPoW
+	}
+	return nil
+}
+
+// getCache returns the verification cache of the epoch, it is generated
+// on the first use and the callers of the same epoch wait for it
+func (e *Ethash) getCache(epoch uint64) *cache {
+	e.cachesLock.Lock()
+	var c *cache
+	if v, ok := e.caches.Get(epoch); ok {
+		c = v.(*cache)
+	} else {
+		c = &cache{epoch: epoch}
+		e.caches.Add(epoch, c)
+	}
+	e.cachesLock.Unlock()
+
+	c.once.Do(func() {
+		start := time.Now()
+		if e.test {
+			c.datasetSize = testDatasetSize
+			c.cache = generateCache(testCacheSize, seedHash(epoch))
+
+			// the test dataset is small enough to mine with the full dataset
+			c.dataset = generateDataset(testDatasetSize, c.cache)
+		} else {
+			c.datasetSize = datasetSize(epoch)
+			c.cache = generateCache(cacheSize(epoch), seedHash(epoch))
+		}
+		e.logger.Debug("generated verification cache", "epoch", epoch, "elapsed", time.Since(start))
+	})
+	return c
+}
+
+// cache is the verification cache of an epoch
+type cache struct {
+	epoch uint64
+	once  sync.Once
+
+	cache       []uint32
+	datasetSize uint64
+
+	// dataset is the full dataset, it is only generated in test mode
+	dataset []uint32
+}
+
+func (c *cache) hashimoto(hash []byte, nonce uint64) ([]byte, []byte) {
+	if c.dataset != nil {
+		return hashimotoFull(c.dataset, hash, nonce)
+	}
+	return hashimotoLight(c.datasetSize, c.cache, hash, nonce)
+}
+
+// sealHash returns the hash of the header without the mix digest and the nonce,
+// which is the input of the proof of work
+func sealHash(h *types.Header) []byte {
+	arena := fastrlp.DefaultArenaPool.Get()
+	defer fastrlp.DefaultArenaPool.Put(arena)
+
+	vv := arena.NewArray()
+	vv.Set(arena.NewBytes(h.ParentHash.Bytes()))
+	vv.Set(arena.NewBytes(h.Sha3Uncles.Bytes()))
+	vv.Set(arena.NewBytes(h.Miner.Bytes()))
+	vv.Set(arena.NewBytes(h.StateRoot.Bytes()))
+	vv.Set(arena.NewBytes(h.TxRoot.Bytes()))
+	vv.Set(arena.NewBytes(h.ReceiptsRoot.Bytes()))
+	vv.Set(arena.NewCopyBytes(h.LogsBloom[:]))
+	vv.Set(arena.NewUint(h.Difficulty))
+	vv.Set(arena.NewUint(h.Number))
+	vv.Set(arena.NewUint(h.GasLimit))
+	vv.Set(arena.NewUint(h.GasUsed))
+	vv.Set(arena.NewUint(h.Timestamp))
+	vv.Set(arena.NewCopyBytes(h.ExtraData))
+	if h.BaseFee != nil {
+		vv.Set(arena.NewBigInt(h.BaseFee))
+	}
+
+	return keccak.Keccak256Rlp(nil, vv)
+}
@@ -0,0 +1,264 @@
+package ethash
+
+import (
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEthash(t *testing.T) *Ethash {
+	caches, err := lru.New(inmemoryCaches)
+	assert.NoError(t, err)
+
+	return &Ethash{
+		logger: hclog.NewNullLogger(),
+		forks:  &chain.Forks{Homestead: chain.NewFork(0)},
+		test:   true,
+		caches: caches,
+
+		maxClockDrift: consensus.DefaultMaxClockDrift,
+	}
+}
+
+func TestCalcDifficulty(t *testing.T) {
+	cases := []struct {
+		name   string
+		forks  *chain.Forks
+		number uint64
+		diff   uint64
+		delta  uint64
+		uncles bool
+		expect uint64
+	}{
+		{
+			name:   "frontier fast block",
+			forks:  &chain.Forks{},
+			number: 1000,
+			diff:   1000000000,
+			delta:  5,
+			expect: 1000488281,
+		},
+		{
+			name:   "frontier slow block",
+			forks:  &chain.Forks{},
+			number: 1000,
+			diff:   1000000000,
+			delta:  20,
+			expect: 999511719,
+		},
+		{
+			name:   "frontier bomb",
+			forks:  &chain.Forks{},
+			number: 299999,
+			diff:   1000000000,
+			delta:  20,
+			expect: 999511719 + 2,
+		},
+		{
+			name:   "homestead",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0)},
+			number: 1000,
+			diff:   1000000000,
+			delta:  35,
+			expect: 999023438,
+		},
+		{
+			name:   "homestead minimum difficulty",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0)},
+			number: 1000,
+			diff:   131072,
+			delta:  1000,
+			expect: 131072,
+		},
+		{
+			name:   "byzantium without uncles",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0), Byzantium: chain.NewFork(0)},
+			number: 1000,
+			diff:   1000000000,
+			delta:  9,
+			expect: 1000000000,
+		},
+		{
+			name:   "byzantium with uncles",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0), Byzantium: chain.NewFork(0)},
+			number: 1000,
+			diff:   1000000000,
+			delta:  9,
+			uncles: true,
+			expect: 1000488281,
+		},
+		{
+			name:   "byzantium delayed bomb",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0), Byzantium: chain.NewFork(0)},
+			number: 3200000,
+			diff:   1000000000,
+			delta:  9,
+			expect: 1000000000 + 1,
+		},
+		{
+			name:   "constantinople delayed bomb",
+			forks:  &chain.Forks{Homestead: chain.NewFork(0), Byzantium: chain.NewFork(0), Constantinople: chain.NewFork(0)},
+			number: 3200000,
+			diff:   1000000000,
+			delta:  9,
+			expect: 1000000000,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			parent := &types.Header{
+				Number:     c.number,
+				Difficulty: c.diff,
+				Timestamp:  1000,
+				Sha3Uncles: types.EmptyUncleHash,
+			}
+			if c.uncles {
+				parent.Sha3Uncles = types.Hash{0x1}
+			}
+			diff := CalcDifficulty(c.forks, parent.Timestamp+c.delta, parent)
+			assert.Equal(t, c.expect, diff.Uint64())
+		})
+	}
+}
+
+func TestVerifyHeader(t *testing.T) {
+	e := newTestEthash(t)
+
+	parent := &types.Header{
+		Number:     10,
+		Difficulty: 131072,
+		GasLimit:   5000000,
+		Timestamp:  uint64(time.Now().Add(-time.Minute).Unix()),
+		Sha3Uncles: types.EmptyUncleHash,
+	}
+	parent.ComputeHash()
+
+	newHeader := func() *types.Header {
+		header := &types.Header{
+			ParentHash: parent.Hash,
+			Number:     parent.Number + 1,
+			GasLimit:   parent.GasLimit,
+			Timestamp:  parent.Timestamp + 20,
+			Sha3Uncles: types.EmptyUncleHash,
+		}
+		header.Difficulty = CalcDifficulty(e.forks, header.Timestamp, parent).Uint64()
+		return header
+	}
+
+	header, ok := e.seal(newHeader(), func() bool { return false })
+	assert.True(t, ok)
+	assert.NoError(t, e.VerifyHeader(parent, header))
+
+	cases := []struct {
+		name string
+		hook func(h *types.Header)
+		err  string
+	}{
+		{
+			name: "wrong nonce",
+			hook: func(h *types.Header) {
+				h.SetNonce(binary.BigEndian.Uint64(h.Nonce[:]) + 1)
+			},
+			err: errInvalidMixDigest.Error(),
+		},
+		{
+			name: "wrong mix digest",
+			hook: func(h *types.Header) {
+				h.MixHash = types.Hash{0x1}
+			},
+			err: errInvalidMixDigest.Error(),
+		},
+		{
+			name: "extra data too long",
+			hook: func(h *types.Header) {
+				h.ExtraData = make([]byte, maxExtraDataSize+1)
+			},
+			err: errExtraDataTooLong.Error(),
+		},
+		{
+			name: "older timestamp",
+			hook: func(h *types.Header) {
+				h.Timestamp = parent.Timestamp
+			},
+			err: errOlderBlockTime.Error(),
+		},
+		{
+			name: "future block",
+			hook: func(h *types.Header) {
+				h.Timestamp = uint64(time.Now().Add(time.Hour).Unix())
+			},
+			err: consensus.ErrFutureTimestamp.Error(),
+		},
+		{
+			name: "wrong difficulty",
+			hook: func(h *types.Header) {
+				h.Difficulty++
+			},
+			err: "invalid difficulty",
+		},
+		{
+			name: "gas limit out of bounds",
+			hook: func(h *types.Header) {
+				h.GasLimit = parent.GasLimit * 2
+			},
+			err: "invalid gas limit",
+		},
+		{
+			name: "gas used above the gas limit",
+			hook: func(h *types.Header) {
+				h.GasUsed = h.GasLimit + 1
+			},
+			err: "invalid gas used",
+		},
+		{
+			name: "base fee before london",
+			hook: func(h *types.Header) {
+				h.BaseFee = big.NewInt(chain.InitialBaseFee)
+			},
+			err: "base fee before the london fork",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := header.Copy()
+			c.hook(h)
+
+			err := e.VerifyHeader(parent, h)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestSeal_Abort(t *testing.T) {
+	e := newTestEthash(t)
+
+	header := &types.Header{
+		Number:     1,
+		Difficulty: ^uint64(0),
+	}
+	_, ok := e.seal(header, func() bool { return true })
+	assert.False(t, ok)
+}
+
+func TestGetCache(t *testing.T) {
+	e := newTestEthash(t)
+
+	c := e.getCache(0)
+	assert.Len(t, c.cache, testCacheSize/4)
+	assert.Len(t, c.dataset, testDatasetSize/4)
+
+	// the caches are reused
+	assert.Equal(t, c, e.getCache(0))
+	assert.NotEqual(t, c.cache, e.getCache(1).cache)
+}
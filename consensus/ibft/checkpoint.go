@@ -0,0 +1,241 @@
+package ibft
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/types"
+	web3 "github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+	"github.com/umbracle/go-web3/jsonrpc"
+)
+
+// The checkpoint contract in the root chain implements the methods:
+//
+//	lastCheckpoint() returns (uint256)
+//	submitCheckpoint(uint256 number, bytes32 hash, bytes[] seals)
+//
+// The seals are the committed seals of the block, each one signs
+// keccak256(keccak256(hash ++ 0x02)) with the key of a validator and together
+// they have more than two thirds of the voting power. The contract checks
+// them with the validator set it trusts.
+var (
+	lastCheckpointMethodID   = methodID("lastCheckpoint()")
+	submitCheckpointMethodID = methodID("submitCheckpoint(uint256,bytes32,bytes[])")
+)
+
+var (
+	lastCheckpointType   = abi.MustNewType("tuple(uint256 number)")
+	submitCheckpointType = abi.MustNewType("tuple(uint256 number, bytes32 hash, bytes[] seals)")
+)
+
+// defaultCheckpointInterval is the number of blocks between the checkpoints
+const defaultCheckpointInterval = 256
+
+// maxRootChainID is the largest chain id of the root chain that fits
+// in the v value of the signature of the transactions
+const maxRootChainID = 109
+
+// checkpointConfig is the root chain that receives the checkpoints
+type checkpointConfig struct {
+	// rootChain is the url of the json-rpc of the root chain
+	rootChain string
+
+	// contract is the checkpoint contract in the root chain
+	contract types.Address
+
+	// interval is the number of blocks between the checkpoints
+	interval uint64
+}
+
+func parseCheckpointConfig(raw interface{}) (*checkpointConfig, error) {
+	config, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("checkpoint is not a map")
+	}
+	c := &checkpointConfig{}
+	if c.rootChain, ok = config["rootChain"].(string); !ok || c.rootChain == "" {
+		return nil, fmt.Errorf("checkpoint rootChain is not an url")
+	}
+	str, ok := config["contract"].(string)
+	if !ok {
+		return nil, fmt.Errorf("checkpoint contract is not an address")
+	}
+	if err := c.contract.UnmarshalText([]byte(str)); err != nil {
+		return nil, fmt.Errorf("checkpoint contract is not an address: %v", err)
+	}
+	var err error
+	if c.interval, err = consensus.GetUint(config, "interval", defaultCheckpointInterval, false); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// rootChain is the json-rpc of the root chain used by the checkpoints
+type rootChain interface {
+	ChainID() (*big.Int, error)
+	GetNonce(addr web3.Address, blockNumber web3.BlockNumber) (uint64, error)
+	GasPrice() (uint64, error)
+	EstimateGas(msg *web3.CallMsg) (uint64, error)
+	Call(msg *web3.CallMsg, block web3.BlockNumber) (string, error)
+	SendRawTransaction(data []byte) (web3.Hash, error)
+}
+
+// setupCheckpoint connects to the json-rpc of the root chain
+func (i *Ibft) setupCheckpoint() error {
+	client, err := jsonrpc.NewClient(i.checkpointConfig.rootChain)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the root chain: %v", err)
+	}
+	i.rootChain = client.Eth()
+	return nil
+}
+
+// runCheckpoints submits the checkpoints to the root chain while the node is sealing
+func (i *Ibft) runCheckpoints() {
+	for {
+		select {
+		case <-time.After(i.getBlockTime()):
+		case <-i.closeCh:
+			return
+		}
+
+		if err := i.checkpoint(); err != nil {
+			i.logger.Error("failed to submit the checkpoint", "err", err)
+		}
+	}
+}
+
+// checkpoint submits the last checkpoint of the chain if it is the turn of the
+// validator. The validators take turns to submit the checkpoints, a checkpoint
+// that is not submitted is replaced by the next one
+func (i *Ibft) checkpoint() error {
+	interval := i.checkpointConfig.interval
+
+	head := i.blockchain.Header()
+	number := head.Number - head.Number%interval
+	if number == 0 || number <= i.lastCheckpoint {
+		return nil
+	}
+
+	snap, err := i.getSnapshot(number)
+	if err != nil {
+		return err
+	}
+	if snap.Set[(number/interval)%uint64(snap.Set.Len())] != i.validatorKeyAddr {
+		i.lastCheckpoint = number
+		return nil
+	}
+
+	last, err := i.getLastCheckpoint()
+	if err != nil {
+		return err
+	}
+	if last >= number {
+		i.lastCheckpoint = number
+		return nil
+	}
+
+	header, ok := i.blockchain.GetHeaderByNumber(number)
+	if !ok {
+		return fmt.Errorf("header %d not found", number)
+	}
+	extra, err := getIbftExtra(header)
+	if err != nil {
+		return err
+	}
+	input, err := abi.Encode(map[string]interface{}{
+		"number": new(big.Int).SetUint64(number),
+		"hash":   web3.Hash(header.Hash),
+		"seals":  extra.CommittedSeal,
+	}, submitCheckpointType)
+	if err != nil {
+		return err
+	}
+	hash, err := i.sendRootChainTxn(append(append([]byte{}, submitCheckpointMethodID...), input...))
+	if err != nil {
+		return err
+	}
+	i.lastCheckpoint = number
+
+	i.logger.Info("checkpoint submitted", "number", number, "hash", header.Hash, "txn", hash)
+	return nil
+}
+
+// getLastCheckpoint returns the number of the last checkpoint in the contract
+func (i *Ibft) getLastCheckpoint() (uint64, error) {
+	res, err := i.rootChain.Call(&web3.CallMsg{
+		From: web3.Address(i.validatorKeyAddr),
+		To:   web3.Address(i.checkpointConfig.contract),
+		Data: lastCheckpointMethodID,
+	}, web3.Latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the checkpoint contract: %v", err)
+	}
+	buf, err := hex.DecodeHex(res)
+	if err != nil {
+		return 0, err
+	}
+	decoded, err := abi.Decode(lastCheckpointType, buf)
+	if err != nil {
+		return 0, err
+	}
+	last, ok := decoded.(map[string]interface{})["number"].(*big.Int)
+	if !ok || !last.IsUint64() {
+		return 0, fmt.Errorf("failed to decode the last checkpoint")
+	}
+	return last.Uint64(), nil
+}
+
+// sendRootChainTxn sends a transaction to the checkpoint contract signed with the validator key
+func (i *Ibft) sendRootChainTxn(input []byte) (web3.Hash, error) {
+	from := web3.Address(i.validatorKeyAddr)
+	contract := i.checkpointConfig.contract
+
+	chainID, err := i.rootChain.ChainID()
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	if !chainID.IsUint64() || chainID.Uint64() > maxRootChainID {
+		return web3.Hash{}, fmt.Errorf("root chain id %s is not supported", chainID)
+	}
+	nonce, err := i.rootChain.GetNonce(from, web3.Pending)
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	gasPrice, err := i.rootChain.GasPrice()
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	gas, err := i.rootChain.EstimateGas(&web3.CallMsg{
+		From: from,
+		To:   web3.Address(contract),
+		Data: input,
+	})
+	if err != nil {
+		return web3.Hash{}, err
+	}
+
+	txn := &types.Transaction{
+		Nonce:    nonce,
+		GasPrice: new(big.Int).SetUint64(gasPrice),
+		Gas:      gas,
+		To:       &contract,
+		Value:    big.NewInt(0),
+		Input:    input,
+	}
+	signer := crypto.NewEIP155Signer(chainID.Uint64())
+	sig, err := i.signer.Sign(signer.Hash(txn).Bytes())
+	if err != nil {
+		return web3.Hash{}, err
+	}
+	txn.R = sig[:32]
+	txn.S = sig[32:64]
+	txn.V = sig[64] + 35 + byte(chainID.Uint64()*2)
+
+	return i.rootChain.SendRawTransaction(txn.MarshalRLP())
+}
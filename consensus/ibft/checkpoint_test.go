@@ -0,0 +1,138 @@
+package ibft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+	web3 "github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+)
+
+type mockRootChain struct {
+	last uint64
+	sent []*types.Transaction
+}
+
+func (m *mockRootChain) ChainID() (*big.Int, error) {
+	return big.NewInt(100), nil
+}
+
+func (m *mockRootChain) GetNonce(addr web3.Address, blockNumber web3.BlockNumber) (uint64, error) {
+	return uint64(len(m.sent)), nil
+}
+
+func (m *mockRootChain) GasPrice() (uint64, error) {
+	return 1, nil
+}
+
+func (m *mockRootChain) EstimateGas(msg *web3.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (m *mockRootChain) Call(msg *web3.CallMsg, block web3.BlockNumber) (string, error) {
+	res, err := abi.Encode(map[string]interface{}{
+		"number": new(big.Int).SetUint64(m.last),
+	}, lastCheckpointType)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToHex(res), nil
+}
+
+func (m *mockRootChain) SendRawTransaction(data []byte) (web3.Hash, error) {
+	txn := &types.Transaction{}
+	if err := txn.UnmarshalRLP(data); err != nil {
+		return web3.Hash{}, err
+	}
+	m.sent = append(m.sent, txn)
+	return web3.Hash{0x1}, nil
+}
+
+func TestCheckpoint_Submit(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "B")
+
+	rootChain := &mockRootChain{}
+	contract := types.StringToAddress("100")
+	i.rootChain = rootChain
+	i.checkpointConfig = &checkpointConfig{
+		contract: contract,
+		interval: 1,
+	}
+
+	// the block 1 has the committed seals of A and C
+	genesis := i.blockchain.Header()
+	header := i.DummyBlock().Header
+	header.Number = 1
+	header.ParentHash = genesis.Hash
+
+	var seals [][]byte
+	for _, name := range []string{"A", "C"} {
+		seal, err := writeCommittedSeal(NewLocalSigner(i.pool.get(name).priv), header)
+		assert.NoError(t, err)
+		seals = append(seals, seal)
+	}
+	header, err := writeCommittedSeals(header, seals)
+	assert.NoError(t, err)
+	header.ComputeHash()
+	assert.NoError(t, i.blockchain.WriteHeaders([]*types.Header{header}))
+
+	// B submits the checkpoint of block 1
+	assert.NoError(t, i.checkpoint())
+	assert.Len(t, rootChain.sent, 1)
+
+	txn := rootChain.sent[0]
+	assert.Equal(t, contract, *txn.To)
+
+	from, err := crypto.NewEIP155Signer(100).Sender(txn)
+	assert.NoError(t, err)
+	assert.Equal(t, i.pool.get("B").Address(), from)
+
+	assert.Equal(t, submitCheckpointMethodID, txn.Input[:4])
+	decoded, err := abi.Decode(submitCheckpointType, txn.Input[4:])
+	assert.NoError(t, err)
+	args := decoded.(map[string]interface{})
+	assert.Equal(t, uint64(1), args["number"].(*big.Int).Uint64())
+	assert.Equal(t, [32]byte(header.Hash), args["hash"])
+	assert.Equal(t, seals, args["seals"])
+
+	// the checkpoint is only submitted once
+	assert.NoError(t, i.checkpoint())
+	assert.Len(t, rootChain.sent, 1)
+
+	// the checkpoint is already in the contract
+	i.lastCheckpoint = 0
+	rootChain.last = 1
+	assert.NoError(t, i.checkpoint())
+	assert.Len(t, rootChain.sent, 1)
+
+	// it is not the turn of A
+	i.lastCheckpoint = 0
+	rootChain.last = 0
+	i.validatorKeyAddr = i.pool.get("A").Address()
+	assert.NoError(t, i.checkpoint())
+	assert.Len(t, rootChain.sent, 1)
+}
+
+func TestCheckpoint_Config(t *testing.T) {
+	config, err := parseCheckpointConfig(map[string]interface{}{
+		"rootChain": "http://127.0.0.1:8545",
+		"contract":  "0x0000000000000000000000000000000000000100",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(defaultCheckpointInterval), config.interval)
+	assert.Equal(t, types.StringToAddress("100"), config.contract)
+
+	_, err = parseCheckpointConfig(map[string]interface{}{
+		"rootChain": "http://127.0.0.1:8545",
+	})
+	assert.Error(t, err)
+
+	_, err = parseCheckpointConfig(map[string]interface{}{
+		"contract": "0x0000000000000000000000000000000000000100",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package ibft
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+	web3 "github.com/umbracle/go-web3"
+	"github.com/umbracle/go-web3/abi"
+)
+
+// The contracts that set the validators, either the staking contract or a
+// governance contract, implement the method:
+//
+//	validators() returns (address[])
+//
+// which is queried at every checkpoint block. The validators returned are the
+// validators of the next epoch.
+var validatorsMethodID = methodID("validators()")
+
+var validatorsType = abi.MustNewType("tuple(address[] validators)")
+
+// queryGasLimit is the gas available to read the validators from the contract
+const queryGasLimit = 10000000
+
+func methodID(sig string) []byte {
+	return crypto.Keccak256([]byte(sig))[:4]
+}
+
+// votesEnabled returns whether the validators can vote to change the validator set
+func (i *Ibft) votesEnabled() bool {
+	return i.mechanism != PoS && i.mechanism != Contract
+}
+
+// validatorSource returns the source of the validator set
+func (i *Ibft) validatorSource() proto.IbftStatusResp_ValidatorSource {
+	if i.validatorContract == nil {
+		return proto.IbftStatusResp_VOTES
+	}
+	if i.votesEnabled() {
+		return proto.IbftStatusResp_VOTES_AND_CONTRACT
+	}
+	return proto.IbftStatusResp_CONTRACT
+}
+
+// getContractValidators reads the validators of the contract at the state of the header
+func (i *Ibft) getContractValidators(contract types.Address, header *types.Header) (ValidatorSet, error) {
+	transition, err := i.executor.BeginTxn(header.StateRoot, header)
+	if err != nil {
+		return nil, err
+	}
+	res, _, err := transition.Call2(types.ZeroAddress, contract, validatorsMethodID, big.NewInt(0), queryGasLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the validators contract: %v", err)
+	}
+
+	decoded, err := abi.Decode(validatorsType, res)
+	if err != nil {
+		return nil, err
+	}
+	addrs, ok := decoded.(map[string]interface{})["validators"].([]web3.Address)
+	if !ok {
+		return nil, fmt.Errorf("failed to decode the validators")
+	}
+	validators := ValidatorSet{}
+	for _, addr := range addrs {
+		validators = append(validators, types.Address(addr))
+	}
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("empty validator set in the validators contract")
+	}
+	return validators, nil
+}
+
+// stakeWeightUnit is the stake of a unit of voting power
+var stakeWeightUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+// getStakes reads the stake of the validators in the staking contract at the
+// state of the header
+func (i *Ibft) getStakes(contract types.Address, header *types.Header, validators ValidatorSet) (map[types.Address]*big.Int, error) {
+	transition, err := i.executor.BeginTxn(header.StateRoot, header)
+	if err != nil {
+		return nil, err
+	}
+	stakes := map[types.Address]*big.Int{}
+	for _, addr := range validators {
+		input := append(append([]byte{}, stakedAmountMethodID...), types.BytesToHash(addr.Bytes()).Bytes()...)
+		res, _, err := transition.Call2(types.ZeroAddress, contract, input, big.NewInt(0), queryGasLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the stake of %s: %v", addr, err)
+		}
+		stakes[addr] = new(big.Int).SetBytes(res)
+	}
+	return stakes, nil
+}
+
+// selectStakers returns the stakers of the staking contract that are
+// validators, the ones with the minimum stake up to the maximum number
+// of validators in order of staking. The contract enforces both limits
+// but the config can raise them after the genesis
+func (i *Ibft) selectStakers(contract types.Address, header *types.Header, stakers ValidatorSet) (ValidatorSet, error) {
+	if i.minStake == nil && i.maxValidators == 0 {
+		return stakers, nil
+	}
+	stakes, err := i.getStakes(contract, header, stakers)
+	if err != nil {
+		return nil, err
+	}
+	validators := ValidatorSet{}
+	for _, addr := range stakers {
+		if i.maxValidators != 0 && uint64(len(validators)) == i.maxValidators {
+			break
+		}
+		if i.minStake != nil && stakes[addr].Cmp(i.minStake) < 0 {
+			continue
+		}
+		validators = append(validators, addr)
+	}
+	if len(validators) == 0 {
+		return nil, fmt.Errorf("no staker has the minimum stake of %s", i.minStake)
+	}
+	return validators, nil
+}
+
+// getStakeWeights reads the stake of the validators in the staking contract at
+// the state of the header. The validators without a full unit of stake have a
+// weight of one to be able to start the chain
+func (i *Ibft) getStakeWeights(contract types.Address, header *types.Header, validators ValidatorSet) (Weights, error) {
+	stakes, err := i.getStakes(contract, header, validators)
+	if err != nil {
+		return nil, err
+	}
+	weights := Weights{}
+	for _, addr := range validators {
+		weight := new(big.Int).Div(stakes[addr], stakeWeightUnit)
+		if !weight.IsUint64() {
+			return nil, fmt.Errorf("stake of %s is too large", addr)
+		}
+		if weights[addr] = weight.Uint64(); weights[addr] == 0 {
+			weights[addr] = 1
+		}
+	}
+	return weights, nil
+}
@@ -0,0 +1,108 @@
+package ibft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot_VotesAndContract(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B")
+
+	executor, root := newStakingExecutor(t, []types.Address{pool.get("A").Address(), pool.get("B").Address()}, nil, nil, 0)
+
+	genesis := pool.genesis()
+	genesis.StateRoot = root
+
+	b := blockchain.TestBlockchain(t, genesis)
+	ibft := &Ibft{
+		epochSize:         3,
+		mechanism:         PoA,
+		validatorContract: &StakingContractAddr,
+		blockchain:        b,
+		executor:          executor,
+		config:            &consensus.Config{},
+	}
+	assert.NoError(t, ibft.setupSnapshot())
+
+	pool.add("C")
+	write := func(signer string, vote string) {
+		t.Helper()
+
+		h := &types.Header{
+			Number:     b.Header().Number + 1,
+			ParentHash: b.Header().Hash,
+			MixHash:    IstanbulDigest,
+			StateRoot:  root,
+			ExtraData:  genesis.ExtraData,
+		}
+		if vote != "" {
+			h.Miner = pool.get(vote).Address()
+			h.Nonce = nonceAuthVote
+		}
+		h = pool.get(signer).sign(h)
+		h.ComputeHash()
+
+		assert.NoError(t, ibft.processHeaders([]*types.Header{h}))
+		assert.NoError(t, b.WriteHeaders([]*types.Header{h}))
+	}
+
+	// the votes change the validators during the epoch
+	write("A", "C")
+	write("B", "C")
+
+	snap, err := ibft.getSnapshot(2)
+	assert.NoError(t, err)
+	assert.True(t, snap.Set.Includes(pool.get("C").Address()))
+
+	// the checkpoint resets the validators to the ones in the contract
+	write("C", "")
+
+	snap, err = ibft.getSnapshot(3)
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSet{pool.get("A").Address(), pool.get("B").Address()}, snap.Set)
+}
+
+func TestOperator_ValidatorSource(t *testing.T) {
+	contract := types.StringToAddress("1")
+
+	cases := []struct {
+		mechanism MechanismType
+		contract  *types.Address
+		source    proto.IbftStatusResp_ValidatorSource
+		votes     bool
+	}{
+		{PoA, nil, proto.IbftStatusResp_VOTES, true},
+		{PoA, &contract, proto.IbftStatusResp_VOTES_AND_CONTRACT, true},
+		{PoS, &StakingContractAddr, proto.IbftStatusResp_CONTRACT, false},
+		{Contract, &contract, proto.IbftStatusResp_CONTRACT, false},
+	}
+	for _, c := range cases {
+		ibft := &Ibft{
+			mechanism:         c.mechanism,
+			validatorContract: c.contract,
+		}
+		o := &operator{ibft: ibft}
+
+		resp, err := o.Status(context.Background(), &empty.Empty{})
+		assert.NoError(t, err)
+		assert.Equal(t, c.source, resp.Source)
+		if c.contract != nil {
+			assert.Equal(t, c.contract.String(), resp.Contract)
+		} else {
+			assert.Empty(t, resp.Contract)
+		}
+
+		if !c.votes {
+			_, err = o.Propose(context.Background(), &proto.Candidate{Address: contract.String(), Auth: true})
+			assert.Error(t, err)
+		}
+	}
+}
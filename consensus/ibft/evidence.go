@@ -0,0 +1,174 @@
+package ibft
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/types"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+// Evidence are two conflicting messages signed by a validator in the same view
+type Evidence struct {
+	Validator types.Address `json:"validator"`
+	Sequence  uint64        `json:"sequence"`
+	Round     uint64        `json:"round"`
+	Type      string        `json:"type"`
+
+	// Digests are the digests of the conflicting messages
+	Digests []string `json:"digests"`
+
+	// Messages are the signed messages encoded with protobuf
+	Messages [][]byte `json:"messages"`
+}
+
+func (e *Evidence) toProto() *proto.Evidence {
+	return &proto.Evidence{
+		Validator: e.Validator.String(),
+		Sequence:  e.Sequence,
+		Round:     e.Round,
+		Type:      e.Type,
+		Digests:   e.Digests,
+		Messages:  e.Messages,
+	}
+}
+
+// signedView is the view of a signed message of a validator
+type signedView struct {
+	validator types.Address
+	sequence  uint64
+	round     uint64
+	typ       proto.MessageReq_Type
+}
+
+type signedMsg struct {
+	digest string
+	msg    *proto.MessageReq
+}
+
+// evidenceStore records the proposals and commits of the validators to
+// detect the double signs. The evidence is saved to the path, if any
+type evidenceStore struct {
+	lock     sync.Mutex
+	path     string
+	signed   map[signedView]*signedMsg
+	minSeq   uint64
+	evidence []*Evidence
+}
+
+func newEvidenceStore() *evidenceStore {
+	return &evidenceStore{
+		signed:   map[signedView]*signedMsg{},
+		evidence: []*Evidence{},
+	}
+}
+
+func (e *evidenceStore) loadFromPath(path string) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.path = filepath.Join(path, "evidence")
+	return readDataStore(e.path, &e.evidence)
+}
+
+// msgDigest returns the digest of the signed content of the proposals and
+// the commits, the other messages cannot be used as evidence
+func msgDigest(msg *proto.MessageReq) (string, bool) {
+	switch msg.Type {
+	case proto.MessageReq_Preprepare:
+		if msg.Proposal == nil {
+			return "", false
+		}
+		return hex.EncodeToHex(crypto.Keccak256(msg.Proposal.Value)), true
+	case proto.MessageReq_Commit:
+		// the seal signs the hash of the committed block
+		return msg.Seal, true
+	}
+	return "", false
+}
+
+// observe records a validated message and returns the evidence if it conflicts
+// with another message of the validator. The messages before minSeq are removed.
+// The sender of the message is decoded, from is the sender field that was signed
+func (e *evidenceStore) observe(msg *proto.MessageReq, from string, minSeq uint64) (*Evidence, error) {
+	digest, ok := msgDigest(msg)
+	if !ok {
+		return nil, nil
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if minSeq > e.minSeq {
+		e.minSeq = minSeq
+		for view := range e.signed {
+			if view.sequence < minSeq {
+				delete(e.signed, view)
+			}
+		}
+	}
+	if msg.View.Sequence < e.minSeq {
+		return nil, nil
+	}
+
+	view := signedView{
+		validator: msg.FromAddr(),
+		sequence:  msg.View.Sequence,
+		round:     msg.View.Round,
+		typ:       msg.Type,
+	}
+	signed := msg.Copy()
+	signed.From = from
+
+	prev, ok := e.signed[view]
+	if !ok {
+		e.signed[view] = &signedMsg{digest: digest, msg: signed}
+		return nil, nil
+	}
+	if prev.digest == digest || prev.msg == nil {
+		return nil, nil
+	}
+
+	evidence := &Evidence{
+		Validator: view.validator,
+		Sequence:  view.sequence,
+		Round:     view.round,
+		Type:      msg.Type.String(),
+		Digests:   []string{prev.digest, digest},
+	}
+	for _, m := range []*proto.MessageReq{prev.msg, signed} {
+		data, err := gproto.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		evidence.Messages = append(evidence.Messages, data)
+	}
+
+	// there is only one evidence for each view
+	prev.msg = nil
+	e.evidence = append(e.evidence, evidence)
+
+	if e.path != "" {
+		if err := writeDataStore(e.path, e.evidence); err != nil {
+			return evidence, err
+		}
+	}
+	return evidence, nil
+}
+
+// list returns the evidence of the validator or all if it is nil
+func (e *evidenceStore) list(validator *types.Address) []*Evidence {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	res := []*Evidence{}
+	for _, evidence := range e.evidence {
+		if validator == nil || evidence.Validator == *validator {
+			res = append(res, evidence)
+		}
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package ibft
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/network"
+	gproto "github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvidence_DoubleSign(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "ibft-evidence")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	i := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	assert.NoError(t, i.evidence.loadFromPath(tmpDir))
+
+	proposal := func(data string) *proto.MessageReq {
+		msg := &proto.MessageReq{
+			Type: proto.MessageReq_Preprepare,
+			View: proto.ViewMsg(1, 0),
+			Proposal: &any.Any{
+				Value: []byte(data),
+			},
+		}
+		assert.NoError(t, signMsg(NewLocalSigner(i.pool.get("B").priv), msg))
+		return msg
+	}
+
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(proposal("a")))
+	assert.Len(t, i.evidence.list(nil), 0)
+
+	// the conflicting proposal is propagated and recorded
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(proposal("b")))
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(proposal("c")))
+
+	evidence := i.evidence.list(nil)
+	assert.Len(t, evidence, 1)
+	assert.Equal(t, i.pool.get("B").Address(), evidence[0].Validator)
+	assert.Equal(t, uint64(1), evidence[0].Sequence)
+	assert.Equal(t, proto.MessageReq_Preprepare.String(), evidence[0].Type)
+
+	// the messages of the evidence are signed by the validator
+	for _, data := range evidence[0].Messages {
+		msg := &proto.MessageReq{}
+		assert.NoError(t, gproto.Unmarshal(data, msg))
+		assert.NoError(t, validateMsg(msg))
+		assert.Equal(t, i.pool.get("B").Address(), msg.FromAddr())
+	}
+
+	// the evidence is persisted
+	store := newEvidenceStore()
+	assert.NoError(t, store.loadFromPath(tmpDir))
+	assert.Equal(t, evidence, store.list(nil))
+
+	o := &operator{ibft: i.Ibft}
+	resp, err := o.GetEvidence(context.Background(), &proto.EvidenceReq{
+		Validator: i.pool.get("C").Address().String(),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Evidence, 0)
+
+	resp, err = o.GetEvidence(context.Background(), &proto.EvidenceReq{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Evidence, 1)
+}
+
+func TestEvidence_Prune(t *testing.T) {
+	e := newEvidenceStore()
+
+	commit := func(sequence uint64, seal string) *proto.MessageReq {
+		return &proto.MessageReq{
+			From: "0x1",
+			Type: proto.MessageReq_Commit,
+			View: proto.ViewMsg(sequence, 0),
+			Seal: seal,
+		}
+	}
+
+	_, err := e.observe(commit(1, "0xa"), "", 0)
+	assert.NoError(t, err)
+
+	// the messages of the old sequences are removed
+	_, err = e.observe(commit(5, "0xa"), "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, e.signed, 1)
+
+	evidence, err := e.observe(commit(1, "0xb"), "", 2)
+	assert.NoError(t, err)
+	assert.Nil(t, evidence)
+
+	evidence, err = e.observe(commit(5, "0xb"), "", 2)
+	assert.NoError(t, err)
+	assert.NotNil(t, evidence)
+}
@@ -0,0 +1,111 @@
+package ibft
+
+import (
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/network"
+)
+
+const (
+	// msgSequenceWindow is the number of sequences around the next block
+	// of the messages propagated by the node
+	msgSequenceWindow = 10
+
+	// seenMsgsSize is the number of messages kept to drop the duplicates
+	seenMsgsSize = 4096
+)
+
+// validateGossipMsg validates the messages of the topic before they reach the
+// state machine or are propagated, the messages of the validators are ignored
+// but not rejected if the node is not able to validate them yet
+func (i *Ibft) validateGossipMsg(obj interface{}) network.ValidationResult {
+	msg, ok := obj.(*proto.MessageReq)
+	if !ok || msg.View == nil || msg.View.Sequence == 0 {
+		return network.ValidationReject
+	}
+
+	// the signature is the same for the same message
+	if i.seenMsgs.Contains(msg.Signature) {
+		return network.ValidationIgnore
+	}
+
+	from := msg.From
+	if err := validateMsg(msg); err != nil {
+		i.metrics.invalidMsg(invalidSignature)
+		return network.ValidationReject
+	}
+
+	next := i.blockchain.Header().Number + 1
+	sequence := msg.View.Sequence
+	if sequence+msgSequenceWindow < next || sequence > next+msgSequenceWindow {
+		return network.ValidationIgnore
+	}
+
+	// the validators of a future sequence are not known, the
+	// latest ones are the best guess
+	snapNum := next - 1
+	if sequence < next {
+		snapNum = sequence - 1
+	}
+	snap, err := i.getSnapshot(snapNum)
+	if err != nil || snap == nil {
+		return network.ValidationIgnore
+	}
+	if !snap.Set.Includes(msg.FromAddr()) {
+		i.metrics.invalidMsg(invalidValidator)
+		if sequence > next {
+			return network.ValidationIgnore
+		}
+		return network.ValidationReject
+	}
+
+	// the messages of the batch are validated on their own, the batch
+	// only includes messages of the sender
+	if len(msg.Batch) != 0 {
+		if !i.validateBatchSender(msg) {
+			return network.ValidationReject
+		}
+		batch := []*proto.MessageReq{}
+		for _, m := range msg.Batch {
+			switch i.validateGossipMsg(m) {
+			case network.ValidationReject:
+				return network.ValidationReject
+			case network.ValidationAccept:
+				batch = append(batch, m)
+			}
+		}
+		// the ignored messages do not reach the state machine
+		msg.Batch = batch
+	}
+
+	minSeq := uint64(0)
+	if next > msgSequenceWindow {
+		minSeq = next - msgSequenceWindow
+	}
+	evidence, err := i.evidence.observe(msg, from, minSeq)
+	if err != nil {
+		i.logger.Error("failed to save the evidence", "err", err)
+	}
+	if evidence != nil {
+		i.logger.Warn("double sign", "validator", evidence.Validator, "sequence", evidence.Sequence, "round", evidence.Round, "type", evidence.Type)
+	}
+
+	// only the valid messages are seen, otherwise any copy of the
+	// signature would drop the original message
+	i.seenMsgs.Add(msg.Signature, struct{}{})
+	return network.ValidationAccept
+}
+
+// validateBatchSender checks that the messages of the batch are signed by the
+// sender of the message before they are validated and seen
+func (i *Ibft) validateBatchSender(msg *proto.MessageReq) bool {
+	for _, m := range msg.Batch {
+		if len(m.Batch) != 0 {
+			return false
+		}
+		m = m.Copy()
+		if err := validateMsg(m); err != nil || m.From != msg.From {
+			return false
+		}
+	}
+	return true
+}
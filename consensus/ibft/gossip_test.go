@@ -0,0 +1,122 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGossip_ValidateMsg(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	i.pool.add("X")
+
+	signed := func(from string, sequence uint64) *proto.MessageReq {
+		msg := &proto.MessageReq{
+			Type: proto.MessageReq_Prepare,
+			View: proto.ViewMsg(sequence, 0),
+		}
+		assert.NoError(t, signMsg(NewLocalSigner(i.pool.get(from).priv), msg))
+		return msg
+	}
+
+	cases := []struct {
+		name   string
+		msg    *proto.MessageReq
+		result network.ValidationResult
+	}{
+		{
+			name:   "validator",
+			msg:    signed("B", 1),
+			result: network.ValidationAccept,
+		},
+		{
+			name:   "non validator",
+			msg:    signed("X", 1),
+			result: network.ValidationReject,
+		},
+		{
+			// the validators of the sequence are not known yet
+			name:   "future non validator",
+			msg:    signed("X", 2),
+			result: network.ValidationIgnore,
+		},
+		{
+			name:   "out of the sequence window",
+			msg:    signed("B", 2+msgSequenceWindow),
+			result: network.ValidationIgnore,
+		},
+		{
+			name: "invalid signature",
+			msg: &proto.MessageReq{
+				Type:      proto.MessageReq_Prepare,
+				View:      proto.ViewMsg(1, 0),
+				Signature: "0x1234",
+			},
+			result: network.ValidationReject,
+		},
+		{
+			name:   "no view",
+			msg:    &proto.MessageReq{},
+			result: network.ValidationReject,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.result, i.validateGossipMsg(c.msg))
+		})
+	}
+}
+
+func TestGossip_ValidateMsg_Duplicates(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "A")
+
+	msg := &proto.MessageReq{
+		Type: proto.MessageReq_Commit,
+		View: proto.ViewMsg(1, 0),
+	}
+	assert.NoError(t, signMsg(NewLocalSigner(i.pool.get("B").priv), msg))
+
+	// the validation decodes the sender
+	accepted := msg.Copy()
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(accepted))
+	assert.Equal(t, i.pool.get("B").Address().String(), accepted.From)
+
+	// the same message is only propagated once
+	assert.Equal(t, network.ValidationIgnore, i.validateGossipMsg(msg.Copy()))
+}
+
+func TestGossip_ValidateMsg_Batch(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "A")
+
+	signed := func(from string, typ proto.MessageReq_Type, batch ...*proto.MessageReq) *proto.MessageReq {
+		msg := &proto.MessageReq{
+			Type:  typ,
+			View:  proto.ViewMsg(1, 0),
+			Batch: batch,
+		}
+		assert.NoError(t, signMsg(NewLocalSigner(i.pool.get(from).priv), msg))
+		return msg
+	}
+
+	// the messages of the batch are validated and decoded
+	msg := signed("B", proto.MessageReq_RoundChange, signed("B", proto.MessageReq_Prepare), signed("B", proto.MessageReq_Commit))
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(msg))
+	assert.Len(t, msg.Batch, 2)
+	for _, m := range msg.Batch {
+		assert.Equal(t, i.pool.get("B").Address().String(), m.From)
+	}
+
+	// the batch cannot include the messages of another validator
+	msg = signed("B", proto.MessageReq_Prepare, signed("C", proto.MessageReq_Prepare))
+	assert.Equal(t, network.ValidationReject, i.validateGossipMsg(msg))
+
+	// the messages of the batch already seen are dropped
+	commit := signed("C", proto.MessageReq_Commit)
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(commit.Copy()))
+
+	msg = signed("C", proto.MessageReq_Prepare, commit.Copy())
+	assert.Equal(t, network.ValidationAccept, i.validateGossipMsg(msg))
+	assert.Len(t, msg.Batch, 0)
+}
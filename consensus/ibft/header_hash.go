@@ -6,6 +6,12 @@ import (
 	"github.com/umbracle/fastrlp"
 )
 
+// HeaderHash is the hash function of the ibft headers, the seal and
+// the committed seals of the extra data are not part of the hash
+func HeaderHash(h *types.Header) types.Hash {
+	return istambulHeaderHash(h)
+}
+
 func istambulHeaderHash(h *types.Header) types.Hash {
 	// this function replaces extra so we need to make a copy
 	h = h.Copy() // Remove later
@@ -35,6 +41,9 @@ func istambulHeaderHash(h *types.Header) types.Hash {
 	vv.Set(arena.NewUint(h.GasUsed))
 	vv.Set(arena.NewUint(h.Timestamp))
 	vv.Set(arena.NewCopyBytes(h.ExtraData))
+	if h.BaseFee != nil {
+		vv.Set(arena.NewBigInt(h.BaseFee))
+	}
 
 	buf := keccak.Keccak256Rlp(nil, vv)
 	return types.BytesToHash(buf)
@@ -0,0 +1,153 @@
+package ibft
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// ValidatorsAt is the validator set effective at a block and the result
+// of its votes, it is reconstructed from the snapshots
+type ValidatorsAt struct {
+	Number   uint64
+	Hash     types.Hash
+	Proposer types.Address
+
+	// Validators sealed the block with their voting power
+	Validators ValidatorSet
+	Weights    Weights
+
+	// Vote is the vote cast by the proposer in the block, if any
+	Vote *Vote
+
+	// Added and Removed are the validators changed by the block
+	Added   []types.Address
+	Removed []types.Address
+
+	// Tallies are the pending proposals after the block
+	Tallies []*VoteTally
+}
+
+// VoteTally is the number of votes of a proposal
+type VoteTally struct {
+	Address   types.Address
+	Authorize bool
+	Votes     int
+	Required  int
+}
+
+// ValidatorsAt returns the validator set that sealed the block and the
+// result of the votes at the block
+func (i *Ibft) ValidatorsAt(number uint64) (*ValidatorsAt, error) {
+	header, ok := i.blockchain.GetHeaderByNumber(number)
+	if !ok {
+		return nil, fmt.Errorf("header %d not found", number)
+	}
+	if number > i.store.getLastBlock() {
+		return nil, fmt.Errorf("block %d is not processed yet", number)
+	}
+
+	after, err := i.findSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+
+	// the genesis validators do not seal any block
+	before := after
+	res := &ValidatorsAt{
+		Number: number,
+		Hash:   header.Hash,
+	}
+	if number != 0 {
+		if before, err = i.findSnapshot(number - 1); err != nil {
+			return nil, err
+		}
+		if res.Proposer, err = ecrecoverFromHeader(header); err != nil {
+			return nil, err
+		}
+		if header.Miner != types.ZeroAddress && i.votesEnabled() {
+			res.Vote = &Vote{
+				Validator: res.Proposer,
+				Address:   header.Miner,
+				Authorize: header.Nonce == nonceAuthVote,
+			}
+		}
+	}
+	res.Validators = append(ValidatorSet{}, before.Set...)
+	res.Weights = before.Weights.Copy()
+
+	for _, addr := range after.Set {
+		if !before.Set.Includes(addr) {
+			res.Added = append(res.Added, addr)
+		}
+	}
+	for _, addr := range before.Set {
+		if !after.Set.Includes(addr) {
+			res.Removed = append(res.Removed, addr)
+		}
+	}
+
+	tallies := map[types.Address]*VoteTally{}
+	for _, vote := range after.Votes {
+		tally, ok := tallies[vote.Address]
+		if !ok {
+			tally = &VoteTally{
+				Address:   vote.Address,
+				Authorize: vote.Authorize,
+				Required:  after.Set.Len()/2 + 1,
+			}
+			tallies[vote.Address] = tally
+			res.Tallies = append(res.Tallies, tally)
+		}
+		tally.Votes++
+	}
+	return res, nil
+}
+
+func (i *Ibft) findSnapshot(number uint64) (*Snapshot, error) {
+	snap, err := i.getSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("snapshot for block %d not found", number)
+	}
+	return snap, nil
+}
+
+func (v *ValidatorsAt) toProto() *proto.ValidatorsResp {
+	resp := &proto.ValidatorsResp{
+		Number:   v.Number,
+		Hash:     v.Hash.String(),
+		Proposer: v.Proposer.String(),
+	}
+	for _, addr := range v.Validators {
+		resp.Validators = append(resp.Validators, &proto.Snapshot_Validator{
+			Address: addr.String(),
+			Weight:  v.Weights.Get(addr),
+		})
+	}
+	if v.Vote != nil {
+		resp.Vote = &proto.Snapshot_Vote{
+			Validator: v.Vote.Validator.String(),
+			Proposed:  v.Vote.Address.String(),
+			Auth:      v.Vote.Authorize,
+		}
+	}
+	for _, addr := range v.Added {
+		resp.Added = append(resp.Added, addr.String())
+	}
+	for _, addr := range v.Removed {
+		resp.Removed = append(resp.Removed, addr.String())
+	}
+	for _, tally := range v.Tallies {
+		resp.Tallies = append(resp.Tallies, &proto.ValidatorsResp_Tally{
+			Address:  tally.Address.String(),
+			Auth:     tally.Authorize,
+			Votes:    uint64(tally.Votes),
+			Required: uint64(tally.Required),
+		})
+	}
+	return resp
+}
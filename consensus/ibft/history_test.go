@@ -0,0 +1,88 @@
+package ibft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_ValidatorsAt(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("a", "b", "c")
+
+	genesis := pool.genesis()
+	b := blockchain.TestBlockchain(t, genesis)
+	ibft := &Ibft{
+		epochSize:  10,
+		blockchain: b,
+		config:     &consensus.Config{},
+	}
+	assert.NoError(t, ibft.setupSnapshot())
+
+	// a and b vote to add d, the block 3 has no votes
+	pool.add("d")
+	for i, sealer := range []string{"a", "b", "c"} {
+		h := &types.Header{
+			Number:     uint64(i + 1),
+			ParentHash: b.Header().Hash,
+			MixHash:    IstanbulDigest,
+			ExtraData:  genesis.ExtraData,
+		}
+		if sealer != "c" {
+			h.Miner = pool.get("d").Address()
+			h.Nonce = nonceAuthVote
+		}
+		h = pool.get(sealer).sign(h)
+		h.ComputeHash()
+
+		assert.NoError(t, ibft.processHeaders([]*types.Header{h}))
+		assert.NoError(t, b.WriteHeaders([]*types.Header{h}))
+	}
+
+	addr := func(name string) types.Address {
+		return pool.get(name).Address()
+	}
+	initial := ValidatorSet{addr("a"), addr("b"), addr("c")}
+
+	v, err := ibft.ValidatorsAt(0)
+	assert.NoError(t, err)
+	assert.Equal(t, initial, v.Validators)
+	assert.Equal(t, types.ZeroAddress, v.Proposer)
+
+	// the first vote is pending
+	v, err = ibft.ValidatorsAt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, initial, v.Validators)
+	assert.Equal(t, addr("a"), v.Proposer)
+	assert.Equal(t, &Vote{Validator: addr("a"), Address: addr("d"), Authorize: true}, v.Vote)
+	assert.Equal(t, []*VoteTally{{Address: addr("d"), Authorize: true, Votes: 1, Required: 2}}, v.Tallies)
+	assert.Empty(t, v.Added)
+
+	// the block 2 is sealed by the initial validators and adds d
+	v, err = ibft.ValidatorsAt(2)
+	assert.NoError(t, err)
+	assert.Equal(t, initial, v.Validators)
+	assert.Equal(t, []types.Address{addr("d")}, v.Added)
+	assert.Empty(t, v.Tallies)
+
+	v, err = ibft.ValidatorsAt(3)
+	assert.NoError(t, err)
+	assert.Equal(t, append(initial, addr("d")), v.Validators)
+	assert.Nil(t, v.Vote)
+
+	_, err = ibft.ValidatorsAt(4)
+	assert.Error(t, err)
+
+	// the operator returns the same validators
+	o := &operator{ibft: ibft}
+	resp, err := o.GetValidators(context.Background(), &proto.ValidatorsReq{Number: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, addr("b").String(), resp.Proposer)
+	assert.Len(t, resp.Validators, 3)
+	assert.Equal(t, []string{addr("d").String()}, resp.Added)
+}
@@ -2,14 +2,17 @@ package ibft
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"math"
+	"math/big"
 	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/consensus"
 	"github.com/0xPolygon/minimal/consensus/ibft/proto"
 	"github.com/0xPolygon/minimal/crypto"
@@ -21,15 +24,44 @@ import (
 	"github.com/0xPolygon/minimal/types"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
 	"google.golang.org/grpc"
 )
 
 type blockchainInterface interface {
 	Header() *types.Header
 	GetHeaderByNumber(i uint64) (*types.Header, bool)
+	GetHeaderByHash(hash types.Hash) (*types.Header, bool)
 	WriteBlocks(blocks []*types.Block) error
 }
 
+// MechanismType is the mechanism that selects the validators
+type MechanismType string
+
+const (
+	// PoA selects the validators with the votes of the current validators
+	PoA MechanismType = "PoA"
+
+	// PoS reads the validators from the staking contract at every epoch
+	PoS MechanismType = "PoS"
+
+	// Contract reads the validators from the validators contract at every epoch
+	Contract MechanismType = "Contract"
+)
+
+const defaultEpochSize = 100000
+
+var (
+	// defaultBlockPeriod is the minimum time between blocks
+	defaultBlockPeriod = 2 * time.Second
+
+	// defaultRoundTimeout is the timeout of the first round
+	defaultRoundTimeout = 10 * time.Second
+)
+
+// defaultRoundTimeoutMultiplier is the base of the exponential growth of the rounds
+const defaultRoundTimeoutMultiplier = 2
+
 type Ibft struct {
 	sealing bool
 
@@ -41,18 +73,91 @@ type Ibft struct {
 	executor   *state.Executor
 	closeCh    chan struct{}
 
-	validatorKey     *ecdsa.PrivateKey
+	// signer signs with the validator key, the guard prevents
+	// it from signing conflicting blocks
+	signer           SignerBackend
+	guard            *signGuard
 	validatorKeyAddr types.Address
 
-	txpool *txpool.TxPool
+	txpool  *txpool.TxPool
+	builder *consensus.Builder
 
 	// snapshot state
 	// store     *memdb.MemDB
 	store     *snapshotStore
 	epochSize uint64
+	mechanism MechanismType
+
+	// snapshotLock sets up the snapshots only once, by the
+	// first header verified or when the engine is started
+	snapshotLock sync.Mutex
+
+	// blockTime is the minimum time between blocks and roundTimeout
+	// the base timeout of the rounds, the blockTime can be reloaded
+	blockTime    time.Duration
+	roundTimeout time.Duration
+
+	// the later rounds wait multiplier^round seconds more than the
+	// roundTimeout, up to maxRoundTimeout if it is set
+	roundTimeoutMultiplier uint64
+	maxRoundTimeout        time.Duration
+
+	// maxIdleTime is the maximum time without blocks if the txpool is
+	// empty, empty blocks are not suppressed if it is zero
+	maxIdleTime time.Duration
+
+	// maxClockDrift is how far in the future the timestamp of a header can be
+	maxClockDrift time.Duration
+
+	// validatorContract is the contract queried for the validators
+	// at every checkpoint block, if any
+	validatorContract *types.Address
+
+	// proposerPolicy selects the proposer of the rounds
+	proposerPolicy ProposerPolicy
+
+	// weights are the voting power of the validators in the genesis,
+	// with stakeWeighted they are read from the staking contract
+	weights       Weights
+	stakeWeighted bool
+
+	// quorumBlock is the first block whose quorum is more than two thirds
+	// of the voting power, the quorum is 2F+1 before it or if it is nil
+	quorumBlock *uint64
 
-	// queue of messages
+	// minStake and maxValidators limit the stakers of the staking contract
+	// that are validators, the limits are not set if they are nil or zero
+	minStake      *big.Int
+	maxValidators uint64
+
+	// forkValidators are the validators of the first block
+	// if the engine is activated at a fork of the chain
+	forkValidators ValidatorSet
+
+	// queue of messages and the messages of the gossip
+	// that were already validated
 	msgQueue *msgQueue
+	seenMsgs *lru.Cache
+
+	// evidence of the double signs of the validators
+	evidence *evidenceStore
+
+	// liveness of the validators in the recent blocks, a validator that misses
+	// more than livenessThreshold percent of the livenessWindow blocks is reported
+	liveness          *livenessTracker
+	livenessWindow    uint64
+	livenessThreshold uint64
+
+	// checkpoints of the chain submitted to the root chain, if any
+	checkpointConfig *checkpointConfig
+	rootChain        rootChain
+	lastCheckpoint   uint64
+
+	// the watchdog resyncs the node once the round changes of a
+	// sequence go beyond watchdogRounds
+	watchdogRounds uint64
+	lastWatchdog   *proto.View
+
 	updateCh chan struct{}
 
 	// sync protocol
@@ -62,31 +167,70 @@ type Ibft struct {
 	network   *network.Server
 	transport transport
 
+	// outgoing are the messages of the current step of the state
+	// machine, they are gossiped in a single batch when the state
+	// changes or before waiting for more messages
+	outgoing []*proto.MessageReq
+
 	operator *operator
 
+	// changes of the consensus state for the operator
+	statusStream statusStream
+
+	metrics *metrics
+
+	// round changes forced by the operator, the reply waits until
+	// the state machine has moved to the new round
+	roundChangeCh    chan *roundChangeReq
+	roundChangeReply chan *proto.RoundChangeResp
+
+	// round state saved to recover the round after a restart
+	lastRoundState []byte
+	recoveredRound *roundState
+
 	// aux test methods
 	forceTimeoutCh bool
 }
 
-func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv *grpc.Server, logger hclog.Logger) (consensus.Consensus, error) {
+func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool *txpool.TxPool, network *network.Server, blockchain *blockchain.Blockchain, executor *state.Executor, srv grpc.ServiceRegistrar, logger hclog.Logger) (consensus.Consensus, error) {
 	p := &Ibft{
-		logger:       logger.Named("ibft"),
-		config:       config,
-		blockchain:   blockchain,
-		executor:     executor,
-		closeCh:      make(chan struct{}),
-		txpool:       txpool,
-		state:        &currentState{},
-		network:      network,
-		epochSize:    100000,
-		syncNotifyCh: make(chan bool),
-		sealing:      sealing,
-	}
-
-	// Important. We change the hash function for the headers
-	types.HeaderHash = istambulHeaderHash
+		logger:            logger.Named("ibft"),
+		config:            config,
+		blockchain:        blockchain,
+		executor:          executor,
+		closeCh:           make(chan struct{}),
+		txpool:            txpool,
+		state:             &currentState{},
+		network:           network,
+		epochSize:         defaultEpochSize,
+		mechanism:         PoA,
+		syncNotifyCh:      make(chan bool),
+		sealing:           sealing,
+		livenessWindow:    defaultLivenessWindow,
+		livenessThreshold: defaultLivenessThreshold,
+		watchdogRounds:    defaultWatchdogRounds,
+	}
+
+	if err := p.setupConfig(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.metrics, err = newMetrics(config.Metrics); err != nil {
+		return nil, err
+	}
+
+	// Important. We change the hash function for the headers, the
+	// switch of the chains with several engines sets it instead
+	if config.ForkBlock == 0 && config.EndBlock == 0 {
+		types.HeaderHash = HeaderHash
+	}
 
 	p.syncer = protocol.NewSyncer(logger, network, blockchain)
+	p.builder = consensus.NewBuilder(p, config, executor, txpool, 100000000)
+
+	// the block rewards go to the proposer of the seal
+	consensus.SetProposer(executor, config, p.blockProposer)
 
 	// register the grpc operator
 	p.operator = &operator{ibft: p}
@@ -98,22 +242,277 @@ func Factory(ctx context.Context, sealing bool, config *consensus.Config, txpool
 
 	p.logger.Info("validator key", "addr", p.validatorKeyAddr.String())
 
-	// start the transport protocol
-	if err := p.setupTransport(); err != nil {
-		return nil, err
-	}
 	return p, nil
 }
 
+// setupConfig reads the engine options of the config
+func (i *Ibft) setupConfig() error {
+	if raw, ok := i.config.Config["type"]; ok {
+		mechanism, ok := raw.(string)
+		if !ok || (MechanismType(mechanism) != PoA && MechanismType(mechanism) != PoS && MechanismType(mechanism) != Contract) {
+			return fmt.Errorf("type must be %s, %s or %s", PoA, PoS, Contract)
+		}
+		i.mechanism = MechanismType(mechanism)
+	}
+	if i.mechanism == PoS {
+		i.validatorContract = &StakingContractAddr
+	}
+
+	policy := RoundRobin
+	if raw, ok := i.config.Config["proposerPolicy"]; ok {
+		if policy, ok = raw.(string); !ok {
+			return fmt.Errorf("proposerPolicy is not a string")
+		}
+	}
+	var err error
+	if i.proposerPolicy, err = getProposerPolicy(policy); err != nil {
+		return err
+	}
+	if raw, ok := i.config.Config["validatorContract"]; ok {
+		str, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("validatorContract is not an address")
+		}
+		var addr types.Address
+		if err := addr.UnmarshalText([]byte(str)); err != nil {
+			return fmt.Errorf("validatorContract is not an address: %v", err)
+		}
+		i.validatorContract = &addr
+	}
+	if i.mechanism == Contract && i.validatorContract == nil {
+		return fmt.Errorf("validatorContract is required with the %s type", Contract)
+	}
+
+	if i.epochSize, err = consensus.GetUint(i.config.Config, "epochSize", defaultEpochSize, false); err != nil {
+		return err
+	}
+
+	// the block time and the round timeout are in seconds
+	blockTime, err := consensus.GetUint(i.config.Config, "blockTime", uint64(defaultBlockPeriod/time.Second), false)
+	if err != nil {
+		return err
+	}
+	roundTimeout, err := consensus.GetUint(i.config.Config, "roundTimeout", uint64(defaultRoundTimeout/time.Second), false)
+	if err != nil {
+		return err
+	}
+	if roundTimeout <= blockTime {
+		// the validators would time out while waiting for the proposal
+		return fmt.Errorf("roundTimeout (%ds) has to be longer than the blockTime (%ds)", roundTimeout, blockTime)
+	}
+	i.blockTime = time.Duration(blockTime) * time.Second
+	i.roundTimeout = time.Duration(roundTimeout) * time.Second
+
+	if i.roundTimeoutMultiplier, err = consensus.GetUint(i.config.Config, "roundTimeoutMultiplier", defaultRoundTimeoutMultiplier, false); err != nil {
+		return err
+	}
+	if _, ok := i.config.Config["maxRoundTimeout"]; ok {
+		maxRoundTimeout, err := consensus.GetUint(i.config.Config, "maxRoundTimeout", 0, false)
+		if err != nil {
+			return err
+		}
+		if maxRoundTimeout < roundTimeout {
+			return fmt.Errorf("maxRoundTimeout (%ds) cannot be shorter than the roundTimeout (%ds)", maxRoundTimeout, roundTimeout)
+		}
+		i.maxRoundTimeout = time.Duration(maxRoundTimeout) * time.Second
+	}
+
+	// the validators must agree on the option since they wait
+	// longer for the proposals
+	if _, ok := i.config.Config["maxIdleTime"]; ok {
+		maxIdleTime, err := consensus.GetUint(i.config.Config, "maxIdleTime", 0, false)
+		if err != nil {
+			return err
+		}
+		if maxIdleTime <= blockTime {
+			return fmt.Errorf("maxIdleTime (%ds) has to be longer than the blockTime (%ds)", maxIdleTime, blockTime)
+		}
+		i.maxIdleTime = time.Duration(maxIdleTime) * time.Second
+	}
+
+	maxClockDrift, err := consensus.GetUint(i.config.Config, "maxClockDrift", uint64(consensus.DefaultMaxClockDrift/time.Second), true)
+	if err != nil {
+		return err
+	}
+	i.maxClockDrift = time.Duration(maxClockDrift) * time.Second
+
+	if i.livenessWindow, err = consensus.GetUint(i.config.Config, "livenessWindow", defaultLivenessWindow, false); err != nil {
+		return err
+	}
+	if i.livenessThreshold, err = consensus.GetUint(i.config.Config, "livenessThreshold", defaultLivenessThreshold, false); err != nil {
+		return err
+	}
+	if i.livenessThreshold > 100 {
+		return fmt.Errorf("livenessThreshold (%d) is a percentage of the blocks", i.livenessThreshold)
+	}
+
+	// the validators must have the same weights
+	if raw, ok := i.config.Config["weights"]; ok {
+		weights, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("weights is not a map of the validators")
+		}
+		i.weights = Weights{}
+		for str := range weights {
+			var addr types.Address
+			if err := addr.UnmarshalText([]byte(str)); err != nil {
+				return fmt.Errorf("weight of %s is not for an address: %v", str, err)
+			}
+			weight, err := consensus.GetUint(weights, str, 0, false)
+			if err != nil {
+				return err
+			}
+			i.weights[addr] = weight
+		}
+	}
+	if _, ok := i.config.Config["quorumBlock"]; ok {
+		quorumBlock, err := consensus.GetUint(i.config.Config, "quorumBlock", 0, true)
+		if err != nil {
+			return err
+		}
+		i.quorumBlock = &quorumBlock
+	}
+	if raw, ok := i.config.Config["stakeWeighted"]; ok {
+		if i.stakeWeighted, ok = raw.(bool); !ok {
+			return fmt.Errorf("stakeWeighted is not a bool")
+		}
+		if i.stakeWeighted && i.mechanism != PoS {
+			return fmt.Errorf("stakeWeighted is only available with the %s type", PoS)
+		}
+	}
+
+	if raw, ok := i.config.Config["minStake"]; ok {
+		// the stake is in wei and does not fit a json number
+		str, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("minStake is not a string")
+		}
+		if i.minStake, err = types.ParseUint256orHex(&str); err != nil {
+			return fmt.Errorf("minStake is not a number: %v", err)
+		}
+		if i.minStake.Sign() == 0 {
+			i.minStake = nil
+		}
+	}
+	if i.maxValidators, err = consensus.GetUint(i.config.Config, "maxValidators", 0, false); err != nil {
+		return err
+	}
+	if (i.minStake != nil || i.maxValidators != 0) && i.mechanism != PoS {
+		return fmt.Errorf("minStake and maxValidators are only available with the %s type", PoS)
+	}
+
+	if i.watchdogRounds, err = consensus.GetUint(i.config.Config, "watchdogRounds", defaultWatchdogRounds, false); err != nil {
+		return err
+	}
+
+	if raw, ok := i.config.Config["checkpoint"]; ok {
+		if i.checkpointConfig, err = parseCheckpointConfig(raw); err != nil {
+			return err
+		}
+	}
+
+	// there is no genesis extra with the validators if the
+	// engine starts at a fork
+	if i.config.ForkBlock != 0 {
+		raw, ok := i.config.Config["validators"].([]interface{})
+		if !ok || len(raw) == 0 {
+			return fmt.Errorf("validators are required to start at the fork block %d", i.config.ForkBlock)
+		}
+		for _, v := range raw {
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("validator %v is not an address", v)
+			}
+			var addr types.Address
+			if err := addr.UnmarshalText([]byte(str)); err != nil {
+				return fmt.Errorf("validator %s is not an address: %v", str, err)
+			}
+			i.forkValidators = append(i.forkValidators, addr)
+		}
+	}
+	return nil
+}
+
+// reloadableOptions are the options of the config that can change at runtime
+var reloadableOptions = map[string]struct{}{
+	"blockTime": {},
+}
+
+// Reload implements the consensus.Reloader interface
+func (i *Ibft) Reload(config *consensus.Config) error {
+	// the other options cannot change
+	for _, options := range []map[string]interface{}{config.Config, i.config.Config} {
+		for name := range options {
+			if _, ok := reloadableOptions[name]; !ok && !reflect.DeepEqual(config.Config[name], i.config.Config[name]) {
+				return fmt.Errorf("%s cannot be reloaded", name)
+			}
+		}
+	}
+
+	blockTime, err := consensus.GetUint(config.Config, "blockTime", uint64(defaultBlockPeriod/time.Second), false)
+	if err != nil {
+		return err
+	}
+	if time.Duration(blockTime)*time.Second >= i.roundTimeout {
+		return fmt.Errorf("roundTimeout (%s) has to be longer than the blockTime (%ds)", i.roundTimeout, blockTime)
+	}
+	if i.maxIdleTime != 0 && i.maxIdleTime <= time.Duration(blockTime)*time.Second {
+		return fmt.Errorf("maxIdleTime (%s) has to be longer than the blockTime (%ds)", i.maxIdleTime, blockTime)
+	}
+
+	i.setBlockTime(time.Duration(blockTime) * time.Second)
+	if i.builder != nil {
+		i.builder.SetGasTarget(config.BlockGasTarget)
+	}
+
+	// the next reloads compare the options with the reloaded ones
+	i.config.Config = config.Config
+	i.config.BlockGasTarget = config.BlockGasTarget
+	return nil
+}
+
+func (i *Ibft) getBlockTime() time.Duration {
+	return time.Duration(atomic.LoadInt64((*int64)(&i.blockTime)))
+}
+
+func (i *Ibft) setBlockTime(blockTime time.Duration) {
+	atomic.StoreInt64((*int64)(&i.blockTime), int64(blockTime))
+}
+
 func (i *Ibft) Start() error {
 	// start the snapshot
-	if err := i.setupSnapshot(); err != nil {
+	if err := i.initSnapshot(); err != nil {
+		return err
+	}
+
+	if i.config.Path != "" {
+		if err := i.evidence.loadFromPath(i.config.Path); err != nil {
+			return err
+		}
+	}
+	if err := i.loadRoundState(); err != nil {
+		return err
+	}
+
+	// start the transport protocol, the messages are validated
+	// with the snapshots
+	if err := i.setupTransport(); err != nil {
 		return err
 	}
 
 	i.syncer.Start()
 	go i.start()
 
+	if i.isSealing() {
+		go i.runSignerHealthCheck()
+
+		if i.checkpointConfig != nil {
+			if err := i.setupCheckpoint(); err != nil {
+				return err
+			}
+			go i.runCheckpoints()
+		}
+	}
 	return nil
 }
 
@@ -137,6 +536,9 @@ func (i *Ibft) setupTransport() error {
 	if err != nil {
 		return err
 	}
+	if err := topic.SetValidator(i.validateGossipMsg); err != nil {
+		return err
+	}
 
 	err = topic.Subscribe(func(obj interface{}) {
 		msg := obj.(*proto.MessageReq)
@@ -147,18 +549,20 @@ func (i *Ibft) setupTransport() error {
 			return
 		}
 
-		// decode sender
-		if err := validateMsg(msg); err != nil {
-			i.logger.Error("failed to validate msg", "err", err)
-			return
-		}
-
+		// the sender is decoded by the topic validator
 		if msg.From == i.validatorKeyAddr.String() {
 			// we are the sender, skip this message since we already
 			// relay our own messages internally.
 			return
 		}
+
+		// the messages of the batch are processed as if they were sent alone
+		batch := msg.Batch
+		msg.Batch = nil
 		i.pushMessage(msg)
+		for _, m := range batch {
+			i.pushMessage(m)
+		}
 	})
 	if err != nil {
 		return err
@@ -171,18 +575,53 @@ func (i *Ibft) setupTransport() error {
 func (i *Ibft) createKey() error {
 	// i.msgQueue = msgQueueImpl{}
 	i.msgQueue = newMsgQueue()
+	i.seenMsgs, _ = lru.New(seenMsgsSize)
+	i.evidence = newEvidenceStore()
+	i.liveness = newLivenessTracker(i.livenessWindow, i.livenessThreshold, i.metrics, i.logger)
 	i.closeCh = make(chan struct{})
 	i.updateCh = make(chan struct{})
-
-	if i.validatorKey == nil {
-		// generate a validator private key
-		validatorKey, err := crypto.ReadPrivKey(filepath.Join(i.config.Path, IbftKeyName))
-		if err != nil {
-			return err
+	i.roundChangeCh = make(chan *roundChangeReq)
+
+	if i.signer == nil {
+		if raw, ok := i.config.Config["remoteSigner"]; ok {
+			// the validator key is in a remote signer
+			target, ok := raw.(string)
+			if !ok {
+				return fmt.Errorf("remoteSigner is not an address")
+			}
+			config := &RemoteSignerTLS{}
+			for key, file := range map[string]*string{
+				"remoteSignerCA":   &config.CAFile,
+				"remoteSignerCert": &config.CertFile,
+				"remoteSignerKey":  &config.KeyFile,
+			} {
+				if raw, ok := i.config.Config[key]; ok {
+					if *file, ok = raw.(string); !ok {
+						return fmt.Errorf("%s is not a file", key)
+					}
+				}
+			}
+			signer, err := NewRemoteSigner(target, config)
+			if err != nil {
+				return err
+			}
+			i.signer = signer
+		} else {
+			// generate a validator private key
+			validatorKey, err := crypto.ReadPrivKey(filepath.Join(i.config.Path, IbftKeyName))
+			if err != nil {
+				return err
+			}
+			i.signer = NewLocalSigner(validatorKey)
 		}
-		i.validatorKey = validatorKey
-		i.validatorKeyAddr = crypto.PubKeyToAddress(&validatorKey.PublicKey)
 	}
+	i.validatorKeyAddr = i.signer.Address()
+
+	guard, err := newSignGuard(i.config.Path)
+	if err != nil {
+		return err
+	}
+	i.guard = guard
 	return nil
 }
 
@@ -210,7 +649,9 @@ func (i *Ibft) start() {
 func (i *Ibft) runCycle() {
 	if i.state.view != nil {
 		i.logger.Debug("cycle", "state", i.getState(), "sequence", i.state.view.Sequence, "round", i.state.view.Round)
+		i.metrics.setView(i.state.view)
 	}
+	i.publishStatus()
 
 	switch i.getState() {
 	case AcceptState:
@@ -250,6 +691,8 @@ func (i *Ibft) isValidSnapshot() bool {
 }
 
 func (i *Ibft) runSyncState() {
+	i.replyRoundChange()
+
 	for i.isState(SyncState) {
 		// try to sync with some target peer
 		p := i.syncer.BestPeer()
@@ -303,34 +746,46 @@ func (i *Ibft) runSyncState() {
 	}
 }
 
-var defaultBlockPeriod = 2 * time.Second
+func (i *Ibft) buildBlock(parent *types.Header) (*types.Block, error) {
+	block, err := i.builder.Build(context.Background(), parent)
+	if err != nil {
+		return nil, err
+	}
+	i.logger.Info("build block", "number", block.Number(), "txns", len(block.Transactions))
+	return block, nil
+}
 
-func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, error) {
-	header := &types.Header{
-		ParentHash: parent.Hash,
-		Number:     parent.Number + 1,
-		Miner:      types.Address{},
-		Nonce:      types.Nonce{},
-		MixHash:    IstanbulDigest,
-		Difficulty: parent.Number + 1,   // we need to do this because blockchain needs difficulty to organize blocks and forks
-		StateRoot:  types.EmptyRootHash, // this avoids needing state for now
-		Sha3Uncles: types.EmptyUncleHash,
-		GasLimit:   100000000, // placeholder for now
+// Prepare implements the consensus.Consensus interface
+func (i *Ibft) Prepare(header *types.Header) error {
+	parent, ok := i.blockchain.GetHeaderByHash(header.ParentHash)
+	if !ok {
+		return fmt.Errorf("parent %s not found", header.ParentHash)
+	}
+	snap, err := i.getSnapshot(parent.Number)
+	if err != nil {
+		return err
 	}
 
-	// try to pick a candidate
-	if candidate := i.operator.getNextCandidate(snap); candidate != nil {
-		header.Miner = types.StringToAddress(candidate.Address)
-		if candidate.Auth {
-			header.Nonce = nonceAuthVote
-		} else {
-			header.Nonce = nonceDropVote
+	header.Miner = types.Address{}
+	header.Nonce = types.Nonce{}
+	header.MixHash = IstanbulDigest
+	header.Difficulty = header.Number // we need to do this because blockchain needs difficulty to organize blocks and forks
+
+	// try to pick a candidate, if the validators can vote
+	if i.votesEnabled() {
+		if candidate := i.operator.getNextCandidate(snap); candidate != nil {
+			header.Miner = types.StringToAddress(candidate.Address)
+			if candidate.Auth {
+				header.Nonce = nonceAuthVote
+			} else {
+				header.Nonce = nonceDropVote
+			}
 		}
 	}
 
 	// set the timestamp
 	parentTime := time.Unix(int64(parent.Timestamp), 0)
-	headerTime := parentTime.Add(defaultBlockPeriod)
+	headerTime := parentTime.Add(i.getBlockTime())
 
 	if headerTime.Before(time.Now()) {
 		headerTime = time.Now()
@@ -339,33 +794,13 @@ func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, e
 
 	// we need to include in the extra field the current set of validators
 	putIbftExtraValidators(header, snap.Set)
+	return nil
+}
 
-	transition, err := i.executor.BeginTxn(parent.StateRoot, header)
-	if err != nil {
-		return nil, err
-	}
-	txns := []*types.Transaction{}
-	for {
-		txn, retFn := i.txpool.Pop()
-		if txn == nil {
-			break
-		}
-		if err := transition.Write(txn); err != nil {
-			retFn()
-			break
-		}
-		txns = append(txns, txn)
-	}
-
-	_, root := transition.Commit()
-	header.StateRoot = root
-	header.GasUsed = transition.TotalGas()
-
-	// build the block
-	block := consensus.BuildBlock(header, txns, transition.Receipts())
-
-	// write the seal of the block after all the fields are completed
-	header, err = writeSeal(i.validatorKey, block.Header)
+// Seal implements the consensus.Consensus interface, it writes the seal of
+// the proposer, the committed seals are written once the block is committed
+func (i *Ibft) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	header, err := i.sealProposal(block.Header)
 	if err != nil {
 		return nil, err
 	}
@@ -374,11 +809,28 @@ func (i *Ibft) buildBlock(snap *Snapshot, parent *types.Header) (*types.Block, e
 	// compute the hash, this is only a provisional hash since the final one
 	// is sealed after all the committed seals
 	block.Header.ComputeHash()
-
-	i.logger.Info("build block", "number", header.Number, "txns", len(txns))
 	return block, nil
 }
 
+// blockProposer returns the proposer of the seal of the block, the blocks
+// built by the node are not sealed yet when the rewards are credited
+func (i *Ibft) blockProposer(header *types.Header) (types.Address, error) {
+	extra, err := getIbftExtra(header)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if len(extra.Seal) == 0 {
+		return i.validatorKeyAddr, nil
+	}
+	return ecrecoverFromHeader(header)
+}
+
+// twoThirdsQuorum returns whether the quorum of the block is more than two
+// thirds of the voting power, the older blocks keep the quorum of 2F+1
+func (i *Ibft) twoThirdsQuorum(number uint64) bool {
+	return i.quorumBlock != nil && number >= *i.quorumBlock
+}
+
 func (i *Ibft) runAcceptState() { // start new round
 	logger := i.logger.Named("acceptState")
 	logger.Info("Accept state", "sequence", i.state.view.Sequence)
@@ -391,6 +843,10 @@ func (i *Ibft) runAcceptState() { // start new round
 		i.setState(SyncState)
 		return
 	}
+	if i.state.locked && i.state.block.Number() != number {
+		// the chain moved past the locked block while syncing
+		i.state.unlock()
+	}
 	snap, err := i.getSnapshot(parent.Number)
 	if err != nil {
 		i.logger.Error("cannot find snapshot", "num", parent.Number)
@@ -408,6 +864,12 @@ func (i *Ibft) runAcceptState() { // start new round
 	i.logger.Info("current snapshot", "validators", len(snap.Set), "votes", len(snap.Votes))
 
 	i.state.validators = snap.Set
+	i.state.weights = snap.Weights
+	i.state.twoThirds = i.twoThirdsQuorum(number)
+
+	// a validator that restarted rejoins the round it was in
+	recovered := i.recoverRound()
+	i.metrics.startRound(i.state.view)
 
 	// reset round messages
 	i.state.resetRoundMsgs()
@@ -418,14 +880,18 @@ func (i *Ibft) runAcceptState() { // start new round
 		lastProposer, _ = ecrecoverFromHeader(parent)
 	}
 
-	i.state.CalcProposer(lastProposer)
+	i.state.CalcProposer(i.proposerPolicy, lastProposer, parent)
 
 	if i.state.proposer == i.validatorKeyAddr {
 		logger.Info("we are the proposer", "block", number)
 
-		if !i.state.locked {
+		if !i.state.locked && !recovered {
+			if !i.waitForTxns(parent) {
+				return
+			}
+
 			// since the state is not locked, we need to build a new block
-			i.state.block, err = i.buildBlock(snap, parent)
+			i.state.block, err = i.buildBlock(parent)
 			if err != nil {
 				i.logger.Error("failed to build block", "err", err)
 				i.setState(RoundChangeState)
@@ -444,6 +910,7 @@ func (i *Ibft) runAcceptState() { // start new round
 
 		// send the preprepare message as an RLP encoded block
 		i.sendPreprepareMsg()
+		i.metrics.proposed()
 
 		// send the prepare message since we are ready to move the state
 		i.sendPrepareMsg()
@@ -458,7 +925,7 @@ func (i *Ibft) runAcceptState() { // start new round
 	// we are NOT a proposer for the block. Then, we have to wait
 	// for a pre-prepare message from the proposer
 
-	timerCh := i.randomTimeout()
+	timerCh := i.acceptTimeout(parent)
 	for i.getState() == AcceptState {
 		msg, ok := i.getNextMessage(timerCh)
 		if !ok {
@@ -471,6 +938,7 @@ func (i *Ibft) runAcceptState() { // start new round
 
 		if msg.From != i.state.proposer.String() {
 			i.logger.Error("msg received from wrong proposer")
+			i.metrics.invalidMsg(invalidProposer)
 			continue
 		}
 
@@ -478,6 +946,7 @@ func (i *Ibft) runAcceptState() { // start new round
 		block := &types.Block{}
 		if err := block.UnmarshalRLP(msg.Proposal.Value); err != nil {
 			i.logger.Error("failed to unmarshal block", "err", err)
+			i.metrics.invalidMsg(invalidProposal)
 			i.setState(RoundChangeState)
 			return
 		}
@@ -485,17 +954,21 @@ func (i *Ibft) runAcceptState() { // start new round
 			// the state is locked, we need to receive the same block
 			if block.Hash() == i.state.block.Hash() {
 				// fast-track and send a commit message and wait for validations
+				i.metrics.proposed()
 				i.sendCommitMsg()
 				i.setState(ValidateState)
 			} else {
+				i.metrics.invalidMsg(invalidLockedHash)
 				i.handleStateErr(errIncorrectBlockLocked)
 			}
 		} else {
 			// since its a new block, we have to verify it first
 			if err := i.verifyHeaderImpl(snap, parent, block.Header); err != nil {
 				i.logger.Error("block verification failed", "err", err)
+				i.metrics.invalidMsg(invalidProposal)
 				i.handleStateErr(errBlockVerificationFailed)
 			} else {
+				i.metrics.proposed()
 				i.state.block = block
 
 				// send prepare message and wait for validations
@@ -543,12 +1016,12 @@ func (i *Ibft) runValidateState() {
 			panic(fmt.Sprintf("BUG: %s", reflect.TypeOf(msg.Type)))
 		}
 
-		if i.state.numPrepared() > i.state.NumValid() {
+		if i.state.preparedWeight() >= i.state.QuorumWeight() {
 			// we have received enough pre-prepare messages
 			sendCommit()
 		}
 
-		if i.state.numCommited() > i.state.NumValid() {
+		if i.state.committedWeight() >= i.state.QuorumWeight() {
 			// we have received enough commit messages
 			sendCommit()
 
@@ -558,26 +1031,33 @@ func (i *Ibft) runValidateState() {
 	}
 
 	if i.getState() == CommitState {
-		// at this point either if it works or not we need to unlock
-		block := i.state.block
-		i.state.unlock()
+		i.runCommitState()
+	}
+}
 
-		if err := i.insertBlock(block); err != nil {
-			// start a new round with the state unlocked since we need to
-			// be able to propose/validate a different block
-			i.logger.Error("failed to insert block", "err", err)
-			i.handleStateErr(errFailedToInsertBlock)
-		} else {
-			// move ahead to the next block
-			i.setState(AcceptState)
-		}
+func (i *Ibft) runCommitState() {
+	// at this point either if it works or not we need to unlock
+	block := i.state.block
+	i.state.unlock()
+
+	if err := i.insertBlock(block); err != nil {
+		// start a new round with the state unlocked since we need to
+		// be able to propose/validate a different block
+		i.logger.Error("failed to insert block", "err", err)
+		i.releaseBlock(block)
+		i.handleStateErr(errFailedToInsertBlock)
+	} else {
+		// move ahead to the next block
+		i.setState(AcceptState)
 	}
 }
 
 func (i *Ibft) insertBlock(block *types.Block) error {
 	committedSeals := [][]byte{}
-	for _, commit := range i.state.committed {
+	sealers := []types.Address{}
+	for addr, commit := range i.state.committed {
 		committedSeals = append(committedSeals, hex.MustDecodeHex(commit.Seal)) // TODO: Validate
+		sealers = append(sealers, addr)
 	}
 
 	header, err := writeCommittedSeals(block.Header, committedSeals)
@@ -592,6 +1072,7 @@ func (i *Ibft) insertBlock(block *types.Block) error {
 	if err := i.blockchain.WriteBlocks([]*types.Block{block}); err != nil {
 		return err
 	}
+	i.metrics.committed(i.state.view.Round, sealers, len(i.state.validators))
 
 	// increase the sequence number and reset the round if any
 	i.state.view = &proto.View{
@@ -622,11 +1103,18 @@ func (i *Ibft) handleStateErr(err error) {
 
 func (i *Ibft) runRoundChangeState() {
 	sendRoundChange := func(round uint64) {
+		if i.watchdogTriggered(round) {
+			i.runWatchdog(round)
+			return
+		}
 		i.logger.Debug("local round change", "round", round)
-		// set the new round
+		// set the new round, the round changes of the others for the
+		// round are kept since they count for the quorum of the round.
+		// Before the quorumBlock the round is cleaned as it was
 		i.state.view.Round = round
-		// clean the round
-		i.state.cleanRound(round)
+		if !i.state.twoThirds {
+			i.state.cleanRound(round)
+		}
 		// send the round change message
 		i.sendRoundChange()
 	}
@@ -686,14 +1174,22 @@ func (i *Ibft) runRoundChangeState() {
 			continue
 		}
 
-		// we only expect RoundChange messages right now
+		// we only expect RoundChange messages right now, the certificates
+		// are reached once the weight of the round crosses them
+		prev := i.state.weights.Sum(i.state.roundMessages[msg.View.Round])
 		num := i.state.AddRoundMessage(msg)
+		reached := func(weight uint64) bool {
+			return prev < weight && num >= weight
+		}
 
-		if num == i.state.NumValid() {
+		if i.addPiggybackedCommit(msg) && i.state.committedWeight() >= i.state.QuorumWeight() {
+			// the seals of the round changes commit the block of the round
+			i.setState(CommitState)
+		} else if reached(i.state.RoundQuorumWeight()) {
 			// start a new round inmediatly
 			i.state.view.Round = msg.View.Round
 			i.setState(AcceptState)
-		} else if num == i.state.validators.MinFaultyNodes()+1 {
+		} else if reached(i.state.validators.MaxFaultyWeight(i.state.weights) + 1) {
 			// weak certificate, try to catch up if our round number is smaller
 			if i.state.view.Round < msg.View.Round {
 				// update timer
@@ -702,11 +1198,39 @@ func (i *Ibft) runRoundChangeState() {
 			}
 		}
 	}
+
+	if i.getState() == CommitState {
+		i.runCommitState()
+	}
+}
+
+// addPiggybackedCommit adds the committed seal of the round change message
+// as a commit if it seals the block of the state
+func (i *Ibft) addPiggybackedCommit(msg *proto.MessageReq) bool {
+	if msg.Seal == "" || i.state.block == nil || msg.Digest != i.state.block.Hash().String() {
+		return false
+	}
+	seal, err := hex.DecodeHex(msg.Seal)
+	if err != nil {
+		return false
+	}
+	if err := verifyCommittedSeal(i.state.block.Header, seal, msg.FromAddr()); err != nil {
+		i.logger.Debug("invalid piggybacked seal", "from", msg.From, "err", err)
+		return false
+	}
+	i.state.addCommited(&proto.MessageReq{
+		Type: proto.MessageReq_Commit,
+		From: msg.From,
+		Seal: msg.Seal,
+		View: msg.View,
+	})
+	return true
 }
 
 // --- com wrappers ---
 
 func (i *Ibft) sendRoundChange() {
+	i.metrics.roundChanges.Inc()
 	i.gossip(proto.MessageReq_RoundChange)
 }
 
@@ -737,14 +1261,19 @@ func (i *Ibft) gossip(typ proto.MessageReq_Type) {
 		}
 	}
 
-	// if the message is commit, we need to add the committed seal
-	if msg.Type == proto.MessageReq_Commit {
-		seal, err := writeCommittedSeal(i.validatorKey, i.state.block.Header)
+	// if the message is commit, we need to add the committed seal. The round
+	// changes of a locked validator piggyback the seal of the locked block so
+	// that the others can commit it without another round
+	if msg.Type == proto.MessageReq_Commit || msg.Type == proto.MessageReq_RoundChange && i.state.locked {
+		seal, err := i.commitSeal(i.state.block.Header)
 		if err != nil {
 			i.logger.Error("failed to commit seal", "err", err)
 			return
 		}
 		msg.Seal = hex.EncodeToHex(seal)
+		if msg.Type == proto.MessageReq_RoundChange {
+			msg.Digest = i.state.block.Hash().String()
+		}
 	}
 
 	if msg.Type != proto.MessageReq_Preprepare {
@@ -753,10 +1282,30 @@ func (i *Ibft) gossip(typ proto.MessageReq_Type) {
 		msg2.From = i.validatorKeyAddr.String()
 		i.pushMessage(msg2)
 	}
-	if err := signMsg(i.validatorKey, msg); err != nil {
+	if err := signMsg(i.signer, msg); err != nil {
 		i.logger.Error("failed to sign message", "err", err)
 		return
 	}
+	i.outgoing = append(i.outgoing, msg)
+}
+
+// flushMessages gossips the messages of the step, the first message carries
+// the others in its batch and it is signed again to include them
+func (i *Ibft) flushMessages() {
+	if len(i.outgoing) == 0 {
+		return
+	}
+	msg, batch := i.outgoing[0], i.outgoing[1:]
+	i.outgoing = nil
+
+	if len(batch) != 0 {
+		msg.Batch = batch
+		if err := signMsg(i.signer, msg); err != nil {
+			i.logger.Error("failed to sign message", "err", err)
+			return
+		}
+	}
+
 	if err := i.transport.Gossip(msg); err != nil {
 		i.logger.Error("failed to gossip", "err", err)
 	}
@@ -771,6 +1320,9 @@ func (i *Ibft) isState(s IbftState) bool {
 }
 
 func (i *Ibft) setState(s IbftState) {
+	// the messages of the previous state are gossiped together
+	i.flushMessages()
+
 	i.logger.Debug("state change", "new", s)
 	i.state.setState(s)
 }
@@ -780,13 +1332,57 @@ func (i *Ibft) forceTimeout() {
 }
 
 func (i *Ibft) randomTimeout() chan struct{} {
-	// calculate the timeout duration depending on the current round
-	timeout := time.Duration(10000) * time.Millisecond
-	round := i.state.view.Round
-	if round > 0 {
-		timeout += time.Duration(math.Pow(2, float64(round))) * time.Second
+	return timeoutCh(i.calcRoundTimeout(i.state.view.Round))
+}
+
+// calcRoundTimeout returns the timeout duration depending on the round
+func (i *Ibft) calcRoundTimeout(round uint64) time.Duration {
+	timeout := i.roundTimeout
+	if round == 0 {
+		return timeout
+	}
+	extra := math.Pow(float64(i.roundTimeoutMultiplier), float64(round))
+	if i.maxRoundTimeout != 0 && extra >= (i.maxRoundTimeout-timeout).Seconds() {
+		return i.maxRoundTimeout
 	}
+	return timeout + time.Duration(extra)*time.Second
+}
+
+// idlePollInterval is the period to check the txpool while idle
+var idlePollInterval = 500 * time.Millisecond
 
+// waitForTxns waits for transactions in the txpool before proposing in the
+// first round, up to maxIdleTime since the parent. The next rounds always
+// propose for liveness. It returns false if ibft is closing
+func (i *Ibft) waitForTxns(parent *types.Header) bool {
+	if i.maxIdleTime == 0 || i.state.view.Round != 0 {
+		return true
+	}
+	deadline := time.Unix(int64(parent.Timestamp), 0).Add(i.maxIdleTime)
+	for i.txpool.Length() == 0 && time.Now().Before(deadline) {
+		select {
+		case <-time.After(idlePollInterval):
+		case <-i.closeCh:
+			return false
+		}
+	}
+	return true
+}
+
+// acceptTimeout is the timeout to wait for a proposal, which might be
+// delayed up to maxIdleTime in the first round
+func (i *Ibft) acceptTimeout(parent *types.Header) chan struct{} {
+	if i.maxIdleTime == 0 || i.state.view.Round != 0 {
+		return i.randomTimeout()
+	}
+	idle := time.Until(time.Unix(int64(parent.Timestamp), 0).Add(i.maxIdleTime))
+	if idle < 0 {
+		idle = 0
+	}
+	return timeoutCh(idle + i.roundTimeout)
+}
+
+func timeoutCh(timeout time.Duration) chan struct{} {
 	doneCh := make(chan struct{})
 	go func() {
 		time.Sleep(timeout)
@@ -813,6 +1409,12 @@ func (i *Ibft) verifyHeaderImpl(snap *Snapshot, parent, header *types.Header) er
 	if header.Sha3Uncles != types.EmptyUncleHash {
 		return fmt.Errorf("invalid sha3 uncles")
 	}
+	if err := consensus.VerifyTimestamp(header, i.maxClockDrift); err != nil {
+		return err
+	}
+	if err := chain.VerifyBaseFee(i.config.Forks(), parent, header); err != nil {
+		return err
+	}
 	// difficulty has to match number
 	if header.Difficulty != header.Number {
 		return fmt.Errorf("wrong difficulty")
@@ -826,6 +1428,9 @@ func (i *Ibft) verifyHeaderImpl(snap *Snapshot, parent, header *types.Header) er
 }
 
 func (i *Ibft) VerifyHeader(parent, header *types.Header) error {
+	if err := i.initSnapshot(); err != nil {
+		return err
+	}
 	snap, err := i.getSnapshot(parent.Number)
 	if err != nil {
 		return err
@@ -835,7 +1440,7 @@ func (i *Ibft) VerifyHeader(parent, header *types.Header) error {
 		return err
 	}
 	// verify the commited seals
-	if err := verifyCommitedFields(snap, header); err != nil {
+	if err := verifyCommitedFields(snap, header, i.twoThirdsQuorum(header.Number)); err != nil {
 		return err
 	}
 
@@ -852,16 +1457,28 @@ func (i *Ibft) Close() error {
 	if i.config.Path != "" {
 		i.store.saveToPath(i.config.Path)
 	}
-	return nil
+	return i.signer.Close()
 }
 
 func (i *Ibft) getNextMessage(stopCh chan struct{}) (*proto.MessageReq, bool) {
 	if stopCh == nil {
 		stopCh = make(chan struct{})
 	}
+
+	// the state changed with the previous message
+	i.metrics.setView(i.state.view)
+	i.publishStatus()
+	i.saveRoundState()
+	i.replyRoundChange()
+
 	for {
 		msg := i.msgQueue.readMessage(i.getState(), i.state.view)
 		if msg != nil {
+			if !i.state.validators.Includes(msg.obj.FromAddr()) {
+				// only the validators take part in the consensus
+				i.metrics.invalidMsg(invalidValidator)
+				continue
+			}
 			return msg.obj, true
 		}
 
@@ -870,6 +1487,10 @@ func (i *Ibft) getNextMessage(stopCh chan struct{}) (*proto.MessageReq, bool) {
 			return nil, true
 		}
 
+		// the queued messages are processed, the messages of the
+		// step are gossiped before waiting for more
+		i.flushMessages()
+
 		// wait until there is a new message or
 		// someone closes the stopCh (i.e. timeout for round change)
 		select {
@@ -878,10 +1499,63 @@ func (i *Ibft) getNextMessage(stopCh chan struct{}) (*proto.MessageReq, bool) {
 		case <-i.closeCh:
 			return nil, false
 		case <-i.updateCh:
+		case req := <-i.roundChangeCh:
+			if req.resync {
+				i.resync()
+				req.replyCh <- i.roundChangeResp()
+				return nil, false
+			}
+			// handle it like a timeout of the current state
+			i.roundChangeReply = req.replyCh
+			return nil, true
 		}
 	}
 }
 
+// roundChangeReq is a round change or a resync forced by the operator
+type roundChangeReq struct {
+	resync  bool
+	replyCh chan *proto.RoundChangeResp
+}
+
+func (i *Ibft) roundChangeResp() *proto.RoundChangeResp {
+	resp := &proto.RoundChangeResp{
+		State:    i.getState().String(),
+		Sequence: i.blockchain.Header().Number + 1,
+	}
+	if i.state.view != nil && !i.isState(SyncState) {
+		resp.Sequence = i.state.view.Sequence
+		resp.Round = i.state.view.Round
+	}
+	return resp
+}
+
+// replyRoundChange replies to the operator once the forced round change is done
+func (i *Ibft) replyRoundChange() {
+	if i.roundChangeReply != nil {
+		i.roundChangeReply <- i.roundChangeResp()
+		i.roundChangeReply = nil
+	}
+}
+
+// resync drops the current round, including the locked block, and moves
+// back to sync state. The sign guard allows committing another block at
+// the same height in the later rounds
+func (i *Ibft) resync() {
+	i.logger.Info("resync forced by the operator")
+	i.releaseBlock(i.state.block)
+	i.state.unlock()
+	i.dropRound()
+}
+
+// dropRound resets the messages of the round and moves back to sync state,
+// the locked block, if any, is kept
+func (i *Ibft) dropRound() {
+	i.state.resetRoundMsgs()
+	i.state.err = nil
+	i.setState(SyncState)
+}
+
 func (i *Ibft) pushMessage(msg *proto.MessageReq) {
 	task := &msgTask{
 		view: msg.View,
@@ -2,11 +2,13 @@ package ibft
 
 import (
 	"testing"
+	"time"
 
 	"github.com/0xPolygon/minimal/blockchain"
 	"github.com/0xPolygon/minimal/consensus"
 	"github.com/0xPolygon/minimal/consensus/ibft/proto"
 	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/txpool"
 	"github.com/0xPolygon/minimal/types"
 	"github.com/golang/protobuf/ptypes/any"
 	"github.com/hashicorp/go-hclog"
@@ -128,9 +130,10 @@ func TestTransition_AcceptState_Proposer_Locked(t *testing.T) {
 	i.state.locked = true
 	i.state.block = &types.Block{
 		Header: &types.Header{
-			Number: 10,
+			Number: 1,
 		},
 	}
+	locked := i.state.block
 
 	i.runCycle()
 
@@ -140,9 +143,37 @@ func TestTransition_AcceptState_Proposer_Locked(t *testing.T) {
 		locked:   true,
 		outgoing: 2, // preprepare and prepare
 	})
-	if i.state.block.Number() != 10 {
+	if i.state.block != locked {
 		t.Fatal("bad block")
 	}
+
+	// the prepare is sent in the batch of the preprepare
+	assert.Equal(t, 1, i.gossiped)
+	assert.Equal(t, proto.MessageReq_Preprepare, i.respMsg[0].Type)
+	assert.Equal(t, proto.MessageReq_Prepare, i.respMsg[1].Type)
+}
+
+func TestTransition_AcceptState_StaleLock(t *testing.T) {
+	// the lock of a block of another height is dropped, the chain
+	// moved past it while the validator was syncing
+	i := newMockIbft(t, []string{"A", "B", "C"}, "B")
+	i.setState(AcceptState)
+
+	block := i.DummyBlock()
+	block.Header.Number = 2
+	block.Header.ComputeHash()
+
+	i.state.block = block
+	i.state.locked = true
+	i.forceTimeout()
+
+	i.runCycle()
+
+	i.expect(expectResult{
+		sequence: 1,
+		state:    RoundChangeState,
+		locked:   false,
+	})
 }
 
 func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
@@ -151,7 +182,7 @@ func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
 	i.setState(AcceptState)
 
 	block := i.DummyBlock()
-	header, err := writeSeal(i.pool.get("A").priv, block.Header)
+	header, err := writeSeal(NewLocalSigner(i.pool.get("A").priv), block.Header)
 	assert.NoError(t, err)
 	block.Header = header
 
@@ -174,6 +205,56 @@ func TestTransition_AcceptState_Validator_VerifyCorrect(t *testing.T) {
 	})
 }
 
+func TestTransition_MessagesPerHeight(t *testing.T) {
+	// the validator gossips one batch for each step of the height
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "B")
+	i.state.view = proto.ViewMsg(1, 0)
+	i.setState(AcceptState)
+
+	block := i.DummyBlock()
+	header, err := writeSeal(NewLocalSigner(i.pool.get("A").priv), block.Header)
+	assert.NoError(t, err)
+	block.Header = header
+
+	i.emitMsg(&proto.MessageReq{
+		From: "A",
+		Type: proto.MessageReq_Preprepare,
+		Proposal: &any.Any{
+			Value: block.MarshalRLP(),
+		},
+		View: proto.ViewMsg(1, 0),
+	})
+	for _, from := range []string{"A", "C", "D"} {
+		i.emitMsg(&proto.MessageReq{
+			From: from,
+			Type: proto.MessageReq_Prepare,
+			View: proto.ViewMsg(1, 0),
+		})
+	}
+
+	i.runCycle()
+
+	// the prepare is gossiped when the validator moves to validate state
+	assert.Equal(t, ValidateState, i.getState())
+	assert.Equal(t, 1, i.gossiped)
+
+	i.Close()
+	i.runCycle()
+
+	// all the prepares are processed before the commit is gossiped
+	i.expect(expectResult{
+		sequence:    1,
+		state:       ValidateState,
+		prepareMsgs: 4,
+		commitMsgs:  1,
+		locked:      true,
+		outgoing:    2, // prepare and commit
+	})
+	assert.Equal(t, 2, i.gossiped)
+	assert.Equal(t, proto.MessageReq_Prepare, i.respMsg[0].Type)
+	assert.Equal(t, proto.MessageReq_Commit, i.respMsg[1].Type)
+}
+
 func TestTransition_AcceptState_Validator_VerifyFails(t *testing.T) {
 	i := newMockIbft(t, []string{"A", "B", "C"}, "B")
 	i.state.view = proto.ViewMsg(1, 0)
@@ -182,7 +263,7 @@ func TestTransition_AcceptState_Validator_VerifyFails(t *testing.T) {
 	block := i.DummyBlock()
 	block.Header.MixHash = types.Hash{} // invalidates the block
 
-	header, err := writeSeal(i.pool.get("A").priv, block.Header)
+	header, err := writeSeal(NewLocalSigner(i.pool.get("A").priv), block.Header)
 	assert.NoError(t, err)
 	block.Header = header
 
@@ -300,39 +381,52 @@ func TestTransition_AcceptState_Validator_LockCorrect(t *testing.T) {
 }
 
 func TestTransition_RoundChangeState_CatchupRound(t *testing.T) {
-	m := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
-	m.setState(RoundChangeState)
-
-	// new messages arrive with round number 2
-	m.emitMsg(&proto.MessageReq{
-		From: "B",
-		Type: proto.MessageReq_RoundChange,
-		View: proto.ViewMsg(1, 2),
-	})
-	m.emitMsg(&proto.MessageReq{
-		From: "C",
-		Type: proto.MessageReq_RoundChange,
-		View: proto.ViewMsg(1, 2),
-	})
-	m.emitMsg(&proto.MessageReq{
-		From: "D",
-		Type: proto.MessageReq_RoundChange,
-		View: proto.ViewMsg(1, 2),
-	})
-	m.Close()
-
-	// as soon as it starts it will move to round 1 because it has
-	// not processed all the messages yet.
-	// After it receives 3 Round change messages higher than his own
-	// round it will change round again and move to accept
-	m.runCycle()
-
-	m.expect(expectResult{
-		sequence: 1,
-		round:    2,
-		outgoing: 1, // our new round change
-		state:    AcceptState,
-	})
+	cases := []struct {
+		twoThirds bool
+		outgoing  uint64
+	}{
+		// After it receives 3 Round change messages higher than his own
+		// round it will change round again and move to accept
+		{false, 1}, // our new round change
+		// After it receives 2 Round change messages higher than his own
+		// round it catches up, and with the third one there is a quorum
+		// of the round and it moves to accept
+		{true, 2}, // our round changes of the rounds 1 and 2
+	}
+	for _, c := range cases {
+		m := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
+		m.state.twoThirds = c.twoThirds
+		m.setState(RoundChangeState)
+
+		// new messages arrive with round number 2
+		m.emitMsg(&proto.MessageReq{
+			From: "B",
+			Type: proto.MessageReq_RoundChange,
+			View: proto.ViewMsg(1, 2),
+		})
+		m.emitMsg(&proto.MessageReq{
+			From: "C",
+			Type: proto.MessageReq_RoundChange,
+			View: proto.ViewMsg(1, 2),
+		})
+		m.emitMsg(&proto.MessageReq{
+			From: "D",
+			Type: proto.MessageReq_RoundChange,
+			View: proto.ViewMsg(1, 2),
+		})
+		m.Close()
+
+		// as soon as it starts it will move to round 1 because it has
+		// not processed all the messages yet.
+		m.runCycle()
+
+		m.expect(expectResult{
+			sequence: 1,
+			round:    2,
+			outgoing: c.outgoing,
+			state:    AcceptState,
+		})
+	}
 }
 
 func TestTransition_RoundChangeState_Timeout(t *testing.T) {
@@ -454,6 +548,50 @@ func TestTransition_RoundChangeState_MaxRound(t *testing.T) {
 	})
 }
 
+func TestTransition_RoundChangeState_PiggybackedCommit(t *testing.T) {
+	m := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
+
+	block := m.DummyBlock()
+	block.Header.ComputeHash()
+	m.state.block = block
+	m.state.lock()
+
+	roundChange := func(from string, seal []byte, digest string) *proto.MessageReq {
+		return &proto.MessageReq{
+			From:   m.pool.get(from).Address().String(),
+			Type:   proto.MessageReq_RoundChange,
+			View:   proto.ViewMsg(1, 1),
+			Seal:   hex.EncodeToHex(seal),
+			Digest: digest,
+		}
+	}
+	sealOf := func(from string) []byte {
+		seal, err := writeCommittedSeal(NewLocalSigner(m.pool.get(from).priv), block.Header)
+		assert.NoError(t, err)
+		return seal
+	}
+
+	// the seal of the locked block counts as a commit
+	assert.True(t, m.addPiggybackedCommit(roundChange("B", sealOf("B"), block.Hash().String())))
+	assert.Equal(t, 1, m.state.numCommited())
+
+	// the seal of another validator or another block is not counted
+	assert.False(t, m.addPiggybackedCommit(roundChange("C", sealOf("D"), block.Hash().String())))
+	assert.False(t, m.addPiggybackedCommit(roundChange("C", sealOf("C"), types.ZeroHash.String())))
+	assert.Equal(t, 1, m.state.numCommited())
+
+	// the round change of a locked validator carries its seal
+	m.setState(RoundChangeState)
+	m.sendRoundChange()
+	m.flushMessages()
+
+	msg := m.respMsg[0]
+	assert.Equal(t, block.Hash().String(), msg.Digest)
+	seal, err := hex.DecodeHex(msg.Seal)
+	assert.NoError(t, err)
+	assert.NoError(t, verifyCommittedSeal(block.Header, seal, m.pool.get("A").Address()))
+}
+
 type mockIbft struct {
 	t *testing.T
 	*Ibft
@@ -461,6 +599,7 @@ type mockIbft struct {
 	blockchain *blockchain.Blockchain
 	pool       *testerAccountPool
 	respMsg    []*proto.MessageReq
+	gossiped   int
 }
 
 func (m *mockIbft) DummyBlock() *types.Block {
@@ -483,6 +622,10 @@ func (m *mockIbft) GetHeaderByNumber(i uint64) (*types.Header, bool) {
 	return m.blockchain.GetHeaderByNumber(i)
 }
 
+func (m *mockIbft) GetHeaderByHash(hash types.Hash) (*types.Header, bool) {
+	return m.blockchain.GetHeaderByHash(hash)
+}
+
 func (m *mockIbft) WriteBlocks(blocks []*types.Block) error {
 	return nil
 }
@@ -504,7 +647,10 @@ func (m *mockIbft) addMessage(msg *proto.MessageReq) {
 }
 
 func (m *mockIbft) Gossip(msg *proto.MessageReq) error {
+	// the messages of the batch are counted on their own
 	m.respMsg = append(m.respMsg, msg)
+	m.respMsg = append(m.respMsg, msg.Batch...)
+	m.gossiped++
 	return nil
 }
 
@@ -532,17 +678,31 @@ func newMockIbft(t *testing.T, accounts []string, account string) *mockIbft {
 		logger:           hclog.NewNullLogger(),
 		config:           &consensus.Config{},
 		blockchain:       m,
-		validatorKey:     addr.priv,
+		signer:           NewLocalSigner(addr.priv),
 		validatorKeyAddr: addr.Address(),
 		closeCh:          make(chan struct{}),
 		updateCh:         make(chan struct{}),
 		operator:         &operator{},
 		state:            newState(),
+		blockTime:        defaultBlockPeriod,
+		roundTimeout:     defaultRoundTimeout,
+		maxClockDrift:    consensus.DefaultMaxClockDrift,
+		proposerPolicy:   &roundRobinPolicy{},
+
+		livenessWindow:    defaultLivenessWindow,
+		livenessThreshold: defaultLivenessThreshold,
+		watchdogRounds:    defaultWatchdogRounds,
+
+		roundTimeoutMultiplier: defaultRoundTimeoutMultiplier,
 	}
 
 	// by default set the state to (1, 0)
 	ibft.state.view = proto.ViewMsg(1, 0)
 
+	var err error
+	ibft.metrics, err = newMetrics(nil)
+	assert.NoError(t, err)
+
 	m.Ibft = ibft
 
 	assert.NoError(t, ibft.setupSnapshot())
@@ -596,3 +756,271 @@ func (m *mockIbft) expect(res expectResult) {
 		m.t.Fatalf("incorrect error %v %v", m.state.err, res.err)
 	}
 }
+
+func TestConfig_BlockTime(t *testing.T) {
+	cases := []struct {
+		config       map[string]interface{}
+		blockTime    time.Duration
+		roundTimeout time.Duration
+		maxIdleTime  time.Duration
+		drift        time.Duration
+		err          bool
+	}{
+		{
+			config:       map[string]interface{}{},
+			blockTime:    defaultBlockPeriod,
+			roundTimeout: defaultRoundTimeout,
+		},
+		{
+			// from the genesis
+			config:       map[string]interface{}{"blockTime": float64(5), "roundTimeout": float64(20)},
+			blockTime:    5 * time.Second,
+			roundTimeout: 20 * time.Second,
+		},
+		{
+			// from the node config
+			config:       map[string]interface{}{"blockTime": 1},
+			blockTime:    1 * time.Second,
+			roundTimeout: defaultRoundTimeout,
+		},
+		{
+			config: map[string]interface{}{"blockTime": float64(0)},
+			err:    true,
+		},
+		{
+			config: map[string]interface{}{"blockTime": float64(1.5)},
+			err:    true,
+		},
+		{
+			// the round times out before the proposal
+			config: map[string]interface{}{"blockTime": float64(30)},
+			err:    true,
+		},
+		{
+			config:       map[string]interface{}{"maxIdleTime": float64(60)},
+			blockTime:    defaultBlockPeriod,
+			roundTimeout: defaultRoundTimeout,
+			maxIdleTime:  60 * time.Second,
+		},
+		{
+			config: map[string]interface{}{"maxIdleTime": float64(1)},
+			err:    true,
+		},
+		{
+			config:       map[string]interface{}{"maxClockDrift": float64(60)},
+			blockTime:    defaultBlockPeriod,
+			roundTimeout: defaultRoundTimeout,
+			drift:        60 * time.Second,
+		},
+		{
+			config: map[string]interface{}{"maxClockDrift": float64(-1)},
+			err:    true,
+		},
+	}
+
+	for _, c := range cases {
+		i := &Ibft{
+			config: &consensus.Config{Config: c.config},
+		}
+		err := i.setupConfig()
+		if c.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.blockTime, i.blockTime)
+		assert.Equal(t, c.roundTimeout, i.roundTimeout)
+		assert.Equal(t, c.maxIdleTime, i.maxIdleTime)
+		if c.drift == 0 {
+			c.drift = consensus.DefaultMaxClockDrift
+		}
+		assert.Equal(t, c.drift, i.maxClockDrift)
+	}
+}
+
+func TestConfig_RoundTimeout(t *testing.T) {
+	i := &Ibft{
+		config: &consensus.Config{Config: map[string]interface{}{}},
+	}
+	assert.NoError(t, i.setupConfig())
+
+	// the default timeout doubles without a limit
+	assert.Equal(t, defaultRoundTimeout, i.calcRoundTimeout(0))
+	assert.Equal(t, defaultRoundTimeout+2*time.Second, i.calcRoundTimeout(1))
+	assert.Equal(t, defaultRoundTimeout+1024*time.Second, i.calcRoundTimeout(10))
+
+	i.config.Config = map[string]interface{}{
+		"roundTimeout":           float64(5),
+		"roundTimeoutMultiplier": float64(3),
+		"maxRoundTimeout":        float64(60),
+	}
+	assert.NoError(t, i.setupConfig())
+	assert.Equal(t, 5*time.Second, i.calcRoundTimeout(0))
+	assert.Equal(t, 14*time.Second, i.calcRoundTimeout(2))
+	assert.Equal(t, 60*time.Second, i.calcRoundTimeout(4))
+	assert.Equal(t, 60*time.Second, i.calcRoundTimeout(1000))
+
+	i.config.Config = map[string]interface{}{"maxRoundTimeout": float64(5)}
+	assert.Error(t, i.setupConfig())
+
+	i.config.Config = map[string]interface{}{"roundTimeoutMultiplier": float64(0)}
+	assert.Error(t, i.setupConfig())
+}
+
+func TestConfig_Reload(t *testing.T) {
+	i := &Ibft{
+		config: &consensus.Config{Config: map[string]interface{}{
+			"blockTime":   float64(2),
+			"maxIdleTime": float64(8),
+		}},
+		builder: consensus.NewBuilder(nil, &consensus.Config{}, nil, nil, 0),
+	}
+	assert.NoError(t, i.setupConfig())
+
+	reload := func(config map[string]interface{}) error {
+		return i.Reload(&consensus.Config{Config: config, BlockGasTarget: 1000})
+	}
+
+	assert.NoError(t, reload(map[string]interface{}{"blockTime": float64(4), "maxIdleTime": float64(8)}))
+	assert.Equal(t, 4*time.Second, i.getBlockTime())
+
+	// the config keeps the reloaded options
+	assert.Equal(t, float64(4), i.config.Config["blockTime"])
+	assert.Equal(t, uint64(1000), i.config.BlockGasTarget)
+
+	// the other options cannot change
+	assert.Error(t, reload(map[string]interface{}{"blockTime": float64(4)}))
+	assert.Error(t, reload(map[string]interface{}{"blockTime": float64(4), "maxIdleTime": float64(8), "epochSize": float64(10)}))
+
+	// the block time has to be shorter than the timeouts
+	assert.Error(t, reload(map[string]interface{}{"blockTime": float64(8), "maxIdleTime": float64(8)}))
+	assert.Equal(t, 4*time.Second, i.getBlockTime())
+}
+
+func TestConfig_Weights(t *testing.T) {
+	addr := types.StringToAddress("1")
+
+	i := &Ibft{
+		config: &consensus.Config{Config: map[string]interface{}{
+			"weights": map[string]interface{}{addr.String(): float64(5)},
+		}},
+	}
+	assert.NoError(t, i.setupConfig())
+	assert.Equal(t, Weights{addr: 5}, i.weights)
+
+	for _, config := range []map[string]interface{}{
+		{"weights": map[string]interface{}{addr.String(): float64(0)}},
+		{"weights": map[string]interface{}{"a": float64(1)}},
+		// the stake is only in the staking contract
+		{"stakeWeighted": true},
+	} {
+		i := &Ibft{
+			mechanism: PoA,
+			config:    &consensus.Config{Config: config},
+		}
+		assert.Error(t, i.setupConfig())
+	}
+}
+
+func TestConfig_QuorumBlock(t *testing.T) {
+	// the quorum of 2F+1 is kept without the fork
+	i := &Ibft{config: &consensus.Config{Config: map[string]interface{}{}}}
+	assert.NoError(t, i.setupConfig())
+	assert.False(t, i.twoThirdsQuorum(100))
+
+	i = &Ibft{config: &consensus.Config{Config: map[string]interface{}{
+		"quorumBlock": float64(10),
+	}}}
+	assert.NoError(t, i.setupConfig())
+	assert.False(t, i.twoThirdsQuorum(9))
+	assert.True(t, i.twoThirdsQuorum(10))
+
+	// the blocks before the fork are sealed with 3 of 5 validators
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C", "D", "E")
+	snap := &Snapshot{Set: pool.ValidatorSet()}
+
+	sealed := func(number uint64) *types.Header {
+		h := &types.Header{Number: number}
+		putIbftExtraValidators(h, pool.ValidatorSet())
+
+		seals := [][]byte{}
+		for _, name := range []string{"A", "B", "C"} {
+			seal, err := writeCommittedSeal(NewLocalSigner(pool.get(name).priv), h)
+			assert.NoError(t, err)
+			seals = append(seals, seal)
+		}
+		h, err := writeCommittedSeals(h, seals)
+		assert.NoError(t, err)
+		return h
+	}
+	assert.NoError(t, verifyCommitedFields(snap, sealed(9), i.twoThirdsQuorum(9)))
+	assert.Error(t, verifyCommitedFields(snap, sealed(10), i.twoThirdsQuorum(10)))
+}
+
+func TestWaitForTxns_Idle(t *testing.T) {
+	pool, err := txpool.NewTxPool(hclog.NewNullLogger(), false, nil, nil, nil)
+	assert.NoError(t, err)
+
+	i := &Ibft{
+		txpool:      pool,
+		state:       newState(),
+		closeCh:     make(chan struct{}),
+		maxIdleTime: 2 * time.Second,
+	}
+	i.state.view = proto.ViewMsg(1, 0)
+
+	// the chain has been idle for too long, propose the empty block
+	parent := &types.Header{Timestamp: uint64(time.Now().Add(-time.Minute).Unix())}
+	assert.True(t, i.waitForTxns(parent))
+
+	// wait for the max idle time since the parent
+	now := time.Now()
+	parent.Timestamp = uint64(now.Unix())
+	assert.True(t, i.waitForTxns(parent))
+	assert.True(t, time.Since(now) >= time.Second)
+
+	// the next rounds do not wait
+	now = time.Now()
+	parent.Timestamp = uint64(now.Unix())
+	i.state.view = proto.ViewMsg(1, 1)
+	assert.True(t, i.waitForTxns(parent))
+	assert.True(t, time.Since(now) < time.Second)
+
+	// closing
+	i.state.view = proto.ViewMsg(1, 0)
+	close(i.closeCh)
+	assert.False(t, i.waitForTxns(parent))
+}
+
+func TestVerifyHeader_Timestamp(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A")
+
+	snap := &Snapshot{
+		Set: pool.ValidatorSet(),
+	}
+	i := &Ibft{
+		config:        &consensus.Config{},
+		maxClockDrift: 10 * time.Second,
+	}
+
+	sealed := func(drift time.Duration) *types.Header {
+		h := &types.Header{
+			Nonce:      nonceDropVote,
+			MixHash:    IstanbulDigest,
+			Sha3Uncles: types.EmptyUncleHash,
+			Timestamp:  uint64(time.Now().Add(drift).Unix()),
+		}
+		putIbftExtraValidators(h, pool.ValidatorSet())
+
+		h, err := writeSeal(NewLocalSigner(pool.get("A").priv), h)
+		assert.NoError(t, err)
+		return h
+	}
+
+	// the clock of the proposer is ahead within the drift
+	parent := &types.Header{}
+	assert.NoError(t, i.verifyHeaderImpl(snap, parent, sealed(5*time.Second)))
+	assert.Equal(t, consensus.ErrFutureTimestamp, i.verifyHeaderImpl(snap, parent, sealed(time.Minute)))
+}
@@ -0,0 +1,180 @@
+package ibft
+
+import (
+	"sync"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// defaultLivenessWindow is the number of recent blocks of the liveness
+	defaultLivenessWindow = 100
+
+	// defaultLivenessThreshold is the percentage of missed blocks in the
+	// window that raises an alert for a validator
+	defaultLivenessThreshold = 50
+)
+
+// livenessBlock are the validators and the sealers of a block
+type livenessBlock struct {
+	number     uint64
+	validators ValidatorSet
+	sealers    map[types.Address]struct{}
+}
+
+// validatorLiveness is the participation of a validator in the window
+type validatorLiveness struct {
+	validator types.Address
+
+	// blocks is the number of blocks of the window in which it was a validator
+	blocks uint64
+
+	// sealed is the number of those blocks with its committed seal
+	sealed uint64
+
+	// lastSealed is the last block of the window with its seal, if any
+	lastSealed uint64
+
+	// alert is set while it misses more blocks than the threshold
+	alert bool
+}
+
+func (v *validatorLiveness) participation() float64 {
+	if v.blocks == 0 {
+		return 0
+	}
+	return float64(v.sealed) / float64(v.blocks)
+}
+
+// missed returns whether it missed more blocks than the percentage
+func (v *validatorLiveness) missed(threshold uint64) bool {
+	return (v.blocks-v.sealed)*100 > v.blocks*threshold
+}
+
+// livenessTracker records the committed seals of the validators in the recent
+// blocks and warns about the validators that miss more blocks than the threshold
+type livenessTracker struct {
+	lock      sync.Mutex
+	logger    hclog.Logger
+	metrics   *metrics
+	window    uint64
+	threshold uint64
+	blocks    []*livenessBlock
+	alerts    map[types.Address]struct{}
+}
+
+func newLivenessTracker(window, threshold uint64, metrics *metrics, logger hclog.Logger) *livenessTracker {
+	return &livenessTracker{
+		logger:    logger,
+		metrics:   metrics,
+		window:    window,
+		threshold: threshold,
+		blocks:    []*livenessBlock{},
+		alerts:    map[types.Address]struct{}{},
+	}
+}
+
+// observe records the sealers of a block, the validators are the
+// validators of the parent. A block replaces the observed blocks at
+// the same height or above after a reorg
+func (l *livenessTracker) observe(number uint64, validators ValidatorSet, sealers []types.Address) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var prev ValidatorSet
+	if len(l.blocks) != 0 {
+		prev = l.blocks[len(l.blocks)-1].validators
+	}
+	for len(l.blocks) != 0 && l.blocks[len(l.blocks)-1].number >= number {
+		l.blocks = l.blocks[:len(l.blocks)-1]
+	}
+	block := &livenessBlock{
+		number:     number,
+		validators: validators,
+		sealers:    map[types.Address]struct{}{},
+	}
+	for _, addr := range sealers {
+		block.sealers[addr] = struct{}{}
+	}
+	l.blocks = append(l.blocks, block)
+	if uint64(len(l.blocks)) > l.window {
+		l.blocks = l.blocks[uint64(len(l.blocks))-l.window:]
+	}
+
+	// the validators that are not in the set anymore are not tracked
+	for _, addr := range prev {
+		if !validators.Includes(addr) {
+			delete(l.alerts, addr)
+			l.metrics.liveness.DeleteLabelValues(addr.String())
+		}
+	}
+
+	// the alerts are only raised once the window is full
+	full := uint64(len(l.blocks)) == l.window
+	for _, v := range l.listLocked(nil) {
+		l.metrics.liveness.WithLabelValues(v.validator.String()).Set(v.participation())
+
+		_, alert := l.alerts[v.validator]
+		if missed := full && v.missed(l.threshold); missed && !alert {
+			l.alerts[v.validator] = struct{}{}
+			l.logger.Warn("validator is missing blocks", "validator", v.validator, "sealed", v.sealed, "blocks", v.blocks, "threshold", l.threshold)
+		} else if !missed && alert {
+			delete(l.alerts, v.validator)
+			l.logger.Info("validator is sealing blocks again", "validator", v.validator, "sealed", v.sealed, "blocks", v.blocks)
+		}
+	}
+}
+
+// list returns the liveness of the validator or of all the
+// validators of the last block if it is nil
+func (l *livenessTracker) list(validator *types.Address) []*validatorLiveness {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.listLocked(validator)
+}
+
+func (l *livenessTracker) listLocked(validator *types.Address) []*validatorLiveness {
+	res := []*validatorLiveness{}
+	if len(l.blocks) == 0 {
+		return res
+	}
+	for _, addr := range l.blocks[len(l.blocks)-1].validators {
+		if validator != nil && addr != *validator {
+			continue
+		}
+		v := &validatorLiveness{validator: addr}
+		for _, block := range l.blocks {
+			if !block.validators.Includes(addr) {
+				continue
+			}
+			v.blocks++
+			if _, ok := block.sealers[addr]; ok {
+				v.sealed++
+				v.lastSealed = block.number
+			}
+		}
+		_, v.alert = l.alerts[addr]
+		res = append(res, v)
+	}
+	return res
+}
+
+// observeLiveness records the committed seals of the header in the liveness
+func (i *Ibft) observeLiveness(header *types.Header, validators ValidatorSet) {
+	if i.liveness == nil {
+		// the snapshots are processed before the engine is set up
+		return
+	}
+	// the old blocks are not in the window when the snapshot is rebuilt
+	if head := i.blockchain.Header(); head != nil && header.Number+i.liveness.window <= head.Number {
+		return
+	}
+	sealers, err := committedSealers(header)
+	if err != nil {
+		i.logger.Debug("failed to read the committed seals", "number", header.Number, "err", err)
+		return
+	}
+	i.liveness.observe(header.Number, validators, sealers)
+}
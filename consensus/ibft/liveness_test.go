@@ -0,0 +1,134 @@
+package ibft
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness_Observe(t *testing.T) {
+	m, err := newMetrics(nil)
+	assert.NoError(t, err)
+
+	a, b, c := types.StringToAddress("1"), types.StringToAddress("2"), types.StringToAddress("3")
+	validators := ValidatorSet{a, b, c}
+
+	l := newLivenessTracker(4, 50, m, hclog.NewNullLogger())
+
+	// b misses every block but the alert waits for the full window
+	for number := uint64(1); number <= 3; number++ {
+		l.observe(number, validators, []types.Address{a, c})
+		assert.False(t, l.list(&b)[0].alert)
+	}
+	l.observe(4, validators, []types.Address{a, c})
+
+	live := l.list(&b)[0]
+	assert.True(t, live.alert)
+	assert.Equal(t, uint64(4), live.blocks)
+	assert.Equal(t, uint64(0), live.sealed)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.liveness.WithLabelValues(b.String())))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.liveness.WithLabelValues(a.String())))
+
+	// the window only keeps the last blocks
+	l.observe(5, validators, []types.Address{a, b, c})
+	l.observe(6, validators, []types.Address{a, b, c})
+
+	live = l.list(&b)[0]
+	assert.False(t, live.alert)
+	assert.Equal(t, uint64(2), live.sealed)
+	assert.Equal(t, uint64(6), live.lastSealed)
+	assert.Equal(t, 0.5, live.participation())
+
+	// a block at the same height replaces the blocks after a reorg
+	l.observe(6, validators, []types.Address{a, c})
+	live = l.list(&b)[0]
+	assert.True(t, live.alert)
+	assert.Equal(t, uint64(5), live.lastSealed)
+
+	// the removed validators are not tracked
+	l.observe(7, ValidatorSet{a, c}, []types.Address{a, c})
+	assert.Len(t, l.list(nil), 2)
+	assert.Len(t, l.list(&b), 0)
+	assert.Equal(t, 2, testutil.CollectAndCount(m.liveness))
+}
+
+func TestLiveness_Config(t *testing.T) {
+	cases := []struct {
+		config    map[string]interface{}
+		window    uint64
+		threshold uint64
+		err       bool
+	}{
+		{
+			config:    map[string]interface{}{},
+			window:    defaultLivenessWindow,
+			threshold: defaultLivenessThreshold,
+		},
+		{
+			config:    map[string]interface{}{"livenessWindow": float64(10), "livenessThreshold": float64(20)},
+			window:    10,
+			threshold: 20,
+		},
+		{
+			config: map[string]interface{}{"livenessThreshold": float64(101)},
+			err:    true,
+		},
+	}
+
+	for _, c := range cases {
+		i := &Ibft{
+			config: &consensus.Config{Config: c.config},
+		}
+		err := i.setupConfig()
+		if c.err {
+			assert.Error(t, err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, c.window, i.livenessWindow)
+		assert.Equal(t, c.threshold, i.livenessThreshold)
+	}
+}
+
+func TestLiveness_Operator(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "A")
+
+	// the committed seals of the block are recovered
+	block := i.DummyBlock()
+	block.Header.Number = 1
+	var seals [][]byte
+	for _, name := range []string{"A", "C"} {
+		seal, err := writeCommittedSeal(NewLocalSigner(i.pool.get(name).priv), block.Header)
+		assert.NoError(t, err)
+		seals = append(seals, seal)
+	}
+	header, err := writeCommittedSeals(block.Header, seals)
+	assert.NoError(t, err)
+	i.observeLiveness(header, i.pool.ValidatorSet())
+
+	o := &operator{ibft: i.Ibft}
+	resp, err := o.GetLiveness(context.Background(), &proto.LivenessReq{
+		Validator: i.pool.get("B").Address().String(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(defaultLivenessWindow), resp.Window)
+	assert.Len(t, resp.Validators, 1)
+	assert.Equal(t, uint64(1), resp.Validators[0].Blocks)
+	assert.Equal(t, uint64(0), resp.Validators[0].Sealed)
+
+	resp, err = o.GetLiveness(context.Background(), &proto.LivenessReq{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Validators, 3)
+	for _, v := range resp.Validators {
+		if v.Validator == i.pool.get("B").Address().String() {
+			continue
+		}
+		assert.Equal(t, uint64(1), v.LastSealed)
+	}
+}
@@ -0,0 +1,176 @@
+package ibft
+
+import (
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reasons of the invalid messages
+const (
+	invalidSignature  = "signature"
+	invalidValidator  = "non_validator"
+	invalidProposer   = "wrong_proposer"
+	invalidProposal   = "invalid_block"
+	invalidLockedHash = "locked_block"
+)
+
+// metrics are the metrics of the consensus, an operator can alert
+// on the rounds and the participation before the chain halts
+type metrics struct {
+	sequence        prometheus.Gauge
+	round           prometheus.Gauge
+	roundsPerBlock  prometheus.Histogram
+	roundChanges    prometheus.Counter
+	watchdog        prometheus.Counter
+	proposalLatency prometheus.Histogram
+	commitLatency   prometheus.Histogram
+	participation   prometheus.Gauge
+	seals           *prometheus.CounterVec
+	liveness        *prometheus.GaugeVec
+	invalidMsgs     *prometheus.CounterVec
+
+	// start of the current round and sequence
+	roundStart    time.Time
+	sequenceStart time.Time
+	lastSequence  uint64
+}
+
+// newMetrics returns the metrics of the consensus, they are
+// only exported if there is a registerer
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		sequence: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "sequence",
+			Help:      "Current sequence of the consensus",
+		}),
+		round: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "round",
+			Help:      "Current round of the consensus",
+		}),
+		roundsPerBlock: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "rounds_per_block",
+			Help:      "Number of rounds to commit a block",
+			Buckets:   prometheus.LinearBuckets(1, 1, 10),
+		}),
+		roundChanges: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "round_changes_total",
+			Help:      "Number of round changes sent by the node",
+		}),
+		watchdog: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "watchdog_recoveries_total",
+			Help:      "Number of resyncs of the watchdog of the round changes",
+		}),
+		proposalLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "proposal_duration_seconds",
+			Help:      "Time since the start of the round until there is a proposal",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		commitLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "commit_duration_seconds",
+			Help:      "Time since the start of the sequence until the block is committed",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		participation: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "participation_ratio",
+			Help:      "Ratio of the validators with a committed seal in the last block",
+		}),
+		seals: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "committed_seals_total",
+			Help:      "Number of committed seals of each validator in the blocks of the node",
+		}, []string{"validator"}),
+		liveness: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "validator_liveness_ratio",
+			Help:      "Ratio of the blocks of the liveness window with a committed seal of each validator",
+		}, []string{"validator"}),
+		invalidMsgs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "ibft",
+			Name:      "invalid_messages_total",
+			Help:      "Number of consensus messages that failed the validation",
+		}, []string{"reason"}),
+	}
+	if registerer == nil {
+		return m, nil
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.sequence,
+		m.round,
+		m.roundsPerBlock,
+		m.roundChanges,
+		m.watchdog,
+		m.proposalLatency,
+		m.commitLatency,
+		m.participation,
+		m.seals,
+		m.liveness,
+		m.invalidMsgs,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *metrics) setView(view *proto.View) {
+	m.sequence.Set(float64(view.Sequence))
+	m.round.Set(float64(view.Round))
+}
+
+// startRound records the start of the round of the view
+func (m *metrics) startRound(view *proto.View) {
+	now := time.Now()
+	if view.Sequence != m.lastSequence {
+		m.lastSequence = view.Sequence
+		m.sequenceStart = now
+	}
+	m.roundStart = now
+	m.setView(view)
+}
+
+// proposed records the latency of the proposal of the round
+func (m *metrics) proposed() {
+	m.proposalLatency.Observe(time.Since(m.roundStart).Seconds())
+}
+
+// committed records the metrics of a block committed in the round
+// with the seals of the sealers
+func (m *metrics) committed(round uint64, sealers []types.Address, validators int) {
+	m.roundsPerBlock.Observe(float64(round + 1))
+	m.commitLatency.Observe(time.Since(m.sequenceStart).Seconds())
+
+	for _, addr := range sealers {
+		m.seals.WithLabelValues(addr.String()).Inc()
+	}
+	if validators != 0 {
+		m.participation.Set(float64(len(sealers)) / float64(validators))
+	}
+}
+
+func (m *metrics) invalidMsg(reason string) {
+	m.invalidMsgs.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,73 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Register(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	_, err := newMetrics(registry)
+	assert.NoError(t, err)
+
+	// the metrics of a second engine cannot be registered
+	_, err = newMetrics(registry)
+	assert.Error(t, err)
+}
+
+func TestMetrics_InvalidMessages(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C"}, "B")
+	i.state.view = proto.ViewMsg(1, 0)
+	i.setState(AcceptState)
+
+	// C is not the proposer
+	i.emitMsg(&proto.MessageReq{
+		From: "C",
+		Type: proto.MessageReq_Preprepare,
+		Proposal: &any.Any{
+			Value: i.DummyBlock().MarshalRLP(),
+		},
+		View: proto.ViewMsg(1, 0),
+	})
+
+	// X is not a validator
+	i.pool.add("X")
+	i.emitMsg(&proto.MessageReq{
+		From: "X",
+		Type: proto.MessageReq_Preprepare,
+		View: proto.ViewMsg(1, 0),
+	})
+	i.forceTimeout()
+
+	i.runCycle()
+
+	invalid := func(reason string) float64 {
+		return testutil.ToFloat64(i.metrics.invalidMsgs.WithLabelValues(reason))
+	}
+	assert.Equal(t, float64(1), invalid(invalidProposer))
+	assert.Equal(t, float64(1), invalid(invalidValidator))
+	assert.Equal(t, float64(1), testutil.ToFloat64(i.metrics.sequence))
+}
+
+func TestMetrics_Committed(t *testing.T) {
+	m, err := newMetrics(nil)
+	assert.NoError(t, err)
+
+	m.startRound(proto.ViewMsg(1, 0))
+	m.startRound(proto.ViewMsg(1, 2))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.round))
+
+	a, b := types.StringToAddress("1"), types.StringToAddress("2")
+	m.committed(2, []types.Address{a, b}, 4)
+
+	assert.Equal(t, 0.5, testutil.ToFloat64(m.participation))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.seals.WithLabelValues(a.String())))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.roundsPerBlock))
+}
@@ -21,11 +21,63 @@ type operator struct {
 
 func (o *operator) Status(ctx context.Context, req *empty.Empty) (*proto.IbftStatusResp, error) {
 	resp := &proto.IbftStatusResp{
-		Key: o.ibft.validatorKeyAddr.String(),
+		Key:    o.ibft.validatorKeyAddr.String(),
+		Source: o.ibft.validatorSource(),
+	}
+	if o.ibft.validatorContract != nil {
+		resp.Contract = o.ibft.validatorContract.String()
 	}
 	return resp, nil
 }
 
+// StreamStatus implements the IbftOperator service
+func (o *operator) StreamStatus(req *empty.Empty, stream proto.IbftOperator_StreamStatusServer) error {
+	ch, cancel := o.ibft.statusStream.subscribe()
+	defer cancel()
+
+	for {
+		select {
+		case status := <-ch:
+			if err := stream.Send(status); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// RoundChange implements the IbftOperator service
+func (o *operator) RoundChange(ctx context.Context, req *proto.RoundChangeReq) (*proto.RoundChangeResp, error) {
+	if !o.ibft.isSealing() {
+		return nil, fmt.Errorf("the node is not sealing")
+	}
+	if o.ibft.isState(SyncState) {
+		return nil, fmt.Errorf("the node is syncing")
+	}
+
+	roundChange := &roundChangeReq{
+		resync:  req.Resync,
+		replyCh: make(chan *proto.RoundChangeResp, 1),
+	}
+	select {
+	case o.ibft.roundChangeCh <- roundChange:
+	case <-o.ibft.closeCh:
+		return nil, fmt.Errorf("the node is closing")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case resp := <-roundChange.replyCh:
+		return resp, nil
+	case <-o.ibft.closeCh:
+		return nil, fmt.Errorf("the node is closing")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 func (o *operator) getNextCandidate(snap *Snapshot) *proto.Candidate {
 	o.candidatesLock.Lock()
 	defer o.candidatesLock.Unlock()
@@ -90,6 +142,10 @@ func (o *operator) Propose(ctx context.Context, req *proto.Candidate) (*empty.Em
 		return nil, err
 	}
 
+	if !o.ibft.votesEnabled() {
+		return nil, fmt.Errorf("the validators are selected by the validators contract in %s", o.ibft.mechanism)
+	}
+
 	// check if the candidate is already there
 	o.candidatesLock.Lock()
 	defer o.candidatesLock.Unlock()
@@ -128,6 +184,26 @@ func (o *operator) Propose(ctx context.Context, req *proto.Candidate) (*empty.Em
 	return &empty.Empty{}, nil
 }
 
+// Unvote removes a pending candidate, the votes already included
+// in a block are not reverted
+func (o *operator) Unvote(ctx context.Context, req *proto.UnvoteReq) (*empty.Empty, error) {
+	var addr types.Address
+	if err := addr.UnmarshalText([]byte(req.Address)); err != nil {
+		return nil, err
+	}
+
+	o.candidatesLock.Lock()
+	defer o.candidatesLock.Unlock()
+
+	for indx, c := range o.candidates {
+		if types.StringToAddress(c.Address) == addr {
+			o.candidates = append(o.candidates[:indx], o.candidates[indx+1:]...)
+			return &empty.Empty{}, nil
+		}
+	}
+	return nil, fmt.Errorf("not a candidate")
+}
+
 func (o *operator) Candidates(ctx context.Context, req *empty.Empty) (*proto.CandidatesResp, error) {
 	o.candidatesLock.Lock()
 	defer o.candidatesLock.Unlock()
@@ -138,3 +214,53 @@ func (o *operator) Candidates(ctx context.Context, req *empty.Empty) (*proto.Can
 	resp.Candidates = append(resp.Candidates, o.candidates...)
 	return resp, nil
 }
+
+// GetEvidence implements the IbftOperator service
+func (o *operator) GetEvidence(ctx context.Context, req *proto.EvidenceReq) (*proto.EvidenceResp, error) {
+	var validator *types.Address
+	if req.Validator != "" {
+		addr := types.StringToAddress(req.Validator)
+		validator = &addr
+	}
+
+	resp := &proto.EvidenceResp{}
+	for _, evidence := range o.ibft.evidence.list(validator) {
+		resp.Evidence = append(resp.Evidence, evidence.toProto())
+	}
+	return resp, nil
+}
+
+// GetLiveness implements the IbftOperator service
+func (o *operator) GetLiveness(ctx context.Context, req *proto.LivenessReq) (*proto.LivenessResp, error) {
+	var validator *types.Address
+	if req.Validator != "" {
+		addr := types.StringToAddress(req.Validator)
+		validator = &addr
+	}
+
+	liveness := o.ibft.liveness
+	resp := &proto.LivenessResp{
+		Window:    liveness.window,
+		Threshold: liveness.threshold,
+	}
+	for _, v := range liveness.list(validator) {
+		resp.Validators = append(resp.Validators, &proto.ValidatorLiveness{
+			Validator:     v.validator.String(),
+			Blocks:        v.blocks,
+			Sealed:        v.sealed,
+			LastSealed:    v.lastSealed,
+			Participation: v.participation(),
+			Alert:         v.alert,
+		})
+	}
+	return resp, nil
+}
+
+// GetValidators implements the IbftOperator service
+func (o *operator) GetValidators(ctx context.Context, req *proto.ValidatorsReq) (*proto.ValidatorsResp, error) {
+	validators, err := o.ibft.ValidatorsAt(req.Number)
+	if err != nil {
+		return nil, err
+	}
+	return validators.toProto(), nil
+}
@@ -123,3 +123,104 @@ func TestOperator_Propose(t *testing.T) {
 	})
 	assert.Error(t, err)
 }
+
+func TestOperator_RoundChange(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "B")
+	i.sealing = true
+	i.setState(ValidateState)
+
+	doneCh := make(chan struct{})
+	go func() {
+		// the forced round change moves the validation to round change state
+		// which sends the round change for the next round
+		i.runCycle()
+		i.runCycle()
+		close(doneCh)
+	}()
+
+	o := &operator{ibft: i.Ibft}
+	resp, err := o.RoundChange(context.Background(), &proto.RoundChangeReq{})
+	assert.NoError(t, err)
+	assert.Equal(t, RoundChangeState.String(), resp.State)
+	assert.Equal(t, uint64(1), resp.Sequence)
+	assert.Equal(t, uint64(1), resp.Round)
+
+	i.Close()
+	<-doneCh
+
+	i.expect(expectResult{
+		sequence: 1,
+		round:    1,
+		state:    RoundChangeState,
+		outgoing: 1,
+	})
+}
+
+func TestOperator_RoundChange_Resync(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "B")
+	i.sealing = true
+	i.setState(ValidateState)
+	i.state.block = i.DummyBlock()
+	i.state.lock()
+
+	doneCh := make(chan struct{})
+	go func() {
+		i.runCycle()
+		close(doneCh)
+	}()
+
+	o := &operator{ibft: i.Ibft}
+	resp, err := o.RoundChange(context.Background(), &proto.RoundChangeReq{Resync: true})
+	assert.NoError(t, err)
+	assert.Equal(t, SyncState.String(), resp.State)
+	assert.Equal(t, uint64(1), resp.Sequence)
+	<-doneCh
+
+	// the state machine is unlocked
+	i.expect(expectResult{
+		sequence: 1,
+		state:    SyncState,
+	})
+	assert.Nil(t, i.state.block)
+
+	// it is not possible to force a round change while syncing
+	_, err = o.RoundChange(context.Background(), &proto.RoundChangeReq{})
+	assert.Error(t, err)
+}
+
+func TestOperator_Unvote(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C")
+
+	ibft := &Ibft{
+		blockchain: blockchain.TestBlockchain(t, pool.genesis()),
+		config:     &consensus.Config{},
+	}
+	assert.NoError(t, ibft.setupSnapshot())
+
+	o := &operator{ibft: ibft}
+	pool.add("X")
+
+	_, err := o.Propose(context.Background(), &proto.Candidate{
+		Address: pool.get("X").Address().String(),
+		Auth:    true,
+	})
+	assert.NoError(t, err)
+
+	// we cannot withdraw a vote that was not proposed
+	_, err = o.Unvote(context.Background(), &proto.UnvoteReq{
+		Address: pool.get("A").Address().String(),
+	})
+	assert.Error(t, err)
+
+	_, err = o.Unvote(context.Background(), &proto.UnvoteReq{
+		Address: pool.get("X").Address().String(),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, o.candidates, 0)
+
+	// the candidate is not voted anymore
+	snap, err := ibft.getLatestSnapshot()
+	assert.NoError(t, err)
+	assert.Nil(t, o.getNextCandidate(snap))
+}
@@ -0,0 +1,79 @@
+package ibft
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// ProposerPolicy selects the proposer of the rounds of a block. All the
+// validators have to use the same policy to agree on the proposer
+type ProposerPolicy interface {
+	// CalcProposer returns the proposer of the round of the child of the parent,
+	// lastProposer is the proposer of the parent or the zero address at genesis
+	CalcProposer(validators ValidatorSet, weights Weights, round uint64, lastProposer types.Address, parent *types.Header) types.Address
+}
+
+const (
+	// RoundRobin moves to the next validator on every block and round
+	RoundRobin = "roundrobin"
+
+	// Sticky keeps the proposer of the last block until there is a round change
+	Sticky = "sticky"
+
+	// Random picks the proposer with a seed from the parent hash, the validators
+	// are picked with a probability proportional to their weight
+	Random = "random"
+)
+
+var proposerPolicies = map[string]ProposerPolicy{
+	RoundRobin: &roundRobinPolicy{},
+	Sticky:     &stickyPolicy{},
+	Random:     &randomPolicy{},
+}
+
+func getProposerPolicy(name string) (ProposerPolicy, error) {
+	policy, ok := proposerPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("proposer policy must be %s, %s or %s", RoundRobin, Sticky, Random)
+	}
+	return policy, nil
+}
+
+type roundRobinPolicy struct{}
+
+func (r *roundRobinPolicy) CalcProposer(validators ValidatorSet, weights Weights, round uint64, lastProposer types.Address, parent *types.Header) types.Address {
+	return validators.CalcProposer(round, lastProposer)
+}
+
+type stickyPolicy struct{}
+
+func (s *stickyPolicy) CalcProposer(validators ValidatorSet, weights Weights, round uint64, lastProposer types.Address, parent *types.Header) types.Address {
+	seed := round
+	if indx := validators.Index(lastProposer); indx != -1 {
+		seed += uint64(indx)
+	}
+	return validators[seed%uint64(validators.Len())]
+}
+
+type randomPolicy struct{}
+
+func (r *randomPolicy) CalcProposer(validators ValidatorSet, weights Weights, round uint64, lastProposer types.Address, parent *types.Header) types.Address {
+	// every round has a different seed to move on from a faulty proposer
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, round)
+	seed := binary.BigEndian.Uint64(crypto.Keccak256(parent.Hash.Bytes(), buf)[:8])
+
+	// pick the validator that holds the position of the seed in the weights
+	pick := seed % validators.TotalWeight(weights)
+	for _, addr := range validators {
+		weight := weights.Get(addr)
+		if pick < weight {
+			return addr
+		}
+		pick -= weight
+	}
+	return validators[len(validators)-1]
+}
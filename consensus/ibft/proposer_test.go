@@ -0,0 +1,59 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposerPolicy(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C", "D")
+
+	vals := pool.ValidatorSet()
+	addr := func(name string) types.Address {
+		return pool.get(name).Address()
+	}
+	parent := &types.Header{Hash: types.StringToHash("1")}
+
+	calc := func(name string, round uint64, lastProposer types.Address) types.Address {
+		policy, err := getProposerPolicy(name)
+		assert.NoError(t, err)
+		return policy.CalcProposer(vals, nil, round, lastProposer, parent)
+	}
+
+	// the round robin moves on every block
+	assert.Equal(t, addr("C"), calc(RoundRobin, 0, addr("B")))
+	assert.Equal(t, addr("D"), calc(RoundRobin, 1, addr("B")))
+
+	// the sticky proposer only changes with the rounds
+	assert.Equal(t, addr("A"), calc(Sticky, 0, types.ZeroAddress))
+	assert.Equal(t, addr("B"), calc(Sticky, 0, addr("B")))
+	assert.Equal(t, addr("C"), calc(Sticky, 1, addr("B")))
+
+	// the random proposer only depends on the parent and the round
+	assert.Equal(t, calc(Random, 0, addr("A")), calc(Random, 0, addr("B")))
+
+	_, err := getProposerPolicy("unknown")
+	assert.Error(t, err)
+}
+
+func TestProposerPolicy_RandomWeights(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B")
+
+	vals := pool.ValidatorSet()
+	weights := Weights{pool.get("A").Address(): 9}
+
+	// A proposes most of the blocks
+	count := 0
+	for i := 0; i < 100; i++ {
+		parent := &types.Header{Hash: types.BytesToHash([]byte{byte(i)})}
+		if (&randomPolicy{}).CalcProposer(vals, weights, 0, types.ZeroAddress, parent) == pool.get("A").Address() {
+			count++
+		}
+	}
+	assert.True(t, count > 70)
+	assert.True(t, count < 100)
+}
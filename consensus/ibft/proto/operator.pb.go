@@ -26,12 +26,65 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+type IbftStatusResp_ValidatorSource int32
+
+const (
+	IbftStatusResp_VOTES              IbftStatusResp_ValidatorSource = 0
+	IbftStatusResp_CONTRACT           IbftStatusResp_ValidatorSource = 1
+	IbftStatusResp_VOTES_AND_CONTRACT IbftStatusResp_ValidatorSource = 2
+)
+
+// Enum value maps for IbftStatusResp_ValidatorSource.
+var (
+	IbftStatusResp_ValidatorSource_name = map[int32]string{
+		0: "VOTES",
+		1: "CONTRACT",
+		2: "VOTES_AND_CONTRACT",
+	}
+	IbftStatusResp_ValidatorSource_value = map[string]int32{
+		"VOTES":              0,
+		"CONTRACT":           1,
+		"VOTES_AND_CONTRACT": 2,
+	}
+)
+
+func (x IbftStatusResp_ValidatorSource) Enum() *IbftStatusResp_ValidatorSource {
+	p := new(IbftStatusResp_ValidatorSource)
+	*p = x
+	return p
+}
+
+func (x IbftStatusResp_ValidatorSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IbftStatusResp_ValidatorSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_consensus_ibft_proto_operator_proto_enumTypes[0].Descriptor()
+}
+
+func (IbftStatusResp_ValidatorSource) Type() protoreflect.EnumType {
+	return &file_consensus_ibft_proto_operator_proto_enumTypes[0]
+}
+
+func (x IbftStatusResp_ValidatorSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IbftStatusResp_ValidatorSource.Descriptor instead.
+func (IbftStatusResp_ValidatorSource) EnumDescriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{0, 0}
+}
+
 type IbftStatusResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// source of the validator set
+	Source IbftStatusResp_ValidatorSource `protobuf:"varint,2,opt,name=source,proto3,enum=v1.IbftStatusResp_ValidatorSource" json:"source,omitempty"`
+	// address of the contract with the validators, if any
+	Contract string `protobuf:"bytes,3,opt,name=contract,proto3" json:"contract,omitempty"`
 }
 
 func (x *IbftStatusResp) Reset() {
@@ -73,6 +126,238 @@ func (x *IbftStatusResp) GetKey() string {
 	return ""
 }
 
+func (x *IbftStatusResp) GetSource() IbftStatusResp_ValidatorSource {
+	if x != nil {
+		return x.Source
+	}
+	return IbftStatusResp_VOTES
+}
+
+func (x *IbftStatusResp) GetContract() string {
+	if x != nil {
+		return x.Contract
+	}
+	return ""
+}
+
+// IbftConsensusStatus is the state of the consensus of the node
+type IbftConsensusStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State    string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Round    uint64 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+	Proposer string `protobuf:"bytes,4,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	// number of messages received in the current view
+	Prepared     uint64 `protobuf:"varint,5,opt,name=prepared,proto3" json:"prepared,omitempty"`
+	Committed    uint64 `protobuf:"varint,6,opt,name=committed,proto3" json:"committed,omitempty"`
+	RoundChanges uint64 `protobuf:"varint,7,opt,name=round_changes,json=roundChanges,proto3" json:"round_changes,omitempty"`
+	// hash of the locked block, empty if the node is not locked
+	LockedBlock string `protobuf:"bytes,8,opt,name=locked_block,json=lockedBlock,proto3" json:"locked_block,omitempty"`
+}
+
+func (x *IbftConsensusStatus) Reset() {
+	*x = IbftConsensusStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IbftConsensusStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IbftConsensusStatus) ProtoMessage() {}
+
+func (x *IbftConsensusStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IbftConsensusStatus.ProtoReflect.Descriptor instead.
+func (*IbftConsensusStatus) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *IbftConsensusStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *IbftConsensusStatus) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *IbftConsensusStatus) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *IbftConsensusStatus) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *IbftConsensusStatus) GetPrepared() uint64 {
+	if x != nil {
+		return x.Prepared
+	}
+	return 0
+}
+
+func (x *IbftConsensusStatus) GetCommitted() uint64 {
+	if x != nil {
+		return x.Committed
+	}
+	return 0
+}
+
+func (x *IbftConsensusStatus) GetRoundChanges() uint64 {
+	if x != nil {
+		return x.RoundChanges
+	}
+	return 0
+}
+
+func (x *IbftConsensusStatus) GetLockedBlock() string {
+	if x != nil {
+		return x.LockedBlock
+	}
+	return ""
+}
+
+type RoundChangeReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// resync resets the state machine and syncs again with the peers
+	// instead of moving to the next round
+	Resync bool `protobuf:"varint,1,opt,name=resync,proto3" json:"resync,omitempty"`
+}
+
+func (x *RoundChangeReq) Reset() {
+	*x = RoundChangeReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundChangeReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundChangeReq) ProtoMessage() {}
+
+func (x *RoundChangeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundChangeReq.ProtoReflect.Descriptor instead.
+func (*RoundChangeReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RoundChangeReq) GetResync() bool {
+	if x != nil {
+		return x.Resync
+	}
+	return false
+}
+
+type RoundChangeResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State    string `protobuf:"bytes,1,opt,name=state,proto3" json:"state,omitempty"`
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Round    uint64 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+}
+
+func (x *RoundChangeResp) Reset() {
+	*x = RoundChangeResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoundChangeResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundChangeResp) ProtoMessage() {}
+
+func (x *RoundChangeResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundChangeResp.ProtoReflect.Descriptor instead.
+func (*RoundChangeResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RoundChangeResp) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *RoundChangeResp) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *RoundChangeResp) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
 type SnapshotReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -85,7 +370,7 @@ type SnapshotReq struct {
 func (x *SnapshotReq) Reset() {
 	*x = SnapshotReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[1]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -98,7 +383,7 @@ func (x *SnapshotReq) String() string {
 func (*SnapshotReq) ProtoMessage() {}
 
 func (x *SnapshotReq) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[1]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -111,7 +396,7 @@ func (x *SnapshotReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotReq.ProtoReflect.Descriptor instead.
 func (*SnapshotReq) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{1}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SnapshotReq) GetLatest() bool {
@@ -142,7 +427,7 @@ type Snapshot struct {
 func (x *Snapshot) Reset() {
 	*x = Snapshot{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[2]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -155,7 +440,7 @@ func (x *Snapshot) String() string {
 func (*Snapshot) ProtoMessage() {}
 
 func (x *Snapshot) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[2]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -168,7 +453,7 @@ func (x *Snapshot) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
 func (*Snapshot) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{2}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Snapshot) GetValidators() []*Snapshot_Validator {
@@ -211,7 +496,7 @@ type ProposeReq struct {
 func (x *ProposeReq) Reset() {
 	*x = ProposeReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[3]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -224,7 +509,7 @@ func (x *ProposeReq) String() string {
 func (*ProposeReq) ProtoMessage() {}
 
 func (x *ProposeReq) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[3]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -237,7 +522,7 @@ func (x *ProposeReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProposeReq.ProtoReflect.Descriptor instead.
 func (*ProposeReq) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{3}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ProposeReq) GetAddress() string {
@@ -254,6 +539,53 @@ func (x *ProposeReq) GetAuth() bool {
 	return false
 }
 
+type UnvoteReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *UnvoteReq) Reset() {
+	*x = UnvoteReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnvoteReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnvoteReq) ProtoMessage() {}
+
+func (x *UnvoteReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnvoteReq.ProtoReflect.Descriptor instead.
+func (*UnvoteReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UnvoteReq) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
 type CandidatesResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -265,7 +597,7 @@ type CandidatesResp struct {
 func (x *CandidatesResp) Reset() {
 	*x = CandidatesResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[4]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -278,7 +610,7 @@ func (x *CandidatesResp) String() string {
 func (*CandidatesResp) ProtoMessage() {}
 
 func (x *CandidatesResp) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[4]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -291,7 +623,7 @@ func (x *CandidatesResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CandidatesResp.ProtoReflect.Descriptor instead.
 func (*CandidatesResp) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{4}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CandidatesResp) GetCandidates() []*Candidate {
@@ -313,7 +645,7 @@ type Candidate struct {
 func (x *Candidate) Reset() {
 	*x = Candidate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[5]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -326,7 +658,7 @@ func (x *Candidate) String() string {
 func (*Candidate) ProtoMessage() {}
 
 func (x *Candidate) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[5]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -339,7 +671,7 @@ func (x *Candidate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Candidate.ProtoReflect.Descriptor instead.
 func (*Candidate) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{5}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *Candidate) GetAddress() string {
@@ -356,31 +688,32 @@ func (x *Candidate) GetAuth() bool {
 	return false
 }
 
-type Snapshot_Validator struct {
+type EvidenceReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// validator filters the evidence of a validator, all if empty
+	Validator string `protobuf:"bytes,1,opt,name=validator,proto3" json:"validator,omitempty"`
 }
 
-func (x *Snapshot_Validator) Reset() {
-	*x = Snapshot_Validator{}
+func (x *EvidenceReq) Reset() {
+	*x = EvidenceReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[6]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Snapshot_Validator) String() string {
+func (x *EvidenceReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Snapshot_Validator) ProtoMessage() {}
+func (*EvidenceReq) ProtoMessage() {}
 
-func (x *Snapshot_Validator) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[6]
+func (x *EvidenceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -391,18 +724,572 @@ func (x *Snapshot_Validator) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Snapshot_Validator.ProtoReflect.Descriptor instead.
-func (*Snapshot_Validator) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{2, 0}
+// Deprecated: Use EvidenceReq.ProtoReflect.Descriptor instead.
+func (*EvidenceReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Snapshot_Validator) GetAddress() string {
+func (x *EvidenceReq) GetValidator() string {
+	if x != nil {
+		return x.Validator
+	}
+	return ""
+}
+
+type EvidenceResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Evidence []*Evidence `protobuf:"bytes,1,rep,name=evidence,proto3" json:"evidence,omitempty"`
+}
+
+func (x *EvidenceResp) Reset() {
+	*x = EvidenceResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvidenceResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvidenceResp) ProtoMessage() {}
+
+func (x *EvidenceResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvidenceResp.ProtoReflect.Descriptor instead.
+func (*EvidenceResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EvidenceResp) GetEvidence() []*Evidence {
+	if x != nil {
+		return x.Evidence
+	}
+	return nil
+}
+
+// Evidence are two conflicting messages signed by a validator in the same view
+type Evidence struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Validator string `protobuf:"bytes,1,opt,name=validator,proto3" json:"validator,omitempty"`
+	Sequence  uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Round     uint64 `protobuf:"varint,3,opt,name=round,proto3" json:"round,omitempty"`
+	Type      string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	// digests of the proposals or the committed seals of the messages
+	Digests []string `protobuf:"bytes,5,rep,name=digests,proto3" json:"digests,omitempty"`
+	// messages are the signed messages encoded with protobuf
+	Messages [][]byte `protobuf:"bytes,6,rep,name=messages,proto3" json:"messages,omitempty"`
+}
+
+func (x *Evidence) Reset() {
+	*x = Evidence{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Evidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Evidence) ProtoMessage() {}
+
+func (x *Evidence) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Evidence.ProtoReflect.Descriptor instead.
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Evidence) GetValidator() string {
+	if x != nil {
+		return x.Validator
+	}
+	return ""
+}
+
+func (x *Evidence) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Evidence) GetRound() uint64 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Evidence) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Evidence) GetDigests() []string {
+	if x != nil {
+		return x.Digests
+	}
+	return nil
+}
+
+func (x *Evidence) GetMessages() [][]byte {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type LivenessReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// validator filters the liveness of a validator, all if empty
+	Validator string `protobuf:"bytes,1,opt,name=validator,proto3" json:"validator,omitempty"`
+}
+
+func (x *LivenessReq) Reset() {
+	*x = LivenessReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LivenessReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LivenessReq) ProtoMessage() {}
+
+func (x *LivenessReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LivenessReq.ProtoReflect.Descriptor instead.
+func (*LivenessReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LivenessReq) GetValidator() string {
+	if x != nil {
+		return x.Validator
+	}
+	return ""
+}
+
+type LivenessResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// window is the number of recent blocks tracked
+	Window uint64 `protobuf:"varint,1,opt,name=window,proto3" json:"window,omitempty"`
+	// threshold is the percentage of missed blocks that raises an alert
+	Threshold  uint64               `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	Validators []*ValidatorLiveness `protobuf:"bytes,3,rep,name=validators,proto3" json:"validators,omitempty"`
+}
+
+func (x *LivenessResp) Reset() {
+	*x = LivenessResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LivenessResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LivenessResp) ProtoMessage() {}
+
+func (x *LivenessResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LivenessResp.ProtoReflect.Descriptor instead.
+func (*LivenessResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LivenessResp) GetWindow() uint64 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+func (x *LivenessResp) GetThreshold() uint64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *LivenessResp) GetValidators() []*ValidatorLiveness {
+	if x != nil {
+		return x.Validators
+	}
+	return nil
+}
+
+// ValidatorLiveness is the participation of a validator in the recent blocks
+type ValidatorLiveness struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Validator string `protobuf:"bytes,1,opt,name=validator,proto3" json:"validator,omitempty"`
+	// blocks of the window in which it was a validator and the
+	// number of them with its committed seal
+	Blocks uint64 `protobuf:"varint,2,opt,name=blocks,proto3" json:"blocks,omitempty"`
+	Sealed uint64 `protobuf:"varint,3,opt,name=sealed,proto3" json:"sealed,omitempty"`
+	// last block of the window with its committed seal, zero if none
+	LastSealed uint64 `protobuf:"varint,4,opt,name=last_sealed,json=lastSealed,proto3" json:"last_sealed,omitempty"`
+	// ratio of the blocks with its committed seal
+	Participation float64 `protobuf:"fixed64,5,opt,name=participation,proto3" json:"participation,omitempty"`
+	// alert is set while it misses more blocks than the threshold
+	Alert bool `protobuf:"varint,6,opt,name=alert,proto3" json:"alert,omitempty"`
+}
+
+func (x *ValidatorLiveness) Reset() {
+	*x = ValidatorLiveness{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidatorLiveness) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatorLiveness) ProtoMessage() {}
+
+func (x *ValidatorLiveness) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatorLiveness.ProtoReflect.Descriptor instead.
+func (*ValidatorLiveness) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ValidatorLiveness) GetValidator() string {
+	if x != nil {
+		return x.Validator
+	}
+	return ""
+}
+
+func (x *ValidatorLiveness) GetBlocks() uint64 {
+	if x != nil {
+		return x.Blocks
+	}
+	return 0
+}
+
+func (x *ValidatorLiveness) GetSealed() uint64 {
+	if x != nil {
+		return x.Sealed
+	}
+	return 0
+}
+
+func (x *ValidatorLiveness) GetLastSealed() uint64 {
+	if x != nil {
+		return x.LastSealed
+	}
+	return 0
+}
+
+func (x *ValidatorLiveness) GetParticipation() float64 {
+	if x != nil {
+		return x.Participation
+	}
+	return 0
+}
+
+func (x *ValidatorLiveness) GetAlert() bool {
+	if x != nil {
+		return x.Alert
+	}
+	return false
+}
+
+type ValidatorsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number uint64 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+}
+
+func (x *ValidatorsReq) Reset() {
+	*x = ValidatorsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidatorsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatorsReq) ProtoMessage() {}
+
+func (x *ValidatorsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatorsReq.ProtoReflect.Descriptor instead.
+func (*ValidatorsReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ValidatorsReq) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+// ValidatorsResp is the validator set effective at a block and the result of its votes
+type ValidatorsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number   uint64 `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Hash     string `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Proposer string `protobuf:"bytes,3,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	// validators that sealed the block
+	Validators []*Snapshot_Validator `protobuf:"bytes,4,rep,name=validators,proto3" json:"validators,omitempty"`
+	// vote cast by the proposer in the block, if any
+	Vote *Snapshot_Vote `protobuf:"bytes,5,opt,name=vote,proto3" json:"vote,omitempty"`
+	// validators added and removed by the block
+	Added   []string `protobuf:"bytes,6,rep,name=added,proto3" json:"added,omitempty"`
+	Removed []string `protobuf:"bytes,7,rep,name=removed,proto3" json:"removed,omitempty"`
+	// tallies of the pending proposals after the block
+	Tallies []*ValidatorsResp_Tally `protobuf:"bytes,8,rep,name=tallies,proto3" json:"tallies,omitempty"`
+}
+
+func (x *ValidatorsResp) Reset() {
+	*x = ValidatorsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidatorsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatorsResp) ProtoMessage() {}
+
+func (x *ValidatorsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatorsResp.ProtoReflect.Descriptor instead.
+func (*ValidatorsResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ValidatorsResp) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *ValidatorsResp) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *ValidatorsResp) GetProposer() string {
+	if x != nil {
+		return x.Proposer
+	}
+	return ""
+}
+
+func (x *ValidatorsResp) GetValidators() []*Snapshot_Validator {
+	if x != nil {
+		return x.Validators
+	}
+	return nil
+}
+
+func (x *ValidatorsResp) GetVote() *Snapshot_Vote {
+	if x != nil {
+		return x.Vote
+	}
+	return nil
+}
+
+func (x *ValidatorsResp) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *ValidatorsResp) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *ValidatorsResp) GetTallies() []*ValidatorsResp_Tally {
+	if x != nil {
+		return x.Tallies
+	}
+	return nil
+}
+
+type Snapshot_Validator struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// voting power of the validator
+	Weight uint64 `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+}
+
+func (x *Snapshot_Validator) Reset() {
+	*x = Snapshot_Validator{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Snapshot_Validator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot_Validator) ProtoMessage() {}
+
+func (x *Snapshot_Validator) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot_Validator.ProtoReflect.Descriptor instead.
+func (*Snapshot_Validator) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{5, 0}
+}
+
+func (x *Snapshot_Validator) GetAddress() string {
 	if x != nil {
 		return x.Address
 	}
 	return ""
 }
 
+func (x *Snapshot_Validator) GetWeight() uint64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
 type Snapshot_Vote struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -416,7 +1303,7 @@ type Snapshot_Vote struct {
 func (x *Snapshot_Vote) Reset() {
 	*x = Snapshot_Vote{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[7]
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -429,7 +1316,7 @@ func (x *Snapshot_Vote) String() string {
 func (*Snapshot_Vote) ProtoMessage() {}
 
 func (x *Snapshot_Vote) ProtoReflect() protoreflect.Message {
-	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[7]
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -442,7 +1329,7 @@ func (x *Snapshot_Vote) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Snapshot_Vote.ProtoReflect.Descriptor instead.
 func (*Snapshot_Vote) Descriptor() ([]byte, []int) {
-	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{2, 1}
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{5, 1}
 }
 
 func (x *Snapshot_Vote) GetValidator() string {
@@ -466,6 +1353,78 @@ func (x *Snapshot_Vote) GetAuth() bool {
 	return false
 }
 
+type ValidatorsResp_Tally struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Auth    bool   `protobuf:"varint,2,opt,name=auth,proto3" json:"auth,omitempty"`
+	Votes   uint64 `protobuf:"varint,3,opt,name=votes,proto3" json:"votes,omitempty"`
+	// votes required to pass the proposal
+	Required uint64 `protobuf:"varint,4,opt,name=required,proto3" json:"required,omitempty"`
+}
+
+func (x *ValidatorsResp_Tally) Reset() {
+	*x = ValidatorsResp_Tally{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_operator_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidatorsResp_Tally) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidatorsResp_Tally) ProtoMessage() {}
+
+func (x *ValidatorsResp_Tally) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_operator_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidatorsResp_Tally.ProtoReflect.Descriptor instead.
+func (*ValidatorsResp_Tally) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_operator_proto_rawDescGZIP(), []int{17, 0}
+}
+
+func (x *ValidatorsResp_Tally) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ValidatorsResp_Tally) GetAuth() bool {
+	if x != nil {
+		return x.Auth
+	}
+	return false
+}
+
+func (x *ValidatorsResp_Tally) GetVotes() uint64 {
+	if x != nil {
+		return x.Votes
+	}
+	return 0
+}
+
+func (x *ValidatorsResp_Tally) GetRequired() uint64 {
+	if x != nil {
+		return x.Required
+	}
+	return 0
+}
+
 var File_consensus_ibft_proto_operator_proto protoreflect.FileDescriptor
 
 var file_consensus_ibft_proto_operator_proto_rawDesc = []byte{
@@ -473,13 +1432,47 @@ var file_consensus_ibft_proto_operator_proto_rawDesc = []byte{
 	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x2e,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
 	0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x22, 0x0a, 0x0e, 0x49, 0x62, 0x66, 0x74, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x3d, 0x0a, 0x0b, 0x53, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xbe, 0x01, 0x0a, 0x0e, 0x49, 0x62, 0x66, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x3a, 0x0a, 0x06, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x22, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x62, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x2e,
+	0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x52,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x22, 0x42, 0x0a, 0x0f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x56, 0x4f, 0x54, 0x45, 0x53, 0x10,
+	0x00, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x4f, 0x4e, 0x54, 0x52, 0x41, 0x43, 0x54, 0x10, 0x01, 0x12,
+	0x16, 0x0a, 0x12, 0x56, 0x4f, 0x54, 0x45, 0x53, 0x5f, 0x41, 0x4e, 0x44, 0x5f, 0x43, 0x4f, 0x4e,
+	0x54, 0x52, 0x41, 0x43, 0x54, 0x10, 0x02, 0x22, 0xfb, 0x01, 0x0a, 0x13, 0x49, 0x62, 0x66, 0x74,
+	0x43, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f,
+	0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x12,
+	0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x22, 0x28, 0x0a, 0x0e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x79, 0x6e,
+	0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x22,
+	0x59, 0x0a, 0x0f, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75,
+	0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x22, 0x3d, 0x0a, 0x0b, 0x53, 0x6e,
 	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x74,
 	0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x61, 0x74, 0x65, 0x73,
 	0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x94, 0x02, 0x0a, 0x08, 0x53, 0x6e,
+	0x04, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0xac, 0x02, 0x0a, 0x08, 0x53, 0x6e,
 	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x36, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
 	0x74, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x76, 0x31, 0x2e,
 	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
@@ -489,40 +1482,131 @@ var file_consensus_ibft_proto_operator_proto_rawDesc = []byte{
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x27, 0x0a, 0x05, 0x76, 0x6f,
 	0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x76, 0x31, 0x2e, 0x53,
 	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x52, 0x05, 0x76, 0x6f,
-	0x74, 0x65, 0x73, 0x1a, 0x25, 0x0a, 0x09, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x74, 0x65, 0x73, 0x1a, 0x3d, 0x0a, 0x09, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x1a, 0x54, 0x0a, 0x04, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0x3a, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x61, 0x75, 0x74, 0x68, 0x22, 0x25, 0x0a, 0x09, 0x55, 0x6e, 0x76, 0x6f, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x3f, 0x0a, 0x0e, 0x43,
+	0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2d, 0x0a,
+	0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x22, 0x39, 0x0a, 0x09,
+	0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0x2b, 0x0a, 0x0b, 0x45, 0x76, 0x69, 0x64, 0x65,
+	0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x6f, 0x72, 0x22, 0x38, 0x0a, 0x0c, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x12, 0x28, 0x0a, 0x08, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x69, 0x64,
+	0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x65, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x22, 0xa4,
+	0x01, 0x0a, 0x08, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71,
+	0x75, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x08, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x73, 0x22, 0x2b, 0x0a, 0x0b, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x71, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
+	0x6f, 0x72, 0x22, 0x7b, 0x0a, 0x0c, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74,
+	0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x35, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x4c, 0x69, 0x76, 0x65, 0x6e,
+	0x65, 0x73, 0x73, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x22,
+	0xbe, 0x01, 0x0a, 0x11, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x4c, 0x69, 0x76,
+	0x65, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74,
+	0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x65, 0x61, 0x6c, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x61,
+	0x6c, 0x65, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x61, 0x6c,
+	0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x53, 0x65,
+	0x61, 0x6c, 0x65, 0x64, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c,
+	0x65, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x61, 0x6c, 0x65, 0x72, 0x74,
+	0x22, 0x27, 0x0a, 0x0d, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65,
+	0x71, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x22, 0x84, 0x03, 0x0a, 0x0e, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x16, 0x0a, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70,
+	0x6f, 0x73, 0x65, 0x72, 0x12, 0x36, 0x0a, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
+	0x52, 0x0a, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x25, 0x0a, 0x04,
+	0x76, 0x6f, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x56, 0x6f, 0x74, 0x65, 0x52, 0x04, 0x76,
+	0x6f, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x06, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x64, 0x64, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x64, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x07, 0x74, 0x61, 0x6c, 0x6c, 0x69, 0x65, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61,
+	0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x2e, 0x54, 0x61, 0x6c, 0x6c, 0x79, 0x52, 0x07,
+	0x74, 0x61, 0x6c, 0x6c, 0x69, 0x65, 0x73, 0x1a, 0x67, 0x0a, 0x05, 0x54, 0x61, 0x6c, 0x6c, 0x79,
 	0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x1a, 0x54, 0x0a, 0x04, 0x56, 0x6f,
-	0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f, 0x72,
-	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04,
-	0x61, 0x75, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68,
-	0x22, 0x3a, 0x0a, 0x0a, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x52, 0x65, 0x71, 0x12, 0x18,
-	0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0x3f, 0x0a, 0x0e,
-	0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x2d,
-	0x0a, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74,
-	0x65, 0x52, 0x0a, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x22, 0x39, 0x0a,
-	0x09, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
-	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
-	0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x32, 0xde, 0x01, 0x0a, 0x0c, 0x49, 0x62, 0x66,
-	0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x2c, 0x0a, 0x0b, 0x47, 0x65, 0x74,
-	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x0f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e,
-	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x53,
-	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x30, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f,
-	0x73, 0x65, 0x12, 0x0d, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74,
-	0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x38, 0x0a, 0x0a, 0x43, 0x61, 0x6e,
-	0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
-	0x12, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52,
-	0x65, 0x73, 0x70, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x62, 0x66, 0x74, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42, 0x17, 0x5a, 0x15, 0x2f, 0x63, 0x6f,
+	0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75,
+	0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x76,
+	0x6f, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64,
+	0x32, 0xa6, 0x04, 0x0a, 0x0c, 0x49, 0x62, 0x66, 0x74, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f,
+	0x72, 0x12, 0x2c, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x12, 0x0f, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x71, 0x1a, 0x0c, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12,
+	0x30, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x70, 0x6f, 0x73, 0x65, 0x12, 0x0d, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x12, 0x2f, 0x0a, 0x06, 0x55, 0x6e, 0x76, 0x6f, 0x74, 0x65, 0x12, 0x0d, 0x2e, 0x76, 0x31,
+	0x2e, 0x55, 0x6e, 0x76, 0x6f, 0x74, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x12, 0x38, 0x0a, 0x0a, 0x43, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73,
+	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x34, 0x0a, 0x06,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x12,
+	0x2e, 0x76, 0x31, 0x2e, 0x49, 0x62, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x41, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x17, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x62, 0x66, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x30, 0x01, 0x12, 0x36, 0x0a, 0x0b, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x75, 0x6e, 0x64, 0x43,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x13, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f,
+	0x75, 0x6e, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12, 0x30, 0x0a,
+	0x0b, 0x47, 0x65, 0x74, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x0f, 0x2e, 0x76,
+	0x31, 0x2e, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x71, 0x1a, 0x10, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x76, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x30, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x0f,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x1a,
+	0x10, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x36, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x12, 0x11, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x6f,
+	0x72, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x12, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42, 0x17, 0x5a, 0x15, 0x2f, 0x63, 0x6f,
 	0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x2f, 0x69, 0x62, 0x66, 0x74, 0x2f, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
@@ -539,35 +1623,68 @@ func file_consensus_ibft_proto_operator_proto_rawDescGZIP() []byte {
 	return file_consensus_ibft_proto_operator_proto_rawDescData
 }
 
-var file_consensus_ibft_proto_operator_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_consensus_ibft_proto_operator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_consensus_ibft_proto_operator_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
 var file_consensus_ibft_proto_operator_proto_goTypes = []interface{}{
-	(*IbftStatusResp)(nil),     // 0: v1.IbftStatusResp
-	(*SnapshotReq)(nil),        // 1: v1.SnapshotReq
-	(*Snapshot)(nil),           // 2: v1.Snapshot
-	(*ProposeReq)(nil),         // 3: v1.ProposeReq
-	(*CandidatesResp)(nil),     // 4: v1.CandidatesResp
-	(*Candidate)(nil),          // 5: v1.Candidate
-	(*Snapshot_Validator)(nil), // 6: v1.Snapshot.Validator
-	(*Snapshot_Vote)(nil),      // 7: v1.Snapshot.Vote
-	(*empty.Empty)(nil),        // 8: google.protobuf.Empty
+	(IbftStatusResp_ValidatorSource)(0), // 0: v1.IbftStatusResp.ValidatorSource
+	(*IbftStatusResp)(nil),              // 1: v1.IbftStatusResp
+	(*IbftConsensusStatus)(nil),         // 2: v1.IbftConsensusStatus
+	(*RoundChangeReq)(nil),              // 3: v1.RoundChangeReq
+	(*RoundChangeResp)(nil),             // 4: v1.RoundChangeResp
+	(*SnapshotReq)(nil),                 // 5: v1.SnapshotReq
+	(*Snapshot)(nil),                    // 6: v1.Snapshot
+	(*ProposeReq)(nil),                  // 7: v1.ProposeReq
+	(*UnvoteReq)(nil),                   // 8: v1.UnvoteReq
+	(*CandidatesResp)(nil),              // 9: v1.CandidatesResp
+	(*Candidate)(nil),                   // 10: v1.Candidate
+	(*EvidenceReq)(nil),                 // 11: v1.EvidenceReq
+	(*EvidenceResp)(nil),                // 12: v1.EvidenceResp
+	(*Evidence)(nil),                    // 13: v1.Evidence
+	(*LivenessReq)(nil),                 // 14: v1.LivenessReq
+	(*LivenessResp)(nil),                // 15: v1.LivenessResp
+	(*ValidatorLiveness)(nil),           // 16: v1.ValidatorLiveness
+	(*ValidatorsReq)(nil),               // 17: v1.ValidatorsReq
+	(*ValidatorsResp)(nil),              // 18: v1.ValidatorsResp
+	(*Snapshot_Validator)(nil),          // 19: v1.Snapshot.Validator
+	(*Snapshot_Vote)(nil),               // 20: v1.Snapshot.Vote
+	(*ValidatorsResp_Tally)(nil),        // 21: v1.ValidatorsResp.Tally
+	(*empty.Empty)(nil),                 // 22: google.protobuf.Empty
 }
 var file_consensus_ibft_proto_operator_proto_depIdxs = []int32{
-	6, // 0: v1.Snapshot.validators:type_name -> v1.Snapshot.Validator
-	7, // 1: v1.Snapshot.votes:type_name -> v1.Snapshot.Vote
-	5, // 2: v1.CandidatesResp.candidates:type_name -> v1.Candidate
-	1, // 3: v1.IbftOperator.GetSnapshot:input_type -> v1.SnapshotReq
-	5, // 4: v1.IbftOperator.Propose:input_type -> v1.Candidate
-	8, // 5: v1.IbftOperator.Candidates:input_type -> google.protobuf.Empty
-	8, // 6: v1.IbftOperator.Status:input_type -> google.protobuf.Empty
-	2, // 7: v1.IbftOperator.GetSnapshot:output_type -> v1.Snapshot
-	8, // 8: v1.IbftOperator.Propose:output_type -> google.protobuf.Empty
-	4, // 9: v1.IbftOperator.Candidates:output_type -> v1.CandidatesResp
-	0, // 10: v1.IbftOperator.Status:output_type -> v1.IbftStatusResp
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	0,  // 0: v1.IbftStatusResp.source:type_name -> v1.IbftStatusResp.ValidatorSource
+	19, // 1: v1.Snapshot.validators:type_name -> v1.Snapshot.Validator
+	20, // 2: v1.Snapshot.votes:type_name -> v1.Snapshot.Vote
+	10, // 3: v1.CandidatesResp.candidates:type_name -> v1.Candidate
+	13, // 4: v1.EvidenceResp.evidence:type_name -> v1.Evidence
+	16, // 5: v1.LivenessResp.validators:type_name -> v1.ValidatorLiveness
+	19, // 6: v1.ValidatorsResp.validators:type_name -> v1.Snapshot.Validator
+	20, // 7: v1.ValidatorsResp.vote:type_name -> v1.Snapshot.Vote
+	21, // 8: v1.ValidatorsResp.tallies:type_name -> v1.ValidatorsResp.Tally
+	5,  // 9: v1.IbftOperator.GetSnapshot:input_type -> v1.SnapshotReq
+	10, // 10: v1.IbftOperator.Propose:input_type -> v1.Candidate
+	8,  // 11: v1.IbftOperator.Unvote:input_type -> v1.UnvoteReq
+	22, // 12: v1.IbftOperator.Candidates:input_type -> google.protobuf.Empty
+	22, // 13: v1.IbftOperator.Status:input_type -> google.protobuf.Empty
+	22, // 14: v1.IbftOperator.StreamStatus:input_type -> google.protobuf.Empty
+	3,  // 15: v1.IbftOperator.RoundChange:input_type -> v1.RoundChangeReq
+	11, // 16: v1.IbftOperator.GetEvidence:input_type -> v1.EvidenceReq
+	14, // 17: v1.IbftOperator.GetLiveness:input_type -> v1.LivenessReq
+	17, // 18: v1.IbftOperator.GetValidators:input_type -> v1.ValidatorsReq
+	6,  // 19: v1.IbftOperator.GetSnapshot:output_type -> v1.Snapshot
+	22, // 20: v1.IbftOperator.Propose:output_type -> google.protobuf.Empty
+	22, // 21: v1.IbftOperator.Unvote:output_type -> google.protobuf.Empty
+	9,  // 22: v1.IbftOperator.Candidates:output_type -> v1.CandidatesResp
+	1,  // 23: v1.IbftOperator.Status:output_type -> v1.IbftStatusResp
+	2,  // 24: v1.IbftOperator.StreamStatus:output_type -> v1.IbftConsensusStatus
+	4,  // 25: v1.IbftOperator.RoundChange:output_type -> v1.RoundChangeResp
+	12, // 26: v1.IbftOperator.GetEvidence:output_type -> v1.EvidenceResp
+	15, // 27: v1.IbftOperator.GetLiveness:output_type -> v1.LivenessResp
+	18, // 28: v1.IbftOperator.GetValidators:output_type -> v1.ValidatorsResp
+	19, // [19:29] is the sub-list for method output_type
+	9,  // [9:19] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_consensus_ibft_proto_operator_proto_init() }
@@ -589,7 +1706,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SnapshotReq); i {
+			switch v := v.(*IbftConsensusStatus); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -601,7 +1718,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Snapshot); i {
+			switch v := v.(*RoundChangeReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -613,7 +1730,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ProposeReq); i {
+			switch v := v.(*RoundChangeResp); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -625,7 +1742,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CandidatesResp); i {
+			switch v := v.(*SnapshotReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -637,7 +1754,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Candidate); i {
+			switch v := v.(*Snapshot); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -649,7 +1766,7 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Snapshot_Validator); i {
+			switch v := v.(*ProposeReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -661,6 +1778,150 @@ func file_consensus_ibft_proto_operator_proto_init() {
 			}
 		}
 		file_consensus_ibft_proto_operator_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnvoteReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CandidatesResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Candidate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvidenceReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvidenceResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Evidence); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LivenessReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LivenessResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidatorLiveness); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidatorsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidatorsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Snapshot_Validator); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Snapshot_Vote); i {
 			case 0:
 				return &v.state
@@ -672,19 +1933,32 @@ func file_consensus_ibft_proto_operator_proto_init() {
 				return nil
 			}
 		}
+		file_consensus_ibft_proto_operator_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidatorsResp_Tally); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_consensus_ibft_proto_operator_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   8,
+			NumEnums:      1,
+			NumMessages:   21,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_consensus_ibft_proto_operator_proto_goTypes,
 		DependencyIndexes: file_consensus_ibft_proto_operator_proto_depIdxs,
+		EnumInfos:         file_consensus_ibft_proto_operator_proto_enumTypes,
 		MessageInfos:      file_consensus_ibft_proto_operator_proto_msgTypes,
 	}.Build()
 	File_consensus_ibft_proto_operator_proto = out.File
@@ -21,8 +21,14 @@ const _ = grpc.SupportPackageIsVersion7
 type IbftOperatorClient interface {
 	GetSnapshot(ctx context.Context, in *SnapshotReq, opts ...grpc.CallOption) (*Snapshot, error)
 	Propose(ctx context.Context, in *Candidate, opts ...grpc.CallOption) (*empty.Empty, error)
+	Unvote(ctx context.Context, in *UnvoteReq, opts ...grpc.CallOption) (*empty.Empty, error)
 	Candidates(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CandidatesResp, error)
 	Status(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*IbftStatusResp, error)
+	StreamStatus(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (IbftOperator_StreamStatusClient, error)
+	RoundChange(ctx context.Context, in *RoundChangeReq, opts ...grpc.CallOption) (*RoundChangeResp, error)
+	GetEvidence(ctx context.Context, in *EvidenceReq, opts ...grpc.CallOption) (*EvidenceResp, error)
+	GetLiveness(ctx context.Context, in *LivenessReq, opts ...grpc.CallOption) (*LivenessResp, error)
+	GetValidators(ctx context.Context, in *ValidatorsReq, opts ...grpc.CallOption) (*ValidatorsResp, error)
 }
 
 type ibftOperatorClient struct {
@@ -51,6 +57,15 @@ func (c *ibftOperatorClient) Propose(ctx context.Context, in *Candidate, opts ..
 	return out, nil
 }
 
+func (c *ibftOperatorClient) Unvote(ctx context.Context, in *UnvoteReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/v1.IbftOperator/Unvote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *ibftOperatorClient) Candidates(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*CandidatesResp, error) {
 	out := new(CandidatesResp)
 	err := c.cc.Invoke(ctx, "/v1.IbftOperator/Candidates", in, out, opts...)
@@ -69,14 +84,88 @@ func (c *ibftOperatorClient) Status(ctx context.Context, in *empty.Empty, opts .
 	return out, nil
 }
 
+func (c *ibftOperatorClient) StreamStatus(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (IbftOperator_StreamStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &IbftOperator_ServiceDesc.Streams[0], "/v1.IbftOperator/StreamStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ibftOperatorStreamStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IbftOperator_StreamStatusClient interface {
+	Recv() (*IbftConsensusStatus, error)
+	grpc.ClientStream
+}
+
+type ibftOperatorStreamStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *ibftOperatorStreamStatusClient) Recv() (*IbftConsensusStatus, error) {
+	m := new(IbftConsensusStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ibftOperatorClient) RoundChange(ctx context.Context, in *RoundChangeReq, opts ...grpc.CallOption) (*RoundChangeResp, error) {
+	out := new(RoundChangeResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftOperator/RoundChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ibftOperatorClient) GetEvidence(ctx context.Context, in *EvidenceReq, opts ...grpc.CallOption) (*EvidenceResp, error) {
+	out := new(EvidenceResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftOperator/GetEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ibftOperatorClient) GetLiveness(ctx context.Context, in *LivenessReq, opts ...grpc.CallOption) (*LivenessResp, error) {
+	out := new(LivenessResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftOperator/GetLiveness", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ibftOperatorClient) GetValidators(ctx context.Context, in *ValidatorsReq, opts ...grpc.CallOption) (*ValidatorsResp, error) {
+	out := new(ValidatorsResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftOperator/GetValidators", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IbftOperatorServer is the server API for IbftOperator service.
 // All implementations must embed UnimplementedIbftOperatorServer
 // for forward compatibility
 type IbftOperatorServer interface {
 	GetSnapshot(context.Context, *SnapshotReq) (*Snapshot, error)
 	Propose(context.Context, *Candidate) (*empty.Empty, error)
+	Unvote(context.Context, *UnvoteReq) (*empty.Empty, error)
 	Candidates(context.Context, *empty.Empty) (*CandidatesResp, error)
 	Status(context.Context, *empty.Empty) (*IbftStatusResp, error)
+	StreamStatus(*empty.Empty, IbftOperator_StreamStatusServer) error
+	RoundChange(context.Context, *RoundChangeReq) (*RoundChangeResp, error)
+	GetEvidence(context.Context, *EvidenceReq) (*EvidenceResp, error)
+	GetLiveness(context.Context, *LivenessReq) (*LivenessResp, error)
+	GetValidators(context.Context, *ValidatorsReq) (*ValidatorsResp, error)
 	mustEmbedUnimplementedIbftOperatorServer()
 }
 
@@ -90,12 +179,30 @@ func (UnimplementedIbftOperatorServer) GetSnapshot(context.Context, *SnapshotReq
 func (UnimplementedIbftOperatorServer) Propose(context.Context, *Candidate) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Propose not implemented")
 }
+func (UnimplementedIbftOperatorServer) Unvote(context.Context, *UnvoteReq) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unvote not implemented")
+}
 func (UnimplementedIbftOperatorServer) Candidates(context.Context, *empty.Empty) (*CandidatesResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Candidates not implemented")
 }
 func (UnimplementedIbftOperatorServer) Status(context.Context, *empty.Empty) (*IbftStatusResp, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedIbftOperatorServer) StreamStatus(*empty.Empty, IbftOperator_StreamStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStatus not implemented")
+}
+func (UnimplementedIbftOperatorServer) RoundChange(context.Context, *RoundChangeReq) (*RoundChangeResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RoundChange not implemented")
+}
+func (UnimplementedIbftOperatorServer) GetEvidence(context.Context, *EvidenceReq) (*EvidenceResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEvidence not implemented")
+}
+func (UnimplementedIbftOperatorServer) GetLiveness(context.Context, *LivenessReq) (*LivenessResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLiveness not implemented")
+}
+func (UnimplementedIbftOperatorServer) GetValidators(context.Context, *ValidatorsReq) (*ValidatorsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidators not implemented")
+}
 func (UnimplementedIbftOperatorServer) mustEmbedUnimplementedIbftOperatorServer() {}
 
 // UnsafeIbftOperatorServer may be embedded to opt out of forward compatibility for this service.
@@ -145,6 +252,24 @@ func _IbftOperator_Propose_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IbftOperator_Unvote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnvoteReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftOperatorServer).Unvote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftOperator/Unvote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftOperatorServer).Unvote(ctx, req.(*UnvoteReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _IbftOperator_Candidates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(empty.Empty)
 	if err := dec(in); err != nil {
@@ -181,6 +306,99 @@ func _IbftOperator_Status_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _IbftOperator_StreamStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(empty.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IbftOperatorServer).StreamStatus(m, &ibftOperatorStreamStatusServer{stream})
+}
+
+type IbftOperator_StreamStatusServer interface {
+	Send(*IbftConsensusStatus) error
+	grpc.ServerStream
+}
+
+type ibftOperatorStreamStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *ibftOperatorStreamStatusServer) Send(m *IbftConsensusStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IbftOperator_RoundChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoundChangeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftOperatorServer).RoundChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftOperator/RoundChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftOperatorServer).RoundChange(ctx, req.(*RoundChangeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IbftOperator_GetEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvidenceReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftOperatorServer).GetEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftOperator/GetEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftOperatorServer).GetEvidence(ctx, req.(*EvidenceReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IbftOperator_GetLiveness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LivenessReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftOperatorServer).GetLiveness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftOperator/GetLiveness",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftOperatorServer).GetLiveness(ctx, req.(*LivenessReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IbftOperator_GetValidators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftOperatorServer).GetValidators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftOperator/GetValidators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftOperatorServer).GetValidators(ctx, req.(*ValidatorsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // IbftOperator_ServiceDesc is the grpc.ServiceDesc for IbftOperator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -196,6 +414,10 @@ var IbftOperator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Propose",
 			Handler:    _IbftOperator_Propose_Handler,
 		},
+		{
+			MethodName: "Unvote",
+			Handler:    _IbftOperator_Unvote_Handler,
+		},
 		{
 			MethodName: "Candidates",
 			Handler:    _IbftOperator_Candidates_Handler,
@@ -204,7 +426,29 @@ var IbftOperator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _IbftOperator_Status_Handler,
 		},
+		{
+			MethodName: "RoundChange",
+			Handler:    _IbftOperator_RoundChange_Handler,
+		},
+		{
+			MethodName: "GetEvidence",
+			Handler:    _IbftOperator_GetEvidence_Handler,
+		},
+		{
+			MethodName: "GetLiveness",
+			Handler:    _IbftOperator_GetLiveness_Handler,
+		},
+		{
+			MethodName: "GetValidators",
+			Handler:    _IbftOperator_GetValidators_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStatus",
+			Handler:       _IbftOperator_StreamStatus_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "consensus/ibft/proto/operator.proto",
 }
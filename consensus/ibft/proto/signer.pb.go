@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0
+// 	protoc        v3.12.0
+// source: consensus/ibft/proto/signer.proto
+
+package proto
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type SignerAddressResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *SignerAddressResp) Reset() {
+	*x = SignerAddressResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_signer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignerAddressResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignerAddressResp) ProtoMessage() {}
+
+func (x *SignerAddressResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_signer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignerAddressResp.ProtoReflect.Descriptor instead.
+func (*SignerAddressResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_signer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SignerAddressResp) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+type SignReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// keccak256 digest to sign
+	Digest []byte `protobuf:"bytes,1,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (x *SignReq) Reset() {
+	*x = SignReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_signer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignReq) ProtoMessage() {}
+
+func (x *SignReq) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_signer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignReq.ProtoReflect.Descriptor instead.
+func (*SignReq) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_signer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SignReq) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+type SignResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Signature []byte `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (x *SignResp) Reset() {
+	*x = SignResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_consensus_ibft_proto_signer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignResp) ProtoMessage() {}
+
+func (x *SignResp) ProtoReflect() protoreflect.Message {
+	mi := &file_consensus_ibft_proto_signer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignResp.ProtoReflect.Descriptor instead.
+func (*SignResp) Descriptor() ([]byte, []int) {
+	return file_consensus_ibft_proto_signer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SignResp) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+var File_consensus_ibft_proto_signer_proto protoreflect.FileDescriptor
+
+var file_consensus_ibft_proto_signer_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x6e, 0x73, 0x75, 0x73, 0x2f, 0x69, 0x62, 0x66, 0x74,
+	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x02, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2d, 0x0a, 0x11, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x22, 0x21, 0x0a, 0x07, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x71, 0x12, 0x16,
+	0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06,
+	0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x22, 0x28, 0x0a, 0x08, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x32, 0xa3, 0x01, 0x0a, 0x0a, 0x49, 0x62, 0x66, 0x74, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12,
+	0x38, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x1a, 0x15, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x41, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x21, 0x0a, 0x04, 0x53, 0x69, 0x67,
+	0x6e, 0x12, 0x0b, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x0c,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x12, 0x38, 0x0a, 0x06,
+	0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x17, 0x5a, 0x15, 0x2f, 0x63, 0x6f, 0x6e, 0x73, 0x65,
+	0x6e, 0x73, 0x75, 0x73, 0x2f, 0x69, 0x62, 0x66, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_consensus_ibft_proto_signer_proto_rawDescOnce sync.Once
+	file_consensus_ibft_proto_signer_proto_rawDescData = file_consensus_ibft_proto_signer_proto_rawDesc
+)
+
+func file_consensus_ibft_proto_signer_proto_rawDescGZIP() []byte {
+	file_consensus_ibft_proto_signer_proto_rawDescOnce.Do(func() {
+		file_consensus_ibft_proto_signer_proto_rawDescData = protoimpl.X.CompressGZIP(file_consensus_ibft_proto_signer_proto_rawDescData)
+	})
+	return file_consensus_ibft_proto_signer_proto_rawDescData
+}
+
+var file_consensus_ibft_proto_signer_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_consensus_ibft_proto_signer_proto_goTypes = []interface{}{
+	(*SignerAddressResp)(nil), // 0: v1.SignerAddressResp
+	(*SignReq)(nil),           // 1: v1.SignReq
+	(*SignResp)(nil),          // 2: v1.SignResp
+	(*empty.Empty)(nil),       // 3: google.protobuf.Empty
+}
+var file_consensus_ibft_proto_signer_proto_depIdxs = []int32{
+	3, // 0: v1.IbftSigner.Address:input_type -> google.protobuf.Empty
+	1, // 1: v1.IbftSigner.Sign:input_type -> v1.SignReq
+	3, // 2: v1.IbftSigner.Health:input_type -> google.protobuf.Empty
+	0, // 3: v1.IbftSigner.Address:output_type -> v1.SignerAddressResp
+	2, // 4: v1.IbftSigner.Sign:output_type -> v1.SignResp
+	3, // 5: v1.IbftSigner.Health:output_type -> google.protobuf.Empty
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_consensus_ibft_proto_signer_proto_init() }
+func file_consensus_ibft_proto_signer_proto_init() {
+	if File_consensus_ibft_proto_signer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_consensus_ibft_proto_signer_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignerAddressResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_signer_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_consensus_ibft_proto_signer_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_consensus_ibft_proto_signer_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_consensus_ibft_proto_signer_proto_goTypes,
+		DependencyIndexes: file_consensus_ibft_proto_signer_proto_depIdxs,
+		MessageInfos:      file_consensus_ibft_proto_signer_proto_msgTypes,
+	}.Build()
+	File_consensus_ibft_proto_signer_proto = out.File
+	file_consensus_ibft_proto_signer_proto_rawDesc = nil
+	file_consensus_ibft_proto_signer_proto_goTypes = nil
+	file_consensus_ibft_proto_signer_proto_depIdxs = nil
+}
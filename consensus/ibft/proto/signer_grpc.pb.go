@@ -0,0 +1,174 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	empty "github.com/golang/protobuf/ptypes/empty"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// IbftSignerClient is the client API for IbftSigner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IbftSignerClient interface {
+	Address(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SignerAddressResp, error)
+	Sign(ctx context.Context, in *SignReq, opts ...grpc.CallOption) (*SignResp, error)
+	Health(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+}
+
+type ibftSignerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIbftSignerClient(cc grpc.ClientConnInterface) IbftSignerClient {
+	return &ibftSignerClient{cc}
+}
+
+func (c *ibftSignerClient) Address(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*SignerAddressResp, error) {
+	out := new(SignerAddressResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftSigner/Address", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ibftSignerClient) Sign(ctx context.Context, in *SignReq, opts ...grpc.CallOption) (*SignResp, error) {
+	out := new(SignResp)
+	err := c.cc.Invoke(ctx, "/v1.IbftSigner/Sign", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ibftSignerClient) Health(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/v1.IbftSigner/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IbftSignerServer is the server API for IbftSigner service.
+// All implementations must embed UnimplementedIbftSignerServer
+// for forward compatibility
+type IbftSignerServer interface {
+	Address(context.Context, *empty.Empty) (*SignerAddressResp, error)
+	Sign(context.Context, *SignReq) (*SignResp, error)
+	Health(context.Context, *empty.Empty) (*empty.Empty, error)
+	mustEmbedUnimplementedIbftSignerServer()
+}
+
+// UnimplementedIbftSignerServer must be embedded to have forward compatible implementations.
+type UnimplementedIbftSignerServer struct {
+}
+
+func (UnimplementedIbftSignerServer) Address(context.Context, *empty.Empty) (*SignerAddressResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Address not implemented")
+}
+func (UnimplementedIbftSignerServer) Sign(context.Context, *SignReq) (*SignResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedIbftSignerServer) Health(context.Context, *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedIbftSignerServer) mustEmbedUnimplementedIbftSignerServer() {}
+
+// UnsafeIbftSignerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IbftSignerServer will
+// result in compilation errors.
+type UnsafeIbftSignerServer interface {
+	mustEmbedUnimplementedIbftSignerServer()
+}
+
+func RegisterIbftSignerServer(s grpc.ServiceRegistrar, srv IbftSignerServer) {
+	s.RegisterService(&IbftSigner_ServiceDesc, srv)
+}
+
+func _IbftSigner_Address_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftSignerServer).Address(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftSigner/Address",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftSignerServer).Address(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IbftSigner_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftSignerServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftSigner/Sign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftSignerServer).Sign(ctx, req.(*SignReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IbftSigner_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IbftSignerServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.IbftSigner/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IbftSignerServer).Health(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IbftSigner_ServiceDesc is the grpc.ServiceDesc for IbftSigner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IbftSigner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.IbftSigner",
+	HandlerType: (*IbftSignerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Address",
+			Handler:    _IbftSigner_Address_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _IbftSigner_Sign_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _IbftSigner_Health_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "consensus/ibft/proto/signer.proto",
+}
@@ -0,0 +1,149 @@
+package ibft
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+// roundState is the state of the current round, it is saved to the path so
+// that a validator that restarts rejoins the round instead of starting at
+// round zero, and it keeps the locked block.
+type roundState struct {
+	Sequence uint64 `json:"sequence"`
+	Round    uint64 `json:"round"`
+	Locked   bool   `json:"locked"`
+
+	// Block is the rlp encoded block of the round, if any
+	Block []byte `json:"block,omitempty"`
+
+	// Messages are the prepare, commit and round change messages
+	// collected in the sequence encoded with protobuf
+	Messages [][]byte `json:"messages,omitempty"`
+}
+
+func (i *Ibft) roundStatePath() string {
+	return filepath.Join(i.config.Path, "round")
+}
+
+// saveRoundState writes the round state if it has changed since the last time
+func (i *Ibft) saveRoundState() {
+	if i.config.Path == "" || i.state.view == nil || i.isState(SyncState) {
+		return
+	}
+
+	r := &roundState{
+		Sequence: i.state.view.Sequence,
+		Round:    i.state.view.Round,
+		Locked:   i.state.locked,
+	}
+	if i.state.block != nil {
+		r.Block = i.state.block.MarshalRLP()
+	}
+
+	msgs := []*proto.MessageReq{}
+	for _, msg := range i.state.prepared {
+		msgs = append(msgs, msg)
+	}
+	for _, msg := range i.state.committed {
+		msgs = append(msgs, msg)
+	}
+	for _, round := range i.state.roundMessages {
+		for _, msg := range round {
+			msgs = append(msgs, msg)
+		}
+	}
+	// the messages are sorted so that the state of the same messages
+	// encodes the same and it is not written again
+	sort.Slice(msgs, func(a, b int) bool {
+		x, y := msgs[a], msgs[b]
+		if x.Type != y.Type {
+			return x.Type < y.Type
+		}
+		if x.View.GetRound() != y.View.GetRound() {
+			return x.View.GetRound() < y.View.GetRound()
+		}
+		return x.From < y.From
+	})
+	for _, msg := range msgs {
+		data, err := gproto.Marshal(msg)
+		if err != nil {
+			i.logger.Error("failed to encode the round message", "err", err)
+			return
+		}
+		r.Messages = append(r.Messages, data)
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		i.logger.Error("failed to encode the round state", "err", err)
+		return
+	}
+	if bytes.Equal(data, i.lastRoundState) {
+		return
+	}
+	if err := writeDataStore(i.roundStatePath(), r); err != nil {
+		i.logger.Error("failed to save the round state", "err", err)
+		return
+	}
+	i.lastRoundState = data
+}
+
+// loadRoundState reads the round state saved before the node stopped
+func (i *Ibft) loadRoundState() error {
+	if i.config.Path == "" {
+		return nil
+	}
+	var r *roundState
+	if err := readDataStore(i.roundStatePath(), &r); err != nil {
+		return err
+	}
+	i.recoveredRound = r
+	return nil
+}
+
+// recoverRound moves the state machine to the round saved before the restart
+// if it is still in the same sequence. It returns whether the block of the
+// round was recovered
+func (i *Ibft) recoverRound() bool {
+	r := i.recoveredRound
+	if r == nil {
+		return false
+	}
+	i.recoveredRound = nil
+
+	if r.Sequence != i.state.view.Sequence {
+		return false
+	}
+	i.logger.Info("recover round", "sequence", r.Sequence, "round", r.Round, "locked", r.Locked)
+	i.state.view.Round = r.Round
+
+	// the collected messages are processed again
+	for _, data := range r.Messages {
+		msg := &proto.MessageReq{}
+		if err := gproto.Unmarshal(data, msg); err != nil {
+			i.logger.Error("failed to decode the round message", "err", err)
+			continue
+		}
+		i.pushMessage(msg)
+	}
+
+	if r.Block == nil {
+		return false
+	}
+	block := &types.Block{}
+	if err := block.UnmarshalRLP(r.Block); err != nil {
+		i.logger.Error("failed to decode the round block", "err", err)
+		return false
+	}
+	i.state.block = block
+	if r.Locked {
+		i.state.lock()
+	}
+	return true
+}
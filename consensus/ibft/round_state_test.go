@@ -0,0 +1,105 @@
+package ibft
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundState_Recover(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "ibft-round")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "C")
+	i.config.Path = tmpDir
+
+	// C is locked in the round 2, in which it is the proposer
+	i.setState(ValidateState)
+	i.state.view = proto.ViewMsg(1, 2)
+	i.state.validators = i.pool.ValidatorSet()
+	i.state.block = i.DummyBlock()
+	i.state.lock()
+	i.addMessage(&proto.MessageReq{
+		From: "A",
+		Type: proto.MessageReq_Prepare,
+		View: proto.ViewMsg(1, 2),
+	})
+	i.saveRoundState()
+	block := i.state.block
+
+	// restart the node
+	i.state = newState()
+	i.state.view = proto.ViewMsg(1, 0)
+	i.msgQueue = newMsgQueue()
+	assert.NoError(t, i.loadRoundState())
+
+	i.setState(AcceptState)
+	i.runCycle()
+
+	// it proposes the locked block again in the same round
+	i.expect(expectResult{
+		sequence: 1,
+		round:    2,
+		state:    ValidateState,
+		locked:   true,
+		outgoing: 2,
+	})
+	assert.Equal(t, block.MarshalRLP(), i.state.block.MarshalRLP())
+
+	// the collected message is processed again
+	task := i.msgQueue.readMessage(ValidateState, i.state.view)
+	assert.NotNil(t, task)
+	assert.Equal(t, i.pool.get("A").Address(), task.obj.FromAddr())
+}
+
+func TestRoundState_OtherSequence(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "ibft-round")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "B")
+	i.config.Path = tmpDir
+
+	i.setState(ValidateState)
+	i.state.view = proto.ViewMsg(5, 2)
+	i.saveRoundState()
+
+	assert.NoError(t, i.loadRoundState())
+	i.state.view = proto.ViewMsg(1, 0)
+
+	// the saved round is of another sequence
+	assert.False(t, i.recoverRound())
+	assert.Equal(t, uint64(0), i.state.view.Round)
+	assert.Nil(t, i.recoveredRound)
+}
+
+func TestRoundState_SaveOnce(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "ibft-round")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
+	i.config.Path = tmpDir
+
+	i.setState(ValidateState)
+	i.state.view = proto.ViewMsg(1, 2)
+	for _, name := range []string{"A", "B", "C", "D"} {
+		from := i.pool.get(name).Address().String()
+		i.state.addPrepared(&proto.MessageReq{From: from, Type: proto.MessageReq_Prepare, View: proto.ViewMsg(1, 2)})
+		i.state.addCommited(&proto.MessageReq{From: from, Type: proto.MessageReq_Commit, View: proto.ViewMsg(1, 2)})
+		i.state.AddRoundMessage(&proto.MessageReq{From: from, Type: proto.MessageReq_RoundChange, View: proto.ViewMsg(1, 3)})
+	}
+	i.saveRoundState()
+
+	// the same messages are not written again
+	assert.NoError(t, os.Remove(i.roundStatePath()))
+	for n := 0; n < 10; n++ {
+		i.saveRoundState()
+	}
+	_, err = os.Stat(i.roundStatePath())
+	assert.True(t, os.IsNotExist(err))
+}
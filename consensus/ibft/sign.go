@@ -1,7 +1,6 @@
 package ibft
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 
 	"github.com/0xPolygon/minimal/consensus/ibft/proto"
@@ -39,7 +38,7 @@ func ecrecoverFromHeader(h *types.Header) (types.Address, error) {
 	return ecrecoverImpl(extra.Seal, msg)
 }
 
-func signSealImpl(prv *ecdsa.PrivateKey, h *types.Header, committed bool) ([]byte, error) {
+func signSealImpl(signer SignerBackend, h *types.Header, committed bool) ([]byte, error) {
 	sig, err := signHash(h)
 	if err != nil {
 		return nil, err
@@ -49,16 +48,16 @@ func signSealImpl(prv *ecdsa.PrivateKey, h *types.Header, committed bool) ([]byt
 	if committed {
 		sig = commitMsg(sig)
 	}
-	seal, err := crypto.Sign(prv, crypto.Keccak256(sig))
+	seal, err := signer.Sign(crypto.Keccak256(sig))
 	if err != nil {
 		return nil, err
 	}
 	return seal, nil
 }
 
-func writeSeal(prv *ecdsa.PrivateKey, h *types.Header) (*types.Header, error) {
+func writeSeal(signer SignerBackend, h *types.Header) (*types.Header, error) {
 	h = h.Copy()
-	seal, err := signSealImpl(prv, h, false)
+	seal, err := signSealImpl(signer, h, false)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +72,8 @@ func writeSeal(prv *ecdsa.PrivateKey, h *types.Header) (*types.Header, error) {
 	return h, nil
 }
 
-func writeCommittedSeal(prv *ecdsa.PrivateKey, h *types.Header) ([]byte, error) {
-	return signSealImpl(prv, h, true)
+func writeCommittedSeal(signer SignerBackend, h *types.Header) ([]byte, error) {
+	return signSealImpl(signer, h, true)
 }
 
 func writeCommittedSeals(h *types.Header, seals [][]byte) (*types.Header, error) {
@@ -132,6 +131,9 @@ func signHash(h *types.Header) ([]byte, error) {
 	vv.Set(arena.NewUint(h.GasUsed))
 	vv.Set(arena.NewUint(h.Timestamp))
 	vv.Set(arena.NewCopyBytes(h.ExtraData))
+	if h.BaseFee != nil {
+		vv.Set(arena.NewBigInt(h.BaseFee))
+	}
 
 	buf := keccak.Keccak256Rlp(nil, vv)
 	return buf, nil
@@ -148,29 +150,60 @@ func verifySigner(snap *Snapshot, header *types.Header) error {
 	return nil
 }
 
-func verifyCommitedFields(snap *Snapshot, header *types.Header) error {
-	extra, err := getIbftExtra(header)
+// verifyCommittedSeal checks that the committed seal of the header is signed by the address
+func verifyCommittedSeal(header *types.Header, seal []byte, addr types.Address) error {
+	signMsg, err := signHash(header)
 	if err != nil {
 		return err
 	}
-	if len(extra.CommittedSeal) == 0 {
-		return fmt.Errorf("empty committed seals")
+	signer, err := ecrecoverImpl(seal, commitMsg(signMsg))
+	if err != nil {
+		return err
+	}
+	if signer != addr {
+		return fmt.Errorf("seal signed by %s", signer)
+	}
+	return nil
+}
+
+// committedSealers returns the signers of the committed seals of the header
+func committedSealers(header *types.Header) ([]types.Address, error) {
+	extra, err := getIbftExtra(header)
+	if err != nil {
+		return nil, err
 	}
 
 	// get the message that needs to be signed
 	signMsg, err := signHash(header)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	signMsg = commitMsg(signMsg)
 
-	visited := map[types.Address]struct{}{}
+	sealers := make([]types.Address, 0, len(extra.CommittedSeal))
 	for _, seal := range extra.CommittedSeal {
 		addr, err := ecrecoverImpl(seal, signMsg)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		sealers = append(sealers, addr)
+	}
+	return sealers, nil
+}
 
+// verifyCommitedFields checks that the committed seals of the header have
+// the weight of a quorum, twoThirds selects the quorum of the block
+func verifyCommitedFields(snap *Snapshot, header *types.Header, twoThirds bool) error {
+	sealers, err := committedSealers(header)
+	if err != nil {
+		return err
+	}
+	if len(sealers) == 0 {
+		return fmt.Errorf("empty committed seals")
+	}
+
+	visited := map[types.Address]struct{}{}
+	for _, addr := range sealers {
 		if _, ok := visited[addr]; ok {
 			return fmt.Errorf("repeated seal")
 		} else {
@@ -181,8 +214,12 @@ func verifyCommitedFields(snap *Snapshot, header *types.Header) error {
 		}
 	}
 
-	validSeals := len(visited)
-	if validSeals <= 2*snap.Set.MinFaultyNodes() {
+	// the seals need the weight of a quorum
+	var validSeals uint64
+	for addr := range visited {
+		validSeals += snap.Weights.Get(addr)
+	}
+	if validSeals < snap.Set.QuorumWeight(snap.Weights, twoThirds) {
 		return fmt.Errorf("not enough seals to seal block")
 	}
 	return nil
@@ -205,12 +242,12 @@ func validateMsg(msg *proto.MessageReq) error {
 	return nil
 }
 
-func signMsg(key *ecdsa.PrivateKey, msg *proto.MessageReq) error {
+func signMsg(signer SignerBackend, msg *proto.MessageReq) error {
 	signMsg, err := msg.PayloadNoSig()
 	if err != nil {
 		return err
 	}
-	sig, err := crypto.Sign(key, crypto.Keccak256(signMsg))
+	sig, err := signer.Sign(crypto.Keccak256(signMsg))
 	if err != nil {
 		return err
 	}
@@ -22,11 +22,11 @@ func TestSign_Sealer(t *testing.T) {
 	// non-validator address
 	pool.add("X")
 
-	badSealedBlock, _ := writeSeal(pool.get("X").priv, h)
+	badSealedBlock, _ := writeSeal(NewLocalSigner(pool.get("X").priv), h)
 	assert.Error(t, verifySigner(snap, badSealedBlock))
 
 	// seal the block with a validator
-	goodSealedBlock, _ := writeSeal(pool.get("A").priv, h)
+	goodSealedBlock, _ := writeSeal(NewLocalSigner(pool.get("A").priv), h)
 	assert.NoError(t, verifySigner(snap, goodSealedBlock))
 }
 
@@ -44,10 +44,10 @@ func TestSign_CommittedSeals(t *testing.T) {
 	// non-validator address
 	pool.add("X")
 
-	buildCommittedSeal := func(accnt []string) error {
+	buildCommittedSeal := func(accnt []string, twoThirds ...bool) error {
 		seals := [][]byte{}
 		for _, accnt := range accnt {
-			seal, err := writeCommittedSeal(pool.get(accnt).priv, h)
+			seal, err := writeCommittedSeal(NewLocalSigner(pool.get(accnt).priv), h)
 			assert.NoError(t, err)
 			seals = append(seals, seal)
 		}
@@ -55,7 +55,7 @@ func TestSign_CommittedSeals(t *testing.T) {
 		sealed, err := writeCommittedSeals(h, seals)
 		assert.NoError(t, err)
 
-		return verifyCommitedFields(snap, sealed)
+		return verifyCommitedFields(snap, sealed, len(twoThirds) != 0 && twoThirds[0])
 	}
 
 	// Correct
@@ -69,6 +69,15 @@ func TestSign_CommittedSeals(t *testing.T) {
 
 	// Failed - Not enough signatures
 	assert.Error(t, buildCommittedSeal([]string{"A"}))
+
+	// the quorum of more than two thirds needs 4 of the 5 validators
+	assert.Error(t, buildCommittedSeal([]string{"A", "B", "C"}, true))
+	assert.NoError(t, buildCommittedSeal([]string{"A", "B", "C", "D"}, true))
+
+	// the seals need the weight of the validators
+	snap.Weights = Weights{pool.get("A").Address(): 10}
+	assert.NoError(t, buildCommittedSeal([]string{"A"}))
+	assert.Error(t, buildCommittedSeal([]string{"B", "C", "D", "E"}))
 }
 
 func TestSign_Messages(t *testing.T) {
@@ -76,8 +85,29 @@ func TestSign_Messages(t *testing.T) {
 	pool.add("A")
 
 	msg := &proto.MessageReq{}
-	assert.NoError(t, signMsg(pool.get("A").priv, msg))
+	assert.NoError(t, signMsg(NewLocalSigner(pool.get("A").priv), msg))
 	assert.NoError(t, validateMsg(msg))
 
 	assert.Equal(t, msg.From, pool.get("A").Address().String())
 }
+
+func TestSign_BlockProposer(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B")
+
+	i := &Ibft{validatorKeyAddr: pool.get("A").Address()}
+
+	h := &types.Header{}
+	putIbftExtraValidators(h, pool.ValidatorSet())
+
+	// the block built by the node is not sealed yet
+	proposer, err := i.blockProposer(h)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.get("A").Address(), proposer)
+
+	sealed, err := writeSeal(NewLocalSigner(pool.get("B").priv), h)
+	assert.NoError(t, err)
+	proposer, err = i.blockProposer(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, pool.get("B").Address(), proposer)
+}
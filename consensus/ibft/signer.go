@@ -0,0 +1,371 @@
+package ibft
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// SignerBackend signs the seals and the messages with the validator key
+type SignerBackend interface {
+	// Address returns the address of the validator key
+	Address() types.Address
+
+	// Sign signs a 32 bytes digest
+	Sign(digest []byte) ([]byte, error)
+
+	// Health returns an error if the backend cannot sign
+	Health() error
+
+	// Close closes the backend
+	Close() error
+}
+
+// localSigner signs with a key in memory
+type localSigner struct {
+	key  *ecdsa.PrivateKey
+	addr types.Address
+}
+
+// NewLocalSigner returns a signer backend for the key
+func NewLocalSigner(key *ecdsa.PrivateKey) SignerBackend {
+	return &localSigner{
+		key:  key,
+		addr: crypto.PubKeyToAddress(&key.PublicKey),
+	}
+}
+
+func (l *localSigner) Address() types.Address {
+	return l.addr
+}
+
+func (l *localSigner) Sign(digest []byte) ([]byte, error) {
+	return crypto.Sign(l.key, digest)
+}
+
+func (l *localSigner) Health() error {
+	return nil
+}
+
+func (l *localSigner) Close() error {
+	return nil
+}
+
+// remoteSignerTimeout is the time to wait for a response of the remote signer
+var remoteSignerTimeout = 5 * time.Second
+
+// remoteSigner signs with a key kept by an IbftSigner grpc service
+// (i.e. a signing service in front of an HSM)
+type remoteSigner struct {
+	conn   *grpc.ClientConn
+	client proto.IbftSignerClient
+	addr   types.Address
+}
+
+// RemoteSignerTLS are the files of the mutual tls connection with the remote
+// signer. The node verifies the signer with the CA and the signer has to
+// verify the certificate of the node, the only one that can sign
+type RemoteSignerTLS struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+}
+
+func (c *RemoteSignerTLS) tlsConfig() (*tls.Config, error) {
+	if c == nil || c.CAFile == "" || c.CertFile == "" || c.KeyFile == "" {
+		return nil, fmt.Errorf("the remote signer requires the ca, the certificate and the key files")
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the remote signer certificate: %v", err)
+	}
+	data, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// NewRemoteSigner connects to the IbftSigner service at target over mutual
+// tls and checks that it is healthy
+func NewRemoteSigner(target string, config *RemoteSignerTLS) (SignerBackend, error) {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	if err != nil {
+		return nil, err
+	}
+	r := &remoteSigner{
+		conn:   conn,
+		client: proto.NewIbftSignerClient(conn),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+	defer cancel()
+
+	resp, err := r.client.Address(ctx, &empty.Empty{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to get the address of the remote signer: %v", err)
+	}
+	if err := r.addr.UnmarshalText([]byte(resp.Address)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := r.Health(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *remoteSigner) Address() types.Address {
+	return r.addr
+}
+
+func (r *remoteSigner) Sign(digest []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+	defer cancel()
+
+	resp, err := r.client.Sign(ctx, &proto.SignReq{Digest: digest})
+	if err != nil {
+		return nil, fmt.Errorf("remote signer failed: %v", err)
+	}
+
+	// do not trust the signer, the signature has to be from the validator key
+	pub, err := crypto.RecoverPubkey(resp.Signature, digest)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %v", err)
+	}
+	if addr := crypto.PubKeyToAddress(pub); addr != r.addr {
+		return nil, fmt.Errorf("remote signer signed with %s instead of %s", addr, r.addr)
+	}
+	return resp.Signature, nil
+}
+
+func (r *remoteSigner) Health() error {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+	defer cancel()
+
+	if _, err := r.client.Health(ctx, &empty.Empty{}); err != nil {
+		return fmt.Errorf("remote signer is not healthy: %v", err)
+	}
+	return nil
+}
+
+func (r *remoteSigner) Close() error {
+	return r.conn.Close()
+}
+
+// SignerService serves a signer backend as an IbftSigner grpc service
+type SignerService struct {
+	backend SignerBackend
+
+	proto.UnimplementedIbftSignerServer
+}
+
+// NewSignerService returns the IbftSigner service of the backend
+func NewSignerService(backend SignerBackend) *SignerService {
+	return &SignerService{backend: backend}
+}
+
+// Address implements the IbftSigner service
+func (s *SignerService) Address(ctx context.Context, req *empty.Empty) (*proto.SignerAddressResp, error) {
+	return &proto.SignerAddressResp{Address: s.backend.Address().String()}, nil
+}
+
+// Sign implements the IbftSigner service
+func (s *SignerService) Sign(ctx context.Context, req *proto.SignReq) (*proto.SignResp, error) {
+	if len(req.Digest) != types.HashLength {
+		return nil, fmt.Errorf("digest must be %d bytes", types.HashLength)
+	}
+	sig, err := s.backend.Sign(req.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.SignResp{Signature: sig}, nil
+}
+
+// Health implements the IbftSigner service
+func (s *SignerService) Health(ctx context.Context, req *empty.Empty) (*empty.Empty, error) {
+	if err := s.backend.Health(); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+// signerHealthInterval is the period of the health checks of the signer
+var signerHealthInterval = 30 * time.Second
+
+// runSignerHealthCheck checks periodically the signer and reports when
+// it stops being able to sign and when it recovers
+func (i *Ibft) runSignerHealthCheck() {
+	healthy := true
+	for {
+		select {
+		case <-time.After(signerHealthInterval):
+		case <-i.closeCh:
+			return
+		}
+
+		err := i.signer.Health()
+		if err != nil && healthy {
+			i.logger.Error("signer is not healthy", "err", err)
+		} else if err == nil && !healthy {
+			i.logger.Info("signer is healthy again")
+		}
+		healthy = err == nil
+	}
+}
+
+const signGuardFileName = "sign-guard"
+
+// signRecord is the last block sealed or committed by the validator
+type signRecord struct {
+	Number uint64
+	Round  uint64
+	Hash   types.Hash
+
+	// Released is set when the round reset without committing the block
+	Released bool
+}
+
+// signGuard refuses to sign conflicting seals, even after a restart of the node
+// if it has a path. A validator cannot propose two blocks in the same round
+// nor commit two blocks at the same height since it is locked on the first one,
+// unless the lock was released in an earlier round
+type signGuard struct {
+	path string
+
+	lock     sync.Mutex
+	Proposal *signRecord
+	Commit   *signRecord
+}
+
+func newSignGuard(path string) (*signGuard, error) {
+	g := &signGuard{path: path}
+	if path != "" {
+		if err := readDataStore(filepath.Join(path, signGuardFileName), g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *signGuard) checkProposal(number, round uint64, hash types.Hash) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if last := g.Proposal; last != nil {
+		if number < last.Number || number == last.Number && round < last.Round {
+			return fmt.Errorf("refusing to seal block %d at round %d, already sealed block %d at round %d", number, round, last.Number, last.Round)
+		}
+		if number == last.Number && round == last.Round {
+			if hash != last.Hash {
+				return fmt.Errorf("refusing to seal a second block %d at round %d", number, round)
+			}
+			return nil
+		}
+	}
+	g.Proposal = &signRecord{Number: number, Round: round, Hash: hash}
+	return g.save()
+}
+
+func (g *signGuard) checkCommit(number, round uint64, hash types.Hash) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if last := g.Commit; last != nil {
+		if number < last.Number {
+			return fmt.Errorf("refusing to commit block %d, already committed block %d", number, last.Number)
+		}
+		if number == last.Number {
+			if hash == last.Hash && !last.Released {
+				return nil
+			}
+			if hash != last.Hash && (!last.Released || round <= last.Round) {
+				return fmt.Errorf("refusing to commit a second block %d", number)
+			}
+		}
+	}
+	g.Commit = &signRecord{Number: number, Round: round, Hash: hash}
+	return g.save()
+}
+
+// releaseCommit allows committing another block at the height of the last
+// commit in the later rounds, once the validator is not locked on it
+func (g *signGuard) releaseCommit(number uint64) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.Commit == nil || g.Commit.Number != number || g.Commit.Released {
+		return nil
+	}
+	g.Commit.Released = true
+	return g.save()
+}
+
+func (g *signGuard) save() error {
+	if g.path == "" {
+		return nil
+	}
+	return writeDataStore(filepath.Join(g.path, signGuardFileName), g)
+}
+
+// sealProposal writes the seal of the block proposed in the current round
+func (i *Ibft) sealProposal(h *types.Header) (*types.Header, error) {
+	hash, err := signHash(h)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.guard.checkProposal(h.Number, i.state.view.Round, types.BytesToHash(hash)); err != nil {
+		return nil, err
+	}
+	return writeSeal(i.signer, h)
+}
+
+// commitSeal returns the committed seal of the block in the current round
+func (i *Ibft) commitSeal(h *types.Header) ([]byte, error) {
+	hash, err := signHash(h)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.guard.checkCommit(h.Number, i.state.view.Round, types.BytesToHash(hash)); err != nil {
+		return nil, err
+	}
+	return writeCommittedSeal(i.signer, h)
+}
+
+// releaseBlock releases the commit of the block in the sign guard after the
+// round resets without inserting it
+func (i *Ibft) releaseBlock(block *types.Block) {
+	if block == nil {
+		return
+	}
+	if err := i.guard.releaseCommit(block.Number()); err != nil {
+		i.logger.Error("failed to release the sign guard", "err", err)
+	}
+}
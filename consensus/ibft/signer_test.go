@@ -0,0 +1,305 @@
+package ibft
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestSignGuard_Proposal(t *testing.T) {
+	g, err := newSignGuard("")
+	assert.NoError(t, err)
+
+	a, b := types.StringToHash("1"), types.StringToHash("2")
+
+	assert.NoError(t, g.checkProposal(1, 0, a))
+
+	// the same proposal can be sealed again
+	assert.NoError(t, g.checkProposal(1, 0, a))
+
+	// a different block in the same round cannot
+	assert.Error(t, g.checkProposal(1, 0, b))
+
+	// a different block in the next round can
+	assert.NoError(t, g.checkProposal(1, 1, b))
+
+	// the previous rounds and blocks are not sealed anymore
+	assert.Error(t, g.checkProposal(1, 0, a))
+	assert.NoError(t, g.checkProposal(2, 0, a))
+	assert.Error(t, g.checkProposal(1, 2, a))
+}
+
+func TestSignGuard_Commit(t *testing.T) {
+	g, err := newSignGuard("")
+	assert.NoError(t, err)
+
+	a, b := types.StringToHash("1"), types.StringToHash("2")
+
+	assert.NoError(t, g.checkCommit(1, 0, a))
+	assert.NoError(t, g.checkCommit(1, 1, a))
+
+	// a validator cannot commit two blocks at the same height, not even in another round
+	assert.Error(t, g.checkCommit(1, 2, b))
+
+	assert.NoError(t, g.checkCommit(2, 0, b))
+	assert.Error(t, g.checkCommit(1, 0, a))
+}
+
+func TestSignGuard_ReleaseCommit(t *testing.T) {
+	g, err := newSignGuard("")
+	assert.NoError(t, err)
+
+	a, b := types.StringToHash("1"), types.StringToHash("2")
+
+	assert.NoError(t, g.checkCommit(1, 0, a))
+
+	// only the lock of the last height is released
+	assert.NoError(t, g.releaseCommit(0))
+	assert.Error(t, g.checkCommit(1, 1, b))
+
+	// once the lock is released another block can be committed in the later rounds
+	assert.NoError(t, g.releaseCommit(1))
+	assert.Error(t, g.checkCommit(1, 0, b))
+	assert.NoError(t, g.checkCommit(1, 1, b))
+
+	// and the validator is locked on it
+	assert.Error(t, g.checkCommit(1, 2, a))
+	assert.NoError(t, g.checkCommit(1, 2, b))
+}
+
+func TestSignGuard_Restart(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "sign-guard")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	a, b := types.StringToHash("1"), types.StringToHash("2")
+
+	g, err := newSignGuard(tmpDir)
+	assert.NoError(t, err)
+	assert.NoError(t, g.checkProposal(1, 0, a))
+	assert.NoError(t, g.checkCommit(1, 0, a))
+
+	// the guard remembers the seals after a restart
+	g, err = newSignGuard(tmpDir)
+	assert.NoError(t, err)
+	assert.Error(t, g.checkProposal(1, 0, b))
+	assert.Error(t, g.checkCommit(1, 0, b))
+}
+
+// mockSigner is a signer backend signing with another key than its address
+type mockSigner struct {
+	SignerBackend
+	sign   SignerBackend
+	health error
+}
+
+func (m *mockSigner) Sign(digest []byte) ([]byte, error) {
+	return m.sign.Sign(digest)
+}
+
+func (m *mockSigner) Health() error {
+	return m.health
+}
+
+// testCA signs the certificates of the remote signer and its clients
+type testCA struct {
+	dir  string
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("/tmp", "remote-signer")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	ca := &testCA{dir: dir, key: key, cert: cert}
+	ca.write(t, "ca.pem", "CERTIFICATE", der)
+	return ca
+}
+
+func (c *testCA) write(t *testing.T, name, typ string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(c.dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600))
+	return path
+}
+
+// issue writes a certificate of the CA for the local address and returns
+// the files of the certificate and the key
+func (c *testCA) issue(t *testing.T, name string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, &key.PublicKey, c.key)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return c.write(t, name+".pem", "CERTIFICATE", der), c.write(t, name+".key", "EC PRIVATE KEY", keyDer)
+}
+
+// tlsConfig returns the files of a client certificate of the CA
+func (c *testCA) tlsConfig(t *testing.T) *RemoteSignerTLS {
+	t.Helper()
+
+	certFile, keyFile := c.issue(t, "client")
+	return &RemoteSignerTLS{
+		CAFile:   filepath.Join(c.dir, "ca.pem"),
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}
+}
+
+// testSignerService serves the backend with a certificate of the CA and
+// requires the client certificates of the CA
+func testSignerService(t *testing.T, ca *testCA, backend SignerBackend) string {
+	t.Helper()
+
+	certFile, keyFile := ca.issue(t, "signer")
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	assert.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})))
+	proto.RegisterIbftSignerServer(srv, NewSignerService(backend))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	local := NewLocalSigner(key)
+
+	ca := newTestCA(t)
+	signer, err := NewRemoteSigner(testSignerService(t, ca, local), ca.tlsConfig(t))
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	assert.Equal(t, local.Address(), signer.Address())
+	assert.NoError(t, signer.Health())
+
+	digest := crypto.Keccak256([]byte("digest"))
+	sig, err := signer.Sign(digest)
+	assert.NoError(t, err)
+
+	pub, err := crypto.RecoverPubkey(sig, digest)
+	assert.NoError(t, err)
+	assert.Equal(t, local.Address(), crypto.PubKeyToAddress(pub))
+
+	// the digests are 32 bytes
+	_, err = signer.Sign([]byte{0x1})
+	assert.Error(t, err)
+}
+
+func TestRemoteSigner_WrongKey(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	key1, _ := crypto.GenerateKey()
+
+	backend := &mockSigner{
+		SignerBackend: NewLocalSigner(key0),
+		sign:          NewLocalSigner(key1),
+	}
+	ca := newTestCA(t)
+	signer, err := NewRemoteSigner(testSignerService(t, ca, backend), ca.tlsConfig(t))
+	assert.NoError(t, err)
+	defer signer.Close()
+
+	_, err = signer.Sign(crypto.Keccak256([]byte("digest")))
+	assert.Error(t, err)
+}
+
+func TestRemoteSigner_Unhealthy(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	backend := &mockSigner{
+		SignerBackend: NewLocalSigner(key),
+		health:        fmt.Errorf("hsm not available"),
+	}
+	ca := newTestCA(t)
+	_, err := NewRemoteSigner(testSignerService(t, ca, backend), ca.tlsConfig(t))
+	assert.Error(t, err)
+}
+
+func TestRemoteSigner_TLS(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	ca := newTestCA(t)
+	target := testSignerService(t, ca, NewLocalSigner(key))
+
+	// the tls files are required
+	_, err := NewRemoteSigner(target, nil)
+	assert.Error(t, err)
+
+	// the signer rejects the clients with a certificate of another CA
+	_, err = NewRemoteSigner(target, newTestCA(t).tlsConfig(t))
+	assert.Error(t, err)
+
+	// and the node rejects a signer with a certificate of another CA
+	other := newTestCA(t)
+	config := ca.tlsConfig(t)
+	config.CAFile = filepath.Join(other.dir, "ca.pem")
+	_, err = NewRemoteSigner(target, config)
+	assert.Error(t, err)
+}
@@ -22,6 +22,23 @@ var (
 	nonceDropVote = types.Nonce{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 )
 
+// initSnapshot sets up the snapshots the first time they are needed, the
+// engines activated at a fork verify the first blocks of their range
+// before they are started
+func (i *Ibft) initSnapshot() error {
+	i.snapshotLock.Lock()
+	defer i.snapshotLock.Unlock()
+
+	if i.store != nil {
+		return nil
+	}
+	if err := i.setupSnapshot(); err != nil {
+		i.store = nil
+		return err
+	}
+	return nil
+}
+
 func (i *Ibft) setupSnapshot() error {
 	i.store = newSnapshotStore()
 
@@ -32,13 +49,30 @@ func (i *Ibft) setupSnapshot() error {
 		}
 	}
 
+	// the votes of the stored snapshots are reset at the epochs
+	if epochSize := i.store.epochSize; epochSize != 0 && epochSize != i.epochSize {
+		return fmt.Errorf("the snapshots were built with an epoch size of %d, the config has %d", epochSize, i.epochSize)
+	}
+	i.store.epochSize = i.epochSize
+
 	header := i.blockchain.Header()
 	meta, err := i.getSnapshotMetadata()
 	if err != nil {
 		return err
 	}
 
-	if header.Number == 0 {
+	from := meta.LastBlock + 1
+	if fork := i.config.ForkBlock; fork != 0 {
+		// the first snapshot is the parent of the fork block
+		if i.store.find(fork-1) == nil {
+			if err := i.addForkSnap(fork - 1); err != nil {
+				return err
+			}
+		}
+		if from < fork {
+			from = fork
+		}
+	} else if header.Number == 0 {
 		// add genesis
 		if err := i.addHeaderSnap(header); err != nil {
 			return err
@@ -46,13 +80,10 @@ func (i *Ibft) setupSnapshot() error {
 	}
 
 	// some of the data might get lost due to ungrateful disconnections
-	if header.Number > meta.LastBlock {
-		i.logger.Info("syncing past snapshots", "from", meta.LastBlock, "to", header.Number)
+	if header.Number >= from {
+		i.logger.Info("syncing past snapshots", "from", from-1, "to", header.Number)
 
-		for num := meta.LastBlock + 1; num <= header.Number; num++ {
-			if num == 0 {
-				continue
-			}
+		for num := from; num <= header.Number; num++ {
 			header, ok := i.blockchain.GetHeaderByNumber(num)
 			if !ok {
 				return fmt.Errorf("header %d not found", num)
@@ -75,15 +106,35 @@ func (i *Ibft) addHeaderSnap(header *types.Header) error {
 
 	// create the first snapshot from the genesis
 	snap := &Snapshot{
-		Hash:   header.Hash.String(),
-		Number: header.Number,
-		Votes:  []*Vote{},
-		Set:    extra.Validators,
+		Hash:    header.Hash.String(),
+		Number:  header.Number,
+		Votes:   []*Vote{},
+		Set:     extra.Validators,
+		Weights: i.weights.Copy(),
 	}
 	i.store.add(snap)
 	return nil
 }
 
+// addForkSnap creates the first snapshot with the validators of the config
+// at the block before the fork, its header is sealed by the previous engine
+func (i *Ibft) addForkSnap(num uint64) error {
+	header, ok := i.blockchain.GetHeaderByNumber(num)
+	if !ok {
+		return fmt.Errorf("header %d not found", num)
+	}
+	snap := &Snapshot{
+		Hash:    header.Hash.String(),
+		Number:  header.Number,
+		Votes:   []*Vote{},
+		Set:     i.forkValidators,
+		Weights: i.weights.Copy(),
+	}
+	i.store.add(snap)
+	i.store.updateLastBlock(num)
+	return nil
+}
+
 func (i *Ibft) getLatestSnapshot() (*Snapshot, error) {
 	meta, err := i.getSnapshotMetadata()
 	if err != nil {
@@ -130,109 +181,149 @@ func (i *Ibft) processHeaders(headers []*types.Header) error {
 	}
 
 	for _, h := range headers {
-		number := h.Number
-
-		validator, err := ecrecoverFromHeader(h)
-		if err != nil {
+		// the votes of the header change the set in place
+		validators := append(ValidatorSet{}, snap.Set...)
+		if err := i.applyHeader(snap, h); err != nil {
 			return err
 		}
-		if !snap.Set.Includes(validator) {
-			return fmt.Errorf("unauthroized validator")
+		i.observeLiveness(h, validators)
+		if err := saveSnap(h); err != nil {
+			return err
 		}
 
-		if number%i.epochSize == 0 {
-			// during a checkpoint block, we reset the voles
-			// and there cannot be any proposals
-			snap.Votes = nil
-			if err := saveSnap(h); err != nil {
-				return err
-			}
-
-			// remove in-memory snaphots from two epochs before this one
-			epoch := int(number/i.epochSize) - 2
+		if h.Number%i.epochSize == 0 {
+			// compact the snapshots from two epochs before this one
+			epoch := int(h.Number/i.epochSize) - snapshotRecentEpochs
 			if epoch > 0 {
-				purgeBlock := uint64(epoch) * i.epochSize
-				i.store.deleteLower(purgeBlock)
+				i.store.compact(uint64(epoch)*i.epochSize, i.epochSize)
+				i.store.updateLastBlock(h.Number)
+				if err := i.saveSnapDataToFile(); err != nil {
+					return err
+				}
 			}
-			continue
 		}
+	}
 
-		// if we have a miner address, this might be a vote
-		if h.Miner == types.ZeroAddress {
-			continue
-		}
+	// update the metadata
+	i.store.updateLastBlock(headers[len(headers)-1].Number)
+	return nil
+}
 
-		// the nonce selects the action
-		var authorize bool
-		if h.Nonce == nonceAuthVote {
-			authorize = true
-		} else if h.Nonce == nonceDropVote {
-			authorize = false
-		} else {
-			return fmt.Errorf("incorrect vote nonce")
-		}
+// applyHeader updates the snapshot with the votes of the header
+func (i *Ibft) applyHeader(snap *Snapshot, h *types.Header) error {
+	number := h.Number
 
-		// validate the vote
-		if authorize {
-			// we can only authorize if they are not on the validators list
-			if snap.Set.Includes(h.Miner) {
-				continue
+	validator, err := ecrecoverFromHeader(h)
+	if err != nil {
+		return err
+	}
+	if !snap.Set.Includes(validator) {
+		return fmt.Errorf("unauthroized validator")
+	}
+
+	if number%i.epochSize == 0 {
+		// during a checkpoint block, we reset the voles
+		// and there cannot be any proposals
+		snap.Votes = nil
+
+		if i.validatorContract != nil {
+			// the validators of the next epoch are the ones in the
+			// contract at the state of the parent block
+			parent, ok := i.blockchain.GetHeaderByHash(h.ParentHash)
+			if !ok {
+				return fmt.Errorf("parent of checkpoint block %d not found", number)
 			}
-		} else {
-			// we can only remove if they are part of the validators list
-			if !snap.Set.Includes(h.Miner) {
-				continue
+			validators, err := i.getContractValidators(*i.validatorContract, parent)
+			if err != nil {
+				return err
+			}
+			if i.mechanism == PoS {
+				if validators, err = i.selectStakers(*i.validatorContract, parent, validators); err != nil {
+					return err
+				}
+			}
+			snap.Set = validators
+
+			if i.stakeWeighted {
+				if snap.Weights, err = i.getStakeWeights(*i.validatorContract, parent, validators); err != nil {
+					return err
+				}
 			}
 		}
+		return nil
+	}
 
-		count := snap.Count(func(v *Vote) bool {
-			return v.Validator == validator && v.Address == h.Miner
-		})
-		if count > 1 {
-			// there can only be one vote per validator per address
-			return fmt.Errorf("more than one proposal per validator per address found")
+	// if we have a miner address, this might be a vote
+	if h.Miner == types.ZeroAddress {
+		return nil
+	}
+	if !i.votesEnabled() {
+		return fmt.Errorf("votes are not allowed in %s", i.mechanism)
+	}
+
+	// the nonce selects the action
+	var authorize bool
+	if h.Nonce == nonceAuthVote {
+		authorize = true
+	} else if h.Nonce == nonceDropVote {
+		authorize = false
+	} else {
+		return fmt.Errorf("incorrect vote nonce")
+	}
+
+	// validate the vote
+	if authorize {
+		// we can only authorize if they are not on the validators list
+		if snap.Set.Includes(h.Miner) {
+			return nil
 		}
-		if count == 0 {
-			// cast the new vote since there is no one yet
-			snap.Votes = append(snap.Votes, &Vote{
-				Validator: validator,
-				Address:   h.Miner,
-				Authorize: authorize,
-			})
+	} else {
+		// we can only remove if they are part of the validators list
+		if !snap.Set.Includes(h.Miner) {
+			return nil
 		}
+	}
 
-		// check the tally for the proposed validator
-		tally := snap.Count(func(v *Vote) bool {
-			return v.Address == h.Miner
+	count := snap.Count(func(v *Vote) bool {
+		return v.Validator == validator && v.Address == h.Miner
+	})
+	if count > 1 {
+		// there can only be one vote per validator per address
+		return fmt.Errorf("more than one proposal per validator per address found")
+	}
+	if count == 0 {
+		// cast the new vote since there is no one yet
+		snap.Votes = append(snap.Votes, &Vote{
+			Validator: validator,
+			Address:   h.Miner,
+			Authorize: authorize,
 		})
+	}
 
-		if tally > snap.Set.Len()/2 {
-			if authorize {
-				// add the proposal to the validator list
-				snap.Set.Add(h.Miner)
-			} else {
-				// remove the proposal from the validators list
-				snap.Set.Del(h.Miner)
-
-				// remove any votes casted by the removed validator
-				snap.RemoveVotes(func(v *Vote) bool {
-					return v.Validator == h.Miner
-				})
-			}
+	// check the tally for the proposed validator
+	tally := snap.Count(func(v *Vote) bool {
+		return v.Address == h.Miner
+	})
 
-			// remove all the votes that promoted this validator
+	if tally > snap.Set.Len()/2 {
+		if authorize {
+			// add the proposal to the validator list
+			snap.Set.Add(h.Miner)
+		} else {
+			// remove the proposal from the validators list
+			snap.Set.Del(h.Miner)
+
+			// remove any votes casted by the removed validator
 			snap.RemoveVotes(func(v *Vote) bool {
-				return v.Address == h.Miner
+				return v.Validator == h.Miner
 			})
 		}
 
-		if err := saveSnap(h); err != nil {
-			return nil
-		}
+		// remove all the votes that promoted this validator
+		snap.RemoveVotes(func(v *Vote) bool {
+			return v.Address == h.Miner
+		})
 	}
-
-	// update the metadata
-	i.store.updateLastBlock(headers[len(headers)-1].Number)
 	return nil
 }
 
@@ -244,10 +335,39 @@ func (i *Ibft) getSnapshotMetadata() (*snapshotMetadata, error) {
 }
 
 func (i *Ibft) getSnapshot(num uint64) (*Snapshot, error) {
+	if num < i.store.getCompacted() {
+		return i.rebuildSnapshot(num)
+	}
 	snap := i.store.find(num)
 	return snap, nil
 }
 
+// rebuildSnapshot replays the headers since the epoch boundary
+// to get a snapshot removed by the compaction
+func (i *Ibft) rebuildSnapshot(num uint64) (*Snapshot, error) {
+	base := i.store.find(num - num%i.epochSize)
+	if base == nil {
+		return nil, fmt.Errorf("snapshot for block %d not found", num)
+	}
+	snap := base.Copy()
+	snap.Number, snap.Hash = base.Number, base.Hash
+
+	for n := base.Number + 1; n <= num; n++ {
+		h, ok := i.blockchain.GetHeaderByNumber(n)
+		if !ok {
+			return nil, fmt.Errorf("header %d not found", n)
+		}
+		prev := snap.Copy()
+		if err := i.applyHeader(snap, h); err != nil {
+			return nil, err
+		}
+		if !snap.Equal(prev) {
+			snap.Number, snap.Hash = h.Number, h.Hash.String()
+		}
+	}
+	return snap, nil
+}
+
 type Vote struct {
 	Validator types.Address
 	Address   types.Address
@@ -286,10 +406,20 @@ type Snapshot struct {
 
 	// current set of validators
 	Set ValidatorSet
+
+	// voting power of the validators, if they are weighted
+	Weights Weights `json:",omitempty"`
 }
 
 type snapshotMetadata struct {
 	LastBlock uint64
+
+	// Compacted is the block below which only the snapshots
+	// at the epoch boundaries are stored
+	Compacted uint64
+
+	// EpochSize is the epoch of the votes of the snapshots
+	EpochSize uint64
 }
 
 func (s *Snapshot) Equal(ss *Snapshot) bool {
@@ -306,6 +436,9 @@ func (s *Snapshot) Equal(ss *Snapshot) bool {
 	if !s.Set.Equal(&ss.Set) {
 		return false
 	}
+	if !s.Weights.Equal(ss.Weights) {
+		return false
+	}
 	return true
 }
 
@@ -337,6 +470,7 @@ func (s *Snapshot) Copy() *Snapshot {
 		ss.Votes[indx] = vote.Copy()
 	}
 	ss.Set = append(ss.Set, s.Set...)
+	ss.Weights = s.Weights.Copy()
 	return ss
 }
 
@@ -361,13 +495,20 @@ func (s *Snapshot) ToProto() *proto.Snapshot {
 	for _, val := range s.Set {
 		resp.Validators = append(resp.Validators, &proto.Snapshot_Validator{
 			Address: val.String(),
+			Weight:  s.Weights.Get(val),
 		})
 	}
 	return resp
 }
 
+// snapshotRecentEpochs is the number of epochs for which all the snapshots are
+// kept, the older ones are rebuilt from the snapshots at the epoch boundaries
+const snapshotRecentEpochs = 2
+
 type snapshotStore struct {
 	lastNumber uint64
+	compacted  uint64
+	epochSize  uint64
 	lock       sync.Mutex
 	list       snapshotSortedList
 }
@@ -386,6 +527,8 @@ func (s *snapshotStore) loadFromPath(path string) error {
 	}
 	if meta != nil {
 		s.lastNumber = meta.LastBlock
+		s.compacted = meta.Compacted
+		s.epochSize = meta.EpochSize
 	}
 
 	// load snapshots
@@ -407,7 +550,9 @@ func (s *snapshotStore) saveToPath(path string) error {
 
 	// write metadata
 	meta := &snapshotMetadata{
-		LastBlock: s.lastNumber,
+		LastBlock: s.getLastBlock(),
+		Compacted: s.getCompacted(),
+		EpochSize: s.epochSize,
 	}
 	if err := writeDataStore(filepath.Join(path, "metadata"), meta); err != nil {
 		return err
@@ -423,14 +568,33 @@ func (s *snapshotStore) updateLastBlock(num uint64) {
 	atomic.StoreUint64(&s.lastNumber, num)
 }
 
-func (s *snapshotStore) deleteLower(num uint64) {
+func (s *snapshotStore) getCompacted() uint64 {
+	return atomic.LoadUint64(&s.compacted)
+}
+
+// compact removes the snapshots lower than num, which has to be an epoch
+// boundary, except the ones in use at the epoch boundaries
+func (s *snapshotStore) compact(num, epochSize uint64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	i := sort.Search(len(s.list), func(i int) bool {
-		return s.list[i].Number >= num
-	})
-	s.list = s.list[i:]
+	list := snapshotSortedList{}
+	for indx, snap := range s.list {
+		if snap.Number >= num || indx == len(s.list)-1 {
+			list = append(list, s.list[indx:]...)
+			break
+		}
+		// the snapshot is in use until the next one
+		boundary := (snap.Number + epochSize - 1) / epochSize * epochSize
+		if boundary < s.list[indx+1].Number {
+			list = append(list, snap)
+		}
+	}
+	s.list = list
+
+	if num > s.getCompacted() {
+		atomic.StoreUint64(&s.compacted, num)
+	}
 }
 
 func (s *snapshotStore) find(num uint64) *Snapshot {
@@ -461,6 +625,17 @@ func (s *snapshotStore) find(num uint64) *Snapshot {
 	return nil
 }
 
+// truncate removes the snapshots after num
+func (s *snapshotStore) truncate(num uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := sort.Search(len(s.list), func(i int) bool {
+		return s.list[i].Number > num
+	})
+	s.list = s.list[:i]
+}
+
 func (s *snapshotStore) add(snap *Snapshot) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -503,7 +678,12 @@ func writeDataStore(path string, obj interface{}) error {
 	if err != nil {
 		return err
 	}
-	if err := ioutil.WriteFile(path, data, 0755); err != nil {
+	// write a temporary file first to not lose the data if the node stops
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
 		return err
 	}
 	return nil
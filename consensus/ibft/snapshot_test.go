@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strconv"
 	"testing"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/0xPolygon/minimal/consensus"
 	"github.com/0xPolygon/minimal/crypto"
 	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,7 +27,7 @@ func (t *testerAccount) Address() types.Address {
 }
 
 func (t *testerAccount) sign(h *types.Header) *types.Header {
-	h, _ = writeSeal(t.priv, h)
+	h, _ = writeSeal(NewLocalSigner(t.priv), h)
 	return h
 }
 
@@ -534,7 +536,138 @@ func TestSnapshot_PurgeSnapshots(t *testing.T) {
 	err := ibft1.processHeaders(headers)
 	assert.NoError(t, err)
 
-	assert.Equal(t, len(ibft1.store.list), 21)
+	// the snapshots of the last two epochs plus the ones at the boundaries 0, 10 and 20
+	assert.Equal(t, len(ibft1.store.list), 24)
+	assert.Equal(t, ibft1.store.getCompacted(), uint64(30))
+}
+
+func TestSnapshot_CompactRebuild(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "snapshot-compact")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	pool := newTesterAccountPool()
+	pool.add("a", "b", "c")
+
+	genesis := pool.genesis()
+	b := blockchain.TestBlockchain(t, genesis)
+	ibft1 := &Ibft{
+		epochSize:  5,
+		blockchain: b,
+		config:     &consensus.Config{Path: tmpDir},
+	}
+	assert.NoError(t, ibft1.setupSnapshot())
+
+	// snapshots before they are compacted
+	expected := []*Snapshot{ibft1.store.find(0)}
+
+	for i := 1; i <= 30; i++ {
+		h := &types.Header{
+			Number:     uint64(i),
+			ParentHash: b.Header().Hash,
+			MixHash:    IstanbulDigest,
+			ExtraData:  genesis.ExtraData,
+		}
+		if i%3 != 0 {
+			id := strconv.Itoa(i)
+			pool.add(id)
+
+			h.Miner = pool.get(id).Address()
+			h.Nonce = nonceAuthVote
+		}
+		h = pool.get("a").sign(h)
+		h.ComputeHash()
+
+		assert.NoError(t, ibft1.processHeaders([]*types.Header{h}))
+		assert.NoError(t, b.WriteHeaders([]*types.Header{h}))
+
+		expected = append(expected, ibft1.store.find(uint64(i)))
+	}
+	assert.Equal(t, ibft1.store.getCompacted(), uint64(20))
+
+	check := func(ibft *Ibft) {
+		for num, snap := range expected {
+			found, err := ibft.getSnapshot(uint64(num))
+			assert.NoError(t, err)
+			assert.True(t, snap.Equal(found))
+			assert.Equal(t, snap.Number, found.Number)
+			assert.Equal(t, snap.Hash, found.Hash)
+		}
+	}
+	check(ibft1)
+
+	// the compacted store is written to disk at the checkpoints
+	ibft2 := &Ibft{
+		logger:     hclog.NewNullLogger(),
+		epochSize:  5,
+		blockchain: b,
+		config:     &consensus.Config{Path: tmpDir},
+	}
+	assert.NoError(t, ibft2.setupSnapshot())
+	assert.Equal(t, ibft1.store.list, ibft2.store.list)
+	check(ibft2)
+
+	// the snapshots cannot be used with another epoch size
+	ibft3 := &Ibft{
+		logger:     hclog.NewNullLogger(),
+		epochSize:  10,
+		blockchain: b,
+		config:     &consensus.Config{Path: tmpDir},
+	}
+	assert.Error(t, ibft3.setupSnapshot())
+}
+
+func TestSnapshot_ForkBlock(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("a", "b")
+
+	genesis := pool.genesis()
+	b := blockchain.TestBlockchain(t, genesis)
+
+	// the blocks before the fork are sealed by another engine
+	for i := 1; i <= 4; i++ {
+		h := &types.Header{
+			Number:     uint64(i),
+			ParentHash: b.Header().Hash,
+		}
+		if i >= 3 {
+			h.MixHash = IstanbulDigest
+			h.ExtraData = genesis.ExtraData
+			h = pool.get("b").sign(h)
+		}
+		h.ComputeHash()
+		assert.NoError(t, b.WriteHeaders([]*types.Header{h}))
+	}
+
+	ibft := &Ibft{
+		logger:         hclog.NewNullLogger(),
+		epochSize:      10,
+		blockchain:     b,
+		config:         &consensus.Config{ForkBlock: 3},
+		forkValidators: ValidatorSet{pool.get("b").Address()},
+	}
+	assert.NoError(t, ibft.setupSnapshot())
+
+	snap, err := ibft.getSnapshot(2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), snap.Number)
+	assert.Equal(t, ibft.forkValidators, snap.Set)
+
+	// the blocks since the fork are sealed by the fork validators
+	snap, err = ibft.getSnapshot(4)
+	assert.NoError(t, err)
+	assert.Equal(t, ibft.forkValidators, snap.Set)
+	assert.Equal(t, uint64(4), ibft.store.getLastBlock())
+
+	h := &types.Header{
+		Number:     5,
+		ParentHash: b.Header().Hash,
+		MixHash:    IstanbulDigest,
+		ExtraData:  genesis.ExtraData,
+	}
+	h = pool.get("a").sign(h)
+	h.ComputeHash()
+	assert.Error(t, ibft.processHeaders([]*types.Header{h}))
 }
 
 func TestSnapshot_Store_SaveLoad(t *testing.T) {
@@ -0,0 +1,284 @@
+package ibft
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/0xPolygon/minimal/state/runtime/evm"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// StakingContractAddr is the address of the staking system contract that
+// holds the validator set of the PoS chains
+var StakingContractAddr = types.StringToAddress("0x0000000000000000000000000000000000001001")
+
+// The staking contract has the methods:
+//
+//	validators() returns (address[])       the validators in order of staking
+//	stakedAmount(address) returns (uint256) the amount staked by an address
+//	stake() payable                         stakes the value, the first stake adds the sender
+//	unstake()                               returns the stake and removes the sender
+//
+// The last validator cannot unstake. A stake fails if the total stake of the
+// sender is below the minimum stake or if it would add a validator past the
+// maximum number of validators. The changes of the validators take effect at
+// the next epoch, when the validators of the contract are read.
+var (
+	stakedAmountMethodID = methodID("stakedAmount(address)")
+	stakeMethodID        = methodID("stake()")
+	unstakeMethodID      = methodID("unstake()")
+)
+
+// The storage of the contract is laid out as:
+//
+//	slot 0                 number of validators n
+//	slot 1..n              the validators
+//	address | stakePrefix  stake of the address
+//	address | indexPrefix  position of the validator plus one
+var (
+	stakePrefix = new(big.Int).Lsh(big.NewInt(1), 160)
+	indexPrefix = new(big.Int).Lsh(big.NewInt(2), 160)
+)
+
+func stakingSlot(n uint64) types.Hash {
+	return types.BytesToHash(new(big.Int).SetUint64(n).Bytes())
+}
+
+func stakingKey(prefix *big.Int, addr types.Address) types.Hash {
+	return types.BytesToHash(new(big.Int).Or(prefix, new(big.Int).SetBytes(addr.Bytes())).Bytes())
+}
+
+// StakingContractStorage returns the genesis storage of the staking contract
+// with the initial validators and their stake, if any. The balance of the
+// contract has to hold the stakes
+func StakingContractStorage(validators []types.Address, stake *big.Int) map[types.Hash]types.Hash {
+	storage := map[types.Hash]types.Hash{
+		stakingSlot(0): stakingSlot(uint64(len(validators))),
+	}
+	for indx, val := range validators {
+		storage[stakingSlot(uint64(indx+1))] = types.BytesToHash(val.Bytes())
+		storage[stakingKey(indexPrefix, val)] = stakingSlot(uint64(indx + 1))
+		if stake != nil && stake.Sign() != 0 {
+			storage[stakingKey(stakePrefix, val)] = types.BytesToHash(stake.Bytes())
+		}
+	}
+	return storage
+}
+
+// StakingContractCode returns the runtime code of the staking contract with
+// the minimum stake and the maximum number of validators, there is no limit
+// if they are nil or zero
+func StakingContractCode(minStake *big.Int, maxValidators uint64) []byte {
+	a := newAssembler()
+
+	// dispatch on the method id, the first 4 bytes of the calldata
+	a.push(big.NewInt(0))
+	a.op(evm.CALLDATALOAD)
+	a.push(new(big.Int).Lsh(big.NewInt(1), 224))
+	a.op(evm.SWAP1, evm.DIV)
+	for _, method := range []struct {
+		id    []byte
+		label string
+	}{
+		{validatorsMethodID, "validators"},
+		{stakedAmountMethodID, "stakedAmount"},
+		{stakeMethodID, "stake"},
+		{unstakeMethodID, "unstake"},
+	} {
+		a.op(evm.DUP1)
+		a.pushBytes(method.id)
+		a.op(evm.EQ)
+		a.jumpi(method.label)
+	}
+	a.label("revert")
+	a.push(big.NewInt(0))
+	a.op(evm.DUP1, evm.REVERT)
+
+	// validators(): abi encode the list of slots 1..n
+	a.label("validators")
+	a.push(big.NewInt(0x20))
+	a.push(big.NewInt(0))
+	a.op(evm.MSTORE)
+	a.push(big.NewInt(0))
+	a.op(evm.SLOAD, evm.DUP1)
+	a.push(big.NewInt(0x20))
+	a.op(evm.MSTORE)
+	a.push(big.NewInt(0)) // [n, i]
+	a.label("validatorsLoop")
+	a.op(evm.DUP1+1, evm.DUP1+1, evm.LT, evm.ISZERO)
+	a.jumpi("validatorsEnd")
+	a.op(evm.DUP1)
+	a.push(big.NewInt(1))
+	a.op(evm.ADD, evm.SLOAD, evm.DUP1+1)
+	a.push(big.NewInt(0x20))
+	a.op(evm.MUL)
+	a.push(big.NewInt(0x40))
+	a.op(evm.ADD, evm.MSTORE)
+	a.push(big.NewInt(1))
+	a.op(evm.ADD)
+	a.jump("validatorsLoop")
+	a.label("validatorsEnd")
+	a.op(evm.POP)
+	a.push(big.NewInt(0x20))
+	a.op(evm.MUL)
+	a.push(big.NewInt(0x40))
+	a.op(evm.ADD)
+	a.push(big.NewInt(0))
+	a.op(evm.RETURN)
+
+	// stakedAmount(address)
+	a.label("stakedAmount")
+	a.push(big.NewInt(4))
+	a.op(evm.CALLDATALOAD)
+	a.push(new(big.Int).Sub(stakePrefix, big.NewInt(1)))
+	a.op(evm.AND)
+	a.push(stakePrefix)
+	a.op(evm.OR, evm.SLOAD)
+	a.push(big.NewInt(0))
+	a.op(evm.MSTORE)
+	a.push(big.NewInt(0x20))
+	a.push(big.NewInt(0))
+	a.op(evm.RETURN)
+
+	// stake(): add the value to the stake and the sender to the validators
+	a.label("stake")
+	a.op(evm.CALLVALUE, evm.ISZERO)
+	a.jumpi("revert")
+	a.op(evm.CALLER)
+	a.push(stakePrefix)
+	a.op(evm.OR, evm.DUP1, evm.SLOAD, evm.CALLVALUE, evm.ADD) // [key, amount]
+	if minStake != nil && minStake.Sign() != 0 {
+		a.op(evm.DUP1)
+		a.push(minStake)
+		a.op(evm.GT)
+		a.jumpi("revert")
+	}
+	a.op(evm.SWAP1, evm.SSTORE)
+	a.op(evm.CALLER)
+	a.push(indexPrefix)
+	a.op(evm.OR, evm.SLOAD)
+	a.jumpi("stop")
+	if maxValidators != 0 {
+		a.push(new(big.Int).SetUint64(maxValidators))
+		a.push(big.NewInt(0))
+		a.op(evm.SLOAD, evm.LT, evm.ISZERO) // n < max
+		a.jumpi("revert")
+	}
+	a.push(big.NewInt(0))
+	a.op(evm.SLOAD, evm.CALLER, evm.DUP1+1) // [n, caller, n]
+	a.push(big.NewInt(1))
+	a.op(evm.ADD, evm.SSTORE)
+	a.push(big.NewInt(1))
+	a.op(evm.ADD, evm.DUP1, evm.CALLER) // [n+1, n+1, caller]
+	a.push(indexPrefix)
+	a.op(evm.OR, evm.SSTORE)
+	a.push(big.NewInt(0))
+	a.op(evm.SSTORE)
+	a.label("stop")
+	a.op(evm.STOP)
+
+	// unstake(): move the last validator to the position of the sender
+	// and send back the stake
+	a.label("unstake")
+	a.op(evm.CALLVALUE)
+	a.jumpi("revert")
+	a.op(evm.CALLER)
+	a.push(indexPrefix)
+	a.op(evm.OR, evm.SLOAD, evm.DUP1, evm.ISZERO) // [pos]
+	a.jumpi("revert")
+	a.push(big.NewInt(0))
+	a.op(evm.SLOAD, evm.DUP1) // [pos, n, n]
+	a.push(big.NewInt(1))
+	a.op(evm.EQ)
+	a.jumpi("revert")
+	a.op(evm.DUP1, evm.SLOAD)              // [pos, n, last]
+	a.op(evm.DUP1, evm.DUP1+3, evm.SSTORE) // slot pos = last
+	a.op(evm.DUP1+2, evm.SWAP1)            // [pos, n, pos, last]
+	a.push(indexPrefix)
+	a.op(evm.OR, evm.SSTORE) // index of last = pos
+	a.push(big.NewInt(0))
+	a.op(evm.DUP1+1, evm.SSTORE) // slot n = 0
+	a.push(big.NewInt(1))
+	a.op(evm.SWAP1, evm.SUB)
+	a.push(big.NewInt(0))
+	a.op(evm.SSTORE, evm.POP) // n = n - 1
+	a.push(big.NewInt(0))
+	a.op(evm.CALLER)
+	a.push(indexPrefix)
+	a.op(evm.OR, evm.SSTORE) // index of sender = 0
+	a.op(evm.CALLER)
+	a.push(stakePrefix)
+	a.op(evm.OR, evm.DUP1, evm.SLOAD) // [key, amount]
+	a.push(big.NewInt(0))
+	a.op(evm.DUP1+2, evm.SSTORE) // stake of sender = 0
+	a.push(big.NewInt(0))
+	a.op(evm.DUP1, evm.DUP1, evm.DUP1, evm.DUP1+4, evm.CALLER, evm.GAS, evm.CALL, evm.ISZERO)
+	a.jumpi("revert")
+	a.op(evm.STOP)
+
+	return a.bytes()
+}
+
+// assembler writes evm bytecode with labels for the jumps
+type assembler struct {
+	code   []byte
+	labels map[string]int
+	jumps  map[int]string
+}
+
+func newAssembler() *assembler {
+	return &assembler{
+		labels: map[string]int{},
+		jumps:  map[int]string{},
+	}
+}
+
+func (a *assembler) op(ops ...evm.OpCode) {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+}
+
+func (a *assembler) pushBytes(b []byte) {
+	a.code = append(a.code, evm.PUSH1+byte(len(b))-1)
+	a.code = append(a.code, b...)
+}
+
+func (a *assembler) push(v *big.Int) {
+	b := v.Bytes()
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	a.pushBytes(b)
+}
+
+func (a *assembler) label(name string) {
+	a.labels[name] = len(a.code)
+	a.code = append(a.code, evm.JUMPDEST)
+}
+
+func (a *assembler) pushLabel(name string) {
+	a.jumps[len(a.code)+1] = name
+	a.pushBytes([]byte{0, 0})
+}
+
+func (a *assembler) jump(name string) {
+	a.pushLabel(name)
+	a.op(evm.JUMP)
+}
+
+func (a *assembler) jumpi(name string) {
+	a.pushLabel(name)
+	a.op(evm.JUMPI)
+}
+
+func (a *assembler) bytes() []byte {
+	for pos, name := range a.jumps {
+		dst, ok := a.labels[name]
+		if !ok {
+			panic(fmt.Sprintf("BUG: label %s not found", name))
+		}
+		a.code[pos], a.code[pos+1] = byte(dst>>8), byte(dst)
+	}
+	return a.code
+}
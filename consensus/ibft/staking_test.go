@@ -0,0 +1,313 @@
+package ibft
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/state"
+	itrie "github.com/0xPolygon/minimal/state/immutable-trie"
+	"github.com/0xPolygon/minimal/state/runtime/evm"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStakingExecutor(t *testing.T, validators []types.Address, balances map[types.Address]*big.Int, minStake *big.Int, maxValidators uint64) (*state.Executor, types.Hash) {
+	t.Helper()
+
+	executor := state.NewExecutor(&chain.Params{Forks: chain.AllForksEnabled, ChainID: 100}, itrie.NewState(itrie.NewMemoryStorage()))
+	executor.SetRuntime(evm.NewEVM())
+	executor.GetHash = func(*types.Header) state.GetHashByNumber {
+		return func(uint64) types.Hash {
+			return types.Hash{}
+		}
+	}
+
+	alloc := map[types.Address]*chain.GenesisAccount{
+		StakingContractAddr: {
+			Code:    StakingContractCode(minStake, maxValidators),
+			Storage: StakingContractStorage(validators, minStake),
+		},
+	}
+	for addr, balance := range balances {
+		alloc[addr] = &chain.GenesisAccount{Balance: balance}
+	}
+	return executor, executor.WriteGenesis(alloc)
+}
+
+func TestStakingContract(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C", "D")
+
+	addr := func(name string) types.Address {
+		return pool.get(name).Address()
+	}
+
+	executor, root := newStakingExecutor(t, []types.Address{addr("A"), addr("B")}, map[types.Address]*big.Int{
+		addr("C"): big.NewInt(100),
+		addr("D"): big.NewInt(100),
+	}, nil, 0)
+	ibft := &Ibft{executor: executor}
+
+	header := &types.Header{Number: 1, GasLimit: 10000000}
+	nonces := map[types.Address]uint64{}
+
+	// apply sends a transaction to the staking contract in a new block and
+	// returns whether it succeeds
+	apply := func(from string, input []byte, value int64) bool {
+		transition, err := executor.BeginTxn(root, header)
+		assert.NoError(t, err)
+
+		txn := &types.Transaction{
+			Nonce:    nonces[addr(from)],
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &StakingContractAddr,
+			Value:    big.NewInt(value),
+			Input:    input,
+			From:     addr(from),
+		}
+		assert.NoError(t, transition.Write(txn))
+		nonces[addr(from)]++
+
+		_, root = transition.Commit()
+		return transition.Receipts()[0].Status != nil && *transition.Receipts()[0].Status == types.ReceiptSuccess
+	}
+
+	expectValidators := func(names ...string) {
+		t.Helper()
+
+		validators, err := ibft.getContractValidators(StakingContractAddr, &types.Header{StateRoot: root})
+		assert.NoError(t, err)
+
+		expected := ValidatorSet{}
+		for _, name := range names {
+			expected = append(expected, addr(name))
+		}
+		assert.Equal(t, expected, validators)
+	}
+
+	expectStake := func(name string, amount uint64) {
+		t.Helper()
+
+		transition, err := executor.BeginTxn(root, header)
+		assert.NoError(t, err)
+
+		input := append(append([]byte{}, stakedAmountMethodID...), types.BytesToHash(addr(name).Bytes()).Bytes()...)
+		res, _, err := transition.Call2(types.ZeroAddress, StakingContractAddr, input, big.NewInt(0), queryGasLimit)
+		assert.NoError(t, err)
+		assert.Equal(t, amount, new(big.Int).SetBytes(res).Uint64())
+	}
+
+	expectBalance := func(name string, amount int64) {
+		t.Helper()
+
+		transition, err := executor.BeginTxn(root, header)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(amount), transition.GetBalance(addr(name)))
+	}
+
+	expectValidators("A", "B")
+
+	// the first stake adds the sender to the validators
+	assert.True(t, apply("C", stakeMethodID, 10))
+	expectValidators("A", "B", "C")
+	expectStake("C", 10)
+
+	// the next stakes only increase the amount
+	assert.True(t, apply("C", stakeMethodID, 5))
+	expectValidators("A", "B", "C")
+	expectStake("C", 15)
+	expectBalance("C", 85)
+
+	// a stake without value fails
+	assert.False(t, apply("D", stakeMethodID, 0))
+
+	// only the validators can unstake
+	assert.False(t, apply("D", unstakeMethodID, 0))
+
+	// the last validator takes the position of the one that leaves
+	assert.True(t, apply("A", unstakeMethodID, 0))
+	expectValidators("C", "B")
+
+	// the stake is returned
+	assert.True(t, apply("C", unstakeMethodID, 0))
+	expectValidators("B")
+	expectStake("C", 0)
+	expectBalance("C", 100)
+
+	// the last validator cannot leave
+	assert.False(t, apply("B", unstakeMethodID, 0))
+	expectValidators("B")
+
+	// unknown methods fail
+	assert.False(t, apply("D", []byte{0x1, 0x2, 0x3, 0x4}, 0))
+}
+
+func TestSnapshot_PoS(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C")
+
+	// the staking contract has different validators than the genesis
+	executor, root := newStakingExecutor(t, []types.Address{pool.get("A").Address(), pool.get("C").Address()}, nil, nil, 0)
+
+	genesis := pool.genesis()
+	genesis.StateRoot = root
+
+	b := blockchain.TestBlockchain(t, genesis)
+	ibft := &Ibft{
+		epochSize:         2,
+		mechanism:         PoS,
+		validatorContract: &StakingContractAddr,
+		blockchain:        b,
+		executor:          executor,
+		config:            &consensus.Config{},
+	}
+	assert.NoError(t, ibft.setupSnapshot())
+
+	newHeader := func(signer string, vote string) *types.Header {
+		h := &types.Header{
+			Number:     b.Header().Number + 1,
+			ParentHash: b.Header().Hash,
+			MixHash:    IstanbulDigest,
+			StateRoot:  root,
+			ExtraData:  genesis.ExtraData,
+		}
+		if vote != "" {
+			h.Miner = pool.get(vote).Address()
+			h.Nonce = nonceDropVote
+		}
+		h = pool.get(signer).sign(h)
+		h.ComputeHash()
+		return h
+	}
+
+	// the votes are not allowed
+	assert.Error(t, ibft.processHeaders([]*types.Header{newHeader("A", "B")}))
+
+	header := newHeader("B", "")
+	assert.NoError(t, ibft.processHeaders([]*types.Header{header}))
+	assert.NoError(t, b.WriteHeaders([]*types.Header{header}))
+
+	// the checkpoint reads the validators from the staking contract
+	header = newHeader("B", "")
+	assert.NoError(t, ibft.processHeaders([]*types.Header{header}))
+	assert.NoError(t, b.WriteHeaders([]*types.Header{header}))
+
+	snap, err := ibft.getSnapshot(2)
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSet{pool.get("A").Address(), pool.get("C").Address()}, snap.Set)
+
+	// B is not a validator in the next epoch
+	assert.Error(t, ibft.processHeaders([]*types.Header{newHeader("B", "")}))
+	assert.NoError(t, ibft.processHeaders([]*types.Header{newHeader("C", "")}))
+}
+
+func TestStakingContract_Weights(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B")
+
+	addr := func(name string) types.Address {
+		return pool.get(name).Address()
+	}
+
+	ether := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), stakeWeightUnit)
+	}
+	executor, root := newStakingExecutor(t, []types.Address{addr("A")}, map[types.Address]*big.Int{
+		addr("B"): ether(10),
+	}, nil, 0)
+	ibft := &Ibft{executor: executor}
+
+	header := &types.Header{Number: 1, GasLimit: 10000000}
+	transition, err := executor.BeginTxn(root, header)
+	assert.NoError(t, err)
+	assert.NoError(t, transition.Write(&types.Transaction{
+		GasPrice: big.NewInt(0),
+		Gas:      1000000,
+		To:       &StakingContractAddr,
+		Value:    new(big.Int).Add(ether(3), big.NewInt(1)),
+		Input:    stakeMethodID,
+		From:     addr("B"),
+	}))
+	_, root = transition.Commit()
+
+	validators, err := ibft.getContractValidators(StakingContractAddr, &types.Header{StateRoot: root})
+	assert.NoError(t, err)
+
+	// A is a genesis validator without stake
+	weights, err := ibft.getStakeWeights(StakingContractAddr, &types.Header{StateRoot: root}, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, Weights{addr("A"): 1, addr("B"): 3}, weights)
+}
+
+func TestStakingContract_Limits(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C", "D")
+
+	addr := func(name string) types.Address {
+		return pool.get(name).Address()
+	}
+
+	executor, root := newStakingExecutor(t, []types.Address{addr("A")}, map[types.Address]*big.Int{
+		addr("B"): big.NewInt(100),
+		addr("C"): big.NewInt(100),
+		addr("D"): big.NewInt(100),
+	}, big.NewInt(10), 2)
+
+	header := &types.Header{Number: 1, GasLimit: 10000000}
+	nonces := map[types.Address]uint64{}
+
+	stake := func(from string, value int64) bool {
+		transition, err := executor.BeginTxn(root, header)
+		assert.NoError(t, err)
+
+		_, failed, err := transition.Apply(&types.Transaction{
+			Nonce:    nonces[addr(from)],
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &StakingContractAddr,
+			Value:    big.NewInt(value),
+			Input:    stakeMethodID,
+			From:     addr(from),
+		})
+		assert.NoError(t, err)
+		nonces[addr(from)]++
+
+		_, root = transition.Commit()
+		return !failed
+	}
+
+	// the stake has to reach the minimum
+	assert.False(t, stake("B", 9))
+	assert.True(t, stake("B", 10))
+
+	// the contract is full
+	assert.False(t, stake("C", 10))
+
+	// the validators can increase their stake
+	assert.True(t, stake("B", 1))
+
+	ibft := &Ibft{executor: executor, minStake: big.NewInt(10)}
+	validators, err := ibft.getContractValidators(StakingContractAddr, &types.Header{StateRoot: root})
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSet{addr("A"), addr("B")}, validators)
+
+	// the genesis validators have the minimum stake
+	selected, err := ibft.selectStakers(StakingContractAddr, &types.Header{StateRoot: root}, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, validators, selected)
+
+	// the snapshot applies the limits of the config if they are raised
+	ibft.minStake = big.NewInt(11)
+	selected, err = ibft.selectStakers(StakingContractAddr, &types.Header{StateRoot: root}, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSet{addr("B")}, selected)
+
+	ibft.minStake, ibft.maxValidators = nil, 1
+	selected, err = ibft.selectStakers(StakingContractAddr, &types.Header{StateRoot: root}, validators)
+	assert.NoError(t, err)
+	assert.Equal(t, ValidatorSet{addr("A")}, selected)
+}
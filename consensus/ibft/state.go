@@ -43,6 +43,14 @@ type currentState struct {
 	// the snapshot being currently used
 	validators ValidatorSet
 
+	// weights are the voting power of the validators, all
+	// of them have the same power if it is nil
+	weights Weights
+
+	// twoThirds is whether the quorum of the sequence is more than
+	// two thirds of the voting power, see ValidatorSet.QuorumWeight
+	twoThirds bool
+
 	// state is the current state
 	state uint64
 
@@ -91,9 +99,19 @@ func (c *currentState) setState(s IbftState) {
 	atomic.StoreUint64(stateAddr, uint64(s))
 }
 
-func (c *currentState) NumValid() int {
-	// represents the number of required messages
-	return 2 * c.validators.MinFaultyNodes()
+// QuorumWeight is the weight of the messages required to prepare, commit
+// and change the round
+func (c *currentState) QuorumWeight() uint64 {
+	return c.validators.QuorumWeight(c.weights, c.twoThirds)
+}
+
+// RoundQuorumWeight is the weight of the round changes that start a round,
+// without twoThirds it is 2F with F the max faulty weight
+func (c *currentState) RoundQuorumWeight() uint64 {
+	if !c.twoThirds {
+		return 2 * c.validators.MaxFaultyWeight(c.weights)
+	}
+	return c.QuorumWeight()
 }
 
 // getErr returns the current error if any and consumes it
@@ -104,10 +122,10 @@ func (c *currentState) getErr() error {
 }
 
 func (c *currentState) maxRound() (maxRound uint64, found bool) {
-	num := c.validators.MinFaultyNodes() + 1
+	num := c.validators.MaxFaultyWeight(c.weights) + 1
 
 	for k, round := range c.roundMessages {
-		if len(round) < num {
+		if c.weights.Sum(round) < num {
 			continue
 		}
 		if maxRound < k {
@@ -124,8 +142,8 @@ func (c *currentState) resetRoundMsgs() {
 	c.roundMessages = map[uint64]map[types.Address]*proto.MessageReq{}
 }
 
-func (c *currentState) CalcProposer(lastProposer types.Address) {
-	c.proposer = c.validators.CalcProposer(c.view.Round, lastProposer)
+func (c *currentState) CalcProposer(policy ProposerPolicy, lastProposer types.Address, parent *types.Header) {
+	c.proposer = policy.CalcProposer(c.validators, c.weights, c.view.Round, lastProposer, parent)
 }
 
 func (c *currentState) lock() {
@@ -153,12 +171,14 @@ func (c *currentState) numRounds(round uint64) int {
 	return len(obj)
 }
 
-func (c *currentState) AddRoundMessage(msg *proto.MessageReq) int {
+// AddRoundMessage adds the round change message and returns the
+// weight of the messages of its round
+func (c *currentState) AddRoundMessage(msg *proto.MessageReq) uint64 {
 	if msg.Type != proto.MessageReq_RoundChange {
 		return 0
 	}
 	c.addMessage(msg)
-	return len(c.roundMessages[msg.View.Round])
+	return c.weights.Sum(c.roundMessages[msg.View.Round])
 }
 
 func (c *currentState) addPrepared(msg *proto.MessageReq) {
@@ -203,6 +223,59 @@ func (c *currentState) numCommited() int {
 	return len(c.committed)
 }
 
+func (c *currentState) preparedWeight() uint64 {
+	return c.weights.Sum(c.prepared)
+}
+
+func (c *currentState) committedWeight() uint64 {
+	return c.weights.Sum(c.committed)
+}
+
+// Weights are the voting power of the validators, the
+// validators that are not included have a weight of one
+type Weights map[types.Address]uint64
+
+// Get returns the weight of the validator
+func (w Weights) Get(addr types.Address) uint64 {
+	if weight, ok := w[addr]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Sum returns the total weight of the senders of the messages
+func (w Weights) Sum(msgs map[types.Address]*proto.MessageReq) (weight uint64) {
+	for addr := range msgs {
+		weight += w.Get(addr)
+	}
+	return
+}
+
+// Copy returns a copy of the weights
+func (w Weights) Copy() Weights {
+	if w == nil {
+		return nil
+	}
+	ww := Weights{}
+	for addr, weight := range w {
+		ww[addr] = weight
+	}
+	return ww
+}
+
+// Equal checks whether the validators have the same weights
+func (w Weights) Equal(ww Weights) bool {
+	if len(w) != len(ww) {
+		return false
+	}
+	for addr, weight := range w {
+		if other, ok := ww[addr]; !ok || other != weight {
+			return false
+		}
+	}
+	return true
+}
+
 type ValidatorSet []types.Address
 
 func (v *ValidatorSet) CalcProposer(round uint64, lastProposer types.Address) types.Address {
@@ -264,3 +337,31 @@ func (v *ValidatorSet) Includes(addr types.Address) bool {
 func (v *ValidatorSet) MinFaultyNodes() int {
 	return int(math.Ceil(float64(len(*v))/3)) - 1
 }
+
+// TotalWeight returns the voting power of the validators
+func (v *ValidatorSet) TotalWeight(weights Weights) (total uint64) {
+	for _, addr := range *v {
+		total += weights.Get(addr)
+	}
+	return
+}
+
+// MaxFaultyWeight is the weight of the faulty validators that can be tolerated,
+// it is the number of faulty nodes if all the validators have the same weight
+func (v *ValidatorSet) MaxFaultyWeight(weights Weights) uint64 {
+	total := v.TotalWeight(weights)
+	if total == 0 {
+		return 0
+	}
+	return (total+2)/3 - 1
+}
+
+// QuorumWeight is the weight of the messages of a quorum and of the committed
+// seals. It is the minimum weight with more than two thirds of the voting power
+// with twoThirds, otherwise it is 2F+1 with F the max faulty weight
+func (v *ValidatorSet) QuorumWeight(weights Weights, twoThirds bool) uint64 {
+	if !twoThirds {
+		return 2*v.MaxFaultyWeight(weights) + 1
+	}
+	return 2*v.TotalWeight(weights)/3 + 1
+}
@@ -28,6 +28,50 @@ func TestState_FaultyNodes(t *testing.T) {
 	}
 }
 
+func TestState_FaultyWeight(t *testing.T) {
+	pool := newTesterAccountPool()
+	pool.add("A", "B", "C", "D")
+	vals := pool.ValidatorSet()
+
+	// the same weights tolerate the same faulty nodes
+	assert.Equal(t, uint64(vals.MinFaultyNodes()), vals.MaxFaultyWeight(nil))
+	assert.Equal(t, uint64(vals.MinFaultyNodes()), vals.MaxFaultyWeight(Weights{pool.get("A").Address(): 1}))
+
+	// A has half of the voting power
+	weights := Weights{pool.get("A").Address(): 3}
+	assert.Equal(t, uint64(6), vals.TotalWeight(weights))
+	assert.Equal(t, uint64(1), vals.MaxFaultyWeight(weights))
+	assert.Equal(t, uint64(3), vals.QuorumWeight(weights, false))
+	assert.Equal(t, uint64(5), vals.QuorumWeight(weights, true))
+
+	c := newState()
+	c.validators = vals
+	c.weights = weights
+
+	msg := func(acct string) *proto.MessageReq {
+		return &proto.MessageReq{
+			From: pool.get(acct).Address().String(),
+			Type: proto.MessageReq_Commit,
+			View: proto.ViewMsg(1, 0),
+		}
+	}
+	c.addCommited(msg("A"))
+	assert.Equal(t, 1, c.numCommited())
+	assert.Equal(t, uint64(3), c.committedWeight())
+	assert.True(t, c.committedWeight() >= c.QuorumWeight())
+
+	// A alone does not have more than two thirds of the voting power
+	c.twoThirds = true
+	assert.False(t, c.committedWeight() >= c.QuorumWeight())
+
+	c.addCommited(msg("B"))
+	assert.False(t, c.committedWeight() >= c.QuorumWeight())
+
+	c.addCommited(msg("C"))
+	assert.Equal(t, uint64(5), c.committedWeight())
+	assert.True(t, c.committedWeight() >= c.QuorumWeight())
+}
+
 func TestState_AddMessages(t *testing.T) {
 	pool := newTesterAccountPool()
 	pool.add("A", "B", "C", "D")
@@ -0,0 +1,87 @@
+package ibft
+
+import (
+	"sync"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+	gproto "github.com/golang/protobuf/proto"
+)
+
+// statusStream sends the last consensus status to the subscribers
+type statusStream struct {
+	lock   sync.Mutex
+	last   *proto.IbftConsensusStatus
+	subs   map[uint64]chan *proto.IbftConsensusStatus
+	nextID uint64
+}
+
+// subscribe returns a channel with the changes of the status, starting with
+// the current one. A slow subscriber only receives the latest status
+func (s *statusStream) subscribe() (chan *proto.IbftConsensusStatus, func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.subs == nil {
+		s.subs = map[uint64]chan *proto.IbftConsensusStatus{}
+	}
+	id := s.nextID
+	s.nextID++
+
+	ch := make(chan *proto.IbftConsensusStatus, 1)
+	if s.last != nil {
+		ch <- s.last
+	}
+	s.subs[id] = ch
+
+	cancel := func() {
+		s.lock.Lock()
+		delete(s.subs, id)
+		s.lock.Unlock()
+	}
+	return ch, cancel
+}
+
+func (s *statusStream) publish(status *proto.IbftConsensusStatus) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.last != nil && gproto.Equal(s.last, status) {
+		return
+	}
+	s.last = status
+
+	for _, ch := range s.subs {
+		// replace the status the subscriber did not read yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- status
+	}
+}
+
+// publishStatus publishes the state of the consensus, it has to be
+// called from the consensus loop
+func (i *Ibft) publishStatus() {
+	status := &proto.IbftConsensusStatus{
+		State:     i.getState().String(),
+		Sequence:  i.blockchain.Header().Number + 1,
+		Prepared:  uint64(len(i.state.prepared)),
+		Committed: uint64(len(i.state.committed)),
+	}
+	if view := i.state.view; view != nil && i.getState() != SyncState {
+		status.Sequence = view.Sequence
+		status.Round = view.Round
+	}
+	if i.state.proposer != types.ZeroAddress {
+		status.Proposer = i.state.proposer.String()
+	}
+	for _, msgs := range i.state.roundMessages {
+		status.RoundChanges += uint64(len(msgs))
+	}
+	if i.state.isLocked() && i.state.block != nil {
+		status.LockedBlock = i.state.block.Hash().String()
+	}
+	i.statusStream.publish(status)
+}
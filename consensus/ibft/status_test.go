@@ -0,0 +1,65 @@
+package ibft
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusStream(t *testing.T) {
+	var s statusStream
+
+	s.publish(&proto.IbftConsensusStatus{Sequence: 1})
+
+	// a new subscriber receives the current status
+	ch, cancel := s.subscribe()
+	assert.Equal(t, uint64(1), (<-ch).Sequence)
+
+	// the same status is not published twice
+	s.publish(&proto.IbftConsensusStatus{Sequence: 1})
+	assert.Len(t, ch, 0)
+
+	// a slow subscriber only receives the latest status
+	s.publish(&proto.IbftConsensusStatus{Sequence: 2})
+	s.publish(&proto.IbftConsensusStatus{Sequence: 3})
+	assert.Equal(t, uint64(3), (<-ch).Sequence)
+
+	cancel()
+	s.publish(&proto.IbftConsensusStatus{Sequence: 4})
+	assert.Len(t, ch, 0)
+}
+
+func TestStatusStream_Publish(t *testing.T) {
+	i := newMockIbft(t, []string{"A", "B", "C", "D"}, "A")
+	i.setState(ValidateState)
+	i.state.view = proto.ViewMsg(1, 2)
+	i.state.proposer = i.pool.get("B").Address()
+	i.state.block = i.DummyBlock()
+	i.state.lock()
+
+	i.addMessage(&proto.MessageReq{
+		From: "A",
+		Type: proto.MessageReq_Prepare,
+		View: proto.ViewMsg(1, 2),
+	})
+	i.addMessage(&proto.MessageReq{
+		From: "C",
+		Type: proto.MessageReq_RoundChange,
+		View: proto.ViewMsg(1, 3),
+	})
+
+	ch, cancel := i.statusStream.subscribe()
+	defer cancel()
+
+	i.publishStatus()
+
+	status := <-ch
+	assert.Equal(t, ValidateState.String(), status.State)
+	assert.Equal(t, uint64(1), status.Sequence)
+	assert.Equal(t, uint64(2), status.Round)
+	assert.Equal(t, i.pool.get("B").Address().String(), status.Proposer)
+	assert.Equal(t, uint64(1), status.Prepared)
+	assert.Equal(t, uint64(1), status.RoundChanges)
+	assert.Equal(t, i.state.block.Hash().String(), status.LockedBlock)
+}
@@ -0,0 +1,125 @@
+package ibft
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// defaultWatchdogRounds is the number of rounds of a sequence after which
+// the watchdog is triggered, about half an hour of round changes
+const defaultWatchdogRounds = 10
+
+const watchdogFileName = "watchdog"
+
+// watchdogDump is the state of the consensus when the watchdog is triggered
+type watchdogDump struct {
+	Time        time.Time       `json:"time"`
+	Sequence    uint64          `json:"sequence"`
+	Round       uint64          `json:"round"`
+	Head        uint64          `json:"head"`
+	Proposer    types.Address   `json:"proposer"`
+	Locked      bool            `json:"locked"`
+	LockedBlock string          `json:"locked_block,omitempty"`
+	Validators  []types.Address `json:"validators"`
+
+	// Snapshot is the number of the snapshot of the validators
+	Snapshot uint64 `json:"snapshot"`
+
+	// RoundChanges are the senders of the round changes of each round
+	RoundChanges map[uint64][]types.Address `json:"round_changes"`
+}
+
+// watchdogTriggered returns whether the round is beyond the watchdog rounds.
+// After a recovery it only triggers again if the rounds keep escalating
+func (i *Ibft) watchdogTriggered(round uint64) bool {
+	if round <= i.watchdogRounds {
+		return false
+	}
+	last := i.lastWatchdog
+	if last != nil && last.Sequence == i.state.view.Sequence && round <= last.Round+i.watchdogRounds {
+		return false
+	}
+	return true
+}
+
+// runWatchdog dumps the state of the stuck round, rebuilds the snapshots of
+// the current epoch from the local headers and moves to sync state to get the
+// next blocks from the peers. The locked block is kept, the round is dropped
+func (i *Ibft) runWatchdog(round uint64) {
+	i.lastWatchdog = proto.ViewMsg(i.state.view.Sequence, round)
+	i.metrics.watchdog.Inc()
+
+	dump := i.watchdogDump(round)
+	i.logger.Warn("round change watchdog triggered", "sequence", dump.Sequence, "round", round, "head", dump.Head, "locked", dump.Locked, "snapshot", dump.Snapshot)
+	if i.config.Path != "" {
+		if err := writeDataStore(filepath.Join(i.config.Path, watchdogFileName), dump); err != nil {
+			i.logger.Error("failed to write the watchdog dump", "err", err)
+		}
+	}
+
+	if err := i.resyncSnapshot(); err != nil {
+		i.logger.Error("failed to rebuild the snapshot from the local headers", "err", err)
+	}
+
+	i.dropRound()
+}
+
+func (i *Ibft) watchdogDump(round uint64) *watchdogDump {
+	dump := &watchdogDump{
+		Time:         time.Now().UTC(),
+		Sequence:     i.state.view.Sequence,
+		Round:        round,
+		Head:         i.blockchain.Header().Number,
+		Proposer:     i.state.proposer,
+		Locked:       i.state.locked,
+		Validators:   i.state.validators,
+		RoundChanges: map[uint64][]types.Address{},
+	}
+	if i.state.locked && i.state.block != nil {
+		dump.LockedBlock = i.state.block.Hash().String()
+	}
+	if snap, err := i.getSnapshot(dump.Head); err == nil && snap != nil {
+		dump.Snapshot = snap.Number
+	}
+	for r, msgs := range i.state.roundMessages {
+		senders := []types.Address{}
+		for addr := range msgs {
+			senders = append(senders, addr)
+		}
+		sort.Slice(senders, func(i, j int) bool {
+			return senders[i].String() < senders[j].String()
+		})
+		dump.RoundChanges[r] = senders
+	}
+	return dump
+}
+
+// resyncSnapshot drops the snapshots after the epoch boundary of the head and
+// processes the local headers again. The headers are not fetched from the
+// peers, the snapshot only includes the blocks written locally
+func (i *Ibft) resyncSnapshot() error {
+	head := i.blockchain.Header()
+
+	base := head.Number - head.Number%i.epochSize
+	if fork := i.config.ForkBlock; fork != 0 && base < fork-1 {
+		base = fork - 1
+	}
+	i.store.truncate(base)
+	i.store.updateLastBlock(base)
+
+	for num := base + 1; num <= head.Number; num++ {
+		header, ok := i.blockchain.GetHeaderByNumber(num)
+		if !ok {
+			return fmt.Errorf("header %d not found", num)
+		}
+		if err := i.processHeaders([]*types.Header{header}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package ibft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog_StuckRound(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "ibft-watchdog")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	m.config.Path = tmpDir
+	m.epochSize = defaultEpochSize
+	m.Close()
+
+	m.state.block = m.DummyBlock()
+	m.state.lock()
+
+	// a snapshot that does not belong to the chain
+	m.pool.add("X")
+	m.store.add(&Snapshot{Number: 5, Set: ValidatorSet{m.pool.get("X").Address()}})
+
+	// B is far beyond the watchdog rounds
+	m.addMessage(&proto.MessageReq{
+		From: "B",
+		Type: proto.MessageReq_RoundChange,
+		View: proto.ViewMsg(1, defaultWatchdogRounds+5),
+	})
+
+	m.setState(RoundChangeState)
+	m.runCycle()
+
+	// the node drops the round and syncs again with the block locked
+	m.expect(expectResult{
+		sequence: 1,
+		state:    SyncState,
+		locked:   true,
+	})
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.metrics.watchdog))
+	assert.Equal(t, uint64(defaultWatchdogRounds+5), m.lastWatchdog.Round)
+
+	// the snapshots after the head are removed
+	snap, err := m.getSnapshot(5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), snap.Number)
+
+	// the state of the round is dumped
+	var dump *watchdogDump
+	assert.NoError(t, readDataStore(filepath.Join(tmpDir, watchdogFileName), &dump))
+	assert.True(t, dump.Locked)
+	assert.Equal(t, m.state.validators, ValidatorSet(dump.Validators))
+	assert.Len(t, dump.RoundChanges[defaultWatchdogRounds+5], 1)
+}
+
+func TestWatchdog_Escalating(t *testing.T) {
+	m := newMockIbft(t, []string{"A", "B", "C"}, "A")
+	m.watchdogRounds = 4
+
+	assert.False(t, m.watchdogTriggered(4))
+	assert.True(t, m.watchdogTriggered(5))
+
+	// after a recovery the rounds have to go beyond the watchdog rounds again
+	m.lastWatchdog = proto.ViewMsg(1, 5)
+	assert.False(t, m.watchdogTriggered(9))
+	assert.True(t, m.watchdogTriggered(10))
+
+	// a new sequence starts over
+	m.state.view = proto.ViewMsg(2, 0)
+	assert.True(t, m.watchdogTriggered(5))
+}
@@ -0,0 +1,260 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+)
+
+// Engine is a consensus engine of the chain since a block
+type Engine struct {
+	Name  string
+	Block uint64
+
+	// Consensus is the engine, if it is not set it is built with the
+	// Factory once the chain reaches the range of blocks of the engine
+	Consensus Consensus
+	Factory   func() (Consensus, error)
+
+	// HeaderHash is the hash function of the headers of the engine
+	HeaderHash func(h *types.Header) types.Hash
+}
+
+type switchBlockchain interface {
+	Header() *types.Header
+	SubscribeEvents() blockchain.Subscription
+}
+
+// Switch runs the consensus engine of each range of blocks. Only the engine
+// of the next block is started, it is replaced by the next engine once the
+// head of the chain is the last block before its range. All the engines
+// verify the blocks of their range
+type Switch struct {
+	logger     hclog.Logger
+	blockchain switchBlockchain
+	engines    []*Engine
+
+	lock    sync.Mutex
+	started bool
+	active  int
+	sub     blockchain.Subscription
+}
+
+// NewSwitch returns the switch of the engines, sorted by block with the first
+// one at the genesis. It replaces the header hash function with the one of the
+// engine of each block
+func NewSwitch(logger hclog.Logger, blockchain switchBlockchain, engines []*Engine) (*Switch, error) {
+	if len(engines) == 0 || engines[0].Block != 0 {
+		return nil, fmt.Errorf("the first consensus engine has to start at the genesis")
+	}
+	for i := 1; i < len(engines); i++ {
+		if engines[i].Block <= engines[i-1].Block {
+			return nil, fmt.Errorf("consensus engines are not sorted by block")
+		}
+	}
+	s := &Switch{
+		logger:     logger.Named("switch"),
+		blockchain: blockchain,
+		engines:    engines,
+		active:     -1,
+	}
+	types.HeaderHash = func(h *types.Header) types.Hash {
+		return s.engineAt(h.Number).HeaderHash(h)
+	}
+	return s, nil
+}
+
+func (s *Switch) engineIndex(number uint64) int {
+	indx := 0
+	for i, engine := range s.engines {
+		if engine.Block <= number {
+			indx = i
+		}
+	}
+	return indx
+}
+
+func (s *Switch) engineAt(number uint64) *Engine {
+	return s.engines[s.engineIndex(number)]
+}
+
+// build returns the consensus of the engine and builds it the first time,
+// the lock has to be held
+func (s *Switch) build(engine *Engine) (Consensus, error) {
+	if engine.Consensus != nil {
+		return engine.Consensus, nil
+	}
+	if engine.Factory == nil {
+		return nil, fmt.Errorf("consensus engine %s cannot be built", engine.Name)
+	}
+	s.logger.Info("build consensus engine", "engine", engine.Name, "block", engine.Block)
+
+	consensus, err := engine.Factory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build consensus engine %s: %v", engine.Name, err)
+	}
+	engine.Consensus = consensus
+	return consensus, nil
+}
+
+// consensusAt returns the consensus of the engine of the block
+func (s *Switch) consensusAt(number uint64) (Consensus, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.build(s.engineAt(number))
+}
+
+// activate closes the active engine and starts the engine of the block if
+// it is a later one. The engines never go back to a previous one
+func (s *Switch) activate(number uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	indx := s.engineIndex(number)
+	if !s.started || indx <= s.active {
+		return nil
+	}
+	if s.active >= 0 {
+		prev := s.engines[s.active]
+		s.logger.Info("stop consensus engine", "engine", prev.Name, "block", number)
+		if err := prev.Consensus.Close(); err != nil {
+			s.logger.Error("failed to close consensus engine", "engine", prev.Name, "err", err)
+		}
+	}
+
+	engine := s.engines[indx]
+	consensus, err := s.build(engine)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("start consensus engine", "engine", engine.Name, "block", number)
+
+	s.active = indx
+	return consensus.Start()
+}
+
+// ActiveEngine returns the engine started to seal the next block, it is nil
+// if the switch is not started
+func (s *Switch) ActiveEngine() *Engine {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.active < 0 {
+		return nil
+	}
+	return s.engines[s.active]
+}
+
+// Reload implements the Reloader interface, the active engine applies the
+// options of the config. The config has to be the one of the active engine
+func (s *Switch) Reload(config *Config) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.active < 0 {
+		return fmt.Errorf("there is no active consensus engine")
+	}
+	engine := s.engines[s.active]
+	if config.ForkBlock != engine.Block {
+		return fmt.Errorf("the config is not the one of the active consensus engine %s", engine.Name)
+	}
+	reloader, ok := engine.Consensus.(Reloader)
+	if !ok {
+		return fmt.Errorf("consensus engine %s cannot reload its options", engine.Name)
+	}
+	return reloader.Reload(config)
+}
+
+// VerifyHeader implements the Consensus interface, the engine of the header
+// verifies it even if it is not the active one. The engines only switch
+// once the head of the chain is written
+func (s *Switch) VerifyHeader(parent, header *types.Header) error {
+	consensus, err := s.consensusAt(header.Number)
+	if err != nil {
+		return err
+	}
+	return consensus.VerifyHeader(parent, header)
+}
+
+// VerifyUncle implements the blockchain.UncleVerifier interface, the uncles
+// are only allowed by the engine of the block if it verifies them
+func (s *Switch) VerifyUncle(parent, uncle *types.Header) error {
+	consensus, err := s.consensusAt(uncle.Number)
+	if err != nil {
+		return err
+	}
+	verifier, ok := consensus.(blockchain.UncleVerifier)
+	if !ok {
+		return fmt.Errorf("the consensus engine of block %d does not allow uncles", uncle.Number)
+	}
+	return verifier.VerifyUncle(parent, uncle)
+}
+
+// Prepare implements the Consensus interface
+func (s *Switch) Prepare(header *types.Header) error {
+	consensus, err := s.consensusAt(header.Number)
+	if err != nil {
+		return err
+	}
+	return consensus.Prepare(header)
+}
+
+// Seal implements the Consensus interface
+func (s *Switch) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	consensus, err := s.consensusAt(block.Number())
+	if err != nil {
+		return nil, err
+	}
+	return consensus.Seal(block, ctx)
+}
+
+// Start implements the Consensus interface
+func (s *Switch) Start() error {
+	s.lock.Lock()
+	s.started = true
+	s.lock.Unlock()
+
+	if err := s.activate(s.blockchain.Header().Number + 1); err != nil {
+		return err
+	}
+
+	s.sub = s.blockchain.SubscribeEvents()
+	go s.run()
+	return nil
+}
+
+func (s *Switch) run() {
+	for {
+		evnt := s.sub.GetEvent()
+		if evnt == nil {
+			return
+		}
+		if len(evnt.NewChain) == 0 {
+			continue
+		}
+		// start the engine that seals the next block
+		if err := s.activate(evnt.Header().Number + 1); err != nil {
+			s.logger.Error("failed to start consensus engine", "err", err)
+		}
+	}
+}
+
+// Close implements the Consensus interface
+func (s *Switch) Close() error {
+	if s.sub != nil {
+		s.sub.Close()
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.active < 0 {
+		return nil
+	}
+	return s.engines[s.active].Consensus.Close()
+}
@@ -0,0 +1,231 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEngine struct {
+	running  bool
+	verified []uint64
+}
+
+func (m *mockEngine) VerifyHeader(parent, header *types.Header) error {
+	m.verified = append(m.verified, header.Number)
+	return nil
+}
+
+func (m *mockEngine) Prepare(header *types.Header) error {
+	return nil
+}
+
+func (m *mockEngine) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	return block, nil
+}
+
+func (m *mockEngine) Start() error {
+	m.running = true
+	return nil
+}
+
+func (m *mockEngine) Close() error {
+	m.running = false
+	return nil
+}
+
+type mockReloadEngine struct {
+	mockEngine
+	config *Config
+}
+
+func (m *mockReloadEngine) Reload(config *Config) error {
+	m.config = config
+	return nil
+}
+
+type mockSwitchBlockchain struct {
+	header *types.Header
+	sub    *blockchain.MockSubscription
+}
+
+func (m *mockSwitchBlockchain) Header() *types.Header {
+	return m.header
+}
+
+func (m *mockSwitchBlockchain) SubscribeEvents() blockchain.Subscription {
+	return m.sub
+}
+
+func TestSwitch_Engines(t *testing.T) {
+	defer func(hash func(h *types.Header) types.Hash) {
+		types.HeaderHash = hash
+	}(types.HeaderHash)
+
+	a, b := &mockEngine{}, &mockEngine{}
+	built := 0
+	engines := []*Engine{
+		{
+			Name:      "a",
+			Consensus: a,
+			HeaderHash: func(h *types.Header) types.Hash {
+				return types.StringToHash("a")
+			},
+		},
+		{
+			Name:  "b",
+			Block: 10,
+			Factory: func() (Consensus, error) {
+				built++
+				return b, nil
+			},
+			HeaderHash: func(h *types.Header) types.Hash {
+				return types.StringToHash("b")
+			},
+		},
+	}
+	chain := &mockSwitchBlockchain{
+		header: &types.Header{Number: 5},
+		sub:    blockchain.NewMockSubscription(),
+	}
+
+	s, err := NewSwitch(hclog.NewNullLogger(), chain, engines)
+	assert.NoError(t, err)
+
+	// each range of blocks has the hash of its engine
+	assert.Equal(t, types.StringToHash("a"), types.HeaderHash(&types.Header{Number: 9}))
+	assert.Equal(t, types.StringToHash("b"), types.HeaderHash(&types.Header{Number: 10}))
+
+	assert.NoError(t, s.Start())
+	assert.True(t, a.running)
+
+	// the historical blocks are verified by their engine
+	assert.NoError(t, s.VerifyHeader(&types.Header{Number: 3}, &types.Header{Number: 4}))
+	assert.Equal(t, []uint64{4}, a.verified)
+
+	// the next engine is only built for the blocks of its range
+	assert.Equal(t, 0, built)
+
+	// the first block of the fork is verified by the next engine but
+	// the engines only switch once the head is written
+	assert.NoError(t, s.VerifyHeader(&types.Header{Number: 9}, &types.Header{Number: 10}))
+	assert.Equal(t, []uint64{10}, b.verified)
+	assert.Equal(t, 1, built)
+	assert.True(t, a.running)
+	assert.False(t, b.running)
+
+	evnt := &blockchain.Event{}
+	evnt.AddNewHeader(&types.Header{Number: 10})
+	chain.sub.Push(evnt)
+	chain.sub.Push(&blockchain.Event{})
+
+	s.lock.Lock()
+	assert.False(t, a.running)
+	assert.True(t, b.running)
+	s.lock.Unlock()
+
+	// the previous engine does not start again
+	assert.NoError(t, s.VerifyHeader(&types.Header{Number: 4}, &types.Header{Number: 5}))
+	assert.Equal(t, []uint64{4, 5}, a.verified)
+	assert.False(t, a.running)
+	assert.Equal(t, 1, built)
+
+	assert.NoError(t, s.Close())
+	assert.False(t, b.running)
+}
+
+func TestSwitch_StartAtFork(t *testing.T) {
+	defer func(hash func(h *types.Header) types.Hash) {
+		types.HeaderHash = hash
+	}(types.HeaderHash)
+
+	a, b := &mockEngine{}, &mockEngine{}
+	engines := []*Engine{
+		{Name: "a", Consensus: a},
+		{Name: "b", Block: 10, Consensus: b},
+	}
+	chain := &mockSwitchBlockchain{
+		header: &types.Header{Number: 8},
+		sub:    blockchain.NewMockSubscription(),
+	}
+
+	s, err := NewSwitch(hclog.NewNullLogger(), chain, engines)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Start())
+	assert.True(t, a.running)
+
+	// the engine of the next block starts once the head is written
+	evnt := &blockchain.Event{}
+	evnt.AddNewHeader(&types.Header{Number: 9})
+	chain.sub.Push(evnt)
+	chain.sub.Push(&blockchain.Event{})
+
+	s.lock.Lock()
+	assert.Equal(t, 1, s.active)
+	s.lock.Unlock()
+	assert.False(t, a.running)
+	assert.True(t, b.running)
+}
+
+func TestSwitch_Reload(t *testing.T) {
+	defer func(hash func(h *types.Header) types.Hash) {
+		types.HeaderHash = hash
+	}(types.HeaderHash)
+
+	a, b := &mockEngine{}, &mockReloadEngine{}
+	engines := []*Engine{
+		{Name: "a", Consensus: a},
+		{Name: "b", Block: 10, Consensus: b},
+	}
+	chain := &mockSwitchBlockchain{
+		header: &types.Header{Number: 5},
+		sub:    blockchain.NewMockSubscription(),
+	}
+
+	s, err := NewSwitch(hclog.NewNullLogger(), chain, engines)
+	assert.NoError(t, err)
+
+	// there is no active engine before the start
+	assert.Nil(t, s.ActiveEngine())
+	assert.Error(t, s.Reload(&Config{}))
+
+	assert.NoError(t, s.Start())
+	assert.Equal(t, "a", s.ActiveEngine().Name)
+
+	// the active engine cannot reload its options
+	assert.Error(t, s.Reload(&Config{}))
+
+	evnt := &blockchain.Event{}
+	evnt.AddNewHeader(&types.Header{Number: 9})
+	chain.sub.Push(evnt)
+	chain.sub.Push(&blockchain.Event{})
+	assert.Equal(t, "b", s.ActiveEngine().Name)
+
+	// the config has to be the one of the active engine
+	assert.Error(t, s.Reload(&Config{}))
+	assert.Nil(t, b.config)
+
+	config := &Config{ForkBlock: 10}
+	assert.NoError(t, s.Reload(config))
+	assert.Equal(t, config, b.config)
+}
+
+func TestSwitch_InvalidEngines(t *testing.T) {
+	chain := &mockSwitchBlockchain{}
+
+	_, err := NewSwitch(hclog.NewNullLogger(), chain, []*Engine{
+		{Name: "a", Block: 1},
+	})
+	assert.Error(t, err)
+
+	_, err = NewSwitch(hclog.NewNullLogger(), chain, []*Engine{
+		{Name: "a"},
+		{Name: "b", Block: 10},
+		{Name: "c", Block: 10},
+	})
+	assert.Error(t, err)
+}
@@ -1,10 +1,90 @@
 package consensus
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/0xPolygon/minimal/types"
 	"github.com/0xPolygon/minimal/types/buildroot"
 )
 
+// DefaultMaxClockDrift is how far in the future the timestamp of a header can be
+// if the engine does not set the maxClockDrift option. Clique does not allow any
+// drift by default
+const DefaultMaxClockDrift = 15 * time.Second
+
+// ErrFutureTimestamp is returned for the headers with a timestamp further
+// in the future than the allowed clock drift
+var ErrFutureTimestamp = errors.New("timestamp is beyond the allowed clock drift")
+
+// VerifyTimestamp checks that the timestamp of the header is at most
+// maxClockDrift ahead of the local time
+func VerifyTimestamp(header *types.Header, maxClockDrift time.Duration) error {
+	if header.Timestamp > uint64(time.Now().Add(maxClockDrift).Unix()) {
+		return ErrFutureTimestamp
+	}
+	return nil
+}
+
+// GetUint reads a number of the config of an engine, the config decoded from the
+// genesis file has the numbers as float64. The number is def if the option is not
+// set and zero is only valid if allowZero is set
+func GetUint(config map[string]interface{}, name string, def uint64, allowZero bool) (uint64, error) {
+	raw, ok := config[name]
+	if !ok {
+		return def, nil
+	}
+	var num uint64
+	switch v := raw.(type) {
+	case uint64:
+		num = v
+	case int:
+		if v < 0 {
+			return 0, fmt.Errorf("%s is not a positive integer", name)
+		}
+		num = uint64(v)
+	case float64:
+		if v < 0 || v != float64(uint64(v)) {
+			return 0, fmt.Errorf("%s is not a positive integer", name)
+		}
+		num = uint64(v)
+	default:
+		return 0, fmt.Errorf("%s is not a positive integer", name)
+	}
+	if num == 0 && !allowZero {
+		return 0, fmt.Errorf("%s has to be positive", name)
+	}
+	return num, nil
+}
+
+// gasLimitBoundDivisor is the bound of the change of the gas limit between
+// a block and its parent
+const gasLimitBoundDivisor = 1024
+
+// CalcGasLimit returns the gas limit of the child of a block with the parent gas
+// limit, it moves toward the target by less than 1/1024 of the parent gas limit.
+// The parent gas limit is kept if the target is zero
+func CalcGasLimit(parentGasLimit, target uint64) uint64 {
+	if target == 0 {
+		return parentGasLimit
+	}
+	delta := parentGasLimit / gasLimitBoundDivisor
+	if delta > 0 {
+		delta--
+	}
+	if parentGasLimit < target {
+		if target-parentGasLimit < delta {
+			return target
+		}
+		return parentGasLimit + delta
+	}
+	if parentGasLimit-target < delta {
+		return target
+	}
+	return parentGasLimit - delta
+}
+
 func BuildBlock(header *types.Header, txs []*types.Transaction, receipts []*types.Receipt) *types.Block {
 	if len(txs) == 0 {
 		header.TxRoot = types.EmptyRootHash
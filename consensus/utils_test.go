@@ -0,0 +1,81 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalcGasLimit(t *testing.T) {
+	cases := []struct {
+		parent uint64
+		target uint64
+		expect uint64
+	}{
+		{
+			// no target
+			parent: 1024000,
+			expect: 1024000,
+		},
+		{
+			parent: 1024000,
+			target: 2000000,
+			expect: 1024999,
+		},
+		{
+			parent: 1024000,
+			target: 1000,
+			expect: 1023001,
+		},
+		{
+			// the target is within the bound
+			parent: 1024000,
+			target: 1024500,
+			expect: 1024500,
+		},
+		{
+			parent: 1024000,
+			target: 1024000,
+			expect: 1024000,
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, CalcGasLimit(c.parent, c.target))
+	}
+}
+
+func TestGetUint(t *testing.T) {
+	config := map[string]interface{}{
+		"float":    float64(10),
+		"int":      5,
+		"zero":     float64(0),
+		"negative": float64(-1),
+		"fraction": 1.5,
+		"string":   "10",
+	}
+
+	num, err := GetUint(config, "float", 1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), num)
+
+	num, err = GetUint(config, "int", 1, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), num)
+
+	// the default is used if the option is not set
+	num, err = GetUint(config, "missing", 7, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), num)
+
+	// zero is only valid if it is allowed
+	_, err = GetUint(config, "zero", 1, false)
+	assert.Error(t, err)
+	num, err = GetUint(config, "zero", 1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), num)
+
+	for _, name := range []string{"negative", "fraction", "string"} {
+		_, err = GetUint(config, name, 1, true)
+		assert.Error(t, err, name)
+	}
+}
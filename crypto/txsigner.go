@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"fmt"
+	"math/big"
 	"math/bits"
 
 	"crypto/ecdsa"
@@ -68,11 +69,26 @@ func calcTxHash(tx *types.Transaction, chainID uint64) types.Hash {
 	return types.BytesToHash(hash)
 }
 
+// calcTypedTxHash returns the signing hash of a typed txn, the hash of
+// the type and the payload without the signature values
+func calcTypedTxHash(tx *types.Transaction) types.Hash {
+	a := signerPool.Get()
+
+	v := tx.MarshalPayloadWith(a, false)
+	hash := keccak.Keccak256(nil, v.MarshalTo([]byte{byte(tx.Type)}))
+	signerPool.Put(a)
+
+	return types.BytesToHash(hash)
+}
+
 func (f *FrontierSigner) Hash(tx *types.Transaction) types.Hash {
 	return calcTxHash(tx, 0)
 }
 
 func (f *FrontierSigner) Sender(tx *types.Transaction) (types.Address, error) {
+	if tx.Type != types.LegacyTx {
+		return types.Address{}, fmt.Errorf("typed txns require an eip155 signer")
+	}
 	sig, err := encodeSignature(tx.R, tx.S, tx.V-27)
 	if err != nil {
 		return types.Address{}, err
@@ -113,10 +129,16 @@ type EIP155Signer struct {
 }
 
 func (e *EIP155Signer) Hash(tx *types.Transaction) types.Hash {
+	if tx.Type != types.LegacyTx {
+		return calcTypedTxHash(tx)
+	}
 	return calcTxHash(tx, e.chainID)
 }
 
 func (e *EIP155Signer) Sender(tx *types.Transaction) (types.Address, error) {
+	if tx.Type != types.LegacyTx {
+		return e.typedSender(tx)
+	}
 	protected := true
 
 	if vv := uint(tx.V); bits.Len(vv) <= 8 {
@@ -130,6 +152,10 @@ func (e *EIP155Signer) Sender(tx *types.Transaction) (types.Address, error) {
 	v := tx.V - byte(e.chainID*2)
 	v -= 8
 	v -= 27
+	if v > 1 {
+		// the txn is signed for another chain
+		return types.Address{}, fmt.Errorf("invalid chain id for signer")
+	}
 
 	sig, err := encodeSignature(tx.R, tx.S, v)
 	if err != nil {
@@ -143,8 +169,30 @@ func (e *EIP155Signer) Sender(tx *types.Transaction) (types.Address, error) {
 	return types.BytesToAddress(buf), nil
 }
 
+// typedSender recovers the sender of a typed txn, the V value is the parity
+// of the signature and the chain id is part of the txn
+func (e *EIP155Signer) typedSender(tx *types.Transaction) (types.Address, error) {
+	if tx.ChainID == nil || !tx.ChainID.IsUint64() || tx.ChainID.Uint64() != e.chainID {
+		return types.Address{}, fmt.Errorf("invalid chain id for signer")
+	}
+
+	sig, err := encodeSignature(tx.R, tx.S, tx.V)
+	if err != nil {
+		return types.Address{}, err
+	}
+	pub, err := Ecrecover(e.Hash(tx).Bytes(), sig)
+	if err != nil {
+		return types.Address{}, err
+	}
+	buf := Keccak256(pub[1:])[12:]
+	return types.BytesToAddress(buf), nil
+}
+
 func (e *EIP155Signer) SignTx(tx *types.Transaction, priv *ecdsa.PrivateKey) (*types.Transaction, error) {
 	tx = tx.Copy()
+	if tx.Type != types.LegacyTx && tx.ChainID == nil {
+		tx.ChainID = new(big.Int).SetUint64(e.chainID)
+	}
 
 	h := e.Hash(tx)
 
@@ -155,7 +203,11 @@ func (e *EIP155Signer) SignTx(tx *types.Transaction, priv *ecdsa.PrivateKey) (*t
 
 	tx.R = sig[:32]
 	tx.S = sig[32:64]
-	tx.V = byte(sig[64]+35) + (byte(e.chainID) * 2)
+	if tx.Type != types.LegacyTx {
+		tx.V = sig[64]
+	} else {
+		tx.V = byte(sig[64]+35) + (byte(e.chainID) * 2)
+	}
 
 	return tx, nil
 }
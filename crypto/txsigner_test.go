@@ -52,3 +52,39 @@ func TestEIP1155Signer(t *testing.T) {
 	_, err = signer2.Sender(txn)
 	assert.Error(t, err)
 }
+
+func TestEIP155Signer_TypedTxn(t *testing.T) {
+	signer1 := NewEIP155Signer(1)
+
+	addr0 := types.Address{0x1}
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+
+	for _, typ := range []types.TxType{types.AccessListTx, types.DynamicFeeTx} {
+		txn := &types.Transaction{
+			Type:      typ,
+			To:        &addr0,
+			Value:     big.NewInt(10),
+			GasPrice:  big.NewInt(2),
+			GasTipCap: big.NewInt(1),
+			AccessList: types.AccessList{
+				{Address: addr0, StorageKeys: []types.Hash{{0x1}}},
+			},
+		}
+		txn, err = signer1.SignTx(txn, key)
+		assert.NoError(t, err)
+		assert.Equal(t, big.NewInt(1), txn.ChainID)
+
+		// the sender is recovered after the txn is encoded
+		txn2 := new(types.Transaction)
+		assert.NoError(t, txn2.UnmarshalRLP(txn.MarshalRLP()))
+
+		from, err := signer1.Sender(txn2)
+		assert.NoError(t, err)
+		assert.Equal(t, from, PubKeyToAddress(&key.PublicKey))
+
+		// the chain id of the txn has to match the one of the signer
+		_, err = NewEIP155Signer(2).Sender(txn2)
+		assert.Error(t, err)
+	}
+}
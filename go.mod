@@ -4,6 +4,7 @@ go 1.14
 
 require (
 	github.com/btcsuite/btcd v0.21.0-beta
+	github.com/cockroachdb/pebble v0.0.0-20210526183633-dd2a545f5d75
 	github.com/ethereum/go-ethereum v1.9.15
 	github.com/golang/protobuf v1.4.3
 	github.com/google/gopacket v1.1.18 // indirect
@@ -25,6 +26,7 @@ require (
 	github.com/mitchellh/cli v1.0.0
 	github.com/multiformats/go-multiaddr v0.3.1
 	github.com/multiformats/go-multiaddr-net v0.2.0
+	github.com/prometheus/client_golang v1.10.0
 	github.com/ryanuber/columnize v2.1.2+incompatible
 	github.com/stretchr/testify v1.7.0
 	github.com/syndtr/goleveldb v1.0.1-0.20190923125748-758128399b1d
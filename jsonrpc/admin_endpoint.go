@@ -0,0 +1,65 @@
+package jsonrpc
+
+// Admin is the admin jsonrpc endpoint, it is only
+// registered if it is enabled in the config
+type Admin struct {
+	d *Dispatcher
+}
+
+type peerRes struct {
+	ID        string   `json:"id"`
+	Addrs     []string `json:"addrs"`
+	Protocols []string `json:"protocols"`
+}
+
+type nodeInfoRes struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Addrs   []string `json:"listenAddrs"`
+	P2PAddr string   `json:"p2pAddr"`
+}
+
+// Peers returns the peers connected to the node
+func (a *Admin) Peers() (interface{}, error) {
+	peers, err := a.d.store.Peers()
+	if err != nil {
+		return nil, err
+	}
+	res := []*peerRes{}
+	for _, p := range peers {
+		res = append(res, &peerRes{
+			ID:        p.ID,
+			Addrs:     p.Addrs,
+			Protocols: p.Protocols,
+		})
+	}
+	return res, nil
+}
+
+// NodeInfo returns the identity of the node in the network
+func (a *Admin) NodeInfo() (interface{}, error) {
+	info := a.d.store.NodeInfo()
+	return &nodeInfoRes{
+		ID:      info.ID,
+		Name:    clientVersion(),
+		Addrs:   info.Addrs,
+		P2PAddr: info.P2PAddr,
+	}, nil
+}
+
+// AddPeer connects to the peer of the p2p address, the
+// connection is established in the background
+func (a *Admin) AddPeer(addr string) (interface{}, error) {
+	if err := a.d.store.AddPeer(addr); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// RemovePeer disconnects the peer of the id
+func (a *Admin) RemovePeer(id string) (interface{}, error) {
+	if err := a.d.store.RemovePeer(id); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
@@ -0,0 +1,74 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStoreAdmin struct {
+	nullBlockchainInterface
+
+	peers []*PeerInfo
+}
+
+func (m *mockStoreAdmin) Peers() ([]*PeerInfo, error) {
+	return m.peers, nil
+}
+
+func (m *mockStoreAdmin) NodeInfo() *NodeInfo {
+	return &NodeInfo{ID: "a", Addrs: []string{"/ip4/127.0.0.1/tcp/1478"}, P2PAddr: "/ip4/127.0.0.1/tcp/1478/p2p/a"}
+}
+
+func (m *mockStoreAdmin) AddPeer(addr string) error {
+	m.peers = append(m.peers, &PeerInfo{ID: addr})
+	return nil
+}
+
+func (m *mockStoreAdmin) RemovePeer(id string) error {
+	for i, p := range m.peers {
+		if p.ID == id {
+			m.peers = append(m.peers[:i], m.peers[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func TestAdminEndpoint(t *testing.T) {
+	store := &mockStoreAdmin{}
+	s := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	// the namespace is not enabled by default
+	_, err := s.Handle([]byte(`{"method": "admin_peers", "params": []}`), "")
+	assert.Error(t, err)
+
+	s.registerAdmin()
+
+	handle := func(req string, res interface{}) {
+		resp, err := s.Handle([]byte(req), "")
+		assert.NoError(t, err)
+		assert.NoError(t, expectJSONResult(resp, res))
+	}
+
+	var ok bool
+	handle(`{"method": "admin_addPeer", "params": ["b"]}`, &ok)
+	assert.True(t, ok)
+
+	var peers []*peerRes
+	handle(`{"method": "admin_peers", "params": []}`, &peers)
+	assert.Len(t, peers, 1)
+	assert.Equal(t, "b", peers[0].ID)
+
+	handle(`{"method": "admin_removePeer", "params": ["b"]}`, &ok)
+	assert.True(t, ok)
+
+	handle(`{"method": "admin_peers", "params": []}`, &peers)
+	assert.Len(t, peers, 0)
+
+	var info nodeInfoRes
+	handle(`{"method": "admin_nodeInfo", "params": []}`, &info)
+	assert.Equal(t, "a", info.ID)
+	assert.Equal(t, clientVersion(), info.Name)
+	assert.Equal(t, "/ip4/127.0.0.1/tcp/1478/p2p/a", info.P2PAddr)
+}
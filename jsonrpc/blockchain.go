@@ -5,6 +5,7 @@ import (
 
 	"github.com/0xPolygon/minimal/blockchain"
 	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/state/runtime"
 	"github.com/0xPolygon/minimal/types"
 )
 
@@ -13,9 +14,45 @@ import (
 type stateHelperInterface interface {
 	GetAccount(root types.Hash, addr types.Address) (*state.Account, error)
 	GetStorage(root types.Hash, addr types.Address, slot types.Hash) ([]byte, error)
+
+	// GetProof returns the merkle proofs of the account and of its slots
+	GetProof(root types.Hash, addr types.Address, slots []types.Hash) (*state.AccountProof, error)
 	GetCode(hash types.Hash) ([]byte, error)
 }
 
+// PeerInfo is a peer connected to the node
+type PeerInfo struct {
+	ID        string
+	Addrs     []string
+	Protocols []string
+}
+
+// NodeInfo is the identity of the node in the network
+type NodeInfo struct {
+	ID      string
+	Addrs   []string
+	P2PAddr string
+}
+
+// networkInterface is the network of the node for
+// the net and the admin endpoints
+type networkInterface interface {
+	// PeerCount returns the number of connected peers
+	PeerCount() int
+
+	// Peers returns the connected peers
+	Peers() ([]*PeerInfo, error)
+
+	// NodeInfo returns the identity of the node
+	NodeInfo() *NodeInfo
+
+	// AddPeer dials the peer of the p2p address
+	AddPeer(addr string) error
+
+	// RemovePeer disconnects the peer of the id
+	RemovePeer(id string) error
+}
+
 // blockchain is the interface with the blockchain required
 // by the filter manager
 type blockchainInterface interface {
@@ -34,38 +71,109 @@ type blockchainInterface interface {
 	// GetHeaderByNumber returns the header by number
 	GetHeaderByNumber(block uint64) (*types.Header, bool)
 
+	// StateHistory returns the number of blocks below the head whose
+	// state can be queried, zero if the state of all the blocks can be
+	StateHistory() uint64
+
 	// GetAvgGasPrice returns the average gas price
 	GetAvgGasPrice() *big.Int
 
 	// AddTx adds a new transaction to the tx pool
 	AddTx(tx *types.Transaction) error
 
+	// AddTxs adds a batch of transactions to the tx pool and
+	// returns the result of each one
+	AddTxs(txns []*types.Transaction) []error
+
 	// GetBlockByHash gets a block using the provided hash
 	GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool)
 
 	// GetBlockByNumber returns a block using the provided number
 	GetBlockByNumber(num uint64, full bool) (*types.Block, bool)
 
-	// ApplyTxn applies a transaction object to the blockchain
-	ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error)
+	// ApplyTxn applies a transaction object to the blockchain, the accounts
+	// of the override are replaced before
+	ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error)
 
 	// GetNonce returns the next nonce for this address
 	GetNonce(addr types.Address) (uint64, bool)
 
+	// Content returns the pending and queued txns of the tx pool by sender
+	Content() (map[types.Address][]*types.Transaction, map[types.Address][]*types.Transaction)
+
+	// SubscribeTxns subscribes for the txns admitted in the tx pool
+	SubscribeTxns() (<-chan *types.Transaction, func())
+
+	// FilterBlooms returns the block numbers in the range whose bloom might match the filter
+	FilterBlooms(from, to uint64, filter [][][]byte) ([]uint64, error)
+
+	// TraceTxn executes the txn of the block with the tracer and returns
+	// its receipt and its return value
+	TraceTxn(block *types.Block, hash types.Hash, tracer runtime.Tracer) (*types.Receipt, []byte, error)
+
+	// TraceBlock executes the txns of the block, each one with the tracer
+	// returned by newTracer
+	TraceBlock(block *types.Block, newTracer func(txn *types.Transaction) runtime.Tracer) ([]*state.TracedTxn, error)
+
 	stateHelperInterface
+	networkInterface
 }
 
 type nullBlockchainInterface struct {
 }
 
+func (b *nullBlockchainInterface) PeerCount() int {
+	return 0
+}
+
+func (b *nullBlockchainInterface) Peers() ([]*PeerInfo, error) {
+	return nil, nil
+}
+
+func (b *nullBlockchainInterface) NodeInfo() *NodeInfo {
+	return nil
+}
+
+func (b *nullBlockchainInterface) AddPeer(addr string) error {
+	return nil
+}
+
+func (b *nullBlockchainInterface) RemovePeer(id string) error {
+	return nil
+}
+
 func (b *nullBlockchainInterface) GetNonce(addr types.Address) (uint64, bool) {
 	return 0, false
 }
 
+func (b *nullBlockchainInterface) Content() (map[types.Address][]*types.Transaction, map[types.Address][]*types.Transaction) {
+	return nil, nil
+}
+
+func (b *nullBlockchainInterface) SubscribeTxns() (<-chan *types.Transaction, func()) {
+	return nil, func() {}
+}
+
+func (b *nullBlockchainInterface) FilterBlooms(from, to uint64, filter [][][]byte) ([]uint64, error) {
+	// without a bloom index every block in the range is a candidate
+	res := []uint64{}
+	for i := from; i <= to; i++ {
+		res = append(res, i)
+		if i == to {
+			break
+		}
+	}
+	return res, nil
+}
+
 func (b *nullBlockchainInterface) Header() *types.Header {
 	return nil
 }
 
+func (b *nullBlockchainInterface) StateHistory() uint64 {
+	return 0
+}
+
 func (b *nullBlockchainInterface) ReadTxLookup(txnHash types.Hash) (types.Hash, bool) {
 	return types.Hash{}, false
 }
@@ -90,6 +198,10 @@ func (b *nullBlockchainInterface) AddTx(tx *types.Transaction) error {
 	return nil
 }
 
+func (b *nullBlockchainInterface) AddTxs(txns []*types.Transaction) []error {
+	return make([]error, len(txns))
+}
+
 func (b *nullBlockchainInterface) State() state.State {
 	return nil
 }
@@ -106,14 +218,26 @@ func (b *nullBlockchainInterface) GetBlockByNumber(num uint64, full bool) (*type
 	return nil, false
 }
 
-func (b *nullBlockchainInterface) ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error) {
+func (b *nullBlockchainInterface) ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
 	return nil, false, nil
 }
 
+func (b *nullBlockchainInterface) TraceTxn(block *types.Block, hash types.Hash, tracer runtime.Tracer) (*types.Receipt, []byte, error) {
+	return nil, nil, nil
+}
+
+func (b *nullBlockchainInterface) TraceBlock(block *types.Block, newTracer func(txn *types.Transaction) runtime.Tracer) ([]*state.TracedTxn, error) {
+	return nil, nil
+}
+
 func (b *nullBlockchainInterface) GetCode(hash types.Hash) ([]byte, error) {
 	return nil, nil
 }
 
+func (b *nullBlockchainInterface) GetProof(root types.Hash, addr types.Address, slots []types.Hash) (*state.AccountProof, error) {
+	return nil, nil
+}
+
 func (b *nullBlockchainInterface) GetStorage(root types.Hash, addr types.Address, slot types.Hash) ([]byte, error) {
 	return nil, nil
 }
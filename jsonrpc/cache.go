@@ -0,0 +1,167 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/types"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// cacheableMethods are the methods whose results do not change once their
+// block is in the chain. The blocks of the results can only change in a reorg
+var cacheableMethods = map[string]struct{}{
+	"eth_getBlockByHash":                      {},
+	"eth_getBlockByNumber":                    {},
+	"eth_getBlockReceipts":                    {},
+	"eth_getTransactionByHash":                {},
+	"eth_getTransactionReceipt":               {},
+	"eth_getTransactionByBlockHashAndIndex":   {},
+	"eth_getTransactionByBlockNumberAndIndex": {},
+	"eth_getUncleByBlockHashAndIndex":         {},
+	"eth_getUncleByBlockNumberAndIndex":       {},
+}
+
+// responseCache caches the results of the cacheable methods by their params,
+// the results of the blocks replaced in a reorg are removed
+type responseCache struct {
+	lock  sync.Mutex
+	cache *lru.Cache
+
+	// generation changes in every reorg, the results
+	// computed before a reorg are not added
+	generation uint64
+}
+
+type cacheEntry struct {
+	// number is the block of the result, the
+	// head when the result does not include it
+	number uint64
+	data   json.RawMessage
+}
+
+func newResponseCache(size int) (*responseCache, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &responseCache{cache: cache}, nil
+}
+
+// cacheKey returns the key of the request, the requests of the
+// blocks by a tag like latest are not cached
+func cacheKey(req Request) (string, bool) {
+	if _, ok := cacheableMethods[req.Method]; !ok {
+		return "", false
+	}
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) == 0 {
+		return "", false
+	}
+	var tag string
+	if err := json.Unmarshal(params[0], &tag); err == nil && (tag == "latest" || tag == "pending") {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, req.Params); err != nil {
+		return "", false
+	}
+	return req.Method + buf.String(), true
+}
+
+func (c *responseCache) get(key string) (json.RawMessage, bool) {
+	entry, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return entry.(*cacheEntry).data, true
+}
+
+func (c *responseCache) currentGeneration() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.generation
+}
+
+// add caches the result computed in the generation, the empty results are not
+// cached since the block or the txn can be added to the chain later
+func (c *responseCache) add(generation uint64, key string, data json.RawMessage, head uint64) {
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return
+	}
+	number, ok := resultNumber(data)
+	if !ok {
+		number = head
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.cache.Add(key, &cacheEntry{number: number, data: data})
+}
+
+// watch removes the results of the blocks replaced in the reorgs of the chain
+func (c *responseCache) watch(sub blockchain.Subscription) {
+	for {
+		evnt := sub.GetEvent()
+		if evnt == nil {
+			return
+		}
+		if len(evnt.OldChain) != 0 {
+			c.reorg(evnt.OldChain)
+		}
+	}
+}
+
+// reorg removes the results from the fork, it is the lowest block of the old chain
+func (c *responseCache) reorg(oldChain []*types.Header) {
+	fork := oldChain[0].Number
+	for _, header := range oldChain {
+		if header.Number < fork {
+			fork = header.Number
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	for _, key := range c.cache.Keys() {
+		if entry, ok := c.cache.Peek(key); ok && entry.(*cacheEntry).number >= fork {
+			c.cache.Remove(key)
+		}
+	}
+}
+
+// resultNumber returns the block of a result, the one
+// of the first item if the result is an array
+func resultNumber(data json.RawMessage) (uint64, bool) {
+	if data[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil || len(items) == 0 {
+			return 0, false
+		}
+		data = items[0]
+	}
+	var obj struct {
+		Number      *argUint64 `json:"number"`
+		BlockNumber *argUint64 `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return 0, false
+	}
+	if obj.Number != nil {
+		return uint64(*obj.Number), true
+	}
+	if obj.BlockNumber != nil {
+		return uint64(*obj.BlockNumber), true
+	}
+	return 0, false
+}
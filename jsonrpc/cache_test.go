@@ -0,0 +1,120 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStoreCache struct {
+	mockBlockStore2
+	calls int
+}
+
+func (m *mockStoreCache) GetBlockByNumber(blockNumber uint64, full bool) (*types.Block, bool) {
+	m.calls++
+	return m.mockBlockStore2.GetBlockByNumber(blockNumber, full)
+}
+
+func TestCache_Key(t *testing.T) {
+	cases := []struct {
+		method string
+		params string
+		key    string
+	}{
+		{"eth_getBlockByNumber", `["0x1", false]`, `eth_getBlockByNumber["0x1",false]`},
+		{"eth_getTransactionByHash", `[ "0x01" ]`, `eth_getTransactionByHash["0x01"]`},
+		// the blocks by tag change with the head
+		{"eth_getBlockByNumber", `["latest", false]`, ""},
+		{"eth_getBlockReceipts", `["pending"]`, ""},
+		// the state of the accounts is not cached
+		{"eth_getBalance", `["0x01", "0x1"]`, ""},
+		{"eth_getBlockByHash", `[]`, ""},
+	}
+	for _, c := range cases {
+		key, ok := cacheKey(Request{Method: c.method, Params: json.RawMessage(c.params)})
+		assert.Equal(t, c.key != "", ok, c.params)
+		assert.Equal(t, c.key, key)
+	}
+}
+
+func TestCache_Dispatcher(t *testing.T) {
+	store := &mockStoreCache{}
+	for i := 0; i < 5; i++ {
+		store.add(&types.Block{
+			Header: &types.Header{
+				Number: uint64(i),
+			},
+		})
+	}
+
+	s := newTestDispatcher(hclog.NewNullLogger(), store)
+	cache, err := newResponseCache(10)
+	assert.NoError(t, err)
+	s.cache = cache
+
+	handle := func(req string) []byte {
+		resp, err := s.Handle([]byte(req), "")
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := handle(`{"id":1,"method":"eth_getBlockByNumber","params":["0x2", false]}`)
+	assert.Equal(t, 1, store.calls)
+
+	// the block is returned from the cache with the id of the request
+	resp2 := handle(`{"id":2,"method":"eth_getBlockByNumber","params":["0x2",false]}`)
+	assert.Equal(t, 1, store.calls)
+	var obj, obj2 Response
+	assert.NoError(t, json.Unmarshal(resp, &obj))
+	assert.NoError(t, json.Unmarshal(resp2, &obj2))
+	assert.Equal(t, 2, obj2.ID)
+	assert.Equal(t, obj.Result, obj2.Result)
+
+	// the blocks that do not exist are not cached
+	for i := 0; i < 2; i++ {
+		_, err := s.Handle([]byte(`{"id":3,"method":"eth_getBlockByNumber","params":["0x9",false]}`), "")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 3, store.calls)
+
+	// a reorg from the block removes it
+	s.cache.reorg([]*types.Header{{Number: 3}, {Number: 2}})
+	handle(`{"id":5,"method":"eth_getBlockByNumber","params":["0x2",false]}`)
+	assert.Equal(t, 4, store.calls)
+
+	// but not the ones before the fork
+	handle(`{"id":6,"method":"eth_getBlockByNumber","params":["0x1",false]}`)
+	s.cache.reorg([]*types.Header{{Number: 2}})
+	handle(`{"id":7,"method":"eth_getBlockByNumber","params":["0x1",false]}`)
+	assert.Equal(t, 5, store.calls)
+}
+
+func TestCache_Generation(t *testing.T) {
+	cache, err := newResponseCache(10)
+	assert.NoError(t, err)
+
+	data := json.RawMessage(`{"number":"0x5"}`)
+
+	// the results computed before a reorg are not added
+	generation := cache.currentGeneration()
+	cache.reorg([]*types.Header{{Number: 8}})
+	cache.add(generation, "a", data, 10)
+	_, ok := cache.get("a")
+	assert.False(t, ok)
+
+	cache.add(cache.currentGeneration(), "a", data, 10)
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+
+	// the results without a block are removed with the head
+	cache.add(cache.currentGeneration(), "b", json.RawMessage(`"0x1"`), 10)
+	cache.reorg([]*types.Header{{Number: 9}})
+	_, ok = cache.get("a")
+	assert.True(t, ok)
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+}
@@ -77,3 +77,28 @@ func (b *BlockNumber) UnmarshalJSON(buffer []byte) error {
 	*b = num
 	return nil
 }
+
+// BlockNumberOrHash is a block by either its number or its hash
+type BlockNumberOrHash struct {
+	BlockNumber *BlockNumber
+	BlockHash   *types.Hash
+}
+
+// UnmarshalJSON decodes a block hash or any of the values of a block number
+func (b *BlockNumberOrHash) UnmarshalJSON(buffer []byte) error {
+	str := strings.Trim(string(buffer), "\"")
+	if len(str) == 2+2*types.HashLength && strings.HasPrefix(str, "0x") {
+		hash := types.Hash{}
+		if err := hash.UnmarshalText([]byte(str)); err != nil {
+			return err
+		}
+		b.BlockHash = &hash
+		return nil
+	}
+	num, err := stringToBlockNumber(str)
+	if err != nil {
+		return err
+	}
+	b.BlockNumber = &num
+	return nil
+}
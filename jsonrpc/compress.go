@@ -0,0 +1,47 @@
+package jsonrpc
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// gzipMinSize is the minimum size of the responses that are
+// compressed, the small ones do not save bandwidth
+const gzipMinSize = 1024
+
+var gzipPool = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(ioutil.Discard)
+	},
+}
+
+// acceptsGzip returns whether the client of the request accepts gzip responses
+func acceptsGzip(req *http.Request) bool {
+	for _, encoding := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		if strings.TrimSpace(strings.SplitN(encoding, ";", 2)[0]) == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeResponse writes the response of the request, compressed
+// with gzip if the client accepts it
+func writeResponse(w http.ResponseWriter, req *http.Request, data []byte) {
+	w.Header().Add("Vary", "Accept-Encoding")
+	if len(data) < gzipMinSize || !acceptsGzip(req) {
+		w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Encoding", "gzip")
+
+	gw := gzipPool.Get().(*gzip.Writer)
+	defer gzipPool.Put(gw)
+
+	gw.Reset(w)
+	gw.Write(data)
+	gw.Close()
+}
@@ -0,0 +1,186 @@
+package jsonrpc
+
+import (
+	"fmt"
+
+	"github.com/0xPolygon/minimal/state/runtime"
+	"github.com/0xPolygon/minimal/state/runtime/tracer"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// Debug is the debug jsonrpc endpoint
+type Debug struct {
+	d *Dispatcher
+}
+
+// maxTraceSteps is the max number of opcodes logged for a txn, the limit of
+// the trace config can only lower it
+const maxTraceSteps = 100000
+
+// traceConfig are the parts of the state traced, the memory is
+// only traced with enableMemory and without disableMemory
+type traceConfig struct {
+	DisableStack   bool `json:"disableStack"`
+	EnableMemory   bool `json:"enableMemory"`
+	DisableMemory  bool `json:"disableMemory"`
+	DisableStorage bool `json:"disableStorage"`
+	Limit          int  `json:"limit"`
+}
+
+type structLogRes struct {
+	PC      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Error   string            `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+type executionResult struct {
+	Gas         uint64          `json:"gas"`
+	Failed      bool            `json:"failed"`
+	ReturnValue string          `json:"returnValue"`
+	StructLogs  []*structLogRes `json:"structLogs"`
+}
+
+type txTraceResult struct {
+	TxHash types.Hash       `json:"txHash"`
+	Result *executionResult `json:"result"`
+}
+
+// TraceTransaction executes again the transaction of the hash on top of
+// the state of its block and returns the opcodes executed
+func (d *Debug) TraceTransaction(hash types.Hash, config *traceConfig) (interface{}, error) {
+	blockHash, ok := d.d.store.ReadTxLookup(hash)
+	if !ok {
+		return nil, fmt.Errorf("txn %s not found", hash)
+	}
+	block, ok := d.d.store.GetBlockByHash(blockHash, true)
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", blockHash)
+	}
+
+	logger := newStructLogger(config)
+	receipt, ret, err := d.d.store.TraceTxn(block, hash, logger)
+	if err != nil {
+		return nil, err
+	}
+	return toExecutionResult(receipt, ret, logger), nil
+}
+
+// TraceBlockByNumber executes again the transactions of the block of the
+// number and returns the opcodes executed by each one
+func (d *Debug) TraceBlockByNumber(number BlockNumber, config *traceConfig) (interface{}, error) {
+	var num uint64
+	switch number {
+	case LatestBlockNumber:
+		num = d.d.store.Header().Number
+
+	case EarliestBlockNumber:
+		return nil, fmt.Errorf("tracing the genesis block is not supported")
+
+	case PendingBlockNumber:
+		return nil, fmt.Errorf("tracing the pending block is not supported")
+
+	default:
+		num = uint64(number)
+	}
+
+	block, ok := d.d.store.GetBlockByNumber(num, true)
+	if !ok {
+		return nil, fmt.Errorf("block %d not found", num)
+	}
+	return d.traceBlock(block, config)
+}
+
+// TraceBlockByHash executes again the transactions of the block of the
+// hash and returns the opcodes executed by each one
+func (d *Debug) TraceBlockByHash(hash types.Hash, config *traceConfig) (interface{}, error) {
+	block, ok := d.d.store.GetBlockByHash(hash, true)
+	if !ok {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return d.traceBlock(block, config)
+}
+
+func (d *Debug) traceBlock(block *types.Block, config *traceConfig) (interface{}, error) {
+	loggers := map[types.Hash]*tracer.StructLogger{}
+	traced, err := d.d.store.TraceBlock(block, func(txn *types.Transaction) runtime.Tracer {
+		logger := newStructLogger(config)
+		loggers[txn.Hash] = logger
+		return logger
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []*txTraceResult{}
+	for _, t := range traced {
+		res = append(res, &txTraceResult{
+			TxHash: t.Txn.Hash,
+			Result: toExecutionResult(t.Receipt, t.ReturnValue, loggers[t.Txn.Hash]),
+		})
+	}
+	return res, nil
+}
+
+func newStructLogger(config *traceConfig) *tracer.StructLogger {
+	if config == nil {
+		config = &traceConfig{}
+	}
+	limit := config.Limit
+	if limit <= 0 || limit > maxTraceSteps {
+		limit = maxTraceSteps
+	}
+	return tracer.NewStructLogger(tracer.Config{
+		DisableStack:   config.DisableStack,
+		EnableMemory:   config.EnableMemory && !config.DisableMemory,
+		DisableStorage: config.DisableStorage,
+		Limit:          limit,
+	})
+}
+
+func toExecutionResult(receipt *types.Receipt, ret []byte, logger *tracer.StructLogger) *executionResult {
+	res := &executionResult{
+		Gas:         receipt.GasUsed,
+		Failed:      receipt.Status != nil && *receipt.Status == types.ReceiptFailed,
+		ReturnValue: fmt.Sprintf("%x", ret),
+		StructLogs:  []*structLogRes{},
+	}
+	for _, log := range logger.StructLogs() {
+		res.StructLogs = append(res.StructLogs, toStructLog(log))
+	}
+	return res
+}
+
+func toStructLog(log *tracer.StructLog) *structLogRes {
+	res := &structLogRes{
+		PC:      log.PC,
+		Op:      log.Op,
+		Gas:     log.Gas,
+		GasCost: log.GasCost,
+		Depth:   log.Depth,
+	}
+	if log.Err != nil {
+		res.Error = log.Err.Error()
+	}
+	if log.Stack != nil {
+		res.Stack = make([]string, len(log.Stack))
+		for i, item := range log.Stack {
+			res.Stack[i] = fmt.Sprintf("0x%x", item)
+		}
+	}
+	for i := 0; i+32 <= len(log.Memory); i += 32 {
+		res.Memory = append(res.Memory, fmt.Sprintf("%x", log.Memory[i:i+32]))
+	}
+	if log.Storage != nil {
+		res.Storage = make(map[string]string, len(log.Storage))
+		for k, v := range log.Storage {
+			res.Storage[fmt.Sprintf("%x", k[:])] = fmt.Sprintf("%x", v[:])
+		}
+	}
+	return res
+}
@@ -0,0 +1,160 @@
+package jsonrpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/state/runtime"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStoreTrace struct {
+	nullBlockchainInterface
+
+	block *types.Block
+}
+
+func (m *mockStoreTrace) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
+	if hash != hash1 {
+		return types.Hash{}, false
+	}
+	return m.block.Hash(), true
+}
+
+func (m *mockStoreTrace) GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool) {
+	return m.block, hash == m.block.Hash()
+}
+
+func (m *mockStoreTrace) Header() *types.Header {
+	return m.block.Header
+}
+
+func (m *mockStoreTrace) GetBlockByNumber(num uint64, full bool) (*types.Block, bool) {
+	return m.block, num == m.block.Number()
+}
+
+func (m *mockStoreTrace) TraceBlock(block *types.Block, newTracer func(txn *types.Transaction) runtime.Tracer) ([]*state.TracedTxn, error) {
+	traced := []*state.TracedTxn{}
+	for _, txn := range block.Transactions {
+		tracer := newTracer(txn)
+		tracer.CaptureState(&runtime.Step{Op: "STOP", Gas: 100, Depth: 1}, nil)
+
+		receipt := &types.Receipt{GasUsed: 21000}
+		receipt.SetStatus(types.ReceiptFailed)
+		traced = append(traced, &state.TracedTxn{Txn: txn, Receipt: receipt})
+	}
+	return traced, nil
+}
+
+func (m *mockStoreTrace) TraceTxn(block *types.Block, hash types.Hash, tracer runtime.Tracer) (*types.Receipt, []byte, error) {
+	memory := make([]byte, 32)
+	memory[31] = 0x2a
+
+	tracer.CaptureState(&runtime.Step{
+		PC:      1,
+		Op:      "SSTORE",
+		Gas:     100,
+		GasCost: 5,
+		Depth:   1,
+		Stack:   []*big.Int{big.NewInt(0x2a), big.NewInt(1)},
+		Memory:  memory,
+	}, nil)
+
+	receipt := &types.Receipt{GasUsed: 21005}
+	receipt.SetStatus(types.ReceiptSuccess)
+	return receipt, []byte{0x1}, nil
+}
+
+func TestDebug_TraceTransaction(t *testing.T) {
+	store := &mockStoreTrace{block: &types.Block{Header: &types.Header{Number: 1}}}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	resp, err := dispatcher.Handle([]byte(`{"id":1,"method":"debug_traceTransaction","params":["`+hash1.String()+`"]}`), "")
+	assert.NoError(t, err)
+
+	var res executionResult
+	assert.NoError(t, expectJSONResult(resp, &res))
+	assert.Equal(t, uint64(21005), res.Gas)
+	assert.False(t, res.Failed)
+	assert.Equal(t, "01", res.ReturnValue)
+	assert.Equal(t, []*structLogRes{
+		{
+			PC:      1,
+			Op:      "SSTORE",
+			Gas:     100,
+			GasCost: 5,
+			Depth:   1,
+			Stack:   []string{"0x2a", "0x1"},
+			Storage: map[string]string{
+				"0000000000000000000000000000000000000000000000000000000000000001": "000000000000000000000000000000000000000000000000000000000000002a",
+			},
+		},
+	}, res.StructLogs)
+
+	// the parts of the state are left out with the config
+	resp, err = dispatcher.Handle([]byte(`{"id":1,"method":"debug_traceTransaction","params":["`+hash1.String()+`", {"disableStack":true,"disableStorage":true}]}`), "")
+	assert.NoError(t, err)
+
+	res = executionResult{}
+	assert.NoError(t, expectJSONResult(resp, &res))
+	assert.Equal(t, []*structLogRes{{PC: 1, Op: "SSTORE", Gas: 100, GasCost: 5, Depth: 1}}, res.StructLogs)
+
+	// the memory is only included if it is enabled
+	resp, err = dispatcher.Handle([]byte(`{"id":1,"method":"debug_traceTransaction","params":["`+hash1.String()+`", {"disableStack":true,"enableMemory":true,"disableStorage":true}]}`), "")
+	assert.NoError(t, err)
+
+	res = executionResult{}
+	assert.NoError(t, expectJSONResult(resp, &res))
+	assert.Equal(t, []string{"000000000000000000000000000000000000000000000000000000000000002a"}, res.StructLogs[0].Memory)
+
+	// unknown txn
+	_, err = dispatcher.Handle([]byte(`{"id":1,"method":"debug_traceTransaction","params":["`+hash2.String()+`"]}`), "")
+	assert.Error(t, err)
+}
+
+func TestDebug_TraceBlock(t *testing.T) {
+	store := &mockStoreTrace{
+		block: &types.Block{
+			Header: &types.Header{Number: 1},
+			Transactions: []*types.Transaction{
+				{Hash: hash1},
+				{Hash: hash2},
+			},
+		},
+	}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	expected := []*txTraceResult{}
+	for _, hash := range []types.Hash{hash1, hash2} {
+		expected = append(expected, &txTraceResult{
+			TxHash: hash,
+			Result: &executionResult{
+				Gas:         21000,
+				Failed:      true,
+				ReturnValue: "",
+				StructLogs:  []*structLogRes{{Op: "STOP", Gas: 100, Depth: 1}},
+			},
+		})
+	}
+
+	reqs := []string{
+		`{"id":1,"method":"debug_traceBlockByNumber","params":["0x1"]}`,
+		`{"id":1,"method":"debug_traceBlockByNumber","params":["latest"]}`,
+		`{"id":1,"method":"debug_traceBlockByHash","params":["` + store.block.Hash().String() + `"]}`,
+	}
+	for _, req := range reqs {
+		resp, err := dispatcher.Handle([]byte(req), "")
+		assert.NoError(t, err)
+
+		var res []*txTraceResult
+		assert.NoError(t, expectJSONResult(resp, &res))
+		assert.Equal(t, expected, res)
+	}
+
+	// unknown block
+	_, err := dispatcher.Handle([]byte(`{"id":1,"method":"debug_traceBlockByNumber","params":["0x2"]}`), "")
+	assert.Error(t, err)
+}
@@ -1,13 +1,17 @@
 package jsonrpc
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
+	"github.com/0xPolygon/minimal/helper/hex"
 	"github.com/0xPolygon/minimal/types"
 	"github.com/hashicorp/go-hclog"
 )
@@ -21,6 +25,15 @@ func invalidMethod(method string) error {
 	return &ErrorObject{Code: -32601, Message: fmt.Sprintf("The method %s does not exist/is not available", method)}
 }
 
+// txnRejected is the error of a transaction that is not admitted in the pool,
+// the transactions that revert in the simulation of the pool return the revert error
+func txnRejected(err error) error {
+	if reverted, ok := err.(interface{ ReturnValue() []byte }); ok && len(reverted.ReturnValue()) != 0 {
+		return revertError(reverted.ReturnValue())
+	}
+	return &ErrorObject{Code: -32000, Message: err.Error()}
+}
+
 func invalidArguments(method string) error {
 	return &ErrorObject{Code: -32602, Message: fmt.Sprintf("invalid arguments to %s", method)}
 }
@@ -35,16 +48,33 @@ type funcData struct {
 	reqt  []reflect.Type
 	fv    reflect.Value
 	isDyn bool
+
+	// withCaller is set if the first argument is the caller of the request
+	withCaller bool
 }
 
 func (f *funcData) numParams() int {
+	if f.withCaller {
+		return f.inNum - 2
+	}
 	return f.inNum - 1
 }
 
+// caller is the client of a request, the endpoints whose first
+// argument is a caller receive it instead of a param
+type caller struct {
+	client string
+}
+
+var callerType = reflect.TypeOf(caller{})
+
 type endpoints struct {
-	Eth  *Eth
-	Web3 *Web3
-	Net  *Net
+	Eth    *Eth
+	Web3   *Web3
+	Net    *Net
+	TxPool *TxPool
+	Debug  *Debug
+	Admin  *Admin
 }
 
 type enabledEndpoints map[string]struct{}
@@ -57,6 +87,26 @@ type Dispatcher struct {
 	endpoints     endpoints
 	filterManager *FilterManager
 	chainID       uint64
+
+	// batchLimit is the maximum number of requests
+	// of a batch, zero means no limit
+	batchLimit uint64
+
+	// logsBlockRange and logsLimit are the maximum number of blocks
+	// and of logs of a query of logs, zero means no limit
+	logsBlockRange uint64
+	logsLimit      uint64
+
+	// limits are the methods served to the clients, there
+	// are no restrictions if nil
+	limits *limits
+
+	// cache are the results of the immutable data, it is disabled if nil
+	cache *responseCache
+
+	// metrics and accessLog record the requests, they are disabled if nil
+	metrics   *metrics
+	accessLog hclog.Logger
 }
 
 // newTestDispatcher returns a dispatcher without the filter manager, used for testing
@@ -89,10 +139,21 @@ func (d *Dispatcher) registerEndpoints() {
 	d.endpoints.Eth = &Eth{d}
 	d.endpoints.Net = &Net{d}
 	d.endpoints.Web3 = &Web3{d}
+	d.endpoints.TxPool = &TxPool{d}
+	d.endpoints.Debug = &Debug{d}
 
 	d.registerService("eth", d.endpoints.Eth)
 	d.registerService("net", d.endpoints.Net)
 	d.registerService("web3", d.endpoints.Web3)
+	d.registerService("txpool", d.endpoints.TxPool)
+	d.registerService("debug", d.endpoints.Debug)
+}
+
+// registerAdmin registers the admin namespace, it is not
+// registered by default because it manages the node
+func (d *Dispatcher) registerAdmin() {
+	d.endpoints.Admin = &Admin{d}
+	d.registerService("admin", d.endpoints.Admin)
 }
 
 func (d *Dispatcher) getFnHandler(req Request) (*serviceData, *funcData, error) {
@@ -118,7 +179,7 @@ type wsConn interface {
 	WriteMessage(b []byte) error
 }
 
-func (d *Dispatcher) handleSubscribe(req Request, conn wsConn) (string, error) {
+func (d *Dispatcher) handleSubscribe(req Request, client string, conn wsConn) (string, error) {
 	var params []interface{}
 	if err := json.Unmarshal(req.Params, &params); err != nil {
 		return "", invalidJSONRequest
@@ -132,22 +193,30 @@ func (d *Dispatcher) handleSubscribe(req Request, conn wsConn) (string, error) {
 		return "", fmt.Errorf("subscribe method '%s' not found", params[0])
 	}
 
-	var filterID string
 	if subscribeMethod == "newHeads" {
-		filterID = d.filterManager.NewBlockFilter(conn)
+		return d.filterManager.NewBlockFilter(client, conn)
 
 	} else if subscribeMethod == "logs" {
-		logFilter, err := decodeLogFilterFromInterface(params[1])
-		if err != nil {
-			return "", err
+		// the logs subscription without criteria notifies all the logs
+		logFilter := &LogFilter{}
+		if len(params) > 1 {
+			var err error
+			if logFilter, err = decodeLogFilterFromInterface(params[1]); err != nil {
+				return "", err
+			}
 		}
-		filterID = d.filterManager.NewLogFilter(logFilter, conn)
+		return d.filterManager.NewLogFilter(logFilter, client, conn)
 
-	} else {
-		return "", fmt.Errorf("subscribe method %s not found", subscribeMethod)
+	} else if subscribeMethod == "newPendingTransactions" {
+		full := false
+		if len(params) > 1 {
+			if full, ok = params[1].(bool); !ok {
+				return "", fmt.Errorf("the full txns flag has to be a bool")
+			}
+		}
+		return d.filterManager.NewPendingTxnsFilter(full, client, conn)
 	}
-
-	return filterID, nil
+	return "", fmt.Errorf("subscribe method %s not found", subscribeMethod)
 }
 
 func (d *Dispatcher) handleUnsubscribe(req Request) (bool, error) {
@@ -167,16 +236,32 @@ func (d *Dispatcher) handleUnsubscribe(req Request) (bool, error) {
 	return d.filterManager.Uninstall(filterID), nil
 }
 
-func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
+func (d *Dispatcher) HandleWs(reqBody []byte, client string, conn wsConn) ([]byte, error) {
+	if isBatch(reqBody) {
+		// the batches cannot subscribe
+		return d.handleBatch(reqBody, client)
+	}
+
 	var req Request
 	if err := json.Unmarshal(reqBody, &req); err != nil {
 		return nil, invalidJSONRequest
 	}
 
+	start := time.Now()
+	resp, err := d.handleWsReq(req, client, conn)
+	d.observe(client, req, len(reqBody), time.Since(start), resp, err)
+	return resp, err
+}
+
+func (d *Dispatcher) handleWsReq(req Request, client string, conn wsConn) ([]byte, error) {
+	if err := d.limits.check(client, req.Method); err != nil {
+		return nil, err
+	}
+
 	// if the request method is eth_subscribe we need to create a
 	// new filter with ws connection
 	if req.Method == "eth_subscribe" {
-		filterID, err := d.handleSubscribe(req, conn)
+		filterID, err := d.handleSubscribe(req, client, conn)
 		if err != nil {
 			return nil, err
 		}
@@ -191,33 +276,207 @@ func (d *Dispatcher) HandleWs(reqBody []byte, conn wsConn) ([]byte, error) {
 			return nil, err
 		}
 
-		res := "false"
-		if ok {
-			res = "true"
-		}
-		resp := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"%s"}`, req.ID, res)
+		resp := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%t}`, req.ID, ok)
 		return []byte(resp), nil
 	}
 
 	// its a normal query that we handle with the dispatcher
-	resp, err := d.handleReq(req)
+	resp, err := d.handleReq(req, client)
 	if err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (d *Dispatcher) Handle(reqBody []byte) ([]byte, error) {
+// RemoveWs uninstalls the filters of a closed websocket connection
+func (d *Dispatcher) RemoveWs(conn wsConn) {
+	if d.filterManager != nil {
+		d.filterManager.RemoveWs(conn)
+	}
+}
+
+// Handle handles the requests of the client, the client is
+// empty if the requests are not restricted by the limits
+func (d *Dispatcher) Handle(reqBody []byte, client string) ([]byte, error) {
+	if isBatch(reqBody) {
+		return d.handleBatch(reqBody, client)
+	}
+
 	var req Request
 	if err := json.Unmarshal(reqBody, &req); err != nil {
 		return nil, invalidJSONRequest
 	}
-	return d.handleReq(req)
+
+	start := time.Now()
+	resp, err := d.handleClientReq(req, client)
+	d.observe(client, req, len(reqBody), time.Since(start), resp, err)
+	return resp, err
+}
+
+func (d *Dispatcher) handleClientReq(req Request, client string) ([]byte, error) {
+	if err := d.limits.check(client, req.Method); err != nil {
+		return nil, err
+	}
+	return d.handleReq(req, client)
+}
+
+func isBatch(reqBody []byte) bool {
+	body := bytes.TrimSpace(reqBody)
+	return len(body) != 0 && body[0] == '['
+}
+
+// maxBatchWorkers is the number of requests of a batch handled at once
+const maxBatchWorkers = 16
+
+// handleBatch handles a batch of requests, the raw transactions of the
+// batch are added to the pool at once. The requests are handled
+// concurrently but the responses keep the order of the batch
+func (d *Dispatcher) handleBatch(reqBody []byte, client string) ([]byte, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(reqBody, &raw); err != nil {
+		return nil, invalidJSONRequest
+	}
+	if len(raw) == 0 {
+		return nil, invalidJSONRequest
+	}
+	if d.batchLimit != 0 && uint64(len(raw)) > d.batchLimit {
+		return nil, &ErrorObject{Code: -32600, Message: fmt.Sprintf("batch of %d requests exceeds the limit of %d", len(raw), d.batchLimit)}
+	}
+
+	start := time.Now()
+
+	reqs := make([]Request, len(raw))
+	resps := make([]json.RawMessage, len(raw))
+	for i, item := range raw {
+		if err := json.Unmarshal(item, &reqs[i]); err != nil {
+			resps[i] = errorResponse(0, invalidJSONRequest)
+			continue
+		}
+		// each request of the batch counts in the limits
+		if err := d.limits.check(client, reqs[i].Method); err != nil {
+			resps[i] = errorResponse(reqs[i].ID, err)
+		}
+	}
+	d.sendRawTransactions(reqs, resps)
+
+	// the requests answered so far take the time of the txns added at once
+	elapsed := time.Since(start)
+	durations := make([]time.Duration, len(raw))
+	for i := range durations {
+		durations[i] = elapsed
+	}
+
+	// the txns sent by the node are signed in order since
+	// they take the nonces of the account
+	var sends []int
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, maxBatchWorkers)
+	for i, req := range reqs {
+		if resps[i] != nil {
+			continue
+		}
+		if req.Method == "eth_sendTransaction" {
+			sends = append(sends, i)
+			continue
+		}
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, req Request) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+			reqStart := time.Now()
+			resps[i] = d.handleBatchReq(req, client)
+			durations[i] = time.Since(reqStart)
+		}(i, req)
+	}
+	for _, i := range sends {
+		reqStart := time.Now()
+		resps[i] = d.handleBatchReq(reqs[i], client)
+		durations[i] = time.Since(reqStart)
+	}
+	wg.Wait()
+
+	if d.metrics != nil || d.accessLog != nil {
+		for i, req := range reqs {
+			d.observe(client, req, len(raw[i]), durations[i], resps[i], responseError(resps[i]))
+		}
+	}
+
+	return json.Marshal(resps)
+}
+
+func (d *Dispatcher) handleBatchReq(req Request, client string) json.RawMessage {
+	resp, err := d.handleReq(req, client)
+	if err != nil {
+		return errorResponse(req.ID, err)
+	}
+	return resp
+}
+
+// errorResponse is the response of a request of a batch that failed
+func errorResponse(id int, err error) json.RawMessage {
+	obj, ok := err.(*ErrorObject)
+	if !ok {
+		obj = internalError
+	}
+	data, _ := json.Marshal(&Response{
+		ID:    id,
+		Error: obj,
+	})
+	return data
 }
 
-func (d *Dispatcher) handleReq(req Request) ([]byte, error) {
+func (d *Dispatcher) handleReq(req Request, client string) ([]byte, error) {
 	d.logger.Debug("request", "method", req.Method, "id", req.ID)
 
+	data, err := d.cachedCall(req, caller{client: client})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := Response{
+		ID:     req.ID,
+		Result: data,
+	}
+	respBytes, err := json.Marshal(resp)
+	if err != nil {
+		return nil, d.internalError(req.Method, err)
+	}
+	return respBytes, nil
+}
+
+// cachedCall returns the result of the request from the cache if it is
+// cacheable, the result is cached if it is not found
+func (d *Dispatcher) cachedCall(req Request, c caller) (json.RawMessage, error) {
+	if d.cache == nil {
+		return d.call(req, c)
+	}
+	key, ok := cacheKey(req)
+	if !ok {
+		return d.call(req, c)
+	}
+	if data, ok := d.cache.get(key); ok {
+		return data, nil
+	}
+
+	generation := d.cache.currentGeneration()
+	data, err := d.call(req, c)
+	if err != nil {
+		return nil, err
+	}
+	var head uint64
+	if header := d.store.Header(); header != nil {
+		head = header.Number
+	}
+	d.cache.add(generation, key, data, head)
+	return data, nil
+}
+
+// call calls the endpoint of the request and returns its result
+func (d *Dispatcher) call(req Request, c caller) (json.RawMessage, error) {
 	service, fd, err := d.getFnHandler(req)
 	if err != nil {
 		return nil, err
@@ -226,11 +485,16 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, error) {
 	inArgs := make([]reflect.Value, fd.inNum)
 	inArgs[0] = service.sv
 
+	first := 1
+	if fd.withCaller {
+		inArgs[1] = reflect.ValueOf(c)
+		first = 2
+	}
 	inputs := make([]interface{}, fd.numParams())
-	for i := 0; i < fd.inNum-1; i++ {
-		val := reflect.New(fd.reqt[i+1])
+	for i := 0; i < fd.numParams(); i++ {
+		val := reflect.New(fd.reqt[i+first])
 		inputs[i] = val.Interface()
-		inArgs[i+1] = val.Elem()
+		inArgs[i+first] = val.Elem()
 	}
 
 	if err := json.Unmarshal(req.Params, &inputs); err != nil {
@@ -240,6 +504,10 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, error) {
 	output := fd.fv.Call(inArgs)
 	err = getError(output[1])
 	if err != nil {
+		if obj, ok := err.(*ErrorObject); ok {
+			// the endpoint already returns a descriptive error
+			return nil, obj
+		}
 		return nil, d.internalError(req.Method, err)
 	}
 
@@ -251,16 +519,7 @@ func (d *Dispatcher) handleReq(req Request) ([]byte, error) {
 			return nil, d.internalError(req.Method, err)
 		}
 	}
-
-	resp := Response{
-		ID:     req.ID,
-		Result: data,
-	}
-	respBytes, err := json.Marshal(resp)
-	if err != nil {
-		return nil, d.internalError(req.Method, err)
-	}
-	return respBytes, nil
+	return data, nil
 }
 
 func (d *Dispatcher) internalError(method string, err error) error {
@@ -305,6 +564,7 @@ func (d *Dispatcher) registerService(serviceName string, service interface{}) {
 				fd.isDyn = true
 			}
 		}
+		fd.withCaller = fd.inNum > 1 && fd.reqt[1] == callerType
 		funcMap[name] = fd
 	}
 
@@ -378,7 +638,11 @@ func (d *Dispatcher) getBlockHeaderImpl(number BlockNumber) (*types.Header, erro
 		return d.store.Header(), nil
 
 	case EarliestBlockNumber:
-		return nil, fmt.Errorf("fetching the earliest header is not supported")
+		header, ok := d.store.GetHeaderByNumber(0)
+		if !ok {
+			return nil, fmt.Errorf("Error fetching the genesis header")
+		}
+		return header, nil
 
 	case PendingBlockNumber:
 		return nil, fmt.Errorf("fetching the pending header is not supported")
@@ -393,6 +657,26 @@ func (d *Dispatcher) getBlockHeaderImpl(number BlockNumber) (*types.Header, erro
 	}
 }
 
+// getStateHeader returns the header of the block of a state query,
+// the state of the blocks below the state history is not available
+func (d *Dispatcher) getStateHeader(number BlockNumber) (*types.Header, error) {
+	header, err := d.getBlockHeaderImpl(number)
+	if err != nil {
+		return nil, err
+	}
+	history := d.store.StateHistory()
+	if history == 0 {
+		return header, nil
+	}
+	if head := d.store.Header(); head != nil && header.Number+history < head.Number {
+		return nil, &ErrorObject{
+			Code:    -32000,
+			Message: fmt.Sprintf("state of block %d is not available, the node keeps the state of the last %d blocks unless it runs in archive mode", header.Number, history),
+		}
+	}
+	return header, nil
+}
+
 func (d *Dispatcher) getNextNonce(address types.Address, number BlockNumber) (uint64, error) {
 	if number == PendingBlockNumber {
 		res, ok := d.store.GetNonce(address)
@@ -400,7 +684,7 @@ func (d *Dispatcher) getNextNonce(address types.Address, number BlockNumber) (ui
 			return res, nil
 		}
 	}
-	header, err := d.getBlockHeaderImpl(number)
+	header, err := d.getStateHeader(number)
 	if err != nil {
 		return 0, err
 	}
@@ -411,6 +695,53 @@ func (d *Dispatcher) getNextNonce(address types.Address, number BlockNumber) (ui
 	return acc.Nonce, nil
 }
 
+// sendRawTransactions adds the txns of the eth_sendRawTransaction requests
+// of a batch to the pool at once and sets their responses
+func (d *Dispatcher) sendRawTransactions(reqs []Request, resps []json.RawMessage) {
+	index := []int{}
+	txns := []*types.Transaction{}
+	for i, req := range reqs {
+		if resps[i] != nil || req.Method != "eth_sendRawTransaction" {
+			continue
+		}
+		var params []string
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params) != 1 {
+			resps[i] = errorResponse(req.ID, invalidArguments(req.Method))
+			continue
+		}
+		buf, err := hex.DecodeHex(params[0])
+		if err != nil {
+			resps[i] = errorResponse(req.ID, invalidArguments(req.Method))
+			continue
+		}
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalRLP(buf); err != nil {
+			resps[i] = errorResponse(req.ID, d.internalError(req.Method, err))
+			continue
+		}
+		tx.ComputeHash()
+
+		index = append(index, i)
+		txns = append(txns, tx)
+	}
+	if len(txns) == 0 {
+		return
+	}
+
+	for j, err := range d.store.AddTxs(txns) {
+		req := reqs[index[j]]
+		if err != nil {
+			resps[index[j]] = errorResponse(req.ID, txnRejected(err))
+			continue
+		}
+		result, _ := json.Marshal(txns[j].Hash.String())
+		resps[index[j]], _ = json.Marshal(&Response{
+			ID:     req.ID,
+			Result: result,
+		})
+	}
+}
+
 func (d *Dispatcher) decodeTxn(arg *txnArgs) (*types.Transaction, error) {
 	// set default values
 	if arg.From == nil {
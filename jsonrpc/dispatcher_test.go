@@ -2,7 +2,10 @@ package jsonrpc
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -59,7 +62,7 @@ func TestDispatcherWebsocket(t *testing.T) {
 		"method": "eth_subscribe",
 		"params": ["newHeads"]
 	}`)
-	if _, err := s.HandleWs(req, mock); err != nil {
+	if _, err := s.HandleWs(req, "", mock); err != nil {
 		t.Fatal(err)
 	}
 
@@ -80,6 +83,111 @@ func TestDispatcherWebsocket(t *testing.T) {
 	}
 }
 
+func TestDispatcherFilterLimits(t *testing.T) {
+	s := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+	s.filterManager.maxConnFilters = 1
+	s.filterManager.maxClientFilters = 2
+
+	// the filters of the http clients are limited by their ip
+	_, err := s.Handle([]byte(`{"id":1,"method":"eth_newBlockFilter","params":[]}`), "a")
+	assert.NoError(t, err)
+
+	mock := &mockWsConn{
+		msgCh: make(chan []byte, 1),
+	}
+	_, err = s.HandleWs([]byte(`{"id":2,"method":"eth_subscribe","params":["newHeads"]}`), "a", mock)
+	assert.NoError(t, err)
+
+	_, err = s.Handle([]byte(`{"id":3,"method":"eth_newFilter","params":[{}]}`), "a")
+	assert.Equal(t, &ErrorObject{Code: -32005, Message: "the client reached the limit of 2 filters"}, err)
+
+	// the subscriptions are also limited by their connection
+	_, err = s.HandleWs([]byte(`{"id":4,"method":"eth_subscribe","params":["logs"]}`), "b", mock)
+	assert.Equal(t, &ErrorObject{Code: -32005, Message: "the connection reached the limit of 1 subscriptions"}, err)
+}
+
+func TestDispatcherWebsocket_PendingTxns(t *testing.T) {
+	store := newMockStore()
+
+	s := newDispatcher(hclog.NewNullLogger(), store, 0)
+	s.registerEndpoints()
+
+	mock := &mockWsConn{
+		msgCh: make(chan []byte, 1),
+	}
+
+	req := []byte(`{
+		"method": "eth_subscribe",
+		"params": ["newPendingTransactions", true]
+	}`)
+	if _, err := s.HandleWs(req, "", mock); err != nil {
+		t.Fatal(err)
+	}
+
+	store.txnCh <- &types.Transaction{
+		Hash:     hash1,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(1),
+	}
+
+	select {
+	case msg := <-mock.msgCh:
+		var res struct {
+			Params struct {
+				Result map[string]interface{}
+			}
+		}
+		assert.NoError(t, json.Unmarshal(msg, &res))
+		assert.Equal(t, hash1.String(), res.Params.Result["hash"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("bad")
+	}
+}
+
+func TestDispatcherBatch(t *testing.T) {
+	store := &mockBlockStore2{}
+	for i := 0; i < 10; i++ {
+		block := &types.Block{Header: &types.Header{Number: uint64(i), ExtraData: []byte{byte(i)}}}
+		block.Header.ComputeHash()
+		store.add(block)
+	}
+
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+	dispatcher.batchLimit = 20
+
+	// the responses keep the order of the batch, the invalid
+	// requests are answered with an error
+	reqs := []string{}
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, fmt.Sprintf(`{"id":%d,"method":"eth_getBlockByNumber","params":["0x%x",false]}`, i, i))
+	}
+	reqs = append(reqs, `1`, `{"id":11,"method":"eth_unknown","params":[]}`)
+
+	resp, err := dispatcher.Handle([]byte("["+strings.Join(reqs, ",")+"]"), "")
+	assert.NoError(t, err)
+
+	var resps []struct {
+		ID     int
+		Result *struct {
+			Number argUint64
+		}
+		Error *ErrorObject
+	}
+	assert.NoError(t, json.Unmarshal(resp, &resps))
+	assert.Len(t, resps, 12)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, i, resps[i].ID)
+		assert.Equal(t, argUint64(i), resps[i].Result.Number)
+	}
+	assert.Equal(t, -32600, resps[10].Error.Code)
+	assert.Equal(t, -32601, resps[11].Error.Code)
+
+	// the batches over the limit are rejected
+	reqs = append(reqs, reqs...)
+	_, err = dispatcher.Handle([]byte("["+strings.Join(reqs, ",")+"]"), "")
+	assert.Error(t, err)
+}
+
 type mockService struct {
 	msgCh chan interface{}
 }
@@ -118,7 +226,7 @@ func TestDispatcherFuncDecode(t *testing.T) {
 		_, err := s.handleReq(Request{
 			Method: "mock_" + typ,
 			Params: []byte(msg),
-		})
+		}, "")
 		assert.NoError(t, err)
 		return <-srv.msgCh
 	}
@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -51,6 +53,121 @@ func (e *Eth) GetBlockByHash(hash types.Hash, full bool) (interface{}, error) {
 	return toBlock(block), nil
 }
 
+// blockByNumber returns the block of the number with its body,
+// it is nil if the block or its body are not found
+func (e *Eth) blockByNumber(number BlockNumber) (*types.Block, error) {
+	num := uint64(number)
+	if number < 0 {
+		header, err := e.d.getBlockHeaderImpl(number)
+		if err != nil {
+			return nil, err
+		}
+		num = header.Number
+	}
+	block, ok := e.d.store.GetBlockByNumber(num, true)
+	if !ok {
+		return nil, nil
+	}
+	return withBody(block), nil
+}
+
+// blockByHash returns the block of the hash with its body,
+// it is nil if the block or its body are not found
+func (e *Eth) blockByHash(hash types.Hash) *types.Block {
+	block, ok := e.d.store.GetBlockByHash(hash, true)
+	if !ok {
+		return nil
+	}
+	return withBody(block)
+}
+
+// withBody returns nil if the body of the block is not in storage,
+// the bodies of the old blocks are pruned in the light mode
+func withBody(block *types.Block) *types.Block {
+	if len(block.Transactions) == 0 && block.Header.TxRoot != types.EmptyRootHash {
+		return nil
+	}
+	return block
+}
+
+// GetTransactionByBlockNumberAndIndex returns the txn of the index in the block of the number
+func (e *Eth) GetTransactionByBlockNumberAndIndex(number BlockNumber, index argUint64) (interface{}, error) {
+	block, err := e.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return blockTransaction(block, index), nil
+}
+
+// GetTransactionByBlockHashAndIndex returns the txn of the index in the block of the hash
+func (e *Eth) GetTransactionByBlockHashAndIndex(hash types.Hash, index argUint64) (interface{}, error) {
+	return blockTransaction(e.blockByHash(hash), index), nil
+}
+
+func blockTransaction(block *types.Block, index argUint64) interface{} {
+	if block == nil || uint64(index) >= uint64(len(block.Transactions)) {
+		return nil
+	}
+	return toBlockTransaction(block.Transactions[index], block.Header, int(index))
+}
+
+// GetBlockTransactionCountByNumber returns the number of txns of the block of the number
+func (e *Eth) GetBlockTransactionCountByNumber(number BlockNumber) (interface{}, error) {
+	block, err := e.blockByNumber(number)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return argUintPtr(uint64(len(block.Transactions))), nil
+}
+
+// GetBlockTransactionCountByHash returns the number of txns of the block of the hash
+func (e *Eth) GetBlockTransactionCountByHash(hash types.Hash) (interface{}, error) {
+	block := e.blockByHash(hash)
+	if block == nil {
+		return nil, nil
+	}
+	return argUintPtr(uint64(len(block.Transactions))), nil
+}
+
+// GetUncleByBlockNumberAndIndex returns the uncle of the index in the block of the number
+func (e *Eth) GetUncleByBlockNumberAndIndex(number BlockNumber, index argUint64) (interface{}, error) {
+	block, err := e.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return blockUncle(block, index), nil
+}
+
+// GetUncleByBlockHashAndIndex returns the uncle of the index in the block of the hash
+func (e *Eth) GetUncleByBlockHashAndIndex(hash types.Hash, index argUint64) (interface{}, error) {
+	return blockUncle(e.blockByHash(hash), index), nil
+}
+
+func blockUncle(block *types.Block, index argUint64) interface{} {
+	if block == nil || uint64(index) >= uint64(len(block.Uncles)) {
+		return nil
+	}
+	return toBlock(&types.Block{Header: block.Uncles[index]})
+}
+
+// GetUncleCountByBlockNumber returns the number of uncles of the block of the number
+func (e *Eth) GetUncleCountByBlockNumber(number BlockNumber) (interface{}, error) {
+	block, err := e.blockByNumber(number)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return argUintPtr(uint64(len(block.Uncles))), nil
+}
+
+// GetUncleCountByBlockHash returns the number of uncles of the block of the hash
+func (e *Eth) GetUncleCountByBlockHash(hash types.Hash) (interface{}, error) {
+	block := e.blockByHash(hash)
+	if block == nil {
+		return nil, nil
+	}
+	return argUintPtr(uint64(len(block.Uncles))), nil
+}
+
 // BlockNumber returns current block number
 func (e *Eth) BlockNumber() (interface{}, error) {
 	h := e.d.store.Header()
@@ -71,7 +188,7 @@ func (e *Eth) SendRawTransaction(input string) (interface{}, error) {
 	tx.ComputeHash()
 
 	if err := e.d.store.AddTx(tx); err != nil {
-		return nil, err
+		return nil, txnRejected(err)
 	}
 	return tx.Hash.String(), nil
 }
@@ -83,7 +200,7 @@ func (e *Eth) SendTransaction(arg *txnArgs) (interface{}, error) {
 		return nil, err
 	}
 	if err := e.d.store.AddTx(transaction); err != nil {
-		return nil, err
+		return nil, txnRejected(err)
 	}
 	return transaction.Hash.String(), nil
 }
@@ -100,9 +217,9 @@ func (e *Eth) GetTransactionByHash(hash types.Hash) (interface{}, error) {
 		// block receipts not found
 		return nil, nil
 	}
-	for _, txn := range block.Transactions {
+	for indx, txn := range block.Transactions {
 		if txn.Hash == hash {
-			return toTransaction(txn), nil
+			return toBlockTransaction(txn, block.Header, indx), nil
 		}
 	}
 	// txn not found (this should not happen)
@@ -148,37 +265,44 @@ func (e *Eth) GetTransactionReceipt(hash types.Hash) (interface{}, error) {
 		return nil, nil
 	}
 
-	txn := block.Transactions[indx]
-	raw := receipts[indx]
-
-	logs := make([]*Log, len(raw.Logs))
-	for indx, elem := range raw.Logs {
-		logs[indx] = &Log{
-			Address:     elem.Address,
-			Topics:      elem.Topics,
-			Data:        argBytes(elem.Data),
-			BlockHash:   block.Hash(),
-			BlockNumber: argUint64(block.Number()),
-			TxHash:      txn.Hash,
-			TxIndex:     argUint64(indx),
-			LogIndex:    argUint64(indx),
-			Removed:     false,
+	// the logs are indexed in the block
+	logIndex := 0
+	for _, raw := range receipts[:indx] {
+		logIndex += len(raw.Logs)
+	}
+	return toReceipt(block, indx, receipts[indx], logIndex), nil
+}
+
+// GetBlockReceipts returns the receipts of all the txns of a block
+func (e *Eth) GetBlockReceipts(b BlockNumberOrHash) (interface{}, error) {
+	hash := b.BlockHash
+	if hash == nil {
+		header, err := e.d.getBlockHeaderImpl(*b.BlockNumber)
+		if err != nil {
+			return nil, err
 		}
+		hash = &header.Hash
+	}
+
+	block, ok := e.d.store.GetBlockByHash(*hash, true)
+	if !ok {
+		// block not found
+		return nil, nil
+	}
+	receipts, err := e.d.store.GetReceiptsByHash(*hash)
+	if err != nil {
+		return nil, err
 	}
-	res := &receipt{
-		Root:              raw.Root,
-		CumulativeGasUsed: argUint64(raw.CumulativeGasUsed),
-		LogsBloom:         raw.LogsBloom,
-		Status:            raw.Status,
-		TxHash:            txn.Hash,
-		TxIndex:           argUint64(indx),
-		BlockHash:         block.Hash(),
-		BlockNumber:       argUint64(block.Number()),
-		GasUsed:           argUint64(raw.GasUsed),
-		ContractAddress:   raw.ContractAddress,
-		FromAddr:          txn.From,
-		ToAddr:            txn.To,
-		Logs:              logs,
+	if len(receipts) != len(block.Transactions) {
+		// receipts not written yet on the db
+		return nil, nil
+	}
+
+	res := []*receipt{}
+	logIndex := 0
+	for indx, raw := range receipts {
+		res = append(res, toReceipt(block, indx, raw, logIndex))
+		logIndex += len(raw.Logs)
 	}
 	return res, nil
 }
@@ -186,7 +310,7 @@ func (e *Eth) GetTransactionReceipt(hash types.Hash) (interface{}, error) {
 // GetStorageAt returns the contract storage at the index position
 func (e *Eth) GetStorageAt(address types.Address, index types.Hash, number BlockNumber) (interface{}, error) {
 	// Fetch the requested header
-	header, err := e.d.getBlockHeaderImpl(number)
+	header, err := e.d.getStateHeader(number)
 	if err != nil {
 		return nil, err
 	}
@@ -199,6 +323,21 @@ func (e *Eth) GetStorageAt(address types.Address, index types.Hash, number Block
 	return argBytesPtr(result), nil
 }
 
+// GetProof returns the merkle proofs of the account and of its storage
+// slots in the state of the block (EIP-1186)
+func (e *Eth) GetProof(address types.Address, slots []types.Hash, number BlockNumber) (interface{}, error) {
+	header, err := e.d.getStateHeader(number)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := e.d.store.GetProof(header.StateRoot, address, slots)
+	if err != nil {
+		return nil, err
+	}
+	return toAccountProof(address, proof), nil
+}
+
 // GasPrice returns the average gas price based on the last x blocks
 func (e *Eth) GasPrice() (interface{}, error) {
 
@@ -209,31 +348,60 @@ func (e *Eth) GasPrice() (interface{}, error) {
 }
 
 // Call executes a smart contract call using the transaction object data
-func (e *Eth) Call(arg *txnArgs, number BlockNumber) (interface{}, error) {
-	transaction, err := e.d.decodeTxn(arg)
+func (e *Eth) Call(arg *txnArgs, number BlockNumber, override *stateOverride, blockOverride *blockOverride) (interface{}, error) {
+	// Fetch the requested header
+	header, err := e.d.getStateHeader(number)
 	if err != nil {
 		return nil, err
 	}
-	// Fetch the requested header
-	header, err := e.d.getBlockHeaderImpl(number)
+	if arg.Nonce == nil && arg.From != nil {
+		arg.Nonce = argUintPtr(e.callNonce(*arg.From, header, override))
+	}
+	transaction, err := e.d.decodeTxn(arg)
 	if err != nil {
 		return nil, err
 	}
+	if blockOverride != nil {
+		header = blockOverride.apply(header)
+	}
 
 	// The return value of the execution is saved in the transition (returnValue field)
-	returnValue, failed, err := e.d.store.ApplyTxn(header, transaction)
+	returnValue, failed, err := e.d.store.ApplyTxn(header, transaction, override.toState())
 	if err != nil {
 		return nil, err
 	}
 
 	if failed {
+		if len(returnValue) != 0 {
+			return nil, revertError(returnValue)
+		}
 		return nil, fmt.Errorf("unable to execute call")
 	}
 	return argBytesPtr(returnValue), nil
 }
 
-// EstimateGas estimates the gas needed to execute a transaction
+// callNonce returns the nonce of the sender in the state the call is executed on
+func (e *Eth) callNonce(from types.Address, header *types.Header, override *stateOverride) uint64 {
+	if override != nil {
+		if account, ok := (*override)[from]; ok && account.Nonce != nil {
+			return uint64(*account.Nonce)
+		}
+	}
+	acc, err := e.d.store.GetAccount(header.StateRoot, from)
+	if err != nil {
+		// the sender is not in the state yet
+		return 0
+	}
+	return acc.Nonce
+}
+
+// EstimateGas estimates the gas needed to execute a transaction, it is the
+// lowest gas limit the transaction executes with, found with a binary search
+// between the intrinsic gas and the gas cap
 func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error) {
+	// the gas limit of the txn is the cap of the estimation
+	hasGas := arg.Gas != nil
+
 	transaction, err := e.d.decodeTxn(arg)
 	if err != nil {
 		return nil, err
@@ -247,20 +415,16 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 	}
 
 	// Fetch the requested header
-	header, err := e.d.getBlockHeaderImpl(number)
+	header, err := e.d.getStateHeader(number)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		lowEnd  = standardGas
-		highEnd uint64
-		gasCap  uint64
-	)
+	var highEnd uint64
 
 	// If the gas limit was passed in, use it as a ceiling
-	if transaction.Gas != 0 && uint64(transaction.Gas) >= standardGas {
-		highEnd = uint64(transaction.Gas)
+	if hasGas && transaction.Gas >= standardGas {
+		highEnd = transaction.Gas
 	} else {
 		// If not, use the referenced block number
 		highEnd = header.GasLimit
@@ -304,85 +468,127 @@ func (e *Eth) EstimateGas(arg *txnArgs, rawNum *BlockNumber) (interface{}, error
 		highEnd = types.GasCap.Uint64()
 	}
 
-	gasCap = highEnd
-
 	// Run the transaction with the estimated gas
-	testTransaction := func(gas uint64) (bool, error) {
+	testTransaction := func(gas uint64) ([]byte, bool, error) {
 		// Create a dummy transaction with the new gas
 		txn := transaction.Copy()
 		txn.Gas = gas
 
-		_, failed, err := e.d.store.ApplyTxn(header, txn)
-		if err != nil {
-			return failed, err
-		}
-
-		return failed, nil
+		return e.d.store.ApplyTxn(header, txn, nil)
 	}
 
-	// Start the binary search for the lowest possible gas price
-	for lowEnd <= highEnd {
-		mid := (lowEnd + highEnd) / 2
-
-		failed, err := testTransaction(mid)
-		if err != nil {
-			return 0, err
+	// The transaction has to execute with the highest gas, otherwise
+	// it fails with any gas
+	returnValue, failed, err := testTransaction(highEnd)
+	if err != nil {
+		return nil, err
+	}
+	if failed {
+		if len(returnValue) != 0 {
+			return nil, revertError(returnValue)
 		}
+		return nil, fmt.Errorf("gas required exceeds allowance (%d)", highEnd)
+	}
+
+	// Start the binary search for the lowest gas, the transaction fails
+	// with the low end and executes with the high end. The gas limit has
+	// to cover the gas before the refunds, so the search runs the txn to
+	// find it instead of using the gas used
+	lowEnd := standardGas - 1
+	for lowEnd+1 < highEnd {
+		mid := lowEnd + (highEnd-lowEnd)/2
 
-		if failed {
+		_, failed, err := testTransaction(mid)
+		// the transaction executes with the highest gas, so
+		// the errors with less gas are caused by the gas
+		if err != nil || failed {
 			// If the transaction failed => increase the gas
-			lowEnd = mid + 1
+			lowEnd = mid
 		} else {
 			// If the transaction didn't fail => lower the gas
-			highEnd = mid - 1
+			highEnd = mid
 		}
 	}
 
-	// we stopped the binary search at the last gas limit
-	// at which the txn could not be executed
-	highEnd += 1
+	return hex.EncodeUint64(highEnd), nil
+}
+
+var (
+	// revertSelector is the selector of the Error(string) of the revert reasons
+	revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
 
-	// Check the edge case if even the highest cap is not enough to complete the transaction
-	if highEnd == gasCap {
-		failed, err := testTransaction(gasCap)
+	// panicSelector is the selector of the Panic(uint256) of the failed asserts
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
 
-		if err != nil {
-			return 0, err
-		}
+// panicReasons are the reasons of the panic codes of solidity
+var panicReasons = map[uint64]string{
+	0x00: "generic panic",
+	0x01: "assert(false)",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum overflow",
+	0x22: "invalid encoded storage byte array accessed",
+	0x31: "out-of-bounds array access; popping on an empty array",
+	0x32: "out-of-bounds access of an array or bytesN",
+	0x41: "out of memory",
+	0x51: "uninitialized function",
+}
 
-		if failed {
-			return 0, fmt.Errorf("gas required exceeds allowance (%d)", gasCap)
+// revertError is the error of a reverted transaction, the data is
+// the return value and the message includes the revert reason
+func revertError(returnValue []byte) error {
+	msg := "execution reverted"
+	if reason, ok := unpackRevertReason(returnValue); ok {
+		msg += ": " + reason
+	}
+	return &ErrorObject{
+		Code:    3,
+		Message: msg,
+		Data:    hex.EncodeToHex(returnValue),
+	}
+}
+
+// unpackRevertReason decodes the reason of an abi encoded Error(string) or Panic(uint256)
+func unpackRevertReason(data []byte) (string, bool) {
+	if len(data) == 4+32 && bytes.Equal(data[:4], panicSelector) {
+		code := new(big.Int).SetBytes(data[4:])
+		if code.IsUint64() {
+			if reason, ok := panicReasons[code.Uint64()]; ok {
+				return reason, true
+			}
 		}
+		return fmt.Sprintf("unknown panic code: 0x%x", code), true
 	}
+	if len(data) < 4+64 || !bytes.Equal(data[:4], revertSelector) {
+		return "", false
+	}
+	data = data[4:]
 
-	return hex.EncodeUint64(highEnd), nil
+	offset := new(big.Int).SetBytes(data[:32])
+	if !offset.IsUint64() || offset.Uint64() > uint64(len(data)-32) {
+		return "", false
+	}
+	start := offset.Uint64() + 32
+
+	size := new(big.Int).SetBytes(data[start-32 : start])
+	if !size.IsUint64() || size.Uint64() > uint64(len(data))-start {
+		return "", false
+	}
+	return string(data[start : start+size.Uint64()]), true
 }
 
-// GetLogs returns an array of logs matching the filter options
+// GetLogs returns an array of logs matching the filter options, either in
+// the block of the hash or in the range of blocks. The queries with a
+// cursor return a page of the logs and the cursor of the next page
 func (e *Eth) GetLogs(filterOptions *LogFilter) (interface{}, error) {
-	var result []*Log
+	page := newLogsPage(filterOptions, e.d.logsLimit)
 	parseReceipts := func(header *types.Header) error {
 		receipts, err := e.d.store.GetReceiptsByHash(header.Hash)
 		if err != nil {
 			return err
 		}
-
-		for indx, receipt := range receipts {
-			for logIndx, log := range receipt.Logs {
-				if filterOptions.Match(log) {
-					result = append(result, &Log{
-						Address:     log.Address,
-						Topics:      log.Topics,
-						Data:        argBytes(log.Data),
-						BlockNumber: argUint64(header.Number),
-						BlockHash:   header.Hash,
-						TxHash:      receipt.TxHash,
-						TxIndex:     argUint64(indx),
-						LogIndex:    argUint64(logIndx),
-					})
-				}
-			}
-		}
+		page.add(header, filterOptions.filterLogs(header, receipts, false))
 		return nil
 	}
 
@@ -394,28 +600,56 @@ func (e *Eth) GetLogs(filterOptions *LogFilter) (interface{}, error) {
 		if err := parseReceipts(block.Header); err != nil {
 			return nil, err
 		}
-		return result, nil
+		return page.result()
 	}
 
 	head := e.d.store.Header().Number
 
 	resolveNum := func(num BlockNumber) uint64 {
-		if num == PendingBlockNumber || num == EarliestBlockNumber {
-			num = LatestBlockNumber
+		switch num {
+		case EarliestBlockNumber:
+			return 0
+		case LatestBlockNumber, PendingBlockNumber:
+			return head
 		}
-		if num == LatestBlockNumber {
+		if uint64(num) > head {
 			return head
 		}
 		return uint64(num)
 	}
 
+	if filterOptions.fromBlock >= 0 && uint64(filterOptions.fromBlock) > head {
+		// the range starts after the head
+		return page.result()
+	}
 	from := resolveNum(filterOptions.fromBlock)
 	to := resolveNum(filterOptions.toBlock)
 
 	if to < from {
 		return nil, fmt.Errorf("incorrect range")
 	}
-	for i := from; i < to; i++ {
+
+	if cursor := filterOptions.cursor; cursor != nil && cursor.number > from {
+		if cursor.number > to {
+			return page.result()
+		}
+		from = cursor.number
+	}
+	if limit := e.d.logsBlockRange; limit != 0 && to-from+1 > limit {
+		if !filterOptions.paginated {
+			return nil, limitExceeded("query exceeds the range of %d blocks, use a cursor to paginate it", limit)
+		}
+		// the page scans the blocks of the range, the next page starts after them
+		to = from + limit - 1
+		page.next = &logCursor{number: to + 1}
+	}
+
+	// use the bloom index to skip the blocks that cannot include logs for the filter
+	candidates, err := e.d.store.FilterBlooms(from, to, filterOptions.bloomFilter())
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range candidates {
 		header, ok := e.d.store.GetHeaderByNumber(i)
 		if !ok {
 			break
@@ -427,13 +661,16 @@ func (e *Eth) GetLogs(filterOptions *LogFilter) (interface{}, error) {
 		if err := parseReceipts(header); err != nil {
 			return nil, err
 		}
+		if page.full {
+			break
+		}
 	}
-	return result, nil
+	return page.result()
 }
 
 // GetBalance returns the account's balance at the referenced block
 func (e *Eth) GetBalance(address types.Address, number BlockNumber) (interface{}, error) {
-	header, err := e.d.getBlockHeaderImpl(number)
+	header, err := e.d.getStateHeader(number)
 	if err != nil {
 		return nil, err
 	}
@@ -455,7 +692,7 @@ func (e *Eth) GetTransactionCount(address types.Address, number BlockNumber) (in
 
 // GetCode returns account code at given block number
 func (e *Eth) GetCode(address types.Address, number BlockNumber) (interface{}, error) {
-	header, err := e.d.getBlockHeaderImpl(number)
+	header, err := e.d.getStateHeader(number)
 	if err != nil {
 		return nil, err
 	}
@@ -473,18 +710,48 @@ func (e *Eth) GetCode(address types.Address, number BlockNumber) (interface{}, e
 }
 
 // NewFilter creates a filter object, based on filter options, to notify when the state changes (logs).
-func (e *Eth) NewFilter(filter *LogFilter) (interface{}, error) {
-	return e.d.filterManager.NewLogFilter(filter, nil), nil
+func (e *Eth) NewFilter(c caller, filter *LogFilter) (interface{}, error) {
+	id, err := e.d.filterManager.NewLogFilter(filter, c.client, nil)
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
 }
 
 // NewBlockFilter creates a filter in the node, to notify when a new block arrives
-func (e *Eth) NewBlockFilter() (interface{}, error) {
-	return e.d.filterManager.NewBlockFilter(nil), nil
+func (e *Eth) NewBlockFilter(c caller) (interface{}, error) {
+	id, err := e.d.filterManager.NewBlockFilter(c.client, nil)
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// NewPendingTransactionFilter creates a filter in the node, to notify when new transactions arrive in the pool
+func (e *Eth) NewPendingTransactionFilter(c caller) (interface{}, error) {
+	id, err := e.d.filterManager.NewPendingTxnsFilter(false, c.client, nil)
+	if err != nil {
+		return nil, err
+	}
+	return id, nil
 }
 
 // GetFilterChanges is a polling method for a filter, which returns an array of logs which occurred since last poll.
 func (e *Eth) GetFilterChanges(id string) (interface{}, error) {
-	return e.d.filterManager.GetFilterChanges(id)
+	res, err := e.d.filterManager.GetFilterChanges(id)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), nil
+}
+
+// GetFilterLogs returns all the logs matching the log filter with given ID
+func (e *Eth) GetFilterLogs(id string) (interface{}, error) {
+	filter, err := e.d.filterManager.GetLogFilter(id)
+	if err != nil {
+		return nil, err
+	}
+	return e.GetLogs(filter)
 }
 
 // UninstallFilter uninstalls a filter with given ID
@@ -2,6 +2,7 @@ package jsonrpc
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"testing"
@@ -138,6 +139,72 @@ func (m *mockBlockStore2) Header() *types.Header {
 	return m.blocks[len(m.blocks)-1].Header
 }
 
+func TestEth_Block_Accessors(t *testing.T) {
+	txn0 := &types.Transaction{Nonce: 0, GasPrice: big.NewInt(1), Value: big.NewInt(0), Hash: types.Hash{0x1}}
+	txn1 := &types.Transaction{Nonce: 1, GasPrice: big.NewInt(1), Value: big.NewInt(0), Hash: types.Hash{0x2}}
+	uncle := &types.Header{Number: 1, Hash: types.Hash{0x3}}
+
+	store := &mockBlockStore2{}
+	store.add(&types.Block{
+		Header: &types.Header{Number: 0, Hash: types.Hash{0x10}, TxRoot: types.EmptyRootHash},
+	}, &types.Block{
+		Header:       &types.Header{Number: 1, Hash: types.Hash{0x11}, TxRoot: types.Hash{0x1}},
+		Transactions: []*types.Transaction{txn0, txn1},
+	}, &types.Block{
+		Header: &types.Header{Number: 2, Hash: types.Hash{0x12}, TxRoot: types.EmptyRootHash},
+		Uncles: []*types.Header{uncle},
+	}, &types.Block{
+		// the body of the block is pruned
+		Header: &types.Header{Number: 3, Hash: types.Hash{0x13}, TxRoot: types.Hash{0x1}},
+	})
+
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+	call := func(method string, params string) interface{} {
+		resp, err := dispatcher.Handle([]byte(`{"id":1,"method":"`+method+`","params":`+params+`}`), "")
+		assert.NoError(t, err)
+		var res interface{}
+		assert.NoError(t, expectJSONResult(resp, &res))
+		return res
+	}
+
+	txn := call("eth_getTransactionByBlockNumberAndIndex", `["0x1", "0x1"]`).(map[string]interface{})
+	assert.Equal(t, txn1.Hash.String(), txn["hash"])
+	assert.Equal(t, types.Hash{0x11}.String(), txn["blockHash"])
+	assert.Equal(t, "0x1", txn["blockNumber"])
+	assert.Equal(t, "0x1", txn["transactionIndex"])
+
+	txn = call("eth_getTransactionByBlockHashAndIndex", `["`+types.Hash{0x11}.String()+`", "0x0"]`).(map[string]interface{})
+	assert.Equal(t, txn0.Hash.String(), txn["hash"])
+	assert.Equal(t, "0x0", txn["transactionIndex"])
+
+	// the txns out of the block and the unknown blocks are null
+	assert.Nil(t, call("eth_getTransactionByBlockNumberAndIndex", `["0x1", "0x2"]`))
+	assert.Nil(t, call("eth_getTransactionByBlockNumberAndIndex", `["0x9", "0x0"]`))
+	assert.Nil(t, call("eth_getTransactionByBlockHashAndIndex", `["`+types.Hash{0x99}.String()+`", "0x0"]`))
+
+	assert.Equal(t, "0x2", call("eth_getBlockTransactionCountByNumber", `["0x1"]`))
+	assert.Equal(t, "0x0", call("eth_getBlockTransactionCountByNumber", `["0x0"]`))
+	assert.Equal(t, "0x0", call("eth_getBlockTransactionCountByHash", `["`+types.Hash{0x12}.String()+`"]`))
+	assert.Nil(t, call("eth_getBlockTransactionCountByHash", `["`+types.Hash{0x99}.String()+`"]`))
+
+	// the blocks without their body are null
+	assert.Nil(t, call("eth_getBlockTransactionCountByNumber", `["0x3"]`))
+	assert.Nil(t, call("eth_getUncleCountByBlockNumber", `["latest"]`))
+
+	assert.Equal(t, "0x1", call("eth_getUncleCountByBlockNumber", `["0x2"]`))
+	assert.Equal(t, "0x0", call("eth_getUncleCountByBlockHash", `["`+types.Hash{0x11}.String()+`"]`))
+
+	res := call("eth_getUncleByBlockNumberAndIndex", `["0x2", "0x0"]`).(map[string]interface{})
+	assert.Equal(t, uncle.Hash.String(), res["hash"])
+	res = call("eth_getUncleByBlockHashAndIndex", `["`+types.Hash{0x12}.String()+`", "0x0"]`).(map[string]interface{})
+	assert.Equal(t, "0x1", res["number"])
+	assert.Nil(t, call("eth_getUncleByBlockHashAndIndex", `["`+types.Hash{0x12}.String()+`", "0x1"]`))
+
+	// the blocks include the hashes of their uncles
+	res = call("eth_getBlockByNumber", `["0x2", false]`).(map[string]interface{})
+	assert.Equal(t, []interface{}{uncle.Hash.String()}, res["uncles"])
+}
+
 func TestEth_Block_GetBlockByNumber(t *testing.T) {
 	store := &mockBlockStore2{}
 	for i := 0; i < 10; i++ {
@@ -203,106 +270,99 @@ func TestEth_Block_BlockNumber(t *testing.T) {
 	assert.Equal(t, argUintPtr(10), num)
 }
 
+type mockLogStore struct {
+	mockBlockStore2
+	receipts map[types.Hash][]*types.Receipt
+}
+
+func (m *mockLogStore) GetReceiptsByHash(hash types.Hash) ([]*types.Receipt, error) {
+	return m.receipts[hash], nil
+}
+
 func TestEth_Block_GetLogs(t *testing.T) {
+	topic0, topic1 := types.Hash{0x1}, types.Hash{0x2}
 
-	/*
-		// Topics we're searching for
-		var topics = [][]types.Hash{
-			{types.StringToHash("topic 4")},
-			{types.StringToHash("topic 5")},
-			{types.StringToHash("topic 6")},
+	store := &mockLogStore{
+		receipts: map[types.Hash][]*types.Receipt{},
+	}
+	for i := 0; i < 5; i++ {
+		block := &types.Block{Header: &types.Header{Number: uint64(i), ExtraData: []byte{byte(i)}}}
+		block.Header.ComputeHash()
+		store.add(block)
+
+		store.receipts[block.Hash()] = []*types.Receipt{
+			{
+				TxHash: types.Hash{byte(i), 0x1},
+				Logs: []*types.Log{
+					{Address: addr0, Topics: []types.Hash{topic0}},
+				},
+			},
+			{
+				TxHash: types.Hash{byte(i), 0x2},
+				Logs: []*types.Log{
+					{Address: addr1, Topics: []types.Hash{topic0, topic1}},
+					{Address: addr0, Topics: []types.Hash{topic1}},
+				},
+			},
 		}
+	}
 
-		testTable := []struct {
-			name          string
-			filterOptions *LogFilter
-			shouldFail    bool
-		}{
-			{"Found matching logs",
-				&LogFilter{
-					fromBlock: 1,
-					toBlock:   4,
-					Topics:    topics,
-				},
-				false},
-			{"Invalid block range", &LogFilter{
-				fromBlock: 10,
-				toBlock:   5,
-				Topics:    topics,
-			}, true},
-			{"Reference blocks not found", &LogFilter{
-				fromBlock: 10,
-				toBlock:   20,
-				Topics:    topics,
-			}, true},
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	getLogs := func(filter string) ([]*Log, error) {
+		f := &LogFilter{}
+		if err := f.UnmarshalJSON([]byte(filter)); err != nil {
+			return nil, err
+		}
+		res, err := dispatcher.endpoints.Eth.GetLogs(f)
+		if err != nil {
+			return nil, err
 		}
+		return res.([]*Log), nil
+	}
 
-		// Setup //
-		store := newMockBlockStore()
+	// the genesis is skipped
+	logs, err := getLogs(`{"fromBlock": "earliest"}`)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 12)
 
-		dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+	logs, err = getLogs(`{"fromBlock": "0x2", "toBlock": "0x3", "address": ["` + addr0.String() + `"]}`)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 4)
+	assert.Equal(t, argUint64(2), logs[0].BlockNumber)
 
-		store.getHeaderByNumberCallback = func(blockNumber uint64) (*types.Header, bool) {
-			return &types.Header{
-				Number:       blockNumber,
-				ReceiptsRoot: types.StringToHash(strconv.FormatUint(blockNumber, 10)),
-			}, true
-		}
+	// the log index is the position of the log in the block
+	assert.Equal(t, argUint64(2), logs[1].LogIndex)
+	assert.Equal(t, argUint64(1), logs[1].TxIndex)
 
-		store.getReceiptsByHashCallback = func(hash types.Hash) ([]*types.Receipt, error) {
-
-			switch hash {
-			case types.StringToHash(strconv.FormatUint(1, 10)):
-				return store.generateReceipts(
-					generateReceiptsParams{
-						numReceipts: 1,
-						numTopics:   1,
-						numLogs:     2,
-					},
-				), nil
-			case types.StringToHash(strconv.FormatUint(2, 10)):
-				return store.generateReceipts(
-					generateReceiptsParams{
-						numReceipts: 2,
-						numTopics:   5,
-						numLogs:     1,
-					},
-				), nil
-			case types.StringToHash(strconv.FormatUint(3, 10)):
-				return store.generateReceipts(
-					generateReceiptsParams{
-						numReceipts: 3,
-						numTopics:   10,
-						numLogs:     5,
-					},
-				), nil
-			}
+	// nested topics match any of the topics in the position
+	logs, err = getLogs(`{"fromBlock": "0x1", "topics": [["` + topic0.String() + `", "` + topic1.String() + `"], "` + topic1.String() + `"]}`)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 4)
+	for _, log := range logs {
+		assert.Equal(t, addr1, log.Address)
+	}
 
-			return nil, nil
-		}
+	logs, err = getLogs(`{"fromBlock": "0x1", "topics": [null, "` + topic1.String() + `"]}`)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 4)
 
-		for index, testCase := range testTable {
+	hash := store.blocks[3].Hash()
+	logs, err = getLogs(`{"blockHash": "` + hash.String() + `", "topics": ["` + topic1.String() + `"]}`)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, hash, logs[0].BlockHash)
 
-			if index == 2 {
-				store.getHeaderByNumberCallback = func(blockNumber uint64) (*types.Header, bool) {
-					return nil, false
-				}
-			}
+	_, err = getLogs(`{"blockHash": "` + hash.String() + `", "fromBlock": "0x1"}`)
+	assert.Error(t, err)
 
-			t.Run(testCase.name, func(t *testing.T) {
-				foundLogs, logError := dispatcher.endpoints.Eth.GetLogs(
-					testCase.filterOptions,
-				)
-
-				if logError != nil && !testCase.shouldFail {
-					// If there is an error, and the test shouldn't fail
-					t.Fatalf("Error: %v", logError)
-				} else if !testCase.shouldFail {
-					assert.Lenf(t, foundLogs, 17, "Invalid number of logs found")
-				}
-			})
-		}
-	*/
+	_, err = getLogs(`{"fromBlock": "0x3", "toBlock": "0x2"}`)
+	assert.Error(t, err)
+
+	// the range after the head has no logs
+	logs, err = getLogs(`{"fromBlock": "0x10", "toBlock": "0x20"}`)
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
 }
 
 var (
@@ -326,6 +386,60 @@ func TestEth_State_GetBalance(t *testing.T) {
 	assert.Error(t, err)
 }
 
+type mockStoreHistory struct {
+	mockBlockStore2
+	history uint64
+}
+
+func (m *mockStoreHistory) StateHistory() uint64 {
+	return m.history
+}
+
+func (m *mockStoreHistory) GetAccount(root types.Hash, addr types.Address) (*state.Account, error) {
+	// the balance of the account is the number of the block of the state
+	return &state.Account{Balance: big.NewInt(int64(root[0]) - 1)}, nil
+}
+
+func TestEth_State_History(t *testing.T) {
+	store := &mockStoreHistory{}
+	for i := 0; i < 10; i++ {
+		store.add(&types.Block{
+			Header: &types.Header{
+				Number:    uint64(i),
+				StateRoot: types.Hash{byte(i + 1)},
+			},
+		})
+	}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	// the state of any block is available in archive mode
+	for _, i := range []int64{0, 2, 9} {
+		balance, err := dispatcher.endpoints.Eth.GetBalance(addr0, BlockNumber(i))
+		assert.NoError(t, err)
+		assert.Equal(t, argBigPtr(big.NewInt(i)), balance)
+	}
+
+	// the other modes keep the state of the last blocks
+	store.history = 5
+
+	balance, err := dispatcher.endpoints.Eth.GetBalance(addr0, BlockNumber(4))
+	assert.NoError(t, err)
+	assert.Equal(t, argBigPtr(big.NewInt(4)), balance)
+
+	_, err = dispatcher.endpoints.Eth.GetBalance(addr0, BlockNumber(3))
+	assert.Equal(t, &ErrorObject{
+		Code:    -32000,
+		Message: "state of block 3 is not available, the node keeps the state of the last 5 blocks unless it runs in archive mode",
+	}, err)
+
+	_, err = dispatcher.endpoints.Eth.GetTransactionCount(addr0, BlockNumber(3))
+	assert.Error(t, err)
+	_, err = dispatcher.endpoints.Eth.GetCode(addr0, BlockNumber(3))
+	assert.Error(t, err)
+	_, err = dispatcher.endpoints.Eth.GetStorageAt(addr0, types.Hash{}, BlockNumber(3))
+	assert.Error(t, err)
+}
+
 func TestEth_State_GetTransactionCount(t *testing.T) {
 	store := &mockAccountStore{}
 
@@ -379,9 +493,13 @@ type mockStoreTxn struct {
 	nullBlockchainInterface
 
 	txn *types.Transaction
+	err error
 }
 
 func (m *mockStoreTxn) AddTx(tx *types.Transaction) error {
+	if m.err != nil {
+		return m.err
+	}
 	m.txn = tx
 	return nil
 }
@@ -411,6 +529,72 @@ func TestEth_TxnPool_SendRawTransaction(t *testing.T) {
 	}
 }
 
+func TestEth_TxnPool_SendRawTransaction_Rejected(t *testing.T) {
+	store := &mockStoreTxn{err: fmt.Errorf("intrinsic gas too low: 0 < 21000")}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	txn := &types.Transaction{
+		From: addr0,
+		V:    1,
+	}
+	req := fmt.Sprintf(`{"method": "eth_sendRawTransaction", "params": ["%s"]}`, hex.EncodeToHex(txn.MarshalRLP()))
+
+	// the reason of the pool is returned instead of an internal error
+	_, err := dispatcher.Handle([]byte(req), "")
+	assert.Equal(t, &ErrorObject{Code: -32000, Message: "intrinsic gas too low: 0 < 21000"}, err)
+}
+
+type mockStoreTxnBatch struct {
+	nullBlockchainInterface
+
+	batches [][]*types.Transaction
+}
+
+func (m *mockStoreTxnBatch) AddTxs(txns []*types.Transaction) []error {
+	m.batches = append(m.batches, txns)
+
+	errs := make([]error, len(txns))
+	for i, txn := range txns {
+		if txn.Nonce != 0 {
+			errs[i] = fmt.Errorf("nonce too low")
+		}
+	}
+	return errs
+}
+
+func TestEth_TxnPool_SendRawTransaction_Batch(t *testing.T) {
+	store := &mockStoreTxnBatch{}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	txn0 := &types.Transaction{From: addr0, V: 1}
+	txn0.ComputeHash()
+	txn1 := &types.Transaction{From: addr0, Nonce: 1, V: 1}
+
+	req := fmt.Sprintf(`[
+		{"id": 1, "method": "eth_sendRawTransaction", "params": ["%s"]},
+		{"id": 2, "method": "eth_chainId", "params": []},
+		{"id": 3, "method": "eth_sendRawTransaction", "params": ["%s"]},
+		{"id": 4, "method": "eth_sendRawTransaction", "params": []}
+	]`, hex.EncodeToHex(txn0.MarshalRLP()), hex.EncodeToHex(txn1.MarshalRLP()))
+
+	data, err := dispatcher.Handle([]byte(req), "")
+	assert.NoError(t, err)
+
+	var resps []*Response
+	assert.NoError(t, json.Unmarshal(data, &resps))
+	assert.Len(t, resps, 4)
+
+	// the raw txns are added to the pool at once
+	assert.Len(t, store.batches, 1)
+	assert.Len(t, store.batches[0], 2)
+
+	assert.Equal(t, `"`+txn0.Hash.String()+`"`, string(resps[0].Result))
+	assert.Equal(t, 2, resps[1].ID)
+	assert.Nil(t, resps[1].Error)
+	assert.Equal(t, &ErrorObject{Code: -32000, Message: "nonce too low"}, resps[2].Error)
+	assert.Equal(t, -32602, resps[3].Error.Code)
+}
+
 func TestEth_TxnPool_SendTransaction(t *testing.T) {
 	store := &mockStoreTxn{}
 	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
@@ -425,3 +609,444 @@ func TestEth_TxnPool_SendTransaction(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEqual(t, store.txn.Hash, types.ZeroHash)
 }
+
+type mockStoreCall struct {
+	mockBlockStore2
+
+	header   *types.Header
+	txn      *types.Transaction
+	override state.StateOverride
+	revert   []byte
+}
+
+func (m *mockStoreCall) GetAccount(root types.Hash, addr types.Address) (*state.Account, error) {
+	if addr != addr0 {
+		return nil, fmt.Errorf("account not found")
+	}
+	return &state.Account{Nonce: 5}, nil
+}
+
+func (m *mockStoreCall) ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
+	m.header = header
+	m.txn = txn
+	m.override = override
+	if m.revert != nil {
+		return m.revert, true, nil
+	}
+	return []byte{0x1}, false, nil
+}
+
+func TestEth_Call_Override(t *testing.T) {
+	store := &mockStoreCall{}
+	for i := 0; i < 3; i++ {
+		store.add(&types.Block{
+			Header: &types.Header{
+				Number: uint64(i),
+			},
+		})
+	}
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	call := func(params string) (string, error) {
+		resp, err := dispatcher.Handle([]byte(`{"id":1,"method":"eth_call","params":`+params+`}`), "")
+		if err != nil {
+			return "", err
+		}
+		var res string
+		assert.NoError(t, expectJSONResult(resp, &res))
+		return res, nil
+	}
+
+	// the call is pinned to the block and the nonce is the one of the sender
+	res, err := call(`[{"from":"` + addr0.String() + `","to":"` + addr1.String() + `","gasPrice":"0x1"}, "0x1"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x01", res)
+	assert.Equal(t, uint64(1), store.header.Number)
+	assert.Equal(t, uint64(5), store.txn.Nonce)
+	assert.Nil(t, store.override)
+
+	// the accounts and the header are replaced
+	res, err = call(`[{"from":"` + addr1.String() + `","to":"` + addr0.String() + `","gasPrice":"0x1"}, "0x0", {
+		"` + addr1.String() + `": {"nonce": "0x2", "balance": "0x10", "code": "0x6001", "stateDiff": {"` + hash1.String() + `": "` + hash2.String() + `"}},
+		"` + addr0.String() + `": {"state": {}}
+	}, {"number": "0x64", "time": "0x10", "coinbase": "` + addr2.String() + `"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x01", res)
+	assert.Equal(t, uint64(2), store.txn.Nonce)
+
+	assert.Equal(t, uint64(100), store.header.Number)
+	assert.Equal(t, uint64(16), store.header.Timestamp)
+	assert.Equal(t, addr2, store.header.Miner)
+
+	// the block of the store is not modified
+	header, _ := store.GetHeaderByNumber(0)
+	assert.Equal(t, uint64(0), header.Number)
+
+	nonce := uint64(2)
+	assert.Equal(t, state.StateOverride{
+		addr1: {
+			Nonce:     &nonce,
+			Balance:   big.NewInt(16),
+			Code:      []byte{0x60, 0x01},
+			StateDiff: map[types.Hash]types.Hash{hash1: hash2},
+		},
+		addr0: {
+			State: map[types.Hash]types.Hash{},
+		},
+	}, store.override)
+
+	// the senders not in the state start with nonce zero
+	_, err = call(`[{"from":"` + addr2.String() + `","to":"` + addr0.String() + `","gasPrice":"0x1"}, "latest"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), store.txn.Nonce)
+}
+
+type mockStoreEstimate struct {
+	nullBlockchainInterface
+
+	// gas is the lowest gas the txn executes with
+	gas    uint64
+	revert []byte
+}
+
+func (m *mockStoreEstimate) Header() *types.Header {
+	return &types.Header{GasLimit: 5000000}
+}
+
+func (m *mockStoreEstimate) GetAccount(root types.Hash, addr types.Address) (*state.Account, error) {
+	return &state.Account{Balance: big.NewInt(1000000)}, nil
+}
+
+func (m *mockStoreEstimate) ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
+	if txn.Gas < 21000 {
+		return nil, false, fmt.Errorf("out of gas")
+	}
+	if m.revert != nil {
+		return m.revert, true, nil
+	}
+	return nil, txn.Gas < m.gas, nil
+}
+
+func TestEth_EstimateGas(t *testing.T) {
+	estimate := func(store *mockStoreEstimate, gas *argUint64) (interface{}, error) {
+		dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+		return dispatcher.endpoints.Eth.EstimateGas(&txnArgs{
+			From:     &addr0,
+			To:       &addr1,
+			Gas:      gas,
+			GasPrice: argBytesPtr([]byte{0x1}),
+			Nonce:    argUintPtr(0),
+		}, nil)
+	}
+
+	// the lowest gas is found up to the balance of the sender
+	for _, gas := range []uint64{21000, 21001, 53123, 999999, 1000000} {
+		res, err := estimate(&mockStoreEstimate{gas: gas}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeUint64(gas), res)
+	}
+
+	// the gas of the txn is the cap
+	_, err := estimate(&mockStoreEstimate{gas: 60000}, argUintPtr(50000))
+	assert.EqualError(t, err, "gas required exceeds allowance (50000)")
+
+	// the balance of the sender is the cap
+	_, err = estimate(&mockStoreEstimate{gas: 1000001}, argUintPtr(2000000))
+	assert.EqualError(t, err, "gas required exceeds allowance (1000000)")
+
+	// the revert reason is returned
+	reason := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6661696c00000000000000000000000000000000000000000000000000000000"
+	revert, err := hex.DecodeHex(reason)
+	assert.NoError(t, err)
+
+	_, err = estimate(&mockStoreEstimate{revert: revert}, nil)
+	assert.Equal(t, &ErrorObject{Code: 3, Message: "execution reverted: fail", Data: reason}, err)
+
+	// the revert data is returned even if it is not a reason
+	_, err = estimate(&mockStoreEstimate{revert: []byte{0x1}}, nil)
+	assert.Equal(t, &ErrorObject{Code: 3, Message: "execution reverted", Data: "0x01"}, err)
+}
+
+type revertedTxnError struct {
+	returnValue []byte
+}
+
+func (e *revertedTxnError) Error() string {
+	return "execution reverted"
+}
+
+func (e *revertedTxnError) ReturnValue() []byte {
+	return e.returnValue
+}
+
+func TestEth_RevertReason(t *testing.T) {
+	cases := []struct {
+		data   string
+		reason string
+	}{
+		{
+			"0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000001",
+			"execution reverted: assert(false)",
+		},
+		{
+			"0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000011",
+			"execution reverted: arithmetic underflow or overflow",
+		},
+		{
+			"0x4e487b71" +
+				"00000000000000000000000000000000000000000000000000000000000000ff",
+			"execution reverted: unknown panic code: 0xff",
+		},
+		{
+			// the size of the reason is out of the data
+			"0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"6661696c00000000000000000000000000000000000000000000000000000000",
+			"execution reverted",
+		},
+	}
+	for _, c := range cases {
+		data, err := hex.DecodeHex(c.data)
+		assert.NoError(t, err)
+		assert.Equal(t, &ErrorObject{Code: 3, Message: c.reason, Data: c.data}, revertError(data))
+	}
+
+	reason := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6661696c00000000000000000000000000000000000000000000000000000000"
+	revert, err := hex.DecodeHex(reason)
+	assert.NoError(t, err)
+	expected := &ErrorObject{Code: 3, Message: "execution reverted: fail", Data: reason}
+
+	// the calls return the revert reason
+	store := &mockStoreCall{revert: revert}
+	store.add(&types.Block{Header: &types.Header{}})
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	_, err = dispatcher.Handle([]byte(`{"id":1,"method":"eth_call","params":[{"from":"`+addr0.String()+`","to":"`+addr1.String()+`","gasPrice":"0x1"}, "latest"]}`), "")
+	assert.Equal(t, expected, err)
+
+	// and the txns that revert in the simulation of the pool
+	assert.Equal(t, expected, txnRejected(&revertedTxnError{returnValue: revert}))
+	assert.Equal(t, &ErrorObject{Code: -32000, Message: "execution reverted"}, txnRejected(&revertedTxnError{}))
+}
+
+type mockStoreProof struct {
+	mockBlockStore2
+
+	root  types.Hash
+	slots []types.Hash
+}
+
+func (m *mockStoreProof) GetProof(root types.Hash, addr types.Address, slots []types.Hash) (*state.AccountProof, error) {
+	m.root = root
+	m.slots = slots
+
+	proof := &state.AccountProof{
+		Proof: [][]byte{{0x1}, {0x2}},
+		StorageProof: []*state.StorageProof{
+			{Key: hash1, Value: []byte{0x1, 0x0}, Proof: [][]byte{{0x3}}},
+		},
+	}
+	if addr == addr0 {
+		proof.Account = &state.Account{
+			Nonce:    2,
+			Balance:  big.NewInt(10),
+			Root:     hash2,
+			CodeHash: hash1.Bytes(),
+		}
+	}
+	return proof, nil
+}
+
+func TestEth_GetProof(t *testing.T) {
+	store := &mockStoreProof{}
+	store.add(&types.Block{
+		Header: &types.Header{
+			StateRoot: hash1,
+		},
+	})
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	getProof := func(addr types.Address) map[string]interface{} {
+		resp, err := dispatcher.Handle([]byte(`{"id":1,"method":"eth_getProof","params":["`+addr.String()+`", ["`+hash1.String()+`"], "latest"]}`), "")
+		assert.NoError(t, err)
+
+		var res map[string]interface{}
+		assert.NoError(t, expectJSONResult(resp, &res))
+		return res
+	}
+
+	res := getProof(addr0)
+	assert.Equal(t, hash1, store.root)
+	assert.Equal(t, []types.Hash{hash1}, store.slots)
+
+	assert.Equal(t, map[string]interface{}{
+		"address":      addr0.String(),
+		"accountProof": []interface{}{"0x01", "0x02"},
+		"balance":      "0xa",
+		"codeHash":     hash1.String(),
+		"nonce":        "0x2",
+		"storageHash":  hash2.String(),
+		"storageProof": []interface{}{
+			map[string]interface{}{
+				"key":   hash1.String(),
+				"value": "0x100",
+				"proof": []interface{}{"0x03"},
+			},
+		},
+	}, res)
+
+	// the account does not exist
+	res = getProof(addr1)
+	assert.Equal(t, "0x0", res["balance"])
+	assert.Equal(t, "0x0", res["nonce"])
+	assert.Equal(t, types.EmptyRootHash.String(), res["storageHash"])
+	assert.Equal(t, "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", res["codeHash"])
+}
+
+func TestEth_GetLogs_Limits(t *testing.T) {
+	store := &mockLogStore{
+		receipts: map[types.Hash][]*types.Receipt{},
+	}
+	for i := 0; i < 6; i++ {
+		block := &types.Block{Header: &types.Header{Number: uint64(i), ExtraData: []byte{byte(i)}}}
+		block.Header.ComputeHash()
+		store.add(block)
+
+		store.receipts[block.Hash()] = []*types.Receipt{
+			{Logs: []*types.Log{{Address: addr0}, {Address: addr1}}},
+			{Logs: []*types.Log{{Address: addr0}}},
+		}
+	}
+
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+	dispatcher.logsBlockRange = 3
+	dispatcher.logsLimit = 4
+
+	getLogs := func(filter string) (interface{}, error) {
+		f := &LogFilter{}
+		if err := f.UnmarshalJSON([]byte(filter)); err != nil {
+			return nil, err
+		}
+		return dispatcher.endpoints.Eth.GetLogs(f)
+	}
+
+	// the queries over the limits fail without a cursor
+	_, err := getLogs(`{"fromBlock": "0x1", "toBlock": "0x4"}`)
+	assert.Equal(t, -32005, err.(*ErrorObject).Code)
+
+	_, err = getLogs(`{"fromBlock": "0x1", "toBlock": "0x2", "address": "` + addr0.String() + `"}`)
+	assert.NoError(t, err)
+
+	_, err = getLogs(`{"fromBlock": "0x1", "toBlock": "0x2"}`)
+	assert.Equal(t, -32005, err.(*ErrorObject).Code)
+
+	_, err = getLogs(`{"cursor": "0x12"}`)
+	assert.Error(t, err)
+
+	// the pages of the query, the limits apply to each page
+	logs := []*Log{}
+	cursor := ""
+	pages := 0
+	for {
+		res, err := getLogs(`{"fromBlock": "earliest", "cursor": "` + cursor + `"}`)
+		assert.NoError(t, err)
+
+		page := res.(*logsPageResult)
+		assert.True(t, len(page.Logs) <= 4)
+		logs = append(logs, page.Logs...)
+
+		pages++
+		if page.Cursor == "" {
+			break
+		}
+		cursor = page.Cursor
+	}
+	assert.Equal(t, 4, pages)
+	assert.Len(t, logs, 15)
+	for i, log := range logs {
+		// every log is returned once and in order
+		assert.Equal(t, argUint64(1+i/3), log.BlockNumber)
+		assert.Equal(t, argUint64(i%3), log.LogIndex)
+	}
+}
+
+type mockStoreReceipts struct {
+	mockLogStore
+}
+
+func (m *mockStoreReceipts) ReadTxLookup(hash types.Hash) (types.Hash, bool) {
+	for _, b := range m.blocks {
+		for _, txn := range b.Transactions {
+			if txn.Hash == hash {
+				return b.Hash(), true
+			}
+		}
+	}
+	return types.Hash{}, false
+}
+
+func TestEth_GetBlockReceipts(t *testing.T) {
+	store := &mockStoreReceipts{}
+	store.receipts = map[types.Hash][]*types.Receipt{}
+
+	block := &types.Block{
+		Header: &types.Header{Number: 1},
+		Transactions: []*types.Transaction{
+			{Nonce: 0, From: addr0},
+			{Nonce: 1, From: addr0, To: &addr1},
+		},
+	}
+	block.Header.ComputeHash()
+	for _, txn := range block.Transactions {
+		txn.ComputeHash()
+	}
+	store.add(block)
+
+	store.receipts[block.Hash()] = []*types.Receipt{
+		{GasUsed: 1, Logs: []*types.Log{{Address: addr1}}},
+		{GasUsed: 2, Logs: []*types.Log{{Address: addr1}, {Address: addr2}}},
+	}
+
+	dispatcher := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	for _, param := range []string{`"latest"`, `"0x1"`, `"` + block.Hash().String() + `"`} {
+		resp, err := dispatcher.Handle([]byte(`{"id":1,"method":"eth_getBlockReceipts","params":[`+param+`]}`), "")
+		assert.NoError(t, err, param)
+
+		var res []*receipt
+		assert.NoError(t, expectJSONResult(resp, &res))
+		assert.Len(t, res, 2)
+
+		assert.Equal(t, block.Transactions[1].Hash, res[1].TxHash)
+		assert.Equal(t, argUint64(1), res[1].TxIndex)
+		assert.Equal(t, argUint64(2), res[1].GasUsed)
+
+		// the logs are indexed in the block
+		assert.Equal(t, argUint64(0), res[0].Logs[0].LogIndex)
+		assert.Equal(t, argUint64(1), res[1].Logs[0].LogIndex)
+		assert.Equal(t, argUint64(2), res[1].Logs[1].LogIndex)
+		assert.Equal(t, argUint64(1), res[1].Logs[1].TxIndex)
+	}
+
+	// the receipt of a txn is the same as the one of the block
+	res, err := dispatcher.endpoints.Eth.GetTransactionReceipt(block.Transactions[1].Hash)
+	assert.NoError(t, err)
+	latest := LatestBlockNumber
+	blockRes, err := dispatcher.endpoints.Eth.GetBlockReceipts(BlockNumberOrHash{BlockNumber: &latest})
+	assert.NoError(t, err)
+	assert.Equal(t, blockRes.([]*receipt)[1], res)
+
+	// unknown block
+	res, err = dispatcher.endpoints.Eth.GetBlockReceipts(BlockNumberOrHash{BlockHash: &hash1})
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
@@ -4,7 +4,6 @@ import (
 	"container/heap"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -26,27 +25,49 @@ type Filter struct {
 	// log filter
 	logFilter *LogFilter
 
-	// index of the filter in the timer array
+	// pending txns filter, the websocket ones send the full
+	// txns if fullTxns is set
+	pendingTxns bool
+	fullTxns    bool
+	txnHashes   []types.Hash
+
+	// index of the filter in the timer array, the websocket
+	// filters do not timeout and are not in the array
 	index int
 
-	// next time to timeout
+	// next time to timeout, it is reset when the filter is polled
 	timestamp time.Time
 
 	// websocket connection
 	ws wsConn
+
+	// client is the ip of the client that installed the filter
+	client string
 }
 
 func (f *Filter) getFilterUpdates() (string, error) {
+	if f.isPendingTxnsFilter() {
+		res, err := json.Marshal(f.txnHashes)
+		if err != nil {
+			return "", err
+		}
+		f.txnHashes = []types.Hash{}
+		return string(res), nil
+	}
 	if f.isBlockFilter() {
 		// block filter
 		headers, newHead := f.block.getUpdates()
 		f.block = newHead
 
-		updates := []string{}
+		updates := []types.Hash{}
 		for _, header := range headers {
-			updates = append(updates, header.Hash.String())
+			updates = append(updates, header.Hash)
+		}
+		res, err := json.Marshal(updates)
+		if err != nil {
+			return "", err
 		}
-		return fmt.Sprintf("[\"%s\"]", strings.Join(updates, "\",\"")), nil
+		return string(res), nil
 	}
 	// log filter
 	res, err := json.Marshal(f.logs)
@@ -84,8 +105,8 @@ func (f *Filter) flush() error {
 		updates, newHead := f.block.getUpdates()
 		f.block = newHead
 
-		for _, block := range updates {
-			raw, err := json.Marshal(block)
+		for _, header := range updates {
+			raw, err := json.Marshal(toHeader(header))
 			if err != nil {
 				return err
 			}
@@ -117,6 +138,10 @@ func (f *Filter) isBlockFilter() bool {
 	return f.block != nil
 }
 
+func (f *Filter) isPendingTxnsFilter() bool {
+	return f.pendingTxns
+}
+
 func (f *Filter) match() bool {
 	return false
 }
@@ -131,9 +156,21 @@ type FilterManager struct {
 
 	subscription blockchain.Subscription
 
+	// txnCh are the txns admitted in the pool
+	txnCh     <-chan *types.Transaction
+	txnCancel func()
+
 	filters map[string]*Filter
 	lock    sync.Mutex
 
+	// maxConnFilters and maxClientFilters are the maximum number of
+	// subscriptions of a websocket connection and of filters of a
+	// client ip, zero means no limit
+	maxConnFilters   int
+	maxClientFilters int
+	connFilters      map[wsConn]int
+	clientFilters    map[string]int
+
 	updateCh chan struct{}
 	timer    timeHeapImpl
 	timeout  time.Duration
@@ -151,6 +188,9 @@ func NewFilterManager(logger hclog.Logger, store blockchainInterface) *FilterMan
 		timer:       timeHeapImpl{},
 		blockStream: &blockStream{},
 		timeout:     defaultTimeout,
+
+		connFilters:   map[wsConn]int{},
+		clientFilters: map[string]int{},
 	}
 
 	// start blockstream with the current header
@@ -160,6 +200,9 @@ func NewFilterManager(logger hclog.Logger, store blockchainInterface) *FilterMan
 	// start the head watcher
 	m.subscription = store.SubscribeEvents()
 
+	// watch the txns of the pool
+	m.txnCh, m.txnCancel = store.SubscribeTxns()
+
 	return m
 }
 
@@ -180,11 +223,10 @@ func (f *FilterManager) Run() {
 	var timeoutCh <-chan time.Time
 	for {
 		// check for the next filter to be removed
-		filter := f.nextTimeoutFilter()
-		if filter == nil {
-			timeoutCh = nil
+		if timestamp, ok := f.nextTimeout(); ok {
+			timeoutCh = time.After(timestamp.Sub(time.Now()))
 		} else {
-			timeoutCh = time.After(filter.timestamp.Sub(time.Now()))
+			timeoutCh = nil
 		}
 
 		select {
@@ -194,12 +236,16 @@ func (f *FilterManager) Run() {
 				f.logger.Error("failed to dispatch event", "err", err)
 			}
 
-		case <-timeoutCh:
-			// timeout for filter
-			if !f.Uninstall(filter.id) {
-				f.logger.Error("failed to uninstall filter", "id", filter.id)
+		case txn := <-f.txnCh:
+			// new txn in the pool
+			if err := f.dispatchTxn(txn); err != nil {
+				f.logger.Error("failed to dispatch txn", "err", err)
 			}
 
+		case <-timeoutCh:
+			// timeout for the filters not polled in time
+			f.removeExpired()
+
 		case <-f.updateCh:
 			// there is a new filter, reset the loop to start the timeout timer
 
@@ -210,17 +256,39 @@ func (f *FilterManager) Run() {
 	}
 }
 
-func (f *FilterManager) nextTimeoutFilter() *Filter {
+// nextTimeout returns the timeout of the next filter to be removed, the
+// timeouts of the filters change when they are polled
+func (f *FilterManager) nextTimeout() (time.Time, bool) {
 	f.lock.Lock()
-	if len(f.filters) == 0 {
-		f.lock.Unlock()
-		return nil
+	defer f.lock.Unlock()
+
+	if len(f.timer) == 0 {
+		return time.Time{}, false
+	}
+	return f.timer[0].timestamp, true
+}
+
+// removeExpired uninstalls the filters whose timeout has passed
+func (f *FilterManager) removeExpired() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := time.Now()
+	for len(f.timer) != 0 && !f.timer[0].timestamp.After(now) {
+		filter := heap.Pop(&f.timer).(*Filter)
+		f.removeFilter(filter)
+
+		f.logger.Debug("filter timeout", "id", filter.id)
 	}
+}
 
-	// pop the first item
-	item := f.timer[0]
-	f.lock.Unlock()
-	return item
+// refresh resets the timeout of a polled filter
+func (f *FilterManager) refresh(filter *Filter) {
+	if filter.isWS() {
+		return
+	}
+	filter.timestamp = time.Now().Add(f.timeout)
+	heap.Fix(&f.timer, filter.index)
 }
 
 func (f *FilterManager) dispatchEvent(evnt *blockchain.Event) error {
@@ -239,26 +307,10 @@ func (f *FilterManager) dispatchEvent(evnt *blockchain.Event) error {
 			return err
 		}
 
-		for indx, receipt := range receipts {
-			// check the logs with the filters
-			for _, log := range receipt.Logs {
-				for _, f := range f.filters {
-					if f.isLogFilter() {
-						if f.logFilter.Match(log) {
-							nn := &Log{
-								Address:     log.Address,
-								Topics:      log.Topics,
-								Data:        argBytes(log.Data),
-								BlockNumber: argUint64(h.Number),
-								BlockHash:   h.Hash,
-								TxHash:      receipt.TxHash,
-								TxIndex:     argUint64(indx),
-								Removed:     removed,
-							}
-							f.logs = append(f.logs, nn)
-						}
-					}
-				}
+		// check the logs with the filters
+		for _, f := range f.filters {
+			if f.isLogFilter() {
+				f.logs = append(f.logs, f.logFilter.filterLogs(h, receipts, removed)...)
 			}
 		}
 		return nil
@@ -274,9 +326,39 @@ func (f *FilterManager) dispatchEvent(evnt *blockchain.Event) error {
 	}
 
 	// flush all the websocket values
-	for _, f := range f.filters {
-		if f.isWS() {
-			f.flush()
+	for _, filter := range f.filters {
+		if filter.isWS() {
+			if err := filter.flush(); err != nil {
+				f.logger.Debug("failed to notify subscription", "id", filter.id, "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FilterManager) dispatchTxn(txn *types.Transaction) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, filter := range f.filters {
+		if !filter.isPendingTxnsFilter() {
+			continue
+		}
+		if !filter.isWS() {
+			filter.txnHashes = append(filter.txnHashes, txn.Hash)
+			continue
+		}
+
+		var res interface{} = txn.Hash
+		if filter.fullTxns {
+			res = toTransaction(txn)
+		}
+		raw, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		if err := filter.sendMessage(string(raw)); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -303,6 +385,7 @@ func (f *FilterManager) GetFilterChanges(id string) (string, error) {
 		// we cannot get updates from a ws filter with getFilterChanges
 		return "", errFilterDoesNotExists
 	}
+	f.refresh(item)
 
 	res, err := item.getFilterUpdates()
 	if err != nil {
@@ -311,35 +394,95 @@ func (f *FilterManager) GetFilterChanges(id string) (string, error) {
 	return res, nil
 }
 
+// GetLogFilter returns the log filter of the filter with the id
+func (f *FilterManager) GetLogFilter(id string) (*LogFilter, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	item, ok := f.filters[id]
+	if !ok || item.isWS() {
+		return nil, errFilterDoesNotExists
+	}
+	if !item.isLogFilter() {
+		return nil, fmt.Errorf("filter is not a log filter")
+	}
+	f.refresh(item)
+
+	return item.logFilter, nil
+}
+
 func (f *FilterManager) Uninstall(id string) bool {
 	f.lock.Lock()
+	defer f.lock.Unlock()
 
 	item, ok := f.filters[id]
 	if !ok {
 		return false
 	}
 
-	delete(f.filters, id)
-	heap.Remove(&f.timer, item.index)
-
-	f.lock.Unlock()
+	f.removeFilter(item)
+	if !item.isWS() {
+		heap.Remove(&f.timer, item.index)
+	}
 	return true
 }
 
-func (f *FilterManager) NewBlockFilter(ws wsConn) string {
-	return f.addFilter(nil, ws)
+// RemoveWs uninstalls the filters of the websocket connection
+func (f *FilterManager) RemoveWs(ws wsConn) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, filter := range f.filters {
+		if filter.ws == ws {
+			f.removeFilter(filter)
+		}
+	}
+}
+
+// removeFilter removes the filter from the filters and from the counts of
+// its connection and client, the caller removes it from the timer
+func (f *FilterManager) removeFilter(filter *Filter) {
+	delete(f.filters, filter.id)
+	if filter.isWS() {
+		if f.connFilters[filter.ws]--; f.connFilters[filter.ws] <= 0 {
+			delete(f.connFilters, filter.ws)
+		}
+	}
+	if filter.client != "" {
+		if f.clientFilters[filter.client]--; f.clientFilters[filter.client] <= 0 {
+			delete(f.clientFilters, filter.client)
+		}
+	}
+}
+
+func (f *FilterManager) NewBlockFilter(client string, ws wsConn) (string, error) {
+	return f.addFilter(nil, client, ws)
 }
 
-func (f *FilterManager) NewLogFilter(logFilter *LogFilter, ws wsConn) string {
-	return f.addFilter(logFilter, ws)
+func (f *FilterManager) NewLogFilter(logFilter *LogFilter, client string, ws wsConn) (string, error) {
+	return f.addFilter(logFilter, client, ws)
 }
 
-func (f *FilterManager) addFilter(logFilter *LogFilter, ws wsConn) string {
-	f.lock.Lock()
+// NewPendingTxnsFilter creates a filter of the txns admitted in the pool,
+// the websocket ones send the full txns if full is set
+func (f *FilterManager) NewPendingTxnsFilter(full bool, client string, ws wsConn) (string, error) {
+	return f.installFilter(&Filter{
+		id:          uuid.New().String(),
+		ws:          ws,
+		client:      client,
+		pendingTxns: true,
+		fullTxns:    full,
+		txnHashes:   []types.Hash{},
+	})
+}
 
+// addFilter installs a block filter or a log filter, the client
+// is empty if the filter is not limited by the client quota
+func (f *FilterManager) addFilter(logFilter *LogFilter, client string, ws wsConn) (string, error) {
 	filter := &Filter{
-		id: uuid.New().String(),
-		ws: ws,
+		id:     uuid.New().String(),
+		ws:     ws,
+		client: client,
 	}
 
 	if logFilter == nil {
@@ -350,10 +493,35 @@ func (f *FilterManager) addFilter(logFilter *LogFilter, ws wsConn) string {
 		// log filter
 		filter.logFilter = logFilter
 	}
+	return f.installFilter(filter)
+}
+
+func (f *FilterManager) installFilter(filter *Filter) (string, error) {
+	f.lock.Lock()
+
+	if filter.isWS() && f.maxConnFilters != 0 && f.connFilters[filter.ws] >= f.maxConnFilters {
+		f.lock.Unlock()
+		return "", limitExceeded("the connection reached the limit of %d subscriptions", f.maxConnFilters)
+	}
+	if filter.client != "" && f.maxClientFilters != 0 && f.clientFilters[filter.client] >= f.maxClientFilters {
+		f.lock.Unlock()
+		return "", limitExceeded("the client reached the limit of %d filters", f.maxClientFilters)
+	}
 
 	f.filters[filter.id] = filter
-	filter.timestamp = time.Now().Add(f.timeout)
-	heap.Push(&f.timer, filter)
+	if filter.isWS() {
+		f.connFilters[filter.ws]++
+	}
+	if filter.client != "" {
+		f.clientFilters[filter.client]++
+	}
+	if filter.isWS() {
+		// the subscriptions last until they are unsubscribed
+		filter.index = -1
+	} else {
+		filter.timestamp = time.Now().Add(f.timeout)
+		heap.Push(&f.timer, filter)
+	}
 
 	f.lock.Unlock()
 
@@ -362,10 +530,11 @@ func (f *FilterManager) addFilter(logFilter *LogFilter, ws wsConn) string {
 	default:
 	}
 
-	return filter.id
+	return filter.id, nil
 }
 
 func (f *FilterManager) Close() {
+	f.txnCancel()
 	close(f.closeCh)
 }
 
@@ -1,6 +1,8 @@
 package jsonrpc
 
 import (
+	"encoding/json"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -18,11 +20,12 @@ func TestFilterLog(t *testing.T) {
 	m := NewFilterManager(hclog.NewNullLogger(), store)
 	go m.Run()
 
-	id := m.addFilter(&LogFilter{
+	id, err := m.addFilter(&LogFilter{
 		Topics: [][]types.Hash{
 			{hash1},
 		},
-	}, nil)
+	}, "", nil)
+	assert.NoError(t, err)
 
 	store.emitEvent(&mockEvent{
 		NewChain: []*mockHeader{
@@ -65,7 +68,41 @@ func TestFilterLog(t *testing.T) {
 
 	time.Sleep(500 * time.Millisecond)
 
-	m.GetFilterChanges(id)
+	res, err := m.GetFilterChanges(id)
+	assert.NoError(t, err)
+
+	// the logs of the dropped blocks are marked as removed
+	var logs []*Log
+	assert.NoError(t, json.Unmarshal([]byte(res), &logs))
+	assert.Len(t, logs, 2)
+	assert.Equal(t, hash2, logs[0].BlockHash)
+	assert.True(t, logs[0].Removed)
+	assert.Equal(t, hash1, logs[1].BlockHash)
+	assert.False(t, logs[1].Removed)
+}
+
+func TestFilterPendingTxns(t *testing.T) {
+	store := newMockStore()
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	go m.Run()
+
+	id, err := m.NewPendingTxnsFilter(false, "", nil)
+	assert.NoError(t, err)
+
+	store.txnCh <- &types.Transaction{Hash: hash1}
+	store.txnCh <- &types.Transaction{Hash: hash2}
+
+	// the manager processes the txn before reading the next one
+	time.Sleep(100 * time.Millisecond)
+
+	res, err := m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`["%s","%s"]`, hash1, hash2), res)
+
+	res, err = m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", res)
 }
 
 func TestFilterBlock(t *testing.T) {
@@ -75,7 +112,8 @@ func TestFilterBlock(t *testing.T) {
 	go m.Run()
 
 	// add block filter
-	id := m.addFilter(nil, nil)
+	id, err := m.addFilter(nil, "", nil)
+	assert.NoError(t, err)
 
 	// emit two events
 	store.emitEvent(&mockEvent{
@@ -106,7 +144,13 @@ func TestFilterBlock(t *testing.T) {
 	// we need to wait for the manager to process the data
 	time.Sleep(500 * time.Millisecond)
 
-	m.GetFilterChanges(id)
+	res, err := m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`["%s","%s","%s"]`, types.StringToHash("1"), types.StringToHash("2"), types.StringToHash("3")), res)
+
+	res, err = m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", res)
 
 	// emit one more event, it should not return the
 	// first three hashes
@@ -122,7 +166,9 @@ func TestFilterBlock(t *testing.T) {
 
 	time.Sleep(500 * time.Millisecond)
 
-	m.GetFilterChanges(id)
+	res, err = m.GetFilterChanges(id)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`["%s"]`, types.StringToHash("4")), res)
 }
 
 func TestFilterTimeout(t *testing.T) {
@@ -134,13 +180,84 @@ func TestFilterTimeout(t *testing.T) {
 	go m.Run()
 
 	// add block filter
-	id := m.addFilter(nil, nil)
+	id, err := m.addFilter(nil, "", nil)
+	assert.NoError(t, err)
 
 	assert.True(t, m.Exists(id))
 	time.Sleep(3 * time.Second)
 	assert.False(t, m.Exists(id))
 }
 
+func TestFilterLimits(t *testing.T) {
+	m := NewFilterManager(hclog.NewNullLogger(), newMockStore())
+	m.maxConnFilters = 2
+	m.maxClientFilters = 3
+
+	conn0, conn1 := &mockWsConn{}, &mockWsConn{}
+
+	// the subscriptions of a connection are limited
+	id, err := m.NewBlockFilter("a", conn0)
+	assert.NoError(t, err)
+	_, err = m.NewPendingTxnsFilter(false, "a", conn0)
+	assert.NoError(t, err)
+	_, err = m.NewLogFilter(&LogFilter{}, "a", conn0)
+	assert.Error(t, err)
+
+	// and the filters of a client with any connection
+	_, err = m.NewBlockFilter("a", conn1)
+	assert.NoError(t, err)
+	_, err = m.NewBlockFilter("a", nil)
+	assert.Error(t, err)
+	_, err = m.NewBlockFilter("b", nil)
+	assert.NoError(t, err)
+
+	// the uninstalled filters release the quotas
+	assert.True(t, m.Uninstall(id))
+	_, err = m.NewBlockFilter("a", conn0)
+	assert.NoError(t, err)
+
+	m.RemoveWs(conn0)
+	assert.Empty(t, m.connFilters[conn0])
+	assert.Equal(t, 1, m.clientFilters["a"])
+
+	// the clients without ip are not limited
+	for i := 0; i < 5; i++ {
+		_, err = m.NewBlockFilter("", nil)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, m.clientFilters, 2)
+}
+
+func TestFilterTimeout_Poll(t *testing.T) {
+	store := newMockStore()
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	m.timeout = 1 * time.Second
+
+	go m.Run()
+
+	id, err := m.addFilter(nil, "", nil)
+	assert.NoError(t, err)
+	wsID, err := m.NewBlockFilter("", &mockWsConn{})
+	assert.NoError(t, err)
+
+	// polling the filter resets the timeout
+	for i := 0; i < 3; i++ {
+		time.Sleep(500 * time.Millisecond)
+		_, err := m.GetFilterChanges(id)
+		assert.NoError(t, err)
+	}
+	assert.True(t, m.Exists(id))
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.False(t, m.Exists(id))
+
+	// the websocket filters do not timeout
+	assert.True(t, m.Exists(wsID))
+	assert.True(t, m.Uninstall(wsID))
+	assert.False(t, m.Uninstall(wsID))
+}
+
 func TestFilterWebsocket(t *testing.T) {
 	store := newMockStore()
 
@@ -151,10 +268,11 @@ func TestFilterWebsocket(t *testing.T) {
 	m := NewFilterManager(hclog.NewNullLogger(), store)
 	go m.Run()
 
-	id := m.NewBlockFilter(mock)
+	id, err := m.NewBlockFilter("", mock)
+	assert.NoError(t, err)
 
 	// we cannot call get filter changes for a websocket filter
-	_, err := m.GetFilterChanges(id)
+	_, err = m.GetFilterChanges(id)
 	assert.Equal(t, err, errFilterDoesNotExists)
 
 	// emit two events
@@ -169,12 +287,80 @@ func TestFilterWebsocket(t *testing.T) {
 	})
 
 	select {
-	case <-mock.msgCh:
+	case msg := <-mock.msgCh:
+		// the headers are notified as in the blocks
+		var notification struct {
+			Params struct {
+				Subscription string
+				Result       map[string]interface{}
+			}
+		}
+		assert.NoError(t, json.Unmarshal(msg, &notification))
+		assert.Equal(t, id, notification.Params.Subscription)
+		assert.Equal(t, types.StringToHash("1").String(), notification.Params.Result["hash"])
+		assert.Equal(t, "0x0", notification.Params.Result["number"])
 	case <-time.After(2 * time.Second):
 		t.Fatal("bad")
 	}
 }
 
+func TestFilterWebsocket_Logs(t *testing.T) {
+	store := newMockStore()
+
+	mock := &mockWsConn{
+		msgCh: make(chan []byte, 2),
+	}
+
+	m := NewFilterManager(hclog.NewNullLogger(), store)
+	go m.Run()
+
+	_, err := m.NewLogFilter(&LogFilter{Addresses: []types.Address{addr1}}, "", mock)
+	assert.NoError(t, err)
+
+	newHeader := func(hash types.Hash, addr types.Address) *mockHeader {
+		return &mockHeader{
+			header: &types.Header{
+				Hash: hash,
+			},
+			receipts: []*types.Receipt{
+				{
+					Logs: []*types.Log{
+						{Address: addr},
+					},
+				},
+			},
+		}
+	}
+
+	// the logs of the dropped blocks are notified as removed
+	store.emitEvent(&mockEvent{
+		OldChain: []*mockHeader{newHeader(hash1, addr1)},
+		NewChain: []*mockHeader{newHeader(hash2, addr2), newHeader(hash3, addr1)},
+	})
+
+	for _, expected := range []struct {
+		hash    types.Hash
+		removed bool
+	}{
+		{hash1, true},
+		{hash3, false},
+	} {
+		select {
+		case msg := <-mock.msgCh:
+			var notification struct {
+				Params struct {
+					Result *Log
+				}
+			}
+			assert.NoError(t, json.Unmarshal(msg, &notification))
+			assert.Equal(t, expected.hash, notification.Params.Result.BlockHash)
+			assert.Equal(t, expected.removed, notification.Params.Result.Removed)
+		case <-time.After(2 * time.Second):
+			t.Fatal("bad")
+		}
+	}
+}
+
 type mockWsConn struct {
 	msgCh chan []byte
 }
@@ -211,11 +397,12 @@ type mockStore struct {
 
 	header       *types.Header
 	subscription *blockchain.MockSubscription
+	txnCh        chan *types.Transaction
 	receiptsLock sync.Mutex
 	receipts     map[types.Hash][]*types.Receipt
 }
 
-func (m *mockStore) ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error) {
+func (m *mockStore) ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
 	panic("implement me")
 }
 
@@ -235,9 +422,14 @@ func newMockStore() *mockStore {
 	return &mockStore{
 		header:       &types.Header{Number: 0},
 		subscription: blockchain.NewMockSubscription(),
+		txnCh:        make(chan *types.Transaction),
 	}
 }
 
+func (m *mockStore) SubscribeTxns() (<-chan *types.Transaction, func()) {
+	return m.txnCh, func() {}
+}
+
 type mockHeader struct {
 	header   *types.Header
 	receipts []*types.Receipt
@@ -249,6 +441,9 @@ type mockEvent struct {
 }
 
 func (m *mockStore) emitEvent(evnt *mockEvent) {
+	m.receiptsLock.Lock()
+	defer m.receiptsLock.Unlock()
+
 	if m.receipts == nil {
 		m.receipts = map[types.Hash][]*types.Receipt{}
 	}
@@ -0,0 +1,86 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"sync"
+)
+
+func (j *JSONRPC) setupIPC() error {
+	// remove the socket of a previous run that was not closed
+	if err := os.Remove(j.config.IPCPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	lis, err := net.Listen("unix", j.config.IPCPath)
+	if err != nil {
+		return err
+	}
+	// only the owner of the node can attach
+	if err := os.Chmod(j.config.IPCPath, 0600); err != nil {
+		lis.Close()
+		return err
+	}
+	j.ipcListener = lis
+
+	j.logger.Info("ipc server started", "path", j.config.IPCPath)
+
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				j.logger.Debug("closed ipc listener", "err", err)
+				return
+			}
+			go j.handleIPC(conn)
+		}
+	}()
+	return nil
+}
+
+// ipcConn serializes the writes of the filters and of the
+// responses in the ipc connection
+type ipcConn struct {
+	lock sync.Mutex
+	conn net.Conn
+}
+
+func (i *ipcConn) WriteMessage(b []byte) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	_, err := i.conn.Write(append(b, '\n'))
+	return err
+}
+
+// handleIPC serves the stream of requests of the connection
+func (j *JSONRPC) handleIPC(c net.Conn) {
+	defer c.Close()
+
+	// the ipc clients are the owner of the node, their
+	// requests are not restricted by the limits
+	conn := &ipcConn{conn: c}
+
+	// remove the subscriptions of the connection once it is closed
+	defer j.dispatcher.RemoveWs(conn)
+
+	dec := json.NewDecoder(c)
+	for {
+		var message json.RawMessage
+		if err := dec.Decode(&message); err != nil {
+			if _, ok := err.(*json.SyntaxError); ok {
+				conn.WriteMessage(errorResponse(0, invalidJSONRequest))
+			}
+			return
+		}
+		go func() {
+			resp, err := j.dispatcher.HandleWs(message, "", conn)
+			if err != nil {
+				var req Request
+				json.Unmarshal(message, &req)
+				resp = errorResponse(req.ID, err)
+			}
+			conn.WriteMessage(resp)
+		}()
+	}
+}
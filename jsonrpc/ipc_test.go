@@ -0,0 +1,69 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPC(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "minimal-ipc-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "minimal.ipc")
+
+	// a stale socket is replaced
+	assert.NoError(t, ioutil.WriteFile(path, nil, 0600))
+
+	store := newMockStore()
+	j := &JSONRPC{
+		logger:     hclog.NewNullLogger(),
+		config:     &Config{Store: store, IPCPath: path},
+		dispatcher: newDispatcher(hclog.NewNullLogger(), store, 0),
+	}
+	assert.NoError(t, j.setupIPC())
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	assert.NoError(t, err)
+
+	// the requests are a stream in the connection
+	_, err = conn.Write([]byte(`{"id":1,"method":"eth_blockNumber","params":[]}
+		[{"id":2,"method":"eth_blockNumber","params":[]}]{"id":3,"method":"eth_subscribe","params":["newHeads"]}`))
+	assert.NoError(t, err)
+
+	resps := map[string]bool{}
+	scanner := bufio.NewScanner(conn)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for i := 0; i < 3; i++ {
+		assert.True(t, scanner.Scan())
+		resps[scanner.Text()] = true
+	}
+	assert.True(t, resps[`{"id":1,"result":"0x0"}`])
+	assert.True(t, resps[`[{"id":2,"result":"0x0"}]`])
+
+	fm := j.dispatcher.(*Dispatcher).filterManager
+
+	// the subscriptions are removed once the connection is closed
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		fm.lock.Lock()
+		defer fm.lock.Unlock()
+		return len(fm.filters) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, j.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,17 +1,18 @@
 package jsonrpc
 
 import (
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 
-	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-var upgrader = websocket.Upgrader{}
-
 var (
 	defaultHttpAddr = &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8545}
 )
@@ -42,17 +43,109 @@ type JSONRPC struct {
 	logger     hclog.Logger
 	config     *Config
 	dispatcher dispatcherImpl
+
+	// number of open websocket connections
+	wsLock  sync.Mutex
+	wsConns int
+
+	ipcListener net.Listener
 }
 
 type dispatcherImpl interface {
-	HandleWs(reqBody []byte, conn wsConn) ([]byte, error)
-	Handle([]byte) ([]byte, error)
+	HandleWs(reqBody []byte, client string, conn wsConn) ([]byte, error)
+	Handle(reqBody []byte, client string) ([]byte, error)
+	RemoveWs(conn wsConn)
 }
 
 type Config struct {
 	Store   blockchainInterface
 	Addr    *net.TCPAddr
 	ChainID uint64
+
+	// WSAddr is the address of the websocket server, the websocket
+	// endpoint is also served in the /ws path of the http server
+	WSAddr *net.TCPAddr
+
+	// WSMaxConns is the maximum number of open websocket
+	// connections, zero means no limit
+	WSMaxConns int
+
+	// WSMaxSubscriptions is the maximum number of subscriptions of a
+	// websocket connection, zero means no limit
+	WSMaxSubscriptions int
+
+	// MaxClientFilters is the maximum number of filters and subscriptions
+	// of a client ip, zero means no limit
+	MaxClientFilters int
+
+	// WSOrigins are the origins allowed to open websocket connections,
+	// '*' allows any origin. Only the requests from the same host are
+	// allowed if it is empty
+	WSOrigins []string
+
+	// BatchLimit is the maximum number of requests of
+	// a batch, zero means no limit
+	BatchLimit uint64
+
+	// MaxRequestSize and MaxResponseSize are the maximum sizes in bytes
+	// of the http requests and responses, zero means no limit. The size of
+	// the websocket requests is wsReadLimit if MaxRequestSize is zero
+	MaxRequestSize  uint64
+	MaxResponseSize uint64
+
+	// LogsBlockRange and LogsLimit are the maximum number of blocks and
+	// of logs of eth_getLogs, zero means no limit. The queries over the
+	// limits fail unless they are paginated with a cursor
+	LogsBlockRange uint64
+	LogsLimit      uint64
+
+	// CacheSize is the number of results of the immutable data, like
+	// the old blocks and receipts, that are cached. Zero disables the cache
+	CacheSize int
+
+	// IPCPath is the path of the unix socket of the ipc
+	// server, it is disabled if empty
+	IPCPath string
+
+	// Admin enables the admin namespace, it manages the peers of the node
+	// so it is only served over the ipc server. The http and websocket
+	// servers serve it only if it is in AllowMethods
+	Admin bool
+
+	// RateLimit is the number of requests per second of each
+	// client ip, zero means no limit
+	RateLimit uint64
+
+	// MethodRateLimits are the number of requests per second of each
+	// client ip to a method or to the methods of a namespace
+	MethodRateLimits map[string]uint64
+
+	// Metrics is the registry of the metrics of the requests,
+	// they are disabled if nil
+	Metrics prometheus.Registerer
+
+	// AccessLog logs each request with its client, method, duration
+	// and sizes. It is disabled if nil
+	AccessLog hclog.Logger
+
+	// TLSConfig serves the http and the websocket servers
+	// over tls, they are served in plain text if nil
+	TLSConfig *tls.Config
+
+	// CORSOrigins are the origins of the browsers allowed to send
+	// requests to the http server, '*' allows any origin
+	CORSOrigins []string
+
+	// VHosts are the host names of the http requests accepted by the
+	// server, '*' accepts any name. The requests to an ip are always
+	// accepted and any name is accepted if it is empty
+	VHosts []string
+
+	// AllowMethods are the methods and namespaces served in the http and
+	// websocket servers, all of them are served if it is empty.
+	// DenyMethods are not served, even if they are allowed
+	AllowMethods []string
+	DenyMethods  []string
 }
 
 // NewJSONRPC returns the JsonRPC http server
@@ -60,23 +153,72 @@ func NewJSONRPC(logger hclog.Logger, config *Config) (*JSONRPC, error) {
 	if config.Addr == nil {
 		config.Addr = defaultHttpAddr
 	}
+	d := newDispatcher(logger, config.Store, config.ChainID)
+	d.batchLimit = config.BatchLimit
+	d.logsBlockRange = config.LogsBlockRange
+	d.logsLimit = config.LogsLimit
+	d.limits = newLimits(config)
+	if d.filterManager != nil {
+		d.filterManager.maxConnFilters = config.WSMaxSubscriptions
+		d.filterManager.maxClientFilters = config.MaxClientFilters
+	}
+	if config.Metrics != nil {
+		d.metrics = newMetrics()
+		if err := d.metrics.register(config.Metrics); err != nil {
+			return nil, err
+		}
+	}
+	d.accessLog = config.AccessLog
+	if config.CacheSize != 0 {
+		cache, err := newResponseCache(config.CacheSize)
+		if err != nil {
+			return nil, err
+		}
+		d.cache = cache
+		go cache.watch(config.Store.SubscribeEvents())
+	}
+	if config.Admin {
+		if config.IPCPath == "" && !d.limits.allows("admin") {
+			return nil, fmt.Errorf("the admin namespace requires the ipc server or allowing it in the methods")
+		}
+		d.registerAdmin()
+	}
+
 	srv := &JSONRPC{
 		logger:     logger.Named("jsonrpc"),
 		config:     config,
-		dispatcher: newDispatcher(logger, config.Store, config.ChainID),
+		dispatcher: d,
 	}
 
 	// start http server
 	if err := srv.setupHTTP(); err != nil {
 		return nil, err
 	}
+	if config.WSAddr != nil {
+		if err := srv.setupWS(); err != nil {
+			return nil, err
+		}
+	}
+	if config.IPCPath != "" {
+		if err := srv.setupIPC(); err != nil {
+			return nil, err
+		}
+	}
 	return srv, nil
 }
 
+// Close closes the ipc server and removes its socket
+func (j *JSONRPC) Close() error {
+	if j.ipcListener != nil {
+		return j.ipcListener.Close()
+	}
+	return nil
+}
+
 func (j *JSONRPC) setupHTTP() error {
 	j.logger.Info("http server started", "addr", j.config.Addr.String())
 
-	lis, err := net.Listen("tcp", j.config.Addr.String())
+	lis, err := j.listen(j.config.Addr)
 	if err != nil {
 		return err
 	}
@@ -86,7 +228,7 @@ func (j *JSONRPC) setupHTTP() error {
 	mux.HandleFunc("/ws", j.handleWs)
 
 	srv := http.Server{
-		Handler: mux,
+		Handler: j.checkVHosts(mux),
 	}
 	go func() {
 		if err := srv.Serve(lis); err != nil {
@@ -96,36 +238,16 @@ func (j *JSONRPC) setupHTTP() error {
 	return nil
 }
 
-type wrapWsConn struct {
-	conn *websocket.Conn
-}
-
-func (w *wrapWsConn) WriteMessage(b []byte) error {
-	return w.conn.WriteMessage(0, b)
-}
-
-func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
-	c, err := upgrader.Upgrade(w, req, nil)
+// listen opens the listener of a server, over tls if it is enabled
+func (j *JSONRPC) listen(addr *net.TCPAddr) (net.Listener, error) {
+	lis, err := net.Listen("tcp", addr.String())
 	if err != nil {
-		return
+		return nil, err
 	}
-	defer c.Close()
-
-	wrapConn := &wrapWsConn{conn: c}
-	for {
-		_, message, err := c.ReadMessage()
-		if err != nil {
-			break
-		}
-		go func() {
-			resp, err := j.dispatcher.HandleWs(message, wrapConn)
-			if err != nil {
-				wrapConn.WriteMessage(resp)
-			} else {
-				wrapConn.WriteMessage([]byte(fmt.Sprintf(err.Error())))
-			}
-		}()
+	if j.config.TLSConfig != nil {
+		lis = tls.NewListener(lis, j.config.TLSConfig)
 	}
+	return lis, nil
 }
 
 func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
@@ -133,6 +255,11 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 		w.Write([]byte(err.Error()))
 		return
 	}
+	j.setCORS(w, req)
+	if req.Method == "OPTIONS" {
+		// the preflight request of the browsers
+		return
+	}
 	if req.Method == "GET" {
 		w.Write([]byte("PolygonSDK JSON-RPC"))
 		return
@@ -141,15 +268,76 @@ func (j *JSONRPC) handle(w http.ResponseWriter, req *http.Request) {
 		w.Write([]byte("method " + req.Method + " not allowed"))
 		return
 	}
+	if max := j.config.MaxRequestSize; max != 0 {
+		if req.ContentLength > int64(max) {
+			http.Error(w, requestTooLarge(max), http.StatusRequestEntityTooLarge)
+			return
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, int64(max))
+	}
 	data, err := ioutil.ReadAll(req.Body)
 	if err != nil {
+		if j.config.MaxRequestSize != 0 && uint64(len(data)) >= j.config.MaxRequestSize {
+			http.Error(w, requestTooLarge(j.config.MaxRequestSize), http.StatusRequestEntityTooLarge)
+			return
+		}
 		handleErr(err)
 		return
 	}
-	resp, err := j.dispatcher.Handle(data)
+	resp, err := j.dispatcher.Handle(data, clientIP(req.RemoteAddr))
 	if err != nil {
 		handleErr(err)
 		return
 	}
-	w.Write(resp)
+	if max := j.config.MaxResponseSize; max != 0 && uint64(len(resp)) > max {
+		handleErr(limitExceeded("response of %d bytes exceeds the limit of %d bytes", len(resp), max))
+		return
+	}
+	writeResponse(w, req, resp)
+}
+
+func requestTooLarge(max uint64) string {
+	return fmt.Sprintf("request body exceeds the limit of %d bytes", max)
+}
+
+// setCORS sets the cors headers if the origin of the request is allowed
+func (j *JSONRPC) setCORS(w http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	if origin == "" || !originAllowed(origin, j.config.CORSOrigins) {
+		return
+	}
+	w.Header().Add("Vary", "Origin")
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+}
+
+// checkVHosts rejects the requests whose host name is not allowed, it
+// protects the server from the dns rebinding attacks of the browsers
+func (j *JSONRPC) checkVHosts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !hostAllowed(req.Host, j.config.VHosts) {
+			http.Error(w, "invalid host specified", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func hostAllowed(host string, vhosts []string) bool {
+	if len(vhosts) == 0 {
+		return true
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) != nil {
+		return true
+	}
+	for _, vhost := range vhosts {
+		if vhost == "*" || strings.EqualFold(vhost, host) {
+			return true
+		}
+	}
+	return false
 }
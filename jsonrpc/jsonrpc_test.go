@@ -1,10 +1,16 @@
 package jsonrpc
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -18,3 +24,134 @@ func TestHTTPServer(t *testing.T) {
 	}
 	fmt.Println(srv)
 }
+
+func newTestHTTPServer(config *Config) http.Handler {
+	j := &JSONRPC{
+		logger:     hclog.NewNullLogger(),
+		config:     config,
+		dispatcher: newTestDispatcher(hclog.NewNullLogger(), newMockStore()),
+	}
+	return j.checkVHosts(http.HandlerFunc(j.handle))
+}
+
+func TestHTTP_CORS(t *testing.T) {
+	srv := newTestHTTPServer(&Config{CORSOrigins: []string{"https://app.example.com"}})
+
+	send := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", strings.NewReader(`{"id":1,"method":"web3_clientVersion","params":[]}`))
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	// preflight request of an allowed origin
+	w := send("OPTIONS", "https://app.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "POST")
+	assert.Empty(t, w.Body.String())
+
+	w = send("POST", "https://app.example.com")
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Body.String(), "result")
+
+	// the origins that are not allowed do not get the headers
+	w = send("POST", "https://other.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestHTTP_VHosts(t *testing.T) {
+	cases := []struct {
+		vhosts []string
+		host   string
+		ok     bool
+	}{
+		{nil, "example.com", true},
+		{[]string{"localhost"}, "localhost:8545", true},
+		{[]string{"localhost"}, "LOCALHOST", true},
+		{[]string{"localhost"}, "attacker.com:8545", false},
+		{[]string{"localhost"}, "127.0.0.1:8545", true},
+		{[]string{"localhost"}, "[::1]:8545", true},
+		{[]string{"*"}, "example.com", true},
+	}
+	for _, c := range cases {
+		srv := newTestHTTPServer(&Config{VHosts: c.vhosts})
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Host = c.host
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+
+		if c.ok {
+			assert.Equal(t, http.StatusOK, w.Code, c.host)
+		} else {
+			assert.Equal(t, http.StatusForbidden, w.Code, c.host)
+		}
+	}
+}
+
+func batchRequest(n int) string {
+	reqs := []string{}
+	for i := 0; i < n; i++ {
+		reqs = append(reqs, fmt.Sprintf(`{"id":%d,"method":"web3_clientVersion","params":[]}`, i))
+	}
+	return "[" + strings.Join(reqs, ",") + "]"
+}
+
+func TestHTTP_Compression(t *testing.T) {
+	srv := newTestHTTPServer(&Config{})
+
+	send := func(body, encoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		return w
+	}
+
+	// the large responses are compressed if the client accepts gzip
+	plain := send(batchRequest(50), "")
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+
+	w := send(batchRequest(50), "deflate, gzip;q=0.9")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Get("Vary"), "Accept-Encoding")
+	assert.Less(t, w.Body.Len(), plain.Body.Len())
+
+	gr, err := gzip.NewReader(w.Body)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, plain.Body.String(), string(data))
+
+	// the small ones are not
+	w = send(batchRequest(1), "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestHTTP_PayloadLimits(t *testing.T) {
+	body := batchRequest(10)
+	srv := newTestHTTPServer(&Config{MaxRequestSize: uint64(len(body)) - 1})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	// the size of the body is also limited without a content length
+	req = httptest.NewRequest("POST", "/", ioutil.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	srv = newTestHTTPServer(&Config{MaxRequestSize: uint64(len(body)), MaxResponseSize: 100})
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "exceeds the limit of 100 bytes")
+	assert.NotContains(t, w.Body.String(), "result")
+}
@@ -0,0 +1,192 @@
+package jsonrpc
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+var rateLimited = &ErrorObject{Code: -32005, Message: "request rate limit exceeded"}
+
+// maxBuckets is the number of buckets of a limiter, the
+// bucket of the least recent client is removed over it
+const maxBuckets = 10000
+
+// bucket are the requests that a client can send at once
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket for each client, the buckets are
+// refilled at the rate of requests per second up to the rate
+type rateLimiter struct {
+	lock    sync.Mutex
+	rate    float64
+	buckets *lru.Cache
+	now     func() time.Time
+}
+
+func newRateLimiter(rate uint64) *rateLimiter {
+	buckets, _ := lru.New(maxBuckets)
+	return &rateLimiter{
+		rate:    float64(rate),
+		buckets: buckets,
+		now:     time.Now,
+	}
+}
+
+// allow takes a token of the bucket of the client,
+// it returns false if the bucket is empty
+func (r *rateLimiter) allow(client string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	client = clientRange(client)
+
+	now := r.now()
+	var b *bucket
+	if obj, ok := r.buckets.Get(client); ok {
+		b = obj.(*bucket)
+	} else {
+		b = &bucket{tokens: r.rate, last: now}
+		r.buckets.Add(client, b)
+	}
+
+	b.tokens = r.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (r *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	tokens := b.tokens + now.Sub(b.last).Seconds()*r.rate
+	if tokens > r.rate {
+		tokens = r.rate
+	}
+	return tokens
+}
+
+// clientRange returns the /64 network of an ipv6 client, a host
+// usually has all of it so its addresses share the bucket
+func clientRange(client string) string {
+	ip := net.ParseIP(client)
+	if ip == nil || ip.To4() != nil {
+		return client
+	}
+	return ip.Mask(net.CIDRMask(64, 128)).String()
+}
+
+// ipcNamespaces are the namespaces that manage the node, they are only
+// served over the ipc server unless they are in the allowed methods
+var ipcNamespaces = toRuleSet([]string{"admin"})
+
+// limits are the methods served to the clients and the rate of
+// their requests. A rule is either a method (eth_getLogs) or
+// all the methods of a namespace (debug)
+type limits struct {
+	allow map[string]struct{}
+	deny  map[string]struct{}
+
+	// limiter are the requests of each client
+	limiter *rateLimiter
+
+	// methods are the requests of each client to a rule
+	methods     map[string]*rateLimiter
+	methodRules map[string]struct{}
+}
+
+func newLimits(config *Config) *limits {
+	l := &limits{
+		allow:       toRuleSet(config.AllowMethods),
+		deny:        toRuleSet(config.DenyMethods),
+		methods:     map[string]*rateLimiter{},
+		methodRules: map[string]struct{}{},
+	}
+	if config.RateLimit != 0 {
+		l.limiter = newRateLimiter(config.RateLimit)
+	}
+	for rule, rate := range config.MethodRateLimits {
+		if rate != 0 {
+			l.methods[rule] = newRateLimiter(rate)
+			l.methodRules[rule] = struct{}{}
+		}
+	}
+	return l
+}
+
+func toRuleSet(rules []string) map[string]struct{} {
+	res := map[string]struct{}{}
+	for _, rule := range rules {
+		res[rule] = struct{}{}
+	}
+	return res
+}
+
+// match returns the rule of the set that applies to the method, the
+// rule of the method takes precedence over the one of its namespace
+func match(method string, rules map[string]struct{}) (string, bool) {
+	if _, ok := rules[method]; ok {
+		return method, true
+	}
+	namespace := strings.SplitN(method, "_", 2)[0]
+	if _, ok := rules[namespace]; ok {
+		return namespace, true
+	}
+	return "", false
+}
+
+// allows returns whether a rule of the allowed methods serves the namespace
+func (l *limits) allows(namespace string) bool {
+	for rule := range l.allow {
+		if strings.SplitN(rule, "_", 2)[0] == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns an error if the client cannot call the method. The
+// requests without a client, like the ones of the ipc server, are
+// not restricted
+func (l *limits) check(client string, method string) error {
+	if l == nil || client == "" {
+		return nil
+	}
+	if _, ok := match(method, l.deny); ok {
+		return invalidMethod(method)
+	}
+	if _, ok := match(method, ipcNamespaces); ok {
+		if _, ok := match(method, l.allow); !ok {
+			return invalidMethod(method)
+		}
+	}
+	if len(l.allow) != 0 {
+		if _, ok := match(method, l.allow); !ok {
+			return invalidMethod(method)
+		}
+	}
+
+	if l.limiter != nil && !l.limiter.allow(client) {
+		return rateLimited
+	}
+	if rule, ok := match(method, l.methodRules); ok && !l.methods[rule].allow(client) {
+		return rateLimited
+	}
+	return nil
+}
+
+// clientIP returns the ip of the remote address of a request
+func clientIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
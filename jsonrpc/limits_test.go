@@ -0,0 +1,152 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+
+	r := newRateLimiter(2)
+	r.now = func() time.Time {
+		return now
+	}
+
+	// the bucket starts full
+	assert.True(t, r.allow("a"))
+	assert.True(t, r.allow("a"))
+	assert.False(t, r.allow("a"))
+
+	// the clients have their own buckets
+	assert.True(t, r.allow("b"))
+
+	// the bucket is refilled at the rate
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, r.allow("a"))
+	assert.False(t, r.allow("a"))
+
+	// but it does not go over the rate
+	now = now.Add(10 * time.Second)
+	assert.True(t, r.allow("a"))
+	assert.True(t, r.allow("a"))
+	assert.False(t, r.allow("a"))
+
+}
+
+func TestRateLimiter_Buckets(t *testing.T) {
+	r := newRateLimiter(1)
+
+	// the addresses of an ipv6 /64 share the bucket
+	assert.True(t, r.allow("2001:db8:1:2::1"))
+	assert.False(t, r.allow("2001:db8:1:2:ffff::2"))
+	assert.True(t, r.allow("2001:db8:1:3::1"))
+	assert.True(t, r.allow("127.0.0.1"))
+	assert.True(t, r.allow("127.0.0.2"))
+
+	// the least recent bucket is removed over the max
+	buckets, _ := lru.New(2)
+	r.buckets = buckets
+
+	assert.True(t, r.allow("a"))
+	assert.True(t, r.allow("b"))
+	assert.False(t, r.allow("a"))
+	assert.True(t, r.allow("c"))
+	assert.Equal(t, 2, r.buckets.Len())
+
+	// b was removed and starts with a full bucket
+	assert.True(t, r.allow("b"))
+	assert.False(t, r.allow("c"))
+}
+
+func TestLimits_Methods(t *testing.T) {
+	l := newLimits(&Config{
+		AllowMethods: []string{"eth", "net_version"},
+		DenyMethods:  []string{"eth_sendTransaction"},
+	})
+
+	cases := []struct {
+		method string
+		ok     bool
+	}{
+		{"eth_blockNumber", true},
+		{"net_version", true},
+		{"net_listening", false},
+		{"eth_sendTransaction", false},
+		{"debug_traceTransaction", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.ok, l.check("a", c.method) == nil, c.method)
+	}
+
+	// the requests without a client are not restricted
+	assert.NoError(t, l.check("", "debug_traceTransaction"))
+}
+
+func TestLimits_Admin(t *testing.T) {
+	// the admin namespace is only served over the ipc server by default
+	l := newLimits(&Config{})
+	assert.Equal(t, invalidMethod("admin_addPeer"), l.check("a", "admin_addPeer"))
+	assert.NoError(t, l.check("", "admin_addPeer"))
+	assert.False(t, l.allows("admin"))
+
+	// unless it is allowed
+	l = newLimits(&Config{AllowMethods: []string{"eth", "admin_peers"}})
+	assert.NoError(t, l.check("a", "admin_peers"))
+	assert.Equal(t, invalidMethod("admin_addPeer"), l.check("a", "admin_addPeer"))
+	assert.True(t, l.allows("admin"))
+
+	// and not denied
+	l = newLimits(&Config{AllowMethods: []string{"admin"}, DenyMethods: []string{"admin_removePeer"}})
+	assert.NoError(t, l.check("a", "admin_addPeer"))
+	assert.Equal(t, invalidMethod("admin_removePeer"), l.check("a", "admin_removePeer"))
+}
+
+func TestLimits_Rate(t *testing.T) {
+	l := newLimits(&Config{
+		RateLimit: 3,
+		MethodRateLimits: map[string]uint64{
+			"eth_getLogs": 1,
+			"debug":       2,
+		},
+	})
+
+	assert.NoError(t, l.check("a", "eth_getLogs"))
+	assert.Equal(t, rateLimited, l.check("a", "eth_getLogs"))
+
+	assert.NoError(t, l.check("b", "debug_traceTransaction"))
+	assert.NoError(t, l.check("b", "debug_traceBlockByHash"))
+	assert.Equal(t, rateLimited, l.check("b", "debug_traceTransaction"))
+
+	// the requests of the methods count in the limit of the client
+	assert.NoError(t, l.check("a", "eth_blockNumber"))
+	assert.Equal(t, rateLimited, l.check("a", "eth_blockNumber"))
+}
+
+func TestDispatcher_Limits(t *testing.T) {
+	s := newTestDispatcher(hclog.NewNullLogger(), newMockStore())
+	s.limits = newLimits(&Config{
+		DenyMethods:      []string{"debug"},
+		MethodRateLimits: map[string]uint64{"eth_blockNumber": 1},
+	})
+
+	_, err := s.Handle([]byte(`{"id":1,"method":"debug_traceTransaction","params":[]}`), "a")
+	assert.Equal(t, invalidMethod("debug_traceTransaction"), err)
+
+	// each request of a batch counts in the limits
+	resp, err := s.Handle([]byte(`[
+		{"id":1,"method":"eth_blockNumber","params":[]},
+		{"id":2,"method":"eth_blockNumber","params":[]}
+	]`), "a")
+	assert.NoError(t, err)
+
+	var res []*Response
+	assert.NoError(t, json.Unmarshal(resp, &res))
+	assert.Nil(t, res[0].Error)
+	assert.Equal(t, rateLimited, res[1].Error)
+}
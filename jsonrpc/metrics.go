@@ -0,0 +1,126 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unknownMethod labels the requests of the methods that do not
+// exist, the clients cannot create new series
+const unknownMethod = "unknown"
+
+// metrics are the metrics of the requests by method, an operator can
+// find the clients that overload the node with the expensive methods
+type metrics struct {
+	requests     *prometheus.CounterVec
+	errors       *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "jsonrpc",
+			Name:      "requests_total",
+			Help:      "Number of jsonrpc requests",
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "jsonrpc",
+			Name:      "errors_total",
+			Help:      "Number of jsonrpc requests that returned an error",
+		}, []string{"method"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "jsonrpc",
+			Name:      "request_duration_seconds",
+			Help:      "Time to handle the jsonrpc requests",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}, []string{"method"}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "jsonrpc",
+			Name:      "request_size_bytes",
+			Help:      "Size of the jsonrpc requests",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"method"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "jsonrpc",
+			Name:      "response_size_bytes",
+			Help:      "Size of the jsonrpc responses",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, []string{"method"}),
+	}
+}
+
+func (m *metrics) register(registerer prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{
+		m.requests,
+		m.errors,
+		m.duration,
+		m.requestSize,
+		m.responseSize,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// observe records the request in the metrics and in the access log
+func (d *Dispatcher) observe(client string, req Request, size int, duration time.Duration, resp []byte, err error) {
+	if d.metrics != nil {
+		method := d.methodLabel(req.Method)
+
+		d.metrics.requests.WithLabelValues(method).Inc()
+		if err != nil {
+			d.metrics.errors.WithLabelValues(method).Inc()
+		}
+		d.metrics.duration.WithLabelValues(method).Observe(duration.Seconds())
+		d.metrics.requestSize.WithLabelValues(method).Observe(float64(size))
+		d.metrics.responseSize.WithLabelValues(method).Observe(float64(len(resp)))
+	}
+
+	if d.accessLog != nil {
+		args := []interface{}{
+			"client", client,
+			"method", req.Method,
+			"id", req.ID,
+			"duration", duration.String(),
+			"request_size", size,
+			"response_size", len(resp),
+		}
+		if err != nil {
+			args = append(args, "err", err.Error())
+		}
+		d.accessLog.Info("request", args...)
+	}
+}
+
+func (d *Dispatcher) methodLabel(method string) string {
+	if method == "eth_subscribe" || method == "eth_unsubscribe" {
+		return method
+	}
+	if _, _, err := d.getFnHandler(Request{Method: method}); err != nil {
+		return unknownMethod
+	}
+	return method
+}
+
+// responseError returns the error of a response of a batch, if any
+func responseError(resp []byte) error {
+	var obj struct {
+		Error *ErrorObject `json:"error"`
+	}
+	if err := json.Unmarshal(resp, &obj); err != nil || obj.Error == nil {
+		return nil
+	}
+	return obj.Error
+}
@@ -0,0 +1,60 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_Metrics(t *testing.T) {
+	var buf bytes.Buffer
+
+	s := newTestDispatcher(hclog.NewNullLogger(), newMockStore())
+	s.metrics = newMetrics()
+	assert.NoError(t, s.metrics.register(prometheus.NewRegistry()))
+	s.accessLog = hclog.New(&hclog.LoggerOptions{Output: &buf, JSONFormat: true})
+
+	_, err := s.Handle([]byte(`{"id":1,"method":"eth_blockNumber","params":[]}`), "a")
+	assert.NoError(t, err)
+
+	// the methods that do not exist share a label
+	_, err = s.Handle([]byte(`{"id":2,"method":"eth_foo","params":[]}`), "a")
+	assert.Error(t, err)
+	_, err = s.Handle([]byte(`{"id":3,"method":"bar_foo","params":[]}`), "a")
+	assert.Error(t, err)
+
+	// each request of a batch is recorded
+	_, err = s.Handle([]byte(`[
+		{"id":4,"method":"eth_blockNumber","params":[]},
+		{"id":5,"method":"eth_getBlockByNumber","params":[]}
+	]`), "b")
+	assert.NoError(t, err)
+
+	m := s.metrics
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("eth_blockNumber")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.errors.WithLabelValues("eth_blockNumber")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(unknownMethod)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.errors.WithLabelValues(unknownMethod)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.errors.WithLabelValues("eth_getBlockByNumber")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.duration))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.responseSize))
+
+	// the access log has an entry for each request
+	dec := json.NewDecoder(&buf)
+	entries := []map[string]interface{}{}
+	for dec.More() {
+		var entry map[string]interface{}
+		assert.NoError(t, dec.Decode(&entry))
+		entries = append(entries, entry)
+	}
+	assert.Len(t, entries, 5)
+	assert.Equal(t, "a", entries[0]["client"])
+	assert.Equal(t, "eth_blockNumber", entries[0]["method"])
+	assert.NotNil(t, entries[1]["err"])
+	assert.Equal(t, "b", entries[4]["client"])
+}
@@ -1,5 +1,9 @@
 package jsonrpc
 
+import (
+	"strconv"
+)
+
 // Net is the net jsonrpc endpoint
 type Net struct {
 	d *Dispatcher
@@ -7,15 +11,16 @@ type Net struct {
 
 // Version returns the current network id
 func (n *Net) Version() (interface{}, error) {
-	return nil, nil
+	return strconv.FormatUint(n.d.chainID, 10), nil
 }
 
 // Listening returns true if client is actively listening for network connections
 func (n *Net) Listening() (interface{}, error) {
-	return nil, nil
+	// the node always listens for the connections of the peers
+	return true, nil
 }
 
 // PeerCount returns number of peers currently connected to the client
 func (n *Net) PeerCount() (interface{}, error) {
-	return nil, nil
+	return argUintPtr(uint64(n.d.store.PeerCount())), nil
 }
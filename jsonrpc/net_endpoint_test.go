@@ -0,0 +1,38 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStorePeers struct {
+	nullBlockchainInterface
+}
+
+func (m *mockStorePeers) PeerCount() int {
+	return 3
+}
+
+func TestNetEndpoint(t *testing.T) {
+	s := newTestDispatcher(hclog.NewNullLogger(), &mockStorePeers{})
+	s.chainID = 100
+
+	cases := []struct {
+		method string
+		res    interface{}
+	}{
+		{"net_version", "100"},
+		{"net_listening", true},
+		{"net_peerCount", "0x3"},
+	}
+	for _, c := range cases {
+		resp, err := s.Handle([]byte(`{"method": "`+c.method+`", "params": []}`), "")
+		assert.NoError(t, err)
+
+		var res interface{}
+		assert.NoError(t, expectJSONResult(resp, &res))
+		assert.Equal(t, c.res, res)
+	}
+}
@@ -1,9 +1,11 @@
 package jsonrpc
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 
+	"github.com/0xPolygon/minimal/helper/hex"
 	"github.com/0xPolygon/minimal/types"
 )
 
@@ -16,6 +18,99 @@ type LogFilter struct {
 
 	Addresses []types.Address
 	Topics    [][]types.Hash
+
+	// paginated is set if the query has a cursor, the cursor
+	// is nil in the first page
+	paginated bool
+	cursor    *logCursor
+}
+
+func limitExceeded(format string, args ...interface{}) error {
+	return &ErrorObject{Code: -32005, Message: fmt.Sprintf(format, args...)}
+}
+
+// logCursor is the position of the first log of a page of a query,
+// the index is the position of the log in the block
+type logCursor struct {
+	number uint64
+	index  uint64
+}
+
+func (c *logCursor) String() string {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], c.number)
+	binary.BigEndian.PutUint64(buf[8:], c.index)
+	return hex.EncodeToHex(buf)
+}
+
+func parseLogCursor(str string) (*logCursor, error) {
+	buf, err := hex.DecodeHex(str)
+	if err != nil || len(buf) != 16 {
+		return nil, fmt.Errorf("invalid cursor '%s'", str)
+	}
+	return &logCursor{
+		number: binary.BigEndian.Uint64(buf[:8]),
+		index:  binary.BigEndian.Uint64(buf[8:]),
+	}, nil
+}
+
+// logsPage are the logs of a query up to the limit of results
+type logsPage struct {
+	paginated bool
+	cursor    *logCursor
+	limit     uint64
+
+	logs []*Log
+
+	// next is the cursor of the next page, full is set
+	// if the query has more logs than the limit
+	next *logCursor
+	full bool
+}
+
+func newLogsPage(filter *LogFilter, limit uint64) *logsPage {
+	return &logsPage{
+		paginated: filter.paginated,
+		cursor:    filter.cursor,
+		limit:     limit,
+		logs:      []*Log{},
+	}
+}
+
+// add adds the logs of the block after the cursor until the page is full
+func (p *logsPage) add(header *types.Header, logs []*Log) {
+	for _, log := range logs {
+		if p.cursor != nil && header.Number == p.cursor.number && uint64(log.LogIndex) < p.cursor.index {
+			continue
+		}
+		if p.limit != 0 && uint64(len(p.logs)) == p.limit {
+			p.next = &logCursor{number: header.Number, index: uint64(log.LogIndex)}
+			p.full = true
+			return
+		}
+		p.logs = append(p.logs, log)
+	}
+}
+
+type logsPageResult struct {
+	Logs   []*Log `json:"logs"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// result returns the logs of the page, the paginated queries get the
+// logs with the cursor of the next page if there are more blocks to scan
+func (p *logsPage) result() (interface{}, error) {
+	if !p.paginated {
+		if p.full {
+			return nil, limitExceeded("query returned more than %d logs, use a cursor to paginate it", p.limit)
+		}
+		return p.logs, nil
+	}
+	res := &logsPageResult{Logs: p.logs}
+	if p.next != nil {
+		res.Cursor = p.next.String()
+	}
+	return res, nil
 }
 
 func (l *LogFilter) addTopicSet(set ...string) error {
@@ -67,13 +162,27 @@ func (l *LogFilter) UnmarshalJSON(data []byte) error {
 		ToBlock   string        `json:"toBlock"`
 		Address   interface{}   `json:"address"`
 		Topics    []interface{} `json:"topics"`
+		Cursor    *string       `json:"cursor"`
 	}
 	err := json.Unmarshal(data, &obj)
 	if err != nil {
 		return err
 	}
 
+	if obj.Cursor != nil {
+		// an empty cursor is the first page
+		l.paginated = true
+		if *obj.Cursor != "" {
+			if l.cursor, err = parseLogCursor(*obj.Cursor); err != nil {
+				return err
+			}
+		}
+	}
+
 	l.BlockHash = obj.BlockHash
+	if l.BlockHash != nil && (obj.FromBlock != "" || obj.ToBlock != "") {
+		return fmt.Errorf("blockHash cannot be used with fromBlock or toBlock")
+	}
 
 	if obj.FromBlock == "" {
 		l.fromBlock = LatestBlockNumber
@@ -157,6 +266,27 @@ func (l *LogFilter) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// bloomFilter returns the filter as groups of items for the bloom index. The addresses
+// are the first group and every topic position is a group
+func (l *LogFilter) bloomFilter() [][][]byte {
+	filter := [][][]byte{}
+
+	addrs := [][]byte{}
+	for _, addr := range l.Addresses {
+		addrs = append(addrs, addr.Bytes())
+	}
+	filter = append(filter, addrs)
+
+	for _, sub := range l.Topics {
+		topics := [][]byte{}
+		for _, topic := range sub {
+			topics = append(topics, topic.Bytes())
+		}
+		filter = append(filter, topics)
+	}
+	return filter
+}
+
 // Match returns whether the receipt includes topics for this filter
 func (l *LogFilter) Match(log *types.Log) bool {
 	// check addresses
@@ -165,6 +295,7 @@ func (l *LogFilter) Match(log *types.Log) bool {
 		for _, addr := range l.Addresses {
 			if addr == log.Address {
 				match = true
+				break
 			}
 		}
 		if !match {
@@ -189,3 +320,29 @@ func (l *LogFilter) Match(log *types.Log) bool {
 	}
 	return true
 }
+
+// filterLogs returns the logs of the receipts of the block that match the filter,
+// the log index is the position of the log in the block
+func (l *LogFilter) filterLogs(header *types.Header, receipts []*types.Receipt, removed bool) []*Log {
+	res := []*Log{}
+	logIndx := 0
+	for indx, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if l.Match(log) {
+				res = append(res, &Log{
+					Address:     log.Address,
+					Topics:      log.Topics,
+					Data:        argBytes(log.Data),
+					BlockNumber: argUint64(header.Number),
+					BlockHash:   header.Hash,
+					TxHash:      receipt.TxHash,
+					TxIndex:     argUint64(indx),
+					LogIndex:    argUint64(logIndx),
+					Removed:     removed,
+				})
+			}
+			logIndx++
+		}
+	}
+	return res
+}
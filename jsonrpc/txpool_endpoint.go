@@ -0,0 +1,90 @@
+package jsonrpc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+// TxPool is the txpool jsonrpc endpoint
+type TxPool struct {
+	d *Dispatcher
+}
+
+type txPoolContent struct {
+	Pending map[types.Address]map[string]*transaction `json:"pending"`
+	Queued  map[types.Address]map[string]*transaction `json:"queued"`
+}
+
+// Content returns the pending and queued transactions by sender and nonce
+func (t *TxPool) Content() (interface{}, error) {
+	pending, queued := t.d.store.Content()
+
+	convert := func(content map[types.Address][]*types.Transaction) map[types.Address]map[string]*transaction {
+		res := map[types.Address]map[string]*transaction{}
+		for addr, txns := range content {
+			res[addr] = map[string]*transaction{}
+			for _, txn := range txns {
+				res[addr][strconv.FormatUint(txn.Nonce, 10)] = toTransaction(txn)
+			}
+		}
+		return res
+	}
+	return &txPoolContent{
+		Pending: convert(pending),
+		Queued:  convert(queued),
+	}, nil
+}
+
+type txPoolInspect struct {
+	Pending map[types.Address]map[string]string `json:"pending"`
+	Queued  map[types.Address]map[string]string `json:"queued"`
+}
+
+// Inspect returns a summary of the pending and queued transactions by sender and nonce
+func (t *TxPool) Inspect() (interface{}, error) {
+	pending, queued := t.d.store.Content()
+
+	convert := func(content map[types.Address][]*types.Transaction) map[types.Address]map[string]string {
+		res := map[types.Address]map[string]string{}
+		for addr, txns := range content {
+			res[addr] = map[string]string{}
+			for _, txn := range txns {
+				res[addr][strconv.FormatUint(txn.Nonce, 10)] = inspectTxn(txn)
+			}
+		}
+		return res
+	}
+	return &txPoolInspect{
+		Pending: convert(pending),
+		Queued:  convert(queued),
+	}, nil
+}
+
+func inspectTxn(txn *types.Transaction) string {
+	to := "contract creation"
+	if txn.To != nil {
+		to = txn.To.String()
+	}
+	return fmt.Sprintf("%s: %s wei + %d gas × %s wei", to, txn.Value, txn.Gas, txn.GasPrice)
+}
+
+type txPoolStatus struct {
+	Pending argUint64 `json:"pending"`
+	Queued  argUint64 `json:"queued"`
+}
+
+// Status returns the number of pending and queued transactions
+func (t *TxPool) Status() (interface{}, error) {
+	pending, queued := t.d.store.Content()
+
+	res := &txPoolStatus{}
+	for _, txns := range pending {
+		res.Pending += argUint64(len(txns))
+	}
+	for _, txns := range queued {
+		res.Queued += argUint64(len(txns))
+	}
+	return res, nil
+}
@@ -0,0 +1,75 @@
+package jsonrpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTxPoolStore struct {
+	*mockStore
+
+	pending map[types.Address][]*types.Transaction
+	queued  map[types.Address][]*types.Transaction
+}
+
+func (m *mockTxPoolStore) Content() (map[types.Address][]*types.Transaction, map[types.Address][]*types.Transaction) {
+	return m.pending, m.queued
+}
+
+func TestTxPoolEndpoint(t *testing.T) {
+	addr1, addr2 := types.Address{0x1}, types.Address{0x2}
+	newTxn := func(from types.Address, nonce uint64) *types.Transaction {
+		return &types.Transaction{
+			From:     from,
+			Nonce:    nonce,
+			To:       &addr2,
+			Gas:      21000,
+			GasPrice: big.NewInt(2),
+			Value:    big.NewInt(10),
+		}
+	}
+
+	store := &mockTxPoolStore{
+		mockStore: newMockStore(),
+		pending: map[types.Address][]*types.Transaction{
+			addr1: {newTxn(addr1, 0), newTxn(addr1, 1)},
+		},
+		queued: map[types.Address][]*types.Transaction{
+			addr2: {newTxn(addr2, 5)},
+		},
+	}
+	s := newTestDispatcher(hclog.NewNullLogger(), store)
+
+	resp, err := s.Handle([]byte(`{"method": "txpool_status", "params": []}`), "")
+	assert.NoError(t, err)
+
+	var status map[string]string
+	assert.NoError(t, expectJSONResult(resp, &status))
+	assert.Equal(t, map[string]string{"pending": "0x2", "queued": "0x1"}, status)
+
+	resp, err = s.Handle([]byte(`{"method": "txpool_content", "params": []}`), "")
+	assert.NoError(t, err)
+
+	var content struct {
+		Pending map[types.Address]map[string]map[string]interface{}
+		Queued  map[types.Address]map[string]map[string]interface{}
+	}
+	assert.NoError(t, expectJSONResult(resp, &content))
+	assert.Len(t, content.Pending[addr1], 2)
+	assert.Equal(t, "0x1", content.Pending[addr1]["1"]["nonce"])
+	assert.Equal(t, addr2.String(), content.Queued[addr2]["5"]["from"])
+
+	resp, err = s.Handle([]byte(`{"method": "txpool_inspect", "params": []}`), "")
+	assert.NoError(t, err)
+
+	var inspect struct {
+		Pending map[types.Address]map[string]string
+		Queued  map[types.Address]map[string]string
+	}
+	assert.NoError(t, expectJSONResult(resp, &inspect))
+	assert.Equal(t, addr2.String()+": 10 wei + 21000 gas × 2 wei", inspect.Queued[addr2]["5"])
+}
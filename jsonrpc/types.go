@@ -5,7 +5,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/0xPolygon/minimal/crypto"
 	"github.com/0xPolygon/minimal/helper/hex"
+	"github.com/0xPolygon/minimal/state"
 	"github.com/0xPolygon/minimal/types"
 )
 
@@ -21,6 +23,11 @@ type transaction struct {
 	S        argBytes       `json:"s"`
 	Hash     types.Hash     `json:"hash"`
 	From     types.Address  `json:"from"`
+
+	// the block of the txn, they are null if the txn is pending
+	BlockHash   *types.Hash `json:"blockHash"`
+	BlockNumber *argUint64  `json:"blockNumber"`
+	TxIndex     *argUint64  `json:"transactionIndex"`
 }
 
 func toTransaction(t *types.Transaction) *transaction {
@@ -39,29 +46,31 @@ func toTransaction(t *types.Transaction) *transaction {
 	}
 }
 
-type block struct {
-	ParentHash   types.Hash     `json:"parentHash"`
-	Sha3Uncles   types.Hash     `json:"sha3Uncles"`
-	Miner        types.Address  `json:"miner"`
-	StateRoot    types.Hash     `json:"stateRoot"`
-	TxRoot       types.Hash     `json:"transactionsRoot"`
-	ReceiptsRoot types.Hash     `json:"receiptsRoot"`
-	LogsBloom    types.Bloom    `json:"logsBloom"`
-	Difficulty   argUint64      `json:"difficulty"`
-	Number       argUint64      `json:"number"`
-	GasLimit     argUint64      `json:"gasLimit"`
-	GasUsed      argUint64      `json:"gasUsed"`
-	Timestamp    argUint64      `json:"timestamp"`
-	ExtraData    argBytes       `json:"extraData"`
-	MixHash      types.Hash     `json:"mixHash"`
-	Nonce        types.Nonce    `json:"nonce"`
-	Hash         types.Hash     `json:"hash"`
-	Transactions []*transaction `json:"transactions"`
+type header struct {
+	ParentHash   types.Hash    `json:"parentHash"`
+	Sha3Uncles   types.Hash    `json:"sha3Uncles"`
+	Miner        types.Address `json:"miner"`
+	StateRoot    types.Hash    `json:"stateRoot"`
+	TxRoot       types.Hash    `json:"transactionsRoot"`
+	ReceiptsRoot types.Hash    `json:"receiptsRoot"`
+	LogsBloom    types.Bloom   `json:"logsBloom"`
+	Difficulty   argUint64     `json:"difficulty"`
+	Number       argUint64     `json:"number"`
+	GasLimit     argUint64     `json:"gasLimit"`
+	GasUsed      argUint64     `json:"gasUsed"`
+	Timestamp    argUint64     `json:"timestamp"`
+	ExtraData    argBytes      `json:"extraData"`
+	MixHash      types.Hash    `json:"mixHash"`
+	Nonce        types.Nonce   `json:"nonce"`
+	Hash         types.Hash    `json:"hash"`
+
+	// BaseFee is omitted in the blocks before the London fork
+	BaseFee *argBig `json:"baseFeePerGas,omitempty"`
 }
 
-func toBlock(b *types.Block) *block {
-	h := b.Header
-	res := &block{
+// toHeader returns the header as in the newHeads subscriptions
+func toHeader(h *types.Header) *header {
+	res := &header{
 		ParentHash:   h.ParentHash,
 		Sha3Uncles:   h.Sha3Uncles,
 		Miner:        h.Miner,
@@ -78,10 +87,39 @@ func toBlock(b *types.Block) *block {
 		MixHash:      h.MixHash,
 		Nonce:        h.Nonce,
 		Hash:         h.Hash,
+	}
+	if h.BaseFee != nil {
+		res.BaseFee = argBigPtr(h.BaseFee)
+	}
+	return res
+}
+
+// toBlockTransaction returns the txn of the index in the block of the header
+func toBlockTransaction(t *types.Transaction, h *types.Header, index int) *transaction {
+	res := toTransaction(t)
+	res.BlockHash = &h.Hash
+	res.BlockNumber = argUintPtr(h.Number)
+	res.TxIndex = argUintPtr(uint64(index))
+	return res
+}
+
+type block struct {
+	header
+	Transactions []*transaction `json:"transactions"`
+	Uncles       []types.Hash   `json:"uncles"`
+}
+
+func toBlock(b *types.Block) *block {
+	res := &block{
+		header:       *toHeader(b.Header),
 		Transactions: []*transaction{},
+		Uncles:       []types.Hash{},
+	}
+	for indx, txn := range b.Transactions {
+		res.Transactions = append(res.Transactions, toBlockTransaction(txn, b.Header, indx))
 	}
-	for _, txn := range b.Transactions {
-		res.Transactions = append(res.Transactions, toTransaction(txn))
+	for _, uncle := range b.Uncles {
+		res.Uncles = append(res.Uncles, uncle.Hash)
 	}
 	return res
 }
@@ -102,6 +140,42 @@ type receipt struct {
 	ToAddr            *types.Address       `json:"to"`
 }
 
+// toReceipt returns the receipt of the txn of the index in the block,
+// logIndex is the index in the block of the first log of the receipt
+func toReceipt(b *types.Block, indx int, raw *types.Receipt, logIndex int) *receipt {
+	txn := b.Transactions[indx]
+
+	logs := make([]*Log, len(raw.Logs))
+	for i, elem := range raw.Logs {
+		logs[i] = &Log{
+			Address:     elem.Address,
+			Topics:      elem.Topics,
+			Data:        argBytes(elem.Data),
+			BlockHash:   b.Hash(),
+			BlockNumber: argUint64(b.Number()),
+			TxHash:      txn.Hash,
+			TxIndex:     argUint64(indx),
+			LogIndex:    argUint64(logIndex + i),
+			Removed:     false,
+		}
+	}
+	return &receipt{
+		Root:              raw.Root,
+		CumulativeGasUsed: argUint64(raw.CumulativeGasUsed),
+		LogsBloom:         raw.LogsBloom,
+		Status:            raw.Status,
+		TxHash:            txn.Hash,
+		TxIndex:           argUint64(indx),
+		BlockHash:         b.Hash(),
+		BlockNumber:       argUint64(b.Number()),
+		GasUsed:           argUint64(raw.GasUsed),
+		ContractAddress:   raw.ContractAddress,
+		FromAddr:          txn.From,
+		ToAddr:            txn.To,
+		Logs:              logs,
+	}
+}
+
 type Log struct {
 	Address     types.Address `json:"address"`
 	Topics      []types.Hash  `json:"topics"`
@@ -211,6 +285,125 @@ func encodeToHex(b []byte) []byte {
 }
 
 // txnArgs is the transaction argument for the rpc endpoints
+type accountProof struct {
+	Address      types.Address   `json:"address"`
+	AccountProof []argBytes      `json:"accountProof"`
+	Balance      *argBig         `json:"balance"`
+	CodeHash     types.Hash      `json:"codeHash"`
+	Nonce        argUint64       `json:"nonce"`
+	StorageHash  types.Hash      `json:"storageHash"`
+	StorageProof []*storageProof `json:"storageProof"`
+}
+
+type storageProof struct {
+	Key   types.Hash `json:"key"`
+	Value *argBig    `json:"value"`
+	Proof []argBytes `json:"proof"`
+}
+
+func toProofNodes(proof [][]byte) []argBytes {
+	res := make([]argBytes, len(proof))
+	for i, node := range proof {
+		res[i] = argBytes(node)
+	}
+	return res
+}
+
+func toAccountProof(addr types.Address, proof *state.AccountProof) *accountProof {
+	res := &accountProof{
+		Address:      addr,
+		AccountProof: toProofNodes(proof.Proof),
+		StorageProof: []*storageProof{},
+	}
+	if account := proof.Account; account != nil {
+		res.Balance = argBigPtr(account.Balance)
+		res.CodeHash = types.BytesToHash(account.CodeHash)
+		res.Nonce = argUint64(account.Nonce)
+		res.StorageHash = account.Root
+	} else {
+		// the values of an empty account
+		res.Balance = argBigPtr(big.NewInt(0))
+		res.CodeHash = types.BytesToHash(crypto.Keccak256(nil))
+		res.StorageHash = types.EmptyRootHash
+	}
+	for _, p := range proof.StorageProof {
+		res.StorageProof = append(res.StorageProof, &storageProof{
+			Key:   p.Key,
+			Value: argBigPtr(new(big.Int).SetBytes(p.Value)),
+			Proof: toProofNodes(p.Proof),
+		})
+	}
+	return res
+}
+
+// stateOverride are the accounts replaced before executing eth_call
+type stateOverride map[types.Address]*overrideAccount
+
+type overrideAccount struct {
+	Nonce     *argUint64                 `json:"nonce"`
+	Code      *argBytes                  `json:"code"`
+	Balance   *argBig                    `json:"balance"`
+	State     *map[types.Hash]types.Hash `json:"state"`
+	StateDiff *map[types.Hash]types.Hash `json:"stateDiff"`
+}
+
+func (s *stateOverride) toState() state.StateOverride {
+	if s == nil {
+		return nil
+	}
+	res := state.StateOverride{}
+	for addr, o := range *s {
+		account := &state.OverrideAccount{}
+		if o.Nonce != nil {
+			nonce := uint64(*o.Nonce)
+			account.Nonce = &nonce
+		}
+		if o.Code != nil {
+			account.Code = []byte(*o.Code)
+		}
+		if o.Balance != nil {
+			account.Balance = (*big.Int)(o.Balance)
+		}
+		if o.State != nil {
+			account.State = *o.State
+		}
+		if o.StateDiff != nil {
+			account.StateDiff = *o.StateDiff
+		}
+		res[addr] = account
+	}
+	return res
+}
+
+// blockOverride are the fields of the header replaced before executing eth_call
+type blockOverride struct {
+	Number     *argUint64     `json:"number"`
+	Time       *argUint64     `json:"time"`
+	GasLimit   *argUint64     `json:"gasLimit"`
+	Difficulty *argUint64     `json:"difficulty"`
+	Coinbase   *types.Address `json:"coinbase"`
+}
+
+func (b *blockOverride) apply(header *types.Header) *types.Header {
+	header = header.Copy()
+	if b.Number != nil {
+		header.Number = uint64(*b.Number)
+	}
+	if b.Time != nil {
+		header.Timestamp = uint64(*b.Time)
+	}
+	if b.GasLimit != nil {
+		header.GasLimit = uint64(*b.GasLimit)
+	}
+	if b.Difficulty != nil {
+		header.Difficulty = uint64(*b.Difficulty)
+	}
+	if b.Coinbase != nil {
+		header.Miner = *b.Coinbase
+	}
+	return header
+}
+
 type txnArgs struct {
 	From     *types.Address
 	To       *types.Address
@@ -1,8 +1,12 @@
 package jsonrpc
 
 import (
+	"fmt"
+	"runtime"
+
 	"github.com/0xPolygon/minimal/helper/hex"
 	"github.com/0xPolygon/minimal/helper/keccak"
+	"github.com/0xPolygon/minimal/version"
 )
 
 // Web3 is the web3 jsonrpc endpoint
@@ -12,7 +16,13 @@ type Web3 struct {
 
 // ClientVersion returns the current client version
 func (w *Web3) ClientVersion() (interface{}, error) {
-	return nil, nil
+	return clientVersion(), nil
+}
+
+// clientVersion is the name, the version and the platform of
+// the client, i.e. minimal/v0.1.0-dev/linux-amd64/go1.14
+func clientVersion() string {
+	return fmt.Sprintf("minimal/v%s/%s-%s/%s", version.GetVersion(), runtime.GOOS, runtime.GOARCH, runtime.Version())
 }
 
 // Sha3 returns Keccak-256 (not the standardized SHA3-256) of the given data
@@ -1,6 +1,7 @@
 package jsonrpc
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
@@ -14,10 +15,25 @@ func TestWeb3EndpointSha3(t *testing.T) {
 	resp, err := s.Handle([]byte(`{
 		"method": "web3_sha3",
 		"params": ["0x68656c6c6f20776f726c64"]
-	}`))
+	}`), "")
 	assert.NoError(t, err)
 
 	var res string
 	assert.NoError(t, expectJSONResult(resp, &res))
 	assert.Equal(t, res, "0x47173285a8d7341e5e972fc677286384f802f8ef42a5ec5f03bbfa254cb01fad")
 }
+
+func TestWeb3EndpointClientVersion(t *testing.T) {
+	s := newDispatcher(hclog.NewNullLogger(), newMockStore(), 0)
+
+	resp, err := s.Handle([]byte(`{
+		"method": "web3_clientVersion",
+		"params": []
+	}`), "")
+	assert.NoError(t, err)
+
+	var res string
+	assert.NoError(t, expectJSONResult(resp, &res))
+	assert.Equal(t, clientVersion(), res)
+	assert.True(t, strings.HasPrefix(res, "minimal/v"))
+}
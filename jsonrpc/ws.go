@@ -0,0 +1,187 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsPingInterval is the interval to ping the websocket connections,
+	// the connections that do not answer in wsPongWait are closed
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 60 * time.Second
+
+	wsWriteWait = 10 * time.Second
+
+	// wsReadLimit is the maximum size in bytes of a websocket request
+	wsReadLimit = 5 * 1024 * 1024
+)
+
+func (j *JSONRPC) setupWS() error {
+	j.logger.Info("websocket server started", "addr", j.config.WSAddr.String())
+
+	lis, err := j.listen(j.config.WSAddr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", j.handleWs)
+
+	srv := http.Server{
+		Handler: mux,
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			j.logger.Error("closed websocket connection", "err", err)
+		}
+	}()
+	return nil
+}
+
+// wrapWsConn serializes the writes of the filters and of the
+// responses in the websocket connection
+type wrapWsConn struct {
+	lock sync.Mutex
+	conn *websocket.Conn
+}
+
+func (w *wrapWsConn) WriteMessage(b []byte) error {
+	return w.write(websocket.TextMessage, b)
+}
+
+func (w *wrapWsConn) write(messageType int, b []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return w.conn.WriteMessage(messageType, b)
+}
+
+// ping pings the connection until the close channel is closed
+func (w *wrapWsConn) ping(closeCh chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.write(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-closeCh:
+			return
+		}
+	}
+}
+
+// acquireWs reserves a websocket connection, it returns false if the
+// limit of connections is reached
+func (j *JSONRPC) acquireWs() bool {
+	j.wsLock.Lock()
+	defer j.wsLock.Unlock()
+
+	if j.config.WSMaxConns != 0 && j.wsConns >= j.config.WSMaxConns {
+		return false
+	}
+	j.wsConns++
+	return true
+}
+
+func (j *JSONRPC) releaseWs() {
+	j.wsLock.Lock()
+	defer j.wsLock.Unlock()
+
+	j.wsConns--
+}
+
+func (j *JSONRPC) upgrader() *websocket.Upgrader {
+	upgrader := &websocket.Upgrader{}
+	if len(j.config.WSOrigins) != 0 {
+		upgrader.CheckOrigin = j.checkOrigin
+	}
+	return upgrader
+}
+
+// checkOrigin checks that the origin of the request is allowed, the requests
+// without an origin do not come from a browser
+func (j *JSONRPC) checkOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	return originAllowed(origin, j.config.WSOrigins)
+}
+
+// originAllowed checks if the origin or its host is in the allowed origins
+func originAllowed(origin string, allowed []string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	for _, item := range allowed {
+		if item == "*" || strings.EqualFold(item, origin) || strings.EqualFold(item, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *JSONRPC) handleWs(w http.ResponseWriter, req *http.Request) {
+	if !j.acquireWs() {
+		http.Error(w, "too many websocket connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer j.releaseWs()
+
+	c, err := j.upgrader().Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	wrapConn := &wrapWsConn{conn: c}
+	client := clientIP(req.RemoteAddr)
+
+	// remove the subscriptions of the connection once it is closed
+	defer j.dispatcher.RemoveWs(wrapConn)
+
+	readLimit := int64(wsReadLimit)
+	if j.config.MaxRequestSize != 0 {
+		readLimit = int64(j.config.MaxRequestSize)
+	}
+	c.SetReadLimit(readLimit)
+	c.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.SetPongHandler(func(string) error {
+		return c.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go wrapConn.ping(closeCh)
+
+	for {
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				j.logger.Debug("websocket connection closed", "err", err)
+			}
+			break
+		}
+		go func() {
+			resp, err := j.dispatcher.HandleWs(message, client, wrapConn)
+			if err != nil {
+				var req Request
+				json.Unmarshal(message, &req)
+				resp = errorResponse(req.ID, err)
+			}
+			wrapConn.WriteMessage(resp)
+		}()
+	}
+}
@@ -0,0 +1,108 @@
+package jsonrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWsServer(t *testing.T, config *Config) (*JSONRPC, string) {
+	config.Store = newMockStore()
+
+	j := &JSONRPC{
+		logger:     hclog.NewNullLogger(),
+		config:     config,
+		dispatcher: newDispatcher(hclog.NewNullLogger(), config.Store, 0),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(j.handleWs))
+	t.Cleanup(srv.Close)
+
+	return j, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestWs_Subscribe(t *testing.T) {
+	j, url := newTestWsServer(t, &Config{})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+
+	readMsg := func() string {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, msg, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		return string(msg)
+	}
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id":1,"method":"eth_subscribe","params":["newHeads"]}`)))
+	assert.Contains(t, readMsg(), `"id":1`)
+
+	// the errors are jsonrpc responses
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id":2,"method":"eth_subscribe","params":["unknown"]}`)))
+	assert.Contains(t, readMsg(), `"error"`)
+
+	// the logs subscription does not require the criteria
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"id":3,"method":"eth_subscribe","params":["logs"]}`)))
+	assert.Contains(t, readMsg(), `"result"`)
+
+	fm := j.dispatcher.(*Dispatcher).filterManager
+	fm.lock.Lock()
+	assert.Len(t, fm.filters, 2)
+	fm.lock.Unlock()
+
+	// the subscriptions are removed once the connection is closed
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		fm.lock.Lock()
+		defer fm.lock.Unlock()
+		return len(fm.filters) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWs_MaxConns(t *testing.T) {
+	_, url := newTestWsServer(t, &Config{WSMaxConns: 1})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// the connection is released once it is closed
+	conn.Close()
+	assert.Eventually(t, func() bool {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWs_Origins(t *testing.T) {
+	dial := func(url, origin string) error {
+		conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": []string{origin}})
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	}
+
+	// only the same host is allowed by default
+	_, url := newTestWsServer(t, &Config{})
+	assert.Error(t, dial(url, "http://example.com"))
+
+	_, url = newTestWsServer(t, &Config{WSOrigins: []string{"example.com"}})
+	assert.NoError(t, dial(url, "http://example.com"))
+	assert.Error(t, dial(url, "http://other.com"))
+
+	_, url = newTestWsServer(t, &Config{WSOrigins: []string{"*"}})
+	assert.NoError(t, dial(url, "http://other.com"))
+}
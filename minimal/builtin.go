@@ -1,16 +1,26 @@
 package minimal
 
 import (
+	consensusClique "github.com/0xPolygon/minimal/consensus/clique"
 	consensusDev "github.com/0xPolygon/minimal/consensus/dev"
 	consensusDummy "github.com/0xPolygon/minimal/consensus/dummy"
+	consensusEthash "github.com/0xPolygon/minimal/consensus/ethash"
 	consensusIBFT "github.com/0xPolygon/minimal/consensus/ibft"
 
 	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/types"
 )
 
 var consensusBackends = map[string]consensus.Factory{
-	// "ethash": consensusEthash.Factory,
-	"dev":   consensusDev.Factory,
-	"ibft":  consensusIBFT.Factory,
-	"dummy": consensusDummy.Factory,
+	"ethash": consensusEthash.Factory,
+	"dev":    consensusDev.Factory,
+	"ibft":   consensusIBFT.Factory,
+	"clique": consensusClique.Factory,
+	"dummy":  consensusDummy.Factory,
+}
+
+// consensusHeaderHashes are the hash functions of the headers of the
+// engines that do not hash the headers with the default one
+var consensusHeaderHashes = map[string]func(h *types.Header) types.Hash{
+	"ibft": consensusIBFT.HeaderHash,
 }
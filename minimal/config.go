@@ -3,8 +3,10 @@ package minimal
 import (
 	"net"
 
+	"github.com/0xPolygon/minimal/blockchain"
 	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/txpool"
 )
 
 // Config is used to parametrize the minimal client
@@ -15,9 +17,101 @@ type Config struct {
 	GRPCAddr    *net.TCPAddr
 	LibP2PAddr  *net.TCPAddr
 
+	// JSONRPCWSAddr is the address of the websocket jsonrpc server, the
+	// websocket endpoint is only served in the jsonrpc server if nil
+	JSONRPCWSAddr *net.TCPAddr
+
+	// JSONRPCWSMaxConns is the limit of websocket connections, zero means no limit
+	JSONRPCWSMaxConns int
+
+	// JSONRPCWSMaxSubscriptions is the limit of subscriptions of a websocket
+	// connection and JSONRPCMaxClientFilters the limit of filters and
+	// subscriptions of a client ip, zero means no limit
+	JSONRPCWSMaxSubscriptions int
+	JSONRPCMaxClientFilters   int
+
+	// JSONRPCWSOrigins are the origins allowed to open websocket connections
+	JSONRPCWSOrigins []string
+
+	// JSONRPCBatchLimit is the maximum number of requests of a batch, zero means no limit
+	JSONRPCBatchLimit uint64
+
+	// JSONRPCMaxRequestSize and JSONRPCMaxResponseSize are the maximum sizes
+	// in bytes of the jsonrpc requests and responses, zero means no limit
+	JSONRPCMaxRequestSize  uint64
+	JSONRPCMaxResponseSize uint64
+
+	// JSONRPCLogsBlockRange and JSONRPCLogsLimit are the maximum number of blocks
+	// and of logs of a query of logs, zero means no limit
+	JSONRPCLogsBlockRange uint64
+	JSONRPCLogsLimit      uint64
+
+	// JSONRPCCacheSize is the number of results of the old blocks,
+	// receipts and txns that are cached, zero disables the cache
+	JSONRPCCacheSize int
+
+	// JSONRPCAdmin enables the admin namespace to manage the peers of the node
+	JSONRPCAdmin bool
+
+	// JSONRPCAccessLog is the file of the json access log of the jsonrpc
+	// requests, it is disabled if empty
+	JSONRPCAccessLog string
+
+	// JSONRPCTLS serves the http and websocket jsonrpc servers over tls if set
+	JSONRPCTLS *TLSConfig
+
+	// JSONRPCCORSOrigins are the origins of the browsers allowed to send requests
+	JSONRPCCORSOrigins []string
+
+	// JSONRPCVHosts are the host names accepted by the http server, any if it is empty
+	JSONRPCVHosts []string
+
+	// JSONRPCRateLimit is the number of requests per second of each client ip
+	JSONRPCRateLimit uint64
+
+	// JSONRPCMethodRateLimits are the number of requests per second of each
+	// client ip to a method or a namespace
+	JSONRPCMethodRateLimits map[string]uint64
+
+	// JSONRPCAllow and JSONRPCDeny are the methods and namespaces
+	// served in the http and websocket servers
+	JSONRPCAllow []string
+	JSONRPCDeny  []string
+
+	// IPCPath is the path of the unix socket of the jsonrpc, it is disabled if empty
+	IPCPath string
+
+	// GRPCAuth secures the grpc endpoint, it is open if nil
+	GRPCAuth *GRPCAuthConfig
+
 	Network *network.Config
 	DataDir string
 	Seal    bool
+
+	Storage *blockchain.StorageConfig
+
+	// Consensus are the options of the consensus engine of the node,
+	// they override the ones in the chain params
+	Consensus map[string]interface{}
+
+	// BlockGasTarget is the gas limit the sealed blocks move toward
+	BlockGasTarget uint64
+
+	// PriceBump is the minimum percentage the gas price has to increase to
+	// replace a txn in the pool, it uses the default of the pool if zero
+	PriceBump uint64
+
+	// TxPoolLimits are the caps of the pool, the zero values use the defaults
+	TxPoolLimits txpool.Limits
+
+	// TxPoolPriority is the lane of the txns included first in the blocks
+	TxPoolPriority txpool.Priority
+
+	// TxPoolSimulation dry runs the incoming txns and rejects the ones that revert
+	TxPoolSimulation bool
+
+	// PrometheusAddr is the address of the metrics endpoint, it is disabled if nil
+	PrometheusAddr *net.TCPAddr
 }
 
 func DefaultConfig() *Config {
@@ -25,5 +119,18 @@ func DefaultConfig() *Config {
 		JSONRPCAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8545},
 		GRPCAddr:    &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 9632},
 		Network:     network.DefaultConfig(),
+
+		JSONRPCWSMaxConns: 100,
+		JSONRPCBatchLimit: 100,
+
+		JSONRPCWSMaxSubscriptions: 100,
+		JSONRPCMaxClientFilters:   1000,
+
+		JSONRPCMaxRequestSize:  5 * 1024 * 1024,
+		JSONRPCMaxResponseSize: 25 * 1024 * 1024,
+
+		JSONRPCLogsBlockRange: 10000,
+		JSONRPCLogsLimit:      10000,
+		JSONRPCCacheSize:      1024,
 	}
 }
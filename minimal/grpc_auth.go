@@ -0,0 +1,225 @@
+package minimal
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCAuthConfig secures the grpc endpoint of the operator services. The
+// methods are the full grpc names (i.e. /v1.IbftOperator/Propose) and they
+// can have wildcards (i.e. /v1.IbftOperator/* or * for all the methods)
+type GRPCAuthConfig struct {
+	// CertFile and KeyFile are the tls certificate of the endpoint
+	CertFile string
+	KeyFile  string
+
+	// SelfSigned serves the endpoint over tls with the self
+	// signed certificate of the node if there are no files
+	SelfSigned bool
+
+	// ClientCAFile verifies the certificates of the clients, the
+	// clients without a certificate of the CA are rejected
+	ClientCAFile string
+
+	// Clients are the methods that each client can call by the common name
+	// of its certificate. Any client with a certificate of the CA can call
+	// all the methods if it is empty
+	Clients map[string][]string
+
+	// Tokens are the methods that can be called with each bearer token
+	Tokens map[string][]string
+}
+
+// grpcServerOptions returns the options of the grpc server with the tls
+// credentials and the authorization of the config, if any. The self
+// signed certificate is in the tls dir
+func grpcServerOptions(config *GRPCAuthConfig, tlsDir string) ([]grpc.ServerOption, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	opts := []grpc.ServerOption{}
+	if config.CertFile != "" || config.KeyFile != "" || config.SelfSigned {
+		tlsConfig, err := config.tlsConfig(tlsDir)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if config.ClientCAFile != "" {
+		return nil, fmt.Errorf("the client certificates require the tls certificate of the grpc endpoint")
+	} else if len(config.Tokens) != 0 {
+		// the tokens would be sent in clear text
+		return nil, fmt.Errorf("the tokens require the tls certificate of the grpc endpoint")
+	}
+	for token := range config.Tokens {
+		if token == "" {
+			return nil, fmt.Errorf("the tokens cannot be empty")
+		}
+	}
+
+	if config.ClientCAFile != "" || len(config.Tokens) != 0 {
+		a := &grpcAuthorizer{
+			clients:   config.Clients,
+			tokens:    config.Tokens,
+			clientsCA: config.ClientCAFile != "",
+		}
+		opts = append(opts, grpc.UnaryInterceptor(a.unaryInterceptor), grpc.StreamInterceptor(a.streamInterceptor))
+	}
+	return opts, nil
+}
+
+func (c *GRPCAuthConfig) tlsConfig(tlsDir string) (*tls.Config, error) {
+	certConfig := &TLSConfig{
+		CertFile:   c.CertFile,
+		KeyFile:    c.KeyFile,
+		SelfSigned: c.SelfSigned,
+	}
+	tlsConfig, err := certConfig.tlsConfig(tlsDir)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: %v", err)
+	}
+	if c.ClientCAFile != "" {
+		pool, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		// the clients with a token can connect without a certificate
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		if len(c.Tokens) == 0 {
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool reads the pem certificates of the file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates in %s", file)
+	}
+	return pool, nil
+}
+
+// grpcAuthorizer authorizes the calls with the bearer token or the
+// client certificate of the caller
+type grpcAuthorizer struct {
+	clients   map[string][]string
+	tokens    map[string][]string
+	clientsCA bool
+}
+
+func (a *grpcAuthorizer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *grpcAuthorizer) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// authorize checks that the token or the client certificate of the call can call the method
+func (a *grpcAuthorizer) authorize(ctx context.Context, method string) error {
+	authenticated := false
+
+	if token, ok := bearerToken(ctx); ok {
+		methods, ok := a.lookupToken(token)
+		if !ok {
+			return status.Error(codes.Unauthenticated, "invalid token")
+		}
+		if matchMethod(methods, method) {
+			return nil
+		}
+		authenticated = true
+	}
+
+	if name, ok := clientName(ctx); ok && a.clientsCA {
+		if len(a.clients) == 0 {
+			return nil
+		}
+		if methods, ok := a.clients[name]; ok {
+			if matchMethod(methods, method) {
+				return nil
+			}
+		}
+		authenticated = true
+	}
+
+	if !authenticated {
+		return status.Error(codes.Unauthenticated, "a token or a client certificate is required")
+	}
+	return status.Errorf(codes.PermissionDenied, "not allowed to call %s", method)
+}
+
+// lookupToken compares the token with all the tokens in constant time
+func (a *grpcAuthorizer) lookupToken(token string) ([]string, bool) {
+	var res []string
+	found := false
+	for t, methods := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			res, found = methods, true
+		}
+	}
+	return res, found
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get("authorization") {
+		if strings.HasPrefix(v, "Bearer ") {
+			return strings.TrimPrefix(v, "Bearer "), true
+		}
+	}
+	return "", false
+}
+
+// clientName returns the common name of the verified client certificate, if any
+func clientName(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return info.State.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
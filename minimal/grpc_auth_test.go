@@ -0,0 +1,93 @@
+package minimal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAuth_Authorize(t *testing.T) {
+	a := &grpcAuthorizer{
+		tokens: map[string][]string{
+			"admin":  {"*"},
+			"reader": {"/v1.IbftOperator/Get*", "/v1.System/GetStatus"},
+		},
+		clients: map[string][]string{
+			"voter": {"/v1.IbftOperator/Propose"},
+		},
+		clientsCA: true,
+	}
+
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	}
+	withClient := func(name string) context.Context {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: name}}
+		return peer.NewContext(context.Background(), &peer.Peer{
+			AuthInfo: credentials.TLSInfo{
+				State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+			},
+		})
+	}
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{"no credentials", context.Background(), "/v1.System/GetStatus", codes.Unauthenticated},
+		{"invalid token", withToken("x"), "/v1.System/GetStatus", codes.Unauthenticated},
+		{"admin", withToken("admin"), "/v1.IbftOperator/Propose", codes.OK},
+		{"reader", withToken("reader"), "/v1.IbftOperator/GetSnapshot", codes.OK},
+		{"reader cannot vote", withToken("reader"), "/v1.IbftOperator/Propose", codes.PermissionDenied},
+		{"client", withClient("voter"), "/v1.IbftOperator/Propose", codes.OK},
+		{"client without the method", withClient("voter"), "/v1.IbftOperator/Unvote", codes.PermissionDenied},
+		{"unknown client", withClient("other"), "/v1.IbftOperator/Propose", codes.PermissionDenied},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.code, status.Code(a.authorize(c.ctx, c.method)))
+		})
+	}
+
+	// any client of the CA can call all the methods without the clients
+	a.clients = nil
+	assert.NoError(t, a.authorize(withClient("other"), "/v1.IbftOperator/Unvote"))
+}
+
+func TestGRPCAuth_Options(t *testing.T) {
+	opts, err := grpcServerOptions(nil, "")
+	assert.NoError(t, err)
+	assert.Len(t, opts, 0)
+
+	// the tokens cannot be used without tls
+	_, err = grpcServerOptions(&GRPCAuthConfig{Tokens: map[string][]string{"a": {"*"}}}, "")
+	assert.Error(t, err)
+
+	dir, err := ioutil.TempDir("/tmp", "minimal-grpc-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	opts, err = grpcServerOptions(&GRPCAuthConfig{SelfSigned: true, Tokens: map[string][]string{"a": {"*"}}}, dir)
+	assert.NoError(t, err)
+	assert.Len(t, opts, 3)
+
+	// the tokens cannot be empty
+	_, err = grpcServerOptions(&GRPCAuthConfig{SelfSigned: true, Tokens: map[string][]string{"": {"*"}}}, dir)
+	assert.Error(t, err)
+
+	_, err = grpcServerOptions(&GRPCAuthConfig{ClientCAFile: "ca.pem"}, "")
+	assert.Error(t, err)
+}
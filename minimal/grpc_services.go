@@ -0,0 +1,71 @@
+package minimal
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// lateServices serves the grpc services registered once the grpc server is
+// running, the server refuses new services after it starts. The consensus
+// engines activated at a fork of the chain register their services when
+// they are built
+type lateServices struct {
+	lock    sync.RWMutex
+	methods map[string]*lateMethod
+}
+
+type lateMethod struct {
+	impl   interface{}
+	unary  *grpc.MethodDesc
+	stream *grpc.StreamDesc
+}
+
+func newLateServices() *lateServices {
+	return &lateServices{
+		methods: map[string]*lateMethod{},
+	}
+}
+
+// RegisterService implements the grpc.ServiceRegistrar interface
+func (l *lateServices) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for i := range desc.Methods {
+		method := &desc.Methods[i]
+		l.methods[fmt.Sprintf("/%s/%s", desc.ServiceName, method.MethodName)] = &lateMethod{impl: impl, unary: method}
+	}
+	for i := range desc.Streams {
+		stream := &desc.Streams[i]
+		l.methods[fmt.Sprintf("/%s/%s", desc.ServiceName, stream.StreamName)] = &lateMethod{impl: impl, stream: stream}
+	}
+}
+
+// handle is the handler of the unknown services of the grpc server, the
+// stream interceptors of the server authorize the calls before
+func (l *lateServices) handle(srv interface{}, stream grpc.ServerStream) error {
+	name, ok := grpc.MethodFromServerStream(stream)
+	if !ok {
+		return status.Error(codes.Internal, "method not found in the stream")
+	}
+
+	l.lock.RLock()
+	method, ok := l.methods[name]
+	l.lock.RUnlock()
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", name)
+	}
+
+	if method.stream != nil {
+		return method.stream.Handler(method.impl, stream)
+	}
+	resp, err := method.unary.Handler(method.impl, stream.Context(), stream.RecvMsg, nil)
+	if err != nil {
+		return err
+	}
+	return stream.SendMsg(resp)
+}
@@ -0,0 +1,45 @@
+package minimal
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/0xPolygon/minimal/consensus/ibft"
+	ibftProto "github.com/0xPolygon/minimal/consensus/ibft/proto"
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLateServices(t *testing.T) {
+	late := newLateServices()
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(late.handle))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := ibftProto.NewIbftSignerClient(conn)
+
+	// the service is not registered yet
+	_, err = client.Address(context.Background(), &empty.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// the service is registered once the server is running
+	key, _ := crypto.GenerateKey()
+	signer := ibft.NewLocalSigner(key)
+	ibftProto.RegisterIbftSignerServer(late, ibft.NewSignerService(signer))
+
+	resp, err := client.Address(context.Background(), &empty.Empty{})
+	assert.NoError(t, err)
+	assert.Equal(t, signer.Address().String(), resp.Address)
+}
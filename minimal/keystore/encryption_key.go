@@ -0,0 +1,27 @@
+package keystore
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/0xPolygon/minimal/helper/hex"
+)
+
+// ReadEncryptionKey reads a hex encoded 32 bytes key from a file. Unlike the
+// network key it is never generated, the key has to be provisioned by the
+// operator and kept apart from the data it encrypts
+func ReadEncryptionKey(path string) ([]byte, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key: %v", err)
+	}
+	key, err := hex.DecodeHex(strings.TrimSpace(string(buf)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key: %v", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes but it is %d", len(key))
+	}
+	return key, nil
+}
@@ -0,0 +1,34 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEncryptionKey(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "test-encryptionkey-")
+	assert.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "key")
+
+	// missing file
+	_, err = ReadEncryptionKey(path)
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("0x"+strings.Repeat("01", 32)+"\n"), 0600))
+	key, err := ReadEncryptionKey(path)
+	assert.NoError(t, err)
+	assert.Len(t, key, 32)
+
+	// wrong size
+	assert.NoError(t, ioutil.WriteFile(path, []byte("0102"), 0600))
+	_, err = ReadEncryptionKey(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+package minimal
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ConfigChange is an option of the config changed by a reload
+type ConfigChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// SetConfigLoader sets the function that reads the config again for the reloads
+func (s *Server) SetConfigLoader(loader func() (*Config, error)) {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	s.configLoader = loader
+}
+
+// ReloadConfig reads the config again and applies it, the source
+// (i.e. sighup or grpc) is written in the audit log
+func (s *Server) ReloadConfig(source string) ([]*ConfigChange, error) {
+	s.reloadLock.Lock()
+	loader := s.configLoader
+	s.reloadLock.Unlock()
+
+	if loader == nil {
+		return nil, fmt.Errorf("the config of the server cannot be reloaded")
+	}
+	config, err := loader()
+	if err != nil {
+		s.logger.Error("failed to read the config for the reload", "source", source, "err", err)
+		return nil, err
+	}
+	return s.Reload(config, source)
+}
+
+// Reload applies the options of the config that can change without a restart,
+// the block gas target, the consensus options and the allowed peers. The other
+// options are ignored and nothing is applied if the consensus rejects the changes
+func (s *Server) Reload(config *Config, source string) ([]*ConfigChange, error) {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	logger := s.logger.Named("audit")
+
+	changes := []*ConfigChange{}
+	if s.config.BlockGasTarget != config.BlockGasTarget {
+		changes = append(changes, &ConfigChange{
+			Name: "block_gas_target",
+			Old:  fmt.Sprint(s.config.BlockGasTarget),
+			New:  fmt.Sprint(config.BlockGasTarget),
+		})
+	}
+	changes = append(changes, consensusChanges(s.config.Consensus, config.Consensus)...)
+	consensusChanged := len(changes) != 0
+
+	var allowedPeers []peer.ID
+	if config.Network != nil {
+		allowedPeers = config.Network.AllowedPeers
+	}
+	peersChanged := !equalPeers(s.config.Network.AllowedPeers, allowedPeers)
+	if peersChanged {
+		changes = append(changes, &ConfigChange{
+			Name: "network.allowed_peers",
+			Old:  peersString(s.config.Network.AllowedPeers),
+			New:  peersString(allowedPeers),
+		})
+	}
+
+	if len(changes) == 0 {
+		logger.Info("config reloaded without changes", "source", source)
+		return changes, nil
+	}
+
+	if consensusChanged {
+		reloader, ok := s.consensus.(consensus.Reloader)
+		if !ok {
+			err := fmt.Errorf("the consensus engine cannot reload its options")
+			logger.Error("config reload failed", "source", source, "err", err)
+			return nil, err
+		}
+		if err := reloader.Reload(s.activeConsensusConfig(config)); err != nil {
+			logger.Error("config reload failed", "source", source, "err", err)
+			return nil, err
+		}
+		s.config.BlockGasTarget = config.BlockGasTarget
+		s.config.Consensus = config.Consensus
+	}
+	if peersChanged {
+		s.network.SetAllowedPeers(allowedPeers)
+		s.config.Network.AllowedPeers = allowedPeers
+	}
+
+	for _, change := range changes {
+		logger.Info("config changed", "source", source, "option", change.Name, "old", change.Old, "new", change.New)
+	}
+	return changes, nil
+}
+
+// activeConsensusConfig returns the config of the engine that seals the next
+// block with the consensus options of the node config
+func (s *Server) activeConsensusConfig(nodeConfig *Config) *consensus.Config {
+	params := s.config.Chain.Params
+	name, engineParams, forkBlock := params.GetEngine(), params.Engine, uint64(0)
+	if sw, ok := s.consensus.(*consensus.Switch); ok {
+		if engine := sw.ActiveEngine(); engine != nil {
+			for _, fork := range params.EngineForks {
+				if fork.Block == engine.Block {
+					name, engineParams, forkBlock = fork.GetEngine(), fork.Engine, fork.Block
+				}
+			}
+		}
+	}
+	return s.consensusConfig(name, engineParams, forkBlock, nodeConfig)
+}
+
+func consensusChanges(old, new map[string]interface{}) []*ConfigChange {
+	names := []string{}
+	for name := range old {
+		if !reflect.DeepEqual(old[name], new[name]) {
+			names = append(names, name)
+		}
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	value := func(config map[string]interface{}, name string) string {
+		if v, ok := config[name]; ok {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+	changes := []*ConfigChange{}
+	for _, name := range names {
+		changes = append(changes, &ConfigChange{
+			Name: "consensus." + name,
+			Old:  value(old, name),
+			New:  value(new, name),
+		})
+	}
+	return changes
+}
+
+func equalPeers(a, b []peer.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func peersString(ids []peer.ID) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strings.Join(strs, ",")
+}
@@ -0,0 +1,162 @@
+package minimal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/consensus"
+	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReloader struct {
+	config *consensus.Config
+}
+
+func (m *mockReloader) VerifyHeader(parent, header *types.Header) error {
+	return nil
+}
+
+func (m *mockReloader) Prepare(header *types.Header) error {
+	return nil
+}
+
+func (m *mockReloader) Seal(block *types.Block, ctx context.Context) (*types.Block, error) {
+	return block, nil
+}
+
+func (m *mockReloader) Start() error {
+	return nil
+}
+
+func (m *mockReloader) Close() error {
+	return nil
+}
+
+func (m *mockReloader) Reload(config *consensus.Config) error {
+	if _, ok := config.Config["epochSize"]; ok {
+		return fmt.Errorf("epochSize cannot be reloaded")
+	}
+	m.config = config
+	return nil
+}
+
+func TestServer_Reload(t *testing.T) {
+	engine := &mockReloader{}
+	s := &Server{
+		logger:    hclog.NewNullLogger(),
+		consensus: engine,
+		config: &Config{
+			Chain: &chain.Chain{
+				Params: &chain.Params{
+					Engine: map[string]interface{}{
+						"ibft": map[string]interface{}{"blockTime": float64(2)},
+					},
+				},
+			},
+			Network:        &network.Config{},
+			BlockGasTarget: 100,
+		},
+	}
+
+	newConfig := func(gasTarget uint64, consensus map[string]interface{}) *Config {
+		return &Config{
+			Network:        &network.Config{},
+			BlockGasTarget: gasTarget,
+			Consensus:      consensus,
+		}
+	}
+
+	changes, err := s.Reload(newConfig(100, nil), "test")
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Nil(t, engine.config)
+
+	// the node options override the chain params
+	changes, err = s.Reload(newConfig(200, map[string]interface{}{"blockTime": 5}), "test")
+	assert.NoError(t, err)
+	assert.Equal(t, []*ConfigChange{
+		{Name: "block_gas_target", Old: "100", New: "200"},
+		{Name: "consensus.blockTime", Old: "", New: "5"},
+	}, changes)
+	assert.Equal(t, uint64(200), engine.config.BlockGasTarget)
+	assert.Equal(t, 5, engine.config.Config["blockTime"])
+	assert.Equal(t, uint64(200), s.config.BlockGasTarget)
+
+	// nothing is applied if the engine rejects the changes
+	_, err = s.Reload(newConfig(300, map[string]interface{}{"epochSize": 10}), "test")
+	assert.Error(t, err)
+	assert.Equal(t, uint64(200), s.config.BlockGasTarget)
+
+	_, err = s.ReloadConfig("test")
+	assert.Error(t, err)
+}
+
+type mockSwitchBlockchain struct {
+	header *types.Header
+	sub    *blockchain.MockSubscription
+}
+
+func (m *mockSwitchBlockchain) Header() *types.Header {
+	return m.header
+}
+
+func (m *mockSwitchBlockchain) SubscribeEvents() blockchain.Subscription {
+	return m.sub
+}
+
+func TestServer_ReloadEngineForks(t *testing.T) {
+	defer func(hash func(h *types.Header) types.Hash) {
+		types.HeaderHash = hash
+	}(types.HeaderHash)
+
+	engine := &mockReloader{}
+	bc := &mockSwitchBlockchain{
+		header: &types.Header{Number: 10},
+		sub:    blockchain.NewMockSubscription(),
+	}
+	sw, err := consensus.NewSwitch(hclog.NewNullLogger(), bc, []*consensus.Engine{
+		{Name: "ibft", Consensus: &mockReloader{}},
+		{Name: "clique", Block: 10, Consensus: engine},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, sw.Start())
+	defer sw.Close()
+
+	s := &Server{
+		logger:    hclog.NewNullLogger(),
+		consensus: sw,
+		config: &Config{
+			Chain: &chain.Chain{
+				Params: &chain.Params{
+					Engine: map[string]interface{}{
+						"ibft": map[string]interface{}{"blockTime": float64(2)},
+					},
+					EngineForks: []*chain.EngineFork{
+						{
+							Block: 10,
+							Engine: map[string]interface{}{
+								"clique": map[string]interface{}{"period": float64(5)},
+							},
+						},
+					},
+				},
+			},
+			Network: &network.Config{},
+		},
+	}
+
+	// the active engine reloads the options with its own params
+	_, err = s.Reload(&Config{
+		Network:   &network.Config{},
+		Consensus: map[string]interface{}{"maxClockDrift": 1},
+	}, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), engine.config.ForkBlock)
+	assert.Equal(t, map[string]interface{}{"period": float64(5), "maxClockDrift": 1}, engine.config.Config)
+}
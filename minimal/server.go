@@ -3,9 +3,12 @@ package minimal
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/0xPolygon/minimal/chain"
@@ -19,9 +22,13 @@ import (
 	"github.com/0xPolygon/minimal/types"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 
 	itrie "github.com/0xPolygon/minimal/state/immutable-trie"
+	"github.com/0xPolygon/minimal/state/runtime"
 	"github.com/0xPolygon/minimal/state/runtime/evm"
 	"github.com/0xPolygon/minimal/state/runtime/precompiled"
 
@@ -35,6 +42,8 @@ type Server struct {
 	config *Config
 	state  state.State
 
+	stateStorage itrie.Storage
+
 	consensus consensus.Consensus
 
 	// blockchain stack
@@ -50,11 +59,26 @@ type Server struct {
 	// system grpc server
 	grpcServer *grpc.Server
 
+	// lateServices are the grpc services of the consensus
+	// engines built once the grpc server is running
+	lateServices *lateServices
+
 	// libp2p network
 	network *network.Server
 
 	// transaction pool
 	txpool *txpool.TxPool
+
+	// metrics registry and its http endpoint
+	metrics          *prometheus.Registry
+	prometheusServer *http.Server
+
+	// accessLog is the file of the access log of the jsonrpc
+	accessLog *os.File
+
+	// configLoader reads the config again for the reloads
+	configLoader func() (*Config, error)
+	reloadLock   sync.Mutex
 }
 
 var dirPaths = []string{
@@ -63,15 +87,27 @@ var dirPaths = []string{
 	"keystore",
 	"trie",
 	"libp2p",
+	"txpool",
 }
 
 func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
+	grpcOpts, err := grpcServerOptions(config.GRPCAuth, filepath.Join(config.DataDir, "tls"))
+	if err != nil {
+		return nil, err
+	}
+	lateServices := newLateServices()
+	grpcOpts = append(grpcOpts, grpc.UnknownServiceHandler(lateServices.handle))
+
 	m := &Server{
-		logger:     logger,
-		config:     config,
-		chain:      config.Chain,
-		grpcServer: grpc.NewServer(),
+		logger:       logger,
+		config:       config,
+		chain:        config.Chain,
+		grpcServer:   grpc.NewServer(grpcOpts...),
+		lateServices: lateServices,
+		metrics:      prometheus.NewRegistry(),
 	}
+	m.metrics.MustRegister(prometheus.NewGoCollector())
+	m.metrics.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
 
 	m.logger.Info("Data dir", "path", config.DataDir)
 
@@ -99,6 +135,8 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	m.stateStorage = stateStorage
+
 	st := itrie.NewState(stateStorage)
 	m.state = st
 
@@ -111,7 +149,13 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 	config.Chain.Genesis.StateRoot = genesisRoot
 
 	// blockchain object
-	m.blockchain, err = blockchain.NewBlockchain(logger, m.config.DataDir, config.Chain, nil, m.executor)
+	storageConfig := &blockchain.StorageConfig{}
+	if m.config.Storage != nil {
+		*storageConfig = *m.config.Storage
+	}
+	storageConfig.Metrics = m.metrics
+
+	m.blockchain, err = blockchain.NewBlockchain(logger, m.config.DataDir, storageConfig, config.Chain, nil, m.executor)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +165,7 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 	{
 		hub := &txpoolHub{
 			state:      m.state,
+			executor:   m.executor,
 			Blockchain: m.blockchain,
 		}
 		// start transaction pool
@@ -131,6 +176,20 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		// use the eip155 signer
 		signer := crypto.NewEIP155Signer(uint64(m.config.Chain.Params.ChainID))
 		m.txpool.AddSigner(signer)
+
+		if m.config.PriceBump != 0 {
+			m.txpool.SetPriceBump(m.config.PriceBump)
+		}
+		m.txpool.SetLimits(m.config.TxPoolLimits)
+		m.txpool.SetPriority(m.config.TxPoolPriority)
+		if m.config.TxPoolSimulation {
+			m.txpool.EnableSimulation(hub)
+		}
+		m.txpool.SetForks(m.config.Chain.Params.Forks)
+
+		if err := m.txpool.EnableMetrics(m.metrics); err != nil {
+			return nil, err
+		}
 	}
 
 	{
@@ -141,6 +200,12 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		m.blockchain.SetConsensus(m.consensus)
 	}
 
+	// replay the local transactions once the consensus is set up
+	if err := m.txpool.EnableJournal(filepath.Join(m.config.DataDir, "txpool", "journal")); err != nil {
+		return nil, err
+	}
+	m.txpool.WatchChain(m.blockchain.SubscribeEvents())
+
 	// after consensus is done, we can mine the genesis block in blockchain
 	// This is done because consensus might use a custom Hash function so we need
 	// to wait for consensus because we do any block hashing like genesis
@@ -158,6 +223,12 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	if m.config.PrometheusAddr != nil {
+		if err := m.setupPrometheus(); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := m.consensus.Start(); err != nil {
 		return nil, err
 	}
@@ -166,52 +237,172 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 }
 
 type txpoolHub struct {
-	state state.State
+	state    state.State
+	executor *state.Executor
 	*blockchain.Blockchain
 }
 
+func (t *txpoolHub) ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error) {
+	return applyTxn(t.executor, header, txn, nil)
+}
+
 func (t *txpoolHub) GetNonce(root types.Hash, addr types.Address) uint64 {
+	account, ok := t.getAccount(root, addr)
+	if !ok {
+		return 0
+	}
+	return account.Nonce
+}
+
+func (t *txpoolHub) GetBalance(root types.Hash, addr types.Address) *big.Int {
+	account, ok := t.getAccount(root, addr)
+	if !ok {
+		return big.NewInt(0)
+	}
+	return account.Balance
+}
+
+func (t *txpoolHub) getAccount(root types.Hash, addr types.Address) (*state.Account, bool) {
 	snap, err := t.state.NewSnapshotAt(root)
 	if err != nil {
-		return 0
+		return nil, false
 	}
 	result, ok := snap.Get(keccak.Keccak256(nil, addr.Bytes()))
 	if !ok {
-		return 0
+		return nil, false
 	}
 	var account state.Account
 	if err := account.UnmarshalRlp(result); err != nil {
-		return 0
+		return nil, false
 	}
-	return account.Nonce
+	return &account, true
+}
+
+// setupPrometheus serves the metrics of the registry for prometheus
+func (s *Server) setupPrometheus() error {
+	lis, err := net.Listen("tcp", s.config.PrometheusAddr.String())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{}))
+	s.prometheusServer = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.prometheusServer.Serve(lis); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("prometheus server failed", "err", err)
+		}
+	}()
+
+	s.logger.Info("Prometheus server started", "addr", s.config.PrometheusAddr.String())
+	return nil
 }
 
 func (s *Server) setupConsensus() error {
-	engineName := s.config.Chain.Params.GetEngine()
-	engine, ok := consensusBackends[engineName]
-	if !ok {
-		return fmt.Errorf("consensus engine '%s' not found", engineName)
+	params := s.config.Chain.Params
+	if len(params.EngineForks) == 0 {
+		consensus, err := s.newConsensus(params.GetEngine(), params.Engine, 0, s.grpcServer)
+		if err != nil {
+			return err
+		}
+		s.consensus = consensus
+		return nil
 	}
 
-	engineConfig, ok := s.config.Chain.Params.Engine[engineName].(map[string]interface{})
+	// every engine verifies the blocks of its range with its own header
+	// hash function. The engines are built once the chain reaches their
+	// range, after the grpc server started
+	engines := []*consensus.Engine{}
+	addEngine := func(name string, engineParams map[string]interface{}, block uint64) error {
+		if _, ok := consensusBackends[name]; !ok {
+			return fmt.Errorf("consensus engine '%s' not found", name)
+		}
+		headerHash := types.HeaderHash
+		if hash, ok := consensusHeaderHashes[name]; ok {
+			headerHash = hash
+		}
+		engines = append(engines, &consensus.Engine{
+			Name:       name,
+			Block:      block,
+			HeaderHash: headerHash,
+			Factory: func() (consensus.Consensus, error) {
+				return s.newConsensus(name, engineParams, block, s.lateServices)
+			},
+		})
+		return nil
+	}
+
+	if err := addEngine(params.GetEngine(), params.Engine, 0); err != nil {
+		return err
+	}
+	for _, fork := range params.EngineForks {
+		if err := addEngine(fork.GetEngine(), fork.Engine, fork.Block); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	s.consensus, err = consensus.NewSwitch(s.logger, s.blockchain, engines)
+	return err
+}
+
+// newConsensus creates the consensus engine with the params of the chain and
+// the config of the node, forkBlock is the block the engine is activated at
+func (s *Server) newConsensus(name string, engineParams map[string]interface{}, forkBlock uint64, srv grpc.ServiceRegistrar) (consensus.Consensus, error) {
+	engine, ok := consensusBackends[name]
 	if !ok {
-		engineConfig = map[string]interface{}{}
+		return nil, fmt.Errorf("consensus engine '%s' not found", name)
 	}
-	config := &consensus.Config{
-		Params: s.config.Chain.Params,
-		Config: engineConfig,
-		Path:   filepath.Join(s.config.DataDir, "consensus"),
+
+	config := s.consensusConfig(name, engineParams, forkBlock, s.config)
+	if err := createDir(config.Path); err != nil {
+		return nil, err
 	}
-	consensus, err := engine(context.Background(), s.config.Seal, config, s.txpool, s.network, s.blockchain, s.executor, s.grpcServer, s.logger.Named("consensus"))
-	if err != nil {
-		return err
+	return engine(context.Background(), s.config.Seal, config, s.txpool, s.network, s.blockchain, s.executor, srv, s.logger.Named("consensus"))
+}
+
+// consensusConfig returns the config of the engine with the params of the
+// chain and the options of the node config
+func (s *Server) consensusConfig(name string, engineParams map[string]interface{}, forkBlock uint64, nodeConfig *Config) *consensus.Config {
+	engineConfig := map[string]interface{}{}
+	if params, ok := engineParams[name].(map[string]interface{}); ok {
+		for k, v := range params {
+			engineConfig[k] = v
+		}
+	}
+	for k, v := range nodeConfig.Consensus {
+		engineConfig[k] = v
+	}
+
+	// with engine forks every engine has its own directory and the
+	// range of blocks up to the next engine
+	path := filepath.Join(s.config.DataDir, "consensus")
+	endBlock := uint64(0)
+	if forks := s.config.Chain.Params.EngineForks; len(forks) != 0 {
+		path = filepath.Join(path, name)
+		for _, fork := range forks {
+			if fork.Block > forkBlock {
+				endBlock = fork.Block
+				break
+			}
+		}
+	}
+	return &consensus.Config{
+		Params:    s.config.Chain.Params,
+		Config:    engineConfig,
+		Path:      path,
+		ForkBlock: forkBlock,
+		EndBlock:  endBlock,
+		Metrics:   s.metrics,
+
+		BlockGasTarget: nodeConfig.BlockGasTarget,
 	}
-	s.consensus = consensus
-	return nil
 }
 
 type jsonRPCHub struct {
-	state state.State
+	state   state.State
+	network *network.Server
 
 	*blockchain.Blockchain
 	*txpool.TxPool
@@ -258,6 +449,61 @@ func (j *jsonRPCHub) GetStorage(root types.Hash, addr types.Address, slot types.
 	return obj, nil
 }
 
+func (j *jsonRPCHub) PeerCount() int {
+	return len(j.network.Peers())
+}
+
+func (j *jsonRPCHub) Peers() ([]*jsonrpc.PeerInfo, error) {
+	res := []*jsonrpc.PeerInfo{}
+	for _, p := range j.network.Peers() {
+		protocols, err := j.network.GetProtocols(p.Info.ID)
+		if err != nil {
+			return nil, err
+		}
+		info := &jsonrpc.PeerInfo{
+			ID:        p.Info.ID.String(),
+			Addrs:     []string{},
+			Protocols: protocols,
+		}
+		for _, addr := range j.network.GetPeerInfo(p.Info.ID).Addrs {
+			info.Addrs = append(info.Addrs, addr.String())
+		}
+		res = append(res, info)
+	}
+	return res, nil
+}
+
+func (j *jsonRPCHub) NodeInfo() *jsonrpc.NodeInfo {
+	addrInfo := j.network.AddrInfo()
+
+	info := &jsonrpc.NodeInfo{
+		ID:      addrInfo.ID.String(),
+		Addrs:   []string{},
+		P2PAddr: network.AddrInfoToString(addrInfo),
+	}
+	for _, addr := range addrInfo.Addrs {
+		info.Addrs = append(info.Addrs, addr.String())
+	}
+	return info
+}
+
+func (j *jsonRPCHub) AddPeer(addr string) error {
+	return j.network.JoinAddr(addr, 0)
+}
+
+func (j *jsonRPCHub) RemovePeer(id string) error {
+	peerID, err := peer.Decode(id)
+	if err != nil {
+		return err
+	}
+	j.network.Disconnect(peerID, "removed by the admin")
+	return nil
+}
+
+func (j *jsonRPCHub) GetProof(root types.Hash, addr types.Address, slots []types.Hash) (*state.AccountProof, error) {
+	return state.GetProof(j.state, root, addr, slots)
+}
+
 func (j *jsonRPCHub) GetCode(hash types.Hash) ([]byte, error) {
 	res, ok := j.state.GetCode(hash)
 	if !ok {
@@ -266,9 +512,49 @@ func (j *jsonRPCHub) GetCode(hash types.Hash) ([]byte, error) {
 	return res, nil
 }
 
-func (j *jsonRPCHub) ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error) {
-	transition, err := j.BeginTxn(header.StateRoot, header)
+func (j *jsonRPCHub) ApplyTxn(header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
+	return applyTxn(j.Executor, header, txn, override)
+}
+
+func (j *jsonRPCHub) TraceTxn(block *types.Block, hash types.Hash, tracer runtime.Tracer) (*types.Receipt, []byte, error) {
+	parentRoot, err := j.parentRoot(block)
 	if err != nil {
+		return nil, nil, err
+	}
+	return j.Executor.TraceTxn(parentRoot, block, hash, tracer)
+}
+
+func (j *jsonRPCHub) TraceBlock(block *types.Block, newTracer func(txn *types.Transaction) runtime.Tracer) ([]*state.TracedTxn, error) {
+	parentRoot, err := j.parentRoot(block)
+	if err != nil {
+		return nil, err
+	}
+	return j.Executor.TraceBlock(parentRoot, block, newTracer)
+}
+
+// parentRoot returns the state root the txns of the block are executed on
+func (j *jsonRPCHub) parentRoot(block *types.Block) (types.Hash, error) {
+	parent, ok := j.GetHeaderByHash(block.ParentHash())
+	if !ok {
+		return types.Hash{}, fmt.Errorf("parent block %s not found", block.ParentHash())
+	}
+	return parent.StateRoot, nil
+}
+
+// applyTxn executes the txn on top of the state of the header with the
+// accounts of the override replaced, without committing it. It returns the
+// return value and whether it failed
+func applyTxn(executor *state.Executor, header *types.Header, txn *types.Transaction, override state.StateOverride) ([]byte, bool, error) {
+	// the calls without a gas price do not pay the base fee
+	if header.BaseFee != nil && txn.GasPrice != nil && txn.GasPrice.Sign() == 0 {
+		header = header.Copy()
+		header.BaseFee = nil
+	}
+	transition, err := executor.BeginTxn(header.StateRoot, header)
+	if err != nil {
+		return nil, false, fmt.Errorf("state of block %d is not available: %v", header.Number, err)
+	}
+	if err := transition.OverrideState(override); err != nil {
 		return nil, false, err
 	}
 
@@ -282,6 +568,7 @@ func (j *jsonRPCHub) ApplyTxn(header *types.Header, txn *types.Transaction) ([]b
 func (s *Server) setupJSONRPC() error {
 	hub := &jsonRPCHub{
 		state:      s.state,
+		network:    s.network,
 		Blockchain: s.blockchain,
 		TxPool:     s.txpool,
 		Executor:   s.executor,
@@ -291,6 +578,52 @@ func (s *Server) setupJSONRPC() error {
 		Store:   hub,
 		Addr:    s.config.JSONRPCAddr,
 		ChainID: uint64(s.config.Chain.Params.ChainID),
+
+		WSAddr:     s.config.JSONRPCWSAddr,
+		WSMaxConns: s.config.JSONRPCWSMaxConns,
+		WSOrigins:  s.config.JSONRPCWSOrigins,
+		IPCPath:    s.config.IPCPath,
+		BatchLimit: s.config.JSONRPCBatchLimit,
+		Metrics:    s.metrics,
+		Admin:      s.config.JSONRPCAdmin,
+
+		LogsBlockRange: s.config.JSONRPCLogsBlockRange,
+		LogsLimit:      s.config.JSONRPCLogsLimit,
+		CacheSize:      s.config.JSONRPCCacheSize,
+
+		MaxRequestSize:  s.config.JSONRPCMaxRequestSize,
+		MaxResponseSize: s.config.JSONRPCMaxResponseSize,
+
+		WSMaxSubscriptions: s.config.JSONRPCWSMaxSubscriptions,
+		MaxClientFilters:   s.config.JSONRPCMaxClientFilters,
+
+		CORSOrigins: s.config.JSONRPCCORSOrigins,
+		VHosts:      s.config.JSONRPCVHosts,
+
+		RateLimit:        s.config.JSONRPCRateLimit,
+		MethodRateLimits: s.config.JSONRPCMethodRateLimits,
+		AllowMethods:     s.config.JSONRPCAllow,
+		DenyMethods:      s.config.JSONRPCDeny,
+	}
+
+	if s.config.JSONRPCAccessLog != "" {
+		f, err := os.OpenFile(s.config.JSONRPCAccessLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open the jsonrpc access log: %v", err)
+		}
+		s.accessLog = f
+		conf.AccessLog = hclog.New(&hclog.LoggerOptions{
+			Name:       "jsonrpc.access",
+			Output:     f,
+			JSONFormat: true,
+		})
+	}
+	if s.config.JSONRPCTLS != nil {
+		tlsConfig, err := s.config.JSONRPCTLS.tlsConfig(filepath.Join(s.config.DataDir, "tls"))
+		if err != nil {
+			return fmt.Errorf("jsonrpc: %v", err)
+		}
+		conf.TLSConfig = tlsConfig
 	}
 
 	srv, err := jsonrpc.NewJSONRPC(s.logger, conf)
@@ -328,12 +661,64 @@ func (s *Server) Join(addr0 string, dur time.Duration) error {
 	return s.network.JoinAddr(addr0, dur)
 }
 
+// Backup writes a consistent copy of the blockchain and the state databases
+// to the dst directory while the server is running
+func (s *Server) Backup(dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("backup path '%s' already exists", dst)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	// the state trie is never pruned, copying it after the chain means that
+	// it includes the state of every block in the copy of the chain
+	if err := s.blockchain.Snapshot(filepath.Join(dst, "blockchain")); err != nil {
+		return fmt.Errorf("failed to copy the blockchain: %v", err)
+	}
+	if err := s.stateStorage.Snapshot(filepath.Join(dst, "trie")); err != nil {
+		return fmt.Errorf("failed to copy the state: %v", err)
+	}
+
+	s.logger.Info("backup completed", "path", dst, "elapsed", time.Since(start))
+	return nil
+}
+
+// Compact compacts the range of keys [start, end) of the blockchain storage
+// to reclaim the space of the deleted entries
+func (s *Server) Compact(start, end []byte) error {
+	started := time.Now()
+	if err := s.blockchain.Compact(start, end); err != nil {
+		return err
+	}
+	s.logger.Info("compaction completed", "elapsed", time.Since(started))
+	return nil
+}
+
 func (s *Server) Close() {
 	if err := s.blockchain.Close(); err != nil {
 		s.logger.Error("failed to close blockchain", "err", err.Error())
 	}
 	s.network.Close()
 	s.consensus.Close()
+	if err := s.txpool.Close(); err != nil {
+		s.logger.Error("failed to close the txpool", "err", err.Error())
+	}
+	if err := s.jsonrpcServer.Close(); err != nil {
+		s.logger.Error("failed to close the jsonrpc server", "err", err.Error())
+	}
+	if s.prometheusServer != nil {
+		if err := s.prometheusServer.Close(); err != nil {
+			s.logger.Error("failed to close prometheus server", "err", err.Error())
+		}
+	}
+	if s.accessLog != nil {
+		if err := s.accessLog.Close(); err != nil {
+			s.logger.Error("failed to close the jsonrpc access log", "err", err.Error())
+		}
+	}
 }
 
 // Entry is a backend configuration entry
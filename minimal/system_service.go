@@ -1,7 +1,9 @@
 package minimal
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/0xPolygon/minimal/minimal/proto"
@@ -108,3 +110,46 @@ func (s *systemService) PeersList(ctx context.Context, req *empty.Empty) (*proto
 	}
 	return resp, nil
 }
+
+func (s *systemService) Backup(ctx context.Context, req *proto.BackupRequest) (*empty.Empty, error) {
+	if req.Path == "" {
+		return nil, fmt.Errorf("backup path is empty")
+	}
+	if err := s.s.Backup(req.Path); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *systemService) Compact(ctx context.Context, req *proto.CompactRequest) (*empty.Empty, error) {
+	start, end := req.Start, req.End
+	if len(start) == 0 {
+		start = nil
+	}
+	if len(end) == 0 {
+		end = nil
+	}
+	if start != nil && end != nil && bytes.Compare(start, end) >= 0 {
+		return nil, fmt.Errorf("compaction start is not lower than the end")
+	}
+	if err := s.s.Compact(start, end); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *systemService) Reload(ctx context.Context, req *empty.Empty) (*proto.ReloadResponse, error) {
+	changes, err := s.s.ReloadConfig("grpc")
+	if err != nil {
+		return nil, err
+	}
+	resp := &proto.ReloadResponse{}
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, &proto.ReloadResponse_Change{
+			Name:     change.Name,
+			OldValue: change.Old,
+			NewValue: change.New,
+		})
+	}
+	return resp, nil
+}
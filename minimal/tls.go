@@ -0,0 +1,125 @@
+package minimal
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	selfSignedCert = "cert.pem"
+	selfSignedKey  = "key.pem"
+)
+
+// TLSConfig is the certificate of an endpoint served over tls
+type TLSConfig struct {
+	// CertFile and KeyFile are the pem certificate of the endpoint
+	CertFile string
+	KeyFile  string
+
+	// SelfSigned uses a certificate generated by the node if there are
+	// no files. It is kept in the tls folder of the data dir, the clients
+	// can trust it with its cert.pem
+	SelfSigned bool
+}
+
+// certificate returns the certificate of the config, the self
+// signed one is read from the dir and created if it does not exist
+func (c *TLSConfig) certificate(dir string) (tls.Certificate, error) {
+	if c.CertFile != "" || c.KeyFile != "" {
+		return tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	}
+	if !c.SelfSigned {
+		return tls.Certificate{}, fmt.Errorf("the tls certificate requires the cert and the key files")
+	}
+	return selfSignedCertificate(dir)
+}
+
+// tlsConfig returns the tls config of a server with the certificate
+func (c *TLSConfig) tlsConfig(dir string) (*tls.Config, error) {
+	cert, err := c.certificate(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the tls certificate: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func selfSignedCertificate(dir string) (tls.Certificate, error) {
+	certPath := filepath.Join(dir, selfSignedCert)
+	keyPath := filepath.Join(dir, selfSignedKey)
+
+	_, err := os.Stat(certPath)
+	if err != nil && !os.IsNotExist(err) {
+		return tls.Certificate{}, err
+	}
+	if os.IsNotExist(err) {
+		if err := generateCertificate(dir); err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to generate the self signed certificate: %v", err)
+		}
+	}
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+// generateCertificate writes a self signed certificate for the local
+// names of the node, it is its own CA so the clients can trust it
+func generateCertificate(dir string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "minimal"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// the key is written first, the certificate marks the pair as complete
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := ioutil.WriteFile(filepath.Join(dir, selfSignedKey), keyPem, 0600); err != nil {
+		return err
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return ioutil.WriteFile(filepath.Join(dir, selfSignedCert), certPem, 0644)
+}
@@ -0,0 +1,58 @@
+package minimal
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSConfig_SelfSigned(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "minimal-tls-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = (&TLSConfig{}).tlsConfig(dir)
+	assert.Error(t, err)
+
+	config := &TLSConfig{SelfSigned: true}
+	tlsConfig, err := config.tlsConfig(dir)
+	assert.NoError(t, err)
+
+	// the certificate is created once
+	tlsConfig2, err := config.tlsConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, tlsConfig.Certificates[0].Certificate, tlsConfig2.Certificates[0].Certificate)
+
+	info, err := os.Stat(filepath.Join(dir, selfSignedKey))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	lis = tls.NewListener(lis, tlsConfig)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})}
+	go srv.Serve(lis)
+	defer srv.Close()
+
+	// the clients trust the server with its certificate
+	pool, err := loadCertPool(filepath.Join(dir, selfSignedCert))
+	assert.NoError(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get("https://" + lis.Addr().String())
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+}
@@ -6,12 +6,29 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
 	pubsub "github.com/libp2p/go-libp2p-pubsub"
 )
 
+// ValidationResult is the result of the validation of a topic message
+type ValidationResult int
+
+const (
+	// ValidationAccept delivers and propagates the message
+	ValidationAccept ValidationResult = iota
+
+	// ValidationIgnore drops the message without penalizing the peer
+	ValidationIgnore
+
+	// ValidationReject drops the message and penalizes the peer
+	ValidationReject
+)
+
 type Topic struct {
 	logger hclog.Logger
 
+	ps      *pubsub.PubSub
+	name    string
 	topic   *pubsub.Topic
 	typ     reflect.Type
 	closeCh chan struct{}
@@ -29,6 +46,11 @@ func (t *Topic) Publish(obj proto.Message) error {
 	return t.topic.Publish(context.Background(), data)
 }
 
+// ListPeers returns the peers subscribed to the topic
+func (t *Topic) ListPeers() []peer.ID {
+	return t.topic.ListPeers()
+}
+
 func (t *Topic) Subscribe(handler func(obj interface{})) error {
 	sub, err := t.topic.Subscribe()
 	if err != nil {
@@ -39,6 +61,36 @@ func (t *Topic) Subscribe(handler func(obj interface{})) error {
 	return nil
 }
 
+// SetValidator sets the validator of the messages of the topic, the messages
+// are only delivered and propagated to the peers if they are accepted
+func (t *Topic) SetValidator(validator func(obj interface{}) ValidationResult) error {
+	return t.SetPeerValidator(func(from peer.ID, obj interface{}) ValidationResult {
+		return validator(obj)
+	})
+}
+
+// SetPeerValidator sets a validator that also gets the peer that propagated
+// the message, it is the local peer for the messages published by the node
+func (t *Topic) SetPeerValidator(validator func(from peer.ID, obj interface{}) ValidationResult) error {
+	return t.ps.RegisterTopicValidator(t.name, func(ctx context.Context, id peer.ID, msg *pubsub.Message) pubsub.ValidationResult {
+		obj := t.createObj()
+		if err := proto.Unmarshal(msg.Data, obj); err != nil {
+			return pubsub.ValidationReject
+		}
+
+		switch validator(id, obj) {
+		case ValidationAccept:
+			// the subscription reads the object of the validator
+			msg.ValidatorData = obj
+			return pubsub.ValidationAccept
+		case ValidationIgnore:
+			return pubsub.ValidationIgnore
+		default:
+			return pubsub.ValidationReject
+		}
+	})
+}
+
 func (t *Topic) readLoop(sub *pubsub.Subscription, handler func(obj interface{})) {
 	ctx, cancelFn := context.WithCancel(context.Background())
 	go func() {
@@ -53,6 +105,11 @@ func (t *Topic) readLoop(sub *pubsub.Subscription, handler func(obj interface{})
 			continue
 		}
 
+		if obj, ok := msg.ValidatorData.(proto.Message); ok {
+			handler(obj)
+			continue
+		}
+
 		obj := t.createObj()
 		if err := proto.Unmarshal(msg.Data, obj); err != nil {
 			t.logger.Error("failed to unmarshal topic", "err", err)
@@ -69,6 +126,8 @@ func (s *Server) NewTopic(protoID string, obj proto.Message) (*Topic, error) {
 	}
 	tt := &Topic{
 		logger: s.logger.Named(protoID),
+		ps:     s.ps,
+		name:   protoID,
 		topic:  topic,
 		typ:    reflect.TypeOf(obj).Elem(),
 	}
@@ -38,3 +38,45 @@ func TestGossip(t *testing.T) {
 		t.Fatal("timeout")
 	}
 }
+
+func TestGossip_Validator(t *testing.T) {
+	srv0 := CreateServer(t, nil)
+	srv1 := CreateServer(t, nil)
+
+	MultiJoin(t, srv0, srv1)
+
+	topicName := "topic/0.1"
+
+	topic0, err := srv0.NewTopic(topicName, &testproto.AReq{})
+	assert.NoError(t, err)
+
+	topic1, err := srv1.NewTopic(topicName, &testproto.AReq{})
+	assert.NoError(t, err)
+
+	// topic1 only accepts the messages 'b'
+	assert.NoError(t, topic1.SetValidator(func(obj interface{}) ValidationResult {
+		if obj.(*testproto.AReq).Msg != "b" {
+			return ValidationReject
+		}
+		return ValidationAccept
+	}))
+
+	msgCh := make(chan *testproto.AReq)
+	topic1.Subscribe(func(obj interface{}) {
+		msgCh <- obj.(*testproto.AReq)
+	})
+
+	// wait for topic1 to join the topic
+	for len(topic0.topic.ListPeers()) == 0 {
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.NoError(t, topic0.Publish(&testproto.AReq{Msg: "a"}))
+	assert.NoError(t, topic0.Publish(&testproto.AReq{Msg: "b"}))
+
+	select {
+	case msg := <-msgCh:
+		assert.Equal(t, msg.Msg, "b")
+	case <-time.After(1 * time.Second):
+		t.Fatal("timeout")
+	}
+}
@@ -53,6 +53,11 @@ func (i *identity) setup() {
 			peerID := conn.RemotePeer()
 			i.srv.logger.Trace("Conn", "peer", peerID, "direction", conn.Stat().Direction)
 
+			if !i.srv.isAllowed(peerID) {
+				i.srv.Disconnect(peerID, "peer not allowed")
+				return
+			}
+
 			// limit by MaxPeers on incomming requests since we already limit
 			// the outgoing requests
 			if conn.Stat().Direction == network.DirInbound {
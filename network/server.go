@@ -28,6 +28,9 @@ type Config struct {
 	DataDir    string
 	MaxPeers   uint64
 	Chain      *chain.Chain
+
+	// AllowedPeers are the only peers that can connect if it is not empty
+	AllowedPeers []peer.ID
 }
 
 func DefaultConfig() *Config {
@@ -50,6 +53,9 @@ type Server struct {
 	peers     map[peer.ID]*Peer
 	peersLock sync.Mutex
 
+	allowedPeers     map[peer.ID]struct{}
+	allowedPeersLock sync.RWMutex
+
 	dialQueue *dialQueue
 
 	identity  *identity
@@ -113,6 +119,8 @@ func NewServer(logger hclog.Logger, config *Config) (*Server, error) {
 		protocols:        map[string]Protocol{},
 	}
 
+	srv.SetAllowedPeers(config.AllowedPeers)
+
 	// start identity
 	srv.identity = &identity{srv: srv}
 	srv.identity.setup()
@@ -292,6 +300,40 @@ func (s *Server) delPeer(id peer.ID) {
 	})
 }
 
+// SetAllowedPeers replaces the peers that can connect, all the peers can
+// connect if it is empty. The connected peers that are not allowed are
+// disconnected
+func (s *Server) SetAllowedPeers(ids []peer.ID) {
+	var allowed map[peer.ID]struct{}
+	if len(ids) != 0 {
+		allowed = map[peer.ID]struct{}{}
+		for _, id := range ids {
+			allowed[id] = struct{}{}
+		}
+	}
+
+	s.allowedPeersLock.Lock()
+	s.allowedPeers = allowed
+	s.allowedPeersLock.Unlock()
+
+	for _, id := range s.host.Network().Peers() {
+		if !s.isAllowed(id) {
+			s.Disconnect(id, "peer not allowed")
+		}
+	}
+}
+
+func (s *Server) isAllowed(id peer.ID) bool {
+	s.allowedPeersLock.RLock()
+	defer s.allowedPeersLock.RUnlock()
+
+	if s.allowedPeers == nil {
+		return true
+	}
+	_, ok := s.allowedPeers[id]
+	return ok
+}
+
 func (s *Server) Disconnect(peer peer.ID, reason string) {
 	if s.host.Network().Connectedness(peer) == network.Connected {
 		// send some close message
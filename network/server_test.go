@@ -60,6 +60,28 @@ func TestConnLimit_Outbound(t *testing.T) {
 	assert.True(t, connected)
 }
 
+func TestAllowedPeers(t *testing.T) {
+	conf := func(c *Config) {
+		c.NoDiscover = true
+	}
+
+	srv0 := CreateServer(t, conf)
+	srv1 := CreateServer(t, conf)
+	srv2 := CreateServer(t, conf)
+
+	srv0.SetAllowedPeers([]peer.ID{srv1.AddrInfo().ID})
+
+	// srv2 is not allowed to connect
+	assert.Error(t, srv2.Join(srv0.AddrInfo(), 1*time.Second))
+	assert.NoError(t, srv1.Join(srv0.AddrInfo(), 5*time.Second))
+
+	// srv1 is disconnected once it is not allowed
+	srv0.SetAllowedPeers([]peer.ID{srv2.AddrInfo().ID})
+	assert.True(t, srv1.waitForEvent(5*time.Second, disconnectedPeerHandler(srv0.AddrInfo().ID)))
+
+	assert.NoError(t, srv2.Join(srv0.AddrInfo(), 5*time.Second))
+}
+
 func TestPeersLifecycle(t *testing.T) {
 	srv0 := CreateServer(t, nil)
 	srv1 := CreateServer(t, nil)
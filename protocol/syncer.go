@@ -213,8 +213,12 @@ func (s *Syncer) enqueueBlock(peerID peer.ID, b *types.Block) {
 }
 
 func (s *Syncer) Broadcast(b *types.Block) {
-	// diff is number in ibft
-	diff := new(big.Int).SetUint64(b.Number())
+	// the block is broadcasted after it is written, the number is the
+	// difficulty in ibft if the total difficulty is not found
+	diff, ok := s.blockchain.GetTD(b.Hash())
+	if !ok {
+		diff = new(big.Int).SetUint64(b.Number())
+	}
 
 	// broadcast the new block to all the peers
 	req := &proto.NotifyReq{
@@ -13,6 +13,9 @@ import (
 
 const (
 	spuriousDragonMaxCodeSize = 24576
+
+	// MaxInitCodeSize is the max size of the input of a contract creation
+	MaxInitCodeSize = 2 * spuriousDragonMaxCodeSize
 )
 
 var (
@@ -34,6 +37,16 @@ type Executor struct {
 	GetHash  GetHashByNumberHelper
 
 	PostHook func(txn *Transition)
+
+	// GetProposer returns the proposer of the block for the block rewards
+	// of the chain params, the coinbase is the proposer if it is not set
+	GetProposer func(header *types.Header) (types.Address, error)
+
+	// rewards is set if the miners are credited with the block rewards
+	// of the blocks from rewardsFrom up to rewardsTo, if it is not zero
+	rewards     bool
+	rewardsFrom uint64
+	rewardsTo   uint64
 }
 
 // NewExecutor creates a new executor
@@ -68,6 +81,18 @@ func (e *Executor) WriteGenesis(alloc map[types.Address]*chain.GenesisAccount) t
 	return types.BytesToHash(root)
 }
 
+// EnableRewards credits the block and uncle rewards of the proof of work
+// chains to the miners at the end of each processed block in [from, to),
+// the range has no end if to is zero
+func (e *Executor) EnableRewards(from, to uint64) {
+	e.rewards = true
+	e.rewardsFrom, e.rewardsTo = from, to
+}
+
+func (e *Executor) rewardsEnabled(number uint64) bool {
+	return e.rewards && number >= e.rewardsFrom && (e.rewardsTo == 0 || number < e.rewardsTo)
+}
+
 // SetRuntime adds a runtime to the runtime set
 func (e *Executor) SetRuntime(r runtime.Runtime) {
 	e.runtimes = append(e.runtimes, r)
@@ -92,6 +117,9 @@ func (e *Executor) ProcessBlock(parentRoot types.Hash, block *types.Block) (*Blo
 			return nil, err
 		}
 	}
+	if err := txn.Finalize(block.Uncles); err != nil {
+		return nil, err
+	}
 	_, root := txn.Commit()
 
 	res := &BlockResult{
@@ -102,11 +130,86 @@ func (e *Executor) ProcessBlock(parentRoot types.Hash, block *types.Block) (*Blo
 	return res, nil
 }
 
+// TracedTxn is the result of a txn executed with a tracer
+type TracedTxn struct {
+	Txn         *types.Transaction
+	Receipt     *types.Receipt
+	ReturnValue []byte
+}
+
+// TraceBlock executes the txns of the block on top of the parent state,
+// each txn is traced with the tracer returned by newTracer
+func (e *Executor) TraceBlock(parentRoot types.Hash, block *types.Block, newTracer func(t *types.Transaction) runtime.Tracer) ([]*TracedTxn, error) {
+	return e.replayBlock(parentRoot, block, func(t *types.Transaction) (runtime.Tracer, bool) {
+		return newTracer(t), false
+	})
+}
+
+// TraceTxn executes the txns of the block on top of the parent state until
+// the txn of the hash, which is traced. It returns the receipt and the
+// return value of the txn
+func (e *Executor) TraceTxn(parentRoot types.Hash, block *types.Block, hash types.Hash, tracer runtime.Tracer) (*types.Receipt, []byte, error) {
+	traced, err := e.replayBlock(parentRoot, block, func(t *types.Transaction) (runtime.Tracer, bool) {
+		if t.Hash == hash {
+			return tracer, true
+		}
+		return nil, false
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(traced) == 0 {
+		return nil, nil, fmt.Errorf("txn %s not found in block %s", hash, block.Hash())
+	}
+	return traced[0].Receipt, traced[0].ReturnValue, nil
+}
+
+// replayBlock executes the txns of the block on top of the parent state,
+// trace returns the tracer of each txn (nil to not trace it) and whether
+// the replay stops after it. It returns the results of the traced txns
+func (e *Executor) replayBlock(parentRoot types.Hash, block *types.Block, trace func(t *types.Transaction) (runtime.Tracer, bool)) ([]*TracedTxn, error) {
+	txn, err := e.BeginTxn(parentRoot, block.Header)
+	if err != nil {
+		return nil, err
+	}
+	txn.block = block
+
+	traced := []*TracedTxn{}
+	for _, t := range block.Transactions {
+		if t.Hash == (types.Hash{}) {
+			t.ComputeHash()
+		}
+		tracer, stop := trace(t)
+
+		txn.SetTracer(tracer)
+		if err := txn.Write(t); err != nil {
+			return nil, err
+		}
+		if tracer != nil {
+			receipts := txn.Receipts()
+			traced = append(traced, &TracedTxn{
+				Txn:         t,
+				Receipt:     receipts[len(receipts)-1],
+				ReturnValue: txn.ReturnValue(),
+			})
+		}
+		if stop {
+			break
+		}
+	}
+	return traced, nil
+}
+
 // StateAt returns snapshot at given root
 func (e *Executor) State() State {
 	return e.state
 }
 
+// Config returns the params of the chain
+func (e *Executor) Config() *chain.Params {
+	return e.config
+}
+
 // StateAt returns snapshot at given root
 func (e *Executor) StateAt(root types.Hash) (Snapshot, error) {
 	return e.state.NewSnapshotAt(root)
@@ -133,6 +236,7 @@ func (e *Executor) BeginTxn(parentRoot types.Hash, header *types.Header) (*Trans
 
 	txn := &Transition{
 		r:        e,
+		header:   header,
 		ctx:      env2,
 		state:    newTxn,
 		getHash:  e.GetHash(header),
@@ -151,7 +255,8 @@ type Transition struct {
 	auxState State
 
 	// the current block being processed
-	block *types.Block
+	block  *types.Block
+	header *types.Header
 
 	r       *Executor
 	config  chain.ForksInTime
@@ -164,8 +269,63 @@ type Transition struct {
 	receipts []*types.Receipt
 	totalGas uint64
 
+	// fees of the txns credited at the end of the block with the block rewards
+	fees *big.Int
+
 	// The return value for the contract execution
 	returnValue []byte
+
+	// tracer captures the opcodes of the txns, nil if not tracing
+	tracer runtime.Tracer
+}
+
+// OverrideAccount are the fields of an account replaced before executing
+// a call. State replaces the whole storage while StateDiff only the slots
+// included
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[types.Hash]types.Hash
+	StateDiff map[types.Hash]types.Hash
+}
+
+// StateOverride are the accounts replaced before executing a call
+type StateOverride map[types.Address]*OverrideAccount
+
+// OverrideState replaces the accounts of the override in the state of
+// the transition
+func (t *Transition) OverrideState(override StateOverride) error {
+	for addr, account := range override {
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both state and stateDiff", addr)
+		}
+		if account.Nonce != nil {
+			t.state.SetNonce(addr, *account.Nonce)
+		}
+		if account.Code != nil {
+			t.state.SetCode(addr, account.Code)
+		}
+		if account.Balance != nil {
+			t.state.SetBalance(addr, account.Balance)
+		}
+		if account.State != nil {
+			t.state.SetFullState(addr, account.State)
+		}
+		for key, value := range account.StateDiff {
+			t.state.SetState(addr, key, value)
+		}
+	}
+	return nil
+}
+
+// SetTracer sets the tracer of the opcodes of the next txns
+func (t *Transition) SetTracer(tracer runtime.Tracer) {
+	t.tracer = tracer
+}
+
+func (t *Transition) GetTracer() runtime.Tracer {
+	return t.tracer
 }
 
 func (t *Transition) ReturnValue() []byte {
@@ -210,6 +370,7 @@ func (t *Transition) Write(txn *types.Transaction) error {
 		CumulativeGasUsed: t.totalGas,
 		TxHash:            txn.Hash,
 		GasUsed:           gasUsed,
+		TxType:            txn.Type,
 	}
 
 	if t.config.Byzantium {
@@ -263,8 +424,87 @@ var (
 var (
 	big8  = big.NewInt(8)
 	big32 = big.NewInt(32)
+
+	big100 = big.NewInt(100)
 )
 
+// Finalize credits the rewards of the block if they are enabled in the executor
+// or in the chain params, it is called once all the txns of the block are written
+func (t *Transition) Finalize(uncles []*types.Header) error {
+	if t.r.rewardsEnabled(t.header.Number) {
+		t.AccumulateRewards(uncles)
+	}
+	if rewards := t.r.config.Rewards; rewards != nil {
+		return t.distributeRewards(rewards)
+	}
+	return nil
+}
+
+// distributeRewards mints the block reward and credits the fees of the txns
+// to the proposer of the block and the treasury
+func (t *Transition) distributeRewards(rewards *chain.BlockRewards) error {
+	proposer := t.ctx.Coinbase
+	if t.r.GetProposer != nil {
+		var err error
+		if proposer, err = t.r.GetProposer(t.header); err != nil {
+			return fmt.Errorf("failed to get the proposer of the block rewards: %v", err)
+		}
+	}
+
+	if rewards.Reward != nil && rewards.Reward.Sign() > 0 {
+		beneficiary := proposer
+		if rewards.Treasury != nil {
+			beneficiary = *rewards.Treasury
+		}
+		t.state.AddSealingReward(beneficiary, rewards.Reward)
+	}
+
+	if t.fees == nil || t.fees.Sign() == 0 {
+		return nil
+	}
+	fees := new(big.Int).Set(t.fees)
+	if rewards.TreasuryFees != 0 {
+		share := new(big.Int).Mul(t.fees, new(big.Int).SetUint64(rewards.TreasuryFees))
+		share.Div(share, big100)
+		t.state.AddBalance(*rewards.Treasury, share)
+		fees.Sub(fees, share)
+	}
+	t.state.AddBalance(proposer, fees)
+	return nil
+}
+
+// AccumulateRewards credits the miner of the block with the block reward and a
+// share for each included uncle, the miners of the uncles get the uncle rewards
+func (t *Transition) AccumulateRewards(uncles []*types.Header) {
+	blockReward := FrontierBlockReward
+	if t.config.Byzantium {
+		blockReward = ByzantiumBlockReward
+	}
+	if t.config.Constantinople {
+		blockReward = ConstantinopleBlockReward
+	}
+
+	number := big.NewInt(t.ctx.Number)
+
+	reward := new(big.Int).Set(blockReward)
+	for _, uncle := range uncles {
+		// (uncle number + 8 - number) * block reward / 8
+		r := new(big.Int).SetUint64(uncle.Number)
+		r.Add(r, big8)
+		r.Sub(r, number)
+		r.Mul(r, blockReward)
+		r.Div(r, big8)
+		if r.Sign() <= 0 {
+			// the blockchain rejects the uncles deeper than 7 blocks
+			continue
+		}
+		t.state.AddSealingReward(uncle.Miner, r)
+
+		reward.Add(reward, new(big.Int).Div(blockReward, big32))
+	}
+	t.state.AddSealingReward(t.ctx.Coinbase, reward)
+}
+
 func buildLogs(logs []*types.Log, txHash, blockHash types.Hash, txIndex uint) []*types.Log {
 	newLogs := []*types.Log{}
 
@@ -321,10 +561,16 @@ func (t *Transition) Context() runtime.TxContext {
 }
 
 func (t *Transition) transactionGasCost(msg *types.Transaction) uint64 {
+	return TransactionGasCost(msg, t.config)
+}
+
+// TransactionGasCost returns the intrinsic gas of the transaction, the gas
+// that it pays before any execution
+func TransactionGasCost(msg *types.Transaction, config chain.ForksInTime) uint64 {
 	cost := uint64(0)
 
 	// Contract creation is only paid on the homestead fork
-	if msg.IsContractCreation() && t.config.Homestead {
+	if msg.IsContractCreation() && config.Homestead {
 		cost += 53000
 	} else {
 		cost += 21000
@@ -342,15 +588,41 @@ func (t *Transition) transactionGasCost(msg *types.Transaction) uint64 {
 		cost += uint64(zeros) * 4
 
 		nonZeroCost := uint64(68)
-		if t.config.Istanbul {
+		if config.Istanbul {
 			nonZeroCost = 16
 		}
 		cost += uint64(nonZeros) * nonZeroCost
 	}
 
+	// the addresses and slots of the access list are paid upfront. The
+	// runtime does not implement the warm and cold accesses of EIP-2929
+	// yet, so the access list is only charged and it does not lower the
+	// cost of the accesses to the addresses and slots in the list
+	cost += uint64(len(msg.AccessList)) * 2400
+	cost += uint64(msg.AccessList.StorageKeys()) * 1900
+
 	return uint64(cost)
 }
 
+// baseFee returns the base fee of the block, nil before the London fork
+func (t *Transition) baseFee() *big.Int {
+	if !t.config.London {
+		return nil
+	}
+	return t.header.BaseFee
+}
+
+// effectiveGasPrice returns the gas price paid by the txn, the base fee
+// of the block plus the tip capped by the max fee of the txn
+func (t *Transition) effectiveGasPrice(msg *types.Transaction) *big.Int {
+	baseFee := t.baseFee()
+	price := msg.EffectiveTip(baseFee)
+	if baseFee != nil {
+		price.Add(price, baseFee)
+	}
+	return price
+}
+
 func (t *Transition) preCheck(msg *types.Transaction) (uint64, error) {
 	// validate nonce
 	nonce := t.state.GetNonce(msg.From)
@@ -360,15 +632,31 @@ func (t *Transition) preCheck(msg *types.Transaction) (uint64, error) {
 		return 0, fmt.Errorf("nonce is too big: %d > %d", nonce, msg.Nonce)
 	}
 
-	// deduct the upfront max gas cost
-	upfrontGasCost := new(big.Int).Set(msg.GasPrice)
-	upfrontGasCost = upfrontGasCost.Mul(upfrontGasCost, new(big.Int).SetUint64(msg.Gas))
+	if msg.Type == types.AccessListTx && !t.config.Berlin {
+		return 0, fmt.Errorf("transaction type %d not supported", msg.Type)
+	}
+	if msg.Type == types.DynamicFeeTx {
+		if !t.config.London {
+			return 0, fmt.Errorf("transaction type %d not supported", msg.Type)
+		}
+		if msg.GasTipCap == nil || msg.GasTipCap.Cmp(msg.GasPrice) > 0 {
+			return 0, fmt.Errorf("max priority fee per gas higher than max fee per gas")
+		}
+	}
+	if baseFee := t.baseFee(); baseFee != nil && msg.GasPrice.Cmp(baseFee) < 0 {
+		return 0, fmt.Errorf("max fee per gas less than block base fee: %s < %s", msg.GasPrice, baseFee)
+	}
+
+	// the balance has to cover the max gas cost but only the
+	// effective gas price is deducted
+	gas := new(big.Int).SetUint64(msg.Gas)
+	maxGasCost := new(big.Int).Mul(msg.GasPrice, gas)
 	balance := t.state.GetBalance(msg.From)
 
-	if balance.Cmp(upfrontGasCost) < 0 {
-		return 0, fmt.Errorf("balance %s not enough to pay gas %s", balance, upfrontGasCost)
+	if balance.Cmp(maxGasCost) < 0 {
+		return 0, fmt.Errorf("balance %s not enough to pay gas %s", balance, maxGasCost)
 	}
-	t.state.SubBalance(msg.From, upfrontGasCost)
+	t.state.SubBalance(msg.From, gas.Mul(gas, t.effectiveGasPrice(msg)))
 
 	// calculate gas available for the transaction
 	intrinsicGas := t.transactionGasCost(msg)
@@ -394,7 +682,7 @@ func (t *Transition) apply(msg *types.Transaction) ([]byte, uint64, bool, error)
 		return nil, 0, false, errorVMOutOfGas
 	}
 
-	gasPrice := new(big.Int).Set(msg.GasPrice)
+	gasPrice := t.effectiveGasPrice(msg)
 	value := new(big.Int).Set(msg.Value)
 
 	// Set the specific transaction fields in the context
@@ -433,9 +721,21 @@ func (t *Transition) apply(msg *types.Transaction) ([]byte, uint64, bool, error)
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(gasLeft), gasPrice)
 	txn.AddBalance(msg.From, remaining)
 
-	// pay the coinbase
-	coinbaseFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
-	txn.AddBalance(t.ctx.Coinbase, coinbaseFee)
+	// pay the tip to the coinbase, or to the proposer at the end of the block
+	// with the block rewards. The base fee is burnt
+	tip := gasPrice
+	if baseFee := t.baseFee(); baseFee != nil {
+		tip = new(big.Int).Sub(gasPrice, baseFee)
+	}
+	coinbaseFee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), tip)
+	if t.r.config.Rewards != nil {
+		if t.fees == nil {
+			t.fees = new(big.Int)
+		}
+		t.fees.Add(t.fees, coinbaseFee)
+	} else {
+		txn.AddBalance(t.ctx.Coinbase, coinbaseFee)
+	}
 
 	// return gas to the pool
 	t.addGasPool(gasLeft)
@@ -0,0 +1,138 @@
+package itrie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/umbracle/fastrlp"
+)
+
+// Prove returns the merkle proof of the key, the rlp encoded nodes in the
+// path from the root to the key. The nodes embedded in their parent are not
+// included. If the key is not in the trie the proof shows its absence
+func (t *Trie) Prove(key []byte) ([][]byte, error) {
+	nodes, _, err := walk(t.root, t.storage, key)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hasherPool.Get().(*hasher)
+	defer hasherPool.Put(h)
+
+	txn := t.Txn()
+
+	proof := [][]byte{}
+	for i, n := range nodes {
+		arena, _ := h.AcquireArena()
+		enc := txn.encode(n, h, arena).MarshalTo(nil)
+		h.ReleaseArenas(0)
+
+		if i == 0 || len(enc) >= 32 {
+			proof = append(proof, enc)
+		}
+	}
+	return proof, nil
+}
+
+// encode returns the rlp value of the node, the children are referenced
+// by their hash unless they are embedded
+func (t *Txn) encode(node Node, h *hasher, a *fastrlp.Arena) *fastrlp.Value {
+	val := a.NewArray()
+
+	switch n := node.(type) {
+	case *ShortNode:
+		val.Set(a.NewBytes(hexToCompact(n.key)))
+		val.Set(t.hash(n.child, h, a, 1))
+
+	case *FullNode:
+		for _, i := range n.children {
+			if i == nil {
+				val.Set(a.NewNull())
+			} else {
+				val.Set(t.hash(i, h, a, 1))
+			}
+		}
+		if n.value == nil {
+			val.Set(a.NewNull())
+		} else {
+			val.Set(t.hash(n.value, h, a, 1))
+		}
+
+	default:
+		panic(fmt.Sprintf("unknown node type %v", n))
+	}
+	return val
+}
+
+// VerifyProof checks the merkle proof of the key against the root and
+// returns the value of the key, nil if the proof shows its absence
+func VerifyProof(root []byte, key []byte, proof [][]byte) ([]byte, error) {
+	storage := NewMemoryStorage()
+	for _, enc := range proof {
+		storage.Put(hashit(enc), enc)
+	}
+
+	n, ok, err := GetNode(root, storage)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("root node %x not found in the proof", root)
+	}
+
+	_, value, err := walk(n, storage, key)
+	return value, err
+}
+
+// walk returns the nodes in the path from the root to the key and the value
+// of the key, it fails if a node referenced in the path is not in the storage
+func walk(root Node, storage Storage, key []byte) ([]Node, []byte, error) {
+	nodes := []Node{}
+
+	search := keybytesToHex(key)
+	node := root
+	for node != nil {
+		switch n := node.(type) {
+		case *ValueNode:
+			if !n.hash {
+				if len(search) != 0 {
+					return nodes, nil, nil
+				}
+				return nodes, n.buf, nil
+			}
+			// load the reference from the storage
+			nc, ok, err := GetNode(n.buf, storage)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				return nil, nil, fmt.Errorf("node %x not found", n.buf)
+			}
+			node = nc
+
+		case *ShortNode:
+			nodes = append(nodes, n)
+
+			plen := len(n.key)
+			if plen > len(search) || !bytes.Equal(search[:plen], n.key) {
+				return nodes, nil, nil
+			}
+			node = n.child
+			search = search[plen:]
+
+		case *FullNode:
+			nodes = append(nodes, n)
+
+			if len(search) == 0 {
+				node = n.value
+			} else {
+				node = n.getEdge(search[0])
+				search = search[1:]
+			}
+
+		default:
+			panic(fmt.Sprintf("unknown node type %v", n))
+		}
+	}
+	return nodes, nil, nil
+}
@@ -0,0 +1,106 @@
+package itrie
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProof(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	txn := (&Trie{storage: storage}).Txn()
+	txn.batch = storage
+
+	values := map[string][]byte{}
+	for i := 0; i < 100; i++ {
+		key := hashit([]byte{byte(i)})
+
+		// short values are embedded in their parent
+		value := make([]byte, 1+rand.Intn(40))
+		rand.Read(value)
+
+		txn.Insert(key, value)
+		values[string(key)] = value
+	}
+	root, err := txn.Hash()
+	assert.NoError(t, err)
+
+	// the trie in memory and the trie in the storage
+	snap, err := NewState(storage).NewSnapshotAt(types.BytesToHash(root))
+	assert.NoError(t, err)
+	loaded := snap.(*Trie)
+
+	for _, tt := range []*Trie{txn.Commit(), loaded} {
+		for key, value := range values {
+			proof, err := tt.Prove([]byte(key))
+			assert.NoError(t, err)
+
+			res, err := VerifyProof(root, []byte(key), proof)
+			assert.NoError(t, err)
+			assert.Equal(t, value, res)
+		}
+
+		// the proof of a missing key shows its absence
+		missing := hashit([]byte("missing"))
+		proof, err := tt.Prove(missing)
+		assert.NoError(t, err)
+
+		res, err := VerifyProof(root, missing, proof)
+		assert.NoError(t, err)
+		assert.Nil(t, res)
+	}
+
+	// the nodes of the path are required
+	key := hashit([]byte{0x1})
+	proof, err := loaded.Prove(key)
+	assert.NoError(t, err)
+
+	_, err = VerifyProof(root, key, proof[:len(proof)-1])
+	assert.Error(t, err)
+}
+
+func TestProof_Account(t *testing.T) {
+	st := NewState(NewMemoryStorage())
+
+	addr := types.StringToAddress("1")
+	slot := types.StringToHash("2")
+
+	txn := state.NewTxn(st, st.NewSnapshot())
+	txn.SetNonce(addr, 1)
+	txn.SetState(addr, slot, types.StringToHash("3"))
+
+	_, root := txn.Commit(false)
+
+	proof, err := state.GetProof(st, types.BytesToHash(root), addr, []types.Hash{slot, types.StringToHash("4")})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), proof.Account.Nonce)
+
+	data, err := VerifyProof(root, hashit(addr.Bytes()), proof.Proof)
+	assert.NoError(t, err)
+
+	var account state.Account
+	assert.NoError(t, account.UnmarshalRlp(data))
+	assert.Equal(t, proof.Account.Root, account.Root)
+
+	// the slots are proved against the root of the account
+	assert.Len(t, proof.StorageProof, 2)
+	assert.Equal(t, []byte{0x3}, proof.StorageProof[0].Value)
+	assert.Empty(t, proof.StorageProof[1].Value)
+
+	_, err = VerifyProof(account.Root.Bytes(), hashit(slot.Bytes()), proof.StorageProof[0].Proof)
+	assert.NoError(t, err)
+
+	// the account does not exist
+	proof, err = state.GetProof(st, types.BytesToHash(root), types.StringToAddress("2"), []types.Hash{slot})
+	assert.NoError(t, err)
+	assert.Nil(t, proof.Account)
+	assert.Empty(t, proof.StorageProof[0].Value)
+
+	data, err = VerifyProof(root, hashit(types.StringToAddress("2").Bytes()), proof.Proof)
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+}
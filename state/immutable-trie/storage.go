@@ -7,6 +7,7 @@ import (
 	"github.com/0xPolygon/minimal/types"
 	"github.com/hashicorp/go-hclog"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/umbracle/fastrlp"
 )
 
@@ -29,6 +30,9 @@ type Storage interface {
 	Batch() Batch
 	SetCode(hash types.Hash, code []byte)
 	GetCode(hash types.Hash) ([]byte, bool)
+
+	// Snapshot writes a consistent copy of the storage to dst
+	Snapshot(dst string) error
 }
 
 // KVStorage is a k/v storage on memory using leveldb
@@ -78,6 +82,38 @@ func (kv *KVStorage) Get(k []byte) ([]byte, bool) {
 	return data, true
 }
 
+func (kv *KVStorage) Snapshot(dst string) error {
+	snap, err := kv.db.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Release()
+
+	db, err := leveldb.OpenFile(dst, &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	iter := snap.NewIterator(nil, nil)
+	defer iter.Release()
+
+	batch := &leveldb.Batch{}
+	for iter.Next() {
+		batch.Put(iter.Key(), iter.Value())
+		if len(batch.Dump()) >= 4*opt.MiB {
+			if err := db.Write(batch, nil); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	return db.Write(batch, nil)
+}
+
 func NewLevelDBStorage(path string, logger hclog.Logger) (Storage, error) {
 	db, err := leveldb.OpenFile(path, nil)
 	if err != nil {
@@ -123,6 +159,10 @@ func (m *memStorage) GetCode(hash types.Hash) ([]byte, bool) {
 	return code, ok
 }
 
+func (m *memStorage) Snapshot(dst string) error {
+	return fmt.Errorf("snapshots are not supported by the memory storage")
+}
+
 func (m *memStorage) Batch() Batch {
 	return &memBatch{db: &m.db}
 }
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"github.com/0xPolygon/minimal/crypto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// AccountProof is the merkle proof of an account and of some of its
+// storage slots (EIP-1186)
+type AccountProof struct {
+	// Account is nil if the account does not exist
+	Account      *Account
+	Proof        [][]byte
+	StorageProof []*StorageProof
+}
+
+// StorageProof is the merkle proof of a storage slot
+type StorageProof struct {
+	Key   types.Hash
+	Value []byte
+	Proof [][]byte
+}
+
+// GetProof returns the merkle proofs of the account and of the slots
+// in the state of the root
+func GetProof(s State, root types.Hash, addr types.Address, slots []types.Hash) (*AccountProof, error) {
+	snap, err := s.NewSnapshotAt(root)
+	if err != nil {
+		return nil, err
+	}
+
+	key := crypto.Keccak256(addr.Bytes())
+	proof, err := snap.Prove(key)
+	if err != nil {
+		return nil, err
+	}
+	res := &AccountProof{
+		Proof:        proof,
+		StorageProof: []*StorageProof{},
+	}
+
+	storage := s.NewSnapshot()
+	if data, ok := snap.Get(key); ok {
+		var account Account
+		if err := account.UnmarshalRlp(data); err != nil {
+			return nil, err
+		}
+		res.Account = &account
+
+		if storage, err = s.NewSnapshotAt(account.Root); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, slot := range slots {
+		key := crypto.Keccak256(slot.Bytes())
+		proof, err := storage.Prove(key)
+		if err != nil {
+			return nil, err
+		}
+		storageProof := &StorageProof{
+			Key:   slot,
+			Value: []byte{},
+			Proof: proof,
+		}
+		if data, ok := storage.Get(key); ok {
+			p := stateStateParserPool.Get()
+			v, err := p.Parse(data)
+			if err == nil {
+				storageProof.Value, err = v.GetBytes(storageProof.Value)
+			}
+			stateStateParserPool.Put(p)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.StorageProof = append(res.StorageProof, storageProof)
+	}
+	return res, nil
+}
@@ -55,6 +55,12 @@ type state struct {
 	msg    *runtime.Contract // change with msg
 	config *chain.ForksInTime
 
+	// tracer captures the opcodes, step is the last
+	// opcode captured and stepGas the gas before it
+	tracer  runtime.Tracer
+	step    *runtime.Step
+	stepGas uint64
+
 	// memory
 	memory      []byte
 	lastGasCost uint64
@@ -85,6 +91,8 @@ func (c *state) reset() {
 	c.lastGasCost = 0
 	c.stop = false
 	c.err = nil
+	c.tracer = nil
+	c.step = nil
 
 	// reset bitmap
 	c.bitmap.reset()
@@ -223,6 +231,10 @@ func (c *state) Run() ([]byte, error) {
 		//fmt.Printf("%d OP [%d]: %s (%d)\n", c.ip, c.msg.Depth, op.String(), c.gas)
 		//fmt.Println(c.showStack())
 
+		if c.tracer != nil {
+			c.captureState(op)
+		}
+
 		inst := dispatchTable[op]
 		if inst.inst == nil {
 			c.exit(errOpCodeNotFound)
@@ -253,9 +265,37 @@ func (c *state) Run() ([]byte, error) {
 	if err := c.err; err != nil {
 		vmerr = err
 	}
+	if c.step != nil {
+		c.endStep()
+		c.step.Err = vmerr
+	}
 	return c.ret, vmerr
 }
 
+// captureState sends the opcode to the tracer before it is executed
+func (c *state) captureState(op OpCode) {
+	if c.step != nil {
+		c.endStep()
+	}
+	c.step = &runtime.Step{
+		PC:      uint64(c.ip),
+		Op:      op.String(),
+		Gas:     c.gas,
+		Depth:   c.msg.Depth,
+		Address: c.msg.Address,
+		Stack:   c.stack[:c.sp],
+		Memory:  c.memory,
+	}
+	c.stepGas = c.gas
+	c.tracer.CaptureState(c.step, c.host)
+}
+
+func (c *state) endStep() {
+	if c.stepGas > c.gas {
+		c.step.GasCost = c.stepGas - c.gas
+	}
+}
+
 func (c *state) inStaticCall() bool {
 	return c.msg.Static
 }
@@ -70,6 +70,28 @@ type Host interface {
 	Callx(*Contract, Host) ([]byte, uint64, error)
 	Empty(addr types.Address) bool
 	GetNonce(addr types.Address) uint64
+	GetTracer() Tracer
+}
+
+// Tracer captures the opcodes executed by the runtime
+type Tracer interface {
+	// CaptureState is called before the opcode of the step is executed, the
+	// gas cost and the error of the step are set once it is executed. The
+	// stack and the memory of the step are only valid during the call
+	CaptureState(step *Step, host Host)
+}
+
+// Step is an opcode executed by the runtime
+type Step struct {
+	PC      uint64
+	Op      string
+	Gas     uint64
+	GasCost uint64
+	Depth   int
+	Address types.Address
+	Stack   []*big.Int
+	Memory  []byte
+	Err     error
 }
 
 var (
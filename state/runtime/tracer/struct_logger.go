@@ -0,0 +1,127 @@
+package tracer
+
+import (
+	"math/big"
+
+	"github.com/0xPolygon/minimal/state/runtime"
+	"github.com/0xPolygon/minimal/types"
+)
+
+var _ runtime.Tracer = &StructLogger{}
+
+// Config are the parts of the state included in the logs, the memory is
+// only included if it is enabled since it is copied at each opcode
+type Config struct {
+	DisableStack   bool
+	EnableMemory   bool
+	DisableStorage bool
+
+	// Limit is the max number of opcodes logged, zero is no limit
+	Limit int
+}
+
+// StructLog is the state of the evm when an opcode is executed, the
+// storage are the slots of the contract read or written until the opcode
+// and they are only included in the SLOAD and SSTORE opcodes
+type StructLog struct {
+	PC      uint64
+	Op      string
+	Gas     uint64
+	GasCost uint64
+	Depth   int
+	Stack   []*big.Int
+	Memory  []byte
+	Storage map[types.Hash]types.Hash
+	Err     error
+}
+
+// StructLogger logs the state of the evm opcode by opcode
+type StructLogger struct {
+	config Config
+
+	logs  []*StructLog
+	steps []*runtime.Step
+
+	// storage are the slots accessed by each contract
+	storage map[types.Address]map[types.Hash]types.Hash
+}
+
+// NewStructLogger creates a logger with the parts of the state of the config
+func NewStructLogger(config Config) *StructLogger {
+	return &StructLogger{
+		config:  config,
+		storage: map[types.Address]map[types.Hash]types.Hash{},
+	}
+}
+
+// CaptureState implements the tracer interface
+func (l *StructLogger) CaptureState(step *runtime.Step, host runtime.Host) {
+	if l.config.Limit != 0 && len(l.logs) >= l.config.Limit {
+		return
+	}
+	log := &StructLog{
+		PC:    step.PC,
+		Op:    step.Op,
+		Gas:   step.Gas,
+		Depth: step.Depth,
+	}
+	if !l.config.DisableStack {
+		log.Stack = make([]*big.Int, len(step.Stack))
+		for i, item := range step.Stack {
+			log.Stack[i] = new(big.Int).Set(item)
+		}
+	}
+	if l.config.EnableMemory {
+		log.Memory = append([]byte{}, step.Memory...)
+	}
+	if !l.config.DisableStorage {
+		l.captureStorage(log, step, host)
+	}
+	l.logs = append(l.logs, log)
+	l.steps = append(l.steps, step)
+}
+
+func (l *StructLogger) captureStorage(log *StructLog, step *runtime.Step, host runtime.Host) {
+	size := len(step.Stack)
+
+	var key, value types.Hash
+	switch step.Op {
+	case "SLOAD":
+		if size < 1 {
+			return
+		}
+		key = types.BytesToHash(step.Stack[size-1].Bytes())
+		value = host.GetStorage(step.Address, key)
+
+	case "SSTORE":
+		if size < 2 {
+			return
+		}
+		key = types.BytesToHash(step.Stack[size-1].Bytes())
+		value = types.BytesToHash(step.Stack[size-2].Bytes())
+
+	default:
+		return
+	}
+
+	storage, ok := l.storage[step.Address]
+	if !ok {
+		storage = map[types.Hash]types.Hash{}
+		l.storage[step.Address] = storage
+	}
+	storage[key] = value
+
+	log.Storage = make(map[types.Hash]types.Hash, len(storage))
+	for k, v := range storage {
+		log.Storage[k] = v
+	}
+}
+
+// StructLogs returns the logs of the opcodes executed
+func (l *StructLogger) StructLogs() []*StructLog {
+	for i, step := range l.steps {
+		l.logs[i].GasCost = step.GasCost
+		l.logs[i].Err = step.Err
+	}
+	return l.logs
+}
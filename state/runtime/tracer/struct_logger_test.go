@@ -0,0 +1,122 @@
+package tracer
+
+import (
+	"testing"
+
+	"github.com/0xPolygon/minimal/chain"
+	"github.com/0xPolygon/minimal/state/runtime"
+	"github.com/0xPolygon/minimal/state/runtime/evm"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHost struct {
+	runtime.Host
+
+	tracer  runtime.Tracer
+	storage map[types.Hash]types.Hash
+}
+
+func (m *mockHost) GetTracer() runtime.Tracer {
+	return m.tracer
+}
+
+func (m *mockHost) GetStorage(addr types.Address, key types.Hash) types.Hash {
+	return m.storage[key]
+}
+
+func runLogger(config Config, code []byte) ([]*StructLog, error) {
+	logger := NewStructLogger(config)
+	host := &mockHost{
+		tracer: logger,
+		storage: map[types.Hash]types.Hash{
+			types.BytesToHash([]byte{0x1}): types.BytesToHash([]byte{0x2}),
+		},
+	}
+	contract := runtime.NewContract(1, types.Address{}, types.Address{}, types.StringToAddress("1"), nil, 1000, code)
+
+	_, _, err := evm.NewEVM().Run(contract, host, &chain.ForksInTime{})
+	return logger.StructLogs(), err
+}
+
+func TestStructLogger(t *testing.T) {
+	code := []byte{
+		evm.PUSH1, 0x2a,
+		evm.PUSH1, 0x0,
+		evm.MSTORE,
+		evm.PUSH1, 0x1,
+		evm.SLOAD,
+		byte(evm.STOP),
+	}
+	logs, err := runLogger(Config{EnableMemory: true}, code)
+	assert.NoError(t, err)
+
+	ops := []string{}
+	for _, log := range logs {
+		ops = append(ops, log.Op)
+	}
+	assert.Equal(t, []string{"PUSH1", "PUSH1", "MSTORE", "PUSH1", "SLOAD", "STOP"}, ops)
+
+	// the gas is before the opcode is executed
+	assert.Equal(t, uint64(1000), logs[0].Gas)
+	assert.Equal(t, uint64(3), logs[0].GasCost)
+	assert.Equal(t, uint64(997), logs[1].Gas)
+	assert.Equal(t, uint64(50), logs[4].GasCost)
+	assert.Equal(t, 1, logs[0].Depth)
+
+	// the stack and the memory are copies
+	assert.Len(t, logs[2].Stack, 2)
+	assert.Equal(t, uint64(0x2a), logs[2].Stack[0].Uint64())
+	assert.Len(t, logs[2].Memory, 0)
+	assert.Len(t, logs[3].Memory, 32)
+	assert.Equal(t, byte(0x2a), logs[3].Memory[31])
+
+	// the storage only is in the SLOAD
+	assert.Nil(t, logs[3].Storage)
+	assert.Equal(t, map[types.Hash]types.Hash{
+		types.BytesToHash([]byte{0x1}): types.BytesToHash([]byte{0x2}),
+	}, logs[4].Storage)
+}
+
+func TestStructLogger_Disable(t *testing.T) {
+	code := []byte{
+		evm.PUSH1, 0x2a,
+		evm.PUSH1, 0x0,
+		evm.MSTORE,
+		evm.PUSH1, 0x1,
+		evm.SLOAD,
+		byte(evm.STOP),
+	}
+	logs, err := runLogger(Config{DisableStack: true, DisableStorage: true}, code)
+	assert.NoError(t, err)
+
+	for _, log := range logs {
+		assert.Nil(t, log.Stack)
+		assert.Nil(t, log.Memory)
+		assert.Nil(t, log.Storage)
+	}
+}
+
+func TestStructLogger_Error(t *testing.T) {
+	// the pop fails with an empty stack
+	logs, err := runLogger(Config{}, []byte{evm.POP})
+	assert.Error(t, err)
+
+	assert.Len(t, logs, 1)
+	assert.Equal(t, err, logs[0].Err)
+}
+
+func TestStructLogger_Limit(t *testing.T) {
+	code := []byte{
+		evm.PUSH1, 0x2a,
+		evm.PUSH1, 0x0,
+		evm.MSTORE,
+		byte(evm.STOP),
+	}
+	logs, err := runLogger(Config{Limit: 2}, code)
+	assert.NoError(t, err)
+
+	assert.Len(t, logs, 2)
+	assert.Equal(t, "PUSH1", logs[1].Op)
+	assert.Equal(t, uint64(3), logs[1].GasCost)
+}
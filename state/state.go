@@ -21,6 +21,9 @@ type State interface {
 type Snapshot interface {
 	Get(k []byte) ([]byte, bool)
 	Commit(objs []*Object) (Snapshot, []byte)
+
+	// Prove returns the merkle proof of the key
+	Prove(k []byte) ([][]byte, error)
 }
 
 // account trie
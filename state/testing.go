@@ -81,6 +81,30 @@ func TestState(t *testing.T, buildPreState buildPreState) {
 	t.Run("", func(t *testing.T) {
 		testDeleteCommonStateRoot(t, buildPreState)
 	})
+	t.Run("", func(t *testing.T) {
+		testSetFullState(t, buildPreState)
+	})
+}
+
+func testSetFullState(t *testing.T, buildPreState buildPreState) {
+	state, snap := buildPreState(defaultPreState)
+	txn := newTxn(state, snap)
+
+	txn.SetNonce(addr1, 1)
+	txn.SetFullState(addr1, map[types.Hash]types.Hash{
+		hash2: hash2,
+	})
+
+	// the slots of the prestate are removed
+	assert.Equal(t, hash0, txn.GetState(addr1, hash1))
+	assert.Equal(t, hash2, txn.GetState(addr1, hash2))
+	assert.Equal(t, uint64(1), txn.GetNonce(addr1))
+
+	snap, _ = txn.Commit(false)
+
+	txn = newTxn(state, snap)
+	assert.Equal(t, hash0, txn.GetState(addr1, hash1))
+	assert.Equal(t, hash2, txn.GetState(addr1, hash2))
 }
 
 func testDeleteCommonStateRoot(t *testing.T, buildPreState buildPreState) {
@@ -47,6 +47,10 @@ func (m *mockSnapshot) Commit(objs []*Object) (Snapshot, []byte) {
 	panic("Not implemented in tests")
 }
 
+func (m *mockSnapshot) Prove(k []byte) ([][]byte, error) {
+	panic("Not implemented in tests")
+}
+
 func newStateWithPreState(preState map[types.Address]*PreState) (*mockState, *mockSnapshot) {
 	state := &mockState{
 		snapshots: map[types.Hash]Snapshot{},
@@ -0,0 +1,326 @@
+package txpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xPolygon/minimal/helper/keccak"
+	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/0xPolygon/minimal/types"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"google.golang.org/grpc"
+)
+
+const (
+	// txAnnounceSize is the size in bytes from which the txns are only
+	// announced by hash and the peers fetch them
+	txAnnounceSize = 4 * 1024
+
+	// maxKnownTxns is the number of txn hashes remembered for the node
+	// and for each peer
+	maxKnownTxns = 32768
+
+	fetchTimeout = 5 * time.Second
+
+	// maxFetches is the number of announced txns fetched at the same time
+	maxFetches = 16
+)
+
+var txpoolProtoV1 = "/txpool/0.1"
+
+// knownTxns are the hashes of the txns recently seen by the node and by each
+// peer. The txns are not gossiped again to the peers that already sent them
+// or were sent them
+type knownTxns struct {
+	lock sync.Mutex
+	seen *hashSet
+
+	// received are the txns sent by each peer and sent are the
+	// txns gossiped by the node while the peer was in the topic
+	received map[peer.ID]*hashSet
+	sent     map[peer.ID]*hashSet
+}
+
+func newKnownTxns() *knownTxns {
+	return &knownTxns{
+		seen:     newHashSet(maxKnownTxns),
+		received: map[peer.ID]*hashSet{},
+		sent:     map[peer.ID]*hashSet{},
+	}
+}
+
+// markSeen adds the hash to the txns seen by the node, it returns
+// false if the txn was already seen
+func (k *knownTxns) markSeen(hash types.Hash) bool {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	return k.seen.add(hash)
+}
+
+// forget removes the hash from the txns seen by the node
+func (k *knownTxns) forget(hash types.Hash) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.seen.remove(hash)
+}
+
+func (k *knownTxns) markReceived(hash types.Hash, id peer.ID) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	markPeer(k.received, id, hash)
+}
+
+func (k *knownTxns) markSent(hash types.Hash, peers []peer.ID) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	for _, id := range peers {
+		markPeer(k.sent, id, hash)
+	}
+}
+
+func markPeer(known map[peer.ID]*hashSet, id peer.ID, hash types.Hash) {
+	set, ok := known[id]
+	if !ok {
+		set = newHashSet(maxKnownTxns)
+		known[id] = set
+	}
+	set.add(hash)
+}
+
+// unknownPeers returns the peers that do not know the txn, the peers that
+// were already sent it are included if resend is set
+func (k *knownTxns) unknownPeers(hash types.Hash, peers []peer.ID, resend bool) []peer.ID {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	res := []peer.ID{}
+	for _, id := range peers {
+		if set, ok := k.received[id]; ok && set.has(hash) {
+			continue
+		}
+		if set, ok := k.sent[id]; ok && set.has(hash) && !resend {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res
+}
+
+// prune removes the peers that are not connected anymore
+func (k *knownTxns) prune(peers []peer.ID) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	connected := map[peer.ID]struct{}{}
+	for _, id := range peers {
+		connected[id] = struct{}{}
+	}
+	for _, known := range []map[peer.ID]*hashSet{k.received, k.sent} {
+		for id := range known {
+			if _, ok := connected[id]; !ok {
+				delete(known, id)
+			}
+		}
+	}
+}
+
+// hashSet is a set of hashes that drops the oldest ones after max hashes
+type hashSet struct {
+	max   int
+	set   map[types.Hash]struct{}
+	order []types.Hash
+}
+
+func newHashSet(max int) *hashSet {
+	return &hashSet{
+		max: max,
+		set: map[types.Hash]struct{}{},
+	}
+}
+
+func (h *hashSet) add(hash types.Hash) bool {
+	if _, ok := h.set[hash]; ok {
+		return false
+	}
+	h.set[hash] = struct{}{}
+	h.order = append(h.order, hash)
+	for len(h.set) > h.max {
+		delete(h.set, h.order[0])
+		h.order = h.order[1:]
+	}
+	return true
+}
+
+func (h *hashSet) has(hash types.Hash) bool {
+	_, ok := h.set[hash]
+	return ok
+}
+
+func (h *hashSet) remove(hash types.Hash) {
+	if _, ok := h.set[hash]; !ok {
+		return
+	}
+	delete(h.set, hash)
+	for i, item := range h.order {
+		if item == hash {
+			h.order = append(h.order[:i], h.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// publishTxn gossips the txn if any peer of the topic does not know it yet,
+// the large txns are announced by hash. The gossip is best effort, resend
+// gossips the txn again to the peers that may have missed it
+func (t *TxPool) publishTxn(tx *types.Transaction, resend bool) {
+	t.known.markSeen(tx.Hash)
+
+	peers := t.known.unknownPeers(tx.Hash, t.topic.ListPeers(), resend)
+	if len(peers) == 0 {
+		return
+	}
+
+	txn := &proto.Txn{}
+	kind := gossipTxn
+	if txSize(tx) > txAnnounceSize {
+		txn.Hash = tx.Hash.String()
+		kind = gossipAnnounce
+	} else {
+		txn.Raw = &any.Any{
+			Value: tx.MarshalRLP(),
+		}
+	}
+	if err := t.topic.Publish(txn); err != nil {
+		t.logger.Error("failed to topic txn", "err", err)
+		return
+	}
+	t.known.markSent(tx.Hash, peers)
+	t.metrics.gossipSent.WithLabelValues(kind).Inc()
+}
+
+// validateGossipTxn drops the txns already seen by the node so that they are
+// not propagated again. Every node fetches the announced txns from the peer
+// and announces them again if they are admitted in its pool
+func (t *TxPool) validateGossipTxn(from peer.ID, obj interface{}) network.ValidationResult {
+	if from == t.network.AddrInfo().ID {
+		return network.ValidationAccept
+	}
+
+	raw := obj.(*proto.Txn)
+	if raw.Raw == nil {
+		t.metrics.gossipReceived.WithLabelValues(gossipAnnounce).Inc()
+
+		hash := types.StringToHash(raw.Hash)
+		t.known.markReceived(hash, from)
+
+		if t.known.markSeen(hash) {
+			if t.startFetch() {
+				go t.fetchTxn(from, hash)
+			} else {
+				// too many fetches, another peer can announce it again
+				t.known.forget(hash)
+			}
+		}
+		// the node announces the txn again once it is added to the pool
+		return network.ValidationIgnore
+	}
+
+	t.metrics.gossipReceived.WithLabelValues(gossipTxn).Inc()
+
+	hash := types.BytesToHash(keccak.Keccak256(nil, raw.Raw.Value))
+	t.known.markReceived(hash, from)
+
+	if !t.known.markSeen(hash) {
+		return network.ValidationIgnore
+	}
+	return network.ValidationAccept
+}
+
+// startFetch reserves one of the fetches of the announced txns, it
+// returns false if all of them are in progress
+func (t *TxPool) startFetch() bool {
+	select {
+	case t.fetches <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *TxPool) fetchTxn(from peer.ID, hash types.Hash) {
+	defer func() {
+		<-t.fetches
+	}()
+
+	txn, err := t.requestTxn(from, hash)
+	if err != nil {
+		t.logger.Debug("failed to fetch announced txn", "peer", from, "hash", hash, "err", err)
+
+		// another peer can announce it again
+		t.known.forget(hash)
+		return
+	}
+	if err := t.addImpl("fetch", txn); err != nil {
+		t.logger.Error("failed to add fetched txn", "err", err)
+		return
+	}
+	t.publishTxn(txn, false)
+}
+
+func (t *TxPool) requestTxn(from peer.ID, hash types.Hash) (*types.Transaction, error) {
+	conn, err := t.network.NewProtoStream(txpoolProtoV1, from)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.(*grpc.ClientConn).Close()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancelFn()
+
+	resp, err := proto.NewTxnPoolClient(conn.(*grpc.ClientConn)).GetTxns(ctx, &proto.GetTxnsReq{
+		Hashes: []string{hash.String()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Txns) != 1 {
+		return nil, fmt.Errorf("txn not found")
+	}
+
+	txn := new(types.Transaction)
+	if err := txn.UnmarshalRLP(resp.Txns[0].Value); err != nil {
+		return nil, err
+	}
+	if txn.ComputeHash(); txn.Hash != hash {
+		return nil, fmt.Errorf("expected txn %s but found %s", hash, txn.Hash)
+	}
+	return txn, nil
+}
+
+// txnService serves the txns of the pool to the peers
+type txnService struct {
+	proto.UnimplementedTxnPoolServer
+
+	pool *TxPool
+}
+
+// GetTxns implements the TxnPool service
+func (s *txnService) GetTxns(ctx context.Context, req *proto.GetTxnsReq) (*proto.GetTxnsResp, error) {
+	resp := &proto.GetTxnsResp{}
+	for _, hash := range req.Hashes {
+		if txn := s.pool.getTxn(types.StringToHash(hash)); txn != nil {
+			resp.Txns = append(resp.Txns, &any.Any{
+				Value: txn.MarshalRLP(),
+			})
+		}
+	}
+	return resp, nil
+}
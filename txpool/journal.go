@@ -0,0 +1,163 @@
+package txpool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+// txJournal is the file with the local transactions of the pool, they are
+// replayed on restart. Each record is the length of the record, the sender
+// and the rlp of the transaction
+type txJournal struct {
+	lock   sync.Mutex
+	path   string
+	writer *os.File
+}
+
+func newTxJournal(path string) *txJournal {
+	return &txJournal{
+		path: path,
+	}
+}
+
+// load reads the transactions of the journal, a truncated record at the end
+// of the file is ignored
+func (j *txJournal) load(add func(txn *types.Transaction) error) (int, error) {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, 4)
+
+	num := 0
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return num, nil
+			}
+			return num, err
+		}
+		size := binary.BigEndian.Uint32(header)
+		if size < types.AddressLength {
+			return num, fmt.Errorf("invalid journal record of %d bytes", size)
+		}
+		record := make([]byte, size)
+		if _, err := io.ReadFull(r, record); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return num, nil
+			}
+			return num, err
+		}
+
+		txn := new(types.Transaction)
+		if err := txn.UnmarshalRLP(record[types.AddressLength:]); err != nil {
+			return num, err
+		}
+		txn.From = types.BytesToAddress(record[:types.AddressLength])
+
+		if err := add(txn); err != nil {
+			continue
+		}
+		num++
+	}
+}
+
+// open opens the journal to append the new transactions
+func (j *txJournal) open() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.writer = f
+	return nil
+}
+
+func (j *txJournal) insert(txn *types.Transaction) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.writer == nil {
+		return fmt.Errorf("the journal is closed")
+	}
+	_, err := j.writer.Write(encodeJournalRecord(txn))
+	return err
+}
+
+// rotate replaces the journal with the transactions still in the pool
+func (j *txJournal) rotate(txns []*types.Transaction) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.writer != nil {
+		if err := j.writer.Close(); err != nil {
+			return err
+		}
+		j.writer = nil
+	}
+
+	tmp := j.path + ".new"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, txn := range txns {
+		if _, err := w.Write(encodeJournalRecord(txn)); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return err
+	}
+
+	f, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	j.writer = f
+	return nil
+}
+
+func (j *txJournal) close() error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if j.writer == nil {
+		return nil
+	}
+	err := j.writer.Close()
+	j.writer = nil
+	return err
+}
+
+func encodeJournalRecord(txn *types.Transaction) []byte {
+	raw := txn.MarshalRLP()
+
+	buf := make([]byte, 4, 4+types.AddressLength+len(raw))
+	binary.BigEndian.PutUint32(buf, uint32(types.AddressLength+len(raw)))
+	buf = append(buf, txn.From.Bytes()...)
+	return append(buf, raw...)
+}
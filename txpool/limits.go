@@ -0,0 +1,214 @@
+package txpool
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/0xPolygon/minimal/txpool/proto"
+	"github.com/0xPolygon/minimal/types"
+)
+
+// Limits are the caps of the pool, the pending txns with the lowest
+// tip and the oldest queued txns are evicted when they are hit
+type Limits struct {
+	MaxPending uint64
+	MaxQueued  uint64
+
+	// MaxSize is the size in bytes of the pending and queued txns
+	MaxSize uint64
+
+	// AccountPending and AccountQueued are the caps of each account, the
+	// txns beyond the pending ones are queued until the others are mined
+	AccountPending uint64
+	AccountQueued  uint64
+
+	// Lifetime is how long the txns of the remote accounts stay queued
+	// before they expire
+	Lifetime time.Duration
+}
+
+var defaultLimits = Limits{
+	MaxPending:     4096,
+	MaxQueued:      1024,
+	MaxSize:        64 * 1024 * 1024,
+	AccountPending: 64,
+	AccountQueued:  64,
+	Lifetime:       3 * time.Hour,
+}
+
+// SetLimits sets the caps of the pool, the zero values keep the current ones
+func (t *TxPool) SetLimits(limits Limits) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	if limits.MaxPending != 0 {
+		t.limits.MaxPending = limits.MaxPending
+	}
+	if limits.MaxQueued != 0 {
+		t.limits.MaxQueued = limits.MaxQueued
+	}
+	if limits.MaxSize != 0 {
+		t.limits.MaxSize = limits.MaxSize
+	}
+	if limits.AccountPending != 0 {
+		t.limits.AccountPending = limits.AccountPending
+	}
+	if limits.AccountQueued != 0 {
+		t.limits.AccountQueued = limits.AccountQueued
+	}
+	if limits.Lifetime != 0 {
+		t.limits.Lifetime = limits.Lifetime
+	}
+	t.enforceLimits()
+}
+
+// Size returns the size in bytes of the pending and queued transactions
+func (t *TxPool) Size() uint64 {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	return t.sizeLocked()
+}
+
+func (t *TxPool) sizeLocked() uint64 {
+	size := t.sorted.Size()
+	for _, q := range t.queue {
+		size += q.size
+	}
+	return size
+}
+
+// pendingSlots returns the number of txns of the account that can still
+// be promoted, the pending ones are the nonces between the state nonce and
+// the next nonce of the queue
+func (t *TxPool) pendingSlots(q *txQueue, stateNonce uint64) uint64 {
+	pending := uint64(0)
+	if q.nextNonce > stateNonce {
+		pending = q.nextNonce - stateNonce
+	}
+	if pending >= t.limits.AccountPending {
+		return 0
+	}
+	return t.limits.AccountPending - pending
+}
+
+// enforceLimits evicts txns until the pool is within its limits or only the
+// txns of the local accounts and of the priority lane are left, it returns
+// the evicted txns
+func (t *TxPool) enforceLimits() map[types.Hash]struct{} {
+	evicted := map[types.Hash]struct{}{}
+	evict := func(txn *types.Transaction) bool {
+		if txn == nil {
+			return false
+		}
+		evicted[txn.Hash] = struct{}{}
+		return true
+	}
+
+	for t.queuedLocked() > t.limits.MaxQueued {
+		if !evict(t.evictQueued()) {
+			break
+		}
+	}
+	for t.sorted.Length() > t.limits.MaxPending {
+		if !evict(t.evictPending()) {
+			break
+		}
+	}
+	for t.sizeLocked() > t.limits.MaxSize {
+		if !evict(t.evictQueued()) && !evict(t.evictPending()) {
+			break
+		}
+	}
+	return evicted
+}
+
+// evictQueued evicts the oldest queued txn
+func (t *TxPool) evictQueued() *types.Transaction {
+	var oldest *txQueue
+	var nonce uint64
+	for addr, q := range t.queue {
+		if _, ok := t.locals[addr]; ok || t.priority.hasSender(addr) {
+			continue
+		}
+		for n, added := range q.added {
+			if oldest == nil || added.seq < oldest.added[nonce].seq {
+				oldest, nonce = q, n
+			}
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	txn := oldest.Remove(nonce)
+	t.logger.Debug("evict queued txn", "hash", txn.Hash, "from", txn.From)
+	t.metrics.evict(evictedQueued)
+	t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+	return txn
+}
+
+// expireQueued evicts the queued txns of the remote accounts that have been
+// queued longer than the lifetime, it returns the number of expired txns
+func (t *TxPool) expireQueued() int {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	expired := 0
+	for addr, q := range t.queue {
+		if _, ok := t.locals[addr]; ok || t.priority.hasSender(addr) {
+			continue
+		}
+		for nonce, added := range q.added {
+			if time.Since(added.time) < t.limits.Lifetime {
+				continue
+			}
+			txn := q.Remove(nonce)
+			t.logger.Debug("expire queued txn", "hash", txn.Hash, "from", txn.From)
+			t.metrics.evict(evictedExpired)
+			t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+			expired++
+		}
+	}
+	if expired != 0 {
+		t.logger.Info("expired queued txns", "txns", expired, "lifetime", t.limits.Lifetime)
+	}
+	return expired
+}
+
+// evictPending evicts the last pending txn of the account with the lowest
+// tip so that the other pending txns of the account are still valid
+func (t *TxPool) evictPending() *types.Transaction {
+	var lowest *types.Transaction
+	var lowestTip *big.Int
+	for _, txn := range t.sorted.List() {
+		if _, ok := t.locals[txn.From]; ok || t.priority.has(txn) {
+			continue
+		}
+		if tip := txn.EffectiveTip(t.baseFee); lowest == nil || tip.Cmp(lowestTip) < 0 {
+			lowest, lowestTip = txn, tip
+		}
+	}
+	if lowest == nil {
+		return nil
+	}
+
+	q := t.queue[lowest.From]
+	txn := lowest
+	for nonce := q.nextNonce - 1; nonce > lowest.Nonce; nonce-- {
+		if last := t.sorted.Find(lowest.From, nonce); last != nil {
+			txn = last
+			break
+		}
+	}
+	t.sorted.Delete(txn)
+	q.nextNonce = txn.Nonce
+
+	t.logger.Debug("evict pending txn", "hash", txn.Hash, "from", txn.From)
+	t.metrics.evict(evictedPending)
+	t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+	return txn
+}
+
+func txSize(txn *types.Transaction) uint64 {
+	return uint64(len(txn.MarshalRLP()))
+}
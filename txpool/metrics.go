@@ -0,0 +1,180 @@
+package txpool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reasons of the rejected txns
+const (
+	rejectSender        = "sender"
+	rejectUnsigned      = "unsigned"
+	rejectNonce         = "nonce"
+	rejectAccountQueued = "account_queued"
+	rejectUnderpriced   = "underpriced"
+	rejectOversized     = "oversized"
+	rejectGasLimit      = "gas_limit"
+	rejectTxType        = "tx_type"
+	rejectFeeCap        = "fee_cap"
+	rejectIntrinsicGas  = "intrinsic_gas"
+	rejectInitCode      = "init_code"
+	rejectFunds         = "funds"
+	rejectPoolFull      = "pool_full"
+	rejectReverted      = "reverted"
+	rejectOther         = "other"
+)
+
+// reasons of the evicted txns
+const (
+	evictedPending = "pending"
+	evictedQueued  = "queued"
+	evictedExpired = "expired"
+)
+
+// kinds of the gossiped txns
+const (
+	gossipTxn      = "txn"
+	gossipAnnounce = "announcement"
+)
+
+// txnError is the error of a txn rejected by the pool, the
+// reason labels the rejection in the metrics
+type txnError struct {
+	reason string
+	msg    string
+
+	// returnValue is the return value of a txn reverted in the simulation
+	returnValue []byte
+}
+
+func (e *txnError) Error() string {
+	return e.msg
+}
+
+// ReturnValue returns the return value of the reverted txn, the
+// jsonrpc errors include it and decode the revert reason
+func (e *txnError) ReturnValue() []byte {
+	return e.returnValue
+}
+
+func rejectErr(reason string, format string, args ...interface{}) error {
+	return &txnError{
+		reason: reason,
+		msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func rejectReason(err error) string {
+	if e, ok := err.(*txnError); ok {
+		return e.reason
+	}
+	return rejectOther
+}
+
+// metrics are the metrics of the pool, an operator can alert when the
+// pool is saturated or when the rejections spike
+type metrics struct {
+	pending          prometheus.GaugeFunc
+	queued           prometheus.GaugeFunc
+	added            prometheus.Counter
+	rejected         *prometheus.CounterVec
+	evicted          *prometheus.CounterVec
+	promotionLatency prometheus.Histogram
+	gossipReceived   *prometheus.CounterVec
+	gossipSent       *prometheus.CounterVec
+}
+
+func newMetrics(t *TxPool) *metrics {
+	return &metrics{
+		pending: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "pending_txns",
+			Help:      "Number of pending transactions in the pool",
+		}, func() float64 {
+			return float64(t.Length())
+		}),
+		queued: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "queued_txns",
+			Help:      "Number of transactions waiting for a nonce gap to be filled",
+		}, func() float64 {
+			return float64(t.Queued())
+		}),
+		added: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "added_txns_total",
+			Help:      "Number of transactions admitted in the pool",
+		}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "rejected_txns_total",
+			Help:      "Number of transactions rejected by the pool",
+		}, []string{"reason"}),
+		evicted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "evicted_txns_total",
+			Help:      "Number of transactions evicted to enforce the limits and the lifetime of the pool",
+		}, []string{"reason"}),
+		promotionLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "promotion_duration_seconds",
+			Help:      "Time the transactions are queued until they are promoted to pending",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 12),
+		}),
+		gossipReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "gossip_received_total",
+			Help:      "Number of gossiped transactions and announcements received from the peers",
+		}, []string{"kind"}),
+		gossipSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "minimal",
+			Subsystem: "txpool",
+			Name:      "gossip_sent_total",
+			Help:      "Number of transactions and announcements gossiped to the peers",
+		}, []string{"kind"}),
+	}
+}
+
+// EnableMetrics exports the metrics of the pool in the registerer
+func (t *TxPool) EnableMetrics(registerer prometheus.Registerer) error {
+	m := t.metrics
+	for _, c := range []prometheus.Collector{
+		m.pending,
+		m.queued,
+		m.added,
+		m.rejected,
+		m.evicted,
+		m.promotionLatency,
+		m.gossipReceived,
+		m.gossipSent,
+	} {
+		if err := registerer.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *metrics) reject(err error) {
+	m.rejected.WithLabelValues(rejectReason(err)).Inc()
+}
+
+func (m *metrics) evict(reason string) {
+	m.evicted.WithLabelValues(reason).Inc()
+}
+
+// promoted records how long the promoted txns were queued
+func (m *metrics) promoted(queued []time.Duration) {
+	for _, d := range queued {
+		m.promotionLatency.Observe(d.Seconds())
+	}
+}
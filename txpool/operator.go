@@ -1,16 +1,21 @@
 package txpool
 
 import (
+	"bytes"
 	"context"
+	"sort"
 
 	"github.com/0xPolygon/minimal/txpool/proto"
 	"github.com/0xPolygon/minimal/types"
 	"github.com/golang/protobuf/ptypes/empty"
 )
 
+// Status returns the current status of the pool
 func (t *TxPool) Status(ctx context.Context, req *empty.Empty) (*proto.TxnPoolStatusResp, error) {
 	resp := &proto.TxnPoolStatusResp{
 		Length: t.sorted.Length(),
+		Queued: t.Queued(),
+		Size:   t.Size(),
 	}
 	return resp, nil
 }
@@ -34,7 +39,173 @@ func (t *TxPool) AddTxn(ctx context.Context, raw *proto.AddTxnReq) (*empty.Empty
 	return &empty.Empty{}, nil
 }
 
+// GetContent returns the pending and queued transactions of each account
+func (t *TxPool) GetContent(ctx context.Context, req *empty.Empty) (*proto.ContentResp, error) {
+	pending, queued := t.Content()
+
+	addrs := []types.Address{}
+	for addr := range pending {
+		addrs = append(addrs, addr)
+	}
+	for addr := range queued {
+		if _, ok := pending[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+
+	resp := &proto.ContentResp{}
+	for _, addr := range addrs {
+		resp.Accounts = append(resp.Accounts, t.accountToProto(addr, pending[addr], queued[addr]))
+	}
+	return resp, nil
+}
+
+// GetAccount returns the pending and queued transactions of an account
+func (t *TxPool) GetAccount(ctx context.Context, req *proto.AccountReq) (*proto.AccountResp, error) {
+	addr := types.Address{}
+	if err := addr.UnmarshalText([]byte(req.Address)); err != nil {
+		return nil, err
+	}
+	pending, queued := t.Content()
+	return t.accountToProto(addr, pending[addr], queued[addr]), nil
+}
+
+// DropTxn removes a transaction from the pool
+func (t *TxPool) DropTxn(ctx context.Context, req *proto.DropTxnReq) (*empty.Empty, error) {
+	hash := types.Hash{}
+	if err := hash.UnmarshalText([]byte(req.Hash)); err != nil {
+		return nil, err
+	}
+	if err := t.dropTxn(hash); err != nil {
+		return nil, err
+	}
+	t.logger.Info("dropped txn", "hash", hash)
+
+	t.rotateJournal()
+	return &empty.Empty{}, nil
+}
+
+// FlushAccount removes the queued transactions of an account
+func (t *TxPool) FlushAccount(ctx context.Context, req *proto.AccountReq) (*proto.FlushAccountResp, error) {
+	addr := types.Address{}
+	if err := addr.UnmarshalText([]byte(req.Address)); err != nil {
+		return nil, err
+	}
+	dropped := t.flushQueued(addr)
+	t.logger.Info("flushed queued txns", "address", addr, "txns", dropped)
+
+	if dropped != 0 && t.isLocal(addr) {
+		t.rotateJournal()
+	}
+	return &proto.FlushAccountResp{Dropped: uint64(dropped)}, nil
+}
+
+func (t *TxPool) accountToProto(addr types.Address, pending, queued []*types.Transaction) *proto.AccountResp {
+	resp := &proto.AccountResp{
+		Address: addr.String(),
+		Local:   t.isLocal(addr),
+	}
+	for _, txn := range pending {
+		resp.Pending = append(resp.Pending, txnToProto(txn))
+	}
+	for _, txn := range queued {
+		resp.Queued = append(resp.Queued, txnToProto(txn))
+	}
+	return resp
+}
+
+func txnToProto(txn *types.Transaction) *proto.TxnInfo {
+	return &proto.TxnInfo{
+		Hash:     txn.Hash.String(),
+		Nonce:    txn.Nonce,
+		GasPrice: txn.GasPrice.String(),
+		Gas:      txn.Gas,
+		Type:     uint64(txn.Type),
+	}
+}
+
+const subscriptionBufferSize = 64
+
+// Subscribe streams the events of the pool
 func (t *TxPool) Subscribe(req *empty.Empty, stream proto.TxnPoolOperator_SubscribeServer) error {
-	// TODO
-	return nil
+	ch := t.subscribe()
+	defer t.unsubscribe(ch)
+
+	for {
+		select {
+		case evnt := <-ch:
+			if err := stream.Send(evnt); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (t *TxPool) subscribe() chan *proto.TxPoolEvent {
+	t.subscribersLock.Lock()
+	defer t.subscribersLock.Unlock()
+
+	ch := make(chan *proto.TxPoolEvent, subscriptionBufferSize)
+	t.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (t *TxPool) unsubscribe(ch chan *proto.TxPoolEvent) {
+	t.subscribersLock.Lock()
+	defer t.subscribersLock.Unlock()
+
+	delete(t.subscribers, ch)
+}
+
+// SubscribeTxns returns a channel with the txns admitted in the pool and
+// a function to cancel the subscription. The txns are dropped if the
+// channel is not read fast enough
+func (t *TxPool) SubscribeTxns() (<-chan *types.Transaction, func()) {
+	t.subscribersLock.Lock()
+	defer t.subscribersLock.Unlock()
+
+	ch := make(chan *types.Transaction, subscriptionBufferSize)
+	t.txnSubscribers[ch] = struct{}{}
+
+	cancel := func() {
+		t.subscribersLock.Lock()
+		defer t.subscribersLock.Unlock()
+
+		delete(t.txnSubscribers, ch)
+	}
+	return ch, cancel
+}
+
+// emitEvent notifies the subscribers, the slow ones miss the event
+func (t *TxPool) emitEvent(typ proto.TxPoolEvent_EventType, txn, replaced *types.Transaction) {
+	t.subscribersLock.Lock()
+	defer t.subscribersLock.Unlock()
+
+	if typ != proto.TxPoolEvent_EVICTED {
+		for ch := range t.txnSubscribers {
+			select {
+			case ch <- txn:
+			default:
+			}
+		}
+	}
+
+	evnt := &proto.TxPoolEvent{
+		Type: typ,
+		Hash: txn.Hash.String(),
+	}
+	if replaced != nil {
+		evnt.Replaced = replaced.Hash.String()
+	}
+	for ch := range t.subscribers {
+		select {
+		case ch <- evnt:
+		default:
+		}
+	}
 }
@@ -0,0 +1,134 @@
+package txpool
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+	"sort"
+
+	"github.com/0xPolygon/minimal/types"
+)
+
+// PendingTxns iterates the pending transactions of the pool to build a block,
+// the accounts are ordered by the tip over the base fee of their next
+// transaction and the transactions of each account are ordered by nonce.
+// The transactions of the priority lane go first
+type PendingTxns struct {
+	txns     map[types.Address][]*types.Transaction
+	heads    pendingHeads
+	baseFee  *big.Int
+	priority *priorityLane
+}
+
+// Pending returns a snapshot of the pending transactions, the transactions
+// stay in the pool until their block is processed. The transactions that do
+// not cover the base fee are left out with the next ones of the account
+func (t *TxPool) Pending() *PendingTxns {
+	p := &PendingTxns{
+		txns:     map[types.Address][]*types.Transaction{},
+		baseFee:  t.getBaseFee(),
+		priority: t.getPriority(),
+	}
+	for _, txn := range t.sorted.List() {
+		p.txns[txn.From] = append(p.txns[txn.From], txn)
+	}
+	for from, txns := range p.txns {
+		sort.Slice(txns, func(i, j int) bool {
+			return txns[i].Nonce < txns[j].Nonce
+		})
+		for i, txn := range txns {
+			if txn.EffectiveTip(p.baseFee).Sign() < 0 {
+				txns = txns[:i]
+				break
+			}
+		}
+		if len(txns) == 0 {
+			delete(p.txns, from)
+			continue
+		}
+		p.heads = append(p.heads, p.newHead(txns[0]))
+		p.txns[from] = txns[1:]
+	}
+	heap.Init(&p.heads)
+	return p
+}
+
+// Peek returns the transaction with the highest tip, nil if there
+// are no more transactions
+func (p *PendingTxns) Peek() *types.Transaction {
+	if len(p.heads) == 0 {
+		return nil
+	}
+	return p.heads[0].txn
+}
+
+// Shift replaces the transaction returned by Peek with the next one of
+// the same account
+func (p *PendingTxns) Shift() {
+	if len(p.heads) == 0 {
+		return
+	}
+	from := p.heads[0].txn.From
+	if txns := p.txns[from]; len(txns) > 0 {
+		p.heads[0] = p.newHead(txns[0])
+		p.txns[from] = txns[1:]
+		heap.Fix(&p.heads, 0)
+		return
+	}
+	p.Pop()
+}
+
+// Pop drops the transaction returned by Peek and the rest of the transactions
+// of the account, they cannot be applied without it
+func (p *PendingTxns) Pop() {
+	if len(p.heads) == 0 {
+		return
+	}
+	delete(p.txns, p.heads[0].txn.From)
+	heap.Pop(&p.heads)
+}
+
+func (p *PendingTxns) newHead(txn *types.Transaction) *pendingHead {
+	return &pendingHead{
+		txn:      txn,
+		tip:      txn.EffectiveTip(p.baseFee),
+		priority: p.priority.has(txn),
+	}
+}
+
+type pendingHead struct {
+	txn      *types.Transaction
+	tip      *big.Int
+	priority bool
+}
+
+// pendingHeads is a max heap by priority and tip of the next
+// transaction of each account
+type pendingHeads []*pendingHead
+
+func (p pendingHeads) Len() int { return len(p) }
+
+func (p pendingHeads) Less(i, j int) bool {
+	if p[i].priority != p[j].priority {
+		return p[i].priority
+	}
+	if cmp := p[i].tip.Cmp(p[j].tip); cmp != 0 {
+		return cmp > 0
+	}
+	// keep the order deterministic for the txns with the same tip
+	return bytes.Compare(p[i].txn.From.Bytes(), p[j].txn.From.Bytes()) < 0
+}
+
+func (p pendingHeads) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+
+func (p *pendingHeads) Push(x interface{}) {
+	*p = append(*p, x.(*pendingHead))
+}
+
+func (p *pendingHeads) Pop() interface{} {
+	old := *p
+	n := len(old)
+	x := old[n-1]
+	*p = old[0 : n-1]
+	return x
+}
@@ -0,0 +1,64 @@
+package txpool
+
+import (
+	"github.com/0xPolygon/minimal/types"
+)
+
+// Priority is the lane of the txns that are included first in the blocks
+// regardless of their gas price, i.e. the txns of the bridge and the oracles
+// of a chain. The txns of the lane are not evicted to enforce the limits
+type Priority struct {
+	// Senders are the accounts whose txns are in the lane
+	Senders []types.Address
+
+	// Types are the txn types in the lane
+	Types []types.TxType
+}
+
+type priorityLane struct {
+	senders map[types.Address]struct{}
+	types   map[types.TxType]struct{}
+}
+
+// SetPriority sets the priority lane of the pool
+func (t *TxPool) SetPriority(priority Priority) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	lane := &priorityLane{
+		senders: map[types.Address]struct{}{},
+		types:   map[types.TxType]struct{}{},
+	}
+	for _, addr := range priority.Senders {
+		lane.senders[addr] = struct{}{}
+	}
+	for _, typ := range priority.Types {
+		lane.types[typ] = struct{}{}
+	}
+	t.priority = lane
+}
+
+func (p *priorityLane) hasSender(addr types.Address) bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.senders[addr]
+	return ok
+}
+
+func (p *priorityLane) has(txn *types.Transaction) bool {
+	if p == nil {
+		return false
+	}
+	if _, ok := p.types[txn.Type]; ok {
+		return true
+	}
+	return p.hasSender(txn.From)
+}
+
+func (t *TxPool) getPriority() *priorityLane {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	return t.priority
+}
@@ -25,6 +25,15 @@ type TxnPoolOperatorClient interface {
 	AddTxn(ctx context.Context, in *AddTxnReq, opts ...grpc.CallOption) (*empty.Empty, error)
 	// Subscribe subscribes for new events in the txpool
 	Subscribe(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (TxnPoolOperator_SubscribeClient, error)
+	// GetContent returns the pending and queued transactions of each account
+	GetContent(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ContentResp, error)
+	// GetAccount returns the pending and queued transactions of an account
+	GetAccount(ctx context.Context, in *AccountReq, opts ...grpc.CallOption) (*AccountResp, error)
+	// DropTxn removes a transaction from the pool, the next pending
+	// transactions of the account are queued again
+	DropTxn(ctx context.Context, in *DropTxnReq, opts ...grpc.CallOption) (*empty.Empty, error)
+	// FlushAccount removes the queued transactions of an account
+	FlushAccount(ctx context.Context, in *AccountReq, opts ...grpc.CallOption) (*FlushAccountResp, error)
 }
 
 type txnPoolOperatorClient struct {
@@ -85,6 +94,42 @@ func (x *txnPoolOperatorSubscribeClient) Recv() (*TxPoolEvent, error) {
 	return m, nil
 }
 
+func (c *txnPoolOperatorClient) GetContent(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*ContentResp, error) {
+	out := new(ContentResp)
+	err := c.cc.Invoke(ctx, "/v1.TxnPoolOperator/GetContent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnPoolOperatorClient) GetAccount(ctx context.Context, in *AccountReq, opts ...grpc.CallOption) (*AccountResp, error) {
+	out := new(AccountResp)
+	err := c.cc.Invoke(ctx, "/v1.TxnPoolOperator/GetAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnPoolOperatorClient) DropTxn(ctx context.Context, in *DropTxnReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/v1.TxnPoolOperator/DropTxn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *txnPoolOperatorClient) FlushAccount(ctx context.Context, in *AccountReq, opts ...grpc.CallOption) (*FlushAccountResp, error) {
+	out := new(FlushAccountResp)
+	err := c.cc.Invoke(ctx, "/v1.TxnPoolOperator/FlushAccount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TxnPoolOperatorServer is the server API for TxnPoolOperator service.
 // All implementations must embed UnimplementedTxnPoolOperatorServer
 // for forward compatibility
@@ -95,6 +140,15 @@ type TxnPoolOperatorServer interface {
 	AddTxn(context.Context, *AddTxnReq) (*empty.Empty, error)
 	// Subscribe subscribes for new events in the txpool
 	Subscribe(*empty.Empty, TxnPoolOperator_SubscribeServer) error
+	// GetContent returns the pending and queued transactions of each account
+	GetContent(context.Context, *empty.Empty) (*ContentResp, error)
+	// GetAccount returns the pending and queued transactions of an account
+	GetAccount(context.Context, *AccountReq) (*AccountResp, error)
+	// DropTxn removes a transaction from the pool, the next pending
+	// transactions of the account are queued again
+	DropTxn(context.Context, *DropTxnReq) (*empty.Empty, error)
+	// FlushAccount removes the queued transactions of an account
+	FlushAccount(context.Context, *AccountReq) (*FlushAccountResp, error)
 	mustEmbedUnimplementedTxnPoolOperatorServer()
 }
 
@@ -111,6 +165,18 @@ func (UnimplementedTxnPoolOperatorServer) AddTxn(context.Context, *AddTxnReq) (*
 func (UnimplementedTxnPoolOperatorServer) Subscribe(*empty.Empty, TxnPoolOperator_SubscribeServer) error {
 	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
 }
+func (UnimplementedTxnPoolOperatorServer) GetContent(context.Context, *empty.Empty) (*ContentResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContent not implemented")
+}
+func (UnimplementedTxnPoolOperatorServer) GetAccount(context.Context, *AccountReq) (*AccountResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
+}
+func (UnimplementedTxnPoolOperatorServer) DropTxn(context.Context, *DropTxnReq) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropTxn not implemented")
+}
+func (UnimplementedTxnPoolOperatorServer) FlushAccount(context.Context, *AccountReq) (*FlushAccountResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushAccount not implemented")
+}
 func (UnimplementedTxnPoolOperatorServer) mustEmbedUnimplementedTxnPoolOperatorServer() {}
 
 // UnsafeTxnPoolOperatorServer may be embedded to opt out of forward compatibility for this service.
@@ -181,6 +247,78 @@ func (x *txnPoolOperatorSubscribeServer) Send(m *TxPoolEvent) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _TxnPoolOperator_GetContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPoolOperatorServer).GetContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPoolOperator/GetContent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPoolOperatorServer).GetContent(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnPoolOperator_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPoolOperatorServer).GetAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPoolOperator/GetAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPoolOperatorServer).GetAccount(ctx, req.(*AccountReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnPoolOperator_DropTxn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropTxnReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPoolOperatorServer).DropTxn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPoolOperator/DropTxn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPoolOperatorServer).DropTxn(ctx, req.(*DropTxnReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TxnPoolOperator_FlushAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPoolOperatorServer).FlushAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPoolOperator/FlushAccount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPoolOperatorServer).FlushAccount(ctx, req.(*AccountReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TxnPoolOperator_ServiceDesc is the grpc.ServiceDesc for TxnPoolOperator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -196,6 +334,22 @@ var TxnPoolOperator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddTxn",
 			Handler:    _TxnPoolOperator_AddTxn_Handler,
 		},
+		{
+			MethodName: "GetContent",
+			Handler:    _TxnPoolOperator_GetContent_Handler,
+		},
+		{
+			MethodName: "GetAccount",
+			Handler:    _TxnPoolOperator_GetAccount_Handler,
+		},
+		{
+			MethodName: "DropTxn",
+			Handler:    _TxnPoolOperator_DropTxn_Handler,
+		},
+		{
+			MethodName: "FlushAccount",
+			Handler:    _TxnPoolOperator_FlushAccount_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
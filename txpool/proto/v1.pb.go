@@ -26,12 +26,15 @@ const (
 // of the legacy proto package is being used.
 const _ = proto.ProtoPackageIsVersion4
 
+// Txn is the gossip message of a txn, the large txns are only announced
+// with the hash and the peers fetch them
 type Txn struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Raw *any.Any `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	Raw  *any.Any `protobuf:"bytes,1,opt,name=raw,proto3" json:"raw,omitempty"`
+	Hash string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
 }
 
 func (x *Txn) Reset() {
@@ -73,17 +76,129 @@ func (x *Txn) GetRaw() *any.Any {
 	return nil
 }
 
+func (x *Txn) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type GetTxnsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hashes []string `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`
+}
+
+func (x *GetTxnsReq) Reset() {
+	*x = GetTxnsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_proto_v1_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTxnsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTxnsReq) ProtoMessage() {}
+
+func (x *GetTxnsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_proto_v1_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTxnsReq.ProtoReflect.Descriptor instead.
+func (*GetTxnsReq) Descriptor() ([]byte, []int) {
+	return file_txpool_proto_v1_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetTxnsReq) GetHashes() []string {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+type GetTxnsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Txns []*any.Any `protobuf:"bytes,1,rep,name=txns,proto3" json:"txns,omitempty"`
+}
+
+func (x *GetTxnsResp) Reset() {
+	*x = GetTxnsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_txpool_proto_v1_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTxnsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTxnsResp) ProtoMessage() {}
+
+func (x *GetTxnsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_txpool_proto_v1_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTxnsResp.ProtoReflect.Descriptor instead.
+func (*GetTxnsResp) Descriptor() ([]byte, []int) {
+	return file_txpool_proto_v1_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTxnsResp) GetTxns() []*any.Any {
+	if x != nil {
+		return x.Txns
+	}
+	return nil
+}
+
 var File_txpool_proto_v1_proto protoreflect.FileDescriptor
 
 var file_txpool_proto_v1_proto_rawDesc = []byte{
 	0x0a, 0x15, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x76,
 	0x31, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x76, 0x31, 0x1a, 0x19, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2d, 0x0a, 0x03, 0x54, 0x78, 0x6e, 0x12, 0x26, 0x0a,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x41, 0x0a, 0x03, 0x54, 0x78, 0x6e, 0x12, 0x26, 0x0a,
 	0x03, 0x72, 0x61, 0x77, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f,
 	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79,
-	0x52, 0x03, 0x72, 0x61, 0x77, 0x42, 0x0f, 0x5a, 0x0d, 0x2f, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c,
-	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x03, 0x72, 0x61, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x22, 0x24, 0x0a, 0x0a, 0x47, 0x65, 0x74,
+	0x54, 0x78, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x68, 0x61, 0x73, 0x68, 0x65, 0x73, 0x22,
+	0x37, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x54, 0x78, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x28,
+	0x0a, 0x04, 0x74, 0x78, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41,
+	0x6e, 0x79, 0x52, 0x04, 0x74, 0x78, 0x6e, 0x73, 0x32, 0x35, 0x0a, 0x07, 0x54, 0x78, 0x6e, 0x50,
+	0x6f, 0x6f, 0x6c, 0x12, 0x2a, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x54, 0x78, 0x6e, 0x73, 0x12, 0x0e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x78, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x0f,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x78, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42,
+	0x0f, 0x5a, 0x0d, 0x2f, 0x74, 0x78, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -98,18 +213,23 @@ func file_txpool_proto_v1_proto_rawDescGZIP() []byte {
 	return file_txpool_proto_v1_proto_rawDescData
 }
 
-var file_txpool_proto_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_txpool_proto_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
 var file_txpool_proto_v1_proto_goTypes = []interface{}{
-	(*Txn)(nil),     // 0: v1.Txn
-	(*any.Any)(nil), // 1: google.protobuf.Any
+	(*Txn)(nil),         // 0: v1.Txn
+	(*GetTxnsReq)(nil),  // 1: v1.GetTxnsReq
+	(*GetTxnsResp)(nil), // 2: v1.GetTxnsResp
+	(*any.Any)(nil),     // 3: google.protobuf.Any
 }
 var file_txpool_proto_v1_proto_depIdxs = []int32{
-	1, // 0: v1.Txn.raw:type_name -> google.protobuf.Any
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 0: v1.Txn.raw:type_name -> google.protobuf.Any
+	3, // 1: v1.GetTxnsResp.txns:type_name -> google.protobuf.Any
+	1, // 2: v1.TxnPool.GetTxns:input_type -> v1.GetTxnsReq
+	2, // 3: v1.TxnPool.GetTxns:output_type -> v1.GetTxnsResp
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_txpool_proto_v1_proto_init() }
@@ -130,6 +250,30 @@ func file_txpool_proto_v1_proto_init() {
 				return nil
 			}
 		}
+		file_txpool_proto_v1_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTxnsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_txpool_proto_v1_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTxnsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -137,9 +281,9 @@ func file_txpool_proto_v1_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_txpool_proto_v1_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   3,
 			NumExtensions: 0,
-			NumServices:   0,
+			NumServices:   1,
 		},
 		GoTypes:           file_txpool_proto_v1_proto_goTypes,
 		DependencyIndexes: file_txpool_proto_v1_proto_depIdxs,
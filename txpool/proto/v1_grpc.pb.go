@@ -0,0 +1,103 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TxnPoolClient is the client API for TxnPool service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TxnPoolClient interface {
+	// GetTxns returns the txns of the pool announced by the peer
+	GetTxns(ctx context.Context, in *GetTxnsReq, opts ...grpc.CallOption) (*GetTxnsResp, error)
+}
+
+type txnPoolClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTxnPoolClient(cc grpc.ClientConnInterface) TxnPoolClient {
+	return &txnPoolClient{cc}
+}
+
+func (c *txnPoolClient) GetTxns(ctx context.Context, in *GetTxnsReq, opts ...grpc.CallOption) (*GetTxnsResp, error) {
+	out := new(GetTxnsResp)
+	err := c.cc.Invoke(ctx, "/v1.TxnPool/GetTxns", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TxnPoolServer is the server API for TxnPool service.
+// All implementations must embed UnimplementedTxnPoolServer
+// for forward compatibility
+type TxnPoolServer interface {
+	// GetTxns returns the txns of the pool announced by the peer
+	GetTxns(context.Context, *GetTxnsReq) (*GetTxnsResp, error)
+	mustEmbedUnimplementedTxnPoolServer()
+}
+
+// UnimplementedTxnPoolServer must be embedded to have forward compatible implementations.
+type UnimplementedTxnPoolServer struct {
+}
+
+func (UnimplementedTxnPoolServer) GetTxns(context.Context, *GetTxnsReq) (*GetTxnsResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxns not implemented")
+}
+func (UnimplementedTxnPoolServer) mustEmbedUnimplementedTxnPoolServer() {}
+
+// UnsafeTxnPoolServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TxnPoolServer will
+// result in compilation errors.
+type UnsafeTxnPoolServer interface {
+	mustEmbedUnimplementedTxnPoolServer()
+}
+
+func RegisterTxnPoolServer(s grpc.ServiceRegistrar, srv TxnPoolServer) {
+	s.RegisterService(&TxnPool_ServiceDesc, srv)
+}
+
+func _TxnPool_GetTxns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxnsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TxnPoolServer).GetTxns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.TxnPool/GetTxns",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TxnPoolServer).GetTxns(ctx, req.(*GetTxnsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TxnPool_ServiceDesc is the grpc.ServiceDesc for TxnPool service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TxnPool_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.TxnPool",
+	HandlerType: (*TxnPoolServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTxns",
+			Handler:    _TxnPool_GetTxns_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "txpool/proto/v1.proto",
+}
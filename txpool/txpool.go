@@ -1,29 +1,52 @@
 package txpool
 
 import (
+	"bytes"
 	"container/heap"
 	"fmt"
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/network"
+	libp2pGrpc "github.com/0xPolygon/minimal/network/grpc"
+	"github.com/0xPolygon/minimal/state"
 	"github.com/0xPolygon/minimal/txpool/proto"
 	"github.com/0xPolygon/minimal/types"
-	"github.com/golang/protobuf/ptypes/any"
 	"github.com/hashicorp/go-hclog"
 	"google.golang.org/grpc"
 )
 
 const (
 	defaultIdlePeriod = 1 * time.Minute
+
+	// defaultPriceBump is the minimum percentage the gas price has to
+	// increase to replace a transaction with the same nonce
+	defaultPriceBump = 10
+
+	// defaultRebroadcastInterval is the period to broadcast again the
+	// local transactions that are not mined yet
+	defaultRebroadcastInterval = 1 * time.Minute
+
+	// maxGossipBatch is the maximum number of broadcasted transactions
+	// added to the pool at once
+	maxGossipBatch = 128
+
+	// expireInterval is the period to check for the expired queued transactions
+	expireInterval = 1 * time.Minute
+
+	// txMaxSize is the maximum size in bytes of a transaction
+	txMaxSize = 128 * 1024
 )
 
 type store interface {
 	Header() *types.Header
 	GetNonce(root types.Hash, addr types.Address) uint64
+	GetBalance(root types.Hash, addr types.Address) *big.Int
 	GetBlockByHash(types.Hash, bool) (*types.Block, bool)
 }
 
@@ -31,6 +54,12 @@ type signer interface {
 	Sender(tx *types.Transaction) (types.Address, error)
 }
 
+// simulator executes a txn on top of the state of the header
+// without committing it
+type simulator interface {
+	ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error)
+}
+
 // TxPool is a pool of transactions
 type TxPool struct {
 	logger hclog.Logger
@@ -39,20 +68,66 @@ type TxPool struct {
 	store      store
 	idlePeriod time.Duration
 
-	// unsorted list of transactions per account
-	queue map[types.Address]*txQueue
+	rebroadcastInterval time.Duration
+	closeCh             chan struct{}
+
+	// unsorted list of transactions per account, the transactions with
+	// a nonce ahead of the next nonce of the account are queued here
+	// until the gap is filled
+	queue     map[types.Address]*txQueue
+	queueLock sync.Mutex
+
+	priceBump uint64
+	limits    Limits
+
+	// forks are the forks of the chain to validate the transactions
+	forks *chain.Forks
+
+	// baseFee is the base fee of the next block, nil if the
+	// chain has no base fee
+	baseFee *big.Int
+
+	// simulator dry runs the incoming txns to reject the ones that
+	// revert, the simulation is disabled if nil
+	simulator simulator
+
+	// locals are the accounts of the transactions added to this node, their
+	// transactions are not evicted and they are rebroadcast until mined.
+	// They are persisted in the journal if it is enabled
+	locals  map[types.Address]struct{}
+	journal *txJournal
+
+	// priority is the lane of the txns included first in the blocks
+	priority *priorityLane
+
+	// subscribers of the events and of the admitted txns of the pool
+	subscribers     map[chan *proto.TxPoolEvent]struct{}
+	txnSubscribers  map[chan *types.Transaction]struct{}
+	subscribersLock sync.Mutex
 
 	// sorted list of current valid transactions
 	sorted *txPriceHeap
 
+	// chainSub are the events of the blockchain watched by the pool
+	chainSub blockchain.Subscription
+
 	// network stack
 	network *network.Server
 	topic   *network.Topic
+	known   *knownTxns
+
+	// gossipCh are the broadcasted txns waiting to be added
+	gossipCh chan *types.Transaction
+
+	// fetches are the announced txns being fetched from the peers
+	fetches chan struct{}
 
 	sealing  bool
 	dev      bool
 	NotifyCh chan struct{}
 
+	metrics *metrics
+
 	proto.UnimplementedTxnPoolOperatorServer
 }
 
@@ -62,11 +137,24 @@ func NewTxPool(logger hclog.Logger, sealing bool, store store, grpcServer *grpc.
 		logger:     logger.Named("txpool"),
 		store:      store,
 		idlePeriod: defaultIdlePeriod,
+		closeCh:    make(chan struct{}),
 		queue:      make(map[types.Address]*txQueue, 0),
 		network:    network,
 		sorted:     newTxPriceHeap(),
 		sealing:    sealing,
+
+		priceBump:      defaultPriceBump,
+		limits:         defaultLimits,
+		subscribers:    map[chan *proto.TxPoolEvent]struct{}{},
+		txnSubscribers: map[chan *types.Transaction]struct{}{},
+		locals:         map[types.Address]struct{}{},
+		known:          newKnownTxns(),
+		gossipCh:       make(chan *types.Transaction, maxGossipBatch),
+		fetches:        make(chan struct{}, maxFetches),
+
+		rebroadcastInterval: defaultRebroadcastInterval,
 	}
+	txPool.metrics = newMetrics(txPool)
 
 	if network != nil {
 		// subscribe to the gossip protocol
@@ -74,17 +162,33 @@ func NewTxPool(logger hclog.Logger, sealing bool, store store, grpcServer *grpc.
 		if err != nil {
 			return nil, err
 		}
+		if err := topic.SetPeerValidator(txPool.validateGossipTxn); err != nil {
+			return nil, err
+		}
 		topic.Subscribe(txPool.handleGossipTxn)
 		txPool.topic = topic
+
+		// serve the announced txns to the peers
+		grpc := libp2pGrpc.NewGrpcStream()
+		proto.RegisterTxnPoolServer(grpc.GrpcServer(), &txnService{pool: txPool})
+		network.Register(txpoolProtoV1, grpc)
+
+		go txPool.gossipLoop()
+		go txPool.rebroadcastLoop()
 	}
 
 	if grpcServer != nil {
 		proto.RegisterTxnPoolOperatorServer(grpcServer, txPool)
 	}
+	go txPool.expireLoop()
+
 	return txPool, nil
 }
 
 func (t *TxPool) GetNonce(addr types.Address) (uint64, bool) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
 	q, ok := t.queue[addr]
 	if !ok {
 		return 0, false
@@ -105,12 +209,47 @@ func (t *TxPool) handleGossipTxn(obj interface{}) {
 	}
 
 	raw := obj.(*proto.Txn)
+	if raw.Raw == nil {
+		// the announced txns are fetched when the gossip is validated
+		return
+	}
 	txn := new(types.Transaction)
 	if err := txn.UnmarshalRLP(raw.Raw.Value); err != nil {
 		t.logger.Error("failed to decode broadcasted txn", "err", err)
-	} else {
-		if err := t.addImpl("gossip", txn); err != nil {
-			t.logger.Error("failed to add broadcasted txn", "err", err)
+		return
+	}
+	select {
+	case t.gossipCh <- txn:
+	case <-t.closeCh:
+	}
+}
+
+// gossipLoop adds the broadcasted txns to the pool in batches of the txns
+// received while the previous batch was added
+func (t *TxPool) gossipLoop() {
+	for {
+		var txn *types.Transaction
+		select {
+		case txn = <-t.gossipCh:
+		case <-t.closeCh:
+			return
+		}
+
+		batch := []*types.Transaction{txn}
+	DRAIN:
+		for len(batch) < maxGossipBatch {
+			select {
+			case txn = <-t.gossipCh:
+				batch = append(batch, txn)
+			default:
+				break DRAIN
+			}
+		}
+
+		for i, err := range t.addBatch("gossip", false, batch) {
+			if err != nil {
+				t.logger.Error("failed to add broadcasted txn", "hash", batch[i].Hash, "err", err)
+			}
 		}
 	}
 }
@@ -119,90 +258,531 @@ func (t *TxPool) EnableDev() {
 	t.dev = true
 }
 
+// SetPriceBump sets the minimum percentage the gas price of a transaction
+// has to increase to replace the one with the same nonce
+func (t *TxPool) SetPriceBump(percent uint64) {
+	t.priceBump = percent
+}
+
+// EnableSimulation dry runs the incoming transactions against the latest
+// state and rejects the ones that revert
+func (t *TxPool) EnableSimulation(s simulator) {
+	t.simulator = s
+}
+
+// SetForks sets the forks of the chain used to validate the transactions
+func (t *TxPool) SetForks(forks *chain.Forks) {
+	t.forks = forks
+}
+
+// SetBaseFee sets the base fee of the next block, the pending transactions
+// are ordered by the tip they pay over it
+func (t *TxPool) SetBaseFee(baseFee *big.Int) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	t.baseFee = baseFee
+}
+
+func (t *TxPool) getBaseFee() *big.Int {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	return t.baseFee
+}
+
+// EnableJournal replays the local transactions of the journal file and
+// persists the new ones
+func (t *TxPool) EnableJournal(path string) error {
+	journal := newTxJournal(path)
+
+	num, err := journal.load(func(txn *types.Transaction) error {
+		if !t.dev {
+			// the sender is recovered from the signature
+			txn.From = types.ZeroAddress
+		}
+		return t.addTxns("journal", true, txn)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load the txpool journal: %v", err)
+	}
+	t.logger.Info("loaded the txpool journal", "txns", num)
+
+	if err := journal.rotate(t.localTxns()); err != nil {
+		return fmt.Errorf("failed to rotate the txpool journal: %v", err)
+	}
+	t.journal = journal
+	return nil
+}
+
+// Close stops the rebroadcast and the expiry of the transactions and closes the journal of the pool
+func (t *TxPool) Close() error {
+	select {
+	case <-t.closeCh:
+	default:
+		close(t.closeCh)
+		if t.chainSub != nil {
+			t.chainSub.Close()
+		}
+	}
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.close()
+}
+
+// rebroadcastLoop broadcasts periodically the local transactions until
+// they are mined, the peers may have missed or dropped them
+func (t *TxPool) rebroadcastLoop() {
+	for {
+		select {
+		case <-time.After(t.rebroadcastInterval):
+			t.rebroadcast()
+		case <-t.closeCh:
+			return
+		}
+	}
+}
+
+// expireLoop evicts periodically the queued transactions that are
+// waiting too long for their nonce gap to be filled
+func (t *TxPool) expireLoop() {
+	for {
+		select {
+		case <-time.After(expireInterval):
+			t.expireQueued()
+		case <-t.closeCh:
+			return
+		}
+	}
+}
+
+func (t *TxPool) rebroadcast() {
+	if t.dev {
+		return
+	}
+	// forget the peers that left the topic
+	t.known.prune(t.topic.ListPeers())
+
+	txns := t.localTxns()
+	if len(txns) == 0 {
+		return
+	}
+	t.logger.Debug("rebroadcast local txns", "txns", len(txns))
+	for _, txn := range txns {
+		t.publishTxn(txn, true)
+	}
+}
+
+// localTxns returns the pending and queued transactions of the local
+// accounts sorted by account and nonce
+func (t *TxPool) localTxns() []*types.Transaction {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	txns := []*types.Transaction{}
+	for _, txn := range t.sorted.List() {
+		if _, ok := t.locals[txn.From]; ok {
+			txns = append(txns, txn)
+		}
+	}
+	for addr := range t.locals {
+		if q, ok := t.queue[addr]; ok {
+			txns = append(txns, q.txs...)
+		}
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		if txns[i].From != txns[j].From {
+			return bytes.Compare(txns[i].From.Bytes(), txns[j].From.Bytes()) < 0
+		}
+		return txns[i].Nonce < txns[j].Nonce
+	})
+	return txns
+}
+
 // AddTx adds a new transaction to the pool
 func (t *TxPool) AddTx(tx *types.Transaction) error {
-	if err := t.addImpl("addTxn", tx); err != nil {
-		return err
-	}
+	return t.AddTxs([]*types.Transaction{tx})[0]
+}
+
+// AddTxs adds a batch of new transactions to the pool under one lock
+// acquisition, it returns the result of each transaction
+func (t *TxPool) AddTxs(txns []*types.Transaction) []error {
+	errs := t.addBatch("addTxn", true, txns)
 
-	// broadcast the transaction only if network is enabled
-	// and we are not in dev mode
-	if t.topic != nil && !t.dev {
-		txn := &proto.Txn{
-			Raw: &any.Any{
-				Value: tx.MarshalRLP(),
-			},
+	added := false
+	for i, txn := range txns {
+		if errs[i] != nil {
+			continue
 		}
-		if err := t.topic.Publish(txn); err != nil {
-			t.logger.Error("failed to topic txn", "err", err)
+		added = true
+
+		if t.journal != nil {
+			if err := t.journal.insert(txn); err != nil {
+				t.logger.Error("failed to journal txn", "err", err)
+			}
+		}
+
+		// broadcast the transaction only if network is enabled
+		// and we are not in dev mode
+		if t.topic != nil && !t.dev {
+			t.publishTxn(txn, false)
 		}
 	}
 
-	if t.NotifyCh != nil {
+	if added && t.NotifyCh != nil {
 		select {
 		case t.NotifyCh <- struct{}{}:
 		default:
 		}
 	}
-	return nil
+	return errs
 }
 
 func (t *TxPool) addImpl(ctx string, txns ...*types.Transaction) error {
+	return t.addTxns(ctx, false, txns...)
+}
+
+// addTxns adds the txns to the pool, it returns the first error if any
+// of them is rejected
+func (t *TxPool) addTxns(ctx string, local bool, txns ...*types.Transaction) error {
+	for _, err := range t.addBatch(ctx, local, txns) {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addBatch validates the txns and adds them to the pool under one lock
+// acquisition, it returns the result of each txn. The txns of the local
+// accounts are not evicted to enforce the limits of the pool
+func (t *TxPool) addBatch(ctx string, local bool, txns []*types.Transaction) []error {
+	errs := make([]error, len(txns))
 	if len(txns) == 0 {
-		return nil
+		return errs
 	}
 
-	from := txns[0].From
-	for _, txn := range txns {
-		// Since this is a single point of inclusion for new transactions both
-		// to the promoted queue and pending queue we use this point to calculate the hash
-		txn.ComputeHash()
+	header := t.store.Header()
+	for i, txn := range txns {
+		errs[i] = t.prepareTxn(ctx, txn, header)
+	}
 
-		err := t.validateTx(txn)
-		if err != nil {
-			return err
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	stateNonces := map[types.Address]uint64{}
+	for i, txn := range txns {
+		if errs[i] != nil {
+			continue
 		}
+		stateNonce, ok := stateNonces[txn.From]
+		if !ok {
+			stateNonce = t.store.GetNonce(header.StateRoot, txn.From)
+			stateNonces[txn.From] = stateNonce
+		}
+		errs[i] = t.insertTxn(txn, local, stateNonce)
+	}
 
-		if txn.From == types.ZeroAddress {
-			txn.From, err = t.signer.Sender(txn)
-			if err != nil {
-				return fmt.Errorf("invalid sender")
-			}
-			from = txn.From
+	evicted := t.enforceLimits()
+	for i, txn := range txns {
+		if _, ok := evicted[txn.Hash]; ok && errs[i] == nil {
+			errs[i] = rejectErr(rejectPoolFull, "txpool is full")
+		}
+		if errs[i] != nil {
+			t.metrics.reject(errs[i])
 		} else {
-			// only if we are in dev mode we can accept
-			// a transaction without validation
-			if !t.dev {
-				return fmt.Errorf("cannot accept non-encrypted txn")
-			}
+			t.metrics.added.Inc()
 		}
+	}
+	return errs
+}
+
+// prepareTxn computes the hash of the txn, recovers its sender and
+// validates it against the header
+func (t *TxPool) prepareTxn(ctx string, txn *types.Transaction, header *types.Header) error {
+	// Since this is a single point of inclusion for new transactions both
+	// to the promoted queue and pending queue we use this point to calculate the hash
+	txn.ComputeHash()
+
+	if txn.From == types.ZeroAddress {
+		sender, err := t.signer.Sender(txn)
+		if err != nil {
+			return rejectErr(rejectSender, "invalid sender: %v", err)
+		}
+		txn.From = sender
+
+		if err := t.validateTx(txn, header); err != nil {
+			return err
+		}
+	} else {
+		// only if we are in dev mode we can accept
+		// a transaction without validation
+		if !t.dev {
+			return rejectErr(rejectUnsigned, "cannot accept non-encrypted txn")
+		}
+	}
+
+	t.logger.Debug("add txn", "ctx", ctx, "hash", txn.Hash, "from", txn.From)
+	return nil
+}
 
-		t.logger.Debug("add txn", "ctx", ctx, "hash", txn.Hash, "from", from)
+// insertTxn adds the txn to the queue of its account and promotes the
+// txns of the account that are ready, queueLock has to be held
+func (t *TxPool) insertTxn(txn *types.Transaction, local bool, stateNonce uint64) error {
+	from := txn.From
+	if local {
+		t.locals[from] = struct{}{}
 	}
 
 	txnsQueue, ok := t.queue[from]
 	if !ok {
-		stateRoot := t.store.Header().StateRoot
-
 		// initialize the txn queue for the account
 		txnsQueue = newTxQueue()
-		txnsQueue.nextNonce = t.store.GetNonce(stateRoot, from)
+		txnsQueue.nextNonce = stateNonce
 		t.queue[from] = txnsQueue
 	}
-	for _, txn := range txns {
-		txnsQueue.Add(txn)
+
+	if err := t.queueTxn(txnsQueue, txn, stateNonce); err != nil {
+		return err
+	}
+	t.promote(txnsQueue, stateNonce)
+	return nil
+}
+
+func (t *TxPool) queueTxn(txnsQueue *txQueue, txn *types.Transaction, stateNonce uint64) error {
+	if txn.Nonce < stateNonce {
+		return rejectErr(rejectNonce, "nonce too low, expected %d but found %d", stateNonce, txn.Nonce)
+	}
+	if txn.Nonce < txnsQueue.nextNonce {
+		// the txn replaces a pending one
+		return t.replacePending(txn)
+	}
+	if old := txnsQueue.Get(txn.Nonce); old != nil {
+		// the txn replaces a queued one
+		if old.Hash == txn.Hash {
+			return nil
+		}
+		if err := t.checkPriceBump(old, txn); err != nil {
+			return err
+		}
+		txnsQueue.Replace(txn)
+		t.emitEvent(proto.TxPoolEvent_REPLACED, txn, old)
+		return nil
+	}
+	// the txn is queued if there is a gap or the account is out of pending slots
+	queued := txn.Nonce > txnsQueue.nextNonce || t.pendingSlots(txnsQueue, stateNonce) == 0
+	if queued && uint64(txnsQueue.Len()) >= t.limits.AccountQueued {
+		return rejectErr(rejectAccountQueued, "too many queued txns for %s", txn.From)
+	}
+	txnsQueue.Add(txn)
+	t.emitEvent(proto.TxPoolEvent_ADDED, txn, nil)
+	return nil
+}
+
+// Content returns the pending and the queued transactions of each
+// account sorted by nonce
+func (t *TxPool) Content() (map[types.Address][]*types.Transaction, map[types.Address][]*types.Transaction) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	pending := map[types.Address][]*types.Transaction{}
+	for _, txn := range t.sorted.List() {
+		pending[txn.From] = append(pending[txn.From], txn)
+	}
+	queued := map[types.Address][]*types.Transaction{}
+	for addr, q := range t.queue {
+		if q.Len() != 0 {
+			queued[addr] = append([]*types.Transaction{}, q.txs...)
+		}
+	}
+
+	for _, content := range []map[types.Address][]*types.Transaction{pending, queued} {
+		for _, txns := range content {
+			sort.Slice(txns, func(i, j int) bool {
+				return txns[i].Nonce < txns[j].Nonce
+			})
+		}
+	}
+	return pending, queued
+}
+
+// dropTxn removes the txn from the pool, the pending txns of the account
+// after it are queued again since they cannot be executed without it
+func (t *TxPool) dropTxn(hash types.Hash) error {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	if txn := t.sorted.Get(hash); txn != nil {
+		q := t.queue[txn.From]
+		for _, pending := range t.sorted.List() {
+			if pending.From == txn.From && pending.Nonce > txn.Nonce {
+				t.sorted.Delete(pending)
+				q.Push(pending)
+			}
+		}
+		t.sorted.Delete(txn)
+		q.nextNonce = txn.Nonce
+
+		t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+		return nil
+	}
+	for _, q := range t.queue {
+		for _, txn := range q.txs {
+			if txn.Hash == hash {
+				q.Remove(txn.Nonce)
+				t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("txn %s not found", hash)
+}
+
+// flushQueued removes the queued txns of the account, it returns
+// the number of removed txns
+func (t *TxPool) flushQueued(addr types.Address) int {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	q, ok := t.queue[addr]
+	if !ok {
+		return 0
+	}
+	num := 0
+	for nonce := range q.added {
+		txn := q.Remove(nonce)
+		t.emitEvent(proto.TxPoolEvent_EVICTED, txn, nil)
+		num++
+	}
+	return num
+}
+
+func (t *TxPool) isLocal(addr types.Address) bool {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	_, ok := t.locals[addr]
+	return ok
+}
+
+// rotateJournal replaces the journal with the local txns still in the pool
+func (t *TxPool) rotateJournal() {
+	if t.journal == nil {
+		return
 	}
+	if err := t.journal.rotate(t.localTxns()); err != nil {
+		t.logger.Error("failed to rotate the journal", "err", err)
+	}
+}
 
-	for _, promoted := range txnsQueue.Promote() {
-		t.sorted.Push(promoted)
+// replacePending replaces the pending txn with the same sender and nonce
+func (t *TxPool) replacePending(txn *types.Transaction) error {
+	old := t.sorted.Find(txn.From, txn.Nonce)
+	if old == nil {
+		// the txn is being sealed
+		return rejectErr(rejectNonce, "txn with nonce %d cannot be replaced", txn.Nonce)
 	}
+	if old.Hash == txn.Hash {
+		return nil
+	}
+	if err := t.checkPriceBump(old, txn); err != nil {
+		return err
+	}
+	t.sorted.Delete(old)
+	if err := t.sorted.Push(txn); err != nil {
+		return err
+	}
+	t.emitEvent(proto.TxPoolEvent_REPLACED, txn, old)
 	return nil
 }
 
+// checkPriceBump checks that the gas price of the txn is at least the
+// price bump higher than the one of the txn it replaces
+func (t *TxPool) checkPriceBump(old, txn *types.Transaction) error {
+	oldPrice, price := gasPrice(old), gasPrice(txn)
+
+	threshold := new(big.Int).Mul(oldPrice, new(big.Int).SetUint64(100+t.priceBump))
+	threshold.Div(threshold, big.NewInt(100))
+
+	if price.Cmp(oldPrice) <= 0 || price.Cmp(threshold) < 0 {
+		return rejectErr(rejectUnderpriced, "replacement txn underpriced, the gas price has to be at least %s", threshold)
+	}
+
+	// the tips of the dynamic fee txns have to be bumped too
+	if txn.Type == types.DynamicFeeTx {
+		oldTip, tip := gasTipCap(old), gasTipCap(txn)
+
+		threshold := new(big.Int).Mul(oldTip, new(big.Int).SetUint64(100+t.priceBump))
+		threshold.Div(threshold, big.NewInt(100))
+
+		if tip.Cmp(oldTip) <= 0 || tip.Cmp(threshold) < 0 {
+			return rejectErr(rejectUnderpriced, "replacement txn underpriced, the gas tip cap has to be at least %s", threshold)
+		}
+	}
+	return nil
+}
+
+// gasTipCap returns the max priority fee of the txn, the gas price of the
+// txns without dynamic fee
+func gasTipCap(txn *types.Transaction) *big.Int {
+	if txn.Type != types.DynamicFeeTx {
+		return gasPrice(txn)
+	}
+	if txn.GasTipCap == nil {
+		return big.NewInt(0)
+	}
+	return txn.GasTipCap
+}
+
+func gasPrice(txn *types.Transaction) *big.Int {
+	if txn.GasPrice == nil {
+		return big.NewInt(0)
+	}
+	return txn.GasPrice
+}
+
+// Length returns the number of pending transactions
 func (t *TxPool) Length() uint64 {
 	return t.sorted.Length()
 }
 
+// Queued returns the number of transactions waiting for a gap in the
+// nonces of their account to be filled
+func (t *TxPool) Queued() uint64 {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	return t.queuedLocked()
+}
+
+// getTxn returns the pending or queued txn with the hash, if any
+func (t *TxPool) getTxn(hash types.Hash) *types.Transaction {
+	if txn := t.sorted.Get(hash); txn != nil {
+		return txn
+	}
+
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	for _, q := range t.queue {
+		for _, txn := range q.txs {
+			if txn.Hash == hash {
+				return txn
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TxPool) queuedLocked() uint64 {
+	num := 0
+	for _, q := range t.queue {
+		num += q.Len()
+	}
+	return uint64(num)
+}
+
 func (t *TxPool) Pop() (*types.Transaction, func()) {
 	txn := t.sorted.Pop()
 	if txn == nil {
@@ -223,66 +803,308 @@ func (t *TxPool) ResetWithHeader(h *types.Header) {
 
 // ProcessEvent processes the blockchain event and resets the txpool accordingly
 func (t *TxPool) ProcessEvent(evnt *blockchain.Event) {
-	addTxns := map[types.Hash]*types.Transaction{}
-	for _, evnt := range evnt.OldChain {
-		// reinject these transactions on the pool
-		block, ok := t.store.GetBlockByHash(evnt.Hash, true)
-		if !ok {
-			t.logger.Error("block not found on txn add", "hash", block.Hash())
-		} else {
-			for _, txn := range block.Transactions {
-				addTxns[txn.Hash] = txn
-			}
-		}
+	head := eventHead(evnt)
+	if head == nil {
+		return
+	}
+	if t.forks != nil {
+		t.SetBaseFee(chain.CalcBaseFee(t.forks, head))
 	}
 
 	delTxns := map[types.Hash]*types.Transaction{}
-	for _, evnt := range evnt.NewChain {
+	for _, block := range t.newBlocks(evnt, head) {
 		// remove these transactions from the pool
-		block, ok := t.store.GetBlockByHash(evnt.Hash, true)
-		if !ok {
-			t.logger.Error("block not found on txn del", "hash", block.Hash())
-		} else {
-			for _, txn := range block.Transactions {
-				delete(addTxns, txn.Hash)
-				delTxns[txn.Hash] = txn
-			}
+		for _, txn := range block.Transactions {
+			delTxns[txn.Hash] = txn
 		}
 	}
 
-	// try to include again the transactions in the sorted list
-	for _, txn := range addTxns {
-		if err := t.addImpl("reorg", txn); err != nil {
-			t.logger.Error("failed to add txn", "err", err)
-		}
+	// try to include again the transactions of the dropped blocks
+	if len(evnt.OldChain) != 0 {
+		t.reinject(evnt.OldChain, delTxns, head)
 	}
 
 	// remove the mined transactions from the sorted list
 	for _, txn := range delTxns {
 		t.sorted.Delete(txn)
 	}
+
+	t.promoteQueued(head)
+
+	// drop the mined transactions from the journal
+	t.rotateJournal()
+}
+
+// WatchChain processes the reorgs of the subscription until the pool is
+// closed, the transactions of the dropped blocks are added again
+func (t *TxPool) WatchChain(sub blockchain.Subscription) {
+	t.chainSub = sub
+
+	go func() {
+		for {
+			evnt := sub.GetEvent()
+			if evnt == nil {
+				return
+			}
+			if evnt.Type == blockchain.EventReorg {
+				t.ProcessEvent(evnt)
+			}
+		}
+	}()
+}
+
+// eventHead returns the highest header of the new chain of the event
+func eventHead(evnt *blockchain.Event) *types.Header {
+	var head *types.Header
+	for _, header := range evnt.NewChain {
+		if head == nil || header.Number > head.Number {
+			head = header
+		}
+	}
+	return head
+}
+
+// newBlocks returns the blocks that become canonical with the event, after
+// a reorg they are all the blocks from the head down to the lowest dropped one
+func (t *TxPool) newBlocks(evnt *blockchain.Event, head *types.Header) []*types.Block {
+	if len(evnt.OldChain) == 0 {
+		blocks := []*types.Block{}
+		for _, header := range evnt.NewChain {
+			if block, ok := t.getBlock(header.Hash); ok {
+				blocks = append(blocks, block)
+			}
+		}
+		return blocks
+	}
+
+	lowest := evnt.OldChain[0]
+	for _, header := range evnt.OldChain {
+		if header.Number < lowest.Number {
+			lowest = header
+		}
+	}
+
+	blocks := []*types.Block{}
+	for hash := head.Hash; hash != lowest.ParentHash; {
+		block, ok := t.getBlock(hash)
+		if !ok {
+			break
+		}
+		blocks = append(blocks, block)
+
+		if block.Number() <= lowest.Number {
+			break
+		}
+		hash = block.ParentHash()
+	}
+	return blocks
+}
+
+func (t *TxPool) getBlock(hash types.Hash) (*types.Block, bool) {
+	block, ok := t.store.GetBlockByHash(hash, true)
+	if !ok {
+		t.logger.Error("block not found", "hash", hash)
+		return nil, false
+	}
+	for _, txn := range block.Transactions {
+		txn.ComputeHash()
+	}
+	return block, true
+}
+
+// reinject adds again the txns of the blocks dropped by a reorg that are not
+// included in the new chain. The pending txns of their accounts are demoted
+// so that all of them are promoted again in nonce order
+func (t *TxPool) reinject(oldChain []*types.Header, included map[types.Hash]*types.Transaction, head *types.Header) {
+	txns := []*types.Transaction{}
+	senders := map[types.Address]struct{}{}
+	for _, header := range oldChain {
+		block, ok := t.getBlock(header.Hash)
+		if !ok {
+			continue
+		}
+		for _, txn := range block.Transactions {
+			if _, ok := included[txn.Hash]; ok {
+				continue
+			}
+			from := txn.From
+			if from == types.ZeroAddress {
+				sender, err := t.signer.Sender(txn)
+				if err != nil {
+					t.logger.Error("failed to recover the sender of a dropped txn", "hash", txn.Hash, "err", err)
+					continue
+				}
+				from = sender
+			}
+			senders[from] = struct{}{}
+			txns = append(txns, txn)
+		}
+	}
+	if len(txns) == 0 {
+		return
+	}
+	sort.Slice(txns, func(i, j int) bool {
+		return txns[i].Nonce < txns[j].Nonce
+	})
+
+	t.demote(senders, head)
+
+	for i, err := range t.addBatch("reorg", false, txns) {
+		if err != nil {
+			t.logger.Error("failed to add txn", "hash", txns[i].Hash, "err", err)
+		}
+	}
+	t.logger.Debug("reinject txns of the dropped blocks", "txns", len(txns))
 }
 
-func (t *TxPool) validateTx(tx *types.Transaction) error {
-	/*
-		if tx.Size() > 32*1024 {
-			return fmt.Errorf("oversize data")
+// demote moves back to the queue the pending txns of the accounts whose nonce
+// at the header is below their pending ones
+func (t *TxPool) demote(senders map[types.Address]struct{}, head *types.Header) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	for addr := range senders {
+		q, ok := t.queue[addr]
+		if !ok {
+			continue
+		}
+		nonce := t.store.GetNonce(head.StateRoot, addr)
+		if nonce >= q.nextNonce {
+			continue
+		}
+		for _, txn := range t.sorted.List() {
+			if txn.From == addr && txn.Nonce >= nonce {
+				t.sorted.Delete(txn)
+				q.Push(txn)
+			}
+		}
+		q.nextNonce = nonce
+	}
+}
+
+// promote moves the txns of the queue that are ready to the pending ones
+func (t *TxPool) promote(q *txQueue, stateNonce uint64) {
+	promoted, queued := q.Promote(t.pendingSlots(q, stateNonce))
+	for _, txn := range promoted {
+		t.sorted.Push(txn)
+	}
+	t.metrics.promoted(queued)
+}
+
+// promoteQueued moves the next nonce of the accounts to their nonce at the
+// header, the transactions mined by other nodes may have filled the gap of
+// the queued ones. The pending transactions below the nonce are dropped
+func (t *TxPool) promoteQueued(header *types.Header) {
+	t.queueLock.Lock()
+	defer t.queueLock.Unlock()
+
+	nonces := map[types.Address]uint64{}
+	for addr, q := range t.queue {
+		nonce := t.store.GetNonce(header.StateRoot, addr)
+		if nonce > q.nextNonce {
+			q.nextNonce = nonce
+		}
+		t.promote(q, nonce)
+		nonces[addr] = nonce
+	}
+	for _, txn := range t.sorted.List() {
+		if nonce, ok := nonces[txn.From]; ok && txn.Nonce < nonce {
+			t.sorted.Delete(txn)
+		}
+	}
+}
+
+// validateTx checks that the transaction can be executed on top of the
+// header before it is admitted in the pool
+func (t *TxPool) validateTx(txn *types.Transaction, header *types.Header) error {
+	if size := txSize(txn); size > txMaxSize {
+		return rejectErr(rejectOversized, "oversized data: %d bytes, max %d", size, txMaxSize)
+	}
+	if txn.Gas > header.GasLimit {
+		return rejectErr(rejectGasLimit, "exceeds block gas limit: %d > %d", txn.Gas, header.GasLimit)
+	}
+
+	forks := chain.ForksInTime{}
+	if t.forks != nil {
+		forks = t.forks.At(header.Number + 1)
+	}
+	switch txn.Type {
+	case types.AccessListTx:
+		if !forks.Berlin {
+			return rejectErr(rejectTxType, "transaction type %d not supported", txn.Type)
 		}
-		if tx.Value.Sign() < 0 {
-			return fmt.Errorf("negative value")
+	case types.DynamicFeeTx:
+		if !forks.London {
+			return rejectErr(rejectTxType, "transaction type %d not supported", txn.Type)
 		}
-	*/
+		if txn.GasTipCap == nil || txn.GasTipCap.Cmp(gasPrice(txn)) > 0 {
+			return rejectErr(rejectFeeCap, "max priority fee per gas higher than max fee per gas")
+		}
+	}
+	if intrinsicGas := state.TransactionGasCost(txn, forks); txn.Gas < intrinsicGas {
+		return rejectErr(rejectIntrinsicGas, "intrinsic gas too low: %d < %d", txn.Gas, intrinsicGas)
+	}
+	if txn.IsContractCreation() && forks.EIP158 && len(txn.Input) > state.MaxInitCodeSize {
+		return rejectErr(rejectInitCode, "max init code size exceeded: %d > %d", len(txn.Input), state.MaxInitCodeSize)
+	}
+
+	// the sender has to pay the value and all the gas upfront
+	cost := new(big.Int).Mul(gasPrice(txn), new(big.Int).SetUint64(txn.Gas))
+	if txn.Value != nil {
+		cost.Add(cost, txn.Value)
+	}
+	if balance := t.store.GetBalance(header.StateRoot, txn.From); balance.Cmp(cost) < 0 {
+		return rejectErr(rejectFunds, "insufficient funds for gas * price + value: balance %s, cost %s", balance, cost)
+	}
+
+	// only the txns that can be executed on top of the header are simulated,
+	// the others depend on the txns before them
+	if t.simulator != nil && txn.Nonce == t.store.GetNonce(header.StateRoot, txn.From) {
+		returnValue, failed, err := t.simulator.ApplyTxn(header, txn)
+		if err != nil {
+			return rejectErr(rejectReverted, "simulation failed: %v", err)
+		}
+		if failed {
+			return &txnError{
+				reason:      rejectReverted,
+				msg:         "execution reverted",
+				returnValue: returnValue,
+			}
+		}
+	}
 	return nil
 }
 
 type txQueue struct {
 	txs       txHeap
 	nextNonce uint64
+
+	// size is the size in bytes of the queued txns and
+	// added is the arrival of each queued nonce
+	size  uint64
+	added map[uint64]arrival
+}
+
+// arrival is the order and the time a txn was queued
+type arrival struct {
+	seq  uint64
+	time time.Time
+}
+
+func newArrival() arrival {
+	return arrival{
+		seq:  atomic.AddUint64(&queueSeq, 1),
+		time: time.Now(),
+	}
 }
 
+// queueSeq orders the queued txns of all the accounts by arrival
+var queueSeq uint64
+
 func newTxQueue() *txQueue {
 	return &txQueue{
-		txs: txHeap{},
+		txs:   txHeap{},
+		added: map[uint64]arrival{},
 	}
 }
 
@@ -302,8 +1124,9 @@ func (t *txQueue) Add(tx *types.Transaction) {
 	t.Push(tx)
 }
 
-// Promote promotes all the new valid transactions
-func (t *txQueue) Promote() []*types.Transaction {
+// Promote promotes up to max of the new valid transactions, it
+// returns how long each of them was queued
+func (t *txQueue) Promote(max uint64) ([]*types.Transaction, []time.Duration) {
 	// Remove elements lower than nonce
 	for {
 		tx := t.Peek()
@@ -315,46 +1138,85 @@ func (t *txQueue) Promote() []*types.Transaction {
 
 	// Promote elements
 	tx := t.Peek()
-	if tx == nil || tx.Nonce != t.nextNonce {
-		return nil
+	if tx == nil || tx.Nonce != t.nextNonce || max == 0 {
+		return nil, nil
 	}
 
 	promote := []*types.Transaction{}
+	queued := []time.Duration{}
 	for {
 		promote = append(promote, tx)
+		queued = append(queued, time.Since(t.added[tx.Nonce].time))
 		t.Pop()
 
 		tx2 := t.Peek()
-		if tx2 == nil || tx.Nonce+1 != tx2.Nonce {
+		if tx2 == nil || tx.Nonce+1 != tx2.Nonce || uint64(len(promote)) == max {
 			break
 		}
 		tx = tx2
 	}
-	if len(promote) == 0 {
-		return nil
-	}
 
 	lastTxn := promote[len(promote)-1]
 	t.nextNonce = lastTxn.Nonce + 1
 
-	return promote
+	return promote, queued
 }
 
 func (t *txQueue) Peek() *types.Transaction {
 	return t.txs.Peek()
 }
 
+// Get returns the queued txn with the nonce, if any
+func (t *txQueue) Get(nonce uint64) *types.Transaction {
+	for _, txn := range t.txs {
+		if txn.Nonce == nonce {
+			return txn
+		}
+	}
+	return nil
+}
+
+// Replace replaces the queued txn with the same nonce
+func (t *txQueue) Replace(tx *types.Transaction) {
+	for i, txn := range t.txs {
+		if txn.Nonce == tx.Nonce {
+			t.txs[i] = tx
+			t.size = t.size - txSize(txn) + txSize(tx)
+			t.added[tx.Nonce] = newArrival()
+			return
+		}
+	}
+}
+
+// Remove removes the queued txn with the nonce
+func (t *txQueue) Remove(nonce uint64) *types.Transaction {
+	for i, txn := range t.txs {
+		if txn.Nonce == nonce {
+			heap.Remove(&t.txs, i)
+			t.size -= txSize(txn)
+			delete(t.added, nonce)
+			return txn
+		}
+	}
+	return nil
+}
+
 func (t *txQueue) Push(tx *types.Transaction) {
-	// try to find the txn in the set
-	i := sort.Search(len(t.txs), func(i int) bool {
-		return t.txs[0].Nonce >= tx.Nonce
-	})
-	if i < len(t.txs) && t.txs[i].Nonce == tx.Nonce {
-		// txns with the same nonce is on the list
-		return
+	// the heap is not sorted, look for a txn with the same nonce in the set
+	for _, txn := range t.txs {
+		if txn.Nonce == tx.Nonce {
+			return
+		}
 	}
 
 	heap.Push(&t.txs, tx)
+	t.size += txSize(tx)
+	t.added[tx.Nonce] = newArrival()
+}
+
+// Len returns the number of queued transactions
+func (t *txQueue) Len() int {
+	return t.txs.Len()
 }
 
 func (t *txQueue) Pop() *types.Transaction {
@@ -363,7 +1225,10 @@ func (t *txQueue) Pop() *types.Transaction {
 		return nil
 	}
 
-	return res.(*types.Transaction)
+	tx := res.(*types.Transaction)
+	t.size -= txSize(tx)
+	delete(t.added, tx.Nonce)
+	return tx
 }
 
 // Nonce ordered heap
@@ -407,19 +1272,28 @@ type pricedTx struct {
 	tx    *types.Transaction
 	from  types.Address
 	price *big.Int
+	size  uint64
 	index int
 }
 
 type txPriceHeap struct {
-	lock  sync.Mutex
-	index map[types.Hash]*pricedTx
-	heap  txPriceHeapImpl
+	lock   sync.Mutex
+	index  map[types.Hash]*pricedTx
+	nonces map[accountNonce]*pricedTx
+	heap   txPriceHeapImpl
+	size   uint64
+}
+
+type accountNonce struct {
+	from  types.Address
+	nonce uint64
 }
 
 func newTxPriceHeap() *txPriceHeap {
 	return &txPriceHeap{
-		index: make(map[types.Hash]*pricedTx),
-		heap:  make(txPriceHeapImpl, 0),
+		index:  make(map[types.Hash]*pricedTx),
+		nonces: make(map[accountNonce]*pricedTx),
+		heap:   make(txPriceHeapImpl, 0),
 	}
 }
 
@@ -430,6 +1304,14 @@ func (t *txPriceHeap) Length() uint64 {
 	return uint64(len(t.index))
 }
 
+// Size returns the size in bytes of the transactions in the heap
+func (t *txPriceHeap) Size() uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.size
+}
+
 func (t *txPriceHeap) Delete(tx *types.Transaction) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
@@ -437,7 +1319,43 @@ func (t *txPriceHeap) Delete(tx *types.Transaction) {
 	if item, ok := t.index[tx.Hash]; ok {
 		heap.Remove(&t.heap, item.index)
 		delete(t.index, tx.Hash)
+		delete(t.nonces, accountNonce{item.from, tx.Nonce})
+		t.size -= item.size
+	}
+}
+
+// List returns the transactions in the heap
+func (t *txPriceHeap) List() []*types.Transaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	txns := make([]*types.Transaction, 0, len(t.index))
+	for _, item := range t.index {
+		txns = append(txns, item.tx)
+	}
+	return txns
+}
+
+// Get returns the txn with the hash, if any
+func (t *txPriceHeap) Get(hash types.Hash) *types.Transaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if item, ok := t.index[hash]; ok {
+		return item.tx
+	}
+	return nil
+}
+
+// Find returns the txn of the account with the nonce, if any
+func (t *txPriceHeap) Find(from types.Address, nonce uint64) *types.Transaction {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if item, ok := t.nonces[accountNonce{from, nonce}]; ok {
+		return item.tx
 	}
+	return nil
 }
 
 func (t *txPriceHeap) Push(tx *types.Transaction) error {
@@ -454,8 +1372,11 @@ func (t *txPriceHeap) Push(tx *types.Transaction) error {
 		tx:    tx,
 		from:  tx.From,
 		price: price,
+		size:  txSize(tx),
 	}
+	t.size += pTx.size
 	t.index[tx.Hash] = pTx
+	t.nonces[accountNonce{tx.From, tx.Nonce}] = pTx
 	heap.Push(&t.heap, pTx)
 	return nil
 }
@@ -469,6 +1390,8 @@ func (t *txPriceHeap) Pop() *pricedTx {
 	}
 	tx := heap.Pop(&t.heap).(*pricedTx)
 	delete(t.index, tx.tx.Hash)
+	delete(t.nonces, accountNonce{tx.from, tx.tx.Nonce})
+	t.size -= tx.size
 	return tx
 }
 
@@ -1,13 +1,25 @@
 package txpool
 
 import (
-	"fmt"
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/0xPolygon/minimal/blockchain"
+	"github.com/0xPolygon/minimal/chain"
 	"github.com/0xPolygon/minimal/crypto"
 	"github.com/0xPolygon/minimal/network"
+	"github.com/0xPolygon/minimal/state"
+	"github.com/0xPolygon/minimal/txpool/proto"
 	"github.com/0xPolygon/minimal/types"
 	"github.com/hashicorp/go-hclog"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,7 +43,8 @@ func TestMultipleTransactions(t *testing.T) {
 
 	from2 := types.Address{0x2}
 	txn1 := &types.Transaction{
-		From: from2,
+		From:     from2,
+		GasPrice: big.NewInt(1),
 	}
 	assert.NoError(t, pool.addImpl("", txn1))
 	assert.NoError(t, pool.addImpl("", txn1))
@@ -46,13 +59,13 @@ func TestBroadcast(t *testing.T) {
 	key0, _ := crypto.GenerateKey()
 	addr0 := crypto.PubKeyToAddress(&key0.PublicKey)
 
-	fmt.Println("-- addr")
-	fmt.Println(addr0)
-
 	signer := &crypto.FrontierSigner{}
 
 	createPool := func() *TxPool {
-		pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, network.CreateServer(t, nil))
+		store := &mockStore{
+			balances: map[types.Address]*big.Int{addr0: big.NewInt(1000000000)},
+		}
+		pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, network.CreateServer(t, nil))
 		assert.NoError(t, err)
 		pool.AddSigner(signer)
 		return pool
@@ -64,27 +77,42 @@ func TestBroadcast(t *testing.T) {
 	network.MultiJoin(t, pool1.network, pool2.network)
 
 	// broadcast txn1 from pool1
-	txn1 := &types.Transaction{}
+	txn1 := &types.Transaction{
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		Value:    big.NewInt(0),
+	}
 	txn1, err := signer.SignTx(txn1, key0)
 	assert.NoError(t, err)
 
 	assert.NoError(t, pool1.AddTx(txn1))
-	fmt.Println(pool1.Length())
+	assert.Equal(t, uint64(1), pool1.Length())
 }
 
 type mockStore struct {
+	nonces   map[types.Address]uint64
+	balances map[types.Address]*big.Int
+	blocks   map[types.Hash]*types.Block
 }
 
 func (m *mockStore) GetNonce(root types.Hash, addr types.Address) uint64 {
-	return 0
+	return m.nonces[addr]
+}
+
+func (m *mockStore) GetBalance(root types.Hash, addr types.Address) *big.Int {
+	if balance, ok := m.balances[addr]; ok {
+		return balance
+	}
+	return big.NewInt(0)
 }
 
-func (m *mockStore) GetBlockByHash(types.Hash, bool) (*types.Block, bool) {
-	return nil, false
+func (m *mockStore) GetBlockByHash(hash types.Hash, full bool) (*types.Block, bool) {
+	block, ok := m.blocks[hash]
+	return block, ok
 }
 
 func (m *mockStore) Header() *types.Header {
-	return &types.Header{}
+	return &types.Header{GasLimit: 8000000}
 }
 
 func TestTxnQueue_Promotion(t *testing.T) {
@@ -95,7 +123,8 @@ func TestTxnQueue_Promotion(t *testing.T) {
 	addr1 := types.Address{0x1}
 
 	pool.addImpl("", &types.Transaction{
-		From: addr1,
+		From:     addr1,
+		GasPrice: big.NewInt(1),
 	})
 
 	nonce, _ := pool.GetNonce(addr1)
@@ -104,11 +133,895 @@ func TestTxnQueue_Promotion(t *testing.T) {
 	// though txn0 is not being processed yet and the current nonce is 0
 	// we need to consider that txn0 is on the sorted pool so this one is promoted too
 	pool.addImpl("", &types.Transaction{
-		From:  addr1,
-		Nonce: 1,
+		From:     addr1,
+		Nonce:    1,
+		GasPrice: big.NewInt(1),
 	})
 
 	nonce, _ = pool.GetNonce(addr1)
 	assert.Equal(t, nonce, uint64(2))
 	assert.Equal(t, pool.Length(), uint64(2))
 }
+
+func TestTxnQueue_NonceGap(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	addr1 := types.Address{0x1}
+
+	// txn2 is queued until txn1 fills the gap
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 2, GasPrice: big.NewInt(1)}))
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 0, GasPrice: big.NewInt(1)}))
+	assert.Equal(t, uint64(1), pool.Length())
+	assert.Equal(t, uint64(1), pool.Queued())
+
+	pending, queued := pool.Content()
+	assert.Equal(t, uint64(0), pending[addr1][0].Nonce)
+	assert.Equal(t, uint64(2), queued[addr1][0].Nonce)
+
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 1, GasPrice: big.NewInt(1)}))
+	assert.Equal(t, uint64(3), pool.Length())
+	assert.Equal(t, uint64(0), pool.Queued())
+
+	nonce, _ := pool.GetNonce(addr1)
+	assert.Equal(t, uint64(3), nonce)
+}
+
+func TestTxnQueue_NonceGapMined(t *testing.T) {
+	store := &mockStore{
+		nonces: map[types.Address]uint64{},
+		blocks: map[types.Hash]*types.Block{},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	addr1 := types.Address{0x1}
+
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 1, GasPrice: big.NewInt(1)}))
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 3, GasPrice: big.NewInt(1)}))
+	assert.Equal(t, uint64(0), pool.Length())
+	assert.Equal(t, uint64(2), pool.Queued())
+
+	// the txn with nonce 0 is mined by another node
+	block := &types.Block{Header: &types.Header{Number: 1}}
+	block.Header.ComputeHash()
+	store.blocks[block.Hash()] = block
+	store.nonces[addr1] = 1
+
+	pool.ProcessEvent(&blockchain.Event{NewChain: []*types.Header{block.Header}})
+	assert.Equal(t, uint64(1), pool.Length())
+	assert.Equal(t, uint64(1), pool.Queued())
+
+	// the txns below the nonce of the account are rejected
+	assert.Error(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 0, GasPrice: big.NewInt(1)}))
+}
+
+func TestReorg(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	addr0 := crypto.PubKeyToAddress(&key0.PublicKey)
+
+	signer := crypto.NewEIP155Signer(100)
+
+	store := &mockStore{
+		nonces:   map[types.Address]uint64{},
+		balances: map[types.Address]*big.Int{addr0: big.NewInt(1000000000)},
+		blocks:   map[types.Hash]*types.Block{},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.AddSigner(signer)
+
+	newTxn := func(nonce uint64) *types.Transaction {
+		txn, err := signer.SignTx(&types.Transaction{
+			To:       &types.Address{0x1},
+			Nonce:    nonce,
+			Gas:      21000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		}, key0)
+		assert.NoError(t, err)
+
+		// the txns of the stored blocks have no sender nor hash
+		return &types.Transaction{
+			Nonce:    txn.Nonce,
+			GasPrice: txn.GasPrice,
+			Gas:      txn.Gas,
+			To:       txn.To,
+			Value:    txn.Value,
+			V:        txn.V,
+			R:        txn.R,
+			S:        txn.S,
+		}
+	}
+	newBlock := func(number uint64, parent types.Hash, txns ...*types.Transaction) *types.Block {
+		block := &types.Block{
+			Header:       &types.Header{Number: number, ParentHash: parent, ExtraData: []byte{byte(len(store.blocks))}},
+			Transactions: txns,
+		}
+		block.Header.ComputeHash()
+		store.blocks[block.Hash()] = block
+		return block
+	}
+
+	// the txns 0 and 1 are mined in the old chain
+	genesis := types.Hash{0x1}
+	old := newBlock(1, genesis, newTxn(0), newTxn(1))
+	store.nonces[addr0] = 2
+
+	assert.NoError(t, pool.addImpl("", newTxn(2)))
+	assert.Equal(t, uint64(1), pool.Length())
+
+	// the new chain only includes the txn 0
+	side := newBlock(1, genesis, newTxn(0))
+	head := newBlock(2, side.Hash())
+	store.nonces[addr0] = 1
+
+	pool.ProcessEvent(&blockchain.Event{
+		Type:     blockchain.EventReorg,
+		OldChain: []*types.Header{old.Header},
+		NewChain: []*types.Header{head.Header},
+	})
+
+	// the txn 1 is pending again before the txn 2
+	assert.Equal(t, uint64(2), pool.Length())
+	assert.Equal(t, uint64(0), pool.Queued())
+
+	pending := pool.Pending()
+	assert.Equal(t, uint64(1), pending.Peek().Nonce)
+	pending.Shift()
+	assert.Equal(t, uint64(2), pending.Peek().Nonce)
+
+	nonce, _ := pool.GetNonce(addr0)
+	assert.Equal(t, uint64(3), nonce)
+}
+
+func TestTxnQueue_MaxQueued(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+	pool.SetLimits(Limits{AccountQueued: 2})
+
+	addr1 := types.Address{0x1}
+
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 1, GasPrice: big.NewInt(1)}))
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 2, GasPrice: big.NewInt(1)}))
+	assert.Error(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 3, GasPrice: big.NewInt(1)}))
+
+	// the next nonce is not queued
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: addr1, Nonce: 0, GasPrice: big.NewInt(1)}))
+	assert.Equal(t, uint64(3), pool.Length())
+}
+
+func TestTxnQueue_Replace(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	events := pool.subscribe()
+
+	addr1 := types.Address{0x1}
+	newTxn := func(nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: addr1, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+
+	txn0 := newTxn(0, 100)
+	assert.NoError(t, pool.addImpl("", txn0))
+	assert.Equal(t, proto.TxPoolEvent_ADDED, (<-events).Type)
+
+	// the price bump is 10% by default
+	assert.Error(t, pool.addImpl("", newTxn(0, 109)))
+
+	txn1 := newTxn(0, 110)
+	assert.NoError(t, pool.addImpl("", txn1))
+	assert.Equal(t, uint64(1), pool.Length())
+
+	evnt := <-events
+	assert.Equal(t, proto.TxPoolEvent_REPLACED, evnt.Type)
+	assert.Equal(t, txn1.Hash.String(), evnt.Hash)
+	assert.Equal(t, txn0.Hash.String(), evnt.Replaced)
+
+	txn, _ := pool.Pop()
+	assert.Equal(t, txn1.Hash, txn.Hash)
+
+	// the queued txns can be replaced too
+	pool.SetPriceBump(50)
+
+	assert.NoError(t, pool.addImpl("", newTxn(2, 100)))
+	assert.Error(t, pool.addImpl("", newTxn(2, 120)))
+	assert.NoError(t, pool.addImpl("", newTxn(2, 150)))
+	assert.Equal(t, uint64(1), pool.Queued())
+	assert.Equal(t, big.NewInt(150), pool.queue[addr1].Get(2).GasPrice)
+}
+
+func TestJournal(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("/tmp", "txpool-journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "journal")
+	store := &mockStore{
+		nonces: map[types.Address]uint64{},
+		blocks: map[types.Hash]*types.Block{},
+	}
+	createPool := func() *TxPool {
+		pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+		assert.NoError(t, err)
+		pool.EnableDev()
+		assert.NoError(t, pool.EnableJournal(path))
+		return pool
+	}
+
+	addr1 := types.Address{0x1}
+
+	pool := createPool()
+	assert.NoError(t, pool.AddTx(&types.Transaction{From: addr1, Nonce: 0, GasPrice: big.NewInt(1)}))
+	assert.NoError(t, pool.AddTx(&types.Transaction{From: addr1, Nonce: 2, GasPrice: big.NewInt(1)}))
+
+	// the gossiped txns are not journaled
+	assert.NoError(t, pool.addImpl("gossip", &types.Transaction{From: types.Address{0x2}, GasPrice: big.NewInt(1)}))
+	assert.NoError(t, pool.Close())
+
+	pool = createPool()
+	assert.Equal(t, uint64(1), pool.Length())
+	assert.Equal(t, uint64(1), pool.Queued())
+
+	// the mined txns are dropped from the journal
+	block := &types.Block{Header: &types.Header{Number: 1}}
+	block.Header.ComputeHash()
+	store.blocks[block.Hash()] = block
+	store.nonces[addr1] = 1
+
+	pool.ProcessEvent(&blockchain.Event{NewChain: []*types.Header{block.Header}})
+	assert.NoError(t, pool.Close())
+
+	num, err := newTxJournal(path).load(func(txn *types.Transaction) error {
+		assert.Equal(t, uint64(2), txn.Nonce)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, num)
+}
+
+func TestLimits(t *testing.T) {
+	newPool := func(limits Limits) *TxPool {
+		pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+		assert.NoError(t, err)
+		pool.EnableDev()
+		pool.SetLimits(limits)
+		return pool
+	}
+	newTxn := func(from byte, nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: types.Address{from}, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		pool := newPool(Limits{MaxPending: 3})
+
+		assert.NoError(t, pool.addImpl("", newTxn(1, 0, 1)))
+		assert.NoError(t, pool.addImpl("", newTxn(1, 1, 1)))
+		assert.NoError(t, pool.addImpl("", newTxn(2, 0, 5)))
+
+		// the last txn of the lowest priced account is evicted
+		assert.NoError(t, pool.addImpl("", newTxn(3, 0, 3)))
+		assert.Equal(t, uint64(3), pool.Length())
+
+		nonce, _ := pool.GetNonce(types.Address{1})
+		assert.Equal(t, uint64(1), nonce)
+
+		// a txn cheaper than all the pending ones does not fit
+		assert.Error(t, pool.addImpl("", newTxn(4, 0, 0)))
+
+		// the local txns are not evicted
+		assert.NoError(t, pool.AddTx(newTxn(5, 0, 0)))
+		assert.NotNil(t, pool.sorted.Find(types.Address{5}, 0))
+		assert.Equal(t, uint64(3), pool.Length())
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		pool := newPool(Limits{MaxQueued: 2})
+
+		assert.NoError(t, pool.addImpl("", newTxn(1, 5, 1)))
+		assert.NoError(t, pool.addImpl("", newTxn(2, 5, 2)))
+		assert.NoError(t, pool.addImpl("", newTxn(3, 5, 3)))
+
+		// the oldest queued txn is evicted
+		assert.Equal(t, uint64(2), pool.Queued())
+		assert.Nil(t, pool.queue[types.Address{1}].Get(5))
+	})
+
+	t.Run("account", func(t *testing.T) {
+		pool := newPool(Limits{AccountPending: 2, AccountQueued: 1})
+
+		assert.NoError(t, pool.addImpl("", newTxn(1, 0, 1)))
+		assert.NoError(t, pool.addImpl("", newTxn(1, 1, 1)))
+
+		// the account is out of pending slots, the next txn is queued
+		assert.NoError(t, pool.addImpl("", newTxn(1, 2, 1)))
+		assert.Error(t, pool.addImpl("", newTxn(1, 3, 1)))
+		assert.Equal(t, uint64(2), pool.Length())
+		assert.Equal(t, uint64(1), pool.Queued())
+
+		// the other accounts are not affected
+		assert.NoError(t, pool.addImpl("", newTxn(2, 0, 2)))
+		assert.Equal(t, uint64(3), pool.Length())
+	})
+
+	t.Run("size", func(t *testing.T) {
+		txn := newTxn(1, 0, 1)
+		pool := newPool(Limits{MaxSize: 2 * txSize(txn)})
+
+		assert.NoError(t, pool.addImpl("", txn))
+		assert.NoError(t, pool.addImpl("", newTxn(2, 3, 1)))
+		assert.NoError(t, pool.addImpl("", newTxn(3, 0, 2)))
+
+		// the queued txn is evicted before the pending ones
+		assert.Equal(t, uint64(0), pool.Queued())
+		assert.Equal(t, uint64(2), pool.Length())
+		assert.Equal(t, pool.Size(), 2*txSize(txn))
+	})
+
+	t.Run("lifetime", func(t *testing.T) {
+		pool := newPool(Limits{Lifetime: 50 * time.Millisecond})
+
+		txn := newTxn(1, 5, 1)
+		assert.NoError(t, pool.addImpl("", txn))
+		assert.NoError(t, pool.AddTx(newTxn(2, 5, 1)))
+		time.Sleep(100 * time.Millisecond)
+		assert.NoError(t, pool.addImpl("", newTxn(3, 5, 1)))
+
+		events := pool.subscribe()
+
+		// only the old txn of the remote account expires
+		assert.Equal(t, 1, pool.expireQueued())
+		assert.Equal(t, uint64(2), pool.Queued())
+		assert.Nil(t, pool.queue[types.Address{1}].Get(5))
+
+		evnt := <-events
+		assert.Equal(t, proto.TxPoolEvent_EVICTED, evnt.Type)
+		assert.Equal(t, txn.Hash.String(), evnt.Hash)
+	})
+}
+
+func TestAddTxs(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	newTxn := func(from byte, nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: types.Address{from}, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+	errs := pool.AddTxs([]*types.Transaction{
+		newTxn(1, 0, 100),
+		newTxn(1, 0, 105),
+		newTxn(1, 1, 100),
+		newTxn(2, 0, 200),
+	})
+
+	// only the underpriced replacement is rejected
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[3])
+	assert.Equal(t, uint64(3), pool.Length())
+}
+
+func TestMetrics(t *testing.T) {
+	store := &mockStore{
+		nonces: map[types.Address]uint64{
+			{0x1}: 1,
+		},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+	pool.SetLimits(Limits{MaxPending: 2})
+
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, pool.EnableMetrics(reg))
+
+	newTxn := func(from byte, nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: types.Address{from}, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+
+	// the nonce of the account is 1
+	assert.Error(t, pool.addImpl("", newTxn(1, 0, 1)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(pool.metrics.rejected.WithLabelValues(rejectNonce)))
+
+	// the queued txn is promoted once the gap is filled
+	assert.NoError(t, pool.addImpl("", newTxn(1, 2, 2)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(pool.metrics.queued))
+	assert.NoError(t, pool.addImpl("", newTxn(1, 1, 3)))
+	assert.Equal(t, float64(2), testutil.ToFloat64(pool.metrics.pending))
+	assert.Equal(t, 1, testutil.CollectAndCount(pool.metrics.promotionLatency))
+
+	// the pool is full
+	assert.NoError(t, pool.addImpl("", newTxn(2, 0, 4)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(pool.metrics.evicted.WithLabelValues(evictedPending)))
+	assert.Equal(t, float64(3), testutil.ToFloat64(pool.metrics.added))
+}
+
+func TestPriority(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+	pool.SetLimits(Limits{MaxPending: 3})
+	pool.SetPriority(Priority{
+		Senders: []types.Address{{0x1}},
+		Types:   []types.TxType{types.AccessListTx},
+	})
+
+	newTxn := func(from byte, nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: types.Address{from}, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+	assert.NoError(t, pool.addImpl("", newTxn(1, 0, 1)))
+	assert.NoError(t, pool.addImpl("", newTxn(2, 0, 100)))
+
+	typed := newTxn(3, 0, 2)
+	typed.Type = types.AccessListTx
+	assert.NoError(t, pool.addImpl("", typed))
+
+	// the txns of the lane go first regardless of the gas price
+	pending := pool.Pending()
+	assert.Equal(t, types.Address{3}, pending.Peek().From)
+	pending.Shift()
+	assert.Equal(t, types.Address{1}, pending.Peek().From)
+	pending.Shift()
+	assert.Equal(t, types.Address{2}, pending.Peek().From)
+
+	// the txns of the lane are not evicted
+	assert.NoError(t, pool.addImpl("", newTxn(4, 0, 200)))
+	assert.Equal(t, uint64(3), pool.Length())
+	assert.Nil(t, pool.sorted.Find(types.Address{2}, 0))
+}
+
+func TestOperator_DropAndFlush(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	addr1 := types.Address{0x1}
+	txns := []*types.Transaction{}
+	for _, nonce := range []uint64{0, 1, 2, 5} {
+		txn := &types.Transaction{From: addr1, Nonce: nonce, GasPrice: big.NewInt(1)}
+		assert.NoError(t, pool.addImpl("", txn))
+		txns = append(txns, txn)
+	}
+
+	// the pending txns after the dropped one are queued again
+	_, err = pool.DropTxn(context.Background(), &proto.DropTxnReq{Hash: txns[1].Hash.String()})
+	assert.NoError(t, err)
+
+	resp, err := pool.GetAccount(context.Background(), &proto.AccountReq{Address: addr1.String()})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Pending, 1)
+	assert.Len(t, resp.Queued, 2)
+
+	nonce, _ := pool.GetNonce(addr1)
+	assert.Equal(t, uint64(1), nonce)
+
+	_, err = pool.DropTxn(context.Background(), &proto.DropTxnReq{Hash: txns[1].Hash.String()})
+	assert.Error(t, err)
+
+	flush, err := pool.FlushAccount(context.Background(), &proto.AccountReq{Address: addr1.String()})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), flush.Dropped)
+	assert.Equal(t, uint64(0), pool.Queued())
+	assert.Equal(t, uint64(1), pool.Length())
+}
+
+func TestSubscribeTxns(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	txnCh, cancel := pool.SubscribeTxns()
+
+	// the queued txns are admitted too
+	txn := &types.Transaction{From: types.Address{0x1}, Nonce: 1, GasPrice: big.NewInt(1)}
+	assert.NoError(t, pool.addImpl("", txn))
+	assert.Equal(t, txn.Hash, (<-txnCh).Hash)
+
+	cancel()
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: types.Address{0x1}, Nonce: 2, GasPrice: big.NewInt(1)}))
+	assert.Len(t, txnCh, 0)
+}
+
+func TestRebroadcast(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	signer := &crypto.FrontierSigner{}
+
+	store := &mockStore{
+		balances: map[types.Address]*big.Int{
+			crypto.PubKeyToAddress(&key0.PublicKey): big.NewInt(1000000),
+		},
+	}
+	createPool := func() *TxPool {
+		pool, err := NewTxPool(hclog.NewNullLogger(), true, store, nil, network.CreateServer(t, nil))
+		assert.NoError(t, err)
+		pool.AddSigner(signer)
+		pool.rebroadcastInterval = 100 * time.Millisecond
+		return pool
+	}
+
+	pool1 := createPool()
+	defer pool1.Close()
+
+	// the txn is added before pool2 is connected
+	txn, err := signer.SignTx(&types.Transaction{
+		Gas:      21000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(1),
+	}, key0)
+	assert.NoError(t, err)
+	assert.NoError(t, pool1.AddTx(txn))
+
+	pool2 := createPool()
+	defer pool2.Close()
+
+	network.MultiJoin(t, pool1.network, pool2.network)
+
+	for i := 0; pool2.Length() == 0; i++ {
+		if i == 100 {
+			t.Fatal("the txn is not rebroadcast")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestValidateTx(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	addr0 := crypto.PubKeyToAddress(&key0.PublicKey)
+
+	signer := crypto.NewEIP155Signer(100)
+
+	store := &mockStore{
+		nonces: map[types.Address]uint64{
+			addr0: 1,
+		},
+		balances: map[types.Address]*big.Int{
+			addr0: big.NewInt(100000),
+		},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.AddSigner(signer)
+	pool.SetForks(chain.AllForksEnabled)
+
+	to := types.Address{0x1}
+	newTxn := func(nonce uint64, gas uint64, value int64, input []byte) *types.Transaction {
+		txn := &types.Transaction{
+			To:       &to,
+			Nonce:    nonce,
+			Gas:      gas,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(value),
+			Input:    input,
+		}
+		if input != nil {
+			txn.To = nil
+		}
+		txn, err := signer.SignTx(txn, key0)
+		assert.NoError(t, err)
+		return txn
+	}
+
+	cases := []struct {
+		name string
+		txn  *types.Transaction
+		err  string
+	}{
+		{"intrinsic gas", newTxn(1, 20000, 0, nil), "intrinsic gas too low"},
+		{"contract creation gas", newTxn(1, 21000, 0, []byte{0x1}), "intrinsic gas too low"},
+		{"block gas limit", newTxn(1, 9000000, 0, nil), "exceeds block gas limit"},
+		{"init code size", newTxn(1, 8000000, 0, make([]byte, state.MaxInitCodeSize+1)), "max init code size exceeded"},
+		{"balance", newTxn(1, 21000, 80000, nil), "insufficient funds"},
+		{"nonce", newTxn(0, 21000, 0, nil), "nonce too low"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := pool.addImpl("", c.txn)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+
+	// the txn signed for another chain is rejected
+	txn, err := crypto.NewEIP155Signer(101).SignTx(&types.Transaction{To: &to, Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(0)}, key0)
+	assert.NoError(t, err)
+	err = pool.addImpl("", txn)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid chain id")
+
+	assert.NoError(t, pool.addImpl("", newTxn(1, 21000, 79000, nil)))
+	assert.Equal(t, uint64(1), pool.Length())
+}
+
+func TestPending(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	newTxn := func(from byte, nonce uint64, price int64) *types.Transaction {
+		return &types.Transaction{From: types.Address{from}, Nonce: nonce, GasPrice: big.NewInt(price)}
+	}
+	assert.NoError(t, pool.addImpl("", newTxn(1, 0, 2), newTxn(1, 1, 5), newTxn(1, 2, 1)))
+	assert.NoError(t, pool.addImpl("", newTxn(2, 0, 3), newTxn(2, 1, 3)))
+	assert.NoError(t, pool.addImpl("", newTxn(3, 0, 4)))
+
+	type item struct {
+		from  byte
+		nonce uint64
+	}
+	iterate := func(drop byte) []item {
+		res := []item{}
+		pending := pool.Pending()
+		for txn := pending.Peek(); txn != nil; txn = pending.Peek() {
+			if txn.From[0] == drop {
+				pending.Pop()
+				continue
+			}
+			res = append(res, item{txn.From[0], txn.Nonce})
+			pending.Shift()
+		}
+		return res
+	}
+
+	// the nonce order of each account goes before the gas price
+	assert.Equal(t, []item{{3, 0}, {2, 0}, {2, 1}, {1, 0}, {1, 1}, {1, 2}}, iterate(0))
+
+	// the account is dropped after a txn that is not applied
+	assert.Equal(t, []item{{3, 0}, {1, 0}, {1, 1}, {1, 2}}, iterate(2))
+
+	// the txns stay in the pool
+	assert.Equal(t, uint64(6), pool.Length())
+}
+
+func TestGossip_Announce(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	signer := &crypto.FrontierSigner{}
+
+	store := &mockStore{
+		balances: map[types.Address]*big.Int{
+			crypto.PubKeyToAddress(&key0.PublicKey): big.NewInt(1000000),
+		},
+	}
+	createPool := func(sealing bool) *TxPool {
+		pool, err := NewTxPool(hclog.NewNullLogger(), sealing, store, nil, network.CreateServer(t, nil))
+		assert.NoError(t, err)
+		pool.AddSigner(signer)
+		pool.rebroadcastInterval = 100 * time.Millisecond
+		return pool
+	}
+
+	pool1 := createPool(true)
+	defer pool1.Close()
+
+	pool2 := createPool(false)
+	defer pool2.Close()
+
+	network.MultiJoin(t, pool1.network, pool2.network)
+
+	// the large txns are announced and pool2 fetches it from pool1, the
+	// nodes that do not seal fetch them too to announce them again
+	to := types.Address{0x1}
+	txn, err := signer.SignTx(&types.Transaction{
+		To:       &to,
+		Gas:      100000,
+		GasPrice: big.NewInt(1),
+		Value:    big.NewInt(0),
+		Input:    make([]byte, txAnnounceSize),
+	}, key0)
+	assert.NoError(t, err)
+	assert.NoError(t, pool1.AddTx(txn))
+
+	for i := 0; pool2.Length() == 0; i++ {
+		if i == 100 {
+			t.Fatal("the txn is not fetched")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	assert.Equal(t, txn.Hash, pool2.getTxn(txn.Hash).Hash)
+
+	// the number of fetches in progress is bounded
+	pool3, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	defer pool3.Close()
+
+	for i := 0; i < maxFetches; i++ {
+		assert.True(t, pool3.startFetch())
+	}
+	assert.False(t, pool3.startFetch())
+}
+
+func TestKnownTxns(t *testing.T) {
+	known := newKnownTxns()
+	peers := []peer.ID{"a", "b", "c"}
+
+	hash := types.Hash{0x1}
+	assert.True(t, known.markSeen(hash))
+	assert.False(t, known.markSeen(hash))
+
+	// the txns are not gossiped to the peers that sent them or were sent them
+	known.markReceived(hash, "a")
+	known.markSent(hash, []peer.ID{"b"})
+	assert.Equal(t, []peer.ID{"c"}, known.unknownPeers(hash, peers, false))
+	assert.Equal(t, []peer.ID{"b", "c"}, known.unknownPeers(hash, peers, true))
+
+	known.prune([]peer.ID{"b"})
+	assert.Equal(t, []peer.ID{"a", "c"}, known.unknownPeers(hash, peers, false))
+
+	// the oldest hashes are dropped
+	set := newHashSet(2)
+	set.add(types.Hash{0x1})
+	set.add(types.Hash{0x2})
+	set.add(types.Hash{0x3})
+	assert.False(t, set.has(types.Hash{0x1}))
+	assert.True(t, set.has(types.Hash{0x3}))
+}
+
+func TestTypedTxns(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	addr0 := crypto.PubKeyToAddress(&key0.PublicKey)
+
+	signer := crypto.NewEIP155Signer(100)
+
+	store := &mockStore{
+		balances: map[types.Address]*big.Int{
+			addr0: big.NewInt(10000000),
+		},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.AddSigner(signer)
+
+	to := types.Address{0x1}
+	newTxn := func(typ types.TxType, nonce uint64, feeCap, tipCap int64) *types.Transaction {
+		txn, err := signer.SignTx(&types.Transaction{
+			Type:      typ,
+			To:        &to,
+			Nonce:     nonce,
+			Gas:       30000,
+			GasPrice:  big.NewInt(feeCap),
+			GasTipCap: big.NewInt(tipCap),
+			Value:     big.NewInt(0),
+			AccessList: types.AccessList{
+				{Address: to, StorageKeys: []types.Hash{{0x1}}},
+			},
+		}, key0)
+		assert.NoError(t, err)
+
+		// the txns are decoded from the wire
+		res := new(types.Transaction)
+		assert.NoError(t, res.UnmarshalRLP(txn.MarshalRLP()))
+		return res
+	}
+
+	// the typed txns require the forks
+	err = pool.addImpl("", newTxn(types.DynamicFeeTx, 0, 10, 2))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+
+	pool.SetForks(&chain.Forks{Berlin: chain.NewFork(0), London: chain.NewFork(0)})
+
+	err = pool.addImpl("", newTxn(types.DynamicFeeTx, 0, 10, 20))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "max priority fee per gas higher than max fee per gas")
+
+	// the access list is paid upfront
+	txn := newTxn(types.AccessListTx, 0, 10, 0)
+	txn.Gas = 21000
+	assert.Error(t, pool.addImpl("", txn))
+
+	assert.NoError(t, pool.addImpl("", newTxn(types.AccessListTx, 0, 10, 0)))
+
+	// the replacement of a dynamic fee txn bumps the tip too
+	assert.NoError(t, pool.addImpl("", newTxn(types.DynamicFeeTx, 1, 10, 2)))
+	assert.Error(t, pool.addImpl("", newTxn(types.DynamicFeeTx, 1, 20, 2)))
+	assert.NoError(t, pool.addImpl("", newTxn(types.DynamicFeeTx, 1, 20, 3)))
+	assert.Equal(t, uint64(2), pool.Length())
+}
+
+func TestPending_BaseFee(t *testing.T) {
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, &mockStore{}, nil, nil)
+	assert.NoError(t, err)
+	pool.EnableDev()
+
+	newTxn := func(from byte, nonce uint64, feeCap, tipCap int64) *types.Transaction {
+		return &types.Transaction{
+			Type:      types.DynamicFeeTx,
+			From:      types.Address{from},
+			Nonce:     nonce,
+			GasPrice:  big.NewInt(feeCap),
+			GasTipCap: big.NewInt(tipCap),
+		}
+	}
+	assert.NoError(t, pool.addImpl("", newTxn(1, 0, 100, 5), newTxn(1, 1, 8, 5)))
+	assert.NoError(t, pool.addImpl("", newTxn(2, 0, 12, 4)))
+	assert.NoError(t, pool.addImpl("", &types.Transaction{From: types.Address{3}, GasPrice: big.NewInt(13)}))
+
+	iterate := func() []*types.Transaction {
+		res := []*types.Transaction{}
+		pending := pool.Pending()
+		for txn := pending.Peek(); txn != nil; txn = pending.Peek() {
+			res = append(res, txn)
+			pending.Shift()
+		}
+		return res
+	}
+
+	// without base fee the txns are ordered by the tip cap and the gas price
+	txns := iterate()
+	assert.Len(t, txns, 4)
+	assert.Equal(t, types.Address{3}, txns[0].From)
+
+	// under the base fee the tip of 2 and 3 is capped and the second txn of 1
+	// does not cover the base fee
+	pool.SetBaseFee(big.NewInt(10))
+	txns = iterate()
+	assert.Len(t, txns, 3)
+	assert.Equal(t, types.Address{1}, txns[0].From)
+	assert.Equal(t, types.Address{3}, txns[1].From)
+	assert.Equal(t, types.Address{2}, txns[2].From)
+}
+
+type mockSimulator struct {
+	reverts map[types.Address]struct{}
+	applied int
+}
+
+func (m *mockSimulator) ApplyTxn(header *types.Header, txn *types.Transaction) ([]byte, bool, error) {
+	m.applied++
+	if _, ok := m.reverts[*txn.To]; ok {
+		return []byte{0x1}, true, nil
+	}
+	return nil, false, nil
+}
+
+func TestSimulation(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	addr0 := crypto.PubKeyToAddress(&key0.PublicKey)
+
+	signer := crypto.NewEIP155Signer(100)
+
+	store := &mockStore{
+		balances: map[types.Address]*big.Int{
+			addr0: big.NewInt(10000000),
+		},
+	}
+	pool, err := NewTxPool(hclog.NewNullLogger(), false, store, nil, nil)
+	assert.NoError(t, err)
+	pool.AddSigner(signer)
+
+	reverts := types.Address{0x1}
+	sim := &mockSimulator{
+		reverts: map[types.Address]struct{}{reverts: {}},
+	}
+	pool.EnableSimulation(sim)
+
+	newTxn := func(to types.Address, nonce uint64) *types.Transaction {
+		txn, err := signer.SignTx(&types.Transaction{
+			To:       &to,
+			Nonce:    nonce,
+			Gas:      30000,
+			GasPrice: big.NewInt(1),
+			Value:    big.NewInt(0),
+		}, key0)
+		assert.NoError(t, err)
+		return txn
+	}
+
+	err = pool.addImpl("", newTxn(reverts, 0))
+	assert.Error(t, err)
+	assert.Equal(t, rejectReverted, rejectReason(err))
+	assert.Equal(t, []byte{0x1}, err.(*txnError).ReturnValue())
+
+	// the txns with a future nonce are not simulated
+	assert.NoError(t, pool.addImpl("", newTxn(reverts, 1)))
+	assert.Equal(t, 1, sim.applied)
+
+	assert.NoError(t, pool.addImpl("", newTxn(types.Address{0x2}, 0)))
+	assert.Equal(t, 2, sim.applied)
+	assert.Equal(t, uint64(2), pool.Length())
+}
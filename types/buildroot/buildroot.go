@@ -55,7 +55,14 @@ func CalculateUncleRoot(uncles []*types.Header) types.Hash {
 
 func calculateRootWithRlp(num int, h func(indx int) *fastrlp.Value) types.Hash {
 	hF := func(indx int) []byte {
-		return h(indx).MarshalTo(nil)
+		v := h(indx)
+		if v.Type() == fastrlp.TypeBytes {
+			// the typed txns and receipts are stored in the trie
+			// as the type followed by the payload
+			raw, _ := v.Bytes()
+			return append([]byte{}, raw...)
+		}
+		return v.MarshalTo(nil)
 	}
 	return CalculateRoot(num, hF)
 }
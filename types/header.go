@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/binary"
 	"fmt"
+	"math/big"
 
 	"github.com/0xPolygon/minimal/helper/hex"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -28,6 +29,9 @@ type Header struct {
 	MixHash      Hash
 	Nonce        Nonce
 	Hash         Hash
+
+	// BaseFee is the base fee per gas of the block, nil before the London fork
+	BaseFee *big.Int
 }
 
 func (h *Header) Equal(hh *Header) bool {
@@ -73,6 +77,10 @@ func (h *Header) Copy() *Header {
 
 	hh.ExtraData = make([]byte, len(h.ExtraData))
 	copy(hh.ExtraData[:], h.ExtraData[:])
+
+	if h.BaseFee != nil {
+		hh.BaseFee = new(big.Int).Set(h.BaseFee)
+	}
 	return hh
 }
 
@@ -26,6 +26,10 @@ type Receipt struct {
 	Logs              []*Log
 	Status            *ReceiptStatus
 
+	// TxType is the type of the txn, the receipts of the typed
+	// txns are encoded with the type before the payload
+	TxType TxType
+
 	// context fields
 	GasUsed         uint64
 	ContractAddress Address
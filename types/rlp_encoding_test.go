@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -42,3 +43,111 @@ func TestRLPUnmarshal_Header_ComputeHash(t *testing.T) {
 	assert.NoError(t, h2.UnmarshalRLP(data))
 	assert.Equal(t, h.Hash, h2.Hash)
 }
+
+func TestRLPEncoding_HeaderBaseFee(t *testing.T) {
+	h := &Header{Number: 1}
+	h.ComputeHash()
+
+	h2 := &Header{Number: 1, BaseFee: big.NewInt(1000)}
+	h2.ComputeHash()
+	assert.NotEqual(t, h.Hash, h2.Hash)
+
+	// the base fee is decoded only if the header includes it
+	res := new(Header)
+	assert.NoError(t, res.UnmarshalRLP(h2.MarshalRLP()))
+	assert.Equal(t, h2.BaseFee, res.BaseFee)
+	assert.Equal(t, h2.Hash, res.Hash)
+
+	assert.NoError(t, res.UnmarshalRLP(h.MarshalRLP()))
+	assert.Nil(t, res.BaseFee)
+	assert.Equal(t, h.Hash, res.Hash)
+}
+
+func TestRLPEncoding_TypedTransaction(t *testing.T) {
+	to := StringToAddress("1")
+	txns := []*Transaction{
+		{
+			Type:     AccessListTx,
+			ChainID:  big.NewInt(100),
+			Nonce:    1,
+			GasPrice: big.NewInt(10),
+			Gas:      21000,
+			To:       &to,
+			Value:    big.NewInt(1),
+			Input:    []byte{0x1},
+			AccessList: AccessList{
+				{Address: to, StorageKeys: []Hash{StringToHash("1"), StringToHash("2")}},
+			},
+			V: 1,
+			R: []byte{0x1},
+			S: []byte{0x2},
+		},
+		{
+			Type:      DynamicFeeTx,
+			ChainID:   big.NewInt(100),
+			Nonce:     2,
+			GasTipCap: big.NewInt(2),
+			GasPrice:  big.NewInt(20),
+			Gas:       21000,
+			Value:     big.NewInt(0),
+			Input:     []byte{0x1},
+		},
+	}
+	for _, txn := range txns {
+		txn.ComputeHash()
+
+		buf := txn.MarshalRLP()
+		assert.Equal(t, byte(txn.Type), buf[0])
+
+		txn2 := new(Transaction)
+		assert.NoError(t, txn2.UnmarshalRLP(buf))
+		assert.Equal(t, txn.Hash, txn2.Hash)
+		assert.Equal(t, buf, txn2.MarshalRLP())
+	}
+
+	// the typed txns are mixed with the legacy ones in a block
+	legacy := &Transaction{GasPrice: big.NewInt(1), Value: big.NewInt(0)}
+	legacy.ComputeHash()
+
+	block := &Block{
+		Header:       &Header{},
+		Transactions: append([]*Transaction{legacy}, txns...),
+	}
+	block2 := new(Block)
+	assert.NoError(t, block2.UnmarshalRLP(block.MarshalRLP()))
+	for i, txn := range block.Transactions {
+		assert.Equal(t, txn.Type, block2.Transactions[i].Type)
+		assert.Equal(t, txn.Hash, block2.Transactions[i].Hash)
+	}
+}
+
+func TestRLPEncoding_TypedReceipt(t *testing.T) {
+	receipt := &Receipt{
+		CumulativeGasUsed: 21000,
+		TxType:            DynamicFeeTx,
+		Logs: []*Log{
+			{Address: StringToAddress("1"), Topics: []Hash{StringToHash("1")}},
+		},
+	}
+	receipt.SetStatus(ReceiptSuccess)
+
+	buf := receipt.MarshalRLP()
+	assert.Equal(t, byte(DynamicFeeTx), buf[0])
+
+	receipt2 := new(Receipt)
+	assert.NoError(t, receipt2.UnmarshalRLP(buf))
+	assert.Equal(t, DynamicFeeTx, receipt2.TxType)
+	assert.Equal(t, buf, receipt2.MarshalRLP())
+
+	// the typed receipts are mixed with the legacy ones in the storage
+	legacy := &Receipt{CumulativeGasUsed: 1}
+	legacy.SetStatus(ReceiptFailed)
+
+	receipts := Receipts{legacy, receipt}
+	receipts2 := Receipts{}
+	assert.NoError(t, receipts2.UnmarshalStoreRLP(receipts.MarshalStoreRLPTo(nil)))
+	for i, r := range receipts {
+		assert.Equal(t, r.TxType, receipts2[i].TxType)
+		assert.Equal(t, r.MarshalRLP(), receipts2[i].MarshalRLP())
+	}
+}
@@ -82,6 +82,10 @@ func (h *Header) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 	vv.Set(arena.NewBytes(h.MixHash.Bytes()))
 	vv.Set(arena.NewCopyBytes(h.Nonce[:]))
 
+	// the base fee is only encoded after the London fork
+	if h.BaseFee != nil {
+		vv.Set(arena.NewBigInt(h.BaseFee))
+	}
 	return vv
 }
 
@@ -102,11 +106,26 @@ func (r *Receipt) MarshalRLP() []byte {
 }
 
 func (r *Receipt) MarshalRLPTo(dst []byte) []byte {
+	if r.TxType != LegacyTx {
+		// the receipts of the typed transactions are the type followed by the payload
+		ar := fastrlp.DefaultArenaPool.Get()
+		dst = r.marshalPayloadWith(ar).MarshalTo(append(dst, byte(r.TxType)))
+		fastrlp.DefaultArenaPool.Put(ar)
+		return dst
+	}
 	return MarshalRLPTo(r.MarshalRLPWith, dst)
 }
 
-// MarshalRLPWith marshals a receipt with a specific fastrlp.Arena
+// MarshalRLPWith marshals a receipt with a specific fastrlp.Arena, the receipts
+// of the typed transactions are marshaled as bytes with the type and the payload
 func (r *Receipt) MarshalRLPWith(a *fastrlp.Arena) *fastrlp.Value {
+	if r.TxType != LegacyTx {
+		return a.NewBytes(r.marshalPayloadWith(a).MarshalTo([]byte{byte(r.TxType)}))
+	}
+	return r.marshalPayloadWith(a)
+}
+
+func (r *Receipt) marshalPayloadWith(a *fastrlp.Arena) *fastrlp.Value {
 	vv := a.NewArray()
 	if r.Status != nil {
 		vv.Set(a.NewUint(uint64(*r.Status)))
@@ -150,11 +169,23 @@ func (t *Transaction) MarshalRLP() []byte {
 }
 
 func (t *Transaction) MarshalRLPTo(dst []byte) []byte {
+	if t.Type != LegacyTx {
+		// the typed transactions are the type followed by the payload
+		ar := fastrlp.DefaultArenaPool.Get()
+		dst = t.MarshalPayloadWith(ar, true).MarshalTo(append(dst, byte(t.Type)))
+		fastrlp.DefaultArenaPool.Put(ar)
+		return dst
+	}
 	return MarshalRLPTo(t.MarshalRLPWith, dst)
 }
 
-// MarshalRLPWith marshals the transaction to RLP with a specific fastrlp.Arena
+// MarshalRLPWith marshals the transaction to RLP with a specific fastrlp.Arena,
+// the typed transactions are marshaled as bytes with the type and the payload
 func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
+	if t.Type != LegacyTx {
+		return arena.NewBytes(t.MarshalPayloadWith(arena, true).MarshalTo([]byte{byte(t.Type)}))
+	}
+
 	vv := arena.NewArray()
 
 	vv.Set(arena.NewUint(t.Nonce))
@@ -178,3 +209,48 @@ func (t *Transaction) MarshalRLPWith(arena *fastrlp.Arena) *fastrlp.Value {
 
 	return vv
 }
+
+// MarshalPayloadWith marshals the payload of a typed transaction, the
+// signature values are left out to compute the signing hash
+func (t *Transaction) MarshalPayloadWith(arena *fastrlp.Arena, signature bool) *fastrlp.Value {
+	vv := arena.NewArray()
+
+	vv.Set(arena.NewBigInt(bigOrZero(t.ChainID)))
+	vv.Set(arena.NewUint(t.Nonce))
+	if t.Type == DynamicFeeTx {
+		vv.Set(arena.NewBigInt(bigOrZero(t.GasTipCap)))
+	}
+	vv.Set(arena.NewBigInt(bigOrZero(t.GasPrice)))
+	vv.Set(arena.NewUint(t.Gas))
+
+	// Address may be empty
+	if t.To != nil {
+		vv.Set(arena.NewBytes((*t.To).Bytes()))
+	} else {
+		vv.Set(arena.NewNull())
+	}
+
+	vv.Set(arena.NewBigInt(bigOrZero(t.Value)))
+	vv.Set(arena.NewCopyBytes(t.Input))
+
+	list := arena.NewArray()
+	for _, tuple := range t.AccessList {
+		v := arena.NewArray()
+		v.Set(arena.NewBytes(tuple.Address.Bytes()))
+
+		keys := arena.NewArray()
+		for _, key := range tuple.StorageKeys {
+			keys.Set(arena.NewBytes(key.Bytes()))
+		}
+		v.Set(keys)
+		list.Set(v)
+	}
+	vv.Set(list)
+
+	if signature {
+		vv.Set(arena.NewUint(uint64(t.V)))
+		vv.Set(arena.NewCopyBytes(t.R))
+		vv.Set(arena.NewCopyBytes(t.S))
+	}
+	return vv
+}
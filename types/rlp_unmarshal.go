@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/0xPolygon/minimal/helper/keccak"
 	"github.com/umbracle/fastrlp"
 )
 
@@ -87,8 +88,8 @@ func (h *Header) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
 	if err != nil {
 		return err
 	}
-	if num := len(elems); num != 15 {
-		return fmt.Errorf("not enough elements to decode header, expected 15 but found %d", num)
+	if num := len(elems); num != 15 && num != 16 {
+		return fmt.Errorf("not enough elements to decode header, expected 15 or 16 but found %d", num)
 	}
 
 	// parentHash
@@ -153,6 +154,14 @@ func (h *Header) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
 		return err
 	}
 	h.SetNonce(nonce)
+	// baseFee
+	h.BaseFee = nil
+	if len(elems) == 16 {
+		h.BaseFee = new(big.Int)
+		if err = elems[15].GetBigInt(h.BaseFee); err != nil {
+			return err
+		}
+	}
 
 	// compute the hash after the decoding
 	h.ComputeHash()
@@ -179,11 +188,39 @@ func (r *Receipts) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
 }
 
 func (r *Receipt) UnmarshalRLP(input []byte) error {
+	if len(input) > 0 && input[0] <= 0x7f {
+		// the receipt of a typed transaction
+		return r.unmarshalTyped(input)
+	}
 	return UnmarshalRlp(r.UnmarshalRLPFrom, input)
 }
 
 // UnmarshalRLP unmarshals a Receipt in RLP format
 func (r *Receipt) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
+	if v.Type() == fastrlp.TypeBytes {
+		// receipt of a typed transaction
+		raw, err := v.Bytes()
+		if err != nil {
+			return err
+		}
+		return r.unmarshalTyped(raw)
+	}
+	return r.unmarshalPayload(p, v)
+}
+
+func (r *Receipt) unmarshalTyped(input []byte) error {
+	if len(input) == 0 {
+		return fmt.Errorf("empty typed receipt")
+	}
+	r.TxType = TxType(input[0])
+	if r.TxType != AccessListTx && r.TxType != DynamicFeeTx {
+		return fmt.Errorf("receipt type %d not supported", input[0])
+	}
+	return UnmarshalRlp(r.unmarshalPayload, input[1:])
+}
+
+// unmarshalPayload unmarshals the payload of a receipt
+func (r *Receipt) unmarshalPayload(p *fastrlp.Parser, v *fastrlp.Value) error {
 	elems, err := v.GetElems()
 	if err != nil {
 		return err
@@ -264,11 +301,25 @@ func (l *Log) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
 }
 
 func (t *Transaction) UnmarshalRLP(input []byte) error {
+	if len(input) > 0 && input[0] <= 0x7f {
+		// the rlp of a legacy transaction is a list, a lower first
+		// byte is the type of a typed transaction
+		return t.unmarshalTyped(input)
+	}
 	return UnmarshalRlp(t.UnmarshalRLPFrom, input)
 }
 
 // UnmarshalRLP unmarshals a Transaction in RLP format
 func (t *Transaction) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) error {
+	if v.Type() == fastrlp.TypeBytes {
+		// typed transaction in a block
+		raw, err := v.Bytes()
+		if err != nil {
+			return err
+		}
+		return t.unmarshalTyped(raw)
+	}
+
 	elems, err := v.GetElems()
 	if err != nil {
 		return err
@@ -331,3 +382,133 @@ func (t *Transaction) UnmarshalRLPFrom(p *fastrlp.Parser, v *fastrlp.Value) erro
 	}
 	return nil
 }
+
+func (t *Transaction) unmarshalTyped(input []byte) error {
+	if len(input) == 0 {
+		return fmt.Errorf("empty typed transaction")
+	}
+	t.Type = TxType(input[0])
+	if t.Type != AccessListTx && t.Type != DynamicFeeTx {
+		return fmt.Errorf("transaction type %d not supported", input[0])
+	}
+	if err := UnmarshalRlp(t.unmarshalPayload, input[1:]); err != nil {
+		return err
+	}
+	copy(t.Hash[:], keccak.Keccak256(nil, input))
+	return nil
+}
+
+// unmarshalPayload unmarshals the payload of a typed transaction
+func (t *Transaction) unmarshalPayload(p *fastrlp.Parser, v *fastrlp.Value) error {
+	elems, err := v.GetElems()
+	if err != nil {
+		return err
+	}
+	expected := 11
+	if t.Type == DynamicFeeTx {
+		expected = 12
+	}
+	if num := len(elems); num != expected {
+		return fmt.Errorf("not enough elements to decode transaction, expected %d but found %d", expected, num)
+	}
+
+	getBigInt := func(v *fastrlp.Value) (*big.Int, error) {
+		b := new(big.Int)
+		if err := v.GetBigInt(b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+
+	// chain id
+	if t.ChainID, err = getBigInt(elems[0]); err != nil {
+		return err
+	}
+	// nonce
+	if t.Nonce, err = elems[1].GetUint64(); err != nil {
+		return err
+	}
+	elems = elems[2:]
+
+	// max priority fee per gas
+	if t.Type == DynamicFeeTx {
+		if t.GasTipCap, err = getBigInt(elems[0]); err != nil {
+			return err
+		}
+		elems = elems[1:]
+	}
+	// gasPrice or max fee per gas
+	if t.GasPrice, err = getBigInt(elems[0]); err != nil {
+		return err
+	}
+	// gas
+	if t.Gas, err = elems[1].GetUint64(); err != nil {
+		return err
+	}
+	// to
+	vv, err := elems[2].Bytes()
+	if err != nil {
+		return err
+	}
+	if len(vv) == 20 {
+		addr := BytesToAddress(vv)
+		t.To = &addr
+	} else {
+		t.To = nil
+	}
+	// value
+	if t.Value, err = getBigInt(elems[3]); err != nil {
+		return err
+	}
+	// input
+	if t.Input, err = elems[4].GetBytes(t.Input[:0]); err != nil {
+		return err
+	}
+	// access list
+	tuples, err := elems[5].GetElems()
+	if err != nil {
+		return err
+	}
+	t.AccessList = make(AccessList, len(tuples))
+	for i, tuple := range tuples {
+		items, err := tuple.GetElems()
+		if err != nil {
+			return err
+		}
+		if len(items) != 2 {
+			return fmt.Errorf("not enough elements to decode access tuple, expected 2 but found %d", len(items))
+		}
+		if err := items[0].GetAddr(t.AccessList[i].Address[:]); err != nil {
+			return err
+		}
+		keys, err := items[1].GetElems()
+		if err != nil {
+			return err
+		}
+		t.AccessList[i].StorageKeys = make([]Hash, len(keys))
+		for j, key := range keys {
+			if err := key.GetHash(t.AccessList[i].StorageKeys[j][:]); err != nil {
+				return err
+			}
+		}
+	}
+	// y parity
+	vv, err = elems[6].Bytes()
+	if err != nil {
+		return err
+	}
+	if len(vv) != 1 {
+		t.V = 0x0
+	} else {
+		t.V = vv[0]
+	}
+	// R
+	if t.R, err = elems[7].GetBytes(t.R[:0]); err != nil {
+		return err
+	}
+	// S
+	if t.S, err = elems[8].GetBytes(t.S[:0]); err != nil {
+		return err
+	}
+	return nil
+}
@@ -6,6 +6,33 @@ import (
 	"github.com/0xPolygon/minimal/helper/keccak"
 )
 
+// TxType is the EIP-2718 type of a transaction
+type TxType byte
+
+const (
+	LegacyTx     TxType = 0x0
+	AccessListTx TxType = 0x1
+	DynamicFeeTx TxType = 0x2
+)
+
+// AccessTuple is an address and the storage slots accessed by a transaction
+type AccessTuple struct {
+	Address     Address
+	StorageKeys []Hash
+}
+
+// AccessList is the EIP-2930 list of the addresses and slots accessed by a transaction
+type AccessList []AccessTuple
+
+// StorageKeys returns the number of storage slots in the access list
+func (a AccessList) StorageKeys() int {
+	num := 0
+	for _, tuple := range a {
+		num += len(tuple.StorageKeys)
+	}
+	return num
+}
+
 type Transaction struct {
 	Nonce    uint64
 	GasPrice *big.Int
@@ -18,14 +45,56 @@ type Transaction struct {
 	S        []byte
 	Hash     Hash
 	From     Address
+
+	// Type is the EIP-2718 type, the typed transactions include the chain id
+	// and an access list. The GasPrice of the dynamic fee transactions is the
+	// max fee per gas and GasTipCap is the max priority fee per gas
+	Type       TxType
+	ChainID    *big.Int
+	AccessList AccessList
+	GasTipCap  *big.Int
 }
 
 func (t *Transaction) IsContractCreation() bool {
 	return t.To == nil
 }
 
+// EffectiveTip returns the gas price paid over the base fee, a nil base fee
+// is a chain without base fee. It is negative if the base fee is not covered
+func (t *Transaction) EffectiveTip(baseFee *big.Int) *big.Int {
+	price := bigOrZero(t.GasPrice)
+	if t.Type != DynamicFeeTx {
+		if baseFee == nil {
+			return new(big.Int).Set(price)
+		}
+		return new(big.Int).Sub(price, baseFee)
+	}
+
+	tip := bigOrZero(t.GasTipCap)
+	if baseFee == nil {
+		return new(big.Int).Set(tip)
+	}
+	if max := new(big.Int).Sub(price, baseFee); max.Cmp(tip) < 0 {
+		return max
+	}
+	return new(big.Int).Set(tip)
+}
+
+func bigOrZero(b *big.Int) *big.Int {
+	if b == nil {
+		return big.NewInt(0)
+	}
+	return b
+}
+
 // ComputeHash computes the hash of the transaction
 func (t *Transaction) ComputeHash() *Transaction {
+	if t.Type != LegacyTx {
+		// the hash of a typed transaction is the hash of the type and the payload
+		copy(t.Hash[:], keccak.Keccak256(nil, t.MarshalRLP()))
+		return t
+	}
+
 	ar := marshalArenaPool.Get()
 	hash := keccak.DefaultKeccakPool.Get()
 
@@ -54,5 +123,21 @@ func (t *Transaction) Copy() *Transaction {
 
 	tt.Input = make([]byte, len(t.Input))
 	copy(tt.Input[:], t.Input[:])
+
+	if t.ChainID != nil {
+		tt.ChainID = new(big.Int).Set(t.ChainID)
+	}
+	if t.GasTipCap != nil {
+		tt.GasTipCap = new(big.Int).Set(t.GasTipCap)
+	}
+	if t.AccessList != nil {
+		tt.AccessList = make(AccessList, len(t.AccessList))
+		for i, tuple := range t.AccessList {
+			tt.AccessList[i] = AccessTuple{
+				Address:     tuple.Address,
+				StorageKeys: append([]Hash{}, tuple.StorageKeys...),
+			}
+		}
+	}
 	return tt
 }
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_EffectiveTip(t *testing.T) {
+	legacy := &Transaction{GasPrice: big.NewInt(10)}
+	dynamic := &Transaction{Type: DynamicFeeTx, GasPrice: big.NewInt(10), GasTipCap: big.NewInt(3)}
+
+	cases := []struct {
+		txn     *Transaction
+		baseFee *big.Int
+		tip     int64
+	}{
+		{legacy, nil, 10},
+		{legacy, big.NewInt(4), 6},
+		{dynamic, nil, 3},
+		{dynamic, big.NewInt(4), 3},
+		// the tip is capped by the max fee
+		{dynamic, big.NewInt(8), 2},
+		{dynamic, big.NewInt(12), -2},
+	}
+	for _, c := range cases {
+		assert.Equal(t, big.NewInt(c.tip), c.txn.EffectiveTip(c.baseFee))
+	}
+}